@@ -0,0 +1,13 @@
+data "vboxweb_host_info" "host" {}
+
+# Give the build VM half of the host, within the limits of a lab host.
+resource "vboxweb_machine" "build" {
+  name      = "build-1"
+  source    = "ubuntu-template"
+  cpu_count = max(1, floor(data.vboxweb_host_info.host.processor_count / 2))
+  memory_mb = min(16384, floor(data.vboxweb_host_info.host.memory_mb / 2))
+}
+
+output "virtualbox_version" {
+  value = data.vboxweb_host_info.host.version
+}
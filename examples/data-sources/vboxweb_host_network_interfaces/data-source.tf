@@ -0,0 +1,10 @@
+# Fails the plan if eth0 is missing or down, naming the interfaces that are up.
+data "vboxweb_host_network_interfaces" "host" {
+  require_up = ["eth0"]
+}
+
+output "bridged_interfaces_up" {
+  value = [
+    for i in data.vboxweb_host_network_interfaces.host.interfaces : i.name if i.status == "up"
+  ]
+}
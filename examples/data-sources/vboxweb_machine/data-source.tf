@@ -0,0 +1,12 @@
+# Base VM managed outside Terraform
+data "vboxweb_machine" "base" {
+  name = "ubuntu-24.04-base"
+}
+
+resource "vboxweb_nat_port_forward" "base_ssh" {
+  machine_id   = data.vboxweb_machine.base.id
+  adapter_slot = 0
+  name         = "ssh"
+  protocol     = "tcp"
+  guest_port   = 22
+}
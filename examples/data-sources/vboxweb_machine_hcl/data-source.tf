@@ -0,0 +1,8 @@
+data "vboxweb_machine_hcl" "legacy" {
+  machine_id = "legacy-build-server"
+}
+
+# terraform output -raw legacy_hcl > legacy.tf
+output "legacy_hcl" {
+  value = data.vboxweb_machine_hcl.legacy.hcl
+}
@@ -0,0 +1,14 @@
+# Web VMs created outside Terraform, e.g. web-1, web-2
+data "vboxweb_machines" "web" {
+  name_regex = "^web-[0-9]+$"
+}
+
+resource "vboxweb_nat_port_forward" "web_http" {
+  for_each = { for m in data.vboxweb_machines.web.machines : m.name => m }
+
+  machine_id   = each.value.id
+  adapter_slot = 0
+  name         = "http"
+  protocol     = "tcp"
+  guest_port   = 80
+}
@@ -0,0 +1,18 @@
+data "vboxweb_network_adapters" "web" {
+  machine_id = vboxweb_machine.web.id
+}
+
+locals {
+  # First NAT adapter of the VM
+  nat_slot = [
+    for a in data.vboxweb_network_adapters.web.adapters : a.slot if a.attachment_type == "NAT"
+  ][0]
+}
+
+resource "vboxweb_nat_port_forward" "ssh" {
+  machine_id   = vboxweb_machine.web.id
+  adapter_slot = local.nat_slot
+  name         = "ssh"
+  protocol     = "tcp"
+  guest_port   = 22
+}
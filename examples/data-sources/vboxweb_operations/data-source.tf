@@ -0,0 +1,12 @@
+# Progress references as logged by vboxwebsrv, e.g. while a clone hangs
+data "vboxweb_operations" "stuck" {
+  progress_refs = ["5e5f1b6a2c0d4f11-0000000000000042"]
+  wait_timeout  = "30s"
+}
+
+output "stuck_operations" {
+  value = [
+    for op in data.vboxweb_operations.stuck.operations :
+    "${op.description}: ${op.operation_description} (${op.percent}%)"
+  ]
+}
@@ -0,0 +1,7 @@
+data "vboxweb_os_types" "linux" {
+  family_id = "Linux"
+}
+
+output "linux_64bit_os_types" {
+  value = [for t in data.vboxweb_os_types.linux.os_types : t.id if t.is_64bit]
+}
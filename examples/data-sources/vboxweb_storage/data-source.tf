@@ -0,0 +1,12 @@
+data "vboxweb_storage" "web" {
+  machine_id = vboxweb_machine.web.id
+}
+
+# Paths of all disks attached to the VM
+output "disk_paths" {
+  value = flatten([
+    for ctl in data.vboxweb_storage.web.controllers : [
+      for att in ctl.attachments : att.medium if att.type == "hdd"
+    ]
+  ])
+}
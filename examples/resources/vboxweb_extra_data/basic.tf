@@ -0,0 +1,12 @@
+# Disable the time synchronization of the Guest Additions
+resource "vboxweb_extra_data" "no_time_sync" {
+  machine_id = vboxweb_machine.example.id
+  key        = "VBoxInternal/Devices/VMMDev/0/Config/GetHostTimeDisabled"
+  value      = "1"
+}
+
+# Stop the VirtualBox Manager from checking for updates
+resource "vboxweb_extra_data" "no_update_check" {
+  key   = "GUI/UpdateDate"
+  value = "never"
+}
@@ -0,0 +1,9 @@
+# Tell the guest which address to register with the CI controller.
+# The guest reads it with: VBoxControl guestproperty get /ci/controller
+resource "vboxweb_guest_property" "controller" {
+  machine_id = vboxweb_machine.example.id
+  key        = "/ci/controller"
+  value      = "10.0.2.2:8080"
+  # The guest cannot overwrite it
+  flags = "RDONLYGUEST"
+}
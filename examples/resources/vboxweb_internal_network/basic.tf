@@ -0,0 +1,8 @@
+# Track the VMs attached to the "lab" internal network
+resource "vboxweb_internal_network" "lab" {
+  name = "lab"
+}
+
+output "lab_machine_ids" {
+  value = vboxweb_internal_network.lab.machine_ids
+}
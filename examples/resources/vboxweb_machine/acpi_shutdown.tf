@@ -0,0 +1,9 @@
+# Let the guest OS shut down cleanly when the VM is stopped; power it off
+# if it has not shut down within 5 minutes
+resource "vboxweb_machine" "db" {
+  name          = "db-1"
+  source        = "ubuntu-server-base"
+  state         = "stopped"
+  shutdown_mode = "acpi"
+  wait_timeout  = "5m"
+}
@@ -0,0 +1,6 @@
+# Enable x2APIC on a template that has it off, for guests that need it
+resource "vboxweb_machine" "modern" {
+  name           = "modern-vm"
+  source         = "ubuntu-server-base"
+  x2apic_enabled = true
+}
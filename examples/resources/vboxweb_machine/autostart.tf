@@ -0,0 +1,16 @@
+# Bring the database VM back after a host reboot, before the app VMs
+resource "vboxweb_machine" "db" {
+  name              = "db-1"
+  source            = "ubuntu-server-base"
+  state             = "started"
+  autostart_enabled = true
+  autostart_delay   = 0
+}
+
+resource "vboxweb_machine" "app" {
+  name              = "app-1"
+  source            = "ubuntu-server-base"
+  state             = "started"
+  autostart_enabled = true
+  autostart_delay   = 60
+}
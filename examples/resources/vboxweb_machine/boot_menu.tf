@@ -0,0 +1,7 @@
+# Boot straight into the guest OS for unattended provisioning
+resource "vboxweb_machine" "ci" {
+  name           = "ci-runner"
+  source         = "ubuntu-server-base"
+  state          = "started"
+  boot_menu_mode = "disabled"
+}
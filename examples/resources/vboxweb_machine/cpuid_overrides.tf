@@ -0,0 +1,24 @@
+# Report a fixed CPU signature to the guest so that it can be moved between
+# hosts with different CPUs
+resource "vboxweb_machine" "portable" {
+  name   = "portable-vm"
+  source = "ubuntu-22.04-base"
+
+  cpuid_overrides {
+    leaf = "0x1"
+    eax  = "0x000306a9"
+    ebx  = "0x00020800"
+    ecx  = "0x80000201"
+    edx  = "0x178bfbff"
+  }
+
+  # Mask the extended feature flags of leaf 7
+  cpuid_overrides {
+    leaf     = "0x7"
+    sub_leaf = "0"
+    eax      = "0"
+    ebx      = "0"
+    ecx      = "0"
+    edx      = "0"
+  }
+}
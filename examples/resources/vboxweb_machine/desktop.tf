@@ -0,0 +1,15 @@
+# Desktop VM for UI tests: record its screen and allow copying test
+# artifacts out through the shared clipboard
+resource "vboxweb_machine" "ui_test" {
+  name                     = "ui-test-1"
+  source                   = "windows-11-base"
+  state                    = "started"
+  clipboard_file_transfers = true
+  recording_enabled        = true
+
+  # The recording is written on the VirtualBox host, not where Terraform runs
+  recording_screens = [0]
+  recording_file    = "/srv/recordings/ui-test-1.webm"
+  recording_format  = "vp9"
+  recording_fps     = 15
+}
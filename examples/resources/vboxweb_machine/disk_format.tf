@@ -0,0 +1,7 @@
+# Clone a template with VDI disks into a VM with VMDK disks, e.g. to export
+# them to another hypervisor
+resource "vboxweb_machine" "export" {
+  name        = "export-vm"
+  source      = "ubuntu-template"
+  disk_format = "VMDK"
+}
@@ -0,0 +1,10 @@
+resource "vboxweb_machine" "web" {
+  name          = "web"
+  source        = "ubuntu-template"
+  clone_options = ["KeepDiskNames"]
+}
+
+# e.g. ["ubuntu-template.vdi"] rather than ["web.vdi"]
+output "web_disks" {
+  value = vboxweb_machine.web.disk_names
+}
@@ -0,0 +1,7 @@
+# Use the host hypervisor API on a Windows host where Hyper-V is enabled
+resource "vboxweb_machine" "hyperv_host" {
+  name             = "hyperv-host-vm"
+  source           = "ubuntu-22.04-base"
+  execution_engine = "NativeApi"
+  state            = "started"
+}
@@ -0,0 +1,8 @@
+# Clone a template whose disks are dynamically allocated and give the
+# database VM preallocated disks
+resource "vboxweb_machine" "database" {
+  name        = "db-1"
+  source      = "ubuntu-template"
+  fixed_disks = true
+  state       = "started"
+}
@@ -4,6 +4,7 @@ resource "vboxweb_machine" "full" {
   clone_mode    = "MachineAndChildStates"
   clone_options = ["KeepAllMACs", "KeepDiskNames"]
   state         = "started"
+  stop_method   = "savestate"
   session_type  = "gui"
   wait_timeout  = "30m"
 }
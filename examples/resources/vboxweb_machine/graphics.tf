@@ -0,0 +1,17 @@
+# Fix a template that carries a legacy graphics controller
+resource "vboxweb_machine" "desktop" {
+  name                = "desktop-vm"
+  source              = "ubuntu-desktop-base"
+  graphics_controller = "VMSVGA"
+  vram_mb             = 128
+}
+
+# Dual-monitor desktop with 3D acceleration
+resource "vboxweb_machine" "workstation" {
+  name                = "workstation-vm"
+  source              = "ubuntu-desktop-base"
+  graphics_controller = "VMSVGA"
+  vram_mb             = 256
+  accelerate_3d       = true
+  monitor_count       = 2
+}
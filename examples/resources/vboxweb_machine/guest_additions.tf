@@ -0,0 +1,7 @@
+# Bring a template's outdated Guest Additions up to the host's version
+resource "vboxweb_machine" "app" {
+  name                    = "app-vm"
+  source                  = "ubuntu-22.04-base"
+  state                   = "started"
+  install_guest_additions = true
+}
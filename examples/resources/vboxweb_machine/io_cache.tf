@@ -0,0 +1,16 @@
+# Favor crash consistency over speed for a database VM: write through to
+# the disk images instead of the host's page cache
+resource "vboxweb_machine" "db" {
+  name             = "db-1"
+  source           = "ubuntu-server-base"
+  state            = "started"
+  io_cache_enabled = false
+}
+
+# Only use the host cache for the scratch disks on the SCSI controller
+resource "vboxweb_machine" "build" {
+  name                = "build-1"
+  source              = "ubuntu-server-base"
+  io_cache_enabled    = true
+  io_cache_controller = "SCSI"
+}
@@ -0,0 +1,9 @@
+# Reclaim 2 GB of an idle build agent's RAM for other VMs on the host.
+# The guest must run the Guest Additions.
+resource "vboxweb_machine" "idle" {
+  name              = "build-agent-idle"
+  source            = "ubuntu-template"
+  state             = "started"
+  memory_mb         = 8192
+  memory_balloon_mb = 2048
+}
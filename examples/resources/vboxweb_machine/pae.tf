@@ -0,0 +1,7 @@
+# Legacy 32-bit guest that needs PAE to use more than 4 GB of memory
+resource "vboxweb_machine" "legacy" {
+  name        = "legacy-vm"
+  source      = "debian-i386-base"
+  pae_enabled = true
+  long_mode   = false
+}
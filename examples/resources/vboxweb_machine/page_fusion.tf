@@ -0,0 +1,10 @@
+# Run many clones of the same Windows template on less host RAM by sharing
+# their identical memory pages. The guests must run the Guest Additions.
+resource "vboxweb_machine" "desktop" {
+  count               = 10
+  name                = "win-desktop-${count.index}"
+  source              = "windows-template"
+  state               = "started"
+  memory_mb           = 4096
+  page_fusion_enabled = true
+}
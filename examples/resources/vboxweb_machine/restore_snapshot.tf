@@ -0,0 +1,8 @@
+# Roll a test VM back to its baseline snapshot before a test run. Changing
+# restore_snapshot, e.g. from "baseline" to its UUID, restores it again.
+resource "vboxweb_machine" "test_runner" {
+  name             = "test-runner-1"
+  source           = "ubuntu-24.04-base"
+  state            = "started"
+  restore_snapshot = "baseline"
+}
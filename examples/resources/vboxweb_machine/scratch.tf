@@ -0,0 +1,23 @@
+# Create an empty VM with the settings VirtualBox recommends for Ubuntu,
+# including a SATA controller, and attach an existing disk and an installer ISO
+resource "vboxweb_machine" "example" {
+  name       = "my-vm"
+  os_type_id = "Ubuntu_64"
+  memory_mb  = 4096
+  cpu_count  = 2
+}
+
+resource "vboxweb_storage_attachment" "disk" {
+  machine_id = vboxweb_machine.example.id
+  controller = "SATA"
+  port       = 0
+  medium     = "/var/lib/vbox/disks/my-vm.vdi"
+}
+
+resource "vboxweb_storage_attachment" "installer" {
+  machine_id = vboxweb_machine.example.id
+  controller = "SATA"
+  port       = 1
+  type       = "dvd"
+  medium     = "/var/lib/vbox/iso/ubuntu-24.04-live-server-amd64.iso"
+}
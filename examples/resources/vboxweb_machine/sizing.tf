@@ -0,0 +1,7 @@
+# Give a clone of a small template more memory and CPUs
+resource "vboxweb_machine" "build" {
+  name      = "build-agent"
+  source    = "ubuntu-template"
+  memory_mb = 8192
+  cpu_count = 4
+}
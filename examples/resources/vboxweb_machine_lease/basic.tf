@@ -0,0 +1,13 @@
+variable "ci_job_url" {
+  type = string
+}
+
+data "vboxweb_machine" "runner" {
+  name = "ci-runner-1"
+}
+
+# Fails if another pipeline holds the VM; released on destroy
+resource "vboxweb_machine_lease" "runner" {
+  machine_id = data.vboxweb_machine.runner.id
+  holder     = var.ci_job_url
+}
@@ -0,0 +1,12 @@
+# Three running linked clones named web-0, web-1 and web-2
+resource "vboxweb_machine_pool" "web" {
+  name_prefix   = "web"
+  source        = "ubuntu-template"
+  size          = 3
+  clone_options = ["Link"]
+  state         = "started"
+}
+
+output "web_ids" {
+  value = vboxweb_machine_pool.web.instances[*].id
+}
@@ -0,0 +1,7 @@
+# Instances named k8s-node00, k8s-node01 and k8s-node02
+resource "vboxweb_machine_pool" "nodes" {
+  name_prefix   = "k8s"
+  name_template = "{{.Prefix}}-node{{printf \"%02d\" .Index}}"
+  source        = "ubuntu-template"
+  size          = 3
+}
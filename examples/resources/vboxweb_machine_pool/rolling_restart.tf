@@ -0,0 +1,13 @@
+# Power-cycle the instances one at a time whenever the source changes, so
+# that two of the three stay available.
+resource "vboxweb_machine_pool" "web" {
+  name_prefix = "web"
+  source      = "ubuntu-template"
+  size        = 3
+  state       = "started"
+
+  restart_batch_size = 1
+  restart_triggers = {
+    template_version = var.template_version
+  }
+}
@@ -0,0 +1,10 @@
+# Dump the tasks of a hung Linux guest to its console. Bump "attempt" to
+# send the keys again.
+resource "vboxweb_machine_sysrq" "dump_tasks" {
+  machine_id = vboxweb_machine.web.id
+  key        = "t"
+
+  triggers = {
+    attempt = "1"
+  }
+}
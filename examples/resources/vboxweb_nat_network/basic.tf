@@ -0,0 +1,12 @@
+# A NAT network shared by lab VMs, with DHCP
+resource "vboxweb_nat_network" "lab" {
+  name    = "lab"
+  network = "10.0.9.0/24"
+}
+
+# A NAT network for VMs with static addresses
+resource "vboxweb_nat_network" "static" {
+  name         = "static"
+  network      = "192.168.100.0/24"
+  dhcp_enabled = false
+}
@@ -0,0 +1,14 @@
+resource "vboxweb_nat_network" "lab" {
+  name    = "lab"
+  network = "10.0.9.0/24"
+}
+
+# Forward host port 2222 to SSH on the VM at 10.0.9.5
+resource "vboxweb_nat_network_port_forward" "ssh" {
+  network_name = vboxweb_nat_network.lab.name
+  name         = "ssh"
+  protocol     = "tcp"
+  host_port    = 2222
+  guest_ip     = "10.0.9.5"
+  guest_port   = 22
+}
@@ -0,0 +1,10 @@
+# Forwards DNS over both TCP and UDP. VirtualBox gets two rules,
+# "dns-tcp" and "dns-udp", which share the same auto-selected host port.
+resource "vboxweb_nat_port_forward" "dns" {
+  machine_id     = vboxweb_machine.example.id
+  adapter_slot   = 0
+  name           = "dns"
+  protocol       = "both"
+  auto_host_port = true
+  guest_port     = 53
+}
@@ -0,0 +1,27 @@
+# Bridge the second adapter of a VM to the host's LAN, with a pinned MAC
+# address so that it keeps its DHCP lease across clones
+resource "vboxweb_network_adapter" "lan" {
+  machine_id        = vboxweb_machine.web.id
+  slot              = 1
+  attachment_type   = "bridged"
+  bridged_interface = "eth0"
+  mac_address       = "08:00:27:12:34:56"
+  adapter_type      = "Virtio"
+}
+
+# Put the third adapter on an internal network shared with other lab VMs
+resource "vboxweb_network_adapter" "lab" {
+  machine_id            = vboxweb_machine.web.id
+  slot                  = 2
+  attachment_type       = "intnet"
+  internal_network_name = vboxweb_internal_network.lab.name
+}
+
+# A host-only adapter with its cable unplugged, e.g. to test link failover
+resource "vboxweb_network_adapter" "mgmt" {
+  machine_id          = vboxweb_machine.web.id
+  slot                = 3
+  attachment_type     = "hostonly"
+  host_only_interface = "vboxnet0"
+  cable_connected     = false
+}
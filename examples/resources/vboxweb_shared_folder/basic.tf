@@ -0,0 +1,16 @@
+# Share a source checkout with the guest, mounted at boot
+resource "vboxweb_shared_folder" "src" {
+  machine_id       = vboxweb_machine.example.id
+  name             = "src"
+  host_path        = "/home/ci/src"
+  automount        = true
+  auto_mount_point = "/mnt/src"
+}
+
+# Share build inputs read-only
+resource "vboxweb_shared_folder" "inputs" {
+  machine_id = vboxweb_machine.example.id
+  name       = "inputs"
+  host_path  = "/srv/build-inputs"
+  writable   = false
+}
@@ -0,0 +1,6 @@
+# Take a baseline snapshot once the VM is provisioned
+resource "vboxweb_snapshot" "baseline" {
+  machine_id  = vboxweb_machine.example.id
+  name        = "baseline"
+  description = "Provisioned, before the test suite runs"
+}
@@ -0,0 +1,6 @@
+resource "vboxweb_storage_attachment" "data" {
+  machine_id = vboxweb_machine.example.id
+  controller = "SATA"
+  port       = 1
+  medium     = "/var/lib/vbox/disks/data.vdi"
+}
@@ -0,0 +1,8 @@
+# Add an empty DVD drive to a clone whose template has none.
+# Set medium later to insert a disc without recreating the drive.
+resource "vboxweb_storage_attachment" "dvd" {
+  machine_id = vboxweb_machine.example.id
+  controller = "SATA"
+  port       = 2
+  type       = "dvd"
+}
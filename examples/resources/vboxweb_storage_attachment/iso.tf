@@ -0,0 +1,11 @@
+# Changing medium swaps the disc without detaching the drive.
+# Set medium = "" to eject it.
+resource "vboxweb_storage_attachment" "installer" {
+  machine_id  = vboxweb_machine.example.id
+  controller  = "IDE"
+  port        = 1
+  device      = 0
+  type        = "dvd"
+  medium      = "/var/lib/vbox/iso/ubuntu-24.04-live-server-amd64.iso"
+  access_mode = "readonly"
+}
@@ -0,0 +1,16 @@
+# A fixed-size disk shared by two VMs, for example for a cluster quorum.
+resource "vboxweb_storage_attachment" "shared_node1" {
+  machine_id  = vboxweb_machine.node1.id
+  controller  = "SATA"
+  port        = 1
+  medium      = "/var/lib/vbox/disks/shared-fixed.vdi"
+  access_mode = "shareable"
+}
+
+resource "vboxweb_storage_attachment" "shared_node2" {
+  machine_id  = vboxweb_machine.node2.id
+  controller  = "SATA"
+  port        = 1
+  medium      = "/var/lib/vbox/disks/shared-fixed.vdi"
+  access_mode = "shareable"
+}
@@ -0,0 +1,16 @@
+# Add an NVMe controller and attach a data disk to it
+resource "vboxweb_storage_controller" "nvme" {
+  machine_id = vboxweb_machine.example.id
+  name       = "NVMe"
+  bus        = "NVMe"
+  port_count = 2
+  # The disks on it only hold data
+  bootable = false
+}
+
+resource "vboxweb_storage_attachment" "data" {
+  machine_id = vboxweb_machine.example.id
+  controller = vboxweb_storage_controller.nvme.name
+  port       = 0
+  medium     = "/var/lib/vbox/disks/data.vdi"
+}
@@ -0,0 +1,13 @@
+# Floppy drives need a floppy controller
+resource "vboxweb_storage_controller" "floppy" {
+  machine_id = vboxweb_machine.example.id
+  name       = "Floppy"
+  bus        = "Floppy"
+}
+
+resource "vboxweb_storage_attachment" "floppy" {
+  machine_id = vboxweb_machine.example.id
+  controller = vboxweb_storage_controller.floppy.name
+  port       = 0
+  type       = "floppy"
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+type hostInfoDataSource struct {
+	client *vbox.Client
+}
+
+type hostInfoDataSourceModel struct {
+	Version        types.String `tfsdk:"version"`
+	APIVersion     types.String `tfsdk:"api_version"`
+	OS             types.String `tfsdk:"os"`
+	OSVersion      types.String `tfsdk:"os_version"`
+	ProcessorCount types.Int64  `tfsdk:"processor_count"`
+	MemoryMB       types.Int64  `tfsdk:"memory_mb"`
+	ID             types.String `tfsdk:"id"`
+}
+
+func NewHostInfoDataSource() datasource.DataSource {
+	return &hostInfoDataSource{}
+}
+
+func (d *hostInfoDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_host_info"
+}
+
+func (d *hostInfoDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*providerData).client
+}
+
+func (d *hostInfoDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reports the VirtualBox version and the capacity of the VirtualBox host, e.g. to size VMs to the host or to only use features its version supports.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The VirtualBox version.",
+			},
+			"version": schema.StringAttribute{
+				Computed:    true,
+				Description: "Full VirtualBox version, e.g. '7.1.4'. Builds with a suffix report it too, e.g. '7.1.4_Ubuntu'.",
+			},
+			"api_version": schema.StringAttribute{
+				Computed:    true,
+				Description: "Version of the VirtualBox API, with an underscore, e.g. '7_1'.",
+			},
+			"os": schema.StringAttribute{
+				Computed:    true,
+				Description: "Operating system of the host, e.g. 'Linux', 'Windows' or 'Darwin'.",
+			},
+			"os_version": schema.StringAttribute{
+				Computed:    true,
+				Description: "Version of the host's operating system, e.g. its kernel version.",
+			},
+			"processor_count": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Number of CPUs of the host.",
+			},
+			"memory_mb": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Physical memory of the host in MB.",
+			},
+		},
+	}
+}
+
+func (d *hostInfoDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+	info, err := d.client.GetHostInfo(ctx)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to read VirtualBox host info", err)
+		return
+	}
+
+	state := hostInfoData(info)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// hostInfoData converts host info to its data source model.
+func hostInfoData(info *vbox.HostInfo) hostInfoDataSourceModel {
+	return hostInfoDataSourceModel{
+		Version:        types.StringValue(info.Version),
+		APIVersion:     types.StringValue(info.APIVersion),
+		OS:             types.StringValue(info.OS),
+		OSVersion:      types.StringValue(info.OSVersion),
+		ProcessorCount: types.Int64Value(int64(info.ProcessorCount)),
+		MemoryMB:       types.Int64Value(int64(info.MemoryMB)),
+		ID:             types.StringValue(info.Version),
+	}
+}
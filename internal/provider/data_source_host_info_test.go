@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+func TestHostInfoDataSourceMetadata(t *testing.T) {
+	d := NewHostInfoDataSource()
+
+	req := datasource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &datasource.MetadataResponse{}
+
+	d.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_host_info" {
+		t.Errorf("expected TypeName 'vboxweb_host_info', got %q", resp.TypeName)
+	}
+}
+
+func TestHostInfoDataSourceSchema(t *testing.T) {
+	d := NewHostInfoDataSource()
+
+	req := datasource.SchemaRequest{}
+	resp := &datasource.SchemaResponse{}
+
+	d.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	for _, attrName := range []string{"id", "version", "api_version", "os", "os_version", "processor_count", "memory_mb"} {
+		if attr, ok := resp.Schema.Attributes[attrName]; !ok || !attr.IsComputed() || attr.IsOptional() {
+			t.Errorf("expected computed only %q attribute in schema", attrName)
+		}
+	}
+}
+
+func TestHostInfoData(t *testing.T) {
+	got := hostInfoData(&vbox.HostInfo{
+		Version:        "7.1.4",
+		APIVersion:     "7_1",
+		OS:             "Linux",
+		OSVersion:      "6.8.0-45-generic",
+		ProcessorCount: 16,
+		MemoryMB:       65536,
+	})
+
+	if v := got.Version.ValueString(); v != "7.1.4" || got.ID.ValueString() != v {
+		t.Errorf("version = %q, id = %q, want 7.1.4", v, got.ID.ValueString())
+	}
+	if v := got.APIVersion.ValueString(); v != "7_1" {
+		t.Errorf("api_version = %q, want 7_1", v)
+	}
+	if got.ProcessorCount.ValueInt64() != 16 || got.MemoryMB.ValueInt64() != 65536 {
+		t.Errorf("processor_count = %v, memory_mb = %v, want 16 and 65536", got.ProcessorCount, got.MemoryMB)
+	}
+}
@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+type hostNetworkInterfacesDataSource struct {
+	client *vbox.Client
+}
+
+type hostNetworkInterfacesDataSourceModel struct {
+	RequireUp  []types.String                  `tfsdk:"require_up"`
+	Interfaces []hostNetworkInterfaceDataModel `tfsdk:"interfaces"`
+	ID         types.String                    `tfsdk:"id"`
+}
+
+type hostNetworkInterfaceDataModel struct {
+	Name       types.String `tfsdk:"name"`
+	Status     types.String `tfsdk:"status"`
+	IPAddress  types.String `tfsdk:"ip_address"`
+	MACAddress types.String `tfsdk:"mac_address"`
+	Wireless   types.Bool   `tfsdk:"wireless"`
+}
+
+func NewHostNetworkInterfacesDataSource() datasource.DataSource {
+	return &hostNetworkInterfacesDataSource{}
+}
+
+func (d *hostNetworkInterfacesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_host_network_interfaces"
+}
+
+func (d *hostNetworkInterfacesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*providerData).client
+}
+
+func (d *hostNetworkInterfacesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Lists the network interfaces of the VirtualBox host that network adapters can be bridged to.
+
+VirtualBox accepts an adapter bridged to an interface that is down, such as a disconnected Wi-Fi
+interface, and the VM then has no network. Use require_up to fail the plan instead, with an error
+naming the interfaces that are up.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The names of the listed interfaces, comma separated.",
+			},
+			"require_up": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Names of interfaces that must exist and be up. Reading the data source fails otherwise.",
+			},
+			"interfaces": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Bridged interfaces of the host, ordered by name.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Interface name, as used for a bridged adapter.",
+						},
+						"status": schema.StringAttribute{
+							Computed:    true,
+							Description: "Link status: 'up', 'down' or 'unknown'.",
+						},
+						"ip_address": schema.StringAttribute{
+							Computed:    true,
+							Description: "IPv4 address of the interface; empty if it has none.",
+						},
+						"mac_address": schema.StringAttribute{
+							Computed:    true,
+							Description: "MAC address of the interface.",
+						},
+						"wireless": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the interface is a wireless one.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *hostNetworkInterfacesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var cfg hostNetworkInterfacesDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ifaces, err := d.client.ReadBridgedInterfaces(ctx)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to read host network interfaces", err)
+		return
+	}
+
+	for i, name := range cfg.RequireUp {
+		if err := vbox.CheckBridgedInterfaceUp(name.ValueString(), ifaces); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("require_up").AtListIndex(i), "Host network interface not up", err.Error())
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	names := make([]string, len(ifaces))
+	for i, iface := range ifaces {
+		names[i] = iface.Name
+	}
+	cfg.ID = types.StringValue(strings.Join(names, ","))
+	cfg.Interfaces = hostNetworkInterfaceData(ifaces)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &cfg)...)
+}
+
+// hostNetworkInterfaceData converts host network interfaces to their data
+// source model.
+func hostNetworkInterfaceData(ifaces []vboxapi.HostNetworkInterface) []hostNetworkInterfaceDataModel {
+	out := make([]hostNetworkInterfaceDataModel, 0, len(ifaces))
+	for _, iface := range ifaces {
+		out = append(out, hostNetworkInterfaceDataModel{
+			Name:       types.StringValue(iface.Name),
+			Status:     types.StringValue(strings.ToLower(string(iface.Status))),
+			IPAddress:  types.StringValue(iface.IPAddress),
+			MACAddress: types.StringValue(iface.HardwareAddress),
+			Wireless:   types.BoolValue(iface.Wireless),
+		})
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestHostNetworkInterfacesDataSourceMetadata(t *testing.T) {
+	d := NewHostNetworkInterfacesDataSource()
+
+	req := datasource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &datasource.MetadataResponse{}
+
+	d.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_host_network_interfaces" {
+		t.Errorf("expected TypeName 'vboxweb_host_network_interfaces', got %q", resp.TypeName)
+	}
+}
+
+func TestHostNetworkInterfacesDataSourceSchema(t *testing.T) {
+	d := NewHostNetworkInterfacesDataSource()
+
+	req := datasource.SchemaRequest{}
+	resp := &datasource.SchemaResponse{}
+
+	d.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	if attr, ok := schema.Attributes["require_up"]; !ok || !attr.IsOptional() {
+		t.Error("expected optional 'require_up' attribute in schema")
+	}
+	for _, attrName := range []string{"id", "interfaces"} {
+		if attr, ok := schema.Attributes[attrName]; !ok || !attr.IsComputed() {
+			t.Errorf("expected computed %q attribute in schema", attrName)
+		}
+	}
+}
+
+func TestHostNetworkInterfaceData(t *testing.T) {
+	got := hostNetworkInterfaceData([]vboxapi.HostNetworkInterface{
+		{Name: "eth0", Status: vboxapi.HostNetworkInterfaceStatusUp, IPAddress: "192.168.1.10", HardwareAddress: "00:11:22:33:44:55"},
+		{Name: "wlan0", Status: vboxapi.HostNetworkInterfaceStatusDown, Wireless: true},
+	})
+
+	if len(got) != 2 {
+		t.Fatalf("got %d interfaces, want 2", len(got))
+	}
+	if status := got[0].Status.ValueString(); status != "up" {
+		t.Errorf("status = %q, want %q", status, "up")
+	}
+	if ip := got[0].IPAddress.ValueString(); ip != "192.168.1.10" {
+		t.Errorf("ip_address = %q, want %q", ip, "192.168.1.10")
+	}
+	if status := got[1].Status.ValueString(); status != "down" || !got[1].Wireless.ValueBool() {
+		t.Errorf("second interface = %+v, want a down wireless interface", got[1])
+	}
+
+	if empty := hostNetworkInterfaceData(nil); empty == nil {
+		t.Error("expected an empty interface list, got nil")
+	}
+}
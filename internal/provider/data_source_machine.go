@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+type machineDataSource struct {
+	client *vbox.Client
+}
+
+type machineDataSourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	Name     types.String `tfsdk:"name"`
+	State    types.String `tfsdk:"state"`
+	OSTypeID types.String `tfsdk:"os_type_id"`
+}
+
+func NewMachineDataSource() datasource.DataSource {
+	return &machineDataSource{}
+}
+
+func (d *machineDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine"
+}
+
+func (d *machineDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*providerData).client
+}
+
+func (d *machineDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Looks up an existing VirtualBox VM by name or UUID, without managing it.
+
+Use it to reference a VM managed outside Terraform, for example to attach vboxweb_nat_port_forward
+rules to a base VM without importing it as a vboxweb_machine.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "VirtualBox machine ID (UUID) of the VM to look up. Exactly one of id and name must be set.",
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("name")),
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"name": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Name of the VM to look up. Exactly one of id and name must be set.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"state": schema.StringAttribute{
+				Computed:    true,
+				Description: "VirtualBox machine state at read time, e.g. 'PoweredOff' or 'Running'.",
+			},
+			"os_type_id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Guest OS type of the VM, e.g. 'Ubuntu_64'.",
+			},
+		},
+	}
+}
+
+func (d *machineDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var cfg machineDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// findMachine accepts either a UUID or a name.
+	lookup := cfg.ID.ValueString()
+	if lookup == "" {
+		lookup = cfg.Name.ValueString()
+	}
+
+	info, err := d.client.GetMachineInfoByID(ctx, lookup)
+	if err != nil {
+		if vbox.IsNotFound(err) {
+			resp.Diagnostics.AddError("VM not found", fmt.Sprintf("No VM with ID or name %q is registered with VirtualBox.", lookup))
+			return
+		}
+		addClientError(&resp.Diagnostics, "Failed to read VM", err)
+		return
+	}
+	if !info.Accessible {
+		resp.Diagnostics.AddError(
+			"VM is inaccessible",
+			fmt.Sprintf("VirtualBox could not load the settings of VM %s: %s", info.ID, info.AccessError),
+		)
+		return
+	}
+
+	setMachineData(&cfg, info)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &cfg)...)
+}
+
+// setMachineData records the looked up VM in m.
+func setMachineData(m *machineDataSourceModel, info *vbox.MachineInfo) {
+	m.ID = types.StringValue(info.ID)
+	m.Name = types.StringValue(info.Name)
+	m.State = types.StringValue(info.State)
+	m.OSTypeID = types.StringValue(info.OSTypeID)
+}
@@ -0,0 +1,365 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+type machineHCLDataSource struct {
+	client *vbox.Client
+}
+
+type machineHCLDataSourceModel struct {
+	MachineID types.String `tfsdk:"machine_id"`
+	HCL       types.String `tfsdk:"hcl"`
+	ID        types.String `tfsdk:"id"`
+}
+
+// natRuleHCL is a NAT port forwarding rule of an adapter slot.
+type natRuleHCL struct {
+	Slot     uint32
+	Redirect vboxapi.NATRedirect
+}
+
+func NewMachineHCLDataSource() datasource.DataSource {
+	return &machineHCLDataSource{}
+}
+
+func (d *machineHCLDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_hcl"
+}
+
+func (d *machineHCLDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*providerData).client
+}
+
+func (d *machineHCLDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Generates suggested Terraform configuration for an existing VirtualBox VM: a vboxweb_machine, its
+NAT port forwarding rules as vboxweb_nat_port_forward resources, and import blocks for all of them.
+
+Use it to adopt VMs created outside Terraform. The output is advisory: review it before use. It is
+best effort, so settings that cannot be read are left out with a warning.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The machine ID.",
+			},
+			"machine_id": schema.StringAttribute{
+				Required:    true,
+				Description: "VirtualBox machine ID (UUID) or name.",
+			},
+			"hcl": schema.StringAttribute{
+				Computed:    true,
+				Description: "Suggested configuration, in HCL.",
+			},
+		},
+	}
+}
+
+func (d *machineHCLDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var cfg machineHCLDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	lookup := cfg.MachineID.ValueString()
+	info, err := d.client.GetMachineInfoByID(ctx, lookup)
+	if err != nil {
+		if vbox.IsNotFound(err) {
+			resp.Diagnostics.AddError("VM not found", fmt.Sprintf("No VM with ID or name %q is registered with VirtualBox.", lookup))
+			return
+		}
+		addClientError(&resp.Diagnostics, "Failed to read VM", err)
+		return
+	}
+	if !info.Accessible {
+		resp.Diagnostics.AddError(
+			"VM is inaccessible",
+			fmt.Sprintf("VirtualBox could not load the settings of VM %s: %s", info.ID, info.AccessError),
+		)
+		return
+	}
+
+	settings, err := d.client.ReadMachineSettings(ctx, info.ID)
+	if err != nil {
+		resp.Diagnostics.AddWarning("VM settings left out", fmt.Sprintf("The settings of VM %s could not be read, so the generated configuration leaves them out: %s", info.ID, err))
+		settings = nil
+	}
+
+	rules, err := d.readNATRules(ctx, info.ID)
+	if err != nil {
+		resp.Diagnostics.AddWarning("NAT rules left out", fmt.Sprintf("The NAT port forwarding rules of VM %s could not be read, so the generated configuration leaves them out: %s", info.ID, err))
+		rules = nil
+	}
+
+	cfg.ID = types.StringValue(info.ID)
+	cfg.HCL = types.StringValue(machineHCL(info, settings, rules))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &cfg)...)
+}
+
+// readNATRules returns the NAT port forwarding rules of the enabled NAT
+// adapters of a VM, ordered by slot.
+func (d *machineHCLDataSource) readNATRules(ctx context.Context, machineID string) ([]natRuleHCL, error) {
+	adapters, err := d.client.ReadNetworkAdapters(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+	var rules []natRuleHCL
+	for _, a := range adapters {
+		if a.AttachmentType != vboxapi.NetworkAttachmentTypeNAT || a.NATRedirectCount == 0 {
+			continue
+		}
+		redirects, err := d.client.GetAllNATRedirects(ctx, machineID, a.Slot)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range redirects {
+			rules = append(rules, natRuleHCL{Slot: a.Slot, Redirect: r})
+		}
+	}
+	return rules, nil
+}
+
+// machineHCLSettings are the vboxweb_machine attributes generated from the
+// VM settings, in output order.
+var machineHCLSettings = []string{
+	"memory_mb",
+	"cpu_count",
+	"page_fusion_enabled",
+	"execution_engine",
+	"graphics_controller",
+	"vram_mb",
+	"accelerate_3d",
+	"monitor_count",
+	"apic_enabled",
+	"x2apic_enabled",
+	"pae_enabled",
+	"long_mode",
+	"boot_menu_mode",
+	"autostart_enabled",
+	"autostart_delay",
+	"clipboard_file_transfers",
+	"disable_time_sync",
+	"recording_enabled",
+	"recording_screens",
+	"recording_file",
+	"recording_format",
+	"recording_fps",
+	"io_cache_enabled",
+	"vrde_keyboard_layout",
+	"owner",
+}
+
+// machineHCL renders the suggested configuration of a VM. settings may be
+// nil, in which case only the VM's identity and state are rendered.
+func machineHCL(info *vbox.MachineInfo, settings *vbox.MachineSettings, rules []natRuleHCL) string {
+	// Resource names must start with a letter.
+	label := hclLabel(info.Name)
+	if label == "" || label[0] < 'a' || label[0] > 'z' {
+		label = "vm_" + label
+	}
+	machineAddr := "vboxweb_machine." + label
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated from VM %q (%s). Review before use.\n\n", info.Name, info.ID)
+	writeHCLBlock(&b, "import", [][2]string{
+		{"to", machineAddr},
+		{"id", hclString(info.ID)},
+	})
+
+	// Like import, keep a Saved VM stopped with savestate.
+	desiredState := "stopped"
+	switch normalized := vbox.NormalizeMachineState(info.State); normalized {
+	case "started", "paused":
+		desiredState = normalized
+	}
+	machine := [][2]string{{"name", hclString(info.Name)}}
+	if info.OSTypeID != "" {
+		machine = append(machine, [2]string{"os_type_id", hclString(info.OSTypeID)})
+	}
+	machine = append(machine, [2]string{"state", hclString(desiredState)})
+	if info.State == vboxapi.MachineStateSaved {
+		machine = append(machine, [2]string{"stop_method", hclString(vbox.StopMethodSaveState)})
+	}
+	if settings != nil {
+		var m machineModel
+		setMachineSettings(&m, settings)
+		values := machineHCLValues(&m)
+		for _, name := range machineHCLSettings {
+			if v, ok := values[name]; ok {
+				machine = append(machine, [2]string{name, v})
+			}
+		}
+	}
+	b.WriteString("\n# The VM this one was cloned from, if any, cannot be read back: set source\n")
+	b.WriteString("# to create it again from the same source.\n")
+	writeHCLBlock(&b, fmt.Sprintf("resource \"vboxweb_machine\" %q", label), machine)
+
+	used := map[string]bool{}
+	for _, rule := range rules {
+		r := rule.Redirect
+		ruleLabel := label + "_" + hclLabel(r.Name)
+		for i := 2; used[ruleLabel]; i++ {
+			ruleLabel = fmt.Sprintf("%s_%s_%d", label, hclLabel(r.Name), i)
+		}
+		used[ruleLabel] = true
+		ruleAddr := "vboxweb_nat_port_forward." + ruleLabel
+
+		b.WriteString("\n")
+		writeHCLBlock(&b, "import", [][2]string{
+			{"to", ruleAddr},
+			{"id", hclString(fmt.Sprintf("%s:%d:%s", info.ID, rule.Slot, r.Name))},
+		})
+		attrs := [][2]string{
+			{"machine_id", machineAddr + ".id"},
+			{"adapter_slot", strconv.FormatUint(uint64(rule.Slot), 10)},
+			{"name", hclString(r.Name)},
+			{"protocol", hclString(strings.ToLower(string(r.Protocol)))},
+			{"host_ip", hclString(r.HostIP)},
+			{"host_port", strconv.FormatUint(uint64(r.HostPort), 10)},
+		}
+		if r.GuestIP != "" {
+			attrs = append(attrs, [2]string{"guest_ip", hclString(r.GuestIP)})
+		}
+		attrs = append(attrs, [2]string{"guest_port", strconv.FormatUint(uint64(r.GuestPort), 10)})
+		b.WriteString("\n")
+		writeHCLBlock(&b, fmt.Sprintf("resource \"vboxweb_nat_port_forward\" %q", ruleLabel), attrs)
+	}
+	return b.String()
+}
+
+// machineHCLValues renders the known settings of m, by attribute name.
+// Recording options are only rendered for VMs being recorded, and the file
+// only when a single screen is.
+func machineHCLValues(m *machineModel) map[string]string {
+	values := map[string]attr.Value{
+		"memory_mb":                m.MemoryMB,
+		"cpu_count":                m.CPUCount,
+		"page_fusion_enabled":      m.PageFusionEnabled,
+		"execution_engine":         m.ExecutionEngine,
+		"graphics_controller":      m.GraphicsController,
+		"vram_mb":                  m.VRAMMB,
+		"accelerate_3d":            m.Accelerate3D,
+		"monitor_count":            m.MonitorCount,
+		"apic_enabled":             m.APICEnabled,
+		"x2apic_enabled":           m.X2APICEnabled,
+		"pae_enabled":              m.PAEEnabled,
+		"long_mode":                m.LongMode,
+		"boot_menu_mode":           m.BootMenuMode,
+		"autostart_enabled":        m.AutostartEnabled,
+		"autostart_delay":          m.AutostartDelay,
+		"clipboard_file_transfers": m.ClipboardFileTransfers,
+		"disable_time_sync":        m.DisableTimeSync,
+		"recording_enabled":        m.RecordingEnabled,
+		"io_cache_enabled":         m.IOCacheEnabled,
+		"vrde_keyboard_layout":     m.VRDEKeyboardLayout,
+		"owner":                    m.Owner,
+	}
+	if m.RecordingEnabled.ValueBool() {
+		values["recording_screens"] = m.RecordingScreens
+		values["recording_format"] = m.RecordingFormat
+		values["recording_fps"] = m.RecordingFPS
+		if len(m.RecordingScreens.Elements()) == 1 {
+			values["recording_file"] = m.RecordingFile
+		}
+	}
+
+	out := make(map[string]string, len(values))
+	for name, v := range values {
+		if s, ok := hclValue(v); ok {
+			out[name] = s
+		}
+	}
+	return out
+}
+
+// hclValue renders a known string, bool, number or set of numbers. Other
+// values, such as null ones, are not rendered.
+func hclValue(v attr.Value) (string, bool) {
+	if v == nil || v.IsNull() || v.IsUnknown() {
+		return "", false
+	}
+	switch v := v.(type) {
+	case types.String:
+		if v.ValueString() == "" {
+			return "", false
+		}
+		return hclString(v.ValueString()), true
+	case types.Bool:
+		return strconv.FormatBool(v.ValueBool()), true
+	case types.Int64:
+		return strconv.FormatInt(v.ValueInt64(), 10), true
+	case types.Set:
+		var elems []int64
+		for _, e := range v.Elements() {
+			if n, ok := e.(types.Int64); ok {
+				elems = append(elems, n.ValueInt64())
+			}
+		}
+		slices.Sort(elems)
+		parts := make([]string, 0, len(elems))
+		for _, n := range elems {
+			parts = append(parts, strconv.FormatInt(n, 10))
+		}
+		return "[" + strings.Join(parts, ", ") + "]", true
+	}
+	return "", false
+}
+
+// writeHCLBlock writes a block of attributes, their equals signs aligned
+// the way terraform fmt does.
+func writeHCLBlock(b *strings.Builder, header string, attrs [][2]string) {
+	width := 0
+	for _, a := range attrs {
+		width = max(width, len(a[0]))
+	}
+	b.WriteString(header + " {\n")
+	for _, a := range attrs {
+		fmt.Fprintf(b, "  %-*s = %s\n", width, a[0], a[1])
+	}
+	b.WriteString("}\n")
+}
+
+// hclString quotes s as an HCL string literal, escaping template sequences.
+func hclString(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+		"${", "$${",
+		"%{", "%%{",
+	)
+	return `"` + r.Replace(s) + `"`
+}
+
+// hclLabel turns a VM or rule name into lower case letters, digits and
+// underscores, for use in resource names.
+func hclLabel(name string) string {
+	var b strings.Builder
+	for _, c := range strings.ToLower(name) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestMachineHCLDataSourceMetadata(t *testing.T) {
+	d := NewMachineHCLDataSource()
+
+	req := datasource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &datasource.MetadataResponse{}
+
+	d.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_machine_hcl" {
+		t.Errorf("expected TypeName 'vboxweb_machine_hcl', got %q", resp.TypeName)
+	}
+}
+
+func TestMachineHCLDataSourceSchema(t *testing.T) {
+	d := NewMachineHCLDataSource()
+
+	req := datasource.SchemaRequest{}
+	resp := &datasource.SchemaResponse{}
+
+	d.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	if attr, ok := schema.Attributes["machine_id"]; !ok || !attr.IsRequired() {
+		t.Error("expected required 'machine_id' attribute in schema")
+	}
+	for _, attrName := range []string{"id", "hcl"} {
+		if attr, ok := schema.Attributes[attrName]; !ok || !attr.IsComputed() {
+			t.Errorf("expected computed %q attribute in schema", attrName)
+		}
+	}
+}
+
+func TestMachineHCL(t *testing.T) {
+	info := &vbox.MachineInfo{ID: "uuid-vm", Name: "web-1", State: vboxapi.MachineStateRunning, OSTypeID: "Ubuntu_64", Accessible: true}
+	enabled := true
+	owner := "team-a"
+	settings := &vbox.MachineSettings{
+		MemoryMB:           4096,
+		CPUCount:           2,
+		GraphicsController: vboxapi.GraphicsControllerVMSVGA,
+		VRAMMB:             128,
+		MonitorCount:       1,
+		Accelerate3D:       &enabled,
+		BootMenuMode:       vboxapi.BootMenuModeDisabled,
+		Owner:              &owner,
+	}
+	rules := []natRuleHCL{
+		{Slot: 0, Redirect: vboxapi.NATRedirect{Name: "ssh", Protocol: vboxapi.NATProtocolTCP, HostPort: 2222, GuestPort: 22}},
+		{Slot: 1, Redirect: vboxapi.NATRedirect{Name: "ssh", Protocol: vboxapi.NATProtocolTCP, HostIP: "127.0.0.1", HostPort: 2223, GuestIP: "10.0.2.15", GuestPort: 22}},
+	}
+
+	got := machineHCL(info, settings, rules)
+
+	for _, want := range []string{
+		"import {\n  to = vboxweb_machine.web_1\n  id = \"uuid-vm\"\n}\n",
+		`resource "vboxweb_machine" "web_1" {`,
+		`  name                = "web-1"`,
+		`  os_type_id          = "Ubuntu_64"`,
+		`  state               = "started"`,
+		`  memory_mb           = 4096`,
+		`  cpu_count           = 2`,
+		`  graphics_controller = "VMSVGA"`,
+		`  vram_mb             = 128`,
+		`  accelerate_3d       = true`,
+		`  boot_menu_mode      = "disabled"`,
+		`  owner               = "team-a"`,
+		`resource "vboxweb_nat_port_forward" "web_1_ssh" {`,
+		`  id = "uuid-vm:0:ssh"`,
+		`  machine_id   = vboxweb_machine.web_1.id`,
+		`  host_port    = 2222`,
+		`resource "vboxweb_nat_port_forward" "web_1_ssh_2" {`,
+		`  host_ip      = "127.0.0.1"`,
+		`  guest_ip     = "10.0.2.15"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("generated HCL does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "recording_") {
+		t.Errorf("expected no recording options for a VM that is not recorded:\n%s", got)
+	}
+
+	// Without settings, only the identity and state are generated.
+	got = machineHCL(&vbox.MachineInfo{ID: "uuid-vm", Name: "1st vm", State: vboxapi.MachineStateSaved}, nil, nil)
+	if !strings.Contains(got, `resource "vboxweb_machine" "vm_1st_vm" {`) || !strings.Contains(got, `state       = "stopped"`) || !strings.Contains(got, `stop_method = "savestate"`) {
+		t.Errorf("unexpected HCL:\n%s", got)
+	}
+	if strings.Contains(got, "vboxweb_nat_port_forward") {
+		t.Errorf("expected no NAT rules:\n%s", got)
+	}
+}
+
+func TestHCLString(t *testing.T) {
+	tests := map[string]string{
+		"plain":            `"plain"`,
+		`say "hi"`:         `"say \"hi\""`,
+		`C:\vms`:           `"C:\\vms"`,
+		"${var.x} %{ if }": `"$${var.x} %%{ if }"`,
+	}
+	for in, want := range tests {
+		if got := hclString(in); got != want {
+			t.Errorf("hclString(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+func TestMachineDataSourceMetadata(t *testing.T) {
+	d := NewMachineDataSource()
+
+	req := datasource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &datasource.MetadataResponse{}
+
+	d.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_machine" {
+		t.Errorf("expected TypeName 'vboxweb_machine', got %q", resp.TypeName)
+	}
+}
+
+func TestMachineDataSourceSchema(t *testing.T) {
+	d := NewMachineDataSource()
+
+	req := datasource.SchemaRequest{}
+	resp := &datasource.SchemaResponse{}
+
+	d.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	for _, attrName := range []string{"id", "name"} {
+		attr, ok := schema.Attributes[attrName]
+		if !ok || !attr.IsOptional() || !attr.IsComputed() {
+			t.Errorf("expected optional and computed %q attribute in schema", attrName)
+		}
+	}
+	for _, attrName := range []string{"state", "os_type_id"} {
+		if attr, ok := schema.Attributes[attrName]; !ok || !attr.IsComputed() {
+			t.Errorf("expected computed %q attribute in schema", attrName)
+		}
+	}
+}
+
+func TestSetMachineData(t *testing.T) {
+	m := machineDataSourceModel{Name: types.StringValue("base-vm"), ID: types.StringNull()}
+	setMachineData(&m, &vbox.MachineInfo{ID: "uuid-vm", Name: "base-vm", State: "PoweredOff", OSTypeID: "Ubuntu_64", Accessible: true})
+
+	want := machineDataSourceModel{
+		ID:       types.StringValue("uuid-vm"),
+		Name:     types.StringValue("base-vm"),
+		State:    types.StringValue("PoweredOff"),
+		OSTypeID: types.StringValue("Ubuntu_64"),
+	}
+	if m != want {
+		t.Errorf("model = %+v, want %+v", m, want)
+	}
+}
@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+type machinesDataSource struct {
+	client *vbox.Client
+}
+
+type machinesDataSourceModel struct {
+	NameRegex types.String        `tfsdk:"name_regex"`
+	Owner     types.String        `tfsdk:"owner"`
+	Machines  []machinesDataModel `tfsdk:"machines"`
+	ID        types.String        `tfsdk:"id"`
+}
+
+type machinesDataModel struct {
+	ID    types.String `tfsdk:"id"`
+	Name  types.String `tfsdk:"name"`
+	State types.String `tfsdk:"state"`
+	Owner types.String `tfsdk:"owner"`
+}
+
+func NewMachinesDataSource() datasource.DataSource {
+	return &machinesDataSource{}
+}
+
+func (d *machinesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machines"
+}
+
+func (d *machinesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*providerData).client
+}
+
+func (d *machinesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Lists the VMs registered with VirtualBox, optionally filtered by name or owner.
+
+Use it to drive for_each over existing VMs, for example to add port forwards to every VM whose
+name matches a pattern.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The IDs of the listed machines, comma separated.",
+			},
+			"name_regex": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only list VMs whose name matches this regular expression (Go RE2 syntax). The match is unanchored: use ^ and $ to match the whole name.",
+				Validators: []validator.String{
+					regexValidator{},
+				},
+			},
+			"owner": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only list VMs with this owner, as set by vboxweb_machine's owner attribute.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"machines": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Matching VMs, ordered by name. VMs whose settings VirtualBox cannot load are left out.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:    true,
+							Description: "VirtualBox machine ID (UUID).",
+						},
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "VM name.",
+						},
+						"state": schema.StringAttribute{
+							Computed:    true,
+							Description: "VirtualBox machine state at read time, e.g. 'PoweredOff' or 'Running'.",
+						},
+						"owner": schema.StringAttribute{
+							Computed:    true,
+							Description: "Owner of the VM, or an empty string if it has none.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *machinesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var cfg machinesDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Checked by regexValidator.
+	var nameRegex *regexp.Regexp
+	if s := cfg.NameRegex.ValueString(); s != "" {
+		nameRegex = regexp.MustCompile(s)
+	}
+
+	machines, err := d.client.ListMachines(ctx)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to list VMs", err)
+		return
+	}
+
+	cfg.Machines = machinesData(machines, nameRegex, cfg.Owner.ValueString())
+	ids := make([]string, 0, len(cfg.Machines))
+	for _, m := range cfg.Machines {
+		ids = append(ids, m.ID.ValueString())
+	}
+	cfg.ID = types.StringValue(strings.Join(ids, ","))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &cfg)...)
+}
+
+// machinesData converts the accessible machines matching nameRegex and owner
+// to their data source model. A nil nameRegex or an empty owner matches all
+// machines.
+func machinesData(machines []vbox.MachineInfo, nameRegex *regexp.Regexp, owner string) []machinesDataModel {
+	out := make([]machinesDataModel, 0, len(machines))
+	for _, m := range machines {
+		if !m.Accessible {
+			continue
+		}
+		if nameRegex != nil && !nameRegex.MatchString(m.Name) {
+			continue
+		}
+		if owner != "" && m.Owner != owner {
+			continue
+		}
+		out = append(out, machinesDataModel{
+			ID:    types.StringValue(m.ID),
+			Name:  types.StringValue(m.Name),
+			State: types.StringValue(m.State),
+			Owner: types.StringValue(m.Owner),
+		})
+	}
+	return out
+}
+
+// regexValidator checks that a string is a valid Go regular expression.
+type regexValidator struct{}
+
+func (v regexValidator) Description(_ context.Context) string {
+	return "value must be a valid regular expression"
+}
+
+func (v regexValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v regexValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if _, err := regexp.Compile(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid regular expression",
+			fmt.Sprintf("%s: %v", v.Description(ctx), err))
+	}
+}
@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+func TestMachinesDataSourceMetadata(t *testing.T) {
+	d := NewMachinesDataSource()
+
+	req := datasource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &datasource.MetadataResponse{}
+
+	d.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_machines" {
+		t.Errorf("expected TypeName 'vboxweb_machines', got %q", resp.TypeName)
+	}
+}
+
+func TestMachinesDataSourceSchema(t *testing.T) {
+	d := NewMachinesDataSource()
+
+	req := datasource.SchemaRequest{}
+	resp := &datasource.SchemaResponse{}
+
+	d.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	for _, attrName := range []string{"name_regex", "owner"} {
+		if attr, ok := schema.Attributes[attrName]; !ok || !attr.IsOptional() {
+			t.Errorf("expected optional %q attribute in schema", attrName)
+		}
+	}
+	for _, attrName := range []string{"id", "machines"} {
+		if attr, ok := schema.Attributes[attrName]; !ok || !attr.IsComputed() {
+			t.Errorf("expected computed %q attribute in schema", attrName)
+		}
+	}
+}
+
+func TestMachinesData(t *testing.T) {
+	machines := []vbox.MachineInfo{
+		{ID: "uuid-broken", AccessError: "Could not find file broken.vbox"},
+		{ID: "uuid-db", Name: "db-1", State: "PoweredOff", Accessible: true},
+		{ID: "uuid-web1", Name: "web-1", State: "Running", Owner: "team-a", Accessible: true},
+		{ID: "uuid-web2", Name: "web-2", State: "Running", Owner: "team-b", Accessible: true},
+	}
+
+	tests := []struct {
+		name      string
+		nameRegex *regexp.Regexp
+		owner     string
+		want      []string
+	}{
+		{"no filter", nil, "", []string{"uuid-db", "uuid-web1", "uuid-web2"}},
+		{"name regex", regexp.MustCompile(`^web-`), "", []string{"uuid-web1", "uuid-web2"}},
+		{"owner", nil, "team-a", []string{"uuid-web1"}},
+		{"both", regexp.MustCompile(`^db-`), "team-a", []string{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := machinesData(machines, tc.nameRegex, tc.owner)
+			ids := make([]string, 0, len(got))
+			for _, m := range got {
+				ids = append(ids, m.ID.ValueString())
+			}
+			if !slices.Equal(ids, tc.want) {
+				t.Errorf("ids = %v, want %v", ids, tc.want)
+			}
+		})
+	}
+
+	got := machinesData(machines[2:3], nil, "")
+	want := machinesDataModel{
+		ID:    types.StringValue("uuid-web1"),
+		Name:  types.StringValue("web-1"),
+		State: types.StringValue("Running"),
+		Owner: types.StringValue("team-a"),
+	}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("machines = %+v, want [%+v]", got, want)
+	}
+}
+
+func TestRegexValidator(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"^web-", false},
+		{"web-[0-9]+$", false},
+		{"web-(", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			req := validator.StringRequest{Path: path.Root("test"), ConfigValue: types.StringValue(tc.input)}
+			resp := &validator.StringResponse{}
+			regexValidator{}.ValidateString(context.Background(), req, resp)
+			if resp.Diagnostics.HasError() != tc.wantErr {
+				t.Errorf("ValidateString(%q) error = %v, wantErr %v", tc.input, resp.Diagnostics, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+type networkAdaptersDataSource struct {
+	client *vbox.Client
+}
+
+type networkAdaptersDataSourceModel struct {
+	MachineID types.String              `tfsdk:"machine_id"`
+	Adapters  []networkAdapterDataModel `tfsdk:"adapters"`
+	ID        types.String              `tfsdk:"id"`
+}
+
+type networkAdapterDataModel struct {
+	Slot             types.Int64  `tfsdk:"slot"`
+	AttachmentType   types.String `tfsdk:"attachment_type"`
+	AdapterType      types.String `tfsdk:"adapter_type"`
+	MACAddress       types.String `tfsdk:"mac_address"`
+	CableConnected   types.Bool   `tfsdk:"cable_connected"`
+	LineSpeedKbps    types.Int64  `tfsdk:"line_speed_kbps"`
+	NATRedirectCount types.Int64  `tfsdk:"nat_redirect_count"`
+}
+
+func NewNetworkAdaptersDataSource() datasource.DataSource {
+	return &networkAdaptersDataSource{}
+}
+
+func (d *networkAdaptersDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_network_adapters"
+}
+
+func (d *networkAdaptersDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*providerData).client
+}
+
+func (d *networkAdaptersDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Lists the enabled network adapters of a VirtualBox VM.
+
+Use it to audit the networking of a clone before configuring vboxweb_nat_port_forward rules, for
+example to find its NAT adapter slot.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The machine ID.",
+			},
+			"machine_id": schema.StringAttribute{
+				Required:    true,
+				Description: "VirtualBox machine ID (UUID) or name.",
+			},
+			"adapters": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Enabled network adapters of the VM, ordered by slot. Disabled slots are left out.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"slot": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Adapter slot, as used by vboxweb_nat_port_forward's adapter_slot.",
+						},
+						"attachment_type": schema.StringAttribute{
+							Computed:    true,
+							Description: "What the adapter is attached to: 'Null', 'NAT', 'Bridged', 'Internal', 'HostOnly', 'Generic', 'NATNetwork', 'Cloud' or 'HostOnlyNetwork'.",
+						},
+						"adapter_type": schema.StringAttribute{
+							Computed:    true,
+							Description: "Hardware the adapter emulates: 'Am79C970A', 'Am79C973', 'I82540EM', 'I82543GC', 'I82545EM' or 'Virtio'. Virtio needs a virtio-net driver in the guest.",
+						},
+						"mac_address": schema.StringAttribute{
+							Computed:    true,
+							Description: "MAC address, as 12 hexadecimal digits without separators.",
+						},
+						"cable_connected": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the virtual cable is plugged in.",
+						},
+						"line_speed_kbps": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Speed the adapter reports to the guest, in kbps.",
+						},
+						"nat_redirect_count": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Number of port forwarding rules of a NAT adapter; 0 for other attachment types.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *networkAdaptersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var cfg networkAdaptersDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	adapters, err := d.client.ReadNetworkAdapters(ctx, cfg.MachineID.ValueString())
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to read VM network adapters", err)
+		return
+	}
+
+	cfg.ID = cfg.MachineID
+	cfg.Adapters = networkAdapterData(adapters)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &cfg)...)
+}
+
+// networkAdapterData converts network adapters to their data source model.
+func networkAdapterData(adapters []vbox.NetworkAdapterInfo) []networkAdapterDataModel {
+	out := make([]networkAdapterDataModel, 0, len(adapters))
+	for _, a := range adapters {
+		out = append(out, networkAdapterDataModel{
+			Slot:             types.Int64Value(int64(a.Slot)),
+			AttachmentType:   types.StringValue(string(a.AttachmentType)),
+			AdapterType:      types.StringValue(string(a.AdapterType)),
+			MACAddress:       types.StringValue(a.MACAddress),
+			CableConnected:   types.BoolValue(a.CableConnected),
+			LineSpeedKbps:    types.Int64Value(int64(a.LineSpeedKbps)),
+			NATRedirectCount: types.Int64Value(int64(a.NATRedirectCount)),
+		})
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestNetworkAdaptersDataSourceMetadata(t *testing.T) {
+	d := NewNetworkAdaptersDataSource()
+
+	req := datasource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &datasource.MetadataResponse{}
+
+	d.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_network_adapters" {
+		t.Errorf("expected TypeName 'vboxweb_network_adapters', got %q", resp.TypeName)
+	}
+}
+
+func TestNetworkAdaptersDataSourceSchema(t *testing.T) {
+	d := NewNetworkAdaptersDataSource()
+
+	req := datasource.SchemaRequest{}
+	resp := &datasource.SchemaResponse{}
+
+	d.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	if attr, ok := schema.Attributes["machine_id"]; !ok || !attr.IsRequired() {
+		t.Error("expected required 'machine_id' attribute in schema")
+	}
+	for _, attrName := range []string{"id", "adapters"} {
+		if attr, ok := schema.Attributes[attrName]; !ok || !attr.IsComputed() {
+			t.Errorf("expected computed %q attribute in schema", attrName)
+		}
+	}
+}
+
+func TestNetworkAdapterData(t *testing.T) {
+	got := networkAdapterData([]vbox.NetworkAdapterInfo{
+		{Slot: 0, AttachmentType: vboxapi.NetworkAttachmentTypeNAT, MACAddress: "080027000000", CableConnected: true, NATRedirectCount: 2},
+		{Slot: 2, AttachmentType: vboxapi.NetworkAttachmentTypeBridged, AdapterType: vboxapi.NetworkAdapterTypeVirtio, MACAddress: "080027000002"},
+	})
+
+	if len(got) != 2 {
+		t.Fatalf("got %d adapters, want 2", len(got))
+	}
+	if slot := got[1].Slot.ValueInt64(); slot != 2 {
+		t.Errorf("slot = %d, want 2", slot)
+	}
+	if typ := got[0].AttachmentType.ValueString(); typ != "NAT" {
+		t.Errorf("attachment type = %q, want %q", typ, "NAT")
+	}
+	if typ := got[1].AdapterType.ValueString(); typ != "Virtio" {
+		t.Errorf("adapter type = %q, want %q", typ, "Virtio")
+	}
+	if n := got[0].NATRedirectCount.ValueInt64(); n != 2 {
+		t.Errorf("nat_redirect_count = %d, want 2", n)
+	}
+	if got[1].CableConnected.ValueBool() {
+		t.Error("expected the second adapter's cable to be disconnected")
+	}
+
+	if empty := networkAdapterData(nil); empty == nil {
+		t.Error("expected an empty adapter list, got nil")
+	}
+}
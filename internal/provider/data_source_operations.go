@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+type operationsDataSource struct {
+	client *vbox.Client
+}
+
+type operationsDataSourceModel struct {
+	ProgressRefs []types.String       `tfsdk:"progress_refs"`
+	WaitTimeout  types.String         `tfsdk:"wait_timeout"`
+	Operations   []operationDataModel `tfsdk:"operations"`
+	ID           types.String         `tfsdk:"id"`
+}
+
+type operationDataModel struct {
+	ProgressRef          types.String `tfsdk:"progress_ref"`
+	Description          types.String `tfsdk:"description"`
+	OperationDescription types.String `tfsdk:"operation_description"`
+	Percent              types.Int64  `tfsdk:"percent"`
+	Cancelable           types.Bool   `tfsdk:"cancelable"`
+	Completed            types.Bool   `tfsdk:"completed"`
+	ResultCode           types.Int64  `tfsdk:"result_code"`
+	ErrorText            types.String `tfsdk:"error_text"`
+}
+
+func NewOperationsDataSource() datasource.DataSource {
+	return &operationsDataSource{}
+}
+
+func (d *operationsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_operations"
+}
+
+func (d *operationsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*providerData).client
+}
+
+func (d *operationsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Reports the state of VirtualBox progress objects, the handles of long-running operations such as clones and snapshots.
+
+This is a diagnostic tool. VirtualBox cannot list the operations in flight for a machine, so the
+progress references must be known, for example from the web service log. A reference is only
+valid while the web service session that started the operation is alive.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The progress references, comma separated.",
+			},
+			"progress_refs": schema.ListAttribute{
+				Required:    true,
+				ElementType: types.StringType,
+				Description: "VirtualBox managed object references of the progress objects to report.",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"wait_timeout": schema.StringAttribute{
+				Optional: true,
+				Description: "How long to wait for each operation to complete before reporting it, e.g. '5m'. " +
+					"An operation still running afterwards is reported as is. By default operations are reported without waiting.",
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"operations": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "State of each operation, in the order of progress_refs.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"progress_ref": schema.StringAttribute{
+							Computed:    true,
+							Description: "Progress reference.",
+						},
+						"description": schema.StringAttribute{
+							Computed:    true,
+							Description: "Description of the whole operation.",
+						},
+						"operation_description": schema.StringAttribute{
+							Computed:    true,
+							Description: "Description of the step in progress.",
+						},
+						"percent": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Overall completion, from 0 to 100.",
+						},
+						"cancelable": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the operation can be canceled.",
+						},
+						"completed": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the operation has completed, successfully or not.",
+						},
+						"result_code": schema.Int64Attribute{
+							Computed:    true,
+							Description: "VirtualBox result code once completed, 0 on success.",
+						},
+						"error_text": schema.StringAttribute{
+							Computed:    true,
+							Description: "Error message of a failed operation. Empty otherwise.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *operationsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var cfg operationsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var wait time.Duration
+	if !cfg.WaitTimeout.IsNull() {
+		// The value is checked by durationValidator.
+		wait, _ = time.ParseDuration(cfg.WaitTimeout.ValueString())
+	}
+
+	refs := make([]string, 0, len(cfg.ProgressRefs))
+	cfg.Operations = make([]operationDataModel, 0, len(cfg.ProgressRefs))
+	for _, ref := range cfg.ProgressRefs {
+		info, err := d.client.ReadProgress(ctx, ref.ValueString(), wait)
+		if err != nil {
+			addClientError(&resp.Diagnostics, "Failed to read operation", err)
+			return
+		}
+		refs = append(refs, info.Ref)
+		cfg.Operations = append(cfg.Operations, operationData(info))
+	}
+
+	cfg.ID = types.StringValue(strings.Join(refs, ","))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &cfg)...)
+}
+
+// operationData converts a progress to its data source model.
+func operationData(info *vbox.ProgressInfo) operationDataModel {
+	return operationDataModel{
+		ProgressRef:          types.StringValue(info.Ref),
+		Description:          types.StringValue(info.Description),
+		OperationDescription: types.StringValue(info.Operation),
+		Percent:              types.Int64Value(int64(info.Percent)),
+		Cancelable:           types.BoolValue(info.Cancelable),
+		Completed:            types.BoolValue(info.Completed),
+		ResultCode:           types.Int64Value(int64(info.ResultCode)),
+		ErrorText:            types.StringValue(info.ErrorText),
+	}
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+func TestOperationsDataSourceMetadata(t *testing.T) {
+	d := NewOperationsDataSource()
+
+	req := datasource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &datasource.MetadataResponse{}
+
+	d.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_operations" {
+		t.Errorf("expected TypeName 'vboxweb_operations', got %q", resp.TypeName)
+	}
+}
+
+func TestOperationsDataSourceSchema(t *testing.T) {
+	d := NewOperationsDataSource()
+
+	req := datasource.SchemaRequest{}
+	resp := &datasource.SchemaResponse{}
+
+	d.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	if attr, ok := schema.Attributes["progress_refs"]; !ok || !attr.IsRequired() {
+		t.Error("expected required 'progress_refs' attribute in schema")
+	}
+	if attr, ok := schema.Attributes["wait_timeout"]; !ok || !attr.IsOptional() {
+		t.Error("expected optional 'wait_timeout' attribute in schema")
+	}
+	for _, attrName := range []string{"id", "operations"} {
+		if attr, ok := schema.Attributes[attrName]; !ok || !attr.IsComputed() {
+			t.Errorf("expected computed %q attribute in schema", attrName)
+		}
+	}
+}
+
+func TestOperationData(t *testing.T) {
+	got := operationData(&vbox.ProgressInfo{
+		Ref:        "progress-1",
+		Operation:  "Copying hard disk",
+		Percent:    100,
+		Completed:  true,
+		ResultCode: -2135228412,
+		ErrorText:  "disk full",
+	})
+
+	if ref := got.ProgressRef.ValueString(); ref != "progress-1" {
+		t.Errorf("progress_ref = %q, want %q", ref, "progress-1")
+	}
+	if op := got.OperationDescription.ValueString(); op != "Copying hard disk" {
+		t.Errorf("operation_description = %q, want %q", op, "Copying hard disk")
+	}
+	if !got.Completed.ValueBool() || got.Percent.ValueInt64() != 100 {
+		t.Errorf("expected a completed operation, got %+v", got)
+	}
+	if code := got.ResultCode.ValueInt64(); code != -2135228412 {
+		t.Errorf("result_code = %d, want %d", code, -2135228412)
+	}
+	if text := got.ErrorText.ValueString(); text != "disk full" {
+		t.Errorf("error_text = %q, want %q", text, "disk full")
+	}
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+type osTypesDataSource struct {
+	client *vbox.Client
+}
+
+type osTypesDataSourceModel struct {
+	FamilyID types.String      `tfsdk:"family_id"`
+	OSTypes  []osTypeDataModel `tfsdk:"os_types"`
+	ID       types.String      `tfsdk:"id"`
+}
+
+type osTypeDataModel struct {
+	ID          types.String `tfsdk:"id"`
+	Description types.String `tfsdk:"description"`
+	FamilyID    types.String `tfsdk:"family_id"`
+	Is64Bit     types.Bool   `tfsdk:"is_64bit"`
+}
+
+func NewOSTypesDataSource() datasource.DataSource {
+	return &osTypesDataSource{}
+}
+
+func (d *osTypesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_os_types"
+}
+
+func (d *osTypesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*providerData).client
+}
+
+func (d *osTypesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the guest OS types of the VirtualBox host. Their IDs are the valid values of the os_type_id of a vboxweb_machine.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The family_id filter, or 'all' when it is not set.",
+			},
+			"family_id": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only list the OS types of this family, e.g. 'Linux' or 'Windows'. Matched case-insensitively.",
+			},
+			"os_types": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Guest OS types, ordered by ID.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:    true,
+							Description: "OS type ID, as used for os_type_id, e.g. 'Ubuntu_64'.",
+						},
+						"description": schema.StringAttribute{
+							Computed:    true,
+							Description: "Human readable name of the OS type, e.g. 'Ubuntu (64-bit)'.",
+						},
+						"family_id": schema.StringAttribute{
+							Computed:    true,
+							Description: "ID of the OS family, e.g. 'Linux'.",
+						},
+						"is_64bit": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the OS type is a 64-bit one.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *osTypesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var cfg osTypesDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	osTypes, err := d.client.ReadGuestOSTypes(ctx, cfg.FamilyID.ValueString())
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to read guest OS types", err)
+		return
+	}
+
+	cfg.ID = types.StringValue("all")
+	if cfg.FamilyID.ValueString() != "" {
+		cfg.ID = cfg.FamilyID
+	}
+	cfg.OSTypes = osTypeData(osTypes)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &cfg)...)
+}
+
+// osTypeData converts guest OS types to their data source model.
+func osTypeData(osTypes []vboxapi.GuestOSType) []osTypeDataModel {
+	out := make([]osTypeDataModel, 0, len(osTypes))
+	for _, t := range osTypes {
+		out = append(out, osTypeDataModel{
+			ID:          types.StringValue(t.ID),
+			Description: types.StringValue(t.Description),
+			FamilyID:    types.StringValue(t.FamilyID),
+			Is64Bit:     types.BoolValue(t.Is64Bit),
+		})
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestOSTypesDataSourceMetadata(t *testing.T) {
+	d := NewOSTypesDataSource()
+
+	req := datasource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &datasource.MetadataResponse{}
+
+	d.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_os_types" {
+		t.Errorf("expected TypeName 'vboxweb_os_types', got %q", resp.TypeName)
+	}
+}
+
+func TestOSTypesDataSourceSchema(t *testing.T) {
+	d := NewOSTypesDataSource()
+
+	req := datasource.SchemaRequest{}
+	resp := &datasource.SchemaResponse{}
+
+	d.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	if attr, ok := schema.Attributes["family_id"]; !ok || !attr.IsOptional() {
+		t.Error("expected optional 'family_id' attribute in schema")
+	}
+	for _, attrName := range []string{"id", "os_types"} {
+		if attr, ok := schema.Attributes[attrName]; !ok || !attr.IsComputed() {
+			t.Errorf("expected computed %q attribute in schema", attrName)
+		}
+	}
+}
+
+func TestOSTypeData(t *testing.T) {
+	got := osTypeData([]vboxapi.GuestOSType{
+		{ID: "Ubuntu_64", Description: "Ubuntu (64-bit)", FamilyID: "Linux", Is64Bit: true},
+		{ID: "Debian", Description: "Debian (32-bit)", FamilyID: "Linux"},
+	})
+
+	if len(got) != 2 {
+		t.Fatalf("got %d OS types, want 2", len(got))
+	}
+	if id := got[0].ID.ValueString(); id != "Ubuntu_64" {
+		t.Errorf("id = %q, want %q", id, "Ubuntu_64")
+	}
+	if family := got[0].FamilyID.ValueString(); family != "Linux" || !got[0].Is64Bit.ValueBool() {
+		t.Errorf("first OS type = %+v, want a 64-bit Linux type", got[0])
+	}
+	if got[1].Is64Bit.ValueBool() {
+		t.Errorf("second OS type = %+v, want a 32-bit type", got[1])
+	}
+
+	if empty := osTypeData(nil); empty == nil {
+		t.Error("expected an empty OS type list, got nil")
+	}
+}
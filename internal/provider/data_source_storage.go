@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+type storageDataSource struct {
+	client *vbox.Client
+}
+
+type storageDataSourceModel struct {
+	MachineID   types.String                 `tfsdk:"machine_id"`
+	Controllers []storageControllerDataModel `tfsdk:"controllers"`
+	ID          types.String                 `tfsdk:"id"`
+}
+
+type storageControllerDataModel struct {
+	Name           types.String                 `tfsdk:"name"`
+	Bus            types.String                 `tfsdk:"bus"`
+	ControllerType types.String                 `tfsdk:"controller_type"`
+	PortCount      types.Int64                  `tfsdk:"port_count"`
+	Attachments    []storageAttachmentDataModel `tfsdk:"attachments"`
+}
+
+type storageAttachmentDataModel struct {
+	Port     types.Int64  `tfsdk:"port"`
+	Device   types.Int64  `tfsdk:"device"`
+	Type     types.String `tfsdk:"type"`
+	Medium   types.String `tfsdk:"medium"`
+	MediumID types.String `tfsdk:"medium_id"`
+}
+
+func NewStorageDataSource() datasource.DataSource {
+	return &storageDataSource{}
+}
+
+func (d *storageDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_storage"
+}
+
+func (d *storageDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*providerData).client
+}
+
+func (d *storageDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Lists the storage controllers of a VirtualBox VM and the disks and drives attached to each.
+
+Use it to inspect the storage layout of a clone, for example to find a free port for a
+vboxweb_storage_attachment or the controller name a template uses.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The machine ID.",
+			},
+			"machine_id": schema.StringAttribute{
+				Required:    true,
+				Description: "VirtualBox machine ID (UUID) or name.",
+			},
+			"controllers": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Storage controllers of the VM. Empty when the VM has none.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Controller name, as used by vboxweb_storage_attachment.",
+						},
+						"bus": schema.StringAttribute{
+							Computed:    true,
+							Description: "Bus of the controller: 'IDE', 'SATA', 'SCSI', 'SAS', 'Floppy', 'NVMe', 'USB' or 'VirtioSCSI'.",
+						},
+						"controller_type": schema.StringAttribute{
+							Computed:    true,
+							Description: "Emulated controller chipset, for example 'IntelAhci' or 'PIIX4'.",
+						},
+						"port_count": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Number of ports.",
+						},
+						"attachments": schema.ListNestedAttribute{
+							Computed:    true,
+							Description: "Devices attached to the controller, ordered by port and device.",
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"port": schema.Int64Attribute{
+										Computed:    true,
+										Description: "Controller port number.",
+									},
+									"device": schema.Int64Attribute{
+										Computed:    true,
+										Description: "Device number on the port.",
+									},
+									"type": schema.StringAttribute{
+										Computed:    true,
+										Description: "Device type: 'hdd', 'dvd' or 'floppy'.",
+									},
+									"medium": schema.StringAttribute{
+										Computed:    true,
+										Description: "Path of the medium on the VirtualBox host. Empty when a drive holds no medium.",
+									},
+									"medium_id": schema.StringAttribute{
+										Computed:    true,
+										Description: "UUID of the medium. Empty when a drive holds no medium.",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *storageDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var cfg storageDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	controllers, err := d.client.ReadMachineStorage(ctx, cfg.MachineID.ValueString())
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to read VM storage", err)
+		return
+	}
+
+	cfg.ID = cfg.MachineID
+	cfg.Controllers = storageControllerData(controllers)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &cfg)...)
+}
+
+// storageControllerData converts a storage layout to its data source model.
+func storageControllerData(controllers []vbox.ControllerStorage) []storageControllerDataModel {
+	out := make([]storageControllerDataModel, 0, len(controllers))
+	for _, ctl := range controllers {
+		m := storageControllerDataModel{
+			Name:           types.StringValue(ctl.Name),
+			Bus:            types.StringValue(storageBusName(ctl.Bus)),
+			ControllerType: types.StringValue(string(ctl.Type)),
+			PortCount:      types.Int64Value(int64(ctl.PortCount)),
+			Attachments:    make([]storageAttachmentDataModel, 0, len(ctl.Attachments)),
+		}
+		for _, att := range ctl.Attachments {
+			m.Attachments = append(m.Attachments, storageAttachmentDataModel{
+				Port:     types.Int64Value(int64(att.Port)),
+				Device:   types.Int64Value(int64(att.Device)),
+				Type:     types.StringValue(storageDeviceTypeName(att.DeviceType)),
+				Medium:   types.StringValue(att.Medium),
+				MediumID: types.StringValue(att.MediumID),
+			})
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// storageBusName returns the schema name of a storage bus, or the
+// VirtualBox name for buses the schema does not know.
+func storageBusName(bus vboxapi.StorageBus) string {
+	for name, b := range storageBuses {
+		if b == bus {
+			return name
+		}
+	}
+	return string(bus)
+}
+
+// storageDeviceTypeName returns the schema name of a device type, or the
+// VirtualBox name for types the schema does not know.
+func storageDeviceTypeName(t vboxapi.DeviceType) string {
+	for name, dt := range storageDeviceTypes {
+		if dt == t {
+			return name
+		}
+	}
+	return string(t)
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestStorageDataSourceMetadata(t *testing.T) {
+	d := NewStorageDataSource()
+
+	req := datasource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &datasource.MetadataResponse{}
+
+	d.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_storage" {
+		t.Errorf("expected TypeName 'vboxweb_storage', got %q", resp.TypeName)
+	}
+}
+
+func TestStorageDataSourceSchema(t *testing.T) {
+	d := NewStorageDataSource()
+
+	req := datasource.SchemaRequest{}
+	resp := &datasource.SchemaResponse{}
+
+	d.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	if attr, ok := schema.Attributes["machine_id"]; !ok || !attr.IsRequired() {
+		t.Error("expected required 'machine_id' attribute in schema")
+	}
+	for _, attrName := range []string{"id", "controllers"} {
+		if attr, ok := schema.Attributes[attrName]; !ok || !attr.IsComputed() {
+			t.Errorf("expected computed %q attribute in schema", attrName)
+		}
+	}
+}
+
+func TestStorageControllerData(t *testing.T) {
+	got := storageControllerData([]vbox.ControllerStorage{
+		{
+			StorageController: vbox.StorageController{Name: "NVMe", Bus: vboxapi.StorageBusPCIe, Type: vboxapi.StorageControllerNVMe, PortCount: 1},
+			Attachments: []vbox.StorageAttachment{
+				{Controller: "NVMe", DeviceType: vboxapi.DeviceTypeHardDisk, Medium: "/vms/vm/os.vdi", MediumID: "uuid-os"},
+			},
+		},
+		{
+			StorageController: vbox.StorageController{Name: "IDE", Bus: vboxapi.StorageBusIDE, Type: vboxapi.StorageControllerPIIX4, PortCount: 2},
+			Attachments:       []vbox.StorageAttachment{},
+		},
+	})
+
+	if len(got) != 2 {
+		t.Fatalf("got %d controllers, want 2", len(got))
+	}
+	if bus := got[0].Bus.ValueString(); bus != "NVMe" {
+		t.Errorf("bus = %q, want %q", bus, "NVMe")
+	}
+	if len(got[0].Attachments) != 1 {
+		t.Fatalf("got %d attachments, want 1", len(got[0].Attachments))
+	}
+	if typ := got[0].Attachments[0].Type.ValueString(); typ != "hdd" {
+		t.Errorf("attachment type = %q, want %q", typ, "hdd")
+	}
+	if got[1].Attachments == nil {
+		t.Error("expected an empty attachment list, got nil")
+	}
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// addClientError adds an error diagnostic for a failed client operation.
+// When the failure is a VirtualBox fault, the detail also lists the
+// reporting component, interface and result code.
+func addClientError(diags *diag.Diagnostics, summary string, err error) {
+	diags.AddError(summary, clientErrorDetail(err))
+}
+
+// addReadError reports err, returned while a resource is refreshed. When
+// tolerate is set, SOAP faults other than not-found are reported as warnings
+// instead, so that the caller keeps the prior state and a flaky webservice
+// does not fail the plan.
+func addReadError(diags *diag.Diagnostics, tolerate bool, summary string, err error) {
+	var vErr *vboxapi.Error
+	if tolerate && errors.As(err, &vErr) {
+		diags.AddWarning(summary, clientErrorDetail(err)+
+			"\n\nThe prior state was kept because tolerate_read_errors is set; it may be out of date.")
+		return
+	}
+	addClientError(diags, summary, err)
+}
+
+func clientErrorDetail(err error) string {
+	var vErr *vboxapi.Error
+	if !errors.As(err, &vErr) {
+		return err.Error()
+	}
+
+	var b strings.Builder
+	b.WriteString(err.Error())
+	b.WriteString("\n")
+	if vErr.Component != "" {
+		fmt.Fprintf(&b, "\nVirtualBox component: %s", vErr.Component)
+	}
+	if vErr.Interface != "" {
+		fmt.Fprintf(&b, "\nInterface: %s", vErr.Interface)
+	}
+	if vErr.Operation != "" {
+		fmt.Fprintf(&b, "\nOperation: %s", vErr.Operation)
+	}
+	if vErr.ResultCode != 0 {
+		fmt.Fprintf(&b, "\nResult code: %s", vboxapi.ResultCodeString(vErr.ResultCode))
+	}
+	return b.String()
+}
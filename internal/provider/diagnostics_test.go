@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestClientErrorDetail_VBoxError(t *testing.T) {
+	err := fmt.Errorf("failed to add NAT redirect: %w", &vboxapi.Error{
+		Operation:  "INATEngine_addRedirect",
+		Interface:  "INATEngine",
+		Component:  "NATEngineWrap",
+		ResultCode: vboxapi.ResultInvalidArg,
+		Text:       "A NAT rule of this name already exists",
+	})
+
+	var diags diag.Diagnostics
+	addClientError(&diags, "Failed to create NAT port forward rule", err)
+
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(diags))
+	}
+	detail := diags[0].Detail()
+	for _, want := range []string{
+		"A NAT rule of this name already exists",
+		"VirtualBox component: NATEngineWrap",
+		"Interface: INATEngine",
+		"Operation: INATEngine_addRedirect",
+		"Result code: 0x80070057 E_INVALIDARG",
+	} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("detail missing %q:\n%s", want, detail)
+		}
+	}
+	if diags[0].Summary() != "Failed to create NAT port forward rule" {
+		t.Errorf("unexpected summary %q", diags[0].Summary())
+	}
+}
+
+func TestClientErrorDetail_PlainError(t *testing.T) {
+	err := errors.New("connection refused")
+	if got := clientErrorDetail(err); got != "connection refused" {
+		t.Errorf("clientErrorDetail() = %q, want %q", got, "connection refused")
+	}
+}
+
+func TestAddReadError(t *testing.T) {
+	fault := fmt.Errorf("failed to get machine state: %w", &vboxapi.Error{
+		Operation:  "IMachine_getState",
+		ResultCode: vboxapi.ResultFail,
+		Text:       "The object is not ready",
+	})
+	tests := []struct {
+		name        string
+		tolerate    bool
+		err         error
+		wantWarning bool
+	}{
+		{name: "fault", err: fault},
+		{name: "tolerated fault", tolerate: true, err: fault, wantWarning: true},
+		{name: "tolerated connection error", tolerate: true, err: errors.New("connection refused")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			addReadError(&diags, tt.tolerate, "Failed to read VM state", tt.err)
+
+			if got := diags.WarningsCount() == 1 && !diags.HasError(); got != tt.wantWarning {
+				t.Errorf("diagnostics = %v, want a warning: %t", diags, tt.wantWarning)
+			}
+		})
+	}
+}
@@ -0,0 +1,447 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// cpuidOverrideModel is a cpuid_overrides block of vboxweb_machine. Values
+// are strings so that they can be written in hexadecimal.
+type cpuidOverrideModel struct {
+	Leaf    types.String `tfsdk:"leaf"`
+	SubLeaf types.String `tfsdk:"sub_leaf"`
+	EAX     types.String `tfsdk:"eax"`
+	EBX     types.String `tfsdk:"ebx"`
+	ECX     types.String `tfsdk:"ecx"`
+	EDX     types.String `tfsdk:"edx"`
+}
+
+var cpuidValueRegexp = regexp.MustCompile(`^(0[xX][0-9a-fA-F]{1,8}|[0-9]{1,10})$`)
+
+func cpuidValueValidator() validator.String {
+	return stringvalidator.RegexMatches(cpuidValueRegexp, "must be a 32-bit value in decimal or 0x-prefixed hexadecimal")
+}
+
+// parseCPUIDValue parses a decimal or 0x-prefixed hexadecimal 32-bit value.
+// A null value is 0.
+func parseCPUIDValue(v types.String) (uint32, error) {
+	if v.IsNull() {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(strings.TrimSpace(v.ValueString()), 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid CPUID value %q: must be a 32-bit value in decimal or 0x-prefixed hexadecimal", v.ValueString())
+	}
+	return uint32(n), nil
+}
+
+func (m cpuidOverrideModel) toLeaf() (vboxapi.CPUIDLeaf, error) {
+	var leaf vboxapi.CPUIDLeaf
+	for _, f := range []struct {
+		dst *uint32
+		v   types.String
+	}{
+		{&leaf.Leaf, m.Leaf},
+		{&leaf.SubLeaf, m.SubLeaf},
+		{&leaf.EAX, m.EAX},
+		{&leaf.EBX, m.EBX},
+		{&leaf.ECX, m.ECX},
+		{&leaf.EDX, m.EDX},
+	} {
+		n, err := parseCPUIDValue(f.v)
+		if err != nil {
+			return leaf, err
+		}
+		*f.dst = n
+	}
+	return leaf, nil
+}
+
+// cpuidLeafKey identifies a CPUID leaf override.
+type cpuidLeafKey struct{ leaf, subLeaf uint32 }
+
+func cpuidLeaves(overrides []cpuidOverrideModel) (map[cpuidLeafKey]vboxapi.CPUIDLeaf, error) {
+	leaves := make(map[cpuidLeafKey]vboxapi.CPUIDLeaf, len(overrides))
+	for _, o := range overrides {
+		leaf, err := o.toLeaf()
+		if err != nil {
+			return nil, err
+		}
+		key := cpuidLeafKey{leaf.Leaf, leaf.SubLeaf}
+		if _, ok := leaves[key]; ok {
+			return nil, fmt.Errorf("duplicate cpuid_overrides block for leaf %#x sub-leaf %#x", leaf.Leaf, leaf.SubLeaf)
+		}
+		leaves[key] = leaf
+	}
+	return leaves, nil
+}
+
+// bootMenuModes maps boot_menu_mode values to VirtualBox boot menu modes.
+var bootMenuModes = map[string]vboxapi.BootMenuMode{
+	"disabled":       vboxapi.BootMenuModeDisabled,
+	"menuonly":       vboxapi.BootMenuModeMenuOnly,
+	"messageandmenu": vboxapi.BootMenuModeMessageAndMenu,
+}
+
+// recordingFormats maps recording_format values to VirtualBox video codecs.
+var recordingFormats = map[string]vboxapi.RecordingVideoCodec{
+	"vp8": vboxapi.RecordingVideoCodecVP8,
+	"vp9": vboxapi.RecordingVideoCodecVP9,
+	"av1": vboxapi.RecordingVideoCodecAV1,
+}
+
+// machineSettingsChanges returns the settings configured in plan that differ
+// from prior. With a nil prior, all configured settings are returned.
+func machineSettingsChanges(plan machineModel, prior *machineModel) (vbox.MachineSettings, error) {
+	var s vbox.MachineSettings
+	if v := plan.MemoryMB; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.MemoryMB)) {
+		s.MemoryMB = uint32(v.ValueInt64())
+	}
+	if v := plan.CPUCount; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.CPUCount)) {
+		s.CPUCount = uint32(v.ValueInt64())
+	}
+	if v := plan.PageFusionEnabled; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.PageFusionEnabled)) {
+		enabled := v.ValueBool()
+		s.PageFusion = &enabled
+	}
+	if v := plan.ExecutionEngine; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.ExecutionEngine)) {
+		s.ExecutionEngine = vboxapi.ExecutionEngine(v.ValueString())
+	}
+	if v := plan.GraphicsController; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.GraphicsController)) {
+		s.GraphicsController = vboxapi.GraphicsController(v.ValueString())
+	}
+	if v := plan.VRAMMB; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.VRAMMB)) {
+		s.VRAMMB = uint32(v.ValueInt64())
+	}
+	if v := plan.Accelerate3D; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.Accelerate3D)) {
+		enabled := v.ValueBool()
+		s.Accelerate3D = &enabled
+	}
+	if v := plan.MonitorCount; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.MonitorCount)) {
+		s.MonitorCount = uint32(v.ValueInt64())
+	}
+	if v := plan.APICEnabled; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.APICEnabled)) {
+		enabled := v.ValueBool()
+		s.APIC = &enabled
+	}
+	if v := plan.X2APICEnabled; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.X2APICEnabled)) {
+		enabled := v.ValueBool()
+		s.X2APIC = &enabled
+	}
+	if v := plan.PAEEnabled; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.PAEEnabled)) {
+		enabled := v.ValueBool()
+		s.PAE = &enabled
+	}
+	if v := plan.LongMode; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.LongMode)) {
+		enabled := v.ValueBool()
+		s.LongMode = &enabled
+	}
+	if v := plan.BootMenuMode; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.BootMenuMode)) {
+		s.BootMenuMode = bootMenuModes[v.ValueString()]
+	}
+	if v := plan.AutostartEnabled; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.AutostartEnabled)) {
+		enabled := v.ValueBool()
+		s.AutostartEnabled = &enabled
+	}
+	if v := plan.AutostartDelay; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.AutostartDelay)) {
+		delay := uint32(v.ValueInt64())
+		s.AutostartDelay = &delay
+	}
+	if v := plan.ClipboardFileTransfers; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.ClipboardFileTransfers)) {
+		enabled := v.ValueBool()
+		s.ClipboardFileTransfers = &enabled
+	}
+	if v := plan.DisableTimeSync; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.DisableTimeSync)) {
+		disabled := v.ValueBool()
+		s.TimeSyncDisabled = &disabled
+	}
+	if v := plan.RecordingEnabled; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.RecordingEnabled)) {
+		enabled := v.ValueBool()
+		s.RecordingEnabled = &enabled
+	}
+	if v := plan.RecordingScreens; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.RecordingScreens)) {
+		s.RecordingScreens = []uint32{}
+		for _, e := range v.Elements() {
+			id, ok := e.(types.Int64)
+			if !ok || id.IsNull() || id.IsUnknown() {
+				continue
+			}
+			s.RecordingScreens = append(s.RecordingScreens, uint32(id.ValueInt64()))
+		}
+		slices.Sort(s.RecordingScreens)
+	}
+	if v := plan.RecordingFile; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.RecordingFile)) {
+		file := v.ValueString()
+		s.RecordingFile = &file
+	}
+	if v := plan.RecordingFormat; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.RecordingFormat)) {
+		s.RecordingVideoCodec = recordingFormats[v.ValueString()]
+	}
+	if v := plan.RecordingFPS; !v.IsNull() && !v.IsUnknown() && (prior == nil || !v.Equal(prior.RecordingFPS)) {
+		s.RecordingFPS = uint32(v.ValueInt64())
+	}
+	if v := plan.IOCacheEnabled; !v.IsNull() && !v.IsUnknown() &&
+		(prior == nil || !v.Equal(prior.IOCacheEnabled) || !plan.IOCacheController.Equal(prior.IOCacheController)) {
+		enabled := v.ValueBool()
+		s.HostIOCache = &enabled
+		s.HostIOCacheController = plan.IOCacheController.ValueString()
+	}
+	if v := plan.VRDEKeyboardLayout; !v.IsUnknown() && (prior == nil || !v.Equal(prior.VRDEKeyboardLayout)) {
+		// A null value clears a layout set previously.
+		if !v.IsNull() || prior != nil {
+			layout := v.ValueString()
+			s.VRDEKeyboardLayout = &layout
+		}
+	}
+	if v := plan.Owner; !v.IsUnknown() && (prior == nil || !v.Equal(prior.Owner)) {
+		// A null value clears an owner set previously.
+		if !v.IsNull() || prior != nil {
+			owner := v.ValueString()
+			s.Owner = &owner
+		}
+	}
+
+	planLeaves, err := cpuidLeaves(plan.CPUIDOverrides)
+	if err != nil {
+		return s, err
+	}
+	var priorOverrides []cpuidOverrideModel
+	if prior != nil {
+		priorOverrides = prior.CPUIDOverrides
+	}
+	// Prior values were validated when they were applied.
+	priorLeaves, _ := cpuidLeaves(priorOverrides)
+	for _, o := range plan.CPUIDOverrides {
+		leaf, _ := o.toLeaf()
+		if p, ok := priorLeaves[cpuidLeafKey{leaf.Leaf, leaf.SubLeaf}]; !ok || p != leaf {
+			s.CPUIDLeaves = append(s.CPUIDLeaves, leaf)
+		}
+	}
+	for _, o := range priorOverrides {
+		leaf, err := o.toLeaf()
+		if err != nil {
+			continue
+		}
+		if _, ok := planLeaves[cpuidLeafKey{leaf.Leaf, leaf.SubLeaf}]; !ok {
+			s.RemoveCPUIDLeaves = append(s.RemoveCPUIDLeaves, leaf)
+		}
+	}
+	return s, nil
+}
+
+// setMachineSettings copies the settings read from VirtualBox into m.
+func setMachineSettings(m *machineModel, s *vbox.MachineSettings) {
+	m.MemoryMB = types.Int64Value(int64(s.MemoryMB))
+	m.CPUCount = types.Int64Value(int64(s.CPUCount))
+	m.PageFusionEnabled = types.BoolPointerValue(s.PageFusion)
+	m.ExecutionEngine = types.StringValue(string(s.ExecutionEngine))
+	m.GraphicsController = types.StringValue(string(s.GraphicsController))
+	m.VRAMMB = types.Int64Value(int64(s.VRAMMB))
+	m.Accelerate3D = types.BoolPointerValue(s.Accelerate3D)
+	m.MonitorCount = types.Int64Value(int64(s.MonitorCount))
+	m.APICEnabled = types.BoolPointerValue(s.APIC)
+	m.X2APICEnabled = types.BoolPointerValue(s.X2APIC)
+	m.PAEEnabled = types.BoolPointerValue(s.PAE)
+	m.LongMode = types.BoolPointerValue(s.LongMode)
+	m.BootMenuMode = types.StringValue(strings.ToLower(string(s.BootMenuMode)))
+	m.AutostartEnabled = types.BoolPointerValue(s.AutostartEnabled)
+	if s.AutostartDelay != nil {
+		m.AutostartDelay = types.Int64Value(int64(*s.AutostartDelay))
+	} else {
+		m.AutostartDelay = types.Int64Null()
+	}
+	m.ClipboardFileTransfers = types.BoolPointerValue(s.ClipboardFileTransfers)
+	m.DisableTimeSync = types.BoolPointerValue(s.TimeSyncDisabled)
+	m.RecordingEnabled = types.BoolPointerValue(s.RecordingEnabled)
+	setRecordingScreens(m, s.RecordingScreenSettings)
+	m.IOCacheEnabled = hostIOCacheValue(s.ControllersHostIOCache, m.IOCacheController.ValueString())
+	m.HWVirt = types.BoolPointerValue(s.HWVirt)
+	m.NestedPaging = types.BoolPointerValue(s.NestedPaging)
+	m.NestedVirt = types.BoolPointerValue(s.NestedHWVirt)
+	if s.VRDEKeyboardLayout != nil && *s.VRDEKeyboardLayout != "" {
+		m.VRDEKeyboardLayout = types.StringValue(*s.VRDEKeyboardLayout)
+	} else {
+		m.VRDEKeyboardLayout = types.StringNull()
+	}
+	if s.Owner != nil && *s.Owner != "" {
+		m.Owner = types.StringValue(*s.Owner)
+	} else {
+		m.Owner = types.StringNull()
+	}
+	m.CPUIDOverrides = reconcileCPUIDOverrides(m.CPUIDOverrides, s.CPUIDLeaves)
+}
+
+// setRecordingScreens copies the recording options of screens into m. The
+// file, format and frame rate are those of the first recorded screen, or of
+// the first screen when none is recorded.
+func setRecordingScreens(m *machineModel, screens []vboxapi.RecordingScreenSettings) {
+	if len(screens) == 0 {
+		m.RecordingScreens = types.SetNull(types.Int64Type)
+		m.RecordingFile = types.StringNull()
+		m.RecordingFormat = types.StringNull()
+		m.RecordingFPS = types.Int64Null()
+		return
+	}
+
+	ids := []attr.Value{}
+	first := screens[0]
+	for _, screen := range screens {
+		if !screen.Enabled {
+			continue
+		}
+		if len(ids) == 0 {
+			first = screen
+		}
+		ids = append(ids, types.Int64Value(int64(screen.ID)))
+	}
+	m.RecordingScreens = types.SetValueMust(types.Int64Type, ids)
+	m.RecordingFile = types.StringValue(first.Filename)
+	m.RecordingFormat = types.StringValue(strings.ToLower(string(first.VideoCodec)))
+	m.RecordingFPS = types.Int64Value(int64(first.VideoFPS))
+}
+
+// hostIOCacheValue returns io_cache_enabled for the storage controllers of
+// a VM: the setting of the named controller, or, without a name, the setting
+// shared by all controllers. It is null when the controller does not exist
+// or the controllers differ, so that a configured value is applied again.
+func hostIOCacheValue(controllers map[string]bool, name string) types.Bool {
+	if name != "" {
+		enabled, ok := controllers[name]
+		if !ok {
+			return types.BoolNull()
+		}
+		return types.BoolValue(enabled)
+	}
+	v := types.BoolNull()
+	for _, enabled := range controllers {
+		if !v.IsNull() && v.ValueBool() != enabled {
+			return types.BoolNull()
+		}
+		v = types.BoolValue(enabled)
+	}
+	return v
+}
+
+// reconcileCPUIDOverrides updates the overrides tracked in state with the
+// leaves set on the VM. Overrides removed from the VM are dropped, while
+// leaves not tracked in state are ignored. Values that did not change keep
+// their configured spelling.
+func reconcileCPUIDOverrides(overrides []cpuidOverrideModel, leaves []vboxapi.CPUIDLeaf) []cpuidOverrideModel {
+	onVM := make(map[cpuidLeafKey]vboxapi.CPUIDLeaf, len(leaves))
+	for _, leaf := range leaves {
+		onVM[cpuidLeafKey{leaf.Leaf, leaf.SubLeaf}] = leaf
+	}
+
+	out := make([]cpuidOverrideModel, 0, len(overrides))
+	for _, o := range overrides {
+		want, err := o.toLeaf()
+		if err != nil {
+			continue
+		}
+		got, ok := onVM[cpuidLeafKey{want.Leaf, want.SubLeaf}]
+		if !ok {
+			continue
+		}
+		o.EAX = cpuidValue(o.EAX, want.EAX, got.EAX)
+		o.EBX = cpuidValue(o.EBX, want.EBX, got.EBX)
+		o.ECX = cpuidValue(o.ECX, want.ECX, got.ECX)
+		o.EDX = cpuidValue(o.EDX, want.EDX, got.EDX)
+		out = append(out, o)
+	}
+	return out
+}
+
+func cpuidValue(current types.String, want, got uint32) types.String {
+	if want == got {
+		return current
+	}
+	return types.StringValue(fmt.Sprintf("0x%08x", got))
+}
+
+// impliedBoolModifier plans value for an unconfigured attribute when the
+// attribute at other is configured to when, so that the plan matches what
+// VirtualBox applies. It runs after UseStateForUnknown.
+type impliedBoolModifier struct {
+	other path.Path
+	when  bool
+	value bool
+}
+
+func (m impliedBoolModifier) Description(_ context.Context) string {
+	return fmt.Sprintf("Set to %t when %s is %t.", m.value, m.other, m.when)
+}
+
+func (m impliedBoolModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m impliedBoolModifier) PlanModifyBool(ctx context.Context, req planmodifier.BoolRequest, resp *planmodifier.BoolResponse) {
+	if !req.ConfigValue.IsNull() {
+		return
+	}
+	var other types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, m.other, &other)...)
+	if other.IsNull() || other.IsUnknown() || other.ValueBool() != m.when {
+		return
+	}
+	resp.PlanValue = types.BoolValue(m.value)
+}
+
+// useStateUnlessChangedModifier keeps the prior value of a computed
+// attribute, like UseStateForUnknown, unless one of the attributes at paths
+// changes: VirtualBox may then report a different value.
+type useStateUnlessChangedModifier struct {
+	paths []path.Path
+}
+
+func (m useStateUnlessChangedModifier) Description(_ context.Context) string {
+	return fmt.Sprintf("Keeps the prior value unless %v changes.", m.paths)
+}
+
+func (m useStateUnlessChangedModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m useStateUnlessChangedModifier) PlanModifyBool(ctx context.Context, req planmodifier.BoolRequest, resp *planmodifier.BoolResponse) {
+	if req.StateValue.IsNull() || !req.PlanValue.IsUnknown() {
+		return
+	}
+	for _, p := range m.paths {
+		var planned, prior attr.Value
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, p, &planned)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, p, &prior)...)
+		if resp.Diagnostics.HasError() || !planned.Equal(prior) {
+			return
+		}
+	}
+	resp.PlanValue = req.StateValue
+}
+
+func (m useStateUnlessChangedModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.StateValue.IsNull() || !req.PlanValue.IsUnknown() {
+		return
+	}
+	for _, p := range m.paths {
+		var planned, prior attr.Value
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, p, &planned)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, p, &prior)...)
+		if resp.Diagnostics.HasError() || !planned.Equal(prior) {
+			return
+		}
+	}
+	resp.PlanValue = req.StateValue
+}
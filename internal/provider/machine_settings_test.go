@@ -0,0 +1,502 @@
+package provider
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestMachineSettingsChanges(t *testing.T) {
+	prior := machineModel{ExecutionEngine: types.StringValue("HwVirt")}
+
+	tests := []struct {
+		name   string
+		plan   types.String
+		prior  *machineModel
+		expect vboxapi.ExecutionEngine
+	}{
+		{name: "create with engine", plan: types.StringValue("NativeApi"), expect: vboxapi.ExecutionEngineNativeAPI},
+		{name: "create without engine", plan: types.StringUnknown()},
+		{name: "unchanged", plan: types.StringValue("HwVirt"), prior: &prior},
+		{name: "changed", plan: types.StringValue("NativeApi"), prior: &prior, expect: vboxapi.ExecutionEngineNativeAPI},
+		{name: "not configured", plan: types.StringNull(), prior: &prior},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := machineSettingsChanges(machineModel{ExecutionEngine: tt.plan}, tt.prior)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.ExecutionEngine != tt.expect {
+				t.Errorf("ExecutionEngine = %q, want %q", got.ExecutionEngine, tt.expect)
+			}
+		})
+	}
+}
+
+func TestMachineSettingsChanges_Graphics(t *testing.T) {
+	prior := machineModel{
+		GraphicsController: types.StringValue("VBoxVGA"),
+		VRAMMB:             types.Int64Value(16),
+	}
+
+	plan := prior
+	plan.GraphicsController = types.StringValue("VMSVGA")
+	s, err := machineSettingsChanges(plan, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.GraphicsController != vboxapi.GraphicsControllerVMSVGA || s.VRAMMB != 0 {
+		t.Errorf("expected only the graphics controller to change, got %+v", s)
+	}
+
+	plan = prior
+	plan.VRAMMB = types.Int64Value(128)
+	s, err = machineSettingsChanges(plan, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.GraphicsController != "" || s.VRAMMB != 128 {
+		t.Errorf("expected only VRAM to change, got %+v", s)
+	}
+
+	// Unset values leave the cloned machine's settings alone.
+	plan = machineModel{GraphicsController: types.StringUnknown(), VRAMMB: types.Int64Unknown()}
+	s, err = machineSettingsChanges(plan, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.Empty() {
+		t.Errorf("expected no changes, got %+v", s)
+	}
+}
+
+func TestMachineSettingsChanges_APIC(t *testing.T) {
+	prior := machineModel{
+		APICEnabled:   types.BoolValue(true),
+		X2APICEnabled: types.BoolValue(false),
+	}
+
+	plan := prior
+	plan.X2APICEnabled = types.BoolValue(true)
+	s, err := machineSettingsChanges(plan, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.APIC != nil {
+		t.Errorf("APIC = %v, want unchanged", *s.APIC)
+	}
+	if s.X2APIC == nil || !*s.X2APIC {
+		t.Errorf("X2APIC = %v, want true", s.X2APIC)
+	}
+
+	s, err = machineSettingsChanges(prior, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.Empty() {
+		t.Errorf("expected no changes, got %+v", s)
+	}
+}
+
+func TestMachineSettingsChanges_BootMenuMode(t *testing.T) {
+	prior := machineModel{BootMenuMode: types.StringValue("messageandmenu")}
+
+	plan := prior
+	plan.BootMenuMode = types.StringValue("disabled")
+	s, err := machineSettingsChanges(plan, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.BootMenuMode != vboxapi.BootMenuModeDisabled {
+		t.Errorf("BootMenuMode = %q, want %q", s.BootMenuMode, vboxapi.BootMenuModeDisabled)
+	}
+
+	var m machineModel
+	setMachineSettings(&m, &vbox.MachineSettings{BootMenuMode: vboxapi.BootMenuModeMessageAndMenu})
+	if got := m.BootMenuMode.ValueString(); got != "messageandmenu" {
+		t.Errorf("boot_menu_mode = %q, want %q", got, "messageandmenu")
+	}
+}
+
+func TestMachineSettingsChanges_Display(t *testing.T) {
+	prior := machineModel{
+		Accelerate3D: types.BoolValue(true),
+		MonitorCount: types.Int64Value(1),
+	}
+
+	plan := prior
+	plan.Accelerate3D = types.BoolValue(false)
+	plan.MonitorCount = types.Int64Value(3)
+	s, err := machineSettingsChanges(plan, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Accelerate3D == nil || *s.Accelerate3D {
+		t.Errorf("Accelerate3D = %v, want false", s.Accelerate3D)
+	}
+	if s.MonitorCount != 3 {
+		t.Errorf("MonitorCount = %d, want 3", s.MonitorCount)
+	}
+
+	s, err = machineSettingsChanges(prior, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.Empty() {
+		t.Errorf("expected no changes, got %+v", s)
+	}
+}
+
+func TestMachineSettingsChanges_Autostart(t *testing.T) {
+	prior := machineModel{
+		AutostartEnabled: types.BoolValue(false),
+		AutostartDelay:   types.Int64Value(0),
+	}
+
+	plan := prior
+	plan.AutostartEnabled = types.BoolValue(true)
+	plan.AutostartDelay = types.Int64Value(30)
+	s, err := machineSettingsChanges(plan, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.AutostartEnabled == nil || !*s.AutostartEnabled {
+		t.Errorf("AutostartEnabled = %v, want true", s.AutostartEnabled)
+	}
+	if s.AutostartDelay == nil || *s.AutostartDelay != 30 {
+		t.Errorf("AutostartDelay = %v, want 30", s.AutostartDelay)
+	}
+
+	var m machineModel
+	setMachineSettings(&m, &s)
+	if !m.AutostartEnabled.ValueBool() || m.AutostartDelay.ValueInt64() != 30 {
+		t.Errorf("autostart = %v/%v, want true/30", m.AutostartEnabled, m.AutostartDelay)
+	}
+}
+
+func TestMachineSettingsChanges_ClipboardAndRecording(t *testing.T) {
+	prior := machineModel{
+		ClipboardFileTransfers: types.BoolValue(false),
+		RecordingEnabled:       types.BoolValue(true),
+	}
+
+	plan := prior
+	plan.ClipboardFileTransfers = types.BoolValue(true)
+	s, err := machineSettingsChanges(plan, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.ClipboardFileTransfers == nil || !*s.ClipboardFileTransfers {
+		t.Errorf("ClipboardFileTransfers = %v, want true", s.ClipboardFileTransfers)
+	}
+	if s.RecordingEnabled != nil {
+		t.Errorf("RecordingEnabled = %v, want it unchanged", *s.RecordingEnabled)
+	}
+
+	plan.RecordingEnabled = types.BoolValue(false)
+	s, err = machineSettingsChanges(plan, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.RecordingEnabled == nil || *s.RecordingEnabled {
+		t.Errorf("RecordingEnabled = %v, want false", s.RecordingEnabled)
+	}
+}
+
+func TestMachineSettingsChanges_DisableTimeSync(t *testing.T) {
+	prior := machineModel{DisableTimeSync: types.BoolValue(false)}
+	plan := prior
+	plan.DisableTimeSync = types.BoolValue(true)
+	s, err := machineSettingsChanges(plan, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.TimeSyncDisabled == nil || !*s.TimeSyncDisabled {
+		t.Errorf("TimeSyncDisabled = %v, want true", s.TimeSyncDisabled)
+	}
+
+	s, err = machineSettingsChanges(prior, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.TimeSyncDisabled != nil {
+		t.Errorf("TimeSyncDisabled = %v, want it unchanged", *s.TimeSyncDisabled)
+	}
+}
+
+func TestMachineSettingsChanges_MemoryAndCPUs(t *testing.T) {
+	prior := machineModel{MemoryMB: types.Int64Value(2048), CPUCount: types.Int64Value(2)}
+	plan := prior
+	plan.MemoryMB = types.Int64Value(4096)
+	s, err := machineSettingsChanges(plan, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.MemoryMB != 4096 || s.CPUCount != 0 {
+		t.Errorf("MemoryMB = %d, CPUCount = %d, want 4096 and unchanged", s.MemoryMB, s.CPUCount)
+	}
+
+	plan = machineModel{MemoryMB: types.Int64Unknown(), CPUCount: types.Int64Value(4)}
+	s, err = machineSettingsChanges(plan, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.MemoryMB != 0 || s.CPUCount != 4 {
+		t.Errorf("MemoryMB = %d, CPUCount = %d, want unchanged and 4", s.MemoryMB, s.CPUCount)
+	}
+}
+
+func TestMachineSettingsChanges_RecordingOptions(t *testing.T) {
+	prior := machineModel{
+		RecordingScreens: types.SetValueMust(types.Int64Type, []attr.Value{types.Int64Value(0)}),
+		RecordingFile:    types.StringValue("/vms/vm/vm-screen0.webm"),
+		RecordingFormat:  types.StringValue("vp8"),
+		RecordingFPS:     types.Int64Value(25),
+	}
+
+	plan := prior
+	plan.RecordingScreens = types.SetValueMust(types.Int64Type, []attr.Value{types.Int64Value(1)})
+	plan.RecordingFormat = types.StringValue("vp9")
+	s, err := machineSettingsChanges(plan, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(s.RecordingScreens, []uint32{1}) {
+		t.Errorf("RecordingScreens = %v, want [1]", s.RecordingScreens)
+	}
+	if s.RecordingVideoCodec != vboxapi.RecordingVideoCodecVP9 {
+		t.Errorf("RecordingVideoCodec = %q, want VP9", s.RecordingVideoCodec)
+	}
+	if s.RecordingFile != nil || s.RecordingFPS != 0 {
+		t.Errorf("RecordingFile = %v, RecordingFPS = %d; want them unchanged", s.RecordingFile, s.RecordingFPS)
+	}
+}
+
+func TestSetMachineSettings_MemoryAndCPUs(t *testing.T) {
+	// An imported VM, or one whose sizing is not configured, has no
+	// memory_mb or cpu_count until they are read back.
+	m := machineModel{MemoryMB: types.Int64Null(), CPUCount: types.Int64Null()}
+	setMachineSettings(&m, &vbox.MachineSettings{MemoryMB: 2048, CPUCount: 2})
+	if m.MemoryMB.ValueInt64() != 2048 || m.CPUCount.ValueInt64() != 2 {
+		t.Errorf("memory_mb = %v, cpu_count = %v, want 2048 and 2", m.MemoryMB, m.CPUCount)
+	}
+}
+
+func TestSetMachineSettings_ARMVirtualization(t *testing.T) {
+	// ARM VMs have no x86 virtualization settings to report.
+	m := machineModel{HWVirt: types.BoolValue(true), NestedPaging: types.BoolValue(true), NestedVirt: types.BoolValue(false)}
+	setMachineSettings(&m, &vbox.MachineSettings{})
+	if !m.HWVirt.IsNull() || !m.NestedPaging.IsNull() || !m.NestedVirt.IsNull() {
+		t.Errorf("hwvirt = %v, nested_paging = %v, nested_virt = %v, want null", m.HWVirt, m.NestedPaging, m.NestedVirt)
+	}
+}
+
+func TestSetMachineSettings_RecordingScreens(t *testing.T) {
+	s := vbox.MachineSettings{RecordingScreenSettings: []vboxapi.RecordingScreenSettings{
+		{ID: 0, Filename: "/vms/vm/vm-screen0.webm", VideoCodec: vboxapi.RecordingVideoCodecVP8, VideoFPS: 25},
+		{ID: 1, Enabled: true, Filename: "/srv/recordings/vm.webm", VideoCodec: vboxapi.RecordingVideoCodecVP9, VideoFPS: 30},
+	}}
+
+	var m machineModel
+	setMachineSettings(&m, &s)
+	wantScreens := types.SetValueMust(types.Int64Type, []attr.Value{types.Int64Value(1)})
+	if !m.RecordingScreens.Equal(wantScreens) {
+		t.Errorf("recording_screens = %v, want %v", m.RecordingScreens, wantScreens)
+	}
+	// The options are those of the recorded screen.
+	if m.RecordingFile.ValueString() != "/srv/recordings/vm.webm" || m.RecordingFormat.ValueString() != "vp9" || m.RecordingFPS.ValueInt64() != 30 {
+		t.Errorf("recording options = %v/%v/%v, want /srv/recordings/vm.webm/vp9/30", m.RecordingFile, m.RecordingFormat, m.RecordingFPS)
+	}
+}
+
+func TestMachineSettingsChanges_IOCache(t *testing.T) {
+	prior := machineModel{
+		IOCacheEnabled:    types.BoolValue(true),
+		IOCacheController: types.StringNull(),
+	}
+
+	s, err := machineSettingsChanges(prior, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.HostIOCache != nil {
+		t.Errorf("expected no change, got HostIOCache = %v", *s.HostIOCache)
+	}
+
+	// Narrowing the setting to one controller applies it again.
+	plan := prior
+	plan.IOCacheController = types.StringValue("SATA")
+	s, err = machineSettingsChanges(plan, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.HostIOCache == nil || !*s.HostIOCache || s.HostIOCacheController != "SATA" {
+		t.Errorf("HostIOCache = %v on %q, want true on %q", s.HostIOCache, s.HostIOCacheController, "SATA")
+	}
+}
+
+func TestHostIOCacheValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		controllers map[string]bool
+		controller  string
+		want        types.Bool
+	}{
+		{name: "no controllers", want: types.BoolNull()},
+		{name: "all enabled", controllers: map[string]bool{"IDE": true, "SATA": true}, want: types.BoolValue(true)},
+		{name: "all disabled", controllers: map[string]bool{"IDE": false, "SATA": false}, want: types.BoolValue(false)},
+		{name: "mixed", controllers: map[string]bool{"IDE": false, "SATA": true}, want: types.BoolNull()},
+		{name: "named", controllers: map[string]bool{"IDE": false, "SATA": true}, controller: "IDE", want: types.BoolValue(false)},
+		{name: "named missing", controllers: map[string]bool{"IDE": false}, controller: "SATA", want: types.BoolNull()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hostIOCacheValue(tt.controllers, tt.controller); !got.Equal(tt.want) {
+				t.Errorf("hostIOCacheValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMachineSettingsChanges_VRDEKeyboardLayout(t *testing.T) {
+	prior := machineModel{VRDEKeyboardLayout: types.StringValue("de-de")}
+	empty := machineModel{VRDEKeyboardLayout: types.StringNull()}
+
+	tests := []struct {
+		name   string
+		plan   types.String
+		prior  *machineModel
+		expect *string
+	}{
+		{name: "create with layout", plan: types.StringValue("fr-fr"), expect: ptr("fr-fr")},
+		{name: "create without layout", plan: types.StringNull()},
+		{name: "unchanged", plan: types.StringValue("de-de"), prior: &prior},
+		{name: "changed", plan: types.StringValue("fr-fr"), prior: &prior, expect: ptr("fr-fr")},
+		{name: "removed", plan: types.StringNull(), prior: &prior, expect: ptr("")},
+		{name: "never set", plan: types.StringNull(), prior: &empty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := machineSettingsChanges(machineModel{ExecutionEngine: types.StringNull(), VRDEKeyboardLayout: tt.plan}, tt.prior)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := s.VRDEKeyboardLayout
+			switch {
+			case tt.expect == nil && got != nil:
+				t.Errorf("VRDEKeyboardLayout = %q, want unchanged", *got)
+			case tt.expect != nil && (got == nil || *got != *tt.expect):
+				t.Errorf("VRDEKeyboardLayout = %v, want %q", got, *tt.expect)
+			}
+		})
+	}
+}
+
+func TestMachineSettingsChanges_Owner(t *testing.T) {
+	prior := machineModel{Owner: types.StringValue("team-a")}
+
+	tests := []struct {
+		name   string
+		plan   types.String
+		prior  *machineModel
+		expect *string
+	}{
+		{name: "create with owner", plan: types.StringValue("team-a"), expect: ptr("team-a")},
+		{name: "create without owner", plan: types.StringNull()},
+		{name: "unchanged", plan: types.StringValue("team-a"), prior: &prior},
+		{name: "changed", plan: types.StringValue("team-b"), prior: &prior, expect: ptr("team-b")},
+		{name: "removed", plan: types.StringNull(), prior: &prior, expect: ptr("")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := machineSettingsChanges(machineModel{ExecutionEngine: types.StringNull(), Owner: tt.plan}, tt.prior)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := s.Owner
+			switch {
+			case tt.expect == nil && got != nil:
+				t.Errorf("Owner = %q, want unchanged", *got)
+			case tt.expect != nil && (got == nil || *got != *tt.expect):
+				t.Errorf("Owner = %v, want %q", got, *tt.expect)
+			}
+		})
+	}
+}
+
+func TestMachineSettingsChanges_CPUIDOverrides(t *testing.T) {
+	override := func(leaf, eax string) cpuidOverrideModel {
+		return cpuidOverrideModel{
+			Leaf:    types.StringValue(leaf),
+			SubLeaf: types.StringNull(),
+			EAX:     types.StringValue(eax),
+			EBX:     types.StringValue("0"),
+			ECX:     types.StringValue("0"),
+			EDX:     types.StringValue("0"),
+		}
+	}
+	prior := machineModel{CPUIDOverrides: []cpuidOverrideModel{
+		override("0x1", "0x000306a9"),
+		override("0x80000001", "0"),
+	}}
+	plan := machineModel{CPUIDOverrides: []cpuidOverrideModel{
+		// Same value as prior, spelled differently.
+		override("1", "198313"),
+		override("0x7", "0xffffffff"),
+	}}
+
+	s, err := machineSettingsChanges(plan, &prior)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantSet := []vboxapi.CPUIDLeaf{{Leaf: 0x7, EAX: 0xffffffff}}
+	if !reflect.DeepEqual(s.CPUIDLeaves, wantSet) {
+		t.Errorf("CPUIDLeaves = %+v, want %+v", s.CPUIDLeaves, wantSet)
+	}
+	wantRemove := []vboxapi.CPUIDLeaf{{Leaf: 0x80000001}}
+	if !reflect.DeepEqual(s.RemoveCPUIDLeaves, wantRemove) {
+		t.Errorf("RemoveCPUIDLeaves = %+v, want %+v", s.RemoveCPUIDLeaves, wantRemove)
+	}
+
+	plan.CPUIDOverrides = append(plan.CPUIDOverrides, override("0x7", "0"))
+	if _, err := machineSettingsChanges(plan, &prior); err == nil {
+		t.Error("expected an error for duplicate leaves")
+	}
+}
+
+func TestReconcileCPUIDOverrides(t *testing.T) {
+	overrides := []cpuidOverrideModel{
+		{Leaf: types.StringValue("0x1"), EAX: types.StringValue("0x306A9"), EBX: types.StringValue("0"), ECX: types.StringValue("0"), EDX: types.StringValue("0")},
+		{Leaf: types.StringValue("0x7"), EAX: types.StringValue("1"), EBX: types.StringValue("0"), ECX: types.StringValue("0"), EDX: types.StringValue("0")},
+	}
+	leaves := []vboxapi.CPUIDLeaf{
+		{Leaf: 0x1, EAX: 0x306a9, EDX: 0x10},
+		// Not tracked in state.
+		{Leaf: 0xd, EAX: 1},
+	}
+
+	got := reconcileCPUIDOverrides(overrides, leaves)
+	if len(got) != 1 {
+		t.Fatalf("got %d overrides, want 1: %+v", len(got), got)
+	}
+	if got[0].EAX.ValueString() != "0x306A9" {
+		t.Errorf("EAX = %q, want the configured spelling 0x306A9", got[0].EAX.ValueString())
+	}
+	if got[0].EDX.ValueString() != "0x00000010" {
+		t.Errorf("EDX = %q, want 0x00000010", got[0].EDX.ValueString())
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -2,22 +2,62 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
 )
 
-type vboxwebProvider struct{}
+type vboxwebProvider struct {
+	mu sync.Mutex
+	// clients holds the clients created by Configure, for Close.
+	clients []*vbox.Client
+}
 
 type providerModel struct {
-	Endpoint types.String `tfsdk:"endpoint"`
-	Username types.String `tfsdk:"username"`
-	Password types.String `tfsdk:"password"`
+	Endpoint      types.String `tfsdk:"endpoint"`
+	Username      types.String `tfsdk:"username"`
+	Password      types.String `tfsdk:"password"`
+	DefaultHostIP types.String `tfsdk:"default_host_ip"`
+	// MachineRegistrationWait is a duration string, e.g. 10s.
+	MachineRegistrationWait types.String `tfsdk:"machine_registration_wait"`
+	VerifyConsole           types.Bool   `tfsdk:"verify_console"`
+	PlatformArchitecture    types.String `tfsdk:"platform_architecture"`
+	TolerateReadErrors      types.Bool   `tfsdk:"tolerate_read_errors"`
+	WaitForStableState      types.Bool   `tfsdk:"wait_for_stable_state"`
+	CheckFeatureSupport     types.Bool   `tfsdk:"check_feature_support"`
+	RequestTimeout          types.String `tfsdk:"request_timeout"`
+	MaxRetries              types.Int64  `tfsdk:"max_retries"`
+	ProxyURL                types.String `tfsdk:"proxy_url"`
+	TLSCACert               types.String `tfsdk:"tls_ca_cert"`
+	TLSInsecureSkipVerify   types.Bool   `tfsdk:"tls_insecure_skip_verify"`
+	TLSClientCert           types.String `tfsdk:"tls_client_cert"`
+	TLSClientKey            types.String `tfsdk:"tls_client_key"`
+}
+
+// providerData is handed to resources and data sources by Configure.
+type providerData struct {
+	client *vbox.Client
+	// defaultHostIP is the host_ip of NAT rules that do not set one.
+	defaultHostIP string
+	// tolerateReadErrors makes resources keep their prior state, with a
+	// warning, when a SOAP fault fails their refresh.
+	tolerateReadErrors bool
 }
 
 func New() provider.Provider {
@@ -39,6 +79,7 @@ The VBoxWeb provider enables Terraform to manage VirtualBox virtual machines thr
 - **Clone VMs** from existing templates with configurable clone modes and options
 - **Manage VM power state** (start/stop with configurable session types)
 - **Configure NAT port forwarding** with automatic port allocation
+- **Attach storage media** (disks, ISOs, shared disks) to VMs
 - **Import existing VMs** into Terraform state
 
 ## Requirements
@@ -78,6 +119,81 @@ See the [Getting Started Guide](guides/getting-started.md) for detailed setup in
 				Sensitive:   true,
 				Description: "VirtualBox webservice password.",
 			},
+			"default_host_ip": schema.StringAttribute{
+				Optional:    true,
+				Description: "Host IP address used by vboxweb_nat_port_forward rules that do not set host_ip, for example 127.0.0.1 to only expose forwarded ports locally. It also scopes the port conflict checks of auto-allocated rules. Default: empty, which binds all interfaces.",
+			},
+			"machine_registration_wait": schema.StringAttribute{
+				Optional:    true,
+				Description: "How long vboxweb_nat_port_forward waits for a VM that VirtualBox does not find yet before failing. This absorbs the registration lag of a VM created in the same apply. Default: 10s.",
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"request_timeout": schema.StringAttribute{
+				Optional:    true,
+				Description: "How long a single webservice request may take before it fails, or is retried when max_retries allows. Operations that VirtualBox runs in the background, such as cloning a VM, span many requests and are not limited by it. Default: 90s.",
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"max_retries": schema.Int64Attribute{
+				Optional:    true,
+				Description: "How many times a webservice request is retried, with an increasing backoff, when it fails before vboxwebsrv answers it: connection failures, resets and timeouts, or a 502, 503 or 504 response from a proxy. VirtualBox errors, such as a VM that does not exist, are never retried. A request whose connection was reset may already have been processed, so only raise it for a flaky webservice. Default: 0.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"proxy_url": schema.StringAttribute{
+				Optional:    true,
+				Description: "URL of the HTTP or SOCKS proxy the webservice is reached through, e.g. http://proxy:3128 or socks5://proxy:1080. Default: the proxy set by the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.",
+			},
+			"tls_ca_cert": schema.StringAttribute{
+				Optional:    true,
+				Description: "PEM encoded CA certificate(s) trusted, in addition to the host's root CAs, to verify an https endpoint, e.g. file(\"ca.pem\") for a private CA.",
+			},
+			"tls_insecure_skip_verify": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether the certificate of an https endpoint is accepted without verification. This exposes the webservice credentials to anyone able to intercept the connection: only use it for testing. Default: false.",
+			},
+			"tls_client_cert": schema.StringAttribute{
+				Optional:    true,
+				Description: "PEM encoded certificate the provider authenticates with to an https endpoint that requires client certificates. Requires tls_client_key.",
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("tls_client_key")),
+				},
+			},
+			"tls_client_key": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "PEM encoded private key of tls_client_cert.",
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("tls_client_cert")),
+				},
+			},
+			"verify_console": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether vboxweb_machine waits, for a few seconds, until the console of a VM it starts is reachable before reporting it started. VirtualBox reports a VM running as soon as its process is up, which can be before console operations work. Default: false.",
+			},
+			"platform_architecture": schema.StringAttribute{
+				Optional:    true,
+				Description: "CPU architecture of cloned VMs: 'x86' or 'ARM'. When set, the architecture of the source VM is not detected, which saves two webservice calls per clone on hosts that only run one architecture. Default: detected from the source VM. Also the default architecture of VMs created from scratch, which is otherwise x86.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(string(vboxapi.PlatformArchitectureX86), string(vboxapi.PlatformArchitectureARM)),
+				},
+			},
+			"tolerate_read_errors": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether a SOAP fault returned while a resource is refreshed, e.g. during terraform plan, is reported as a warning instead of an error. The resource then keeps its prior state, which may be out of date, so drift goes unnoticed until a later refresh succeeds. Not-found faults still remove the resource from state, and connection errors still fail. Default: false.",
+			},
+			"check_feature_support": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether features that older VirtualBox versions lack, such as ARM VMs and NVMe or VirtioSCSI storage controllers, are checked against the VirtualBox version of the host before they are used, failing with an error such as 'ARM VMs require VirtualBox >= 7.1, detected 7.0' instead of a SOAP fault. The version is read once, with the first such check. Default: false.",
+			},
+			"wait_for_stable_state": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether power operations, such as starting or stopping a VM, wait until the VM leaves transient states such as 'Starting' or 'Stopping' before completing. VirtualBox can report an operation done while the VM is still transitioning, which otherwise records a transient current_state and shows a diff on the next plan. Default: true.",
+			},
 		},
 	}
 }
@@ -89,18 +205,116 @@ func (p *vboxwebProvider) Configure(ctx context.Context, req provider.ConfigureR
 		return
 	}
 
+	defaultHostIP := cfg.DefaultHostIP.ValueString()
+	if defaultHostIP != "" && net.ParseIP(defaultHostIP) == nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("default_host_ip"),
+			"Invalid default_host_ip",
+			fmt.Sprintf("%q is not a valid IP address.", defaultHostIP),
+		)
+		return
+	}
+
 	client := vbox.NewClient(cfg.Endpoint.ValueString(), cfg.Username.ValueString(), cfg.Password.ValueString())
-	resp.ResourceData = client
-	resp.DataSourceData = client
+	if err := client.SetProxyURL(cfg.ProxyURL.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("proxy_url"), "Invalid proxy_url", err.Error())
+		return
+	}
+	if tlsOpts, ok := providerTLSOptions(cfg); ok {
+		if !strings.HasPrefix(strings.ToLower(cfg.Endpoint.ValueString()), "https://") {
+			resp.Diagnostics.AddWarning(
+				"TLS options ignored",
+				fmt.Sprintf("The tls_* options only apply to https endpoints, and %q is not one.", cfg.Endpoint.ValueString()),
+			)
+		}
+		if err := client.SetTLSOptions(tlsOpts); err != nil {
+			resp.Diagnostics.AddError("Invalid TLS configuration", err.Error())
+			return
+		}
+	}
+	if !cfg.RequestTimeout.IsNull() || !cfg.MaxRetries.IsNull() {
+		// The timeout is checked by durationValidator; 0 keeps the default.
+		timeout, _ := time.ParseDuration(cfg.RequestTimeout.ValueString())
+		client.SetRequestOptions(timeout, int(cfg.MaxRetries.ValueInt64()))
+	}
+	// The value is checked by durationValidator.
+	if wait, err := time.ParseDuration(cfg.MachineRegistrationWait.ValueString()); err == nil {
+		client.SetRegistrationWait(wait)
+	}
+	client.SetVerifyConsole(cfg.VerifyConsole.ValueBool())
+	if !cfg.WaitForStableState.IsNull() {
+		client.SetWaitForStableState(cfg.WaitForStableState.ValueBool())
+	}
+	client.SetPlatformArchitecture(vboxapi.PlatformArchitecture(cfg.PlatformArchitecture.ValueString()))
+	client.SetCheckFeatureSupport(cfg.CheckFeatureSupport.ValueBool())
+
+	p.mu.Lock()
+	p.clients = append(p.clients, client)
+	p.mu.Unlock()
+
+	data := &providerData{
+		client:             client,
+		defaultHostIP:      defaultHostIP,
+		tolerateReadErrors: cfg.TolerateReadErrors.ValueBool(),
+	}
+	resp.ResourceData = data
+	resp.DataSourceData = data
+}
+
+// providerTLSOptions returns the TLS options set in cfg, if any.
+func providerTLSOptions(cfg providerModel) (vbox.TLSOptions, bool) {
+	opts := vbox.TLSOptions{
+		CACertPEM:          cfg.TLSCACert.ValueString(),
+		InsecureSkipVerify: cfg.TLSInsecureSkipVerify.ValueBool(),
+		ClientCertPEM:      cfg.TLSClientCert.ValueString(),
+		ClientKeyPEM:       cfg.TLSClientKey.ValueString(),
+	}
+	return opts, opts != vbox.TLSOptions{}
+}
+
+// Close logs off the webservice sessions the provider's clients keep open.
+// It is called once the provider server stops.
+func (p *vboxwebProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var errs []error
+	for _, c := range p.clients {
+		errs = append(errs, c.Close())
+	}
+	p.clients = nil
+	return errors.Join(errs...)
 }
 
 func (p *vboxwebProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewMachineResource,
+		NewMachinePoolResource,
+		NewMachineLeaseResource,
 		NewNatPortForwardResource,
+		NewSnapshotResource,
+		NewStorageAttachmentResource,
+		NewStorageControllerResource,
+		NewSharedFolderResource,
+		NewGuestPropertyResource,
+		NewInternalNetworkResource,
+		NewExtraDataResource,
+		NewNATNetworkResource,
+		NewNATNetworkPortForwardResource,
+		NewMachineSysRqResource,
+		NewNetworkAdapterResource,
 	}
 }
 
 func (p *vboxwebProvider) DataSources(_ context.Context) []func() datasource.DataSource {
-	return nil
+	return []func() datasource.DataSource{
+		NewMachineDataSource,
+		NewMachinesDataSource,
+		NewMachineHCLDataSource,
+		NewStorageDataSource,
+		NewOperationsDataSource,
+		NewNetworkAdaptersDataSource,
+		NewHostNetworkInterfacesDataSource,
+		NewOSTypesDataSource,
+		NewHostInfoDataSource,
+	}
 }
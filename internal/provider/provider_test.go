@@ -63,6 +63,15 @@ func TestProviderSchema(t *testing.T) {
 	if !passwordAttr.IsSensitive() {
 		t.Error("expected 'password' attribute to be sensitive")
 	}
+
+	// Check tls_client_key attribute
+	keyAttr, ok := schema.Attributes["tls_client_key"]
+	if !ok {
+		t.Fatal("expected 'tls_client_key' attribute in schema")
+	}
+	if !keyAttr.IsSensitive() {
+		t.Error("expected 'tls_client_key' attribute to be sensitive")
+	}
 }
 
 func TestProviderResources(t *testing.T) {
@@ -70,8 +79,8 @@ func TestProviderResources(t *testing.T) {
 
 	resources := p.Resources(context.Background())
 
-	if len(resources) != 2 {
-		t.Fatalf("expected 2 resources, got %d", len(resources))
+	if len(resources) != 15 {
+		t.Fatalf("expected 15 resources, got %d", len(resources))
 	}
 
 	// Verify all resource factories work
@@ -88,8 +97,14 @@ func TestProviderDataSources(t *testing.T) {
 
 	dataSources := p.DataSources(context.Background())
 
-	if len(dataSources) != 0 {
-		t.Errorf("expected no data sources, got %d", len(dataSources))
+	if len(dataSources) != 9 {
+		t.Errorf("expected 9 data sources, got %d", len(dataSources))
+	}
+
+	for i, dataSourceFn := range dataSources {
+		if dataSourceFn() == nil {
+			t.Fatalf("expected non-nil data source at index %d", i)
+		}
 	}
 }
 
@@ -0,0 +1,191 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+type extraDataResource struct {
+	client *vbox.Client
+	// tolerateReadErrors is the provider's tolerate_read_errors.
+	tolerateReadErrors bool
+}
+
+type extraDataModel struct {
+	MachineID types.String `tfsdk:"machine_id"`
+	Key       types.String `tfsdk:"key"`
+	Value     types.String `tfsdk:"value"`
+	ID        types.String `tfsdk:"id"`
+}
+
+func NewExtraDataResource() resource.Resource {
+	return &extraDataResource{}
+}
+
+func (r *extraDataResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_extra_data"
+}
+
+func (r *extraDataResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.tolerateReadErrors = data.tolerateReadErrors
+}
+
+func (r *extraDataResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Manages an extra data item of a VirtualBox VM, or of the VirtualBox installation.
+
+Extra data items are key/value pairs VirtualBox keeps in its settings files. Many settings that
+have no API of their own are stored there, such as the 'VBoxInternal/' device configuration and
+the 'GUI/' settings of the VirtualBox Manager. Items of running VMs can be set, but most only take
+effect when the VM next starts.
+
+Destroying the resource deletes the item. Changes to machine_id or key will trigger replacement of
+the item.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Unique identifier for this resource (machine_id:key, with an empty machine_id for global items).",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"machine_id": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+				Description: "VirtualBox machine ID (UUID) of the VM the item belongs to. Empty string manages a global item. Default: empty string.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"key": schema.StringAttribute{
+				Required:    true,
+				Description: "Key of the extra data item, e.g. 'GUI/ScaleFactor'.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"value": schema.StringAttribute{
+				Required:    true,
+				Description: "Value of the extra data item. It cannot be empty: VirtualBox deletes items set to an empty value.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+		},
+	}
+}
+
+func (r *extraDataResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan extraDataModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.SetExtraData(ctx, plan.MachineID.ValueString(), plan.Key.ValueString(), plan.Value.ValueString()); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to set extra data", err)
+		return
+	}
+
+	plan.ID = types.StringValue(fmt.Sprintf("%s:%s", plan.MachineID.ValueString(), plan.Key.ValueString()))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *extraDataResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state extraDataModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	value, err := r.client.ReadExtraData(ctx, state.MachineID.ValueString(), state.Key.ValueString())
+	if err != nil {
+		// If the machine doesn't exist, remove from state
+		if vbox.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read extra data", err)
+		return
+	}
+
+	// If the item was deleted out of band, remove from state
+	if value == "" {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Value = types.StringValue(value)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *extraDataResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan extraDataModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only the value changes in place.
+	if err := r.client.SetExtraData(ctx, plan.MachineID.ValueString(), plan.Key.ValueString(), plan.Value.ValueString()); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to set extra data", err)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *extraDataResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state extraDataModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteExtraData(ctx, state.MachineID.ValueString(), state.Key.ValueString())
+	if err != nil && !vbox.IsNotFound(err) {
+		addClientError(&resp.Diagnostics, "Failed to delete extra data", err)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState
+func (r *extraDataResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Expected import ID format: machine_id:key, or :key for a global item
+	machineID, key, ok := strings.Cut(req.ID, ":")
+	if !ok || key == "" {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Expected import ID format: machine_id:key, or :key for a global item, got: %s", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("machine_id"), machineID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("key"), key)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
+
+// Ensure the resource implements the expected interfaces
+var _ resource.ResourceWithImportState = &extraDataResource{}
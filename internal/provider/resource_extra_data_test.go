@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func TestExtraDataResourceMetadata(t *testing.T) {
+	r := NewExtraDataResource()
+
+	req := resource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &resource.MetadataResponse{}
+
+	r.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_extra_data" {
+		t.Errorf("expected TypeName 'vboxweb_extra_data', got %q", resp.TypeName)
+	}
+}
+
+func TestExtraDataResourceSchema(t *testing.T) {
+	r := NewExtraDataResource()
+
+	req := resource.SchemaRequest{}
+	resp := &resource.SchemaResponse{}
+
+	r.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	for _, attrName := range []string{"key", "value"} {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsRequired() {
+			t.Errorf("expected %q attribute to be required", attrName)
+		}
+	}
+
+	if attr, ok := schema.Attributes["machine_id"]; !ok || !attr.IsOptional() || !attr.IsComputed() {
+		t.Error("expected optional and computed 'machine_id' attribute in schema")
+	}
+
+	if attr, ok := schema.Attributes["id"]; !ok || !attr.IsComputed() || attr.IsOptional() {
+		t.Error("expected computed only 'id' attribute in schema")
+	}
+}
@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+type guestPropertyResource struct {
+	client *vbox.Client
+	// tolerateReadErrors is the provider's tolerate_read_errors.
+	tolerateReadErrors bool
+}
+
+type guestPropertyModel struct {
+	MachineID types.String `tfsdk:"machine_id"`
+	Key       types.String `tfsdk:"key"`
+	Value     types.String `tfsdk:"value"`
+	Flags     types.String `tfsdk:"flags"`
+	ID        types.String `tfsdk:"id"`
+}
+
+func NewGuestPropertyResource() resource.Resource {
+	return &guestPropertyResource{}
+}
+
+func (r *guestPropertyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_guest_property"
+}
+
+func (r *guestPropertyResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.tolerateReadErrors = data.tolerateReadErrors
+}
+
+func (r *guestPropertyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Manages a guest property of a VirtualBox VM.
+
+Guest properties are key/value pairs shared between the host and the guest, which reads and
+writes them through the Guest Additions, e.g. with VBoxControl guestproperty get. They are
+commonly used to pass IP addresses or credentials to guests. A property set while the VM runs
+is seen by the guest right away.
+
+A value changed by the guest shows up as drift, unless the property has the RDONLYGUEST flag.
+Destroying the resource deletes the property. Changes to machine_id or key will trigger
+replacement of the property.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Unique identifier for this resource (machine_id:key).",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"machine_id": schema.StringAttribute{
+				Required:    true,
+				Description: "VirtualBox machine ID (UUID) of the VM the property belongs to.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"key": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the guest property, e.g. '/ci/ip'. Names under '/VirtualBox/' are set by VirtualBox and the Guest Additions.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"value": schema.StringAttribute{
+				Required:    true,
+				Sensitive:   true,
+				Description: "Value of the guest property. It cannot be empty: VirtualBox deletes properties set to an empty value.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"flags": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+				Description: "Comma separated flags of the property: " + strings.Join(vbox.GuestPropertyFlags, ", ") + ". TRANSIENT properties are deleted when the VM powers off, which makes Terraform recreate them. RDONLYHOST and READONLY properties cannot be changed or deleted by the provider once set. Default: empty string, no flags.",
+				Validators: []validator.String{
+					guestPropertyFlagsValidator{},
+				},
+			},
+		},
+	}
+}
+
+func (r *guestPropertyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan guestPropertyModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.SetGuestProperty(ctx, plan.MachineID.ValueString(), plan.Key.ValueString(), plan.Value.ValueString(), plan.Flags.ValueString()); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to set guest property", err)
+		return
+	}
+
+	plan.ID = types.StringValue(fmt.Sprintf("%s:%s", plan.MachineID.ValueString(), plan.Key.ValueString()))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *guestPropertyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state guestPropertyModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	prop, err := r.client.ReadGuestProperty(ctx, state.MachineID.ValueString(), state.Key.ValueString())
+	if err != nil {
+		// If the machine doesn't exist, remove from state
+		if vbox.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read guest property", err)
+		return
+	}
+
+	// If the property was deleted out of band, or was transient and the
+	// VM powered off, remove from state
+	if prop == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Value = types.StringValue(prop.Value)
+	// VirtualBox reports flags in its own spelling: keep the configured
+	// one while they hold the same flags.
+	if state.Flags.IsNull() || !vbox.GuestPropertyFlagsEqual(state.Flags.ValueString(), prop.Flags) {
+		state.Flags = types.StringValue(prop.Flags)
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *guestPropertyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan guestPropertyModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only the value and flags change in place.
+	if err := r.client.SetGuestProperty(ctx, plan.MachineID.ValueString(), plan.Key.ValueString(), plan.Value.ValueString(), plan.Flags.ValueString()); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to set guest property", err)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *guestPropertyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state guestPropertyModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteGuestProperty(ctx, state.MachineID.ValueString(), state.Key.ValueString())
+	if err != nil && !vbox.IsNotFound(err) {
+		addClientError(&resp.Diagnostics, "Failed to delete guest property", err)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState
+func (r *guestPropertyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Expected import ID format: machine_id:key
+	machineID, key, ok := strings.Cut(req.ID, ":")
+	if !ok || machineID == "" || key == "" {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Expected import ID format: machine_id:key, got: %s", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("machine_id"), machineID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("key"), key)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
+
+// guestPropertyFlagsValidator checks that a string is a comma separated
+// list of guest property flags.
+type guestPropertyFlagsValidator struct{}
+
+func (v guestPropertyFlagsValidator) Description(_ context.Context) string {
+	return "value must be a comma separated list of " + strings.Join(vbox.GuestPropertyFlags, ", ")
+}
+
+func (v guestPropertyFlagsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v guestPropertyFlagsValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() || req.ConfigValue.ValueString() == "" {
+		return
+	}
+	for _, flag := range strings.Split(req.ConfigValue.ValueString(), ",") {
+		flag = strings.ToUpper(strings.TrimSpace(flag))
+		if flag != "NONE" && !slices.Contains(vbox.GuestPropertyFlags, flag) {
+			resp.Diagnostics.AddAttributeError(req.Path, "Invalid guest property flags",
+				fmt.Sprintf("%s, got %q", v.Description(ctx), req.ConfigValue.ValueString()))
+			return
+		}
+	}
+}
+
+// Ensure the resource implements the expected interfaces
+var _ resource.ResourceWithImportState = &guestPropertyResource{}
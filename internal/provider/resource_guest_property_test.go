@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestGuestPropertyResourceMetadata(t *testing.T) {
+	r := NewGuestPropertyResource()
+
+	req := resource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &resource.MetadataResponse{}
+
+	r.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_guest_property" {
+		t.Errorf("expected TypeName 'vboxweb_guest_property', got %q", resp.TypeName)
+	}
+}
+
+func TestGuestPropertyResourceSchema(t *testing.T) {
+	r := NewGuestPropertyResource()
+
+	req := resource.SchemaRequest{}
+	resp := &resource.SchemaResponse{}
+
+	r.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	for _, attrName := range []string{"machine_id", "key", "value"} {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsRequired() {
+			t.Errorf("expected %q attribute to be required", attrName)
+		}
+	}
+
+	if attr, ok := schema.Attributes["flags"]; !ok || !attr.IsOptional() || !attr.IsComputed() {
+		t.Error("expected optional computed 'flags' attribute in schema")
+	}
+	if attr, ok := schema.Attributes["id"]; !ok || !attr.IsComputed() || attr.IsOptional() {
+		t.Error("expected computed only 'id' attribute in schema")
+	}
+}
+
+func TestGuestPropertyFlagsValidator(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"", false},
+		{"TRANSIENT", false},
+		{"rdonlyguest, transient", false},
+		{"NONE", false},
+		{"TRANSIENT,", true},
+		{"VOLATILE", true},
+		{"TRANSIENT;RDONLYGUEST", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			req := validator.StringRequest{Path: path.Root("flags"), ConfigValue: types.StringValue(tc.input)}
+			resp := &validator.StringResponse{}
+			guestPropertyFlagsValidator{}.ValidateString(context.Background(), req, resp)
+			if resp.Diagnostics.HasError() != tc.wantErr {
+				t.Errorf("ValidateString(%q) error = %v, wantErr %v", tc.input, resp.Diagnostics, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+type internalNetworkResource struct {
+	client *vbox.Client
+	// tolerateReadErrors is the provider's tolerate_read_errors.
+	tolerateReadErrors bool
+}
+
+type internalNetworkModel struct {
+	Name types.String `tfsdk:"name"`
+
+	// Computed
+	ID types.String `tfsdk:"id"`
+	// Members holds internalNetworkMemberAttrTypes objects.
+	Members    types.List `tfsdk:"members"`
+	MachineIDs types.List `tfsdk:"machine_ids"`
+}
+
+var internalNetworkMemberAttrTypes = map[string]attr.Type{
+	"machine_id":   types.StringType,
+	"machine_name": types.StringType,
+	"slot":         types.Int64Type,
+}
+
+func NewInternalNetworkResource() resource.Resource {
+	return &internalNetworkResource{}
+}
+
+func (r *internalNetworkResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_internal_network"
+}
+
+func (r *internalNetworkResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.tolerateReadErrors = data.tolerateReadErrors
+}
+
+func (r *internalNetworkResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Tracks a VirtualBox internal network.
+
+Internal networks connect VMs of the same host with each other, and with nothing else. VirtualBox
+has no object for them: an internal network exists while network adapters are attached to it by
+name. This resource creates nothing on the VirtualBox host. It gives the network a name other
+resources can refer to, and lists the network adapters attached to it, found by scanning the
+adapters of all VMs.
+
+Destroying the resource leaves the VMs and their adapters alone. Changes to name will trigger
+replacement of the resource.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Unique identifier for this resource (the network name).",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the internal network, as set on the network adapters attached to it, e.g. 'intnet'.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 127),
+				},
+			},
+			"members": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The enabled network adapters attached to the network, ordered by VM name and slot. Refreshed on every read.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"machine_id": schema.StringAttribute{
+							Computed:    true,
+							Description: "VirtualBox machine ID (UUID) of the VM.",
+						},
+						"machine_name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name of the VM.",
+						},
+						"slot": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Slot of the network adapter, starting at 0.",
+						},
+					},
+				},
+			},
+			"machine_ids": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Machine IDs of the VMs attached to the network, in the order of members, without duplicates.",
+			},
+		},
+	}
+}
+
+func (r *internalNetworkResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan internalNetworkModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	members, err := r.client.ReadInternalNetworkMembers(ctx, plan.Name.ValueString())
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to read internal network members", err)
+		return
+	}
+
+	plan.ID = plan.Name
+	setInternalNetworkMembers(&plan, members)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *internalNetworkResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state internalNetworkModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The network is never gone: without members it merely has none.
+	members, err := r.client.ReadInternalNetworkMembers(ctx, state.Name.ValueString())
+	if err != nil {
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read internal network members", err)
+		return
+	}
+
+	setInternalNetworkMembers(&state, members)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *internalNetworkResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// The name requires replacement; the rest is computed.
+	var plan, state internalNetworkModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.Members = state.Members
+	plan.MachineIDs = state.MachineIDs
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *internalNetworkResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+	// There is nothing to delete on the VirtualBox host.
+}
+
+// ImportState implements resource.ResourceWithImportState
+func (r *internalNetworkResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Expected import ID format: name
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
+
+// setInternalNetworkMembers stores members, and the machine IDs they
+// belong to, in m.
+func setInternalNetworkMembers(m *internalNetworkModel, members []vbox.InternalNetworkMember) {
+	elems := make([]attr.Value, 0, len(members))
+	var ids []string
+	seen := map[string]bool{}
+	for _, member := range members {
+		elems = append(elems, types.ObjectValueMust(internalNetworkMemberAttrTypes, map[string]attr.Value{
+			"machine_id":   types.StringValue(member.MachineID),
+			"machine_name": types.StringValue(member.MachineName),
+			"slot":         types.Int64Value(int64(member.Slot)),
+		}))
+		if !seen[member.MachineID] {
+			seen[member.MachineID] = true
+			ids = append(ids, member.MachineID)
+		}
+	}
+	m.Members = types.ListValueMust(types.ObjectType{AttrTypes: internalNetworkMemberAttrTypes}, elems)
+	m.MachineIDs = stringList(ids)
+}
+
+// Ensure the resource implements the expected interfaces
+var _ resource.ResourceWithImportState = &internalNetworkResource{}
@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+func TestInternalNetworkResourceMetadata(t *testing.T) {
+	r := NewInternalNetworkResource()
+
+	req := resource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &resource.MetadataResponse{}
+
+	r.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_internal_network" {
+		t.Errorf("expected TypeName 'vboxweb_internal_network', got %q", resp.TypeName)
+	}
+}
+
+func TestInternalNetworkResourceSchema(t *testing.T) {
+	r := NewInternalNetworkResource()
+
+	req := resource.SchemaRequest{}
+	resp := &resource.SchemaResponse{}
+
+	r.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	if attr, ok := schema.Attributes["name"]; !ok || !attr.IsRequired() {
+		t.Error("expected required 'name' attribute in schema")
+	}
+
+	for _, attrName := range []string{"id", "members", "machine_ids"} {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsComputed() || attr.IsOptional() {
+			t.Errorf("expected %q attribute to be computed only", attrName)
+		}
+	}
+}
+
+func TestSetInternalNetworkMembers(t *testing.T) {
+	var m internalNetworkModel
+	setInternalNetworkMembers(&m, []vbox.InternalNetworkMember{
+		{MachineID: "uuid-a", MachineName: "a", Slot: 0},
+		{MachineID: "uuid-a", MachineName: "a", Slot: 2},
+		{MachineID: "uuid-b", MachineName: "b", Slot: 1},
+	})
+
+	if n := len(m.Members.Elements()); n != 3 {
+		t.Errorf("expected 3 members, got %d", n)
+	}
+	var ids []string
+	m.MachineIDs.ElementsAs(context.Background(), &ids, false)
+	if len(ids) != 2 || ids[0] != "uuid-a" || ids[1] != "uuid-b" {
+		t.Errorf("machine_ids = %v, want [uuid-a uuid-b]", ids)
+	}
+
+	// A network without members has empty, not null, lists.
+	setInternalNetworkMembers(&m, nil)
+	if m.Members.IsNull() || len(m.Members.Elements()) != 0 || !m.MachineIDs.Equal(types.ListValueMust(types.StringType, nil)) {
+		t.Errorf("expected empty lists, got members %v and machine_ids %v", m.Members, m.MachineIDs)
+	}
+}
@@ -3,39 +3,102 @@ package provider
 import (
 	"context"
 	"fmt"
+	"maps"
+	"math"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
 )
 
 type machineResource struct {
 	client *vbox.Client
+	// tolerateReadErrors is the provider's tolerate_read_errors.
+	tolerateReadErrors bool
 }
 
 type machineModel struct {
-	ID           types.String `tfsdk:"id"`
-	Name         types.String `tfsdk:"name"`
-	Source       types.String `tfsdk:"source"`
-	CloneMode    types.String `tfsdk:"clone_mode"`
-	CloneOptions types.List   `tfsdk:"clone_options"`
+	ID                types.String `tfsdk:"id"`
+	Name              types.String `tfsdk:"name"`
+	Source            types.String `tfsdk:"source"`
+	CloneMode         types.String `tfsdk:"clone_mode"`
+	CloneOptions      types.List   `tfsdk:"clone_options"`
+	ExtraCloneOptions types.List   `tfsdk:"extra_clone_options"`
+	OSTypeID          types.String `tfsdk:"os_type_id"`
+	PlatformArch      types.String `tfsdk:"platform_architecture"`
+	FixedDisks        types.Bool   `tfsdk:"fixed_disks"`
+	DiskFormat        types.String `tfsdk:"disk_format"`
+
+	MemoryMB               types.Int64          `tfsdk:"memory_mb"`
+	CPUCount               types.Int64          `tfsdk:"cpu_count"`
+	MemoryBalloonMB        types.Int64          `tfsdk:"memory_balloon_mb"`
+	PageFusionEnabled      types.Bool           `tfsdk:"page_fusion_enabled"`
+	ExecutionEngine        types.String         `tfsdk:"execution_engine"`
+	VRDEKeyboardLayout     types.String         `tfsdk:"vrde_keyboard_layout"`
+	Owner                  types.String         `tfsdk:"owner"`
+	CPUIDOverrides         []cpuidOverrideModel `tfsdk:"cpuid_overrides"`
+	GraphicsController     types.String         `tfsdk:"graphics_controller"`
+	VRAMMB                 types.Int64          `tfsdk:"vram_mb"`
+	Accelerate3D           types.Bool           `tfsdk:"accelerate_3d"`
+	MonitorCount           types.Int64          `tfsdk:"monitor_count"`
+	APICEnabled            types.Bool           `tfsdk:"apic_enabled"`
+	X2APICEnabled          types.Bool           `tfsdk:"x2apic_enabled"`
+	PAEEnabled             types.Bool           `tfsdk:"pae_enabled"`
+	LongMode               types.Bool           `tfsdk:"long_mode"`
+	BootMenuMode           types.String         `tfsdk:"boot_menu_mode"`
+	AutostartEnabled       types.Bool           `tfsdk:"autostart_enabled"`
+	AutostartDelay         types.Int64          `tfsdk:"autostart_delay"`
+	ClipboardFileTransfers types.Bool           `tfsdk:"clipboard_file_transfers"`
+	DisableTimeSync        types.Bool           `tfsdk:"disable_time_sync"`
+	RecordingEnabled       types.Bool           `tfsdk:"recording_enabled"`
+	RecordingScreens       types.Set            `tfsdk:"recording_screens"`
+	RecordingFile          types.String         `tfsdk:"recording_file"`
+	RecordingFormat        types.String         `tfsdk:"recording_format"`
+	RecordingFPS           types.Int64          `tfsdk:"recording_fps"`
+	IOCacheEnabled         types.Bool           `tfsdk:"io_cache_enabled"`
+	IOCacheController      types.String         `tfsdk:"io_cache_controller"`
+	HWVirt                 types.Bool           `tfsdk:"hwvirt"`
+	NestedPaging           types.Bool           `tfsdk:"nested_paging"`
+	NestedVirt             types.Bool           `tfsdk:"nested_virt"`
 
 	DesiredState types.String `tfsdk:"state"`
+	StopMethod   types.String `tfsdk:"stop_method"`
+	ShutdownMode types.String `tfsdk:"shutdown_mode"`
 	SessionType  types.String `tfsdk:"session_type"`
 	WaitTimeout  types.String `tfsdk:"wait_timeout"`
+	// CloneOperationTimeouts maps operation descriptions to durations.
+	CloneOperationTimeouts types.Map  `tfsdk:"clone_operation_timeouts"`
+	ForceDelete            types.Bool `tfsdk:"force_delete"`
+	InstallGuestAdditions  types.Bool `tfsdk:"install_guest_additions"`
+	// RestoreSnapshot is the name or UUID of the snapshot the VM is
+	// restored to when it changes.
+	RestoreSnapshot types.String `tfsdk:"restore_snapshot"`
 
-	CurrentState types.String `tfsdk:"current_state"`
+	CurrentState    types.String `tfsdk:"current_state"`
+	CurrentSnapshot types.String `tfsdk:"current_snapshot"`
+	DiskNames       types.List   `tfsdk:"disk_names"`
 }
 
 func NewMachineResource() resource.Resource {
@@ -50,12 +113,14 @@ func (r *machineResource) Configure(_ context.Context, req resource.ConfigureReq
 	if req.ProviderData == nil {
 		return
 	}
-	r.client = req.ProviderData.(*vbox.Client)
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.tolerateReadErrors = data.tolerateReadErrors
 }
 
 func (r *machineResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		Description: "Manages a VirtualBox virtual machine. Currently supports creating VMs by cloning an existing template. Direct VM creation from scratch is planned for a future release.",
+		Description: "Manages a VirtualBox virtual machine. The VM is cloned from source when it is set, and otherwise created from scratch with the settings VirtualBox recommends for os_type_id. A VM created from scratch has no disk: attach one with vboxweb_storage_attachment.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed:    true,
@@ -70,9 +135,10 @@ func (r *machineResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 			},
 			"source": schema.StringAttribute{
 				Optional:    true,
-				Description: "Source VM name or UUID to clone from. Required for new VMs (creating VMs from scratch is not yet supported).",
+				Computed:    true,
+				Description: "Source VM name or UUID to clone from. When not set, the VM is created from scratch, which requires os_type_id. Changes force replacement, except on a VM imported without a source hint, where setting source only records it.",
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					importedSourceModifier{},
 				},
 			},
 			"clone_mode": schema.StringAttribute{
@@ -89,7 +155,7 @@ func (r *machineResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 			"clone_options": schema.ListAttribute{
 				Optional:    true,
 				ElementType: types.StringType,
-				Description: "Clone options: Link, KeepAllMACs, KeepNATMACs, KeepDiskNames, KeepHwUUIDs.",
+				Description: "Clone options: Link, KeepAllMACs, KeepNATMACs, KeepDiskNames, KeepHwUUIDs. The clone's disks are named after the new VM unless KeepDiskNames is set, in which case they keep the source's file names; either way they are created in the new VM's folder. disk_names reports the result.",
 				Validators: []validator.List{
 					listvalidator.ValueStringsAre(stringvalidator.OneOf(
 						"Link",
@@ -100,16 +166,343 @@ func (r *machineResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 					)),
 				},
 				PlanModifiers: []planmodifier.List{
-					listplanmodifier.RequiresReplace(),
+					importedCreateOnlyModifier{},
+				},
+			},
+			"extra_clone_options": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Additional clone options passed verbatim to VirtualBox after clone_options. These are NOT validated by the provider, which allows using options introduced by newer VirtualBox releases. Invalid values are rejected by VirtualBox at clone time.",
+				PlanModifiers: []planmodifier.List{
+					importedCreateOnlyModifier{},
+				},
+			},
+			"fixed_disks": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Convert the clone's hard disks to fixed-size (preallocated) disks, which perform better than dynamically allocated ones. VirtualBox clones disks with the allocation of the source's disks and cannot change it in place, so each dynamically allocated disk is copied again to a fixed-size disk that replaces it, which needs as much free space on the host as the disk's full size, plus the size of the original while it is copied. Disks that are already fixed-size are left alone. Cannot be combined with the Link clone option: the disks of linked clones are differencing images, which cannot be fixed-size. Only applies to cloned VMs. Default: false.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"disk_format": schema.StringAttribute{
+				Optional:    true,
+				Description: "Image format of the clone's hard disks: VDI, VMDK or VHD. VirtualBox clones disks in the format of the source's disks, so each disk in another format is copied again to an image in this format, named after the original with the format's extension, and the original is deleted. Allocation is kept: a fixed-size disk stays fixed-size, and fixed_disks can be combined with it. Cannot be combined with the Link clone option: the disks of linked clones are differencing images of the source's disks, which must keep their format. Only applies to cloned VMs. Default: the format of the source's disks.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(vbox.DiskFormatVDI, vbox.DiskFormatVMDK, vbox.DiskFormatVHD),
+				},
+				PlanModifiers: []planmodifier.String{
+					importedCreateOnlyModifier{},
+				},
+			},
+			"os_type_id": schema.StringAttribute{
+				Optional:    true,
+				Description: "VirtualBox OS type ID for the new VM (for example Ubuntu_64). When set, the source VM's OS type is not looked up, which allows correcting a mislabeled template; an ID the VirtualBox host does not know is rejected before the VM is cloned. Default: the source VM's OS type. Required when source is not set. The vboxweb_os_types data source lists the valid IDs.",
+				PlanModifiers: []planmodifier.String{
+					importedCreateOnlyModifier{},
+				},
+			},
+			"platform_architecture": schema.StringAttribute{
+				Optional:    true,
+				Description: "CPU architecture of a VM created from scratch: x86 or ARM. Cloned VMs have the architecture of their source, so it cannot be set together with source. Default: the provider's platform_architecture, or x86.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(string(vboxapi.PlatformArchitectureX86), string(vboxapi.PlatformArchitectureARM)),
+				},
+				PlanModifiers: []planmodifier.String{
+					importedCreateOnlyModifier{},
+				},
+			},
+			"memory_mb": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Guest memory in MB. The accepted range is checked against the VirtualBox host. The VM must be stopped to change it. Default: the memory size of the source VM, or the size VirtualBox recommends for os_type_id. Always read back from the VM, so it reports the VM's memory size when not set, e.g. after import.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, math.MaxUint32),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"cpu_count": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Number of guest CPUs. The accepted range is checked against the VirtualBox host. The VM must be stopped to change it, unless CPU hot-plug is enabled on the VM: then CPUs are attached to or detached from the running guest, up to the VM's CPU slots, and cpu_count reports the attached CPUs. Default: the CPU count of the source VM, or the count VirtualBox recommends for os_type_id. Always read back from the VM, so it reports the VM's CPU count when not set, e.g. after import.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, math.MaxUint32),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"memory_balloon_mb": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Guest memory in MB the Guest Additions hand back to the host through the balloon driver, to run more VMs than the host has RAM for. It is changed live and requires state = \"started\" and the Guest Additions running in the guest; it is only read back while the VM runs. When not set, the balloon is left alone.",
+				Validators: []validator.Int64{
+					int64validator.Between(0, math.MaxUint32),
+				},
+			},
+			"page_fusion_enabled": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Enable page fusion, which shares identical memory pages between VMs to save host memory when many similar VMs run. VirtualBox only supports it on 64-bit hosts other than macOS, for guests running the Guest Additions; on other hosts the VM may fail to start. The VM must be stopped to change it. Default: the machine's current setting.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"execution_engine": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Execution engine used to run guest code: Default, HwVirt (hardware virtualization), NativeApi (the host hypervisor API, e.g. Hyper-V on Windows), Interpreter or Recompiler. The VM must be stopped to change it, and the host must support the engine. Default: the machine's current engine.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(
+						string(vboxapi.ExecutionEngineDefault),
+						string(vboxapi.ExecutionEngineHwVirt),
+						string(vboxapi.ExecutionEngineNativeAPI),
+						string(vboxapi.ExecutionEngineInterpreter),
+						string(vboxapi.ExecutionEngineRecompiler),
+					),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"graphics_controller": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Emulated graphics controller: VMSVGA (modern Linux guests), VBoxSVGA (modern Windows guests), VBoxVGA (legacy guests), QemuRamFB (ARM guests) or Null (no display). The VM must be stopped to change it. Default: the machine's current controller.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(
+						string(vboxapi.GraphicsControllerVMSVGA),
+						string(vboxapi.GraphicsControllerVBoxSVGA),
+						string(vboxapi.GraphicsControllerVBoxVGA),
+						string(vboxapi.GraphicsControllerQemuRamFB),
+						string(vboxapi.GraphicsControllerNull),
+					),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"vram_mb": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Video memory in MB. VirtualBox accepts 1 to 256 MB; the exact range is checked against the VirtualBox host. The VM must be stopped to change it. Default: the machine's current size.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 256),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"accelerate_3d": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Enable 3D acceleration for the guest. Requires the VMSVGA or VBoxSVGA graphics controller and the Guest Additions in the guest. The VM must be stopped to change it. Default: the machine's current setting.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"monitor_count": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Number of virtual monitors. VirtualBox supports 1 to 64; the exact limit is checked against the VirtualBox host. Each monitor needs video memory, so raise vram_mb with it. The VM must be stopped to change it. Default: the machine's current count.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 64),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"apic_enabled": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Expose an APIC to the guest. Disabling it also disables x2APIC. The VM must be stopped to change it. Default: the machine's current setting.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+					impliedBoolModifier{other: path.Root("x2apic_enabled"), when: true, value: true},
+				},
+			},
+			"x2apic_enabled": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Expose the APIC in x2APIC mode, which some modern guests require. Enabling it also enables the APIC, so it cannot be combined with apic_enabled = false. The VM must be stopped to change it. Default: the machine's current setting.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+					impliedBoolModifier{other: path.Root("apic_enabled"), when: false, value: false},
+				},
+			},
+			"pae_enabled": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Expose PAE/NX to the guest, which some 32-bit guests need to use more than 4 GB of memory or the NX bit. Disabling it also disables long_mode. Only supported on x86 VMs. The VM must be stopped to change it. Default: the machine's current setting.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+					impliedBoolModifier{other: path.Root("long_mode"), when: true, value: true},
+				},
+			},
+			"long_mode": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Expose long mode to the guest, which 64-bit guests need. Enabling it also enables PAE, so it cannot be combined with pae_enabled = false. Only supported on x86 VMs. The VM must be stopped to change it. Default: the machine's current setting.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+					impliedBoolModifier{other: path.Root("pae_enabled"), when: false, value: false},
+				},
+			},
+			"boot_menu_mode": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether the firmware shows its boot menu: disabled, menuonly or messageandmenu. Use disabled to avoid delaying unattended boots. The VM must be stopped to change it. Default: the machine's current mode.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(slices.Sorted(maps.Keys(bootMenuModes))...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"autostart_enabled": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Start the VM when the VirtualBox host boots. This also requires the host's autostart service to be configured; see the Autostart section of the documentation. Can be changed while the VM is running. Default: the machine's current setting.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"autostart_delay": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Seconds the host's autostart service waits before starting the VM, e.g. to start a database before the VMs using it. Can be changed while the VM is running. Default: the machine's current delay.",
+				Validators: []validator.Int64{
+					int64validator.Between(0, math.MaxUint32),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"clipboard_file_transfers": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Allow copying files between the host and the guest through the shared clipboard. Requires the Guest Additions and a shared clipboard mode other than disabled. Can be changed while the VM is running. Default: the machine's current setting.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"disable_time_sync": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Stop the Guest Additions from synchronizing the guest clock with the host, for guests that run their own NTP client. Stored in the machine extra data item " + vbox.TimeSyncDisabledExtraDataKey + ". Can be changed while the VM is running, but only takes effect when the VM next starts. Default: the machine's current setting.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"recording_enabled": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Record the VM's screens to a video file on the VirtualBox host, e.g. to capture CI runs of GUI VMs. At least one screen must be set up for recording. Can be changed while the VM is running. Default: the machine's current setting.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"recording_screens": schema.SetAttribute{
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.Int64Type,
+				Description: "IDs of the screens to record, starting at 0 for the first monitor; other screens are not recorded. IDs must be lower than monitor_count. The recording options of a running VM can only be changed while recording_enabled is false. Default: the machine's current screens.",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueInt64sAre(int64validator.Between(0, 63)),
+				},
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"recording_file": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Path of the WebM file the recording is written to. The file is written by VirtualBox on the VirtualBox host, not on the machine running Terraform, so the path must be writable by the host's VirtualBox user. Only one screen can be recorded to a given file. Default: the machine's current file, which VirtualBox puts in the VM's folder.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"recording_format": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Video codec of the recording: vp8, vp9 or av1. Support depends on the VirtualBox host and is checked against it. Default: the machine's current codec.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(slices.Sorted(maps.Keys(recordingFormats))...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"recording_fps": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Frame rate of the recording, from 1 to 60 frames per second. Default: the machine's current rate.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 60),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"io_cache_enabled": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Use the host's I/O cache for the VM's disks. The cache speeds up disk access, but writes the host has not flushed are lost if it crashes. Applies to every storage controller of the VM, or only to `io_cache_controller` when set. Requires the VM to be powered off. Default: the current setting, when all controllers share it.",
+				PlanModifiers: []planmodifier.Bool{
+					useStateUnlessChangedModifier{paths: []path.Path{path.Root("io_cache_controller")}},
+				},
+			},
+			"io_cache_controller": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name of the storage controller that `io_cache_enabled` applies to. Default: all controllers.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.AlsoRequires(path.MatchRoot("io_cache_enabled")),
+				},
+			},
+			"vrde_keyboard_layout": schema.StringAttribute{
+				Optional:    true,
+				Description: "Keyboard layout used by RDP sessions to the VM's remote display (VRDE), for example de-de or fr-fr. Stored in the VRDE property " + vbox.VRDEKeyboardLayoutProperty + ". Can be changed while the VM is running. Removing the attribute clears the property.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"owner": schema.StringAttribute{
+				Optional:    true,
+				Description: "Owner of the VM, e.g. a team name, for quota or cleanup tooling on shared hosts. Stored in the machine extra data item " + vbox.OwnerExtraDataKey + ". Can be changed while the VM is running. Removing the attribute clears the item.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
 				},
 			},
 
 			"state": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
-				Description: "Desired state: started or stopped. Default: stopped.",
+				Description: "Desired state: started, stopped, paused or saved. A paused VM stays in memory with its execution suspended; a saved VM has its state written to disk and resumes from it on the next start. Powered-off VMs are started before they are paused or saved. Default: stopped.",
 				Validators: []validator.String{
-					stringvalidator.OneOf("started", "stopped"),
+					stringvalidator.OneOf("started", "stopped", "paused", "saved"),
+				},
+			},
+			"stop_method": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "How the VM is stopped when converging to the stopped state: poweroff or savestate. With savestate, the VM state is saved to disk and the VM is left in the Saved state, which counts as stopped; it resumes from that state instead of cold booting on the next start. Default: poweroff.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(vbox.StopMethodPowerOff, vbox.StopMethodSaveState),
+				},
+			},
+			"shutdown_mode": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "How the poweroff stop method powers off a running VM: acpi or poweroff. With acpi, the VM's power button is pressed so that the guest OS shuts down cleanly; if the VM is still running after `wait_timeout`, it is powered off. With poweroff, the power is cut, like pulling the plug, which may corrupt guest filesystems. Default: poweroff.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(vbox.ShutdownModeACPI, vbox.ShutdownModePowerOff),
 				},
 			},
 			"session_type": schema.StringAttribute{
@@ -122,9 +515,109 @@ func (r *machineResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 				Computed:    true,
 				Description: "How long to wait for long operations (clone/start/stop/deleteConfig). Default: 20m.",
 			},
+			"clone_operation_timeouts": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "How long each operation of the clone may take, keyed by part of the VirtualBox operation description, e.g. `{ \"hard disk\" = \"2h\", default = \"5m\" }`. Keys match case-insensitively and the longest matching key wins; the `default` entry applies to operations that match no other key. wait_timeout still bounds the whole clone. Only used when the VM is created.",
+				Validators: []validator.Map{
+					mapvalidator.ValueStringsAre(durationValidator{}),
+				},
+			},
+			"force_delete": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Delete the VM even if other machines, such as linked clones, use its disks. Deleting it breaks those machines. Default: false.",
+			},
+			"install_guest_additions": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "After the VM is created and started, update its Guest Additions from the Guest Additions ISO shipped with VirtualBox. Requires state = \"started\" and a Windows, Linux or Solaris guest that already runs the Guest Additions. Only applies when the VM is created. Default: false.",
+			},
+			"restore_snapshot": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name or UUID of a snapshot to restore the VM to, e.g. to roll it back to a known baseline before a test run. The VM is restored when this value changes on update: it is powered off first if it is running, restored, then brought to `state`. Settings configured on the VM are applied again after the restore. Not used when the VM is created.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
 			"current_state": schema.StringAttribute{
 				Computed:    true,
-				Description: "Observed VirtualBox machine state (best-effort).",
+				Description: "Observed VirtualBox machine state, exactly as VirtualBox reports it, e.g. 'PoweredOff', 'Running', 'Saved' or 'Aborted'. Transient states such as 'Starting', 'Stopping', 'Saving' or 'Restoring' are reported as well, so a read during a transition shows it.",
+			},
+			"hwvirt": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether the VM uses hardware virtualization (VT-x / AMD-V). Only reported for x86 VMs.",
+				PlanModifiers: []planmodifier.Bool{
+					useStateUnlessChangedModifier{paths: []path.Path{path.Root("execution_engine")}},
+				},
+			},
+			"nested_paging": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether the VM uses nested paging (Intel EPT / AMD RVI). Only reported for x86 VMs.",
+				PlanModifiers: []planmodifier.Bool{
+					useStateUnlessChangedModifier{paths: []path.Path{path.Root("execution_engine")}},
+				},
+			},
+			"nested_virt": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether hardware virtualization is exposed to the guest, so that it can run its own hypervisor (nested virtualization). Only reported for x86 VMs.",
+				PlanModifiers: []planmodifier.Bool{
+					useStateUnlessChangedModifier{paths: []path.Path{path.Root("execution_engine")}},
+				},
+			},
+			"current_snapshot": schema.StringAttribute{
+				Computed:    true,
+				Description: "Name of the VM's current snapshot, or an empty string if the VM has no snapshots. Can be used to check that a VM is at a known snapshot.",
+				PlanModifiers: []planmodifier.String{
+					useStateUnlessChangedModifier{paths: []path.Path{path.Root("restore_snapshot")}},
+				},
+			},
+			"disk_names": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "File names of the hard disks attached to the VM, ordered by controller, port and device. After the clone they are named after the VM, or after the source's disks with the KeepDiskNames clone option.",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"cpuid_overrides": schema.ListNestedBlock{
+				Description: "CPUID leaf overrides reported to the guest instead of the host's values, e.g. to mask CPU features so that the VM can move between hosts with different CPUs. Only supported on x86 machines. The VM must be stopped to change them. Leaves set outside of Terraform are left alone.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"leaf": schema.StringAttribute{
+							Required:    true,
+							Description: "CPUID leaf (EAX input), e.g. 0x80000001.",
+							Validators:  []validator.String{cpuidValueValidator()},
+						},
+						"sub_leaf": schema.StringAttribute{
+							Optional:    true,
+							Description: "CPUID sub-leaf (ECX input). Default: 0.",
+							Validators:  []validator.String{cpuidValueValidator()},
+						},
+						"eax": schema.StringAttribute{
+							Required:    true,
+							Description: "Value returned in EAX.",
+							Validators:  []validator.String{cpuidValueValidator()},
+						},
+						"ebx": schema.StringAttribute{
+							Required:    true,
+							Description: "Value returned in EBX.",
+							Validators:  []validator.String{cpuidValueValidator()},
+						},
+						"ecx": schema.StringAttribute{
+							Required:    true,
+							Description: "Value returned in ECX.",
+							Validators:  []validator.String{cpuidValueValidator()},
+						},
+						"edx": schema.StringAttribute{
+							Required:    true,
+							Description: "Value returned in EDX.",
+							Validators:  []validator.String{cpuidValueValidator()},
+						},
+					},
+				},
 			},
 		},
 	}
@@ -137,6 +630,10 @@ func normalizeDesiredState(s string) string {
 		return "started"
 	case "stopped", "poweredoff", "powered_off", "off":
 		return "stopped"
+	case "paused", "suspended":
+		return "paused"
+	case "saved":
+		return "saved"
 	default:
 		return s
 	}
@@ -156,6 +653,53 @@ func parseTimeout(s string) time.Duration {
 	return d
 }
 
+// stringList converts strings to a list value.
+func stringList(values []string) types.List {
+	elems := make([]attr.Value, 0, len(values))
+	for _, v := range values {
+		elems = append(elems, types.StringValue(v))
+	}
+	return types.ListValueMust(types.StringType, elems)
+}
+
+// durationValidator checks that a string is a positive Go duration, e.g. 90m.
+type durationValidator struct{}
+
+func (v durationValidator) Description(_ context.Context) string {
+	return "value must be a positive duration, e.g. 30s, 5m or 2h"
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if d, err := time.ParseDuration(req.ConfigValue.ValueString()); err != nil || d <= 0 {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid duration",
+			fmt.Sprintf("%s, got: %q", v.Description(ctx), req.ConfigValue.ValueString()))
+	}
+}
+
+// operationTimeouts converts clone_operation_timeouts. Values are checked by
+// durationValidator.
+func operationTimeouts(m types.Map) vbox.OperationTimeouts {
+	if m.IsNull() || m.IsUnknown() {
+		return nil
+	}
+	out := make(vbox.OperationTimeouts, len(m.Elements()))
+	for key, v := range m.Elements() {
+		if s, ok := v.(types.String); ok {
+			if d, err := time.ParseDuration(s.ValueString()); err == nil && d > 0 {
+				out[key] = d
+			}
+		}
+	}
+	return out
+}
+
 func (r *machineResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var plan machineModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -163,15 +707,7 @@ func (r *machineResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	// Check if source is provided - creating VMs from scratch is not yet supported
-	if plan.Source.IsNull() || strings.TrimSpace(plan.Source.ValueString()) == "" {
-		resp.Diagnostics.AddError(
-			"Creating VM from scratch not supported",
-			"The 'source' attribute is required. Creating VirtualBox VMs from scratch is not yet implemented. "+
-				"Please specify a source VM name or UUID to clone from, or import an existing machine.",
-		)
-		return
-	}
+	fromScratch := plan.Source.IsNull() || strings.TrimSpace(plan.Source.ValueString()) == ""
 
 	if plan.CloneMode.IsNull() || plan.CloneMode.ValueString() == "" {
 		plan.CloneMode = types.StringValue("MachineState")
@@ -179,34 +715,210 @@ func (r *machineResource) Create(ctx context.Context, req resource.CreateRequest
 	if plan.DesiredState.IsNull() || plan.DesiredState.ValueString() == "" {
 		plan.DesiredState = types.StringValue("stopped")
 	}
+	if plan.StopMethod.IsNull() || plan.StopMethod.ValueString() == "" {
+		plan.StopMethod = types.StringValue(vbox.StopMethodPowerOff)
+	}
+	if plan.ShutdownMode.IsNull() || plan.ShutdownMode.ValueString() == "" {
+		plan.ShutdownMode = types.StringValue(vbox.ShutdownModePowerOff)
+	}
 	if plan.SessionType.IsNull() || plan.SessionType.ValueString() == "" {
 		plan.SessionType = types.StringValue("headless")
 	}
 	if plan.WaitTimeout.IsNull() || plan.WaitTimeout.ValueString() == "" {
 		plan.WaitTimeout = types.StringValue("20m")
 	}
+	if plan.ForceDelete.IsNull() || plan.ForceDelete.IsUnknown() {
+		plan.ForceDelete = types.BoolValue(false)
+	}
+	if plan.InstallGuestAdditions.IsNull() || plan.InstallGuestAdditions.IsUnknown() {
+		plan.InstallGuestAdditions = types.BoolValue(false)
+	}
 
 	desired := normalizeDesiredState(plan.DesiredState.ValueString())
 	timeout := parseTimeout(plan.WaitTimeout.ValueString())
 
-	uuid, curState, err := r.client.CloneAndConverge(ctx, vbox.CloneRequest{
-		Name:         plan.Name.ValueString(),
-		Source:       plan.Source.ValueString(),
-		CloneMode:    plan.CloneMode.ValueString(),
-		CloneOptions: vbox.ListToStrings(plan.CloneOptions),
-		DesiredState: desired,
-		SessionType:  plan.SessionType.ValueString(),
-		Timeout:      timeout,
-	})
+	if plan.InstallGuestAdditions.ValueBool() && desired != "started" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("install_guest_additions"),
+			"Guest Additions require a running VM",
+			"install_guest_additions updates the Guest Additions inside the guest, so the VM must be running: set state = \"started\".",
+		)
+		return
+	}
+
+	settings, err := machineSettingsChanges(plan, nil)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to clone VM", err.Error())
+		resp.Diagnostics.AddError("Invalid VM settings", err.Error())
 		return
 	}
 
-	plan.ID = types.StringValue(uuid)
-	plan.CurrentState = types.StringValue(curState)
+	var result *vbox.CloneResult
+	if fromScratch {
+		result, err = r.client.CreateAndConverge(ctx, vbox.CreateRequest{
+			Name:                 plan.Name.ValueString(),
+			OSTypeID:             plan.OSTypeID.ValueString(),
+			PlatformArchitecture: vboxapi.PlatformArchitecture(plan.PlatformArch.ValueString()),
+			Settings:             settings,
+			DesiredState:         desired,
+			StopMethod:           plan.StopMethod.ValueString(),
+			ShutdownMode:         plan.ShutdownMode.ValueString(),
+			SessionType:          plan.SessionType.ValueString(),
+			Timeout:              timeout,
+		})
+		if err != nil {
+			addClientError(&resp.Diagnostics, "Failed to create VM", err)
+			if result == nil {
+				return
+			}
+		}
+	} else {
+		result, err = r.client.CloneAndConverge(ctx, vbox.CloneRequest{
+			Name:              plan.Name.ValueString(),
+			Source:            plan.Source.ValueString(),
+			CloneMode:         plan.CloneMode.ValueString(),
+			CloneOptions:      vbox.ListToStrings(plan.CloneOptions),
+			ExtraCloneOptions: vbox.ListToStrings(plan.ExtraCloneOptions),
+			OSTypeID:          plan.OSTypeID.ValueString(),
+			Settings:          settings,
+			DesiredState:      desired,
+			StopMethod:        plan.StopMethod.ValueString(),
+			ShutdownMode:      plan.ShutdownMode.ValueString(),
+			SessionType:       plan.SessionType.ValueString(),
+			Timeout:           timeout,
+			OperationTimeouts: operationTimeouts(plan.CloneOperationTimeouts),
+			FixedDisks:        plan.FixedDisks.ValueBool(),
+			DiskFormat:        plan.DiskFormat.ValueString(),
+		})
+		if err != nil {
+			addClientError(&resp.Diagnostics, "Failed to clone VM", err)
+			if result == nil {
+				return
+			}
+		}
+	}
+	// A VM that failed after it was registered is kept in state, where the
+	// error taints it, rather than orphaned.
+
+	for _, warning := range result.Warnings {
+		resp.Diagnostics.AddWarning("VM clone warning", warning)
+	}
+
+	plan.ID = types.StringValue(result.ID)
+	plan.CurrentState = types.StringValue(result.State)
 	plan.DesiredState = types.StringValue(desired)
 
+	current, err := r.client.ReadMachineSettings(ctx, result.ID)
+	if err != nil {
+		// The VM exists now; keep it in state and let the next refresh
+		// fill in the settings.
+		resp.Diagnostics.AddWarning("Failed to read VM settings", err.Error())
+		if plan.MemoryMB.IsUnknown() {
+			plan.MemoryMB = types.Int64Null()
+		}
+		if plan.CPUCount.IsUnknown() {
+			plan.CPUCount = types.Int64Null()
+		}
+		if plan.PageFusionEnabled.IsUnknown() {
+			plan.PageFusionEnabled = types.BoolNull()
+		}
+		if plan.ExecutionEngine.IsUnknown() {
+			plan.ExecutionEngine = types.StringNull()
+		}
+		if plan.GraphicsController.IsUnknown() {
+			plan.GraphicsController = types.StringNull()
+		}
+		if plan.VRAMMB.IsUnknown() {
+			plan.VRAMMB = types.Int64Null()
+		}
+		if plan.Accelerate3D.IsUnknown() {
+			plan.Accelerate3D = types.BoolNull()
+		}
+		if plan.MonitorCount.IsUnknown() {
+			plan.MonitorCount = types.Int64Null()
+		}
+		if plan.APICEnabled.IsUnknown() {
+			plan.APICEnabled = types.BoolNull()
+		}
+		if plan.X2APICEnabled.IsUnknown() {
+			plan.X2APICEnabled = types.BoolNull()
+		}
+		if plan.PAEEnabled.IsUnknown() {
+			plan.PAEEnabled = types.BoolNull()
+		}
+		if plan.LongMode.IsUnknown() {
+			plan.LongMode = types.BoolNull()
+		}
+		if plan.BootMenuMode.IsUnknown() {
+			plan.BootMenuMode = types.StringNull()
+		}
+		if plan.AutostartEnabled.IsUnknown() {
+			plan.AutostartEnabled = types.BoolNull()
+		}
+		if plan.AutostartDelay.IsUnknown() {
+			plan.AutostartDelay = types.Int64Null()
+		}
+		if plan.ClipboardFileTransfers.IsUnknown() {
+			plan.ClipboardFileTransfers = types.BoolNull()
+		}
+		if plan.DisableTimeSync.IsUnknown() {
+			plan.DisableTimeSync = types.BoolNull()
+		}
+		if plan.RecordingEnabled.IsUnknown() {
+			plan.RecordingEnabled = types.BoolNull()
+		}
+		if plan.RecordingScreens.IsUnknown() {
+			plan.RecordingScreens = types.SetNull(types.Int64Type)
+		}
+		if plan.RecordingFile.IsUnknown() {
+			plan.RecordingFile = types.StringNull()
+		}
+		if plan.RecordingFormat.IsUnknown() {
+			plan.RecordingFormat = types.StringNull()
+		}
+		if plan.RecordingFPS.IsUnknown() {
+			plan.RecordingFPS = types.Int64Null()
+		}
+		if plan.IOCacheEnabled.IsUnknown() {
+			plan.IOCacheEnabled = types.BoolNull()
+		}
+		plan.HWVirt = types.BoolNull()
+		plan.NestedPaging = types.BoolNull()
+		plan.NestedVirt = types.BoolNull()
+	} else {
+		setMachineSettings(&plan, current)
+	}
+
+	// Clones only have snapshots when they are taken with AllStates.
+	plan.CurrentSnapshot = types.StringValue("")
+	if info, err := r.client.GetMachineInfoByID(ctx, result.ID); err != nil {
+		resp.Diagnostics.AddWarning("Failed to read VM snapshot", err.Error())
+	} else {
+		plan.CurrentSnapshot = types.StringValue(info.CurrentSnapshot)
+		if result.State == "" {
+			plan.CurrentState = types.StringValue(info.State)
+		}
+	}
+
+	plan.DiskNames = types.ListNull(types.StringType)
+	if names, err := r.client.ReadDiskNames(ctx, result.ID); err != nil {
+		resp.Diagnostics.AddWarning("Failed to read VM disk names", err.Error())
+	} else {
+		plan.DiskNames = stringList(names)
+	}
+
+	// A failed install leaves the VM in state, where the error taints it
+	// like a failed provisioner would.
+	if !resp.Diagnostics.HasError() && plan.InstallGuestAdditions.ValueBool() {
+		if err := r.client.InstallGuestAdditionsByID(ctx, result.ID, timeout); err != nil {
+			addClientError(&resp.Diagnostics, "Failed to install Guest Additions", err)
+		}
+	}
+	if !resp.Diagnostics.HasError() && !plan.MemoryBalloonMB.IsNull() {
+		if err := r.client.SetMemoryBalloonByID(ctx, result.ID, uint32(plan.MemoryBalloonMB.ValueInt64()), timeout); err != nil {
+			addClientError(&resp.Diagnostics, "Failed to set VM memory balloon", err)
+		}
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
@@ -222,18 +934,55 @@ func (r *machineResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
-	cur, err := r.client.GetStateByID(ctx, state.ID.ValueString())
+	info, err := r.client.GetMachineInfoByID(ctx, state.ID.ValueString())
 	if err != nil {
 		// If it was deleted out of band, drop from state.
 		if vbox.IsNotFound(err) {
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		resp.Diagnostics.AddError("Failed to read VM state", err.Error())
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read VM state", err)
+		return
+	}
+	// An inaccessible VM's settings can't be read. Keep the last known state
+	// rather than dropping it: the VM comes back once its files do.
+	if !info.Accessible {
+		resp.Diagnostics.AddWarning(
+			"VM is inaccessible",
+			fmt.Sprintf("VirtualBox could not load the settings of VM %s; its state was not refreshed: %s", info.ID, info.AccessError),
+		)
 		return
 	}
 
-	state.CurrentState = types.StringValue(cur)
+	settings, err := r.client.ReadMachineSettings(ctx, state.ID.ValueString())
+	if err != nil {
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read VM settings", err)
+		return
+	}
+
+	diskNames, err := r.client.ReadDiskNames(ctx, state.ID.ValueString())
+	if err != nil {
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read VM disk names", err)
+		return
+	}
+
+	// The balloon can only be read while the VM runs; otherwise the last
+	// known size is kept.
+	if !state.MemoryBalloonMB.IsNull() {
+		balloon, ok, err := r.client.ReadMemoryBalloonByID(ctx, state.ID.ValueString())
+		if err != nil {
+			addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read VM memory balloon", err)
+			return
+		}
+		if ok {
+			state.MemoryBalloonMB = types.Int64Value(int64(balloon))
+		}
+	}
+
+	state.CurrentState = types.StringValue(info.State)
+	state.CurrentSnapshot = types.StringValue(info.CurrentSnapshot)
+	state.DiskNames = stringList(diskNames)
+	setMachineSettings(&state, settings)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
@@ -254,25 +1003,112 @@ func (r *machineResource) Update(ctx context.Context, req resource.UpdateRequest
 	if plan.DesiredState.IsNull() || plan.DesiredState.ValueString() == "" {
 		plan.DesiredState = types.StringValue("stopped")
 	}
+	if plan.StopMethod.IsNull() || plan.StopMethod.ValueString() == "" {
+		plan.StopMethod = types.StringValue(vbox.StopMethodPowerOff)
+	}
+	if plan.ShutdownMode.IsNull() || plan.ShutdownMode.ValueString() == "" {
+		plan.ShutdownMode = types.StringValue(vbox.ShutdownModePowerOff)
+	}
 	if plan.SessionType.IsNull() || plan.SessionType.ValueString() == "" {
 		plan.SessionType = types.StringValue("headless")
 	}
 	if plan.WaitTimeout.IsNull() || plan.WaitTimeout.ValueString() == "" {
 		plan.WaitTimeout = types.StringValue("20m")
 	}
+	if plan.ForceDelete.IsNull() || plan.ForceDelete.IsUnknown() {
+		plan.ForceDelete = types.BoolValue(false)
+	}
+	if plan.InstallGuestAdditions.IsNull() || plan.InstallGuestAdditions.IsUnknown() {
+		plan.InstallGuestAdditions = types.BoolValue(false)
+	}
 
 	desired := normalizeDesiredState(plan.DesiredState.ValueString())
 	timeout := parseTimeout(plan.WaitTimeout.ValueString())
 
-	cur, err := r.client.ConvergeStateByID(ctx, plan.ID.ValueString(), desired, plan.SessionType.ValueString(), timeout)
+	settingsPrior := &prior
+	if v := plan.RestoreSnapshot; !v.IsNull() && !v.IsUnknown() && !v.Equal(prior.RestoreSnapshot) {
+		// Restoring a snapshot requires the VM to be powered off.
+		if _, err := r.client.ConvergeStateByID(ctx, plan.ID.ValueString(), "stopped", plan.StopMethod.ValueString(), plan.ShutdownMode.ValueString(), plan.SessionType.ValueString(), timeout); err != nil {
+			addClientError(&resp.Diagnostics, "Failed to stop VM before restoring snapshot", err)
+			return
+		}
+		snap, err := r.client.RestoreSnapshot(ctx, plan.ID.ValueString(), v.ValueString(), timeout)
+		if err != nil {
+			addClientError(&resp.Diagnostics, "Failed to restore snapshot", err)
+			return
+		}
+		plan.CurrentSnapshot = types.StringValue(snap.Name)
+
+		// The snapshot brings back the settings it was taken with: apply
+		// those that differ from the plan again.
+		restored, err := r.client.ReadMachineSettings(ctx, plan.ID.ValueString())
+		if err != nil {
+			addClientError(&resp.Diagnostics, "Failed to read VM settings", err)
+			return
+		}
+		restoredModel := plan
+		setMachineSettings(&restoredModel, restored)
+		settingsPrior = &restoredModel
+	}
+
+	// Most settings can only be changed while the VM is off: apply them before
+	// starting it, or after stopping it. A saved VM refuses them until its
+	// saved state is discarded by converging to stopped with poweroff.
+	settings, err := machineSettingsChanges(plan, settingsPrior)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to change VM state", err.Error())
+		resp.Diagnostics.AddError("Invalid VM settings", err.Error())
 		return
 	}
+	hasSettings := !settings.Empty()
+	if hasSettings && desired != "stopped" {
+		if err := r.client.ApplyMachineSettings(ctx, plan.ID.ValueString(), settings); err != nil {
+			addClientError(&resp.Diagnostics, "Failed to update VM settings", err)
+			return
+		}
+	}
+
+	cur, err := r.client.ConvergeStateByID(ctx, plan.ID.ValueString(), desired, plan.StopMethod.ValueString(), plan.ShutdownMode.ValueString(), plan.SessionType.ValueString(), timeout)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to change VM state", err)
+		return
+	}
+
+	if hasSettings && desired == "stopped" {
+		if err := r.client.ApplyMachineSettings(ctx, plan.ID.ValueString(), settings); err != nil {
+			addClientError(&resp.Diagnostics, "Failed to update VM settings", err)
+			return
+		}
+	}
+
+	// The balloon lives in the running guest.
+	if !plan.MemoryBalloonMB.IsNull() {
+		if err := r.client.SetMemoryBalloonByID(ctx, plan.ID.ValueString(), uint32(plan.MemoryBalloonMB.ValueInt64()), timeout); err != nil {
+			addClientError(&resp.Diagnostics, "Failed to set VM memory balloon", err)
+			return
+		}
+	}
+
+	current, err := r.client.ReadMachineSettings(ctx, plan.ID.ValueString())
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to read VM settings", err)
+		return
+	}
+	setMachineSettings(&plan, current)
+
+	if plan.CurrentSnapshot.IsUnknown() {
+		info, err := r.client.GetMachineInfoByID(ctx, plan.ID.ValueString())
+		if err != nil {
+			addClientError(&resp.Diagnostics, "Failed to read VM snapshot", err)
+			return
+		}
+		plan.CurrentSnapshot = types.StringValue(info.CurrentSnapshot)
+	}
 
 	plan.CurrentState = types.StringValue(cur)
 	plan.DesiredState = types.StringValue(desired)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	// The configured create-only attributes are now recorded.
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, importedPrivateKey, nil)...)
 }
 
 func (r *machineResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -291,24 +1127,282 @@ func (r *machineResource) Delete(ctx context.Context, req resource.DeleteRequest
 		timeout = parseTimeout(state.WaitTimeout.ValueString())
 	}
 
-	if err := r.client.DeleteByID(ctx, state.ID.ValueString(), timeout); err != nil {
+	if err := r.client.DeleteByID(ctx, state.ID.ValueString(), timeout, state.ForceDelete.ValueBool()); err != nil {
 		if vbox.IsNotFound(err) {
 			return
 		}
-		resp.Diagnostics.AddError("Failed to delete VM", err.Error())
+		addClientError(&resp.Diagnostics, "Failed to delete VM", err)
+		return
+	}
+}
+
+// importedSourceModifier plans source. Imported VMs have an empty source,
+// as the VM they were cloned from is unknown, see ImportState: such a VM
+// is adopted, keeping the empty source when none is configured and taking
+// a configured one in place. Other changes force replacement, like
+// RequiresReplace.
+type importedSourceModifier struct{}
+
+func (m importedSourceModifier) Description(_ context.Context) string {
+	return "Changes force replacement, except on a VM imported without a source."
+}
+
+func (m importedSourceModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m importedSourceModifier) PlanModifyString(_ context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.ConfigValue.IsUnknown() {
+		if !req.State.Raw.IsNull() && !req.StateValue.Equal(types.StringValue("")) {
+			resp.RequiresReplace = true
+		}
+		return
+	}
+	if req.ConfigValue.IsNull() {
+		// Computed only so that an imported VM keeps its empty source.
+		resp.PlanValue = types.StringNull()
+		if req.StateValue.Equal(types.StringValue("")) {
+			resp.PlanValue = req.StateValue
+		}
+	}
+	if req.State.Raw.IsNull() || resp.PlanValue.Equal(req.StateValue) {
+		return
+	}
+	resp.RequiresReplace = !req.StateValue.Equal(types.StringValue(""))
+}
+
+// importedPrivateKey is the private state key marking an imported VM. An
+// imported VM has none of the attributes that only apply when a VM is
+// created, so configuring them must not replace it.
+const importedPrivateKey = "imported"
+
+// importedCreateOnlyModifier plans an attribute that only applies when the VM
+// is created. Changes force replacement, except on an imported VM that never
+// recorded the attribute, where the configured value is only recorded.
+type importedCreateOnlyModifier struct{}
+
+func (m importedCreateOnlyModifier) Description(_ context.Context) string {
+	return "Changes force replacement, except when the attribute is first set on an imported VM."
+}
+
+func (m importedCreateOnlyModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m importedCreateOnlyModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || req.PlanValue.Equal(req.StateValue) {
+		return
+	}
+	resp.RequiresReplace = !req.StateValue.IsNull() || !isImported(ctx, req.Private, &resp.Diagnostics)
+}
+
+func (m importedCreateOnlyModifier) PlanModifyList(ctx context.Context, req planmodifier.ListRequest, resp *planmodifier.ListResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || req.PlanValue.Equal(req.StateValue) {
 		return
 	}
+	resp.RequiresReplace = !req.StateValue.IsNull() || !isImported(ctx, req.Private, &resp.Diagnostics)
+}
+
+// privateState is the resource private state passed to plan modifiers.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+// isImported reports whether private marks an imported VM.
+func isImported(ctx context.Context, private privateState, diags *diag.Diagnostics) bool {
+	imported, d := private.GetKey(ctx, importedPrivateKey)
+	diags.Append(d...)
+	return imported != nil
+}
+
+// machineImportID is a parsed vboxweb_machine import ID.
+type machineImportID struct {
+	// Machine is the UUID or name of the machine to import.
+	Machine string
+	// Source is the optional source hint recorded in state.
+	Source string
+}
+
+// parseMachineImportID parses an import ID of the form
+// "<uuid_or_name>[|source=<source>]".
+func parseMachineImportID(id string) (machineImportID, error) {
+	segments := strings.Split(id, "|")
+	out := machineImportID{Machine: strings.TrimSpace(segments[0])}
+	if out.Machine == "" {
+		return out, fmt.Errorf("machine UUID or name is empty")
+	}
+
+	seen := map[string]bool{}
+	for _, seg := range segments[1:] {
+		key, value, ok := strings.Cut(seg, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return out, fmt.Errorf("invalid segment %q: expected key=value", seg)
+		}
+		if seen[key] {
+			return out, fmt.Errorf("duplicate key %q", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "source":
+			out.Source = strings.TrimSpace(value)
+		default:
+			return out, fmt.Errorf("unknown key %q: supported keys are: source", key)
+		}
+	}
+	return out, nil
+}
+
+// ValidateConfig implements resource.ResourceWithValidateConfig.
+func (r *machineResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var apic, x2apic, pae, longMode types.Bool
+	var recordingScreens types.Set
+	var recordingFile types.String
+	var source, osTypeID, platformArch, diskFormat types.String
+	var fixedDisks types.Bool
+	var cloneOptions types.List
+	var desiredState types.String
+	var memoryBalloon types.Int64
+	var cpuidOverrides types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("source"), &source)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("fixed_disks"), &fixedDisks)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("disk_format"), &diskFormat)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("clone_options"), &cloneOptions)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("os_type_id"), &osTypeID)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("platform_architecture"), &platformArch)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("apic_enabled"), &apic)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("x2apic_enabled"), &x2apic)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("pae_enabled"), &pae)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("long_mode"), &longMode)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("recording_screens"), &recordingScreens)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("recording_file"), &recordingFile)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("state"), &desiredState)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("memory_balloon_mb"), &memoryBalloon)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cpuid_overrides"), &cpuidOverrides)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if source.IsNull() && osTypeID.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("os_type_id"),
+			"OS type required",
+			"os_type_id must be set when source is not: a VM created from scratch gets the settings VirtualBox recommends for its OS type.",
+		)
+	}
+	if !source.IsNull() && !platformArch.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("platform_architecture"),
+			"Platform architecture requires a VM created from scratch",
+			"platform_architecture cannot be combined with source: a cloned VM has the architecture of its source.",
+		)
+	}
+	if platformArch.ValueString() == string(vboxapi.PlatformArchitectureARM) && len(cpuidOverrides.Elements()) > 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("cpuid_overrides"),
+			"CPUID overrides require an x86 VM",
+			"cpuid_overrides cannot be combined with platform_architecture = \"ARM\": only x86 VMs have CPUID leaves.",
+		)
+	}
+	if platformArch.ValueString() == string(vboxapi.PlatformArchitectureARM) {
+		var x86Features []string
+		if !pae.IsNull() {
+			x86Features = append(x86Features, "pae_enabled")
+		}
+		if !longMode.IsNull() {
+			x86Features = append(x86Features, "long_mode")
+		}
+		for _, attr := range x86Features {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attr),
+				"x86 CPU feature on an ARM VM",
+				attr+" cannot be combined with platform_architecture = \"ARM\": only x86 VMs have PAE and long mode.",
+			)
+		}
+	}
+	// Disks are converted after they are cloned.
+	var conversions []string
+	if fixedDisks.ValueBool() {
+		conversions = append(conversions, "fixed_disks")
+	}
+	if !diskFormat.IsNull() {
+		conversions = append(conversions, "disk_format")
+	}
+	for _, attr := range conversions {
+		if source.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attr),
+				"Disk conversion requires a cloned VM",
+				attr+" converts the disks of a clone; a VM created from scratch has no disk.",
+			)
+		}
+		if slices.Contains(vbox.ListToStrings(cloneOptions), "Link") {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attr),
+				"Linked clone disks cannot be converted",
+				attr+" cannot be combined with the Link clone option: the disks of a linked clone are differencing images of the source's disks, which cannot be converted.",
+			)
+		}
+	}
+
+	if !memoryBalloon.IsNull() && !desiredState.IsUnknown() && normalizeDesiredState(desiredState.ValueString()) != "started" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("memory_balloon_mb"),
+			"Memory balloon requires a running VM",
+			"memory_balloon_mb is set in the running guest by the Guest Additions, so the VM must be running: set state = \"started\".",
+		)
+	}
+
+	if !apic.IsNull() && !apic.IsUnknown() && !apic.ValueBool() && x2apic.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("x2apic_enabled"),
+			"x2APIC requires the APIC",
+			"x2apic_enabled = true cannot be combined with apic_enabled = false: x2APIC is a mode of the APIC.",
+		)
+	}
+	if !pae.IsNull() && !pae.IsUnknown() && !pae.ValueBool() && longMode.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("long_mode"),
+			"Long mode requires PAE",
+			"long_mode = true cannot be combined with pae_enabled = false: 64-bit guests need PAE.",
+		)
+	}
+	if !recordingFile.IsNull() && !recordingFile.IsUnknown() && len(recordingScreens.Elements()) > 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("recording_file"),
+			"Recording file requires a single screen",
+			fmt.Sprintf("recording_file can only be set when a single screen is recorded, got %d recording_screens: each screen is recorded to its own file.", len(recordingScreens.Elements())),
+		)
+	}
 }
 
 // ImportState implements resource.ResourceWithImportState.
-// Import ID format: machine UUID or name
+// Import ID format: machine UUID or name, optionally followed by
+// "|source=<source>" to record the source the machine was cloned from.
 func (r *machineResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// The import ID can be either a machine UUID or name
-	machineInfo, err := r.client.GetMachineInfoByID(ctx, req.ID)
+	importID, err := parseMachineImportID(req.ID)
 	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Expected import ID format: <uuid_or_name>[|source=<source>], got %q: %s", req.ID, err.Error()),
+		)
+		return
+	}
+
+	// The machine can be either a UUID or name
+	machineInfo, err := r.client.GetMachineInfoByID(ctx, importID.Machine)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to import machine",
+			fmt.Sprintf("Could not find machine with ID or name %q: %s", importID.Machine, err.Error()),
+		)
+		return
+	}
+	if !machineInfo.Accessible {
 		resp.Diagnostics.AddError(
 			"Failed to import machine",
-			fmt.Sprintf("Could not find machine with ID or name %q: %s", req.ID, err.Error()),
+			fmt.Sprintf("Machine %q is inaccessible, so its settings can't be imported: %s", importID.Machine, machineInfo.AccessError),
 		)
 		return
 	}
@@ -321,25 +1415,50 @@ func (r *machineResource) ImportState(ctx context.Context, req resource.ImportSt
 
 	// Set current state
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("current_state"), machineInfo.State)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("current_snapshot"), machineInfo.CurrentSnapshot)...)
 
-	// Set defaults for fields that can't be determined from existing machine
-	// source is unknown for imported machines - set to empty string (will require manual update)
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("source"), "")...)
+	// The source can't be determined from an existing machine. Use the hint
+	// from the import ID, if any, so that a configured source shows no
+	// change right after import. Without one, the empty source makes
+	// importedSourceModifier take a configured source in place.
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("source"), importID.Source)...)
 
 	// Set sensible defaults for clone options
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("clone_mode"), "MachineState")...)
 
-	// Determine desired state based on current state
+	// The attributes that only apply when a VM is created, such as os_type_id
+	// or clone_options, can't be determined from an existing machine either:
+	// they are left null, and importedCreateOnlyModifier records configured
+	// ones in place.
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, importedPrivateKey, []byte("true"))...)
+
+	// Determine desired state based on current state. Saved and transient
+	// states import as stopped.
 	desiredState := "stopped"
-	if machineInfo.State == "Running" {
-		desiredState = "started"
+	switch normalized := vbox.NormalizeMachineState(machineInfo.State); normalized {
+	case "started", "paused":
+		desiredState = normalized
 	}
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("state"), desiredState)...)
 
+	// A Saved machine was most likely stopped with savestate; keep it that way.
+	stopMethod := vbox.StopMethodPowerOff
+	if machineInfo.State == vboxapi.MachineStateSaved {
+		stopMethod = vbox.StopMethodSaveState
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("stop_method"), stopMethod)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("shutdown_mode"), vbox.ShutdownModePowerOff)...)
+
 	// Set default session type and timeout
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("session_type"), "headless")...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("wait_timeout"), "20m")...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("force_delete"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("install_guest_additions"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("fixed_disks"), false)...)
 }
 
-// Ensure the resource implements the ResourceWithImportState interface
-var _ resource.ResourceWithImportState = &machineResource{}
+// Ensure the resource implements the expected interfaces
+var (
+	_ resource.ResourceWithImportState    = &machineResource{}
+	_ resource.ResourceWithValidateConfig = &machineResource{}
+)
@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+type machineLeaseResource struct {
+	client *vbox.Client
+	// tolerateReadErrors is the provider's tolerate_read_errors.
+	tolerateReadErrors bool
+}
+
+type machineLeaseModel struct {
+	MachineID types.String `tfsdk:"machine_id"`
+	Holder    types.String `tfsdk:"holder"`
+	ID        types.String `tfsdk:"id"`
+}
+
+func NewMachineLeaseResource() resource.Resource {
+	return &machineLeaseResource{}
+}
+
+func (r *machineLeaseResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_lease"
+}
+
+func (r *machineLeaseResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.tolerateReadErrors = data.tolerateReadErrors
+}
+
+func (r *machineLeaseResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Leases a VirtualBox VM to a holder, so that pipelines sharing a host do not use the same VM at once.
+
+Creating the resource fails if the VM is already leased by another holder. Destroying it releases the
+lease. The lease is stored in the machine extra data item ` + vbox.LeaseExtraDataKey + `; it does not
+stop anything else from using the VM.
+
+Changes to machine_id or holder will trigger replacement of the lease.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The machine ID.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"machine_id": schema.StringAttribute{
+				Required:    true,
+				Description: "VirtualBox machine ID (UUID) of the VM to lease.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"holder": schema.StringAttribute{
+				Required:    true,
+				Description: "Who holds the lease, e.g. a CI job URL. It is reported to other holders that try to lease the VM.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+		},
+	}
+}
+
+func (r *machineLeaseResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan machineLeaseModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.AcquireLease(ctx, plan.MachineID.ValueString(), plan.Holder.ValueString()); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to lease VM", err)
+		return
+	}
+
+	plan.ID = plan.MachineID
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *machineLeaseResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state machineLeaseModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	holder, err := r.client.ReadLease(ctx, state.MachineID.ValueString())
+	if err != nil {
+		if vbox.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read VM lease", err)
+		return
+	}
+
+	// A lease released or taken over outside Terraform is gone. An
+	// imported lease has no holder in state yet and takes the VM's.
+	if holder == "" || (!state.Holder.IsNull() && holder != state.Holder.ValueString()) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Holder = types.StringValue(holder)
+	state.ID = state.MachineID
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *machineLeaseResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// All attributes require replacement.
+	var plan machineLeaseModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *machineLeaseResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state machineLeaseModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.ReleaseLease(ctx, state.MachineID.ValueString(), state.Holder.ValueString())
+	if err != nil && !vbox.IsNotFound(err) {
+		addClientError(&resp.Diagnostics, "Failed to release VM lease", err)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState. The import ID is
+// the machine ID; the holder is read from the VM.
+func (r *machineLeaseResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("machine_id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
+
+// Ensure the resource implements the expected interfaces
+var _ resource.ResourceWithImportState = &machineLeaseResource{}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func TestMachineLeaseResourceMetadata(t *testing.T) {
+	r := NewMachineLeaseResource()
+
+	req := resource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &resource.MetadataResponse{}
+
+	r.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_machine_lease" {
+		t.Errorf("expected TypeName 'vboxweb_machine_lease', got %q", resp.TypeName)
+	}
+}
+
+func TestMachineLeaseResourceSchema(t *testing.T) {
+	r := NewMachineLeaseResource()
+
+	req := resource.SchemaRequest{}
+	resp := &resource.SchemaResponse{}
+
+	r.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	for _, attrName := range []string{"machine_id", "holder"} {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsRequired() {
+			t.Errorf("expected %q attribute to be required", attrName)
+		}
+	}
+
+	if attr, ok := schema.Attributes["id"]; !ok || !attr.IsComputed() || attr.IsOptional() {
+		t.Error("expected computed only 'id' attribute in schema")
+	}
+}
@@ -0,0 +1,651 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+type machinePoolResource struct {
+	client *vbox.Client
+	// tolerateReadErrors is the provider's tolerate_read_errors.
+	tolerateReadErrors bool
+}
+
+type machinePoolModel struct {
+	ID           types.String `tfsdk:"id"`
+	NamePrefix   types.String `tfsdk:"name_prefix"`
+	NameTemplate types.String `tfsdk:"name_template"`
+	Source       types.String `tfsdk:"source"`
+	Size         types.Int64  `tfsdk:"size"`
+	CloneMode    types.String `tfsdk:"clone_mode"`
+	CloneOptions types.List   `tfsdk:"clone_options"`
+	DesiredState types.String `tfsdk:"state"`
+	SessionType  types.String `tfsdk:"session_type"`
+	WaitTimeout  types.String `tfsdk:"wait_timeout"`
+	Parallelism  types.Int64  `tfsdk:"parallelism"`
+
+	RestartTriggers  types.Map   `tfsdk:"restart_triggers"`
+	RestartBatchSize types.Int64 `tfsdk:"restart_batch_size"`
+
+	// Instances holds poolInstanceAttrTypes objects.
+	Instances types.List `tfsdk:"instances"`
+}
+
+// poolInstance is a VM of a pool.
+type poolInstance struct {
+	Index int64
+	Name  string
+	ID    string
+	State string
+}
+
+var poolInstanceAttrTypes = map[string]attr.Type{
+	"index":         types.Int64Type,
+	"name":          types.StringType,
+	"id":            types.StringType,
+	"current_state": types.StringType,
+}
+
+func NewMachinePoolResource() resource.Resource {
+	return &machinePoolResource{}
+}
+
+func (r *machinePoolResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_pool"
+}
+
+func (r *machinePoolResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.tolerateReadErrors = data.tolerateReadErrors
+}
+
+func (r *machinePoolResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Manages a pool of identical VirtualBox VMs cloned from one source.
+
+The VMs are named <name_prefix>-<index>, or after name_template, with indexes from 0 to size - 1.
+Growing the pool clones the missing instances; shrinking it deletes the instances with the highest
+indexes.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The pool's name prefix.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name_prefix": schema.StringAttribute{
+				Required:    true,
+				Description: "Prefix of the VM names. Instance i is named <name_prefix>-<i> unless name_template is set.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name_template": schema.StringAttribute{
+				Optional: true,
+				Description: "Go template rendering the name of each instance, e.g. \"{{.Prefix}}-node{{printf \"%02d\" .Index}}\". " +
+					"It is rendered with .Prefix, the name_prefix, and .Index, the instance index, and must give each instance a different name. " +
+					"Default: \"" + defaultPoolNameTemplate + "\".",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"source": schema.StringAttribute{
+				Required:    true,
+				Description: "Source VM name or UUID to clone the instances from.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"size": schema.Int64Attribute{
+				Required:    true,
+				Description: "Number of VMs in the pool. Can be changed in-place.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"clone_mode": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("MachineState"),
+				Description: "Clone mode: MachineState, MachineAndChildStates, AllStates. Default: MachineState.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("MachineState", "MachineAndChildStates", "AllStates"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"clone_options": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Clone options: Link, KeepAllMACs, KeepNATMACs, KeepDiskNames, KeepHwUUIDs.",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(stringvalidator.OneOf(
+						"Link",
+						"KeepAllMACs",
+						"KeepNATMACs",
+						"KeepDiskNames",
+						"KeepHwUUIDs",
+					)),
+				},
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"state": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("stopped"),
+				Description: "Desired state of every instance: started or stopped. Default: stopped.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("started", "stopped"),
+				},
+			},
+			"session_type": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("headless"),
+				Description: "Session type used when starting instances: headless or gui. Default: headless.",
+			},
+			"wait_timeout": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("20m"),
+				Description: "How long to wait for the long operations of each instance (clone/start/stop/deleteConfig). Default: 20m.",
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"parallelism": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(4),
+				Description: "Maximum number of instances cloned, started, stopped or deleted at once. Default: 4.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"restart_triggers": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Arbitrary values that, when changed, power-cycle the running instances, restart_batch_size at a time.",
+			},
+			"restart_batch_size": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(1),
+				Description: "Number of instances power-cycled at once when restart_triggers change. Default: 1.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"instances": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The VMs of the pool, ordered by index.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"index": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Index of the instance in the pool.",
+						},
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "VM name.",
+						},
+						"id": schema.StringAttribute{
+							Computed:    true,
+							Description: "VM UUID.",
+						},
+						"current_state": schema.StringAttribute{
+							Computed:    true,
+							Description: "Current VM state as reported by VirtualBox.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// defaultPoolNameTemplate names the instances of pools without a
+// name_template.
+const defaultPoolNameTemplate = "{{.Prefix}}-{{.Index}}"
+
+// poolNameData is what name templates are rendered with.
+type poolNameData struct {
+	Prefix string
+	Index  int64
+}
+
+// poolNamer parses a name template, defaultPoolNameTemplate if tmpl is
+// empty, and returns a function rendering the name of the instance at
+// index.
+func poolNamer(tmpl, prefix string) (func(index int64) (string, error), error) {
+	if tmpl == "" {
+		tmpl = defaultPoolNameTemplate
+	}
+	t, err := template.New("name_template").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	return func(index int64) (string, error) {
+		var b strings.Builder
+		if err := t.Execute(&b, poolNameData{Prefix: prefix, Index: index}); err != nil {
+			return "", err
+		}
+		name := strings.TrimSpace(b.String())
+		if name == "" {
+			return "", fmt.Errorf("name_template renders an empty name for index %d", index)
+		}
+		return name, nil
+	}, nil
+}
+
+// validatePoolNames checks that a name template renders valid, distinct
+// names for the instances of a pool of size. At least two instances are
+// checked, so that templates that ignore .Index are caught before the pool
+// grows.
+func validatePoolNames(tmpl, prefix string, size int64) error {
+	render, err := poolNamer(tmpl, prefix)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]int64)
+	for i := int64(0); i < max(size, 2); i++ {
+		name, err := render(i)
+		if err != nil {
+			return err
+		}
+		if prev, ok := seen[name]; ok {
+			return fmt.Errorf("instances %d and %d are both named %q; use .Index in name_template", prev, i, name)
+		}
+		seen[name] = i
+	}
+	return nil
+}
+
+// poolChanges returns the indexes of the instances missing from a pool of
+// size, and the instances beyond it, highest index first.
+func poolChanges(instances []poolInstance, size int64) (create []int64, remove []poolInstance) {
+	present := make(map[int64]bool, len(instances))
+	for _, inst := range instances {
+		if inst.Index < size {
+			present[inst.Index] = true
+		} else {
+			remove = append(remove, inst)
+		}
+	}
+	for i := int64(0); i < size; i++ {
+		if !present[i] {
+			create = append(create, i)
+		}
+	}
+	slices.SortFunc(remove, func(a, b poolInstance) int { return int(b.Index - a.Index) })
+	return create, remove
+}
+
+// forEachLimit calls fn for 0 to n-1, with at most limit calls running at
+// once, and returns their errors joined.
+func forEachLimit(n, limit int, fn func(i int) error) error {
+	if limit < 1 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(i)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// cloneInstances clones the instances at indexes. The instances cloned are
+// returned even when others fail.
+func (r *machinePoolResource) cloneInstances(ctx context.Context, m machinePoolModel, indexes []int64) ([]poolInstance, error) {
+	render, err := poolNamer(m.NameTemplate.ValueString(), m.NamePrefix.ValueString())
+	if err != nil {
+		return nil, fmt.Errorf("invalid name_template: %w", err)
+	}
+
+	cloned := make([]*poolInstance, len(indexes))
+	err = forEachLimit(len(indexes), int(m.Parallelism.ValueInt64()), func(i int) error {
+		name, err := render(indexes[i])
+		if err != nil {
+			return fmt.Errorf("invalid name_template: %w", err)
+		}
+		result, err := r.client.CloneAndConverge(ctx, vbox.CloneRequest{
+			Name:         name,
+			Source:       m.Source.ValueString(),
+			CloneMode:    m.CloneMode.ValueString(),
+			CloneOptions: vbox.ListToStrings(m.CloneOptions),
+			DesiredState: m.DesiredState.ValueString(),
+			SessionType:  m.SessionType.ValueString(),
+			Timeout:      parseTimeout(m.WaitTimeout.ValueString()),
+		})
+		// A clone that failed once registered is kept, like the others,
+		// rather than orphaned.
+		if result != nil {
+			cloned[i] = &poolInstance{Index: indexes[i], Name: name, ID: result.ID, State: result.State}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to clone %s: %w", name, err)
+		}
+		return nil
+	})
+
+	var out []poolInstance
+	for _, inst := range cloned {
+		if inst != nil {
+			out = append(out, *inst)
+		}
+	}
+	return out, err
+}
+
+// deleteInstances deletes instances. It returns the instances that could
+// not be deleted.
+func (r *machinePoolResource) deleteInstances(ctx context.Context, m machinePoolModel, instances []poolInstance) ([]poolInstance, error) {
+	failed := make([]bool, len(instances))
+	err := forEachLimit(len(instances), int(m.Parallelism.ValueInt64()), func(i int) error {
+		err := r.client.DeleteByID(ctx, instances[i].ID, parseTimeout(m.WaitTimeout.ValueString()), false)
+		if err != nil && !vbox.IsNotFound(err) {
+			failed[i] = true
+			return fmt.Errorf("failed to delete %s: %w", instances[i].Name, err)
+		}
+		return nil
+	})
+
+	var kept []poolInstance
+	for i, inst := range instances {
+		if failed[i] {
+			kept = append(kept, inst)
+		}
+	}
+	return kept, err
+}
+
+// convergeInstances brings instances to the pool's desired state, updating
+// their State.
+func (r *machinePoolResource) convergeInstances(ctx context.Context, m machinePoolModel, instances []poolInstance) error {
+	return forEachLimit(len(instances), int(m.Parallelism.ValueInt64()), func(i int) error {
+		st, err := r.client.ConvergeStateByID(ctx, instances[i].ID, m.DesiredState.ValueString(), "", "", m.SessionType.ValueString(), parseTimeout(m.WaitTimeout.ValueString()))
+		if err != nil {
+			return fmt.Errorf("failed to change the state of %s: %w", instances[i].Name, err)
+		}
+		instances[i].State = st
+		return nil
+	})
+}
+
+// restartInstances power-cycles the running instances, a batch at a time.
+func (r *machinePoolResource) restartInstances(ctx context.Context, m machinePoolModel, instances []poolInstance) error {
+	ids := make([]string, 0, len(instances))
+	for _, inst := range instances {
+		ids = append(ids, inst.ID)
+	}
+	return r.client.RollingPowerCycle(ctx, ids, int(m.RestartBatchSize.ValueInt64()), m.SessionType.ValueString(), parseTimeout(m.WaitTimeout.ValueString()))
+}
+
+// setPoolInstances stores instances in m, ordered by index.
+func setPoolInstances(m *machinePoolModel, instances []poolInstance) {
+	slices.SortFunc(instances, func(a, b poolInstance) int { return int(a.Index - b.Index) })
+	elems := make([]attr.Value, 0, len(instances))
+	for _, inst := range instances {
+		elems = append(elems, types.ObjectValueMust(poolInstanceAttrTypes, map[string]attr.Value{
+			"index":         types.Int64Value(inst.Index),
+			"name":          types.StringValue(inst.Name),
+			"id":            types.StringValue(inst.ID),
+			"current_state": types.StringValue(inst.State),
+		}))
+	}
+	m.Instances = types.ListValueMust(types.ObjectType{AttrTypes: poolInstanceAttrTypes}, elems)
+}
+
+// poolInstances returns the instances stored in m.
+func poolInstances(m machinePoolModel) []poolInstance {
+	if m.Instances.IsNull() || m.Instances.IsUnknown() {
+		return nil
+	}
+	var out []poolInstance
+	for _, v := range m.Instances.Elements() {
+		obj, ok := v.(types.Object)
+		if !ok {
+			continue
+		}
+		a := obj.Attributes()
+		out = append(out, poolInstance{
+			Index: a["index"].(types.Int64).ValueInt64(),
+			Name:  a["name"].(types.String).ValueString(),
+			ID:    a["id"].(types.String).ValueString(),
+			State: a["current_state"].(types.String).ValueString(),
+		})
+	}
+	return out
+}
+
+// ValidateConfig implements resource.ResourceWithValidateConfig.
+func (r *machinePoolResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var cfg machinePoolModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if cfg.NameTemplate.IsNull() || cfg.NameTemplate.IsUnknown() || cfg.NamePrefix.IsUnknown() || cfg.Size.IsUnknown() {
+		return
+	}
+	if err := validatePoolNames(cfg.NameTemplate.ValueString(), cfg.NamePrefix.ValueString(), cfg.Size.ValueInt64()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name_template"),
+			"Invalid name template",
+			err.Error(),
+		)
+	}
+}
+
+func (r *machinePoolResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan machinePoolModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	create, _ := poolChanges(nil, plan.Size.ValueInt64())
+	instances, err := r.cloneInstances(ctx, plan, create)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to create VM pool", err)
+	}
+
+	// Instances that were cloned are kept in state so that they are not
+	// leaked; the pool is tainted when some failed.
+	plan.ID = plan.NamePrefix
+	setPoolInstances(&plan, instances)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *machinePoolResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state machinePoolModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var instances []poolInstance
+	for _, inst := range poolInstances(state) {
+		info, err := r.client.GetMachineInfoByID(ctx, inst.ID)
+		if err != nil {
+			// Instances deleted out of band are cloned again on the next apply.
+			if vbox.IsNotFound(err) {
+				continue
+			}
+			addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read VM pool instance", err)
+			return
+		}
+		if !info.Accessible {
+			resp.Diagnostics.AddWarning(
+				"VM pool instance is inaccessible",
+				fmt.Sprintf("VirtualBox could not load the settings of instance %q; its state was not refreshed: %s", inst.Name, info.AccessError),
+			)
+		} else {
+			inst.State = info.State
+		}
+		instances = append(instances, inst)
+	}
+
+	setPoolInstances(&state, instances)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *machinePoolResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, prior machinePoolModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	create, remove := poolChanges(poolInstances(prior), plan.Size.ValueInt64())
+	var kept []poolInstance
+	for _, inst := range poolInstances(prior) {
+		if inst.Index < plan.Size.ValueInt64() {
+			kept = append(kept, inst)
+		}
+	}
+
+	// Each step records what it did, so that state matches the VMs even
+	// when a later step fails.
+	failedDeletes, err := r.deleteInstances(ctx, prior, remove)
+	kept = append(kept, failedDeletes...)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to scale down VM pool", err)
+	}
+	if !resp.Diagnostics.HasError() {
+		if err := r.convergeInstances(ctx, plan, kept); err != nil {
+			addClientError(&resp.Diagnostics, "Failed to change VM pool state", err)
+		}
+	}
+	// New instances are cloned after the restart: they already start
+	// with the new configuration.
+	if !resp.Diagnostics.HasError() && !plan.RestartTriggers.Equal(prior.RestartTriggers) {
+		if err := r.restartInstances(ctx, plan, kept); err != nil {
+			addClientError(&resp.Diagnostics, "Failed to restart VM pool", err)
+		}
+	}
+	if !resp.Diagnostics.HasError() {
+		cloned, err := r.cloneInstances(ctx, plan, create)
+		kept = append(kept, cloned...)
+		if err != nil {
+			addClientError(&resp.Diagnostics, "Failed to scale up VM pool", err)
+		}
+	}
+
+	setPoolInstances(&plan, kept)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *machinePoolResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state machinePoolModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	kept, err := r.deleteInstances(ctx, state, poolInstances(state))
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to delete VM pool", err)
+		setPoolInstances(&state, kept)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	}
+}
+
+// ModifyPlan implements resource.ResourceWithModifyPlan. The instances are
+// kept from state unless the pool has to change: when its size or desired
+// state differs from the instances, e.g. after an instance was deleted out
+// of band.
+func (r *machinePoolResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+
+	var plan, state machinePoolModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.Size.IsUnknown() || plan.DesiredState.IsUnknown() {
+		return
+	}
+
+	if poolInSync(poolInstances(state), plan.Size.ValueInt64(), plan.DesiredState.ValueString()) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("instances"), state.Instances)...)
+	}
+}
+
+// poolInSync reports whether instances make up a pool of size whose VMs
+// are in the desired state.
+func poolInSync(instances []poolInstance, size int64, desired string) bool {
+	create, remove := poolChanges(instances, size)
+	if len(create) > 0 || len(remove) > 0 {
+		return false
+	}
+	for _, inst := range instances {
+		if !stateMatches(inst.State, desired) {
+			return false
+		}
+	}
+	return true
+}
+
+// stateMatches reports whether a VM in state st counts as being in the
+// desired state. Saved VMs count as stopped, and VMs in a transient state
+// match neither.
+func stateMatches(st, desired string) bool {
+	normalized := vbox.NormalizeMachineState(st)
+	if desired == "stopped" {
+		return normalized == "stopped" || normalized == "saved"
+	}
+	return normalized == desired
+}
+
+var (
+	_ resource.ResourceWithModifyPlan     = &machinePoolResource{}
+	_ resource.ResourceWithValidateConfig = &machinePoolResource{}
+)
@@ -0,0 +1,243 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func TestMachinePoolResourceMetadata(t *testing.T) {
+	r := NewMachinePoolResource()
+
+	req := resource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &resource.MetadataResponse{}
+
+	r.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_machine_pool" {
+		t.Errorf("expected TypeName 'vboxweb_machine_pool', got %q", resp.TypeName)
+	}
+}
+
+func TestMachinePoolResourceSchema(t *testing.T) {
+	r := NewMachinePoolResource()
+
+	req := resource.SchemaRequest{}
+	resp := &resource.SchemaResponse{}
+
+	r.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	for _, attrName := range []string{"name_prefix", "source", "size"} {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsRequired() {
+			t.Errorf("expected %q attribute to be required", attrName)
+		}
+	}
+
+	for _, attrName := range []string{"id", "instances"} {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsComputed() {
+			t.Errorf("expected %q attribute to be computed", attrName)
+		}
+	}
+}
+
+func TestPoolChanges(t *testing.T) {
+	inst := func(indexes ...int64) []poolInstance {
+		var out []poolInstance
+		for _, i := range indexes {
+			out = append(out, poolInstance{Index: i, Name: fmt.Sprintf("web-%d", i)})
+		}
+		return out
+	}
+
+	tests := []struct {
+		name       string
+		instances  []poolInstance
+		size       int64
+		wantCreate []int64
+		wantRemove []int64
+	}{
+		{name: "create", size: 3, wantCreate: []int64{0, 1, 2}},
+		{name: "unchanged", instances: inst(0, 1, 2), size: 3},
+		{name: "scale up", instances: inst(0, 1), size: 4, wantCreate: []int64{2, 3}},
+		{name: "scale down", instances: inst(0, 1, 2, 3), size: 1, wantRemove: []int64{3, 2, 1}},
+		{name: "scale to zero", instances: inst(0, 1), size: 0, wantRemove: []int64{1, 0}},
+		{name: "refill gap", instances: inst(0, 2), size: 3, wantCreate: []int64{1}},
+		{name: "gap and scale down", instances: inst(0, 2, 3), size: 2, wantCreate: []int64{1}, wantRemove: []int64{3, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			create, remove := poolChanges(tt.instances, tt.size)
+			if !slices.Equal(create, tt.wantCreate) {
+				t.Errorf("create = %v, want %v", create, tt.wantCreate)
+			}
+			var removed []int64
+			for _, r := range remove {
+				removed = append(removed, r.Index)
+			}
+			if !slices.Equal(removed, tt.wantRemove) {
+				t.Errorf("remove = %v, want %v", removed, tt.wantRemove)
+			}
+		})
+	}
+}
+
+func TestPoolInSync(t *testing.T) {
+	instances := []poolInstance{
+		{Index: 0, State: "Running"},
+		{Index: 1, State: "Running"},
+	}
+	if !poolInSync(instances, 2, "started") {
+		t.Error("expected running pool of 2 to be in sync")
+	}
+	if poolInSync(instances, 3, "started") {
+		t.Error("expected pool to be out of sync when growing")
+	}
+	if poolInSync(instances, 2, "stopped") {
+		t.Error("expected running pool to be out of sync when stopping")
+	}
+}
+
+func TestStateMatches(t *testing.T) {
+	tests := []struct {
+		st      string
+		desired string
+		want    bool
+	}{
+		{st: "Running", desired: "started", want: true},
+		{st: "Starting", desired: "started", want: false},
+		{st: "PoweredOff", desired: "stopped", want: true},
+		{st: "Saved", desired: "stopped", want: true},
+		{st: "Aborted", desired: "stopped", want: true},
+		{st: "Stopping", desired: "stopped", want: false},
+		{st: "Saving", desired: "stopped", want: false},
+	}
+	for _, tt := range tests {
+		if got := stateMatches(tt.st, tt.desired); got != tt.want {
+			t.Errorf("stateMatches(%q, %q) = %t, want %t", tt.st, tt.desired, got, tt.want)
+		}
+	}
+}
+
+func TestForEachLimit(t *testing.T) {
+	var running, peak atomic.Int32
+	errBoom := errors.New("boom")
+
+	err := forEachLimit(10, 3, func(i int) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		if i == 5 {
+			return errBoom
+		}
+		return nil
+	})
+
+	if !errors.Is(err, errBoom) {
+		t.Errorf("expected error to wrap errBoom, got %v", err)
+	}
+	if peak.Load() > 3 {
+		t.Errorf("expected at most 3 concurrent calls, got %d", peak.Load())
+	}
+}
+
+func TestPoolNamer(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		index   int64
+		want    string
+		wantErr bool
+	}{
+		{name: "default", index: 3, want: "web-3"},
+		{name: "custom", tmpl: `{{.Prefix}}-node{{printf "%02d" .Index}}`, index: 3, want: "web-node03"},
+		{name: "index only", tmpl: "lab{{.Index}}", index: 0, want: "lab0"},
+		{name: "unknown field", tmpl: "{{.Name}}-{{.Index}}", wantErr: true},
+		{name: "empty name", tmpl: "{{if false}}x{{end}}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			render, err := poolNamer(tt.tmpl, "web")
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			got, err := render(tt.index)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("name = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := poolNamer("{{.Index", "web"); err == nil {
+		t.Error("expected a parse error for an unterminated action")
+	}
+}
+
+func TestValidatePoolNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		size    int64
+		wantErr string
+	}{
+		{name: "default", size: 3},
+		{name: "unique", tmpl: "{{.Prefix}}{{.Index}}", size: 12},
+		{name: "ignores index", tmpl: "{{.Prefix}}", size: 1, wantErr: "both named"},
+		{name: "collision", tmpl: `{{.Prefix}}-{{printf "%.1s" (printf "%d" .Index)}}`, size: 11, wantErr: "instances 1 and 10"},
+		{name: "parse error", tmpl: "{{", size: 1, wantErr: "unclosed action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePoolNames(tt.tmpl, "web", tt.size)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
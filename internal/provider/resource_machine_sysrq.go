@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+type machineSysRqResource struct {
+	client *vbox.Client
+}
+
+type machineSysRqModel struct {
+	MachineID types.String `tfsdk:"machine_id"`
+	Key       types.String `tfsdk:"key"`
+	Triggers  types.Map    `tfsdk:"triggers"`
+	ID        types.String `tfsdk:"id"`
+}
+
+func NewMachineSysRqResource() resource.Resource {
+	return &machineSysRqResource{}
+}
+
+func (r *machineSysRqResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_sysrq"
+}
+
+func (r *machineSysRqResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	r.client = req.ProviderData.(*providerData).client
+}
+
+func (r *machineSysRqResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Sends a magic SysRq key combination to a running VM, to debug a hung Linux guest.
+
+The provider presses Alt+SysRq+key on the VM's keyboard when the resource is created, and again
+whenever it is replaced, e.g. when triggers change. Destroying the resource sends nothing. The
+guest kernel must have SysRq enabled through the kernel.sysrq sysctl, or it ignores the keys.
+
+Changes to any attribute will trigger replacement of the resource, which sends the keys again.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Unique identifier for this resource (machine_id:key).",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"machine_id": schema.StringAttribute{
+				Required:    true,
+				Description: "UUID or name of the VM, which must be running.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"key": schema.StringAttribute{
+				Required:    true,
+				Description: "SysRq command key: a lowercase letter or a digit, e.g. 't' to dump the guest's tasks to its console, 'w' to dump its blocked tasks, 's' to sync its file systems or 'b' to reboot it immediately.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^[a-z0-9]$`), "must be a single lowercase letter or digit"),
+				},
+			},
+			"triggers": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Arbitrary values that, when changed, send the keys again.",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+	}
+}
+
+func (r *machineSysRqResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan machineSysRqModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.SendSysRqByID(ctx, plan.MachineID.ValueString(), plan.Key.ValueString()); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to send SysRq", err)
+		return
+	}
+
+	plan.ID = types.StringValue(fmt.Sprintf("%s:%s", plan.MachineID.ValueString(), plan.Key.ValueString()))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *machineSysRqResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// The keys were sent once; there is nothing to read back.
+	var state machineSysRqModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *machineSysRqResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// All attributes require replacement.
+	var plan machineSysRqModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *machineSysRqResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+	// Keys that were sent cannot be taken back.
+}
+
+// Ensure the resource implements the expected interfaces. There is nothing
+// to import: the resource only records that the keys were sent.
+var _ resource.ResourceWithConfigure = &machineSysRqResource{}
@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestMachineSysRqResourceMetadata(t *testing.T) {
+	r := NewMachineSysRqResource()
+
+	req := resource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &resource.MetadataResponse{}
+
+	r.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_machine_sysrq" {
+		t.Errorf("expected TypeName 'vboxweb_machine_sysrq', got %q", resp.TypeName)
+	}
+}
+
+func TestMachineSysRqResourceSchema(t *testing.T) {
+	r := NewMachineSysRqResource()
+
+	req := resource.SchemaRequest{}
+	resp := &resource.SchemaResponse{}
+
+	r.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	s := resp.Schema
+
+	for _, attrName := range []string{"machine_id", "key"} {
+		attr, ok := s.Attributes[attrName]
+		if !ok || !attr.IsRequired() {
+			t.Errorf("expected required %q attribute in schema", attrName)
+		}
+	}
+	if attr, ok := s.Attributes["triggers"]; !ok || !attr.IsOptional() {
+		t.Error("expected optional 'triggers' attribute in schema")
+	}
+	if attr, ok := s.Attributes["id"]; !ok || !attr.IsComputed() || attr.IsOptional() {
+		t.Error("expected computed only 'id' attribute in schema")
+	}
+
+	key := s.Attributes["key"].(schema.StringAttribute)
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"t", false},
+		{"b", false},
+		{"0", false},
+		{"T", true},
+		{"", true},
+		{"tb", true},
+	}
+	for _, tc := range tests {
+		req := validator.StringRequest{Path: path.Root("key"), ConfigValue: types.StringValue(tc.input)}
+		resp := &validator.StringResponse{}
+		for _, v := range key.Validators {
+			v.ValidateString(context.Background(), req, resp)
+		}
+		if resp.Diagnostics.HasError() != tc.wantErr {
+			t.Errorf("key %q error = %v, wantErr %v", tc.input, resp.Diagnostics, tc.wantErr)
+		}
+	}
+}
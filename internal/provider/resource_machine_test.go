@@ -5,7 +5,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
 )
 
 func TestMachineResourceMetadata(t *testing.T) {
@@ -64,7 +74,7 @@ func TestMachineResourceSchema(t *testing.T) {
 	}
 
 	// Check optional/computed attributes
-	optionalComputedAttrs := []string{"clone_mode", "state", "session_type", "wait_timeout"}
+	optionalComputedAttrs := []string{"clone_mode", "memory_mb", "cpu_count", "page_fusion_enabled", "execution_engine", "graphics_controller", "vram_mb", "accelerate_3d", "monitor_count", "apic_enabled", "x2apic_enabled", "pae_enabled", "long_mode", "boot_menu_mode", "autostart_enabled", "autostart_delay", "clipboard_file_transfers", "disable_time_sync", "recording_enabled", "recording_screens", "recording_file", "recording_format", "recording_fps", "io_cache_enabled", "force_delete", "install_guest_additions", "state", "stop_method", "shutdown_mode", "session_type", "wait_timeout"}
 	for _, attrName := range optionalComputedAttrs {
 		attr, ok := schema.Attributes[attrName]
 		if !ok {
@@ -87,6 +97,27 @@ func TestMachineResourceSchema(t *testing.T) {
 	if !cloneOptionsAttr.IsOptional() {
 		t.Error("expected 'clone_options' attribute to be optional")
 	}
+
+	// Check extra_clone_options is optional list
+	extraCloneOptionsAttr, ok := schema.Attributes["extra_clone_options"]
+	if !ok {
+		t.Fatal("expected 'extra_clone_options' attribute in schema")
+	}
+	if !extraCloneOptionsAttr.IsOptional() {
+		t.Error("expected 'extra_clone_options' attribute to be optional")
+	}
+
+	// Check read-back attributes are computed only
+	for _, attrName := range []string{"hwvirt", "nested_paging", "nested_virt", "disk_names"} {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsComputed() || attr.IsOptional() || attr.IsRequired() {
+			t.Errorf("expected %q attribute to be computed only", attrName)
+		}
+	}
 }
 
 func TestNormalizeDesiredState(t *testing.T) {
@@ -114,6 +145,7 @@ func TestNormalizeDesiredState(t *testing.T) {
 		{"unknown", "unknown"},
 		{"", ""},
 		{"paused", "paused"},
+		{"Saved", "saved"},
 	}
 
 	for _, tc := range tests {
@@ -156,6 +188,560 @@ func TestParseTimeout(t *testing.T) {
 	}
 }
 
+func TestDurationValidator(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"5m", false},
+		{"2h", false},
+		{"1h30m", false},
+		{"0s", true},
+		{"-5m", true},
+		{"5 minutes", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			req := validator.StringRequest{Path: path.Root("test"), ConfigValue: types.StringValue(tc.input)}
+			resp := &validator.StringResponse{}
+			durationValidator{}.ValidateString(context.Background(), req, resp)
+			if resp.Diagnostics.HasError() != tc.wantErr {
+				t.Errorf("ValidateString(%q) error = %v, wantErr %v", tc.input, resp.Diagnostics, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestOperationTimeouts(t *testing.T) {
+	if got := operationTimeouts(types.MapNull(types.StringType)); got != nil {
+		t.Errorf("expected nil for a null map, got %v", got)
+	}
+
+	m := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"hard disk": types.StringValue("2h"),
+		"default":   types.StringValue("5m"),
+	})
+	got := operationTimeouts(m)
+	if len(got) != 2 || got["hard disk"] != 2*time.Hour || got[vbox.DefaultOperationTimeout] != 5*time.Minute {
+		t.Errorf("unexpected operation timeouts: %v", got)
+	}
+}
+
+// machineBoolConfig returns a vboxweb_machine configuration with only the
+// given boolean attributes set.
+func machineBoolConfig(t *testing.T, values map[string]bool) tfsdk.Config {
+	t.Helper()
+	vals := make(map[string]tftypes.Value, len(values))
+	for name, v := range values {
+		vals[name] = tftypes.NewValue(tftypes.Bool, v)
+	}
+	return machineConfig(t, vals)
+}
+
+// machineConfig returns a vboxweb_machine configuration cloned from a
+// template, with the given attributes set and all others null.
+func machineConfig(t *testing.T, values map[string]tftypes.Value) tfsdk.Config {
+	t.Helper()
+	ctx := context.Background()
+	schemaResp := &resource.SchemaResponse{}
+	NewMachineResource().Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	objType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	vals := make(map[string]tftypes.Value, len(objType.AttributeTypes))
+	for name, typ := range objType.AttributeTypes {
+		vals[name] = tftypes.NewValue(typ, nil)
+	}
+	vals["source"] = tftypes.NewValue(tftypes.String, "template")
+	for name, v := range values {
+		vals[name] = v
+	}
+	return tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objType, vals)}
+}
+
+func TestMachineResourceValidateConfig_CPUFeatures(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]bool
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "x2APIC alone", config: map[string]bool{"x2apic_enabled": true}},
+		{name: "both enabled", config: map[string]bool{"apic_enabled": true, "x2apic_enabled": true}},
+		{name: "APIC disabled alone", config: map[string]bool{"apic_enabled": false}},
+		{name: "x2APIC without APIC", config: map[string]bool{"apic_enabled": false, "x2apic_enabled": true}, wantErr: true},
+		{name: "long mode alone", config: map[string]bool{"long_mode": true}},
+		{name: "PAE disabled alone", config: map[string]bool{"pae_enabled": false}},
+		{name: "long mode without PAE", config: map[string]bool{"pae_enabled": false, "long_mode": true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &machineResource{}
+			resp := &resource.ValidateConfigResponse{}
+
+			r.ValidateConfig(context.Background(), resource.ValidateConfigRequest{Config: machineBoolConfig(t, tt.config)}, resp)
+
+			if got := resp.Diagnostics.HasError(); got != tt.wantErr {
+				t.Errorf("HasError() = %v, want %v: %v", got, tt.wantErr, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestMachineResourceValidateConfig_RecordingFile(t *testing.T) {
+	screens := func(ids ...int64) tftypes.Value {
+		elems := make([]tftypes.Value, 0, len(ids))
+		for _, id := range ids {
+			elems = append(elems, tftypes.NewValue(tftypes.Number, id))
+		}
+		return tftypes.NewValue(tftypes.Set{ElementType: tftypes.Number}, elems)
+	}
+	file := tftypes.NewValue(tftypes.String, "/srv/recordings/vm.webm")
+	tests := []struct {
+		name    string
+		config  map[string]tftypes.Value
+		wantErr bool
+	}{
+		{name: "file alone", config: map[string]tftypes.Value{"recording_file": file}},
+		{name: "file with one screen", config: map[string]tftypes.Value{"recording_file": file, "recording_screens": screens(1)}},
+		{name: "screens without file", config: map[string]tftypes.Value{"recording_screens": screens(0, 1)}},
+		{name: "file with several screens", config: map[string]tftypes.Value{"recording_file": file, "recording_screens": screens(0, 1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &machineResource{}
+			resp := &resource.ValidateConfigResponse{}
+
+			r.ValidateConfig(context.Background(), resource.ValidateConfigRequest{Config: machineConfig(t, tt.config)}, resp)
+
+			if got := resp.Diagnostics.HasError(); got != tt.wantErr {
+				t.Errorf("HasError() = %v, want %v: %v", got, tt.wantErr, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestMachineResourceValidateConfig_Source(t *testing.T) {
+	str := func(s string) tftypes.Value { return tftypes.NewValue(tftypes.String, s) }
+	noSource := tftypes.NewValue(tftypes.String, nil)
+	overrideType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"leaf": tftypes.String, "sub_leaf": tftypes.String, "eax": tftypes.String, "ebx": tftypes.String, "ecx": tftypes.String, "edx": tftypes.String,
+	}}
+	cpuidOverrides := tftypes.NewValue(tftypes.List{ElementType: overrideType}, []tftypes.Value{
+		tftypes.NewValue(overrideType, map[string]tftypes.Value{
+			"leaf": str("0x1"), "sub_leaf": tftypes.NewValue(tftypes.String, nil), "eax": str("0x0"), "ebx": str("0x0"), "ecx": str("0x0"), "edx": str("0x0"),
+		}),
+	})
+	tests := []struct {
+		name    string
+		config  map[string]tftypes.Value
+		wantErr bool
+	}{
+		{name: "clone"},
+		{name: "clone with OS type", config: map[string]tftypes.Value{"os_type_id": str("Ubuntu_64")}},
+		{name: "clone with architecture", config: map[string]tftypes.Value{"platform_architecture": str("ARM")}, wantErr: true},
+		{name: "from scratch", config: map[string]tftypes.Value{"source": noSource, "os_type_id": str("Ubuntu_64")}},
+		{name: "from scratch with architecture", config: map[string]tftypes.Value{"source": noSource, "os_type_id": str("Ubuntu_arm64"), "platform_architecture": str("ARM")}},
+		{name: "from scratch without OS type", config: map[string]tftypes.Value{"source": noSource}, wantErr: true},
+		{name: "ARM with long mode", config: map[string]tftypes.Value{"source": noSource, "os_type_id": str("Ubuntu_arm64"), "platform_architecture": str("ARM"), "long_mode": tftypes.NewValue(tftypes.Bool, true)}, wantErr: true},
+		{name: "ARM with CPUID overrides", config: map[string]tftypes.Value{"source": noSource, "os_type_id": str("Ubuntu_arm64"), "platform_architecture": str("ARM"), "cpuid_overrides": cpuidOverrides}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &machineResource{}
+			resp := &resource.ValidateConfigResponse{}
+
+			r.ValidateConfig(context.Background(), resource.ValidateConfigRequest{Config: machineConfig(t, tt.config)}, resp)
+
+			if got := resp.Diagnostics.HasError(); got != tt.wantErr {
+				t.Errorf("HasError() = %v, want %v: %v", got, tt.wantErr, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestMachineResourceValidateConfig_DiskConversion(t *testing.T) {
+	fixed := tftypes.NewValue(tftypes.Bool, true)
+	vmdk := tftypes.NewValue(tftypes.String, "VMDK")
+	options := func(opts ...string) tftypes.Value {
+		elems := make([]tftypes.Value, 0, len(opts))
+		for _, o := range opts {
+			elems = append(elems, tftypes.NewValue(tftypes.String, o))
+		}
+		return tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, elems)
+	}
+	tests := []struct {
+		name    string
+		config  map[string]tftypes.Value
+		wantErr bool
+	}{
+		{name: "full clone", config: map[string]tftypes.Value{"fixed_disks": fixed}},
+		{name: "full clone with options", config: map[string]tftypes.Value{"fixed_disks": fixed, "clone_options": options("KeepDiskNames")}},
+		{name: "linked clone", config: map[string]tftypes.Value{"fixed_disks": fixed, "clone_options": options("Link")}, wantErr: true},
+		{name: "linked clone without fixed disks", config: map[string]tftypes.Value{"fixed_disks": tftypes.NewValue(tftypes.Bool, false), "clone_options": options("Link")}},
+		{name: "from scratch", config: map[string]tftypes.Value{"fixed_disks": fixed, "source": tftypes.NewValue(tftypes.String, nil), "os_type_id": tftypes.NewValue(tftypes.String, "Ubuntu_64")}, wantErr: true},
+		{name: "disk format", config: map[string]tftypes.Value{"disk_format": vmdk}},
+		{name: "fixed disks in a format", config: map[string]tftypes.Value{"fixed_disks": fixed, "disk_format": vmdk}},
+		{name: "disk format of a linked clone", config: map[string]tftypes.Value{"disk_format": vmdk, "clone_options": options("Link")}, wantErr: true},
+		{name: "disk format from scratch", config: map[string]tftypes.Value{"disk_format": vmdk, "source": tftypes.NewValue(tftypes.String, nil), "os_type_id": tftypes.NewValue(tftypes.String, "Ubuntu_64")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &machineResource{}
+			resp := &resource.ValidateConfigResponse{}
+
+			r.ValidateConfig(context.Background(), resource.ValidateConfigRequest{Config: machineConfig(t, tt.config)}, resp)
+
+			if got := resp.Diagnostics.HasError(); got != tt.wantErr {
+				t.Errorf("HasError() = %v, want %v: %v", got, tt.wantErr, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestMachineResourceValidateConfig_MemoryBalloon(t *testing.T) {
+	balloon := tftypes.NewValue(tftypes.Number, 512)
+	state := func(s string) tftypes.Value { return tftypes.NewValue(tftypes.String, s) }
+	tests := []struct {
+		name    string
+		config  map[string]tftypes.Value
+		wantErr bool
+	}{
+		{name: "started", config: map[string]tftypes.Value{"memory_balloon_mb": balloon, "state": state("started")}},
+		{name: "running alias", config: map[string]tftypes.Value{"memory_balloon_mb": balloon, "state": state("running")}},
+		{name: "stopped", config: map[string]tftypes.Value{"memory_balloon_mb": balloon, "state": state("stopped")}, wantErr: true},
+		{name: "default state", config: map[string]tftypes.Value{"memory_balloon_mb": balloon}, wantErr: true},
+		{name: "unknown state", config: map[string]tftypes.Value{"memory_balloon_mb": balloon, "state": tftypes.NewValue(tftypes.String, tftypes.UnknownValue)}},
+		{name: "unset", config: map[string]tftypes.Value{"state": state("stopped")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &machineResource{}
+			resp := &resource.ValidateConfigResponse{}
+
+			r.ValidateConfig(context.Background(), resource.ValidateConfigRequest{Config: machineConfig(t, tt.config)}, resp)
+
+			if got := resp.Diagnostics.HasError(); got != tt.wantErr {
+				t.Errorf("HasError() = %v, want %v: %v", got, tt.wantErr, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestImpliedBoolModifier(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]bool
+		mod    impliedBoolModifier
+		config types.Bool
+		want   types.Bool
+	}{
+		{
+			name:   "x2APIC enables the APIC",
+			values: map[string]bool{"x2apic_enabled": true},
+			mod:    impliedBoolModifier{other: path.Root("x2apic_enabled"), when: true, value: true},
+			config: types.BoolNull(),
+			want:   types.BoolValue(true),
+		},
+		{
+			name:   "disabling the APIC disables x2APIC",
+			values: map[string]bool{"apic_enabled": false},
+			mod:    impliedBoolModifier{other: path.Root("apic_enabled"), when: false, value: false},
+			config: types.BoolNull(),
+			want:   types.BoolValue(false),
+		},
+		{
+			name:   "disabling PAE disables long mode",
+			values: map[string]bool{"pae_enabled": false},
+			mod:    impliedBoolModifier{other: path.Root("pae_enabled"), when: false, value: false},
+			config: types.BoolNull(),
+			want:   types.BoolValue(false),
+		},
+		{
+			name:   "other attribute unset",
+			mod:    impliedBoolModifier{other: path.Root("apic_enabled"), when: false, value: false},
+			config: types.BoolNull(),
+			want:   types.BoolValue(true),
+		},
+		{
+			name:   "configured value wins",
+			values: map[string]bool{"apic_enabled": true, "x2apic_enabled": true},
+			mod:    impliedBoolModifier{other: path.Root("x2apic_enabled"), when: true, value: true},
+			config: types.BoolValue(true),
+			want:   types.BoolValue(true),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := planmodifier.BoolRequest{
+				Config:      machineBoolConfig(t, tt.values),
+				ConfigValue: tt.config,
+				PlanValue:   types.BoolValue(true),
+			}
+			resp := &planmodifier.BoolResponse{PlanValue: req.PlanValue}
+
+			tt.mod.PlanModifyBool(context.Background(), req, resp)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+			}
+			if !resp.PlanValue.Equal(tt.want) {
+				t.Errorf("plan = %v, want %v", resp.PlanValue, tt.want)
+			}
+		})
+	}
+}
+
+func TestUseStateUnlessChangedModifier(t *testing.T) {
+	ctx := context.Background()
+	schemaResp := &resource.SchemaResponse{}
+	NewMachineResource().Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	objType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	// object returns a vboxweb_machine value with only execution_engine set.
+	object := func(engine string) tftypes.Value {
+		vals := make(map[string]tftypes.Value, len(objType.AttributeTypes))
+		for name, typ := range objType.AttributeTypes {
+			vals[name] = tftypes.NewValue(typ, nil)
+		}
+		vals["execution_engine"] = tftypes.NewValue(tftypes.String, engine)
+		return tftypes.NewValue(objType, vals)
+	}
+
+	tests := []struct {
+		name        string
+		priorEngine string
+		planEngine  string
+		state       types.Bool
+		want        types.Bool
+	}{
+		{name: "unchanged", priorEngine: "HwVirt", planEngine: "HwVirt", state: types.BoolValue(true), want: types.BoolValue(true)},
+		{name: "engine changed", priorEngine: "HwVirt", planEngine: "NativeApi", state: types.BoolValue(true), want: types.BoolUnknown()},
+		{name: "no prior value", priorEngine: "HwVirt", planEngine: "HwVirt", state: types.BoolNull(), want: types.BoolUnknown()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := planmodifier.BoolRequest{
+				Plan:       tfsdk.Plan{Schema: schemaResp.Schema, Raw: object(tt.planEngine)},
+				State:      tfsdk.State{Schema: schemaResp.Schema, Raw: object(tt.priorEngine)},
+				StateValue: tt.state,
+				PlanValue:  types.BoolUnknown(),
+			}
+			resp := &planmodifier.BoolResponse{PlanValue: req.PlanValue}
+
+			useStateUnlessChangedModifier{paths: []path.Path{path.Root("execution_engine")}}.PlanModifyBool(ctx, req, resp)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+			}
+			if !resp.PlanValue.Equal(tt.want) {
+				t.Errorf("plan = %v, want %v", resp.PlanValue, tt.want)
+			}
+		})
+	}
+}
+
+func TestUseStateUnlessChangedModifier_String(t *testing.T) {
+	ctx := context.Background()
+	schemaResp := &resource.SchemaResponse{}
+	NewMachineResource().Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	objType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	// object returns a vboxweb_machine value with only restore_snapshot set.
+	object := func(snapshot string) tftypes.Value {
+		vals := make(map[string]tftypes.Value, len(objType.AttributeTypes))
+		for name, typ := range objType.AttributeTypes {
+			vals[name] = tftypes.NewValue(typ, nil)
+		}
+		vals["restore_snapshot"] = tftypes.NewValue(tftypes.String, snapshot)
+		return tftypes.NewValue(objType, vals)
+	}
+
+	tests := []struct {
+		name          string
+		priorSnapshot string
+		planSnapshot  string
+		want          types.String
+	}{
+		{name: "unchanged", priorSnapshot: "baseline", planSnapshot: "baseline", want: types.StringValue("provisioned")},
+		{name: "restore changed", priorSnapshot: "baseline", planSnapshot: "clean", want: types.StringUnknown()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := planmodifier.StringRequest{
+				Plan:       tfsdk.Plan{Schema: schemaResp.Schema, Raw: object(tt.planSnapshot)},
+				State:      tfsdk.State{Schema: schemaResp.Schema, Raw: object(tt.priorSnapshot)},
+				StateValue: types.StringValue("provisioned"),
+				PlanValue:  types.StringUnknown(),
+			}
+			resp := &planmodifier.StringResponse{PlanValue: req.PlanValue}
+
+			useStateUnlessChangedModifier{paths: []path.Path{path.Root("restore_snapshot")}}.PlanModifyString(ctx, req, resp)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+			}
+			if !resp.PlanValue.Equal(tt.want) {
+				t.Errorf("plan = %v, want %v", resp.PlanValue, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportedSourceModifier(t *testing.T) {
+	ctx := context.Background()
+	schemaResp := &resource.SchemaResponse{}
+	NewMachineResource().Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	objType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	// state returns a vboxweb_machine state with only source set, or a
+	// null state for a resource being created.
+	state := func(source types.String, exists bool) tfsdk.State {
+		if !exists {
+			return tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objType, nil)}
+		}
+		vals := make(map[string]tftypes.Value, len(objType.AttributeTypes))
+		for name, typ := range objType.AttributeTypes {
+			vals[name] = tftypes.NewValue(typ, nil)
+		}
+		if !source.IsNull() {
+			vals["source"] = tftypes.NewValue(tftypes.String, source.ValueString())
+		}
+		return tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objType, vals)}
+	}
+
+	tests := []struct {
+		name        string
+		exists      bool
+		prior       types.String
+		config      types.String
+		wantPlan    types.String
+		wantReplace bool
+	}{
+		{name: "create clone", config: types.StringValue("base"), wantPlan: types.StringValue("base")},
+		{name: "create from scratch", config: types.StringNull(), wantPlan: types.StringNull()},
+		{name: "clone unchanged", exists: true, prior: types.StringValue("base"), config: types.StringValue("base"), wantPlan: types.StringValue("base")},
+		{name: "clone source changed", exists: true, prior: types.StringValue("base"), config: types.StringValue("other"), wantPlan: types.StringValue("other"), wantReplace: true},
+		{name: "clone source removed", exists: true, prior: types.StringValue("base"), config: types.StringNull(), wantPlan: types.StringNull(), wantReplace: true},
+		{name: "scratch unchanged", exists: true, prior: types.StringNull(), config: types.StringNull(), wantPlan: types.StringNull()},
+		{name: "scratch source set", exists: true, prior: types.StringNull(), config: types.StringValue("base"), wantPlan: types.StringValue("base"), wantReplace: true},
+		{name: "imported source set", exists: true, prior: types.StringValue(""), config: types.StringValue("base"), wantPlan: types.StringValue("base")},
+		{name: "imported source unknown", exists: true, prior: types.StringValue(""), config: types.StringUnknown(), wantPlan: types.StringUnknown()},
+		{name: "clone source unknown", exists: true, prior: types.StringValue("base"), config: types.StringUnknown(), wantPlan: types.StringUnknown(), wantReplace: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planValue := tt.config
+			if tt.config.IsNull() {
+				// Computed attributes without a configured value are
+				// planned unknown.
+				planValue = types.StringUnknown()
+			}
+			req := planmodifier.StringRequest{
+				State:       state(tt.prior, tt.exists),
+				StateValue:  tt.prior,
+				ConfigValue: tt.config,
+				PlanValue:   planValue,
+			}
+			resp := &planmodifier.StringResponse{PlanValue: req.PlanValue}
+
+			importedSourceModifier{}.PlanModifyString(ctx, req, resp)
+
+			if !resp.PlanValue.Equal(tt.wantPlan) {
+				t.Errorf("plan = %v, want %v", resp.PlanValue, tt.wantPlan)
+			}
+			if resp.RequiresReplace != tt.wantReplace {
+				t.Errorf("RequiresReplace = %t, want %t", resp.RequiresReplace, tt.wantReplace)
+			}
+		})
+	}
+}
+
+// markImported sets *private to private state data marking an imported VM,
+// as ImportState does.
+func markImported[D any, P interface {
+	*D
+	SetKey(context.Context, string, []byte) diag.Diagnostics
+}](t *testing.T, private *P) {
+	t.Helper()
+	*private = P(new(D))
+	if diags := (*private).SetKey(context.Background(), importedPrivateKey, []byte("true")); diags.HasError() {
+		t.Fatalf("failed to mark the VM imported: %v", diags)
+	}
+}
+
+func TestImportedCreateOnlyModifier(t *testing.T) {
+	ctx := context.Background()
+	schemaResp := &resource.SchemaResponse{}
+	NewMachineResource().Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	objType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	// machine returns a vboxweb_machine created from scratch, or imported
+	// without a source hint, with only source and os_type_id set.
+	machine := func(source, osTypeID types.String) tftypes.Value {
+		vals := make(map[string]tftypes.Value, len(objType.AttributeTypes))
+		for name, typ := range objType.AttributeTypes {
+			vals[name] = tftypes.NewValue(typ, nil)
+		}
+		if !source.IsNull() {
+			vals["source"] = tftypes.NewValue(tftypes.String, source.ValueString())
+		}
+		if !osTypeID.IsNull() {
+			vals["os_type_id"] = tftypes.NewValue(tftypes.String, osTypeID.ValueString())
+		}
+		return tftypes.NewValue(objType, vals)
+	}
+
+	tests := []struct {
+		name        string
+		imported    bool
+		prior       types.String
+		config      types.String
+		wantReplace bool
+	}{
+		{name: "unchanged", prior: types.StringValue("Ubuntu_64"), config: types.StringValue("Ubuntu_64")},
+		{name: "changed", prior: types.StringValue("Ubuntu_64"), config: types.StringValue("Debian_64"), wantReplace: true},
+		{name: "set after create", prior: types.StringNull(), config: types.StringValue("Ubuntu_64"), wantReplace: true},
+		{name: "imported without source", imported: true, prior: types.StringNull(), config: types.StringValue("Ubuntu_64")},
+		// Once recorded, the attribute is no longer taken in place, even if
+		// no apply followed the import.
+		{name: "imported and changed", imported: true, prior: types.StringValue("Ubuntu_64"), config: types.StringValue("Debian_64"), wantReplace: true},
+		{name: "imported and removed", imported: true, prior: types.StringValue("Ubuntu_64"), config: types.StringNull(), wantReplace: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := types.StringNull()
+			if tt.imported {
+				source = types.StringValue("")
+			}
+			req := planmodifier.StringRequest{
+				Path:        path.Root("os_type_id"),
+				State:       tfsdk.State{Schema: schemaResp.Schema, Raw: machine(source, tt.prior)},
+				Plan:        tfsdk.Plan{Schema: schemaResp.Schema, Raw: machine(source, tt.config)},
+				StateValue:  tt.prior,
+				ConfigValue: tt.config,
+				PlanValue:   tt.config,
+			}
+			if tt.imported {
+				markImported(t, &req.Private)
+			}
+			resp := &planmodifier.StringResponse{PlanValue: req.PlanValue}
+
+			importedCreateOnlyModifier{}.PlanModifyString(ctx, req, resp)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+			}
+			if !resp.PlanValue.Equal(tt.config) {
+				t.Errorf("plan = %v, want the configured %v", resp.PlanValue, tt.config)
+			}
+			if resp.RequiresReplace != tt.wantReplace {
+				t.Errorf("RequiresReplace = %t, want %t", resp.RequiresReplace, tt.wantReplace)
+			}
+		})
+	}
+}
+
 func TestMachineResourceConfigure_NilProviderData(t *testing.T) {
 	r := &machineResource{}
 
@@ -186,3 +772,34 @@ func TestNewMachineResource(t *testing.T) {
 		t.Error("expected resource to be *machineResource")
 	}
 }
+
+func TestParseMachineImportID(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    machineImportID
+		wantErr bool
+	}{
+		{input: "550e8400-e29b-41d4-a716-446655440000", want: machineImportID{Machine: "550e8400-e29b-41d4-a716-446655440000"}},
+		{input: "my-vm", want: machineImportID{Machine: "my-vm"}},
+		{input: "my-vm|source=base-template", want: machineImportID{Machine: "my-vm", Source: "base-template"}},
+		{input: "my-vm| source = base template ", want: machineImportID{Machine: "my-vm", Source: "base template"}},
+		{input: "my-vm|source=", want: machineImportID{Machine: "my-vm"}},
+		{input: "", wantErr: true},
+		{input: "|source=base", wantErr: true},
+		{input: "my-vm|base-template", wantErr: true},
+		{input: "my-vm|clone_mode=AllStates", wantErr: true},
+		{input: "my-vm|source=a|source=b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseMachineImportID(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMachineImportID(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseMachineImportID(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
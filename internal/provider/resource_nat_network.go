@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+type natNetworkResource struct {
+	client *vbox.Client
+	// tolerateReadErrors is the provider's tolerate_read_errors.
+	tolerateReadErrors bool
+}
+
+type natNetworkModel struct {
+	Name        types.String `tfsdk:"name"`
+	Network     types.String `tfsdk:"network"`
+	Enabled     types.Bool   `tfsdk:"enabled"`
+	DHCPEnabled types.Bool   `tfsdk:"dhcp_enabled"`
+	ID          types.String `tfsdk:"id"`
+}
+
+func NewNATNetworkResource() resource.Resource {
+	return &natNetworkResource{}
+}
+
+func (r *natNetworkResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_nat_network"
+}
+
+func (r *natNetworkResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.tolerateReadErrors = data.tolerateReadErrors
+}
+
+func (r *natNetworkResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Manages a VirtualBox NAT network.
+
+Unlike the NAT attachment, which gives each network adapter a private NAT engine, a NAT network is
+shared by the VMs attached to it: they reach each other as well as the outside world through the
+host. VirtualBox starts the network's NAT service when the first VM attached to it starts.
+
+Changes to network, enabled and dhcp_enabled are applied in place. Changes to name will trigger
+replacement of the NAT network.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Unique identifier for this resource (the network name).",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the NAT network, as set on the network adapters attached to it. Must be unique on the VirtualBox host.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"network": schema.StringAttribute{
+				Required:    true,
+				Description: "IPv4 network in CIDR notation, e.g. '10.0.2.0/24'. The host is the gateway at the first address.",
+				Validators: []validator.String{
+					ipv4CIDRValidator{},
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Whether the NAT network is enabled. Default: true.",
+			},
+			"dhcp_enabled": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Whether VirtualBox runs a DHCP server on the network, handing out addresses to the VMs attached to it. Default: true.",
+			},
+		},
+	}
+}
+
+func (r *natNetworkResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan natNetworkModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.CreateNATNetwork(ctx, natNetworkSettings(plan)); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to create NAT network", err)
+		return
+	}
+
+	plan.ID = plan.Name
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *natNetworkResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state natNetworkModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	network, err := r.client.ReadNATNetwork(ctx, state.Name.ValueString())
+	if err != nil {
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read NAT network", err)
+		return
+	}
+
+	// If the network was removed out of band, remove from state
+	if network == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.ID = state.Name
+	state.Network = types.StringValue(network.Network)
+	state.Enabled = types.BoolValue(network.Enabled)
+	state.DHCPEnabled = types.BoolValue(network.DHCPEnabled)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *natNetworkResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan natNetworkModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.UpdateNATNetwork(ctx, natNetworkSettings(plan)); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to update NAT network", err)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *natNetworkResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state natNetworkModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.DeleteNATNetwork(ctx, state.Name.ValueString()); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to remove NAT network", err)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState
+func (r *natNetworkResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Expected import ID format: name
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
+
+// natNetworkSettings converts m to the settings of a NAT network.
+func natNetworkSettings(m natNetworkModel) vboxapi.NATNetwork {
+	return vboxapi.NATNetwork{
+		Name:        m.Name.ValueString(),
+		Network:     m.Network.ValueString(),
+		Enabled:     m.Enabled.ValueBool(),
+		DHCPEnabled: m.DHCPEnabled.ValueBool(),
+	}
+}
+
+// ipv4CIDRValidator checks that a string is an IPv4 network in CIDR
+// notation, e.g. 10.0.2.0/24.
+type ipv4CIDRValidator struct{}
+
+func (v ipv4CIDRValidator) Description(_ context.Context) string {
+	return "value must be an IPv4 network in CIDR notation, e.g. 10.0.2.0/24"
+}
+
+func (v ipv4CIDRValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v ipv4CIDRValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	ip, _, err := net.ParseCIDR(req.ConfigValue.ValueString())
+	if err != nil || ip.To4() == nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid network",
+			fmt.Sprintf("%s, got %q", v.Description(ctx), req.ConfigValue.ValueString()))
+	}
+}
+
+// Ensure the resource implements the expected interfaces
+var _ resource.ResourceWithImportState = &natNetworkResource{}
@@ -0,0 +1,261 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+type natNetworkPortForwardResource struct {
+	client *vbox.Client
+	// tolerateReadErrors is the provider's tolerate_read_errors.
+	tolerateReadErrors bool
+}
+
+type natNetworkPortForwardModel struct {
+	// Identity fields
+	NetworkName types.String `tfsdk:"network_name"`
+	Name        types.String `tfsdk:"name"`
+
+	// Rule configuration
+	Protocol  types.String `tfsdk:"protocol"`
+	HostIP    types.String `tfsdk:"host_ip"`
+	HostPort  types.Int64  `tfsdk:"host_port"`
+	GuestIP   types.String `tfsdk:"guest_ip"`
+	GuestPort types.Int64  `tfsdk:"guest_port"`
+
+	// Computed
+	ID types.String `tfsdk:"id"`
+}
+
+func NewNATNetworkPortForwardResource() resource.Resource {
+	return &natNetworkPortForwardResource{}
+}
+
+func (r *natNetworkPortForwardResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_nat_network_port_forward"
+}
+
+func (r *natNetworkPortForwardResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.tolerateReadErrors = data.tolerateReadErrors
+}
+
+func (r *natNetworkPortForwardResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Manages an IPv4 port forwarding rule of a VirtualBox NAT network.
+
+The rule forwards a host port to a port of one of the VMs attached to the NAT network, which is
+addressed by its IP address on the network. The host ports of these rules are avoided by the
+auto_host_port allocation of vboxweb_nat_port_forward.
+
+Changes to any attribute will trigger replacement of the rule.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Unique identifier for this resource (network_name:name).",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"network_name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the NAT network, e.g. vboxweb_nat_network.lab.name.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the port forwarding rule. Must be unique within the NAT network and cannot contain ':'.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^[^:]+$`), "must not be empty or contain ':'"),
+				},
+			},
+			"protocol": schema.StringAttribute{
+				Required:    true,
+				Description: "Protocol for the port forwarding rule: 'tcp' or 'udp'.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive("tcp", "udp"),
+				},
+			},
+			"host_ip": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+				Description: "Host IP address to bind to. Empty string means all interfaces. Default: empty string.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"host_port": schema.Int64Attribute{
+				Required:    true,
+				Description: "Host port number (1-65535).",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+				Validators: []validator.Int64{
+					int64validator.Between(1, 65535),
+				},
+			},
+			"guest_ip": schema.StringAttribute{
+				Required:    true,
+				Description: "IP address of the VM on the NAT network, e.g. '10.0.9.5'.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"guest_port": schema.Int64Attribute{
+				Required:    true,
+				Description: "Guest port number (1-65535).",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+				Validators: []validator.Int64{
+					int64validator.Between(1, 65535),
+				},
+			},
+		},
+	}
+}
+
+func (r *natNetworkPortForwardResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan natNetworkPortForwardModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	protocol := vboxapi.NATProtocolTCP
+	if strings.EqualFold(plan.Protocol.ValueString(), "udp") {
+		protocol = vboxapi.NATProtocolUDP
+	}
+	rule := vbox.NATNetworkPortForwardRule{
+		NetworkName: plan.NetworkName.ValueString(),
+		Name:        plan.Name.ValueString(),
+		Protocol:    protocol,
+		HostIP:      plan.HostIP.ValueString(),
+		HostPort:    uint16(plan.HostPort.ValueInt64()),
+		GuestIP:     plan.GuestIP.ValueString(),
+		GuestPort:   uint16(plan.GuestPort.ValueInt64()),
+	}
+	if err := r.client.CreateNATNetworkPortForward(ctx, rule); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to create NAT network port forward rule", err)
+		return
+	}
+
+	plan.ID = types.StringValue(fmt.Sprintf("%s:%s", plan.NetworkName.ValueString(), plan.Name.ValueString()))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *natNetworkPortForwardResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state natNetworkPortForwardModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rule, err := r.client.ReadNATNetworkPortForward(ctx, state.NetworkName.ValueString(), state.Name.ValueString())
+	if err != nil {
+		// If the NAT network doesn't exist, remove from state
+		if vbox.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read NAT network port forward rule", err)
+		return
+	}
+
+	// If the rule was removed out of band, remove from state
+	if rule == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	if rule.Protocol == vboxapi.NATProtocolTCP {
+		state.Protocol = types.StringValue("tcp")
+	} else {
+		state.Protocol = types.StringValue("udp")
+	}
+	state.HostIP = types.StringValue(rule.HostIP)
+	state.HostPort = types.Int64Value(int64(rule.HostPort))
+	state.GuestIP = types.StringValue(rule.GuestIP)
+	state.GuestPort = types.Int64Value(int64(rule.GuestPort))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *natNetworkPortForwardResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// All attributes require replacement.
+	var plan natNetworkPortForwardModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *natNetworkPortForwardResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state natNetworkPortForwardModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.DeleteNATNetworkPortForward(ctx, state.NetworkName.ValueString(), state.Name.ValueString()); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to delete NAT network port forward rule", err)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState
+func (r *natNetworkPortForwardResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Expected import ID format: network_name:name. Rule names cannot
+	// contain ':', network names can.
+	i := strings.LastIndex(req.ID, ":")
+	if i <= 0 || i == len(req.ID)-1 {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Expected import ID format: network_name:name, got: %s", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("network_name"), req.ID[:i])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), req.ID[i+1:])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
+
+// Ensure the resource implements the expected interfaces
+var _ resource.ResourceWithImportState = &natNetworkPortForwardResource{}
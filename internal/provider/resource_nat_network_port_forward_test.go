@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func TestNATNetworkPortForwardResourceMetadata(t *testing.T) {
+	r := NewNATNetworkPortForwardResource()
+
+	req := resource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &resource.MetadataResponse{}
+
+	r.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_nat_network_port_forward" {
+		t.Errorf("expected TypeName 'vboxweb_nat_network_port_forward', got %q", resp.TypeName)
+	}
+}
+
+func TestNATNetworkPortForwardResourceSchema(t *testing.T) {
+	r := NewNATNetworkPortForwardResource()
+
+	req := resource.SchemaRequest{}
+	resp := &resource.SchemaResponse{}
+
+	r.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	for _, attrName := range []string{"network_name", "name", "protocol", "host_port", "guest_ip", "guest_port"} {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsRequired() {
+			t.Errorf("expected %q attribute to be required", attrName)
+		}
+	}
+
+	if attr, ok := schema.Attributes["host_ip"]; !ok || !attr.IsOptional() || !attr.IsComputed() {
+		t.Error("expected optional and computed 'host_ip' attribute in schema")
+	}
+
+	if attr, ok := schema.Attributes["id"]; !ok || !attr.IsComputed() || attr.IsOptional() {
+		t.Error("expected computed only 'id' attribute in schema")
+	}
+}
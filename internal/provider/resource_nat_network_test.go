@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestNATNetworkResourceMetadata(t *testing.T) {
+	r := NewNATNetworkResource()
+
+	req := resource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &resource.MetadataResponse{}
+
+	r.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_nat_network" {
+		t.Errorf("expected TypeName 'vboxweb_nat_network', got %q", resp.TypeName)
+	}
+}
+
+func TestNATNetworkResourceSchema(t *testing.T) {
+	r := NewNATNetworkResource()
+
+	req := resource.SchemaRequest{}
+	resp := &resource.SchemaResponse{}
+
+	r.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	for _, attrName := range []string{"name", "network"} {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsRequired() {
+			t.Errorf("expected %q attribute to be required", attrName)
+		}
+	}
+
+	for _, attrName := range []string{"enabled", "dhcp_enabled"} {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsOptional() || !attr.IsComputed() {
+			t.Errorf("expected %q attribute to be optional and computed", attrName)
+		}
+	}
+
+	if attr, ok := schema.Attributes["id"]; !ok || !attr.IsComputed() || attr.IsOptional() {
+		t.Error("expected computed only 'id' attribute in schema")
+	}
+}
+
+func TestIPv4CIDRValidator(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"10.0.2.0/24", false},
+		{"172.16.0.0/12", false},
+		{"10.0.2.0", true},
+		{"10.0.2.0/33", true},
+		{"fd00::/64", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			req := validator.StringRequest{Path: path.Root("test"), ConfigValue: types.StringValue(tc.input)}
+			resp := &validator.StringResponse{}
+			ipv4CIDRValidator{}.ValidateString(context.Background(), req, resp)
+			if resp.Diagnostics.HasError() != tc.wantErr {
+				t.Errorf("ValidateString(%q) error = %v, wantErr %v", tc.input, resp.Diagnostics, tc.wantErr)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -24,6 +25,10 @@ import (
 
 type natPortForwardResource struct {
 	client *vbox.Client
+	// defaultHostIP is the provider's default_host_ip.
+	defaultHostIP string
+	// tolerateReadErrors is the provider's tolerate_read_errors.
+	tolerateReadErrors bool
 }
 
 type natPortForwardModel struct {
@@ -62,7 +67,10 @@ func (r *natPortForwardResource) Configure(_ context.Context, req resource.Confi
 	if req.ProviderData == nil {
 		return
 	}
-	r.client = req.ProviderData.(*vbox.Client)
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.defaultHostIP = data.defaultHostIP
+	r.tolerateReadErrors = data.tolerateReadErrors
 }
 
 func (r *natPortForwardResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
@@ -78,7 +86,9 @@ from a configured range, avoiding conflicts with other VirtualBox NAT port forwa
   VirtualBox NAT port forwarding rule on the same VirtualBox instance at apply time.
 - This does NOT guarantee the port is not used by other (non-VirtualBox) processes on the host.
 - VirtualBox may not surface runtime bind failures if the port is already in use.
-- Changes to any rule attribute (except auto_host_port settings) will trigger rule replacement.`,
+- Changes to machine_id or name will trigger replacement of the resource. Changes to protocol or
+  adapter_slot delete the old rule before adding the new one. Other changes replace the rule under
+  a single machine lock, so its host port is unbound only briefly.`,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed:    true,
@@ -93,9 +103,9 @@ from a configured range, avoiding conflicts with other VirtualBox NAT port forwa
 			},
 			"adapter_slot": schema.Int64Attribute{
 				Required:    true,
-				Description: "Network adapter slot number (0-7, corresponding to nic1-nic8).",
+				Description: "Network adapter slot number, starting at 0 (slot 0 is nic1). The number of slots depends on the machine's chipset: 8 for PIIX3, 36 for ICH9. Out-of-range slots are rejected when the rule is created.",
 				Validators: []validator.Int64{
-					int64validator.Between(0, 7),
+					int64validator.AtLeast(0),
 				},
 			},
 			"name": schema.StringAttribute{
@@ -107,16 +117,15 @@ from a configured range, avoiding conflicts with other VirtualBox NAT port forwa
 			},
 			"protocol": schema.StringAttribute{
 				Required:    true,
-				Description: "Protocol for the port forwarding rule: 'tcp' or 'udp'.",
+				Description: "Protocol for the port forwarding rule: 'tcp', 'udp' or 'both'. With 'both', two rules named '<name>-tcp' and '<name>-udp' are managed together and share the same host port.",
 				Validators: []validator.String{
-					stringvalidator.OneOfCaseInsensitive("tcp", "udp"),
+					stringvalidator.OneOfCaseInsensitive("tcp", "udp", natProtocolBoth),
 				},
 			},
 			"host_ip": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
-				Default:     stringdefault.StaticString(""),
-				Description: "Host IP address to bind to. Empty string or '0.0.0.0' means all interfaces.",
+				Description: "Host IP address to bind to. Empty string or '0.0.0.0' means all interfaces. Default: the provider's default_host_ip, or empty string if it is not set.",
 			},
 			"host_port": schema.Int64Attribute{
 				Optional:    true,
@@ -199,10 +208,11 @@ func (r *natPortForwardResource) Create(ctx context.Context, req resource.Create
 			Scope:              vbox.HostIPScope(plan.AutoHostIPScope.ValueString()),
 			IncludeNATNetworks: true,
 		}
+		warnOverlappingAutoRanges(&resp.Diagnostics, opts, r.client.RecordAutoRange(opts))
 
 		allocatedPort, err := r.client.AllocateNATHostPort(ctx, opts)
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to allocate host port", err.Error())
+			addClientError(&resp.Diagnostics, "Failed to allocate host port", err)
 			return
 		}
 		hostPort = allocatedPort
@@ -217,33 +227,17 @@ func (r *natPortForwardResource) Create(ctx context.Context, req resource.Create
 		return
 	}
 
-	// Parse protocol
-	proto := vboxapi.NATProtocolTCP
-	if strings.EqualFold(plan.Protocol.ValueString(), "udp") {
-		proto = vboxapi.NATProtocolUDP
-	}
-
-	// Create the rule
-	rule := vbox.NATPortForwardRule{
-		MachineID:   plan.MachineID.ValueString(),
-		AdapterSlot: uint32(plan.AdapterSlot.ValueInt64()),
-		Name:        plan.Name.ValueString(),
-		Protocol:    proto,
-		HostIP:      plan.HostIP.ValueString(),
-		HostPort:    hostPort,
-		GuestIP:     plan.GuestIP.ValueString(),
-		GuestPort:   uint16(plan.GuestPort.ValueInt64()),
-	}
-
-	if err := r.client.CreateNATPortForward(ctx, rule); err != nil {
-		resp.Diagnostics.AddError("Failed to create NAT port forward rule", err.Error())
+	// Create the rules
+	rules := natRules(plan, hostPort)
+	if err := r.client.CreateNATPortForwards(ctx, rules...); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to create NAT port forward rule", err)
 		return
 	}
 
 	// Read back to confirm
-	readRule, err := r.client.ReadNATPortForward(ctx, rule.MachineID, rule.AdapterSlot, rule.Name)
+	readRule, err := r.readBack(ctx, rules)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to verify NAT port forward rule", err.Error())
+		addClientError(&resp.Diagnostics, "Failed to verify NAT port forward rule", err)
 		return
 	}
 	if readRule == nil {
@@ -252,7 +246,7 @@ func (r *natPortForwardResource) Create(ctx context.Context, req resource.Create
 	}
 
 	// Update state
-	plan.ID = types.StringValue(fmt.Sprintf("%s:%d:%s", rule.MachineID, rule.AdapterSlot, rule.Name))
+	plan.ID = types.StringValue(fmt.Sprintf("%s:%d:%s", plan.MachineID.ValueString(), plan.AdapterSlot.ValueInt64(), plan.Name.ValueString()))
 	plan.HostPort = types.Int64Value(int64(hostPort))
 	plan.EffectiveHostPort = types.Int64Value(int64(readRule.HostPort))
 
@@ -266,12 +260,19 @@ func (r *natPortForwardResource) Read(ctx context.Context, req resource.ReadRequ
 		return
 	}
 
-	// Read the rule
-	rule, err := r.client.ReadNATPortForward(
+	name := state.Name.ValueString()
+	names := natRuleNames(name, state.Protocol.ValueString())
+	if state.Protocol.IsNull() {
+		// Imported: the rule is either a single rule or a tcp/udp pair.
+		names = append(natRuleNames(name, "tcp"), natRuleNames(name, natProtocolBoth)...)
+	}
+
+	// Read the rules
+	rules, err := r.client.ReadNATPortForwards(
 		ctx,
 		state.MachineID.ValueString(),
 		uint32(state.AdapterSlot.ValueInt64()),
-		state.Name.ValueString(),
+		names...,
 	)
 	if err != nil {
 		// If the machine doesn't exist, remove from state
@@ -279,30 +280,47 @@ func (r *natPortForwardResource) Read(ctx context.Context, req resource.ReadRequ
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		resp.Diagnostics.AddError("Failed to read NAT port forward rule", err.Error())
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read NAT port forward rule", err)
+		return
+	}
+
+	if state.Protocol.IsNull() {
+		if rules[0] != nil {
+			rules = rules[:1]
+		} else {
+			rules = rules[1:]
+			state.Protocol = types.StringValue(natProtocolBoth)
+		}
+	}
+
+	if len(rules) == 2 {
+		tcp, udp := rules[0], rules[1]
+		if tcp == nil && udp == nil {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		// A pair with a missing half is recreated as a whole.
+		if tcp == nil || udp == nil {
+			resp.Diagnostics.AddWarning(
+				"Incomplete NAT port forward rule pair",
+				fmt.Sprintf("Only one of the rules %q and %q exists; the pair will be recreated.", names[0], names[1]),
+			)
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		setNATRulePair(&state, *tcp, *udp)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 		return
 	}
 
 	// If rule doesn't exist, remove from state
+	rule := rules[0]
 	if rule == nil {
 		resp.State.RemoveResource(ctx)
 		return
 	}
 
-	// Update state with actual values
-	state.EffectiveHostPort = types.Int64Value(int64(rule.HostPort))
-
-	// Update protocol to match actual
-	if rule.Protocol == vboxapi.NATProtocolTCP {
-		state.Protocol = types.StringValue("tcp")
-	} else {
-		state.Protocol = types.StringValue("udp")
-	}
-
-	state.HostIP = types.StringValue(rule.HostIP)
-	state.GuestIP = types.StringValue(rule.GuestIP)
-	state.GuestPort = types.Int64Value(int64(rule.GuestPort))
-
+	setNATRule(&state, *rule)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
@@ -315,19 +333,23 @@ func (r *natPortForwardResource) Update(ctx context.Context, req resource.Update
 		return
 	}
 
-	// NAT port forward rules don't support in-place updates - we need to delete and recreate
-	// This is because VirtualBox API doesn't have an "update" operation for redirects
-
-	// Delete the old rule
-	err := r.client.DeleteNATPortForward(
-		ctx,
-		state.MachineID.ValueString(),
-		uint32(state.AdapterSlot.ValueInt64()),
-		state.Name.ValueString(),
-	)
-	if err != nil && !vbox.IsNotFound(err) {
-		resp.Diagnostics.AddError("Failed to delete old NAT port forward rule", err.Error())
-		return
+	// VirtualBox has no operation to change a redirect. When the rules keep
+	// their names and adapter, each is replaced under a single machine lock
+	// and save, see ReplaceNATPortForwards, which leaves its host port
+	// unbound only briefly. Otherwise the old rules are deleted first.
+	inPlace := strings.EqualFold(plan.Protocol.ValueString(), state.Protocol.ValueString()) &&
+		plan.AdapterSlot.Equal(state.AdapterSlot)
+	if !inPlace {
+		err := r.client.DeleteNATPortForwards(
+			ctx,
+			state.MachineID.ValueString(),
+			uint32(state.AdapterSlot.ValueInt64()),
+			natRuleNames(state.Name.ValueString(), state.Protocol.ValueString())...,
+		)
+		if err != nil && !vbox.IsNotFound(err) {
+			addClientError(&resp.Diagnostics, "Failed to delete old NAT port forward rule", err)
+			return
+		}
 	}
 
 	// Determine the host port to use
@@ -342,10 +364,11 @@ func (r *natPortForwardResource) Update(ctx context.Context, req resource.Update
 			Scope:              vbox.HostIPScope(plan.AutoHostIPScope.ValueString()),
 			IncludeNATNetworks: true,
 		}
+		warnOverlappingAutoRanges(&resp.Diagnostics, opts, r.client.RecordAutoRange(opts))
 
 		allocatedPort, err := r.client.AllocateNATHostPort(ctx, opts)
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to allocate host port", err.Error())
+			addClientError(&resp.Diagnostics, "Failed to allocate host port", err)
 			return
 		}
 		hostPort = allocatedPort
@@ -360,33 +383,22 @@ func (r *natPortForwardResource) Update(ctx context.Context, req resource.Update
 		return
 	}
 
-	// Parse protocol
-	proto := vboxapi.NATProtocolTCP
-	if strings.EqualFold(plan.Protocol.ValueString(), "udp") {
-		proto = vboxapi.NATProtocolUDP
-	}
-
-	// Create the new rule
-	rule := vbox.NATPortForwardRule{
-		MachineID:   plan.MachineID.ValueString(),
-		AdapterSlot: uint32(plan.AdapterSlot.ValueInt64()),
-		Name:        plan.Name.ValueString(),
-		Protocol:    proto,
-		HostIP:      plan.HostIP.ValueString(),
-		HostPort:    hostPort,
-		GuestIP:     plan.GuestIP.ValueString(),
-		GuestPort:   uint16(plan.GuestPort.ValueInt64()),
-	}
-
-	if err := r.client.CreateNATPortForward(ctx, rule); err != nil {
-		resp.Diagnostics.AddError("Failed to create NAT port forward rule", err.Error())
+	// Create the rules
+	rules := natRules(plan, hostPort)
+	if inPlace {
+		if err := r.client.ReplaceNATPortForwards(ctx, rules...); err != nil {
+			addClientError(&resp.Diagnostics, "Failed to update NAT port forward rule", err)
+			return
+		}
+	} else if err := r.client.CreateNATPortForwards(ctx, rules...); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to create NAT port forward rule", err)
 		return
 	}
 
 	// Read back to confirm
-	readRule, err := r.client.ReadNATPortForward(ctx, rule.MachineID, rule.AdapterSlot, rule.Name)
+	readRule, err := r.readBack(ctx, rules)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to verify NAT port forward rule", err.Error())
+		addClientError(&resp.Diagnostics, "Failed to verify NAT port forward rule", err)
 		return
 	}
 	if readRule == nil {
@@ -395,7 +407,7 @@ func (r *natPortForwardResource) Update(ctx context.Context, req resource.Update
 	}
 
 	// Update state
-	plan.ID = types.StringValue(fmt.Sprintf("%s:%d:%s", rule.MachineID, rule.AdapterSlot, rule.Name))
+	plan.ID = types.StringValue(fmt.Sprintf("%s:%d:%s", plan.MachineID.ValueString(), plan.AdapterSlot.ValueInt64(), plan.Name.ValueString()))
 	plan.HostPort = types.Int64Value(int64(hostPort))
 	plan.EffectiveHostPort = types.Int64Value(int64(readRule.HostPort))
 
@@ -409,21 +421,148 @@ func (r *natPortForwardResource) Delete(ctx context.Context, req resource.Delete
 		return
 	}
 
-	err := r.client.DeleteNATPortForward(
+	err := r.client.DeleteNATPortForwards(
 		ctx,
 		state.MachineID.ValueString(),
 		uint32(state.AdapterSlot.ValueInt64()),
-		state.Name.ValueString(),
+		natRuleNames(state.Name.ValueString(), state.Protocol.ValueString())...,
 	)
 	if err != nil {
 		// Ignore not found errors - rule is already gone
 		if !vbox.IsNotFound(err) {
-			resp.Diagnostics.AddError("Failed to delete NAT port forward rule", err.Error())
+			addClientError(&resp.Diagnostics, "Failed to delete NAT port forward rule", err)
 			return
 		}
 	}
 }
 
+// natProtocolBoth is the protocol of a rule that forwards both TCP and UDP.
+// VirtualBox rules have a single protocol, so such a rule is made of two
+// rules named after the resource with a -tcp and -udp suffix.
+const natProtocolBoth = "both"
+
+// natRuleNames returns the names of the VirtualBox rules that make up the
+// rule called name.
+func natRuleNames(name, protocol string) []string {
+	if strings.EqualFold(protocol, natProtocolBoth) {
+		return []string{name + "-tcp", name + "-udp"}
+	}
+	return []string{name}
+}
+
+// natRules returns the VirtualBox rules that make up the rule described by
+// m. Both rules of a pair use the same host port, as TCP and UDP ports are
+// independent.
+func natRules(m natPortForwardModel, hostPort uint16) []vbox.NATPortForwardRule {
+	protocol := m.Protocol.ValueString()
+	protos := []vboxapi.NATProtocol{vboxapi.NATProtocolTCP}
+	switch {
+	case strings.EqualFold(protocol, natProtocolBoth):
+		protos = []vboxapi.NATProtocol{vboxapi.NATProtocolTCP, vboxapi.NATProtocolUDP}
+	case strings.EqualFold(protocol, "udp"):
+		protos = []vboxapi.NATProtocol{vboxapi.NATProtocolUDP}
+	}
+
+	names := natRuleNames(m.Name.ValueString(), protocol)
+	rules := make([]vbox.NATPortForwardRule, len(names))
+	for i, name := range names {
+		rules[i] = vbox.NATPortForwardRule{
+			MachineID:   m.MachineID.ValueString(),
+			AdapterSlot: uint32(m.AdapterSlot.ValueInt64()),
+			Name:        name,
+			Protocol:    protos[i],
+			HostIP:      m.HostIP.ValueString(),
+			HostPort:    hostPort,
+			GuestIP:     m.GuestIP.ValueString(),
+			GuestPort:   uint16(m.GuestPort.ValueInt64()),
+		}
+	}
+	return rules
+}
+
+// readBack reads the rules just created. It returns the first one, or nil
+// if any of them is missing.
+func (r *natPortForwardResource) readBack(ctx context.Context, rules []vbox.NATPortForwardRule) (*vbox.NATPortForwardRule, error) {
+	names := make([]string, len(rules))
+	for i, rule := range rules {
+		names[i] = rule.Name
+	}
+	read, err := r.client.ReadNATPortForwards(ctx, rules[0].MachineID, rules[0].AdapterSlot, names...)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range read {
+		if rule == nil {
+			return nil, nil
+		}
+	}
+	return read[0], nil
+}
+
+// setNATRule updates state with the values of a single VirtualBox rule.
+func setNATRule(state *natPortForwardModel, rule vbox.NATPortForwardRule) {
+	state.EffectiveHostPort = types.Int64Value(int64(rule.HostPort))
+
+	// Update protocol to match actual
+	if rule.Protocol == vboxapi.NATProtocolTCP {
+		state.Protocol = types.StringValue("tcp")
+	} else {
+		state.Protocol = types.StringValue("udp")
+	}
+
+	state.HostIP = types.StringValue(rule.HostIP)
+	state.GuestIP = types.StringValue(rule.GuestIP)
+	state.GuestPort = types.Int64Value(int64(rule.GuestPort))
+}
+
+// setNATRulePair updates state with the values of a tcp/udp rule pair. When
+// the two rules differ, the value that differs from state wins so that a
+// change to either rule shows up as drift.
+func setNATRulePair(state *natPortForwardModel, tcp, udp vbox.NATPortForwardRule) {
+	state.EffectiveHostPort = types.Int64Value(int64(pairValue(uint16(state.EffectiveHostPort.ValueInt64()), tcp.HostPort, udp.HostPort)))
+	state.Protocol = types.StringValue(natProtocolBoth)
+	state.HostIP = types.StringValue(pairValue(state.HostIP.ValueString(), tcp.HostIP, udp.HostIP))
+	state.GuestIP = types.StringValue(pairValue(state.GuestIP.ValueString(), tcp.GuestIP, udp.GuestIP))
+	state.GuestPort = types.Int64Value(int64(pairValue(uint16(state.GuestPort.ValueInt64()), tcp.GuestPort, udp.GuestPort)))
+}
+
+// warnOverlappingAutoRanges adds a warning for each range requested earlier
+// in the apply that overlaps opts with a different host IP or scope.
+func warnOverlappingAutoRanges(diags *diag.Diagnostics, opts vbox.PortAllocatorOptions, overlaps []vbox.PortAllocatorOptions) {
+	for _, o := range overlaps {
+		diags.AddWarning(
+			"Overlapping auto_host_port ranges",
+			fmt.Sprintf("The range %d-%d (host_ip %q, scope %q) overlaps the range %d-%d (host_ip %q, scope %q) "+
+				"of another rule in this apply. Rules with different host IPs or scopes may be allocated "+
+				"ports that conflict; consider disjoint ranges.",
+				opts.MinPort, opts.MaxPort, opts.HostIP, opts.Scope, o.MinPort, o.MaxPort, o.HostIP, o.Scope),
+		)
+	}
+}
+
+func pairValue[T comparable](current, tcp, udp T) T {
+	if tcp == current {
+		return udp
+	}
+	return tcp
+}
+
+// ModifyPlan implements resource.ResourceWithModifyPlan. Rules that do not
+// set host_ip use the provider's default_host_ip, which also drives the
+// host IP scope of auto-allocated ports.
+func (r *natPortForwardResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var hostIP types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("host_ip"), &hostIP)...)
+	if resp.Diagnostics.HasError() || !hostIP.IsNull() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("host_ip"), r.defaultHostIP)...)
+}
+
 // ImportState implements resource.ResourceWithImportState
 func (r *natPortForwardResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	// Expected import ID format: machine_id:adapter_slot:name
@@ -443,10 +582,10 @@ func (r *natPortForwardResource) ImportState(ctx context.Context, req resource.I
 	// Parse adapter slot
 	var adapterSlot int64
 	_, err := fmt.Sscanf(adapterSlotStr, "%d", &adapterSlot)
-	if err != nil || adapterSlot < 0 || adapterSlot > 7 {
+	if err != nil || adapterSlot < 0 {
 		resp.Diagnostics.AddError(
 			"Invalid adapter slot",
-			fmt.Sprintf("Adapter slot must be a number between 0 and 7, got: %s", adapterSlotStr),
+			fmt.Sprintf("Adapter slot must be a non-negative number, got: %s", adapterSlotStr),
 		)
 		return
 	}
@@ -457,5 +596,8 @@ func (r *natPortForwardResource) ImportState(ctx context.Context, req resource.I
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
 }
 
-// Ensure the resource implements the ResourceWithImportState interface
-var _ resource.ResourceWithImportState = &natPortForwardResource{}
+// Ensure the resource implements the expected interfaces
+var (
+	_ resource.ResourceWithImportState = &natPortForwardResource{}
+	_ resource.ResourceWithModifyPlan  = &natPortForwardResource{}
+)
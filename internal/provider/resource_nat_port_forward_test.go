@@ -4,7 +4,15 @@ import (
 	"context"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
 )
 
 func TestNatPortForwardResourceMetadata(t *testing.T) {
@@ -103,3 +111,167 @@ func TestNatPortForwardResourceConfigure_NilProviderData(t *testing.T) {
 		t.Error("expected client to be nil when ProviderData is nil")
 	}
 }
+
+func TestNatPortForwardResourceConfigure_DefaultHostIP(t *testing.T) {
+	r := &natPortForwardResource{}
+
+	req := resource.ConfigureRequest{
+		ProviderData: &providerData{defaultHostIP: "127.0.0.1"},
+	}
+	resp := &resource.ConfigureResponse{}
+
+	r.Configure(context.Background(), req, resp)
+
+	if r.defaultHostIP != "127.0.0.1" {
+		t.Errorf("expected defaultHostIP '127.0.0.1', got %q", r.defaultHostIP)
+	}
+}
+
+func TestNatPortForwardResourceModifyPlan_DefaultHostIP(t *testing.T) {
+	ctx := context.Background()
+	r := &natPortForwardResource{defaultHostIP: "127.0.0.1"}
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	objType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	// object returns a value of the resource type with only host_ip set.
+	object := func(hostIP tftypes.Value) tftypes.Value {
+		vals := make(map[string]tftypes.Value, len(objType.AttributeTypes))
+		for name, typ := range objType.AttributeTypes {
+			vals[name] = tftypes.NewValue(typ, nil)
+		}
+		vals["host_ip"] = hostIP
+		return tftypes.NewValue(objType, vals)
+	}
+
+	tests := []struct {
+		name   string
+		config tftypes.Value
+		expect string
+	}{
+		{name: "unset uses provider default", config: tftypes.NewValue(tftypes.String, nil), expect: "127.0.0.1"},
+		{name: "explicit value is kept", config: tftypes.NewValue(tftypes.String, "0.0.0.0"), expect: "0.0.0.0"},
+		{name: "explicit empty value is kept", config: tftypes.NewValue(tftypes.String, ""), expect: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planned := tt.config
+			if tt.config.IsNull() {
+				planned = tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+			}
+			req := resource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: object(tt.config)},
+				Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: object(planned)},
+			}
+			resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+
+			r.ModifyPlan(ctx, req, resp)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+			}
+			var hostIP types.String
+			resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("host_ip"), &hostIP)...)
+			if hostIP.ValueString() != tt.expect {
+				t.Errorf("planned host_ip = %q, want %q", hostIP.ValueString(), tt.expect)
+			}
+		})
+	}
+}
+
+func TestNatRules_Both(t *testing.T) {
+	m := natPortForwardModel{
+		MachineID:   types.StringValue("uuid-vm"),
+		AdapterSlot: types.Int64Value(1),
+		Name:        types.StringValue("dns"),
+		Protocol:    types.StringValue("Both"),
+		HostIP:      types.StringValue("127.0.0.1"),
+		GuestIP:     types.StringValue(""),
+		GuestPort:   types.Int64Value(53),
+	}
+
+	rules := natRules(m, 5353)
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+	for i, want := range []struct {
+		name  string
+		proto vboxapi.NATProtocol
+	}{{"dns-tcp", vboxapi.NATProtocolTCP}, {"dns-udp", vboxapi.NATProtocolUDP}} {
+		rule := rules[i]
+		if rule.Name != want.name || rule.Protocol != want.proto {
+			t.Errorf("rule %d = %s/%s, want %s/%s", i, rule.Name, rule.Protocol, want.name, want.proto)
+		}
+		if rule.HostPort != 5353 || rule.GuestPort != 53 || rule.HostIP != "127.0.0.1" || rule.AdapterSlot != 1 {
+			t.Errorf("rule %d has unexpected settings: %+v", i, rule)
+		}
+	}
+
+	m.Protocol = types.StringValue("udp")
+	rules = natRules(m, 5353)
+	if len(rules) != 1 || rules[0].Name != "dns" || rules[0].Protocol != vboxapi.NATProtocolUDP {
+		t.Errorf("unexpected rules for udp: %+v", rules)
+	}
+}
+
+func TestSetNATRulePair(t *testing.T) {
+	rule := func(name string, proto vboxapi.NATProtocol, guestPort uint16) vbox.NATPortForwardRule {
+		return vbox.NATPortForwardRule{Name: name, Protocol: proto, HostIP: "127.0.0.1", HostPort: 5353, GuestPort: guestPort}
+	}
+	state := func() natPortForwardModel {
+		return natPortForwardModel{
+			Protocol:          types.StringValue("both"),
+			HostIP:            types.StringValue("127.0.0.1"),
+			GuestIP:           types.StringValue(""),
+			GuestPort:         types.Int64Value(53),
+			EffectiveHostPort: types.Int64Value(5353),
+		}
+	}
+
+	tests := []struct {
+		name          string
+		tcp, udp      vbox.NATPortForwardRule
+		wantGuestPort int64
+	}{
+		{name: "in sync", tcp: rule("dns-tcp", vboxapi.NATProtocolTCP, 53), udp: rule("dns-udp", vboxapi.NATProtocolUDP, 53), wantGuestPort: 53},
+		{name: "tcp drifted", tcp: rule("dns-tcp", vboxapi.NATProtocolTCP, 5300), udp: rule("dns-udp", vboxapi.NATProtocolUDP, 53), wantGuestPort: 5300},
+		{name: "udp drifted", tcp: rule("dns-tcp", vboxapi.NATProtocolTCP, 53), udp: rule("dns-udp", vboxapi.NATProtocolUDP, 5301), wantGuestPort: 5301},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := state()
+			setNATRulePair(&s, tt.tcp, tt.udp)
+			if s.Protocol.ValueString() != natProtocolBoth {
+				t.Errorf("protocol = %q, want %q", s.Protocol.ValueString(), natProtocolBoth)
+			}
+			if s.GuestPort.ValueInt64() != tt.wantGuestPort {
+				t.Errorf("guest_port = %d, want %d", s.GuestPort.ValueInt64(), tt.wantGuestPort)
+			}
+			if s.EffectiveHostPort.ValueInt64() != 5353 {
+				t.Errorf("effective_host_port = %d, want 5353", s.EffectiveHostPort.ValueInt64())
+			}
+		})
+	}
+}
+
+func TestWarnOverlappingAutoRanges(t *testing.T) {
+	client := vbox.NewClient("http://localhost:18083", "", "")
+	first := vbox.PortAllocatorOptions{MinPort: 20000, MaxPort: 20100, Scope: vbox.HostIPScopeAny}
+	second := vbox.PortAllocatorOptions{MinPort: 20100, MaxPort: 20200, HostIP: "127.0.0.1", Scope: vbox.HostIPScopeExact}
+
+	var diags diag.Diagnostics
+	warnOverlappingAutoRanges(&diags, first, client.RecordAutoRange(first))
+	if diags.WarningsCount() != 0 {
+		t.Fatalf("unexpected warnings for the first range: %v", diags)
+	}
+
+	warnOverlappingAutoRanges(&diags, second, client.RecordAutoRange(second))
+	if diags.WarningsCount() != 1 {
+		t.Fatalf("expected 1 warning, got %v", diags)
+	}
+	if summary := diags.Warnings()[0].Summary(); summary != "Overlapping auto_host_port ranges" {
+		t.Errorf("unexpected warning summary %q", summary)
+	}
+}
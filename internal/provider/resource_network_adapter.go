@@ -0,0 +1,435 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+type networkAdapterResource struct {
+	client *vbox.Client
+	// tolerateReadErrors is the provider's tolerate_read_errors.
+	tolerateReadErrors bool
+}
+
+type networkAdapterModel struct {
+	// Identity fields
+	MachineID types.String `tfsdk:"machine_id"`
+	Slot      types.Int64  `tfsdk:"slot"`
+
+	// Attachment
+	AttachmentType      types.String `tfsdk:"attachment_type"`
+	BridgedInterface    types.String `tfsdk:"bridged_interface"`
+	HostOnlyInterface   types.String `tfsdk:"host_only_interface"`
+	InternalNetworkName types.String `tfsdk:"internal_network_name"`
+	NATNetworkName      types.String `tfsdk:"nat_network_name"`
+
+	// Adapter configuration
+	Enabled        types.Bool   `tfsdk:"enabled"`
+	CableConnected types.Bool   `tfsdk:"cable_connected"`
+	MACAddress     types.String `tfsdk:"mac_address"`
+	AdapterType    types.String `tfsdk:"adapter_type"`
+	LineSpeedKbps  types.Int64  `tfsdk:"line_speed_kbps"`
+
+	// Computed
+	ID types.String `tfsdk:"id"`
+}
+
+// networkAttachmentTypes maps the schema's attachment type names to
+// VirtualBox attachment types.
+var networkAttachmentTypes = map[string]vboxapi.NetworkAttachmentType{
+	"nat":          vboxapi.NetworkAttachmentTypeNAT,
+	"bridged":      vboxapi.NetworkAttachmentTypeBridged,
+	"hostonly":     vboxapi.NetworkAttachmentTypeHostOnly,
+	"intnet":       vboxapi.NetworkAttachmentTypeInternal,
+	"natnetwork":   vboxapi.NetworkAttachmentTypeNATNetwork,
+	"disconnected": vboxapi.NetworkAttachmentTypeNull,
+}
+
+// networkAttachmentAttrs names the attribute that holds the interface or
+// network of the attachment types that have one.
+var networkAttachmentAttrs = map[string]string{
+	"bridged":    "bridged_interface",
+	"hostonly":   "host_only_interface",
+	"intnet":     "internal_network_name",
+	"natnetwork": "nat_network_name",
+}
+
+// networks returns the attributes of m listed in networkAttachmentAttrs,
+// by attachment type name.
+func (m *networkAdapterModel) networks() map[string]*types.String {
+	return map[string]*types.String{
+		"bridged":    &m.BridgedInterface,
+		"hostonly":   &m.HostOnlyInterface,
+		"intnet":     &m.InternalNetworkName,
+		"natnetwork": &m.NATNetworkName,
+	}
+}
+
+func NewNetworkAdapterResource() resource.Resource {
+	return &networkAdapterResource{}
+}
+
+func (r *networkAdapterResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_network_adapter"
+}
+
+func (r *networkAdapterResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.tolerateReadErrors = data.tolerateReadErrors
+}
+
+func (r *networkAdapterResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	adapterTypes := make([]string, 0, len(vbox.NetworkAdapterTypes))
+	for _, t := range vbox.NetworkAdapterTypes {
+		adapterTypes = append(adapterTypes, string(t))
+	}
+
+	resp.Schema = schema.Schema{
+		Description: `Configures a network adapter slot of a VirtualBox VM: what it is attached to, whether it is
+enabled and its cable connected, and its MAC address, hardware type and line speed.
+
+A VM has a fixed number of adapter slots, which depends on its chipset, so this resource creates
+nothing: it configures the slot. Destroying the resource leaves the adapter as it is.
+
+The attachment, the interface or network it uses and the cable can change while the VM is running.
+Changing enabled, mac_address or adapter_type requires the VM to be powered off. Before an adapter
+is bridged, the provider checks that the host interface exists and is up.
+
+Changes to machine_id or slot will trigger replacement of the resource; other changes are applied
+in place.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Unique identifier for this resource (machine_id:slot).",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"machine_id": schema.StringAttribute{
+				Required:    true,
+				Description: "VirtualBox machine ID (UUID) or name.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"slot": schema.Int64Attribute{
+				Required:    true,
+				Description: "Adapter slot, numbered from 0. Most chipsets provide 8 slots (0-7), and the VirtualBox GUI shows the first four as Adapter 1-4.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"attachment_type": schema.StringAttribute{
+				Required:    true,
+				Description: "What the adapter is attached to: 'nat', 'bridged' (needs bridged_interface), 'hostonly' (needs host_only_interface), 'intnet' (needs internal_network_name), 'natnetwork' (needs nat_network_name) or 'disconnected'.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("nat", "bridged", "hostonly", "intnet", "natnetwork", "disconnected"),
+				},
+			},
+			"bridged_interface": schema.StringAttribute{
+				Optional:    true,
+				Description: "Host interface to bridge the adapter to, e.g. 'eth0'. Required when attachment_type is 'bridged'. The vboxweb_host_network_interfaces data source lists the candidates.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"host_only_interface": schema.StringAttribute{
+				Optional:    true,
+				Description: "Host-only interface to attach the adapter to, e.g. 'vboxnet0'. Required when attachment_type is 'hostonly'.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"internal_network_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "Internal network the adapter joins, e.g. vboxweb_internal_network.lab.name. Required when attachment_type is 'intnet'.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"nat_network_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "NAT network the adapter joins, e.g. vboxweb_nat_network.lab.name. Required when attachment_type is 'natnetwork'.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Whether the adapter is enabled. Changing it requires the VM to be powered off. Default: true.",
+			},
+			"cable_connected": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Whether the virtual cable is plugged in. Unplug it to simulate a link failure in a running guest. Default: true.",
+			},
+			"mac_address": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "MAC address, as 12 hexadecimal digits, optionally separated by ':' or '-'. Pin it so that the VM keeps its DHCP leases across clones. Changing it requires the VM to be powered off. Default: the current address.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"adapter_type": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Hardware the adapter emulates: 'Am79C970A', 'Am79C973', 'I82540EM', 'I82543GC', 'I82545EM' or 'Virtio'. 'Virtio' performs best, but the guest needs a virtio-net driver, which Linux has built in and Windows gets from the VirtIO drivers. Changing it requires the VM to be powered off. Default: the current type.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+				Validators: []validator.String{
+					stringvalidator.OneOf(adapterTypes...),
+				},
+			},
+			"line_speed_kbps": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Speed the adapter reports to the guest, in kbps, e.g. to test guests on slow links. Default: the current speed.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+				Validators: []validator.Int64{
+					int64validator.Between(1, 4294967295),
+				},
+			},
+		},
+	}
+}
+
+// ValidateConfig implements resource.ResourceWithValidateConfig.
+func (r *networkAdapterResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var cfg networkAdapterModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() || cfg.AttachmentType.IsUnknown() {
+		return
+	}
+
+	attachment := cfg.AttachmentType.ValueString()
+	for name, network := range cfg.networks() {
+		attr := networkAttachmentAttrs[name]
+		switch {
+		case name == attachment && network.IsNull():
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attr),
+				"Missing "+attr,
+				fmt.Sprintf("%s is required when attachment_type is %q", attr, attachment),
+			)
+		case name != attachment && !network.IsNull():
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attr),
+				"Unexpected "+attr,
+				fmt.Sprintf("%s is only used when attachment_type is %q, got %q", attr, name, attachment),
+			)
+		}
+	}
+}
+
+func (r *networkAdapterResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan networkAdapterModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.AttachmentType.ValueString() == "bridged" {
+		if err := r.client.CheckBridgedInterface(ctx, plan.BridgedInterface.ValueString()); err != nil {
+			addClientError(&resp.Diagnostics, "Invalid bridged interface", err)
+			return
+		}
+	}
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.StringValue(fmt.Sprintf("%s:%d", plan.MachineID.ValueString(), plan.Slot.ValueInt64()))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *networkAdapterResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state networkAdapterModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	info, err := r.client.ReadNetworkAdapter(ctx, state.MachineID.ValueString(), uint32(state.Slot.ValueInt64()))
+	if err != nil {
+		// If the machine doesn't exist, remove from state
+		if vbox.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read network adapter", err)
+		return
+	}
+
+	setNetworkAdapterState(&state, info)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *networkAdapterResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan networkAdapterModel
+	var state networkAdapterModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	bridging := plan.AttachmentType.ValueString() == "bridged" &&
+		(!plan.AttachmentType.Equal(state.AttachmentType) || !plan.BridgedInterface.Equal(state.BridgedInterface))
+	if bridging {
+		if err := r.client.CheckBridgedInterface(ctx, plan.BridgedInterface.ValueString()); err != nil {
+			addClientError(&resp.Diagnostics, "Invalid bridged interface", err)
+			return
+		}
+	}
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// apply configures the adapter as plan says and reads it back into plan.
+func (r *networkAdapterResource) apply(ctx context.Context, plan *networkAdapterModel, diags *diag.Diagnostics) {
+	machineID := plan.MachineID.ValueString()
+	slot := uint32(plan.Slot.ValueInt64())
+	if err := r.client.ConfigureNetworkAdapter(ctx, machineID, slot, networkAdapterSettings(*plan)); err != nil {
+		addClientError(diags, "Failed to configure network adapter", err)
+		return
+	}
+
+	info, err := r.client.ReadNetworkAdapter(ctx, machineID, slot)
+	if err != nil {
+		addClientError(diags, "Failed to read network adapter", err)
+		return
+	}
+	setNetworkAdapterState(plan, info)
+}
+
+func (r *networkAdapterResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+	// The slot belongs to the VM; leave the adapter as it is.
+}
+
+// ImportState implements resource.ResourceWithImportState
+func (r *networkAdapterResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Expected import ID format: machine_id:slot. Machine names can
+	// contain ':', slots cannot.
+	i := strings.LastIndex(req.ID, ":")
+	if i <= 0 {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Expected import ID format: machine_id:slot, got: %s", req.ID),
+		)
+		return
+	}
+	slot, err := strconv.ParseUint(req.ID[i+1:], 10, 32)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid slot",
+			fmt.Sprintf("Slot must be a non-negative number, got: %s", req.ID[i+1:]),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("machine_id"), req.ID[:i])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("slot"), int64(slot))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
+
+// networkAdapterSettings converts m to the settings of a network adapter.
+// Attributes that are unknown, because they are not configured and the
+// adapter was not read yet, are left unchanged, as is a line speed of 0,
+// which VirtualBox reports for adapters that use the default speed.
+func networkAdapterSettings(m networkAdapterModel) vbox.NetworkAdapterSettings {
+	attachment := networkAttachmentTypes[m.AttachmentType.ValueString()]
+	s := vbox.NetworkAdapterSettings{
+		AttachmentType: &attachment,
+		Enabled:        m.Enabled.ValueBoolPointer(),
+		CableConnected: m.CableConnected.ValueBoolPointer(),
+	}
+	if network, ok := m.networks()[m.AttachmentType.ValueString()]; ok {
+		s.Network = network.ValueStringPointer()
+	}
+	if !m.MACAddress.IsUnknown() {
+		s.MACAddress = m.MACAddress.ValueStringPointer()
+	}
+	if !m.AdapterType.IsUnknown() && !m.AdapterType.IsNull() {
+		t := vboxapi.NetworkAdapterType(m.AdapterType.ValueString())
+		s.AdapterType = &t
+	}
+	if !m.LineSpeedKbps.IsUnknown() && m.LineSpeedKbps.ValueInt64() > 0 {
+		kbps := uint32(m.LineSpeedKbps.ValueInt64())
+		s.LineSpeedKbps = &kbps
+	}
+	return s
+}
+
+// setNetworkAdapterState sets the attributes of m from the adapter info
+// reports. The interface or network attributes of other attachment types
+// are cleared, and a MAC address VirtualBox reports in another format than
+// m's is kept as m has it.
+func setNetworkAdapterState(m *networkAdapterModel, info *vbox.NetworkAdapterInfo) {
+	attachment := strings.ToLower(string(info.AttachmentType))
+	for name, t := range networkAttachmentTypes {
+		if t == info.AttachmentType {
+			attachment = name
+		}
+	}
+	m.AttachmentType = types.StringValue(attachment)
+	for name, network := range m.networks() {
+		if name == attachment {
+			*network = types.StringValue(info.Network)
+		} else {
+			*network = types.StringNull()
+		}
+	}
+
+	m.Enabled = types.BoolValue(info.Enabled)
+	m.CableConnected = types.BoolValue(info.CableConnected)
+	m.AdapterType = types.StringValue(string(info.AdapterType))
+	m.LineSpeedKbps = types.Int64Value(int64(info.LineSpeedKbps))
+	if mac, err := vbox.NormalizeMACAddress(m.MACAddress.ValueString()); err != nil || mac != info.MACAddress {
+		m.MACAddress = types.StringValue(info.MACAddress)
+	}
+}
+
+// Ensure the resource implements the expected interfaces
+var (
+	_ resource.ResourceWithImportState    = &networkAdapterResource{}
+	_ resource.ResourceWithValidateConfig = &networkAdapterResource{}
+)
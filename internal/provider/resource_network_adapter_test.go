@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestNetworkAdapterResourceMetadata(t *testing.T) {
+	r := NewNetworkAdapterResource()
+
+	req := resource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &resource.MetadataResponse{}
+
+	r.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_network_adapter" {
+		t.Errorf("expected TypeName 'vboxweb_network_adapter', got %q", resp.TypeName)
+	}
+}
+
+func TestNetworkAdapterResourceSchema(t *testing.T) {
+	r := NewNetworkAdapterResource()
+
+	req := resource.SchemaRequest{}
+	resp := &resource.SchemaResponse{}
+
+	r.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	for _, attrName := range []string{"machine_id", "slot", "attachment_type"} {
+		if attr, ok := schema.Attributes[attrName]; !ok || !attr.IsRequired() {
+			t.Errorf("expected required %q attribute in schema", attrName)
+		}
+	}
+	for _, attrName := range []string{"bridged_interface", "host_only_interface", "internal_network_name", "nat_network_name"} {
+		if attr, ok := schema.Attributes[attrName]; !ok || !attr.IsOptional() || attr.IsComputed() {
+			t.Errorf("expected optional, not computed %q attribute in schema", attrName)
+		}
+	}
+	for _, attrName := range []string{"enabled", "cable_connected", "mac_address", "adapter_type", "line_speed_kbps"} {
+		if attr, ok := schema.Attributes[attrName]; !ok || !attr.IsOptional() || !attr.IsComputed() {
+			t.Errorf("expected optional and computed %q attribute in schema", attrName)
+		}
+	}
+}
+
+// networkAdapterConfig returns a vboxweb_network_adapter configuration for
+// slot 0 of a VM, with the given string attributes set and all others null.
+func networkAdapterConfig(t *testing.T, values map[string]string) tfsdk.Config {
+	t.Helper()
+	ctx := context.Background()
+	schemaResp := &resource.SchemaResponse{}
+	NewNetworkAdapterResource().Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	objType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	vals := make(map[string]tftypes.Value, len(objType.AttributeTypes))
+	for name, typ := range objType.AttributeTypes {
+		vals[name] = tftypes.NewValue(typ, nil)
+	}
+	vals["machine_id"] = tftypes.NewValue(tftypes.String, "vm")
+	vals["slot"] = tftypes.NewValue(tftypes.Number, 0)
+	for name, v := range values {
+		vals[name] = tftypes.NewValue(tftypes.String, v)
+	}
+	return tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objType, vals)}
+}
+
+func TestNetworkAdapterResourceValidateConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]string
+		wantErr bool
+	}{
+		{name: "nat", config: map[string]string{"attachment_type": "nat"}},
+		{name: "bridged", config: map[string]string{"attachment_type": "bridged", "bridged_interface": "eth0"}},
+		{name: "internal network", config: map[string]string{"attachment_type": "intnet", "internal_network_name": "lab"}},
+		{name: "bridged without interface", config: map[string]string{"attachment_type": "bridged"}, wantErr: true},
+		{name: "NAT network without name", config: map[string]string{"attachment_type": "natnetwork"}, wantErr: true},
+		{name: "interface of another attachment", config: map[string]string{"attachment_type": "nat", "host_only_interface": "vboxnet0"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &networkAdapterResource{}
+			resp := &resource.ValidateConfigResponse{}
+
+			r.ValidateConfig(context.Background(), resource.ValidateConfigRequest{Config: networkAdapterConfig(t, tt.config)}, resp)
+
+			if got := resp.Diagnostics.HasError(); got != tt.wantErr {
+				t.Errorf("HasError() = %v, want %v: %v", got, tt.wantErr, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestNetworkAdapterSettings(t *testing.T) {
+	m := networkAdapterModel{
+		AttachmentType:   types.StringValue("bridged"),
+		BridgedInterface: types.StringValue("eth0"),
+		Enabled:          types.BoolValue(true),
+		CableConnected:   types.BoolValue(false),
+		MACAddress:       types.StringUnknown(),
+		AdapterType:      types.StringValue("Virtio"),
+		LineSpeedKbps:    types.Int64Value(0),
+	}
+
+	s := networkAdapterSettings(m)
+
+	if s.AttachmentType == nil || *s.AttachmentType != vboxapi.NetworkAttachmentTypeBridged {
+		t.Errorf("attachment type = %v, want Bridged", s.AttachmentType)
+	}
+	if s.Network == nil || *s.Network != "eth0" {
+		t.Errorf("network = %v, want eth0", s.Network)
+	}
+	if s.CableConnected == nil || *s.CableConnected {
+		t.Errorf("cable connected = %v, want false", s.CableConnected)
+	}
+	if s.AdapterType == nil || *s.AdapterType != vboxapi.NetworkAdapterTypeVirtio {
+		t.Errorf("adapter type = %v, want Virtio", s.AdapterType)
+	}
+	if s.MACAddress != nil {
+		t.Errorf("MAC address = %q, want an unknown address to be left unchanged", *s.MACAddress)
+	}
+	if s.LineSpeedKbps != nil {
+		t.Errorf("line speed = %d, want the default speed to be left unchanged", *s.LineSpeedKbps)
+	}
+}
+
+func TestSetNetworkAdapterState(t *testing.T) {
+	m := networkAdapterModel{
+		AttachmentType:   types.StringValue("bridged"),
+		BridgedInterface: types.StringValue("eth0"),
+		MACAddress:       types.StringValue("08:00:27:ab:cd:ef"),
+	}
+
+	setNetworkAdapterState(&m, &vbox.NetworkAdapterInfo{
+		Enabled:        true,
+		AttachmentType: vboxapi.NetworkAttachmentTypeInternal,
+		Network:        "lab",
+		AdapterType:    vboxapi.NetworkAdapterTypeI82545EM,
+		MACAddress:     "080027ABCDEF",
+		CableConnected: true,
+		LineSpeedKbps:  1000000,
+	})
+
+	if got := m.AttachmentType.ValueString(); got != "intnet" {
+		t.Errorf("attachment_type = %q, want intnet", got)
+	}
+	if got := m.InternalNetworkName.ValueString(); got != "lab" {
+		t.Errorf("internal_network_name = %q, want lab", got)
+	}
+	if !m.BridgedInterface.IsNull() {
+		t.Errorf("bridged_interface = %s, want null once the adapter is no longer bridged", m.BridgedInterface)
+	}
+	if got := m.MACAddress.ValueString(); got != "08:00:27:ab:cd:ef" {
+		t.Errorf("mac_address = %q, want the configured format to be kept", got)
+	}
+	if got := m.AdapterType.ValueString(); got != "I82545EM" {
+		t.Errorf("adapter_type = %q, want I82545EM", got)
+	}
+	if got := m.LineSpeedKbps.ValueInt64(); got != 1000000 {
+		t.Errorf("line_speed_kbps = %d, want 1000000", got)
+	}
+
+	// An attachment type the resource cannot configure is reported as is,
+	// and a changed MAC address replaces the configured one.
+	setNetworkAdapterState(&m, &vbox.NetworkAdapterInfo{
+		AttachmentType: vboxapi.NetworkAttachmentTypeGeneric,
+		MACAddress:     "080027000001",
+	})
+	if got := m.AttachmentType.ValueString(); got != "generic" {
+		t.Errorf("attachment_type = %q, want generic", got)
+	}
+	if !m.InternalNetworkName.IsNull() {
+		t.Errorf("internal_network_name = %s, want null", m.InternalNetworkName)
+	}
+	if got := m.MACAddress.ValueString(); got != "080027000001" {
+		t.Errorf("mac_address = %q, want 080027000001", got)
+	}
+}
@@ -0,0 +1,232 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+type sharedFolderResource struct {
+	client *vbox.Client
+	// tolerateReadErrors is the provider's tolerate_read_errors.
+	tolerateReadErrors bool
+}
+
+type sharedFolderModel struct {
+	// Identity fields
+	MachineID types.String `tfsdk:"machine_id"`
+	Name      types.String `tfsdk:"name"`
+
+	// Folder configuration
+	HostPath       types.String `tfsdk:"host_path"`
+	Writable       types.Bool   `tfsdk:"writable"`
+	AutoMount      types.Bool   `tfsdk:"automount"`
+	AutoMountPoint types.String `tfsdk:"auto_mount_point"`
+
+	// Computed
+	ID types.String `tfsdk:"id"`
+}
+
+func NewSharedFolderResource() resource.Resource {
+	return &sharedFolderResource{}
+}
+
+func (r *sharedFolderResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_shared_folder"
+}
+
+func (r *sharedFolderResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.tolerateReadErrors = data.tolerateReadErrors
+}
+
+func (r *sharedFolderResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Manages a shared folder of a VirtualBox VM.
+
+A shared folder makes a directory of the VirtualBox host available to the guest, which needs the
+Guest Additions to mount it. Folders can be added to and removed from running VMs.
+
+Changes to any attribute will trigger replacement of the shared folder.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Unique identifier for this resource (machine_id:name).",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"machine_id": schema.StringAttribute{
+				Required:    true,
+				Description: "VirtualBox machine ID (UUID) of the VM to share the folder with.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the shared folder, as seen by the guest. Must be unique within the VM.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"host_path": schema.StringAttribute{
+				Required:    true,
+				Description: "Absolute path of the shared directory on the VirtualBox host.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"writable": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Whether the guest may write to the folder. Default: true.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"automount": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Whether the Guest Additions mount the folder when the guest boots. Default: false.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"auto_mount_point": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+				Description: "Where the guest mounts the folder when automount is true, e.g. '/mnt/src' or 'S:'. Empty string lets the guest choose. Default: empty string.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+	}
+}
+
+func (r *sharedFolderResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan sharedFolderModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	folder := vbox.SharedFolder{
+		MachineID:      plan.MachineID.ValueString(),
+		Name:           plan.Name.ValueString(),
+		HostPath:       plan.HostPath.ValueString(),
+		Writable:       plan.Writable.ValueBool(),
+		AutoMount:      plan.AutoMount.ValueBool(),
+		AutoMountPoint: plan.AutoMountPoint.ValueString(),
+	}
+	if err := r.client.CreateSharedFolder(ctx, folder); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to create shared folder", err)
+		return
+	}
+
+	plan.ID = types.StringValue(fmt.Sprintf("%s:%s", plan.MachineID.ValueString(), plan.Name.ValueString()))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *sharedFolderResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state sharedFolderModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	folder, err := r.client.ReadSharedFolder(ctx, state.MachineID.ValueString(), state.Name.ValueString())
+	if err != nil {
+		// If the machine doesn't exist, remove from state
+		if vbox.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read shared folder", err)
+		return
+	}
+
+	// If the folder was removed out of band, remove from state
+	if folder == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.HostPath = types.StringValue(folder.HostPath)
+	state.Writable = types.BoolValue(folder.Writable)
+	state.AutoMount = types.BoolValue(folder.AutoMount)
+	state.AutoMountPoint = types.StringValue(folder.AutoMountPoint)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *sharedFolderResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// All attributes require replacement.
+	var plan sharedFolderModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *sharedFolderResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state sharedFolderModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteSharedFolder(ctx, state.MachineID.ValueString(), state.Name.ValueString())
+	if err != nil && !vbox.IsNotFound(err) {
+		addClientError(&resp.Diagnostics, "Failed to remove shared folder", err)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState
+func (r *sharedFolderResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Expected import ID format: machine_id:name
+	machineID, name, ok := strings.Cut(req.ID, ":")
+	if !ok || machineID == "" || name == "" {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Expected import ID format: machine_id:name, got: %s", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("machine_id"), machineID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
+
+// Ensure the resource implements the expected interfaces
+var _ resource.ResourceWithImportState = &sharedFolderResource{}
@@ -0,0 +1,231 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+type snapshotResource struct {
+	client *vbox.Client
+	// tolerateReadErrors is the provider's tolerate_read_errors.
+	tolerateReadErrors bool
+}
+
+type snapshotModel struct {
+	// Identity fields
+	MachineID types.String `tfsdk:"machine_id"`
+
+	// Snapshot configuration
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+
+	// Computed
+	ID      types.String `tfsdk:"id"`
+	TakenAt types.String `tfsdk:"taken_at"`
+}
+
+func NewSnapshotResource() resource.Resource {
+	return &snapshotResource{}
+}
+
+func (r *snapshotResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_snapshot"
+}
+
+func (r *snapshotResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.tolerateReadErrors = data.tolerateReadErrors
+}
+
+func (r *snapshotResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Takes a snapshot of a VirtualBox VM, for example a baseline to return to between test runs.
+
+A running VM keeps running while the snapshot is taken, and its memory is saved with the snapshot.
+Destroying the resource deletes the snapshot and merges its differencing images, which can take a while
+for VMs with large disks.
+
+Changes to name and description are applied in place. Changes to machine_id will trigger replacement
+of the snapshot.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "UUID of the snapshot.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"machine_id": schema.StringAttribute{
+				Required:    true,
+				Description: "VirtualBox machine ID (UUID) to take the snapshot of.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the snapshot. VirtualBox does not require snapshot names to be unique.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+				Description: "Description of the snapshot. Default: empty.",
+			},
+			"taken_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "When the snapshot was taken, in RFC 3339 format.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *snapshotResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan snapshotModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	snap, err := r.client.TakeSnapshot(ctx, plan.MachineID.ValueString(), plan.Name.ValueString(), plan.Description.ValueString(), 0)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to take snapshot", err)
+		return
+	}
+
+	setSnapshot(&plan, snap)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *snapshotResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state snapshotModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	snap, err := r.client.ReadSnapshot(ctx, state.MachineID.ValueString(), state.ID.ValueString())
+	if err != nil {
+		// If the machine doesn't exist, remove from state
+		if vbox.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read snapshot", err)
+		return
+	}
+
+	// If the snapshot was deleted out of band, remove from state
+	if snap == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	setSnapshot(&state, snap)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *snapshotResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan snapshotModel
+	var state snapshotModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only the name and description change in place; the machine requires
+	// replacement.
+	var name, description *string
+	if !plan.Name.Equal(state.Name) {
+		v := plan.Name.ValueString()
+		name = &v
+	}
+	if !plan.Description.Equal(state.Description) {
+		v := plan.Description.ValueString()
+		description = &v
+	}
+
+	if err := r.client.UpdateSnapshot(ctx, state.MachineID.ValueString(), state.ID.ValueString(), name, description); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to update snapshot", err)
+		return
+	}
+
+	snap, err := r.client.ReadSnapshot(ctx, state.MachineID.ValueString(), state.ID.ValueString())
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to verify snapshot", err)
+		return
+	}
+	if snap == nil {
+		resp.Diagnostics.AddError("Snapshot not found after update", "The snapshot was changed but could not be read back")
+		return
+	}
+
+	setSnapshot(&plan, snap)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *snapshotResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state snapshotModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteSnapshot(ctx, state.MachineID.ValueString(), state.ID.ValueString(), 0)
+	if err != nil && !vbox.IsNotFound(err) {
+		addClientError(&resp.Diagnostics, "Failed to delete snapshot", err)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState
+func (r *snapshotResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Expected import ID format: machine_id:snapshot_id
+	machineID, snapshotID, ok := strings.Cut(req.ID, ":")
+	if !ok || machineID == "" || snapshotID == "" {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Expected import ID format: machine_id:snapshot_id, got: %s", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("machine_id"), machineID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), snapshotID)...)
+}
+
+// setSnapshot records the snapshot's identity and settings in m.
+func setSnapshot(m *snapshotModel, snap *vbox.Snapshot) {
+	m.ID = types.StringValue(snap.ID)
+	m.Name = types.StringValue(snap.Name)
+	m.Description = types.StringValue(snap.Description)
+	m.TakenAt = types.StringValue(snap.TakenAt.Format(time.RFC3339))
+}
+
+// Ensure the resource implements the expected interfaces
+var _ resource.ResourceWithImportState = &snapshotResource{}
@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+)
+
+func TestSnapshotResourceMetadata(t *testing.T) {
+	r := NewSnapshotResource()
+
+	req := resource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &resource.MetadataResponse{}
+
+	r.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_snapshot" {
+		t.Errorf("expected TypeName 'vboxweb_snapshot', got %q", resp.TypeName)
+	}
+}
+
+func TestSnapshotResourceSchema(t *testing.T) {
+	r := NewSnapshotResource()
+
+	req := resource.SchemaRequest{}
+	resp := &resource.SchemaResponse{}
+
+	r.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	for _, attrName := range []string{"machine_id", "name"} {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsRequired() {
+			t.Errorf("expected %q attribute to be required", attrName)
+		}
+	}
+
+	if attr, ok := schema.Attributes["description"]; !ok || !attr.IsOptional() {
+		t.Error("expected optional 'description' attribute in schema")
+	}
+
+	for _, attrName := range []string{"id", "taken_at"} {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsComputed() || attr.IsOptional() {
+			t.Errorf("expected %q attribute to be computed only", attrName)
+		}
+	}
+}
+
+func TestSetSnapshot(t *testing.T) {
+	var m snapshotModel
+	setSnapshot(&m, &vbox.Snapshot{
+		MachineID: "uuid-vm",
+		ID:        "uuid-snap",
+		Name:      "baseline",
+		TakenAt:   time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+	})
+
+	if m.ID.ValueString() != "uuid-snap" || m.Name.ValueString() != "baseline" {
+		t.Errorf("id, name = %v, %v; want uuid-snap, baseline", m.ID, m.Name)
+	}
+	if m.Description.IsNull() || m.Description.ValueString() != "" {
+		t.Errorf("description = %v, want an empty string", m.Description)
+	}
+	if want := "2024-05-01T12:30:00Z"; m.TakenAt.ValueString() != want {
+		t.Errorf("taken_at = %v, want %s", m.TakenAt, want)
+	}
+}
+
+// faultBody is a vboxwebsrv SOAP fault for a failure other than not-found.
+const faultBody = `<?xml version="1.0" encoding="UTF-8"?>
+<SOAP-ENV:Envelope xmlns:SOAP-ENV="http://schemas.xmlsoap.org/soap/envelope/" xmlns:vbox="http://www.virtualbox.org/">
+<SOAP-ENV:Body><SOAP-ENV:Fault>
+<faultcode>SOAP-ENV:Client</faultcode>
+<faultstring>VirtualBox error: The object is not ready (0x80004005)</faultstring>
+<detail><vbox:RuntimeFault><resultCode>-2147467259</resultCode><returnval></returnval></vbox:RuntimeFault></detail>
+</SOAP-ENV:Fault></SOAP-ENV:Body></SOAP-ENV:Envelope>`
+
+func TestSnapshotResourceRead_TolerateReadErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(faultBody))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	schemaResp := &resource.SchemaResponse{}
+	NewSnapshotResource().Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	prior := snapshotModel{
+		MachineID:   types.StringValue("uuid-vm"),
+		ID:          types.StringValue("uuid-snapshot"),
+		Name:        types.StringValue("baseline"),
+		Description: types.StringValue(""),
+		TakenAt:     types.StringValue("2024-05-01T12:00:00Z"),
+	}
+
+	for _, tolerate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("tolerate=%t", tolerate), func(t *testing.T) {
+			r := &snapshotResource{client: vbox.NewClient(srv.URL, "", ""), tolerateReadErrors: tolerate}
+			state := tfsdk.State{Schema: schemaResp.Schema}
+			if diags := state.Set(ctx, &prior); diags.HasError() {
+				t.Fatalf("unexpected errors: %v", diags)
+			}
+			resp := &resource.ReadResponse{State: state}
+
+			r.Read(ctx, resource.ReadRequest{State: state}, resp)
+
+			if got := resp.Diagnostics.HasError(); got == tolerate {
+				t.Fatalf("HasError() = %t, want %t: %v", got, !tolerate, resp.Diagnostics)
+			}
+			if !tolerate {
+				return
+			}
+			if resp.Diagnostics.WarningsCount() != 1 {
+				t.Errorf("expected 1 warning, got %v", resp.Diagnostics)
+			}
+			var got snapshotModel
+			if diags := resp.State.Get(ctx, &got); diags.HasError() {
+				t.Fatalf("unexpected errors: %v", diags)
+			}
+			if got != prior {
+				t.Errorf("state = %+v, want the prior state %+v", got, prior)
+			}
+		})
+	}
+}
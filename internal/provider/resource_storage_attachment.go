@@ -0,0 +1,394 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+type storageAttachmentResource struct {
+	client *vbox.Client
+	// tolerateReadErrors is the provider's tolerate_read_errors.
+	tolerateReadErrors bool
+}
+
+type storageAttachmentModel struct {
+	// Identity fields
+	MachineID  types.String `tfsdk:"machine_id"`
+	Controller types.String `tfsdk:"controller"`
+	Port       types.Int64  `tfsdk:"port"`
+	Device     types.Int64  `tfsdk:"device"`
+
+	// Medium configuration
+	Type       types.String `tfsdk:"type"`
+	Medium     types.String `tfsdk:"medium"`
+	AccessMode types.String `tfsdk:"access_mode"`
+
+	// Computed
+	MediumID types.String `tfsdk:"medium_id"`
+	ID       types.String `tfsdk:"id"`
+}
+
+// storageDeviceTypes maps the schema's device type names to VirtualBox device types.
+var storageDeviceTypes = map[string]vboxapi.DeviceType{
+	"hdd":    vboxapi.DeviceTypeHardDisk,
+	"dvd":    vboxapi.DeviceTypeDVD,
+	"floppy": vboxapi.DeviceTypeFloppy,
+}
+
+func NewStorageAttachmentResource() resource.Resource {
+	return &storageAttachmentResource{}
+}
+
+func (r *storageAttachmentResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_storage_attachment"
+}
+
+func (r *storageAttachmentResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.tolerateReadErrors = data.tolerateReadErrors
+}
+
+func (r *storageAttachmentResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Attaches an existing medium (disk image, ISO or floppy image) to a storage controller slot of a VirtualBox VM.
+
+The medium must already exist on the VirtualBox host. While the VM is running, media can only be attached and detached
+on hot-plug capable controllers (SATA/AHCI and USB); other controllers require the VM to be powered off.
+
+**Shared disks:** set access_mode to "shareable" to attach the same disk to several VMs at once.
+VirtualBox only allows this for fixed-size hard disk images; dynamically allocated images are rejected.
+Use access_mode "readonly" to open a medium without write access.
+
+**Removable media:** for dvd and floppy attachments, changing medium swaps the disc in place
+without detaching the drive, and setting it to "" ejects the current disc. This also works while the VM is running.
+Without a medium, an empty drive is attached, for example to add a DVD drive to a clone that has none.
+Floppy drives require a floppy (I82078) controller, and NVMe controllers do not accept DVD drives.
+
+Changes to any other attribute will trigger replacement of the attachment.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Unique identifier for this resource (machine_id:controller:port:device).",
+			},
+			"machine_id": schema.StringAttribute{
+				Required:    true,
+				Description: "VirtualBox machine ID (UUID) to attach the medium to.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"controller": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the storage controller, for example SATA or IDE.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"port": schema.Int64Attribute{
+				Required:    true,
+				Description: "Controller port number.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"device": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(0),
+				Description: "Device number on the port. Only IDE controllers use values other than 0. Default: 0.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("hdd"),
+				Description: "Device type: 'hdd', 'dvd' or 'floppy'. Default: 'hdd'.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("hdd", "dvd", "floppy"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"medium": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+				Description: "Path of the medium image on the VirtualBox host. Required for hdd attachments. For dvd and floppy attachments, omitting it or setting it to \"\" attaches an empty drive, and changes are applied in place by swapping the disc.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIf(
+						mediumRequiresReplace,
+						"Changing the medium of a hard disk attachment requires replacement.",
+						"Changing the medium of a hard disk attachment requires replacement.",
+					),
+				},
+			},
+			"access_mode": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(vbox.MediumAccessReadWrite),
+				Description: "How the medium is opened: 'readwrite', 'readonly' or 'shareable'. 'shareable' sets the medium type to Shareable so that several VMs can attach it, and requires a fixed-size hard disk. Default: 'readwrite'.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(vbox.MediumAccessReadWrite, vbox.MediumAccessReadOnly, vbox.MediumAccessShareable),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"medium_id": schema.StringAttribute{
+				Computed:    true,
+				Description: "UUID of the attached medium. Empty when a removable drive holds no medium.",
+			},
+		},
+	}
+}
+
+// mediumRequiresReplace forces replacement when the medium of a hard disk
+// changes. Removable media are swapped in place with MountMedium.
+func mediumRequiresReplace(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+	var deviceType types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("type"), &deviceType)...)
+	resp.RequiresReplace = deviceType.ValueString() == "hdd"
+}
+
+// ValidateConfig implements resource.ResourceWithValidateConfig.
+func (r *storageAttachmentResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var cfg storageAttachmentModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if cfg.AccessMode.ValueString() == vbox.MediumAccessShareable && !cfg.Type.IsNull() && !cfg.Type.IsUnknown() && cfg.Type.ValueString() != "hdd" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("access_mode"),
+			"Invalid access mode",
+			fmt.Sprintf("access_mode %q is only supported for hdd attachments, got type %q", vbox.MediumAccessShareable, cfg.Type.ValueString()),
+		)
+	}
+
+	isHardDisk := cfg.Type.IsNull() || cfg.Type.ValueString() == "hdd"
+	if isHardDisk && !cfg.Medium.IsUnknown() && cfg.Medium.ValueString() == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("medium"),
+			"Missing medium",
+			"medium is required for hdd attachments; only dvd and floppy drives can be attached empty",
+		)
+	}
+}
+
+func (r *storageAttachmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan storageAttachmentModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	att := vbox.StorageAttachment{
+		MachineID:  plan.MachineID.ValueString(),
+		Controller: plan.Controller.ValueString(),
+		Port:       int32(plan.Port.ValueInt64()),
+		Device:     int32(plan.Device.ValueInt64()),
+		DeviceType: storageDeviceTypes[plan.Type.ValueString()],
+		Medium:     plan.Medium.ValueString(),
+		AccessMode: plan.AccessMode.ValueString(),
+	}
+
+	if err := r.client.AttachStorage(ctx, att); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to attach medium", err)
+		return
+	}
+
+	// Read back to confirm
+	readAtt, err := r.client.ReadStorageAttachment(ctx, att.MachineID, att.Controller, att.Port, att.Device)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to verify storage attachment", err)
+		return
+	}
+	if readAtt == nil {
+		resp.Diagnostics.AddError("Storage attachment not found after creation", "The medium was attached but could not be read back")
+		return
+	}
+
+	plan.ID = types.StringValue(fmt.Sprintf("%s:%s:%d:%d", att.MachineID, att.Controller, att.Port, att.Device))
+	plan.MediumID = types.StringValue(readAtt.MediumID)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *storageAttachmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state storageAttachmentModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	att, err := r.client.ReadStorageAttachment(
+		ctx,
+		state.MachineID.ValueString(),
+		state.Controller.ValueString(),
+		int32(state.Port.ValueInt64()),
+		int32(state.Device.ValueInt64()),
+	)
+	if err != nil {
+		// If the machine doesn't exist, remove from state
+		if vbox.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read storage attachment", err)
+		return
+	}
+
+	// If nothing is attached to the slot anymore, remove from state
+	if att == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	for name, dt := range storageDeviceTypes {
+		if dt == att.DeviceType {
+			state.Type = types.StringValue(name)
+		}
+	}
+	state.Medium = types.StringValue(att.Medium)
+	state.MediumID = types.StringValue(att.MediumID)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *storageAttachmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan storageAttachmentModel
+	var state storageAttachmentModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only the medium of removable drives can change in place; everything
+	// else requires replacement.
+	att := vbox.StorageAttachment{
+		MachineID:  plan.MachineID.ValueString(),
+		Controller: plan.Controller.ValueString(),
+		Port:       int32(plan.Port.ValueInt64()),
+		Device:     int32(plan.Device.ValueInt64()),
+		DeviceType: storageDeviceTypes[plan.Type.ValueString()],
+		Medium:     plan.Medium.ValueString(),
+		AccessMode: plan.AccessMode.ValueString(),
+	}
+
+	if !plan.Medium.Equal(state.Medium) {
+		if err := r.client.MountStorageMedium(ctx, att); err != nil {
+			addClientError(&resp.Diagnostics, "Failed to change medium", err)
+			return
+		}
+	}
+
+	readAtt, err := r.client.ReadStorageAttachment(ctx, att.MachineID, att.Controller, att.Port, att.Device)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to verify storage attachment", err)
+		return
+	}
+	if readAtt == nil {
+		resp.Diagnostics.AddError("Storage attachment not found after update", "The medium was changed but the attachment could not be read back")
+		return
+	}
+
+	plan.MediumID = types.StringValue(readAtt.MediumID)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *storageAttachmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state storageAttachmentModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DetachStorage(
+		ctx,
+		state.MachineID.ValueString(),
+		state.Controller.ValueString(),
+		int32(state.Port.ValueInt64()),
+		int32(state.Device.ValueInt64()),
+	)
+	if err != nil && !vbox.IsNotFound(err) {
+		addClientError(&resp.Diagnostics, "Failed to detach medium", err)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState
+func (r *storageAttachmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Expected import ID format: machine_id:controller:port:device
+	parts := strings.Split(req.ID, ":")
+	if len(parts) != 4 {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Expected import ID format: machine_id:controller:port:device, got: %s", req.ID),
+		)
+		return
+	}
+
+	port, err := strconv.ParseInt(parts[2], 10, 32)
+	if err != nil || port < 0 {
+		resp.Diagnostics.AddError(
+			"Invalid port",
+			fmt.Sprintf("Port must be a non-negative number, got: %s", parts[2]),
+		)
+		return
+	}
+	device, err := strconv.ParseInt(parts[3], 10, 32)
+	if err != nil || device < 0 {
+		resp.Diagnostics.AddError(
+			"Invalid device",
+			fmt.Sprintf("Device must be a non-negative number, got: %s", parts[3]),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("machine_id"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("controller"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("port"), port)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), device)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("access_mode"), vbox.MediumAccessReadWrite)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
+
+// Ensure the resource implements the expected interfaces
+var (
+	_ resource.ResourceWithImportState    = &storageAttachmentResource{}
+	_ resource.ResourceWithValidateConfig = &storageAttachmentResource{}
+)
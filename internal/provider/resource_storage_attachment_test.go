@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func TestStorageAttachmentResourceMetadata(t *testing.T) {
+	r := NewStorageAttachmentResource()
+
+	req := resource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &resource.MetadataResponse{}
+
+	r.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_storage_attachment" {
+		t.Errorf("expected TypeName 'vboxweb_storage_attachment', got %q", resp.TypeName)
+	}
+}
+
+func TestStorageAttachmentResourceSchema(t *testing.T) {
+	r := NewStorageAttachmentResource()
+
+	req := resource.SchemaRequest{}
+	resp := &resource.SchemaResponse{}
+
+	r.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	// Check required attributes
+	requiredAttrs := []string{"machine_id", "controller", "port"}
+	for _, attrName := range requiredAttrs {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsRequired() {
+			t.Errorf("expected %q attribute to be required", attrName)
+		}
+	}
+
+	// Check computed attributes
+	computedOnlyAttrs := []string{"id", "medium_id"}
+	for _, attrName := range computedOnlyAttrs {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsComputed() {
+			t.Errorf("expected %q attribute to be computed", attrName)
+		}
+	}
+
+	// Check optional attributes with defaults
+	optionalWithDefaults := []string{"device", "type", "medium", "access_mode"}
+	for _, attrName := range optionalWithDefaults {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsOptional() {
+			t.Errorf("expected %q attribute to be optional", attrName)
+		}
+	}
+}
+
+func TestStorageAttachmentResourceConfigure_NilProviderData(t *testing.T) {
+	r := &storageAttachmentResource{}
+
+	req := resource.ConfigureRequest{
+		ProviderData: nil,
+	}
+	resp := &resource.ConfigureResponse{}
+
+	r.Configure(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Errorf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	if r.client != nil {
+		t.Error("expected client to be nil when ProviderData is nil")
+	}
+}
@@ -0,0 +1,357 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+type storageControllerResource struct {
+	client *vbox.Client
+	// tolerateReadErrors is the provider's tolerate_read_errors.
+	tolerateReadErrors bool
+}
+
+type storageControllerModel struct {
+	// Identity fields
+	MachineID types.String `tfsdk:"machine_id"`
+	Name      types.String `tfsdk:"name"`
+	Bus       types.String `tfsdk:"bus"`
+
+	// Controller configuration
+	ControllerType types.String `tfsdk:"controller_type"`
+	PortCount      types.Int64  `tfsdk:"port_count"`
+	Bootable       types.Bool   `tfsdk:"bootable"`
+
+	// Computed
+	ID types.String `tfsdk:"id"`
+}
+
+// storageBuses maps the schema's bus names to VirtualBox storage buses.
+// NVMe controllers sit on the PCIe bus.
+var storageBuses = map[string]vboxapi.StorageBus{
+	"IDE":        vboxapi.StorageBusIDE,
+	"SATA":       vboxapi.StorageBusSATA,
+	"SCSI":       vboxapi.StorageBusSCSI,
+	"SAS":        vboxapi.StorageBusSAS,
+	"Floppy":     vboxapi.StorageBusFloppy,
+	"NVMe":       vboxapi.StorageBusPCIe,
+	"USB":        vboxapi.StorageBusUSB,
+	"VirtioSCSI": vboxapi.StorageBusVirtioSCSI,
+}
+
+func NewStorageControllerResource() resource.Resource {
+	return &storageControllerResource{}
+}
+
+func (r *storageControllerResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_storage_controller"
+}
+
+func (r *storageControllerResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.tolerateReadErrors = data.tolerateReadErrors
+}
+
+func (r *storageControllerResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	busNames := slices.Sorted(maps.Keys(storageBuses))
+	var controllerTypes []string
+	for _, name := range busNames {
+		for _, t := range vbox.ControllerTypesForBus(storageBuses[name]) {
+			controllerTypes = append(controllerTypes, string(t))
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		Description: `Adds a storage controller to a VirtualBox VM, for example an NVMe controller that the source VM lacks.
+Disks and drives are then attached to it with vboxweb_storage_attachment.
+
+VirtualBox only changes storage controllers of powered-off VMs. Deleting the controller also detaches
+the devices attached to it.
+
+**Extension Pack:** NVMe and USB storage controllers are provided by the VirtualBox Extension Pack,
+which must be installed on the VirtualBox host.
+
+Changes to machine_id, name or bus will trigger replacement of the controller.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Unique identifier for this resource (machine_id:name).",
+			},
+			"machine_id": schema.StringAttribute{
+				Required:    true,
+				Description: "VirtualBox machine ID (UUID) to add the controller to.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the controller, which storage attachments refer to. Must be unique within the VM.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"bus": schema.StringAttribute{
+				Required:    true,
+				Description: "Bus of the controller: 'IDE', 'SATA', 'SCSI', 'SAS', 'Floppy', 'NVMe', 'USB' or 'VirtioSCSI'.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(busNames...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"controller_type": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Emulated controller chipset. It must suit the bus: 'PIIX4', 'PIIX3' or 'ICH6' for IDE, 'LsiLogic' or 'BusLogic' for SCSI; the other buses have a single type. Default: VirtualBox's default for the bus.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(controllerTypes...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"port_count": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Number of ports. The valid range depends on the bus, for example 1 to 30 for SATA; IDE controllers always have 2. Default: VirtualBox's default for the bus.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"bootable": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether the firmware may boot from the devices attached to the controller. VirtualBox allows one bootable controller per controller type, and makes the first controller of a type bootable; making a controller bootable fails while another of its type is. Default: VirtualBox's choice.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+// ValidateConfig implements resource.ResourceWithValidateConfig.
+func (r *storageControllerResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var cfg storageControllerModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if cfg.Bus.IsNull() || cfg.Bus.IsUnknown() || cfg.ControllerType.IsNull() || cfg.ControllerType.IsUnknown() {
+		return
+	}
+	bus, ok := storageBuses[cfg.Bus.ValueString()]
+	if !ok {
+		return
+	}
+	allowed := vbox.ControllerTypesForBus(bus)
+	for _, t := range allowed {
+		if string(t) == cfg.ControllerType.ValueString() {
+			return
+		}
+	}
+	resp.Diagnostics.AddAttributeError(
+		path.Root("controller_type"),
+		"Invalid controller type",
+		fmt.Sprintf("controller_type %q is not supported on the %s bus; use one of %v", cfg.ControllerType.ValueString(), cfg.Bus.ValueString(), allowed),
+	)
+}
+
+func (r *storageControllerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan storageControllerModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctl := vbox.StorageController{
+		MachineID: plan.MachineID.ValueString(),
+		Name:      plan.Name.ValueString(),
+		Bus:       storageBuses[plan.Bus.ValueString()],
+	}
+	if !plan.ControllerType.IsUnknown() {
+		ctl.Type = vboxapi.StorageControllerType(plan.ControllerType.ValueString())
+	}
+	if !plan.PortCount.IsUnknown() {
+		ctl.PortCount = uint32(plan.PortCount.ValueInt64())
+	}
+	if !plan.Bootable.IsUnknown() {
+		ctl.Bootable = plan.Bootable.ValueBoolPointer()
+	}
+
+	if err := r.client.CreateStorageController(ctx, ctl); err != nil {
+		addClientError(&resp.Diagnostics, "Failed to add storage controller", err)
+		return
+	}
+
+	// Read back to confirm and fill in the defaults
+	readCtl, err := r.client.ReadStorageController(ctx, ctl.MachineID, ctl.Name)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to verify storage controller", err)
+		return
+	}
+	if readCtl == nil {
+		resp.Diagnostics.AddError("Storage controller not found after creation", "The controller was added but could not be read back")
+		return
+	}
+
+	plan.ID = types.StringValue(fmt.Sprintf("%s:%s", ctl.MachineID, ctl.Name))
+	setStorageController(&plan, readCtl)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *storageControllerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state storageControllerModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctl, err := r.client.ReadStorageController(ctx, state.MachineID.ValueString(), state.Name.ValueString())
+	if err != nil {
+		// If the machine doesn't exist, remove from state
+		if vbox.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		addReadError(&resp.Diagnostics, r.tolerateReadErrors, "Failed to read storage controller", err)
+		return
+	}
+
+	// If the controller was removed out of band, remove from state
+	if ctl == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	setStorageController(&state, ctl)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *storageControllerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan storageControllerModel
+	var state storageControllerModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only the controller type, port count and bootable flag change in
+	// place; everything else requires replacement.
+	ctl := vbox.StorageController{
+		MachineID: plan.MachineID.ValueString(),
+		Name:      plan.Name.ValueString(),
+		Bus:       storageBuses[plan.Bus.ValueString()],
+	}
+	if !plan.ControllerType.IsUnknown() && !plan.ControllerType.Equal(state.ControllerType) {
+		ctl.Type = vboxapi.StorageControllerType(plan.ControllerType.ValueString())
+	}
+	if !plan.PortCount.IsUnknown() && !plan.PortCount.Equal(state.PortCount) {
+		ctl.PortCount = uint32(plan.PortCount.ValueInt64())
+	}
+	if !plan.Bootable.IsUnknown() && !plan.Bootable.Equal(state.Bootable) {
+		ctl.Bootable = plan.Bootable.ValueBoolPointer()
+	}
+
+	if ctl.Type != "" || ctl.PortCount != 0 || ctl.Bootable != nil {
+		if err := r.client.UpdateStorageController(ctx, ctl); err != nil {
+			addClientError(&resp.Diagnostics, "Failed to update storage controller", err)
+			return
+		}
+	}
+
+	readCtl, err := r.client.ReadStorageController(ctx, ctl.MachineID, ctl.Name)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Failed to verify storage controller", err)
+		return
+	}
+	if readCtl == nil {
+		resp.Diagnostics.AddError("Storage controller not found after update", "The controller was changed but could not be read back")
+		return
+	}
+
+	setStorageController(&plan, readCtl)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *storageControllerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state storageControllerModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteStorageController(ctx, state.MachineID.ValueString(), state.Name.ValueString())
+	if err != nil && !vbox.IsNotFound(err) {
+		addClientError(&resp.Diagnostics, "Failed to remove storage controller", err)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState
+func (r *storageControllerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Expected import ID format: machine_id:name
+	machineID, name, ok := strings.Cut(req.ID, ":")
+	if !ok || machineID == "" || name == "" {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Expected import ID format: machine_id:name, got: %s", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("machine_id"), machineID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
+
+// setStorageController records the controller's bus and settings in m.
+func setStorageController(m *storageControllerModel, ctl *vbox.StorageController) {
+	for name, bus := range storageBuses {
+		if bus == ctl.Bus {
+			m.Bus = types.StringValue(name)
+		}
+	}
+	m.ControllerType = types.StringValue(string(ctl.Type))
+	m.PortCount = types.Int64Value(int64(ctl.PortCount))
+	m.Bootable = types.BoolPointerValue(ctl.Bootable)
+}
+
+// Ensure the resource implements the expected interfaces
+var (
+	_ resource.ResourceWithImportState    = &storageControllerResource{}
+	_ resource.ResourceWithValidateConfig = &storageControllerResource{}
+)
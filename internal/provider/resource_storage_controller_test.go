@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestStorageControllerResourceMetadata(t *testing.T) {
+	r := NewStorageControllerResource()
+
+	req := resource.MetadataRequest{
+		ProviderTypeName: "vboxweb",
+	}
+	resp := &resource.MetadataResponse{}
+
+	r.Metadata(context.Background(), req, resp)
+
+	if resp.TypeName != "vboxweb_storage_controller" {
+		t.Errorf("expected TypeName 'vboxweb_storage_controller', got %q", resp.TypeName)
+	}
+}
+
+func TestStorageControllerResourceSchema(t *testing.T) {
+	r := NewStorageControllerResource()
+
+	req := resource.SchemaRequest{}
+	resp := &resource.SchemaResponse{}
+
+	r.Schema(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+
+	schema := resp.Schema
+
+	// Check required attributes
+	requiredAttrs := []string{"machine_id", "name", "bus"}
+	for _, attrName := range requiredAttrs {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsRequired() {
+			t.Errorf("expected %q attribute to be required", attrName)
+		}
+	}
+
+	// Check optional/computed attributes
+	optionalComputedAttrs := []string{"controller_type", "port_count", "bootable"}
+	for _, attrName := range optionalComputedAttrs {
+		attr, ok := schema.Attributes[attrName]
+		if !ok {
+			t.Errorf("expected %q attribute in schema", attrName)
+			continue
+		}
+		if !attr.IsOptional() {
+			t.Errorf("expected %q attribute to be optional", attrName)
+		}
+		if !attr.IsComputed() {
+			t.Errorf("expected %q attribute to be computed", attrName)
+		}
+	}
+
+	if attr, ok := schema.Attributes["id"]; !ok || !attr.IsComputed() {
+		t.Error("expected computed 'id' attribute in schema")
+	}
+}
+
+func TestStorageControllerResourceValidateConfig(t *testing.T) {
+	ctx := context.Background()
+	r := &storageControllerResource{}
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	objType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	// config returns a configuration with only bus and controller_type set.
+	config := func(bus, controllerType string) tftypes.Value {
+		vals := make(map[string]tftypes.Value, len(objType.AttributeTypes))
+		for name, typ := range objType.AttributeTypes {
+			vals[name] = tftypes.NewValue(typ, nil)
+		}
+		vals["bus"] = tftypes.NewValue(tftypes.String, bus)
+		if controllerType != "" {
+			vals["controller_type"] = tftypes.NewValue(tftypes.String, controllerType)
+		}
+		return tftypes.NewValue(objType, vals)
+	}
+
+	tests := []struct {
+		name           string
+		bus            string
+		controllerType string
+		wantErr        bool
+	}{
+		{name: "default type", bus: "NVMe"},
+		{name: "matching type", bus: "SCSI", controllerType: "BusLogic"},
+		{name: "NVMe on the PCIe bus", bus: "NVMe", controllerType: "NVMe"},
+		{name: "IDE chipset on SATA", bus: "SATA", controllerType: "PIIX4", wantErr: true},
+		{name: "floppy chipset on IDE", bus: "IDE", controllerType: "I82078", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := resource.ValidateConfigRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: config(tt.bus, tt.controllerType)},
+			}
+			resp := &resource.ValidateConfigResponse{}
+
+			r.ValidateConfig(ctx, req, resp)
+
+			if got := resp.Diagnostics.HasError(); got != tt.wantErr {
+				t.Errorf("HasError() = %v, want %v: %v", got, tt.wantErr, resp.Diagnostics)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package vbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// Feature is a VirtualBox feature that older versions lack.
+type Feature string
+
+const (
+	FeatureARM        Feature = "ARM VMs"
+	FeatureNVMe       Feature = "NVMe storage controllers"
+	FeatureVirtioSCSI Feature = "VirtioSCSI storage controllers"
+)
+
+// featureMinVersions is the first VirtualBox version that supports each
+// Feature.
+var featureMinVersions = map[Feature]apiVersion{
+	FeatureARM:        {7, 1},
+	FeatureNVMe:       {5, 1},
+	FeatureVirtioSCSI: {6, 1},
+}
+
+// apiVersion is a VirtualBox major.minor version.
+type apiVersion struct {
+	major, minor int
+}
+
+func (v apiVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.major, v.minor)
+}
+
+func (v apiVersion) less(o apiVersion) bool {
+	return v.major < o.major || (v.major == o.major && v.minor < o.minor)
+}
+
+// parseAPIVersion parses the version IVirtualBox::APIVersion reports, e.g.
+// "7_1", which may carry a suffix such as "_BETA1".
+func parseAPIVersion(s string) (apiVersion, error) {
+	parts := strings.Split(s, "_")
+	if len(parts) < 2 {
+		return apiVersion{}, fmt.Errorf("unexpected VirtualBox API version %q", s)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return apiVersion{}, fmt.Errorf("unexpected VirtualBox API version %q", s)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return apiVersion{}, fmt.Errorf("unexpected VirtualBox API version %q", s)
+	}
+	return apiVersion{major, minor}, nil
+}
+
+// checkFeature returns an error if version does not support feature.
+func checkFeature(feature Feature, version apiVersion) error {
+	want, ok := featureMinVersions[feature]
+	if !ok || !version.less(want) {
+		return nil
+	}
+	return fmt.Errorf("%s require VirtualBox >= %s, detected %s", feature, want, version)
+}
+
+// SetCheckFeatureSupport sets whether operations that use a Feature first
+// check it against the VirtualBox version of the host, so that they fail
+// with an error naming the version needed instead of a SOAP fault. The
+// version is read once per client.
+func (c *Client) SetCheckFeatureSupport(check bool) {
+	c.checkFeatures = check
+}
+
+// requireFeature returns an error if feature checks are on and the
+// VirtualBox host does not support feature.
+func (c *Client) requireFeature(ctx context.Context, api vboxapi.VBoxAPI, session string, feature Feature) error {
+	if !c.checkFeatures {
+		return nil
+	}
+	version, err := c.hostVersion(ctx, api, session)
+	if err != nil {
+		return err
+	}
+	return checkFeature(feature, version)
+}
+
+// hostVersion returns the VirtualBox version of the host, reading it on
+// first use.
+func (c *Client) hostVersion(ctx context.Context, api vboxapi.VBoxAPI, session string) (apiVersion, error) {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	if c.version != nil {
+		return *c.version, nil
+	}
+	s, err := api.GetAPIVersion(ctx, session)
+	if err != nil {
+		return apiVersion{}, fmt.Errorf("failed to get VirtualBox API version: %w", err)
+	}
+	version, err := parseAPIVersion(s)
+	if err != nil {
+		return apiVersion{}, err
+	}
+	c.version = &version
+	return version, nil
+}
+
+// storageBusFeature returns the Feature a storage controller on bus needs,
+// if any.
+func storageBusFeature(bus vboxapi.StorageBus) (Feature, bool) {
+	switch bus {
+	case vboxapi.StorageBusPCIe:
+		return FeatureNVMe, true
+	case vboxapi.StorageBusVirtioSCSI:
+		return FeatureVirtioSCSI, true
+	}
+	return "", false
+}
@@ -0,0 +1,94 @@
+package vbox
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestParseAPIVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    apiVersion
+		wantErr bool
+	}{
+		{"7_1", apiVersion{7, 1}, false},
+		{"6_1", apiVersion{6, 1}, false},
+		{"7_2_BETA1", apiVersion{7, 2}, false},
+		{"7", apiVersion{}, true},
+		{"7.1", apiVersion{}, true},
+		{"", apiVersion{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseAPIVersion(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseAPIVersion(%q) = %v, %v; want %v, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckFeature(t *testing.T) {
+	tests := []struct {
+		feature Feature
+		version apiVersion
+		wantErr string
+	}{
+		{FeatureARM, apiVersion{7, 1}, ""},
+		{FeatureARM, apiVersion{8, 0}, ""},
+		{FeatureARM, apiVersion{7, 0}, "ARM VMs require VirtualBox >= 7.1, detected 7.0"},
+		{FeatureARM, apiVersion{6, 1}, "ARM VMs require VirtualBox >= 7.1, detected 6.1"},
+		{FeatureVirtioSCSI, apiVersion{6, 1}, ""},
+		{FeatureVirtioSCSI, apiVersion{6, 0}, "VirtioSCSI storage controllers require VirtualBox >= 6.1, detected 6.0"},
+		{FeatureNVMe, apiVersion{5, 1}, ""},
+		{FeatureNVMe, apiVersion{5, 0}, "NVMe storage controllers require VirtualBox >= 5.1, detected 5.0"},
+		{Feature("unknown"), apiVersion{4, 3}, ""},
+	}
+	for _, tt := range tests {
+		err := checkFeature(tt.feature, tt.version)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("checkFeature(%s, %s) unexpected error: %v", tt.feature, tt.version, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("checkFeature(%s, %s) error = %v, want %q", tt.feature, tt.version, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRequireFeature(t *testing.T) {
+	api := newFakeAPI()
+	api.apiVersion = "7_0"
+	c := newTestClient(api)
+	ctx := context.Background()
+
+	// Checks are off by default.
+	if err := c.requireFeature(ctx, api, "session", FeatureARM); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("GetAPIVersion"); n != 0 {
+		t.Errorf("GetAPIVersion called %d times, want 0", n)
+	}
+
+	c.SetCheckFeatureSupport(true)
+	_, err := c.CreateAndConverge(ctx, CreateRequest{Name: "arm", OSTypeID: "Ubuntu_arm64", PlatformArchitecture: vboxapi.PlatformArchitectureARM})
+	if err == nil || !strings.Contains(err.Error(), "ARM VMs require VirtualBox >= 7.1, detected 7.0") {
+		t.Errorf("error = %v, want the ARM check to fail", err)
+	}
+	if n := api.called("CreateMachine"); n != 0 {
+		t.Errorf("CreateMachine called %d times, want 0", n)
+	}
+
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	err = c.CreateStorageController(ctx, StorageController{MachineID: "uuid-vm", Name: "Virtio", Bus: vboxapi.StorageBusVirtioSCSI})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The version is read once.
+	if n := api.called("GetAPIVersion"); n != 1 {
+		t.Errorf("GetAPIVersion called %d times, want 1", n)
+	}
+}
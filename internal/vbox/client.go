@@ -1,12 +1,18 @@
 package vbox
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
 	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox71"
 	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
 )
@@ -16,11 +22,96 @@ type Client struct {
 	endpoint string
 	username string
 	password string
+
+	// newAPI builds the SOAP adapter for each session. Tests replace it
+	// with an in-memory fake.
+	newAPI func() vboxapi.VBoxAPI
+
+	// httpClient sends the SOAP requests. It is built from transport; see
+	// SetTLSOptions, SetRequestOptions and SetProxyURL.
+	httpClient *http.Client
+	transport  transportOptions
+
+	// registrationWait is how long CreateNATPortForwards waits for a
+	// machine that is not registered yet.
+	registrationWait time.Duration
+
+	// autoRanges holds the auto allocation ranges requested so far.
+	autoRanges autoRangeSet
+
+	// verifyConsole makes started VMs count as started only once their
+	// console can be obtained.
+	verifyConsole bool
+
+	// skipStableWait makes power operations return without waiting for
+	// the VM to leave transient states such as Starting or Stopping.
+	skipStableWait bool
+
+	// sessions holds logged-on sessions for reuse by later operations.
+	sessions sessionPool
+
+	// platformArch, when set, is the architecture of every clone; the
+	// source's is not read.
+	platformArch vboxapi.PlatformArchitecture
+
+	// progressPoll sets how often long-running operations are polled.
+	progressPoll ProgressPollConfig
+
+	// checkFeatures makes operations check the features they use against
+	// the host's VirtualBox version, which is cached in version.
+	checkFeatures bool
+	versionMu     sync.Mutex
+	version       *apiVersion
 }
 
+// DefaultRegistrationWait is how long a NAT rule waits for its machine to be
+// registered unless SetRegistrationWait is called.
+const DefaultRegistrationWait = 10 * time.Second
+
 // NewClient creates a new VirtualBox client.
 func NewClient(endpoint, username, password string) *Client {
-	return &Client{endpoint: endpoint, username: username, password: password}
+	c := &Client{endpoint: endpoint, username: username, password: password, registrationWait: DefaultRegistrationWait, progressPoll: DefaultProgressPollConfig}
+	c.httpClient = c.transport.httpClient()
+	c.newAPI = func() vboxapi.VBoxAPI { return newAdapter(c.endpoint, c.httpClient) }
+	return c
+}
+
+// SetRegistrationWait sets how long NAT port forwarding rules wait for a
+// machine that VirtualBox does not find yet, e.g. one created in the same
+// apply whose registration lags. Zero fails immediately.
+func (c *Client) SetRegistrationWait(d time.Duration) {
+	c.registrationWait = d
+}
+
+// SetVerifyConsole sets whether starting a VM also waits, briefly, for its
+// console to be reachable. VirtualBox reports a VM Running as soon as its
+// process is up, which may be before console operations work.
+func (c *Client) SetVerifyConsole(verify bool) {
+	c.verifyConsole = verify
+}
+
+// SetWaitForStableState sets whether power operations wait, after they
+// complete, until the VM leaves transient states such as Starting or
+// Stopping, so that the state they return is a terminal one. It is on by
+// default.
+func (c *Client) SetWaitForStableState(wait bool) {
+	c.skipStableWait = !wait
+}
+
+// SetPlatformArchitecture sets the CPU architecture assumed for clones.
+// When set, the architecture of the source VM is not read, which saves two
+// SOAP calls per clone on hosts that only run one architecture. Empty
+// restores detection. It is also the default architecture of machines
+// created with CreateAndConverge.
+func (c *Client) SetPlatformArchitecture(arch vboxapi.PlatformArchitecture) {
+	c.platformArch = arch
+}
+
+// SetProgressPollConfig sets how often long-running operations, such as
+// clones and power changes, are polled for completion. Zero fields use the
+// values of DefaultProgressPollConfig.
+func (c *Client) SetProgressPollConfig(cfg ProgressPollConfig) {
+	c.progressPoll = cfg
 }
 
 // CloneRequest describes a VM clone operation.
@@ -29,9 +120,71 @@ type CloneRequest struct {
 	Source       string
 	CloneMode    string
 	CloneOptions []string
+	// ExtraCloneOptions are passed to CloneTo verbatim, after CloneOptions.
+	// They are not validated so that options added by newer VirtualBox
+	// releases can be used without a provider update.
+	ExtraCloneOptions []string
+	// OSTypeID, when set, is used for the new machine instead of the
+	// source's OS type, which is then not looked up. It must be a guest OS
+	// type VirtualBox knows.
+	OSTypeID string
+	// Settings are applied to the new machine before its power state is
+	// converged.
+	Settings     MachineSettings
 	DesiredState string // started|stopped
+	StopMethod   string // poweroff|savestate
+	ShutdownMode string // acpi|poweroff
 	SessionType  string // headless|gui
 	Timeout      time.Duration
+	// OperationTimeouts limits how long each operation of the clone, such
+	// as copying a disk, may take. Timeout still bounds the whole clone.
+	OperationTimeouts OperationTimeouts
+	// FixedDisks converts the dynamically allocated hard disks of the clone
+	// to fixed-size disks, and DiskFormat, when set, converts them to that
+	// format, e.g. DiskFormatVMDK. CloneTo keeps the allocation and format
+	// of the source's disks, so each disk to convert is copied again; this
+	// fails for linked clones.
+	FixedDisks bool
+	DiskFormat string
+}
+
+// DefaultOperationTimeout is the OperationTimeouts key whose timeout applies
+// to operations that match no other key.
+const DefaultOperationTimeout = "default"
+
+// OperationTimeouts maps parts of progress operation descriptions, e.g.
+// "hard disk", to the time the matching operations may take. Keys match
+// case-insensitively anywhere in the description; the longest matching key
+// wins. Operations that match no key use the DefaultOperationTimeout entry,
+// if any.
+type OperationTimeouts map[string]time.Duration
+
+// lookup returns the timeout of the operation with the given description,
+// or 0 if it has none.
+func (t OperationTimeouts) lookup(description string) time.Duration {
+	description = strings.ToLower(description)
+	var match string
+	for key := range t {
+		if key == DefaultOperationTimeout {
+			continue
+		}
+		if len(key) > len(match) && strings.Contains(description, strings.ToLower(key)) {
+			match = key
+		}
+	}
+	if match != "" {
+		return t[match]
+	}
+	return t[DefaultOperationTimeout]
+}
+
+// CloneResult is the outcome of CloneAndConverge and CreateAndConverge.
+type CloneResult struct {
+	ID    string
+	State string
+	// Warnings are advisory messages about the clone, e.g. source
+	// snapshots that the clone mode leaves out.
+	Warnings []string
 }
 
 var errNotFound = errors.New("not found")
@@ -43,34 +196,20 @@ func IsNotFound(err error) bool {
 
 // newAdapter creates a version-appropriate adapter.
 // Currently only supports VBox 7.1, but designed for future version support.
-func newAdapter(endpoint string) vboxapi.VBoxAPI {
+func newAdapter(endpoint string, httpClient *http.Client) vboxapi.VBoxAPI {
 	// TODO: In the future, could auto-detect version and return appropriate adapter
-	return vbox71.NewAdapter(endpoint)
+	return vbox71.NewAdapter(endpoint, httpClient)
 }
 
-func (c *Client) withSession(ctx context.Context, fn func(ctx context.Context, api vboxapi.VBoxAPI, session string) error) error {
-	api := newAdapter(c.endpoint)
-
-	session, err := api.Logon(ctx, c.username, c.password)
-	if err != nil {
-		return err
-	}
-
-	// Always try to logoff.
-	defer func() {
-		_ = api.Logoff(context.Background(), session)
-	}()
-
-	return fn(ctx, api, session)
-}
-
-// CloneAndConverge creates a new VM by cloning and sets its power state.
-func (c *Client) CloneAndConverge(ctx context.Context, req CloneRequest) (uuid string, currentState string, err error) {
+// CloneAndConverge creates a new VM by cloning and sets its power state. The
+// settings are checked before the VM is created; should a later step fail
+// once the VM is registered, the result holds its ID along with the error.
+func (c *Client) CloneAndConverge(ctx context.Context, req CloneRequest) (*CloneResult, error) {
 	if strings.TrimSpace(req.Name) == "" {
-		return "", "", fmt.Errorf("name is required")
+		return nil, fmt.Errorf("name is required")
 	}
 	if strings.TrimSpace(req.Source) == "" {
-		return "", "", fmt.Errorf("source is required")
+		return nil, fmt.Errorf("source is required")
 	}
 	if req.Timeout <= 0 {
 		req.Timeout = 20 * time.Minute
@@ -84,29 +223,63 @@ func (c *Client) CloneAndConverge(ctx context.Context, req CloneRequest) (uuid s
 	if req.DesiredState == "" {
 		req.DesiredState = "stopped"
 	}
+	if req.StopMethod == "" {
+		req.StopMethod = StopMethodPowerOff
+	}
+	switch req.DiskFormat {
+	case "", DiskFormatVDI, DiskFormatVMDK, DiskFormatVHD:
+	default:
+		return nil, fmt.Errorf("unsupported disk format %q: use %s, %s or %s", req.DiskFormat, DiskFormatVDI, DiskFormatVMDK, DiskFormatVHD)
+	}
 
-	err = c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+	var result CloneResult
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
 		srcRef, err := findMachine(ctx, api, session, req.Source)
 		if err != nil {
 			return err
 		}
 
-		// Get source osTypeId for the new machine
-		osTypeId, err := api.GetOSTypeId(ctx, srcRef)
-		if err != nil {
-			return fmt.Errorf("failed to get source OS type: %w", err)
+		if warning := cloneModeWarning(ctx, api, srcRef, req.Source, req.CloneMode); warning != "" {
+			result.Warnings = append(result.Warnings, warning)
 		}
 
-		targetRef, err := api.CreateMachine(ctx, session, req.Name, osTypeId, srcRef)
+		// Get source osTypeId for the new machine unless overridden
+		osTypeId := req.OSTypeID
+		if osTypeId == "" {
+			osTypeId, err = api.GetOSTypeId(ctx, srcRef)
+			if err != nil {
+				return fmt.Errorf("failed to get source OS type: %w", err)
+			}
+		} else if err := checkOSType(ctx, api, session, osTypeId); err != nil {
+			return err
+		}
+
+		arch := c.cloneArchitecture(ctx, api, srcRef)
+		if arch == vboxapi.PlatformArchitectureARM {
+			if err := c.requireFeature(ctx, api, session, FeatureARM); err != nil {
+				return err
+			}
+		}
+
+		// The clone has the settings of its source, so the settings are
+		// checked against the source before anything is created.
+		if !req.Settings.Empty() {
+			if _, err := checkMachineSettings(ctx, api, session, srcRef, vboxapi.MachineStatePoweredOff, req.Settings); err != nil {
+				return err
+			}
+		}
+
+		targetRef, err := api.CreateMachine(ctx, session, req.Name, osTypeId, arch)
 		if err != nil {
 			return err
 		}
 
-		progressRef, err := api.CloneTo(ctx, srcRef, targetRef, req.CloneMode, req.CloneOptions)
+		options := append(append([]string{}, req.CloneOptions...), req.ExtraCloneOptions...)
+		progressRef, err := api.CloneTo(ctx, srcRef, targetRef, req.CloneMode, options)
 		if err != nil {
 			return err
 		}
-		if err := waitProgress(ctx, api, progressRef, req.Timeout); err != nil {
+		if err := c.waitProgressOperations(ctx, api, progressRef, req.Timeout, req.OperationTimeouts); err != nil {
 			return err
 		}
 
@@ -114,20 +287,163 @@ func (c *Client) CloneAndConverge(ctx context.Context, req CloneRequest) (uuid s
 			return err
 		}
 
-		uuid, err = api.GetMachineId(ctx, targetRef)
+		result.ID, err = api.GetMachineId(ctx, targetRef)
 		if err != nil {
 			return err
 		}
 
+		if req.FixedDisks || req.DiskFormat != "" {
+			conv := diskConversion{Format: req.DiskFormat, Fixed: req.FixedDisks}
+			if err := c.convertDisks(ctx, api, session, targetRef, conv, req.Timeout); err != nil {
+				return err
+			}
+		}
+
+		if err := applyMachineSettings(ctx, api, session, targetRef, req.Settings); err != nil {
+			return err
+		}
+
 		// Converge state
-		currentState, err = convergeState(ctx, api, session, targetRef, req.DesiredState, req.SessionType, req.Timeout)
+		result.State, err = c.convergeState(ctx, api, session, targetRef, req.DesiredState, req.StopMethod, req.ShutdownMode, req.SessionType, req.Timeout)
 		if err != nil {
 			return err
 		}
 		return nil
 	})
+	return registeredResult(&result, err)
+}
 
-	return uuid, currentState, err
+// CreateRequest describes a VM created from scratch, rather than cloned.
+type CreateRequest struct {
+	Name string
+	// OSTypeID is the guest OS type, e.g. Ubuntu_64. The new machine gets
+	// the settings VirtualBox recommends for it, such as its memory size,
+	// storage controllers and a NAT network adapter.
+	OSTypeID string
+	// PlatformArchitecture is the CPU architecture of the new machine.
+	// When empty, the client's architecture, see SetPlatformArchitecture,
+	// or x86 is used.
+	PlatformArchitecture vboxapi.PlatformArchitecture
+	// MemoryMB and CPUCount override the recommended memory size and CPU
+	// count when not 0.
+	MemoryMB uint32
+	CPUCount uint32
+	// Settings are applied to the new machine before its power state is
+	// converged.
+	Settings     MachineSettings
+	DesiredState string // started|stopped
+	StopMethod   string // poweroff|savestate
+	ShutdownMode string // acpi|poweroff
+	SessionType  string // headless|gui
+	Timeout      time.Duration
+}
+
+// CreateAndConverge creates and registers a new, empty VM and sets its power
+// state. The VM has no disk: attach storage with CreateStorageController and
+// AttachStorage. It is deleted like a cloned VM, with DeleteByID. Like
+// CloneAndConverge, it returns the VM's ID along with an error that occurs
+// once the VM is registered.
+func (c *Client) CreateAndConverge(ctx context.Context, req CreateRequest) (*CloneResult, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if strings.TrimSpace(req.OSTypeID) == "" {
+		return nil, fmt.Errorf("OS type is required to create a VM from scratch")
+	}
+	if req.Timeout <= 0 {
+		req.Timeout = 20 * time.Minute
+	}
+	if req.SessionType == "" {
+		req.SessionType = "headless"
+	}
+	if req.DesiredState == "" {
+		req.DesiredState = "stopped"
+	}
+	if req.StopMethod == "" {
+		req.StopMethod = StopMethodPowerOff
+	}
+	arch := req.PlatformArchitecture
+	if arch == "" {
+		arch = c.platformArch
+	}
+
+	var result CloneResult
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		if arch == vboxapi.PlatformArchitectureARM {
+			if err := c.requireFeature(ctx, api, session, FeatureARM); err != nil {
+				return err
+			}
+		}
+
+		// The new machine is not registered yet, so it is changed without
+		// a lock; its settings are written by SaveSettings.
+		machineRef, err := api.CreateMachine(ctx, session, req.Name, req.OSTypeID, arch)
+		if err != nil {
+			return err
+		}
+		if err := api.ApplyDefaults(ctx, machineRef); err != nil {
+			return fmt.Errorf("failed to apply the defaults of OS type %s: %w", req.OSTypeID, err)
+		}
+		if req.MemoryMB != 0 {
+			if err := api.SetMemorySize(ctx, machineRef, req.MemoryMB); err != nil {
+				return fmt.Errorf("failed to set memory size to %d MB: %w", req.MemoryMB, err)
+			}
+		}
+		if req.CPUCount != 0 {
+			if err := api.SetCPUCount(ctx, machineRef, req.CPUCount); err != nil {
+				return fmt.Errorf("failed to set CPU count to %d: %w", req.CPUCount, err)
+			}
+		}
+		if !req.Settings.Empty() {
+			if _, err := checkMachineSettings(ctx, api, session, machineRef, vboxapi.MachineStatePoweredOff, req.Settings); err != nil {
+				return err
+			}
+		}
+		if err := api.SaveSettings(ctx, machineRef); err != nil {
+			return fmt.Errorf("failed to save machine settings: %w", err)
+		}
+		if err := api.RegisterMachine(ctx, session, machineRef); err != nil {
+			return err
+		}
+
+		result.ID, err = api.GetMachineId(ctx, machineRef)
+		if err != nil {
+			return err
+		}
+
+		if err := applyMachineSettings(ctx, api, session, machineRef, req.Settings); err != nil {
+			return err
+		}
+
+		result.State, err = c.convergeState(ctx, api, session, machineRef, req.DesiredState, req.StopMethod, req.ShutdownMode, req.SessionType, req.Timeout)
+		return err
+	})
+	return registeredResult(&result, err)
+}
+
+// registeredResult returns the outcome of CloneAndConverge or
+// CreateAndConverge. Once the VM is registered, its ID is returned along
+// with any later error, so that the caller can keep track of the VM rather
+// than orphan it.
+func registeredResult(result *CloneResult, err error) (*CloneResult, error) {
+	if err != nil && result.ID == "" {
+		return nil, err
+	}
+	return result, err
+}
+
+// cloneModeWarning returns an advisory message when the source has
+// snapshots that mode does not clone. It is best-effort and returns an
+// empty string if the snapshot count cannot be read.
+func cloneModeWarning(ctx context.Context, api vboxapi.VBoxAPI, srcRef, source, mode string) string {
+	if mode != "MachineState" {
+		return ""
+	}
+	count, err := api.GetSnapshotCount(ctx, srcRef)
+	if err != nil || count == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Source %q has %d snapshot(s), but clone mode MachineState only clones its current state; the snapshots are not copied. Use clone_mode = \"AllStates\" to clone the whole snapshot tree.", source, count)
 }
 
 // MachineInfo contains basic information about a VirtualBox machine.
@@ -135,33 +451,122 @@ type MachineInfo struct {
 	ID    string
 	Name  string
 	State string
+	// OSTypeID is the guest OS type, e.g. Ubuntu_64.
+	OSTypeID string
+	// CurrentSnapshot is the name of the current snapshot, or "" when the
+	// VM has no snapshots.
+	CurrentSnapshot string
+	// Owner is the VM's owner, see OwnerExtraDataKey. Only ListMachines
+	// sets it.
+	Owner string
+	// Accessible is false when VirtualBox could not load the VM's settings,
+	// e.g. because its files were moved or deleted. Only ID and AccessError
+	// are set then.
+	Accessible  bool
+	AccessError string
 }
 
-// GetMachineInfoByID returns basic information about a VM by its UUID.
+// cloneArchitecture returns the architecture to create a clone of srcRef
+// with: the configured one, else the source's, else x86.
+func (c *Client) cloneArchitecture(ctx context.Context, api vboxapi.VBoxAPI, srcRef string) vboxapi.PlatformArchitecture {
+	if c.platformArch != "" {
+		return c.platformArch
+	}
+	arch, err := api.GetPlatformArchitecture(ctx, srcRef)
+	if err != nil {
+		// Default to x86 if we can't determine
+		return vboxapi.PlatformArchitectureX86
+	}
+	return arch
+}
+
+// GetMachineInfoByID returns basic information about a VM by its UUID. An
+// inaccessible VM is not an error: it is reported through Accessible and
+// AccessError.
 func (c *Client) GetMachineInfoByID(ctx context.Context, id string) (*MachineInfo, error) {
-	var info MachineInfo
+	var info *MachineInfo
 	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
 		mRef, err := findMachine(ctx, api, session, id)
 		if err != nil {
 			return err
 		}
-		info.ID, err = api.GetMachineId(ctx, mRef)
-		if err != nil {
-			return err
-		}
-		info.Name, err = api.GetMachineName(ctx, mRef)
+		info, err = readMachineInfo(ctx, api, mRef)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// ListMachines returns basic information about every registered VM,
+// including its owner, ordered by name. Inaccessible VMs are listed with
+// only ID and AccessError set.
+func (c *Client) ListMachines(ctx context.Context) ([]MachineInfo, error) {
+	var out []MachineInfo
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRefs, err := api.GetMachines(ctx, session)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to enumerate machines: %w", err)
 		}
-		info.State, err = api.GetMachineState(ctx, mRef)
-		if err != nil {
-			return err
+		for _, mRef := range machineRefs {
+			info, err := readMachineInfo(ctx, api, mRef)
+			if err != nil {
+				return err
+			}
+			if info.Accessible {
+				if info.Owner, err = api.GetExtraData(ctx, mRef, OwnerExtraDataKey); err != nil {
+					return fmt.Errorf("failed to get owner of machine %s: %w", info.ID, err)
+				}
+			}
+			out = append(out, *info)
 		}
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
+	slices.SortFunc(out, func(a, b MachineInfo) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return out, nil
+}
+
+// readMachineInfo returns basic information about the VM mRef. An
+// inaccessible VM is not an error: only its ID and AccessError are set.
+func readMachineInfo(ctx context.Context, api vboxapi.VBoxAPI, mRef string) (*MachineInfo, error) {
+	var info MachineInfo
+	var err error
+	info.ID, err = api.GetMachineId(ctx, mRef)
+	if err != nil {
+		return nil, err
+	}
+	info.Accessible, err = api.GetMachineAccessible(ctx, mRef)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Accessible {
+		if info.AccessError, err = api.GetMachineAccessError(ctx, mRef); err != nil {
+			return nil, err
+		}
+		return &info, nil
+	}
+	info.Name, err = api.GetMachineName(ctx, mRef)
+	if err != nil {
+		return nil, err
+	}
+	info.State, err = api.GetMachineState(ctx, mRef)
+	if err != nil {
+		return nil, err
+	}
+	info.OSTypeID, err = api.GetOSTypeId(ctx, mRef)
+	if err != nil {
+		return nil, err
+	}
+	info.CurrentSnapshot, err = currentSnapshotName(ctx, api, mRef)
+	if err != nil {
+		return nil, err
+	}
 	return &info, nil
 }
 
@@ -184,7 +589,7 @@ func (c *Client) GetStateByID(ctx context.Context, id string) (string, error) {
 }
 
 // ConvergeStateByID changes a VM's power state.
-func (c *Client) ConvergeStateByID(ctx context.Context, id, desiredState, sessionType string, timeout time.Duration) (string, error) {
+func (c *Client) ConvergeStateByID(ctx context.Context, id, desiredState, stopMethod, shutdownMode, sessionType string, timeout time.Duration) (string, error) {
 	var out string
 	if timeout <= 0 {
 		timeout = 20 * time.Minute
@@ -192,8 +597,11 @@ func (c *Client) ConvergeStateByID(ctx context.Context, id, desiredState, sessio
 	if sessionType == "" {
 		sessionType = "headless"
 	}
+	if stopMethod == "" {
+		stopMethod = StopMethodPowerOff
+	}
 	desiredState = strings.ToLower(strings.TrimSpace(desiredState))
-	if desiredState != "started" && desiredState != "stopped" {
+	if !validDesiredState(desiredState) {
 		return "", fmt.Errorf("invalid desired state: %s", desiredState)
 	}
 
@@ -202,14 +610,84 @@ func (c *Client) ConvergeStateByID(ctx context.Context, id, desiredState, sessio
 		if err != nil {
 			return err
 		}
-		out, err = convergeState(ctx, api, session, mRef, desiredState, sessionType, timeout)
+		out, err = c.convergeState(ctx, api, session, mRef, desiredState, stopMethod, shutdownMode, sessionType, timeout)
 		return err
 	})
 	return out, err
 }
 
-// DeleteByID deletes a VM by its UUID.
-func (c *Client) DeleteByID(ctx context.Context, id string, timeout time.Duration) error {
+// ConvergeStatesByID changes the power state of several VMs, one after the
+// other, in a single session. It stops at the first failure and returns the
+// states of the VMs converged so far, in the order of ids.
+func (c *Client) ConvergeStatesByID(ctx context.Context, ids []string, desiredState, stopMethod, shutdownMode, sessionType string, timeout time.Duration) ([]string, error) {
+	if timeout <= 0 {
+		timeout = 20 * time.Minute
+	}
+	if sessionType == "" {
+		sessionType = "headless"
+	}
+	if stopMethod == "" {
+		stopMethod = StopMethodPowerOff
+	}
+	desiredState = strings.ToLower(strings.TrimSpace(desiredState))
+	if !validDesiredState(desiredState) {
+		return nil, fmt.Errorf("invalid desired state: %s", desiredState)
+	}
+
+	var out []string
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		for _, id := range ids {
+			mRef, err := findMachine(ctx, api, session, id)
+			if err != nil {
+				return err
+			}
+			st, err := c.convergeState(ctx, api, session, mRef, desiredState, stopMethod, shutdownMode, sessionType, timeout)
+			if err != nil {
+				return fmt.Errorf("machine %s: %w", id, err)
+			}
+			out = append(out, st)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// RollingPowerCycle powers off and restarts the running VMs among ids,
+// batchSize at a time, so that the others stay available. A batch is
+// stopped, then started again, before the next one is stopped. VMs that
+// are not running are left alone. It stops at the first batch that fails,
+// leaving the remaining VMs untouched.
+func (c *Client) RollingPowerCycle(ctx context.Context, ids []string, batchSize int, sessionType string, timeout time.Duration) error {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	var running []string
+	for _, id := range ids {
+		st, err := c.GetStateByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("machine %s: %w", id, err)
+		}
+		if st == vboxapi.MachineStateRunning {
+			running = append(running, id)
+		}
+	}
+
+	for batch := range slices.Chunk(running, batchSize) {
+		if _, err := c.ConvergeStatesByID(ctx, batch, "stopped", StopMethodPowerOff, ShutdownModePowerOff, sessionType, timeout); err != nil {
+			return fmt.Errorf("failed to stop %s: %w", strings.Join(batch, ", "), err)
+		}
+		if _, err := c.ConvergeStatesByID(ctx, batch, "started", "", "", sessionType, timeout); err != nil {
+			return fmt.Errorf("failed to start %s: %w", strings.Join(batch, ", "), err)
+		}
+	}
+	return nil
+}
+
+// DeleteByID deletes a VM by its UUID. Unless force is set, it refuses to
+// delete a VM whose disks back the disks of other registered machines, such
+// as linked clones, since deleting them would break those machines.
+func (c *Client) DeleteByID(ctx context.Context, id string, timeout time.Duration, force bool) error {
 	if timeout <= 0 {
 		timeout = 20 * time.Minute
 	}
@@ -220,8 +698,24 @@ func (c *Client) DeleteByID(ctx context.Context, id string, timeout time.Duratio
 			return err
 		}
 
-		// Ensure powered off (best-effort).
-		_ = ensurePoweredOff(ctx, api, session, mRef, timeout)
+		if !force {
+			dependents, err := dependentMachines(ctx, api, session, mRef, id)
+			if err != nil {
+				return fmt.Errorf("failed to check for machines using the disks of %s: %w", id, err)
+			}
+			if len(dependents) > 0 {
+				return fmt.Errorf("machine %s cannot be deleted: its disks are used by other machines (%s); delete those first or set force_delete = true",
+					id, strings.Join(dependents, ", "))
+			}
+		}
+
+		// Ensure powered off (best-effort). A saved VM has no running
+		// process to power down; drop its saved state instead.
+		if st, err := api.GetMachineState(ctx, mRef); err == nil && st == vboxapi.MachineStateSaved {
+			_ = discardSavedState(ctx, api, session, mRef)
+		} else {
+			_ = c.ensurePoweredOff(ctx, api, session, mRef, timeout)
+		}
 
 		mediaRefs, err := api.UnregisterMachine(ctx, mRef)
 		if err != nil {
@@ -232,7 +726,7 @@ func (c *Client) DeleteByID(ctx context.Context, id string, timeout time.Duratio
 		if err != nil {
 			return err
 		}
-		if err := waitProgress(ctx, api, progressRef, timeout); err != nil {
+		if err := c.waitProgress(ctx, api, progressRef, timeout); err != nil {
 			return err
 		}
 
@@ -242,12 +736,27 @@ func (c *Client) DeleteByID(ctx context.Context, id string, timeout time.Duratio
 
 // ---- helpers ----
 
+// currentSnapshotName returns the name of the current snapshot of a machine,
+// or "" when it has no snapshots.
+func currentSnapshotName(ctx context.Context, api vboxapi.VBoxAPI, machineRef string) (string, error) {
+	snapshotRef, err := api.GetCurrentSnapshot(ctx, machineRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to get current snapshot: %w", err)
+	}
+	if snapshotRef == "" {
+		return "", nil
+	}
+	name, err := api.GetSnapshotName(ctx, snapshotRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to get snapshot name: %w", err)
+	}
+	return name, nil
+}
+
 func findMachine(ctx context.Context, api vboxapi.VBoxAPI, session, nameOrID string) (string, error) {
 	machineRef, err := api.FindMachine(ctx, session, nameOrID)
 	if err != nil {
-		// Best-effort mapping to not found.
-		errLower := strings.ToLower(err.Error())
-		if strings.Contains(errLower, "could not find") || strings.Contains(errLower, "object not found") {
+		if vboxapi.IsObjectNotFound(err) {
 			return "", fmt.Errorf("%w: machine %s", errNotFound, nameOrID)
 		}
 		return "", err
@@ -258,19 +767,54 @@ func findMachine(ctx context.Context, api vboxapi.VBoxAPI, session, nameOrID str
 	return machineRef, nil
 }
 
-func waitProgress(ctx context.Context, api vboxapi.VBoxAPI, progressRef string, timeout time.Duration) error {
+// registrationPollInterval is how often findRegisteredMachine looks the
+// machine up again.
+var registrationPollInterval = time.Second
+
+// findRegisteredMachine is findMachine, retried for up to wait while the
+// machine is not found. It absorbs the lag between a machine being created
+// and VirtualBox finding it by ID.
+func findRegisteredMachine(ctx context.Context, api vboxapi.VBoxAPI, session, nameOrID string, wait time.Duration) (string, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		machineRef, err := findMachine(ctx, api, session, nameOrID)
+		if err == nil || !IsNotFound(err) || time.Now().Add(registrationPollInterval).After(deadline) {
+			return machineRef, err
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(registrationPollInterval):
+		}
+	}
+}
+
+func (c *Client) waitProgress(ctx context.Context, api vboxapi.VBoxAPI, progressRef string, timeout time.Duration) error {
+	return c.waitProgressOperations(ctx, api, progressRef, timeout, nil)
+}
+
+// waitProgressOperations waits for a progress like waitProgress, and also
+// fails when one of its operations exceeds its entry in opTimeouts. An
+// operation starts when the operation description changes.
+func (c *Client) waitProgressOperations(ctx context.Context, api vboxapi.VBoxAPI, progressRef string, timeout time.Duration, opTimeouts OperationTimeouts) error {
 	if timeout <= 0 {
 		timeout = 20 * time.Minute
 	}
 	deadline := time.Now().Add(timeout)
-	pollInterval := 2 * time.Second
+	interval := c.progressPoll.initial()
+
+	var (
+		operation string
+		opStarted time.Time
+		opTimeout time.Duration
+		opTracked bool
+	)
 
 	for {
 		// Check if context is cancelled
-		select {
-		case <-ctx.Done():
+		if ctx.Err() != nil {
+			cancelProgress(api, progressRef)
 			return ctx.Err()
-		default:
 		}
 
 		// Check if we've exceeded deadline
@@ -293,44 +837,293 @@ func waitProgress(ctx context.Context, api vboxapi.VBoxAPI, progressRef string,
 			if rc != 0 {
 				// Try to fetch an error message.
 				errText, _ := api.GetProgressErrorText(ctx, progressRef)
-				if errText != "" {
-					return fmt.Errorf("progress failed (resultCode=%d): %s", rc, errText)
-				}
-				return fmt.Errorf("progress failed (resultCode=%d)", rc)
+				return fmt.Errorf("progress failed: %w", &vboxapi.Error{
+					Interface:  "IProgress",
+					ResultCode: rc,
+					Text:       errText,
+				})
 			}
 			return nil
 		}
 
-		// Not completed yet, wait and poll again
-		time.Sleep(pollInterval)
+		// Best-effort: keep waiting, and timing the current operation, if
+		// the description or percentage can't be read.
+		desc, err := api.GetProgressOperationDescription(ctx, progressRef)
+		if err == nil {
+			if percent, err := api.GetProgressPercent(ctx, progressRef); err == nil {
+				tflog.Info(ctx, fmt.Sprintf("%s: %d%%", desc, percent), map[string]interface{}{
+					"progress":  progressRef,
+					"operation": desc,
+					"percent":   percent,
+				})
+			}
+		}
+		if len(opTimeouts) > 0 {
+			if err == nil && (!opTracked || desc != operation) {
+				operation, opStarted, opTimeout, opTracked = desc, time.Now(), opTimeouts.lookup(desc), true
+			}
+			if opTimeout > 0 && time.Since(opStarted) > opTimeout {
+				return fmt.Errorf("timeout waiting for progress operation %q after %v", operation, opTimeout)
+			}
+		}
+
+		// Not completed yet, wait and poll again. The wait ends at the
+		// deadline, so that a timeout is reported on time.
+		select {
+		case <-ctx.Done():
+			cancelProgress(api, progressRef)
+			return ctx.Err()
+		case <-time.After(min(interval, max(time.Until(deadline), 0))):
+		}
+		interval = c.progressPoll.next(interval)
+	}
+}
+
+// cancelProgressTimeout bounds the CancelProgress call made when waiting
+// for a progress is interrupted.
+const cancelProgressTimeout = 10 * time.Second
+
+// cancelProgress asks VirtualBox to abort a progress, best-effort, so that
+// an interrupted clone or power operation does not carry on in the
+// background. It does not use the waiting context, which is canceled.
+func cancelProgress(api vboxapi.VBoxAPI, progressRef string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelProgressTimeout)
+	defer cancel()
+	_ = api.CancelProgress(ctx, progressRef)
+}
+
+// Stop methods used when converging a VM to the stopped state.
+const (
+	StopMethodPowerOff  = "poweroff"
+	StopMethodSaveState = "savestate"
+)
+
+// validDesiredState reports whether s is a state that VMs can be converged
+// to: started, stopped, paused or saved.
+func validDesiredState(s string) bool {
+	switch s {
+	case "started", "stopped", "paused", "saved":
+		return true
+	}
+	return false
+}
+
+// NormalizeMachineState returns the desired state that a VM in VirtualBox
+// state st is in: started, paused, saved or stopped. Transient states, such
+// as Starting or Saving, and Stuck return "": the VM is in none of them.
+func NormalizeMachineState(st string) string {
+	switch st {
+	case vboxapi.MachineStateRunning:
+		return "started"
+	case vboxapi.MachineStatePaused:
+		return "paused"
+	case vboxapi.MachineStateSaved, vboxapi.MachineStateAbortedSaved:
+		return "saved"
+	case vboxapi.MachineStatePoweredOff, vboxapi.MachineStateAborted, vboxapi.MachineStateTeleported:
+		return "stopped"
+	}
+	return ""
+}
+
+// IsTransientState reports whether st is a state a VM only passes through
+// while it changes state, such as Starting, Saving or RestoringSnapshot.
+func IsTransientState(st string) bool {
+	switch st {
+	case vboxapi.MachineStateTeleporting,
+		vboxapi.MachineStateLiveSnapshotting,
+		vboxapi.MachineStateStarting,
+		vboxapi.MachineStateStopping,
+		vboxapi.MachineStateSaving,
+		vboxapi.MachineStateRestoring,
+		vboxapi.MachineStateTeleportingPausedVM,
+		vboxapi.MachineStateTeleportingIn,
+		vboxapi.MachineStateDeletingSnapshotOnline,
+		vboxapi.MachineStateDeletingSnapshotPaused,
+		vboxapi.MachineStateOnlineSnapshotting,
+		vboxapi.MachineStateRestoringSnapshot,
+		vboxapi.MachineStateDeletingSnapshot,
+		vboxapi.MachineStateSettingUp,
+		vboxapi.MachineStateSnapshotting:
+		return true
+	}
+	return false
+}
+
+// waitStableState waits for up to timeout until a VM is no longer in a
+// transient state. VirtualBox may complete the progress of a power
+// operation while the VM is still, e.g., Stopping. It returns immediately
+// when the client does not wait for stable states.
+func (c *Client) waitStableState(ctx context.Context, api vboxapi.VBoxAPI, machineRef string, timeout time.Duration) error {
+	if c.skipStableWait {
+		return nil
+	}
+	if timeout <= 0 {
+		timeout = 20 * time.Minute
+	}
+	deadline := time.Now().Add(timeout)
+	interval := c.progressPoll.initial()
+	for {
+		st, err := api.GetMachineState(ctx, machineRef)
+		if err != nil {
+			return err
+		}
+		if !IsTransientState(st) {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("timed out after %s waiting for the VM to leave state %s", timeout, st)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(min(interval, time.Until(deadline))):
+		}
+		interval = c.progressPoll.next(interval)
+	}
+}
+
+// Shutdown modes used when the poweroff stop method powers a VM off.
+// ShutdownModeACPI presses the virtual power button so that the guest OS
+// shuts down cleanly, and falls back to ShutdownModePowerOff, which cuts
+// the power, if the VM is still running when the timeout elapses.
+const (
+	ShutdownModeACPI     = "acpi"
+	ShutdownModePowerOff = "poweroff"
+)
+
+// convergeState brings a VM to desiredState like changeState and, when the
+// client verifies consoles, checks the console of a VM it leaves running.
+func (c *Client) convergeState(ctx context.Context, api vboxapi.VBoxAPI, vboxSession string, machineRef, desiredState, stopMethod, shutdownMode, sessionType string, timeout time.Duration) (string, error) {
+	st, err := c.changeState(ctx, api, vboxSession, machineRef, desiredState, stopMethod, shutdownMode, sessionType, timeout)
+	if err != nil || !c.verifyConsole || st != vboxapi.MachineStateRunning {
+		return st, err
 	}
+	if err := waitConsoleReady(ctx, api, vboxSession, machineRef); err != nil {
+		return "", err
+	}
+	return st, nil
 }
 
-func convergeState(ctx context.Context, api vboxapi.VBoxAPI, vboxSession string, machineRef, desiredState, sessionType string, timeout time.Duration) (string, error) {
+// consoleReadyAttempts is how many times waitConsoleReady tries to get the
+// console, consoleReadyPollInterval apart.
+const consoleReadyAttempts = 5
+
+var consoleReadyPollInterval = time.Second
+
+// waitConsoleReady checks that the console of a running VM can be obtained,
+// retrying briefly while the VM finishes starting.
+func waitConsoleReady(ctx context.Context, api vboxapi.VBoxAPI, vboxSession, machineRef string) error {
+	sessObj, err := api.GetSessionObject(ctx, vboxSession)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = consoleReachable(ctx, api, machineRef, sessObj)
+		if err == nil {
+			return nil
+		}
+		if attempt == consoleReadyAttempts {
+			return fmt.Errorf("the VM is running but its console is not reachable: %w", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(consoleReadyPollInterval):
+		}
+	}
+}
+
+// consoleReachable gets the console of a running VM through a shared lock.
+func consoleReachable(ctx context.Context, api vboxapi.VBoxAPI, machineRef, sessObj string) error {
+	if err := api.LockMachine(ctx, machineRef, sessObj, true); err != nil {
+		return err
+	}
+	defer unlockSession(ctx, api, sessObj)
+
+	_, err := api.GetConsole(ctx, sessObj)
+	return err
+}
+
+// changeState brings a VM to desiredState. With the savestate stop method,
+// a Saved VM counts as stopped. Paused and saved VMs that are powered off
+// are started first, since only a running VM can be paused or saved.
+func (c *Client) changeState(ctx context.Context, api vboxapi.VBoxAPI, vboxSession string, machineRef, desiredState, stopMethod, shutdownMode, sessionType string, timeout time.Duration) (string, error) {
 	st, err := api.GetMachineState(ctx, machineRef)
 	if err != nil {
 		return "", err
 	}
 
-	want := strings.ToLower(desiredState)
-	if want == "started" {
-		if st == vboxapi.MachineStateRunning {
+	switch strings.ToLower(desiredState) {
+	case "started":
+		switch st {
+		case vboxapi.MachineStateRunning:
 			return st, nil
+		case vboxapi.MachineStatePaused:
+			if err := withConsole(ctx, api, vboxSession, machineRef, api.Resume); err != nil {
+				return "", fmt.Errorf("failed to resume the VM: %w", err)
+			}
+		default:
+			if err := c.ensureRunning(ctx, api, vboxSession, machineRef, sessionType, timeout); err != nil {
+				return "", startFailureHint(ctx, api, machineRef, err)
+			}
 		}
-		if err := ensureRunning(ctx, api, vboxSession, machineRef, sessionType, timeout); err != nil {
+	case "paused":
+		if st == vboxapi.MachineStatePaused {
+			return st, nil
+		}
+		if st != vboxapi.MachineStateRunning {
+			if err := c.ensureRunning(ctx, api, vboxSession, machineRef, sessionType, timeout); err != nil {
+				return "", startFailureHint(ctx, api, machineRef, err)
+			}
+		}
+		if err := withConsole(ctx, api, vboxSession, machineRef, api.Pause); err != nil {
+			return "", fmt.Errorf("failed to pause the VM: %w", err)
+		}
+	case "saved":
+		if st == vboxapi.MachineStateSaved {
+			return st, nil
+		}
+		if !isMachineOnline(st) {
+			if err := c.ensureRunning(ctx, api, vboxSession, machineRef, sessionType, timeout); err != nil {
+				return "", startFailureHint(ctx, api, machineRef, err)
+			}
+		}
+		if err := c.ensureSaved(ctx, api, vboxSession, machineRef, timeout); err != nil {
 			return "", err
 		}
-	} else if want == "stopped" {
+	case "stopped":
 		if st == vboxapi.MachineStatePoweredOff {
 			return st, nil
 		}
-		if err := ensurePoweredOff(ctx, api, vboxSession, machineRef, timeout); err != nil {
-			return "", err
+		switch {
+		case stopMethod == StopMethodSaveState && st == vboxapi.MachineStateSaved:
+			return st, nil
+		case stopMethod == StopMethodSaveState:
+			if err := c.ensureSaved(ctx, api, vboxSession, machineRef, timeout); err != nil {
+				return "", err
+			}
+		case st == vboxapi.MachineStateSaved:
+			if err := discardSavedState(ctx, api, vboxSession, machineRef); err != nil {
+				return "", err
+			}
+		case shutdownMode == ShutdownModeACPI && st == vboxapi.MachineStateRunning:
+			// A paused guest cannot handle the power button.
+			if err := c.ensureShutDown(ctx, api, vboxSession, machineRef, timeout); err != nil {
+				return "", err
+			}
+		default:
+			if err := c.ensurePoweredOff(ctx, api, vboxSession, machineRef, timeout); err != nil {
+				return "", err
+			}
 		}
-	} else {
+	default:
 		return "", fmt.Errorf("invalid desired state: %s", desiredState)
 	}
 
+	if err := c.waitStableState(ctx, api, machineRef, timeout); err != nil {
+		return "", err
+	}
 	st, err = api.GetMachineState(ctx, machineRef)
 	if err != nil {
 		return "", err
@@ -338,7 +1131,7 @@ func convergeState(ctx context.Context, api vboxapi.VBoxAPI, vboxSession string,
 	return st, nil
 }
 
-func ensureRunning(ctx context.Context, api vboxapi.VBoxAPI, vboxSession, machineRef, sessionType string, timeout time.Duration) error {
+func (c *Client) ensureRunning(ctx context.Context, api vboxapi.VBoxAPI, vboxSession, machineRef, sessionType string, timeout time.Duration) error {
 	sessObj, err := api.GetSessionObject(ctx, vboxSession)
 	if err != nil {
 		return err
@@ -349,16 +1142,16 @@ func ensureRunning(ctx context.Context, api vboxapi.VBoxAPI, vboxSession, machin
 		return err
 	}
 
-	if err := waitProgress(ctx, api, progressRef, timeout); err != nil {
+	if err := c.waitProgress(ctx, api, progressRef, timeout); err != nil {
 		return err
 	}
 
 	// Always unlock.
-	_ = api.UnlockSession(context.Background(), sessObj)
-	return nil
+	unlockSession(ctx, api, sessObj)
+	return c.waitStableState(ctx, api, machineRef, timeout)
 }
 
-func ensurePoweredOff(ctx context.Context, api vboxapi.VBoxAPI, vboxSession, machineRef string, timeout time.Duration) error {
+func (c *Client) ensurePoweredOff(ctx context.Context, api vboxapi.VBoxAPI, vboxSession, machineRef string, timeout time.Duration) error {
 	sessObj, err := api.GetSessionObject(ctx, vboxSession)
 	if err != nil {
 		return err
@@ -380,11 +1173,129 @@ func ensurePoweredOff(ctx context.Context, api vboxapi.VBoxAPI, vboxSession, mac
 		return err
 	}
 
-	if err := waitProgress(ctx, api, progressRef, timeout); err != nil {
+	if err := c.waitProgress(ctx, api, progressRef, timeout); err != nil {
+		return err
+	}
+
+	unlockSession(ctx, api, sessObj)
+	return c.waitStableState(ctx, api, machineRef, timeout)
+}
+
+// ensureShutDown presses the power button of a running VM and waits up to
+// timeout for the guest OS to shut down. A VM still running afterwards,
+// e.g. because its guest ignores ACPI events, is powered off.
+func (c *Client) ensureShutDown(ctx context.Context, api vboxapi.VBoxAPI, vboxSession, machineRef string, timeout time.Duration) error {
+	if err := pressPowerButton(ctx, api, vboxSession, machineRef); err != nil {
+		return fmt.Errorf("failed to press the power button: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	interval := c.progressPoll.initial()
+	for {
+		st, err := api.GetMachineState(ctx, machineRef)
+		if err != nil {
+			return err
+		}
+		if st == vboxapi.MachineStatePoweredOff {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(min(interval, time.Until(deadline))):
+		}
+		interval = c.progressPoll.next(interval)
+	}
+
+	tflog.Warn(ctx, "The guest did not shut down after the power button was pressed; powering the VM off", map[string]interface{}{
+		"timeout": timeout.String(),
+	})
+	return c.ensurePoweredOff(ctx, api, vboxSession, machineRef, timeout)
+}
+
+// pressPowerButton sends an ACPI power button press to a running VM.
+func pressPowerButton(ctx context.Context, api vboxapi.VBoxAPI, vboxSession, machineRef string) error {
+	return withConsole(ctx, api, vboxSession, machineRef, api.PowerButton)
+}
+
+// withConsole calls fn with the console of a running VM, through a shared
+// lock that is released afterwards.
+func withConsole(ctx context.Context, api vboxapi.VBoxAPI, vboxSession, machineRef string, fn func(ctx context.Context, consoleRef string) error) error {
+	sessObj, err := api.GetSessionObject(ctx, vboxSession)
+	if err != nil {
+		return err
+	}
+	if err := api.LockMachine(ctx, machineRef, sessObj, true); err != nil {
+		return err
+	}
+	defer unlockSession(ctx, api, sessObj)
+
+	consoleRef, err := api.GetConsole(ctx, sessObj)
+	if err != nil {
 		return err
 	}
+	return fn(ctx, consoleRef)
+}
 
-	_ = api.UnlockSession(context.Background(), sessObj)
+// ensureSaved saves the state of a running VM to disk and stops it. The VM
+// resumes from that state on its next start.
+func (c *Client) ensureSaved(ctx context.Context, api vboxapi.VBoxAPI, vboxSession, machineRef string, timeout time.Duration) error {
+	sessObj, err := api.GetSessionObject(ctx, vboxSession)
+	if err != nil {
+		return err
+	}
+
+	if err := api.LockMachine(ctx, machineRef, sessObj, true); err != nil {
+		return err
+	}
+	defer unlockSession(ctx, api, sessObj)
+
+	mutableMachineRef, err := api.GetMutableMachine(ctx, sessObj)
+	if err != nil {
+		return err
+	}
+
+	progressRef, err := api.SaveState(ctx, mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	return c.waitProgress(ctx, api, progressRef, timeout)
+}
+
+// discardSavedState drops the saved state of a Saved VM, leaving it powered off.
+func discardSavedState(ctx context.Context, api vboxapi.VBoxAPI, vboxSession, machineRef string) error {
+	sessObj, err := api.GetSessionObject(ctx, vboxSession)
+	if err != nil {
+		return err
+	}
+
+	if err := api.LockMachine(ctx, machineRef, sessObj, false); err != nil {
+		return err
+	}
+	defer unlockSession(ctx, api, sessObj)
+
+	mutableMachineRef, err := api.GetMutableMachine(ctx, sessObj)
+	if err != nil {
+		return err
+	}
+
+	return api.DiscardSavedState(ctx, mutableMachineRef, true)
+}
+
+// checkAdapterSlot verifies that slot exists on the machine. The number of
+// slots depends on the chipset, and VirtualBox reports an out-of-range slot
+// with an unhelpful fault.
+func checkAdapterSlot(ctx context.Context, api vboxapi.VBoxAPI, machineRef string, slot uint32) error {
+	maxAdapters, err := api.GetMaxNetworkAdapters(ctx, machineRef)
+	if err != nil {
+		return fmt.Errorf("failed to get max network adapters: %w", err)
+	}
+	if slot >= maxAdapters {
+		return fmt.Errorf("adapter slot %d is out of range: the machine's chipset supports %d network adapters, numbered from 0", slot, maxAdapters)
+	}
 	return nil
 }
 
@@ -403,13 +1314,51 @@ type NATPortForwardRule struct {
 // CreateNATPortForward creates a new NAT port forwarding rule on a VM's adapter.
 // The VM must be powered off or the adapter settings must allow hot changes.
 func (c *Client) CreateNATPortForward(ctx context.Context, rule NATPortForwardRule) error {
+	return c.CreateNATPortForwards(ctx, rule)
+}
+
+// CreateNATPortForwards creates NAT port forwarding rules on the same VM
+// adapter as a unit: if one of them cannot be added, those added before it
+// are removed again. Rules that already exist with the same settings are
+// left as they are.
+func (c *Client) CreateNATPortForwards(ctx context.Context, rules ...NATPortForwardRule) error {
+	return c.setNATPortForwards(ctx, false, rules...)
+}
+
+// ReplaceNATPortForwards is CreateNATPortForwards, except that rules whose
+// name is taken by a rule with other settings replace it. Each rule is
+// removed right before its replacement is added, under the same machine
+// lock and settings save, so its host port is unbound only briefly. If a
+// rule cannot be added, the rules replaced so far are restored.
+func (c *Client) ReplaceNATPortForwards(ctx context.Context, rules ...NATPortForwardRule) error {
+	return c.setNATPortForwards(ctx, true, rules...)
+}
+
+// setNATPortForwards adds rules on the same VM adapter, replacing the
+// existing rules of the same names if replace is set.
+func (c *Client) setNATPortForwards(ctx context.Context, replace bool, rules ...NATPortForwardRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	machineID, adapterSlot := rules[0].MachineID, rules[0].AdapterSlot
+	for _, rule := range rules[1:] {
+		if rule.MachineID != machineID || rule.AdapterSlot != adapterSlot {
+			return fmt.Errorf("NAT port forwarding rules %s and %s are not on the same adapter", rules[0].Name, rule.Name)
+		}
+	}
+
 	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
-		// Find the machine
-		machineRef, err := findMachine(ctx, api, session, rule.MachineID)
+		// The machine may have been created in the same apply and not be
+		// registered yet.
+		machineRef, err := findRegisteredMachine(ctx, api, session, machineID, c.registrationWait)
 		if err != nil {
 			return err
 		}
 
+		if err := checkAdapterSlot(ctx, api, machineRef, adapterSlot); err != nil {
+			return err
+		}
+
 		// Get a session object to lock the machine
 		sessObj, err := api.GetSessionObject(ctx, session)
 		if err != nil {
@@ -420,44 +1369,146 @@ func (c *Client) CreateNATPortForward(ctx context.Context, rule NATPortForwardRu
 		if err := api.LockMachine(ctx, machineRef, sessObj, true); err != nil {
 			return fmt.Errorf("failed to lock machine: %w", err)
 		}
-		defer func() { _ = api.UnlockSession(context.Background(), sessObj) }()
+		defer unlockSession(ctx, api, sessObj)
+
+		// Adding the redirects skips those that exist, so it can be
+		// replayed on a save conflict.
+		return saveWithRetry(ctx, api, machineRef, sessObj, true, func(mutableMachineRef string) error {
+			natEngineRef, err := natEngine(ctx, api, mutableMachineRef, adapterSlot)
+			if err != nil {
+				return err
+			}
+
+			existing, err := api.GetNATRedirects(ctx, natEngineRef)
+			if err != nil {
+				return fmt.Errorf("failed to get NAT redirects: %w", err)
+			}
+
+			// Add the redirects
+			var added []string
+			var replaced []vboxapi.NATRedirect
+			// undo is best-effort: don't leave part of the rules behind.
+			undo := func() {
+				for _, name := range added {
+					_ = api.RemoveNATRedirect(context.Background(), natEngineRef, name)
+				}
+				for _, r := range replaced {
+					_ = api.AddNATRedirect(context.Background(), natEngineRef, r.Name, r.Protocol, r.HostIP, r.HostPort, r.GuestIP, r.GuestPort)
+				}
+			}
+			for _, rule := range rules {
+				if slices.Contains(existing, rule.redirect()) {
+					continue
+				}
+				i := slices.IndexFunc(existing, func(r vboxapi.NATRedirect) bool { return r.Name == rule.Name })
+				if replace && i >= 0 {
+					if err := api.RemoveNATRedirect(ctx, natEngineRef, rule.Name); err != nil {
+						undo()
+						return fmt.Errorf("failed to remove NAT redirect %s: %w", rule.Name, err)
+					}
+					replaced = append(replaced, existing[i])
+				}
+				if err := api.AddNATRedirect(ctx, natEngineRef, rule.Name, rule.Protocol, rule.HostIP, rule.HostPort, rule.GuestIP, rule.GuestPort); err != nil {
+					undo()
+					return fmt.Errorf("failed to add NAT redirect %s: %w", rule.Name, err)
+				}
+				added = append(added, rule.Name)
+			}
+			return nil
+		})
+	})
+}
+
+// natEngine returns the NAT engine of the adapter in slot.
+func natEngine(ctx context.Context, api vboxapi.VBoxAPI, machineRef string, slot uint32) (string, error) {
+	adapterRef, err := api.GetNetworkAdapter(ctx, machineRef, slot)
+	if err != nil {
+		return "", fmt.Errorf("failed to get network adapter slot %d: %w", slot, err)
+	}
+	natEngineRef, err := api.GetNATEngine(ctx, adapterRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to get NAT engine: %w", err)
+	}
+	return natEngineRef, nil
+}
 
-		// Get the mutable machine reference
+// saveWithRetry runs change on the mutable machine of sessObj, which holds
+// a lock on machineRef, and saves the settings. When VirtualBox refuses to
+// save because another process changed the settings meanwhile, it relocks
+// the machine, which drops the pending change and loads the current
+// settings, then runs change and saves once more. change must therefore be
+// safe to replay.
+func saveWithRetry(ctx context.Context, api vboxapi.VBoxAPI, machineRef, sessObj string, shared bool, change func(mutableMachineRef string) error) error {
+	for attempt := 1; ; attempt++ {
 		mutableMachineRef, err := api.GetMutableMachine(ctx, sessObj)
 		if err != nil {
 			return fmt.Errorf("failed to get mutable machine: %w", err)
 		}
-
-		// Get the network adapter
-		adapterRef, err := api.GetNetworkAdapter(ctx, mutableMachineRef, rule.AdapterSlot)
-		if err != nil {
-			return fmt.Errorf("failed to get network adapter slot %d: %w", rule.AdapterSlot, err)
+		if err := change(mutableMachineRef); err != nil {
+			return err
 		}
-
-		// Get the NAT engine
-		natEngineRef, err := api.GetNATEngine(ctx, adapterRef)
-		if err != nil {
-			return fmt.Errorf("failed to get NAT engine: %w", err)
+		err = api.SaveSettings(ctx, mutableMachineRef)
+		if err == nil {
+			return nil
 		}
-
-		// Add the redirect
-		if err := api.AddNATRedirect(ctx, natEngineRef, rule.Name, rule.Protocol, rule.HostIP, rule.HostPort, rule.GuestIP, rule.GuestPort); err != nil {
-			return fmt.Errorf("failed to add NAT redirect: %w", err)
+		if attempt > 1 || !isSettingsConflict(err) {
+			return fmt.Errorf("failed to save machine settings: %w", err)
 		}
 
-		// Save settings
-		if err := api.SaveSettings(ctx, mutableMachineRef); err != nil {
-			return fmt.Errorf("failed to save machine settings: %w", err)
+		tflog.Debug(ctx, "Machine settings changed by another process; applying the change again", map[string]interface{}{"error": err.Error()})
+		unlockSession(ctx, api, sessObj)
+		if err := api.LockMachine(ctx, machineRef, sessObj, shared); err != nil {
+			return fmt.Errorf("failed to lock machine: %w", err)
 		}
+	}
+}
 
-		return nil
-	})
+// isSettingsConflict reports whether err may be VirtualBox refusing to save
+// machine settings that were changed by another process since they were
+// loaded, which it reports as VBOX_E_FILE_ERROR. Every other failure to
+// write the settings file, e.g. a full disk or a missing permission, has
+// the same code, interface and component, and only the message, which
+// depends on the locale of vboxwebsrv, tells them apart. Those failures are
+// therefore retried once too: the retry costs a lock and a save, replays a
+// change that is safe to replay, and returns the second failure as is.
+func isSettingsConflict(err error) bool {
+	var vErr *vboxapi.Error
+	return errors.As(err, &vErr) && vErr.ResultCode == vboxapi.ResultFileError
+}
+
+// isMissingNATRedirect reports whether err is VirtualBox refusing to remove
+// a NAT redirect that does not exist, which it reports as E_INVALIDARG.
+func isMissingNATRedirect(err error) bool {
+	var vErr *vboxapi.Error
+	return errors.As(err, &vErr) && vErr.ResultCode == vboxapi.ResultInvalidArg
+}
+
+// redirect returns the NAT redirect VirtualBox reports for the rule.
+func (r NATPortForwardRule) redirect() vboxapi.NATRedirect {
+	return vboxapi.NATRedirect{
+		Name:      r.Name,
+		Protocol:  r.Protocol,
+		HostIP:    r.HostIP,
+		HostPort:  r.HostPort,
+		GuestIP:   r.GuestIP,
+		GuestPort: r.GuestPort,
+	}
 }
 
 // ReadNATPortForward reads a NAT port forwarding rule by name.
 // Returns nil, nil if the rule does not exist.
 func (c *Client) ReadNATPortForward(ctx context.Context, machineID string, adapterSlot uint32, name string) (*NATPortForwardRule, error) {
-	var result *NATPortForwardRule
+	rules, err := c.ReadNATPortForwards(ctx, machineID, adapterSlot, name)
+	if err != nil {
+		return nil, err
+	}
+	return rules[0], nil
+}
+
+// ReadNATPortForwards reads NAT port forwarding rules by name. The result
+// holds one entry per name, which is nil if the rule does not exist.
+func (c *Client) ReadNATPortForwards(ctx context.Context, machineID string, adapterSlot uint32, names ...string) ([]*NATPortForwardRule, error) {
+	result := make([]*NATPortForwardRule, len(names))
 	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
 		// Find the machine
 		machineRef, err := findMachine(ctx, api, session, machineID)
@@ -482,30 +1533,41 @@ func (c *Client) ReadNATPortForward(ctx context.Context, machineID string, adapt
 			return fmt.Errorf("failed to get NAT redirects: %w", err)
 		}
 
-		// Find the rule by name
-		for _, r := range redirects {
-			if r.Name == name {
-				result = &NATPortForwardRule{
-					MachineID:   machineID,
-					AdapterSlot: adapterSlot,
-					Name:        r.Name,
-					Protocol:    r.Protocol,
-					HostIP:      r.HostIP,
-					HostPort:    r.HostPort,
-					GuestIP:     r.GuestIP,
-					GuestPort:   r.GuestPort,
+		// Find the rules by name
+		for i, name := range names {
+			for _, r := range redirects {
+				if r.Name == name {
+					result[i] = &NATPortForwardRule{
+						MachineID:   machineID,
+						AdapterSlot: adapterSlot,
+						Name:        r.Name,
+						Protocol:    r.Protocol,
+						HostIP:      r.HostIP,
+						HostPort:    r.HostPort,
+						GuestIP:     r.GuestIP,
+						GuestPort:   r.GuestPort,
+					}
+					break
 				}
-				break
 			}
 		}
 		return nil
 	})
-	return result, err
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // DeleteNATPortForward removes a NAT port forwarding rule.
 // Returns nil if the rule does not exist (idempotent).
 func (c *Client) DeleteNATPortForward(ctx context.Context, machineID string, adapterSlot uint32, name string) error {
+	return c.DeleteNATPortForwards(ctx, machineID, adapterSlot, name)
+}
+
+// DeleteNATPortForwards removes NAT port forwarding rules from the same VM
+// adapter. Rules that do not exist are ignored.
+func (c *Client) DeleteNATPortForwards(ctx context.Context, machineID string, adapterSlot uint32, names ...string) error {
 	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
 		// Find the machine
 		machineRef, err := findMachine(ctx, api, session, machineID)
@@ -527,41 +1589,24 @@ func (c *Client) DeleteNATPortForward(ctx context.Context, machineID string, ada
 		if err := api.LockMachine(ctx, machineRef, sessObj, true); err != nil {
 			return fmt.Errorf("failed to lock machine: %w", err)
 		}
-		defer func() { _ = api.UnlockSession(context.Background(), sessObj) }()
-
-		// Get the mutable machine reference
-		mutableMachineRef, err := api.GetMutableMachine(ctx, sessObj)
-		if err != nil {
-			return fmt.Errorf("failed to get mutable machine: %w", err)
-		}
-
-		// Get the network adapter
-		adapterRef, err := api.GetNetworkAdapter(ctx, mutableMachineRef, adapterSlot)
-		if err != nil {
-			return fmt.Errorf("failed to get network adapter slot %d: %w", adapterSlot, err)
-		}
+		defer unlockSession(ctx, api, sessObj)
 
-		// Get the NAT engine
-		natEngineRef, err := api.GetNATEngine(ctx, adapterRef)
-		if err != nil {
-			return fmt.Errorf("failed to get NAT engine: %w", err)
-		}
-
-		// Remove the redirect (ignore error if rule doesn't exist)
-		if err := api.RemoveNATRedirect(ctx, natEngineRef, name); err != nil {
-			// Best-effort: if the error indicates rule not found, ignore
-			errLower := strings.ToLower(err.Error())
-			if !strings.Contains(errLower, "not found") && !strings.Contains(errLower, "does not exist") {
-				return fmt.Errorf("failed to remove NAT redirect: %w", err)
+		// Removing the redirects ignores those that are gone, so it can be
+		// replayed on a save conflict.
+		return saveWithRetry(ctx, api, machineRef, sessObj, true, func(mutableMachineRef string) error {
+			natEngineRef, err := natEngine(ctx, api, mutableMachineRef, adapterSlot)
+			if err != nil {
+				return err
 			}
-		}
 
-		// Save settings
-		if err := api.SaveSettings(ctx, mutableMachineRef); err != nil {
-			return fmt.Errorf("failed to save machine settings: %w", err)
-		}
-
-		return nil
+			// Remove the redirects (ignore error if a rule doesn't exist)
+			for _, name := range names {
+				if err := api.RemoveNATRedirect(ctx, natEngineRef, name); err != nil && !isMissingNATRedirect(err) {
+					return fmt.Errorf("failed to remove NAT redirect %s: %w", name, err)
+				}
+			}
+			return nil
+		})
 	})
 }
 
@@ -576,6 +1621,14 @@ func (c *Client) AllocateNATHostPort(ctx context.Context, opts PortAllocatorOpti
 	return port, err
 }
 
+// RecordAutoRange records the range of an auto host port allocation and
+// returns the ranges recorded earlier that overlap it with a different host
+// IP or scope. Such rules may contend for the same ports in ways their
+// scopes do not account for.
+func (c *Client) RecordAutoRange(opts PortAllocatorOptions) []PortAllocatorOptions {
+	return c.autoRanges.add(opts)
+}
+
 // GetAllNATRedirects returns all NAT redirects for a specific machine and adapter slot.
 func (c *Client) GetAllNATRedirects(ctx context.Context, machineID string, adapterSlot uint32) ([]vboxapi.NATRedirect, error) {
 	var result []vboxapi.NATRedirect
@@ -0,0 +1,1124 @@
+package vbox
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestCloneAndConverge_ExtraCloneOptions(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-template", &fakeMachine{ID: "uuid-template", Name: "template", OSTypeID: "Ubuntu_64"})
+	c := newTestClient(api)
+
+	result, err := c.CloneAndConverge(context.Background(), CloneRequest{
+		Name:              "clone",
+		Source:            "template",
+		CloneOptions:      []string{"Link"},
+		ExtraCloneOptions: []string{"KeepHwUUIDs", "SomeFutureOption"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ID != "uuid-clone" {
+		t.Errorf("uuid = %q, want %q", result.ID, "uuid-clone")
+	}
+	if result.State != "PoweredOff" {
+		t.Errorf("state = %q, want %q", result.State, "PoweredOff")
+	}
+
+	want := []string{"Link", "KeepHwUUIDs", "SomeFutureOption"}
+	if !reflect.DeepEqual(api.cloneOptions, want) {
+		t.Errorf("CloneTo options = %v, want %v", api.cloneOptions, want)
+	}
+}
+
+func TestCloneAndConverge_PlatformArchitecture(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured vboxapi.PlatformArchitecture
+		wantArch   vboxapi.PlatformArchitecture
+		wantReads  int
+	}{
+		{name: "detected from source", wantArch: vboxapi.PlatformArchitectureARM, wantReads: 1},
+		{name: "detection disabled", configured: vboxapi.PlatformArchitectureX86, wantArch: vboxapi.PlatformArchitectureX86},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMachine("machine-template", &fakeMachine{ID: "uuid-template", Name: "template", Architecture: vboxapi.PlatformArchitectureARM})
+			c := newTestClient(api)
+			c.SetPlatformArchitecture(tt.configured)
+
+			if _, err := c.CloneAndConverge(context.Background(), CloneRequest{Name: "clone", Source: "template"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if n := api.called("GetPlatformArchitecture"); n != tt.wantReads {
+				t.Errorf("GetPlatformArchitecture called %d times, want %d", n, tt.wantReads)
+			}
+			if arch := api.machines["machine-clone"].Architecture; arch != tt.wantArch {
+				t.Errorf("clone architecture = %q, want %q", arch, tt.wantArch)
+			}
+		})
+	}
+}
+
+func TestCreateAndConverge(t *testing.T) {
+	api := newFakeAPI()
+	c := newTestClient(api)
+
+	result, err := c.CreateAndConverge(context.Background(), CreateRequest{
+		Name:                 "scratch",
+		OSTypeID:             "Ubuntu_64",
+		PlatformArchitecture: vboxapi.PlatformArchitectureARM,
+		MemoryMB:             4096,
+		CPUCount:             2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ID != "uuid-scratch" || result.State != vboxapi.MachineStatePoweredOff {
+		t.Errorf("result = %+v, want uuid-scratch, PoweredOff", result)
+	}
+
+	m := api.machines["machine-scratch"]
+	if !m.Registered || !m.DefaultsApplied {
+		t.Errorf("registered = %t, defaults applied = %t; want both", m.Registered, m.DefaultsApplied)
+	}
+	if m.OSTypeID != "Ubuntu_64" || m.Architecture != vboxapi.PlatformArchitectureARM {
+		t.Errorf("OS type = %q, architecture = %q; want Ubuntu_64, ARM", m.OSTypeID, m.Architecture)
+	}
+	if m.MemoryMB != 4096 || m.CPUCount != 2 {
+		t.Errorf("memory = %d MB, CPUs = %d; want 4096 MB, 2", m.MemoryMB, m.CPUCount)
+	}
+	if n := api.called("CloneTo"); n != 0 {
+		t.Errorf("CloneTo called %d times, want 0", n)
+	}
+	if n := api.called("SaveSettings"); n == 0 {
+		t.Error("expected the settings to be saved before registration")
+	}
+}
+
+func TestCreateAndConverge_Defaults(t *testing.T) {
+	api := newFakeAPI()
+	c := newTestClient(api)
+	c.SetPlatformArchitecture(vboxapi.PlatformArchitectureX86)
+
+	if _, err := c.CreateAndConverge(context.Background(), CreateRequest{Name: "scratch", OSTypeID: "Debian_64"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := api.machines["machine-scratch"]
+	if m.Architecture != vboxapi.PlatformArchitectureX86 {
+		t.Errorf("architecture = %q, want x86", m.Architecture)
+	}
+	// The OS type's recommendations are kept.
+	if m.MemoryMB != 2048 || m.CPUCount != 1 {
+		t.Errorf("memory = %d MB, CPUs = %d; want the defaults", m.MemoryMB, m.CPUCount)
+	}
+	for _, name := range []string{"SetMemorySize", "SetCPUCount"} {
+		if n := api.called(name); n != 0 {
+			t.Errorf("%s called %d times, want 0", name, n)
+		}
+	}
+
+	if _, err := c.CreateAndConverge(context.Background(), CreateRequest{Name: "no-os"}); err == nil {
+		t.Error("expected an error without an OS type")
+	}
+}
+
+func TestCloneAndConverge_SnapshotWarning(t *testing.T) {
+	tests := []struct {
+		name        string
+		snapshots   uint32
+		mode        string
+		wantWarning bool
+	}{
+		{name: "MachineState with snapshots", snapshots: 2, mode: "MachineState", wantWarning: true},
+		{name: "default mode with snapshots", snapshots: 2, mode: "", wantWarning: true},
+		{name: "AllStates with snapshots", snapshots: 2, mode: "AllStates"},
+		{name: "MachineState without snapshots", mode: "MachineState"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMachine("machine-template", &fakeMachine{ID: "uuid-template", Name: "template", OSTypeID: "Ubuntu_64", Snapshots: tt.snapshots})
+			c := newTestClient(api)
+
+			result, err := c.CloneAndConverge(context.Background(), CloneRequest{
+				Name:      "clone",
+				Source:    "template",
+				CloneMode: tt.mode,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.wantWarning {
+				if len(result.Warnings) != 0 {
+					t.Errorf("expected no warnings, got %v", result.Warnings)
+				}
+				return
+			}
+			if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "2 snapshot(s)") {
+				t.Errorf("expected a snapshot warning, got %v", result.Warnings)
+			}
+		})
+	}
+}
+
+func TestCloneAndConverge_OSTypeOverride(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-template", &fakeMachine{ID: "uuid-template", Name: "template", OSTypeID: "Other"})
+	api.guestOSTypes = []vboxapi.GuestOSType{{ID: "Other"}, {ID: "Ubuntu_64"}}
+	c := newTestClient(api)
+
+	_, err := c.CloneAndConverge(context.Background(), CloneRequest{
+		Name:     "clone",
+		Source:   "template",
+		OSTypeID: "Ubuntu_64",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("GetOSTypeId"); n != 0 {
+		t.Errorf("GetOSTypeId called %d times, want 0", n)
+	}
+	if got := api.machines["machine-clone"].OSTypeID; got != "Ubuntu_64" {
+		t.Errorf("clone OS type = %q, want %q", got, "Ubuntu_64")
+	}
+}
+
+func TestCloneAndConverge_UnknownOSTypeOverride(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-template", &fakeMachine{ID: "uuid-template", Name: "template", OSTypeID: "Other"})
+	api.guestOSTypes = []vboxapi.GuestOSType{{ID: "Other"}, {ID: "Ubuntu_64"}}
+	c := newTestClient(api)
+
+	_, err := c.CloneAndConverge(context.Background(), CloneRequest{
+		Name:     "clone",
+		Source:   "template",
+		OSTypeID: "Ubuntu64",
+	})
+	if err == nil || !strings.Contains(err.Error(), `unknown OS type "Ubuntu64"`) {
+		t.Fatalf("expected an unknown OS type error, got %v", err)
+	}
+	if n := api.called("CreateMachine"); n != 0 {
+		t.Errorf("CreateMachine called %d times, want 0", n)
+	}
+}
+
+func TestCloneAndConverge_OSTypeFromSource(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-template", &fakeMachine{ID: "uuid-template", Name: "template", OSTypeID: "Debian_64"})
+	c := newTestClient(api)
+
+	if _, err := c.CloneAndConverge(context.Background(), CloneRequest{Name: "clone", Source: "template"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("GetOSTypeId"); n != 1 {
+		t.Errorf("GetOSTypeId called %d times, want 1", n)
+	}
+	if got := api.machines["machine-clone"].OSTypeID; got != "Debian_64" {
+		t.Errorf("clone OS type = %q, want %q", got, "Debian_64")
+	}
+}
+
+func TestCloneAndConverge_OperationTimeouts(t *testing.T) {
+	timeouts := OperationTimeouts{
+		"hard disk":             time.Hour,
+		DefaultOperationTimeout: 40 * time.Millisecond,
+	}
+	repeat := func(desc string, n int) []string {
+		ops := make([]string, n)
+		for i := range ops {
+			ops[i] = desc
+		}
+		return ops
+	}
+
+	tests := []struct {
+		name    string
+		ops     []string
+		wantErr string
+	}{
+		{
+			// The disk copy outlasts the default timeout but has its own.
+			name: "slow disk copy",
+			ops:  append([]string{"Copying settings"}, repeat("Copying Hard Disk 'disk.vdi'", 20)...),
+		},
+		{
+			name:    "stalled config copy",
+			ops:     repeat("Copying settings", 20),
+			wantErr: `timeout waiting for progress operation "Copying settings" after 40ms`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMachine("machine-template", &fakeMachine{ID: "uuid-template", Name: "template", OSTypeID: "Ubuntu_64"})
+			api.progressOps = tt.ops
+			c := newTestClient(api)
+			c.SetProgressPollConfig(ProgressPollConfig{Initial: 5 * time.Millisecond, Max: 5 * time.Millisecond})
+
+			_, err := c.CloneAndConverge(context.Background(), CloneRequest{
+				Name:              "clone",
+				Source:            "template",
+				CloneMode:         "MachineState",
+				DesiredState:      "stopped",
+				OperationTimeouts: timeouts,
+			})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOperationTimeoutsLookup(t *testing.T) {
+	timeouts := OperationTimeouts{
+		"disk":                  time.Hour,
+		"hard disk":             2 * time.Hour,
+		DefaultOperationTimeout: 5 * time.Minute,
+	}
+	for desc, want := range map[string]time.Duration{
+		"Copying Hard Disk 'disk.vdi'": 2 * time.Hour,
+		"Copying disk":                 time.Hour,
+		"Copying settings":             5 * time.Minute,
+	} {
+		if got := timeouts.lookup(desc); got != want {
+			t.Errorf("lookup(%q) = %v, want %v", desc, got, want)
+		}
+	}
+	if got := (OperationTimeouts{"disk": time.Hour}).lookup("Copying settings"); got != 0 {
+		t.Errorf("lookup without default = %v, want 0", got)
+	}
+}
+
+func TestCreateNATPortForward(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	err := c.CreateNATPortForward(context.Background(), NATPortForwardRule{
+		MachineID:   "uuid-vm",
+		AdapterSlot: 0,
+		Name:        "ssh",
+		Protocol:    vboxapi.NATProtocolTCP,
+		HostPort:    2222,
+		GuestPort:   22,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rules := api.machines["machine-vm"].NATRedirects[0]
+	if len(rules) != 1 || rules[0].Name != "ssh" || rules[0].HostPort != 2222 {
+		t.Errorf("unexpected NAT rules on slot 0: %+v", rules)
+	}
+}
+
+func TestConvergeStateByID_VerifyConsole(t *testing.T) {
+	defer func(d time.Duration) { consoleReadyPollInterval = d }(consoleReadyPollInterval)
+	consoleReadyPollInterval = time.Millisecond
+
+	t.Run("console reachable on retry", func(t *testing.T) {
+		api := newFakeAPI()
+		api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+		api.consoleErrors = 1
+		c := newTestClient(api)
+		c.SetVerifyConsole(true)
+
+		st, err := c.ConvergeStateByID(context.Background(), "uuid-vm", "started", "", "", "", time.Minute)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if st != vboxapi.MachineStateRunning {
+			t.Errorf("state = %q, want %q", st, vboxapi.MachineStateRunning)
+		}
+		if n := api.called("GetConsole"); n != 2 {
+			t.Errorf("GetConsole called %d times, want 2", n)
+		}
+		if !api.lockShared {
+			t.Error("expected the console to be checked through a shared lock")
+		}
+	})
+
+	t.Run("console never reachable", func(t *testing.T) {
+		api := newFakeAPI()
+		api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+		api.consoleErrors = consoleReadyAttempts
+		c := newTestClient(api)
+		c.SetVerifyConsole(true)
+
+		_, err := c.ConvergeStateByID(context.Background(), "uuid-vm", "started", "", "", "", time.Minute)
+		if err == nil || !strings.Contains(err.Error(), "console is not reachable") {
+			t.Fatalf("expected a console error, got %v", err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		api := newFakeAPI()
+		api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+		c := newTestClient(api)
+
+		if _, err := c.ConvergeStateByID(context.Background(), "uuid-vm", "started", "", "", "", time.Minute); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := api.called("GetConsole"); n != 0 {
+			t.Errorf("GetConsole called %d times, want 0", n)
+		}
+	})
+}
+
+func TestCreateNATPortForward_WaitsForRegistration(t *testing.T) {
+	defer func(d time.Duration) { registrationPollInterval = d }(registrationPollInterval)
+	registrationPollInterval = time.Millisecond
+
+	rule := NATPortForwardRule{MachineID: "uuid-vm", Name: "ssh", Protocol: vboxapi.NATProtocolTCP, HostPort: 2222, GuestPort: 22}
+
+	t.Run("registered after two lookups", func(t *testing.T) {
+		api := newFakeAPI()
+		api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+		api.findMachineMisses = 2
+		c := newTestClient(api)
+		c.SetRegistrationWait(time.Second)
+
+		if err := c.CreateNATPortForward(context.Background(), rule); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := api.called("FindMachine"); n != 3 {
+			t.Errorf("FindMachine called %d times, want 3", n)
+		}
+		if rules := api.machines["machine-vm"].NATRedirects[0]; len(rules) != 1 {
+			t.Errorf("unexpected NAT rules on slot 0: %+v", rules)
+		}
+	})
+
+	t.Run("no wait", func(t *testing.T) {
+		api := newFakeAPI()
+		api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+		api.findMachineMisses = 2
+		c := newTestClient(api)
+
+		err := c.CreateNATPortForward(context.Background(), rule)
+		if !IsNotFound(err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+		if n := api.called("FindMachine"); n != 1 {
+			t.Errorf("FindMachine called %d times, want 1", n)
+		}
+	})
+}
+
+func TestCreateNATPortForward_SlotOutOfRange(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", MaxAdapters: 4})
+	c := newTestClient(api)
+
+	err := c.CreateNATPortForward(context.Background(), NATPortForwardRule{
+		MachineID:   "uuid-vm",
+		AdapterSlot: 4,
+		Name:        "ssh",
+		Protocol:    vboxapi.NATProtocolTCP,
+		HostPort:    2222,
+		GuestPort:   22,
+	})
+	if err == nil {
+		t.Fatal("expected error for out-of-range adapter slot")
+	}
+	want := "adapter slot 4 is out of range: the machine's chipset supports 4 network adapters"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want it to contain %q", err, want)
+	}
+	if n := api.called("GetNetworkAdapter"); n != 0 {
+		t.Errorf("GetNetworkAdapter called %d times, want 0", n)
+	}
+	if n := api.called("LockMachine"); n != 0 {
+		t.Errorf("LockMachine called %d times, want 0", n)
+	}
+}
+
+func TestCreateNATPortForwards_PartialFailure(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	// A different rule already uses the name of the UDP half.
+	api.machines["machine-vm"].NATRedirects[0] = []vboxapi.NATRedirect{
+		{Name: "dns-udp", Protocol: vboxapi.NATProtocolUDP, HostPort: 5000, GuestPort: 5000},
+	}
+	c := newTestClient(api)
+
+	err := c.CreateNATPortForwards(context.Background(),
+		NATPortForwardRule{MachineID: "uuid-vm", Name: "dns-tcp", Protocol: vboxapi.NATProtocolTCP, HostPort: 5353, GuestPort: 53},
+		NATPortForwardRule{MachineID: "uuid-vm", Name: "dns-udp", Protocol: vboxapi.NATProtocolUDP, HostPort: 5353, GuestPort: 53},
+	)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	rules := api.machines["machine-vm"].NATRedirects[0]
+	if len(rules) != 1 || rules[0].Name != "dns-udp" || rules[0].HostPort != 5000 {
+		t.Errorf("expected only the pre-existing rule to be left, got %+v", rules)
+	}
+	if n := api.called("SaveSettings"); n != 0 {
+		t.Errorf("SaveSettings called %d times, want 0", n)
+	}
+}
+
+func TestCreateNATPortForwards_KeepsIdenticalRule(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	tcp := NATPortForwardRule{MachineID: "uuid-vm", Name: "dns-tcp", Protocol: vboxapi.NATProtocolTCP, HostPort: 5353, GuestPort: 53}
+	udp := NATPortForwardRule{MachineID: "uuid-vm", Name: "dns-udp", Protocol: vboxapi.NATProtocolUDP, HostPort: 5353, GuestPort: 53}
+	api.machines["machine-vm"].NATRedirects[0] = []vboxapi.NATRedirect{tcp.redirect()}
+	c := newTestClient(api)
+
+	if err := c.CreateNATPortForwards(context.Background(), tcp, udp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("AddNATRedirect"); n != 1 {
+		t.Errorf("AddNATRedirect called %d times, want 1", n)
+	}
+	read, err := c.ReadNATPortForwards(context.Background(), "uuid-vm", 0, "dns-tcp", "dns-udp", "dns")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if read[0] == nil || read[1] == nil || read[2] != nil {
+		t.Errorf("unexpected rules read back: %+v", read)
+	}
+}
+
+func TestReplaceNATPortForwards(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	tcp := NATPortForwardRule{MachineID: "uuid-vm", Name: "dns-tcp", Protocol: vboxapi.NATProtocolTCP, HostPort: 5353, GuestPort: 53}
+	udp := NATPortForwardRule{MachineID: "uuid-vm", Name: "dns-udp", Protocol: vboxapi.NATProtocolUDP, HostPort: 5353, GuestPort: 53}
+	api.machines["machine-vm"].NATRedirects[0] = []vboxapi.NATRedirect{tcp.redirect(), udp.redirect()}
+	c := newTestClient(api)
+
+	tcp.HostPort, udp.HostPort = 5354, 5354
+	tcp.GuestIP, udp.GuestIP = "10.0.2.15", "10.0.2.15"
+	if err := c.ReplaceNATPortForwards(context.Background(), tcp, udp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rules := api.machines["machine-vm"].NATRedirects[0]
+	if len(rules) != 2 || !slices.Contains(rules, tcp.redirect()) || !slices.Contains(rules, udp.redirect()) {
+		t.Errorf("unexpected NAT rules on slot 0: %+v", rules)
+	}
+	// Both rules are replaced under a single lock and save.
+	if n := api.called("LockMachine"); n != 1 {
+		t.Errorf("LockMachine called %d times, want 1", n)
+	}
+	if n := api.called("SaveSettings"); n != 1 {
+		t.Errorf("SaveSettings called %d times, want 1", n)
+	}
+
+	// Rules that already match are left alone.
+	if err := c.ReplaceNATPortForwards(context.Background(), tcp, udp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("RemoveNATRedirect"); n != 2 {
+		t.Errorf("RemoveNATRedirect called %d times, want 2", n)
+	}
+}
+
+func TestDeleteNATPortForwards_IgnoresMissingRules(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	ssh := NATPortForwardRule{MachineID: "uuid-vm", Name: "ssh", Protocol: vboxapi.NATProtocolTCP, HostPort: 2222, GuestPort: 22}
+	api.machines["machine-vm"].NATRedirects[0] = []vboxapi.NATRedirect{ssh.redirect()}
+	c := newTestClient(api)
+
+	if err := c.DeleteNATPortForwards(context.Background(), "uuid-vm", 0, "gone", "ssh"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rules := api.machines["machine-vm"].NATRedirects[0]; len(rules) != 0 {
+		t.Errorf("unexpected NAT rules left on slot 0: %+v", rules)
+	}
+}
+
+func TestCreateNATPortForwards_RetriesSaveConflict(t *testing.T) {
+	conflict := &vboxapi.Error{
+		Operation:  "IMachine_saveSettings",
+		ResultCode: vboxapi.ResultFileError,
+		Text:       "Die Einstellungsdatei der VM wurde von einem anderen Prozess geändert",
+	}
+	rule := NATPortForwardRule{MachineID: "uuid-vm", Name: "ssh", Protocol: vboxapi.NATProtocolTCP, HostPort: 2222, GuestPort: 22}
+
+	t.Run("conflict then success", func(t *testing.T) {
+		api := newFakeAPI()
+		api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+		api.saveErrs = []error{conflict}
+		c := newTestClient(api)
+
+		if err := c.CreateNATPortForwards(context.Background(), rule); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := api.called("SaveSettings"); n != 2 {
+			t.Errorf("SaveSettings called %d times, want 2", n)
+		}
+		if n := api.called("LockMachine"); n != 2 {
+			t.Errorf("LockMachine called %d times, want 2", n)
+		}
+		if rules := api.machines["machine-vm"].NATRedirects[0]; len(rules) != 1 || rules[0] != rule.redirect() {
+			t.Errorf("unexpected NAT rules on slot 0: %+v", rules)
+		}
+	})
+
+	t.Run("conflict twice", func(t *testing.T) {
+		api := newFakeAPI()
+		api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+		api.saveErrs = []error{conflict, conflict}
+		c := newTestClient(api)
+
+		err := c.CreateNATPortForwards(context.Background(), rule)
+		var vErr *vboxapi.Error
+		if !errors.As(err, &vErr) || vErr.ResultCode != vboxapi.ResultFileError {
+			t.Fatalf("expected the conflict error, got %v", err)
+		}
+		if n := api.called("SaveSettings"); n != 2 {
+			t.Errorf("SaveSettings called %d times, want 2", n)
+		}
+	})
+
+	t.Run("other failure", func(t *testing.T) {
+		api := newFakeAPI()
+		api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+		api.saveErrs = []error{&vboxapi.Error{Operation: "IMachine_saveSettings", ResultCode: vboxapi.ResultAccessDenied, Text: "Access denied"}}
+		c := newTestClient(api)
+
+		if err := c.CreateNATPortForwards(context.Background(), rule); err == nil {
+			t.Fatal("expected an error")
+		}
+		if n := api.called("SaveSettings"); n != 1 {
+			t.Errorf("SaveSettings called %d times, want 1", n)
+		}
+	})
+}
+
+func TestConvergeStateByID_SaveState(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	state, err := c.ConvergeStateByID(context.Background(), "uuid-vm", "stopped", StopMethodSaveState, "", "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state != vboxapi.MachineStateSaved {
+		t.Errorf("state = %q, want %q", state, vboxapi.MachineStateSaved)
+	}
+	if n := api.called("SaveState"); n != 1 {
+		t.Errorf("SaveState called %d times, want 1", n)
+	}
+	if n := api.called("PowerDown"); n != 0 {
+		t.Errorf("PowerDown called %d times, want 0", n)
+	}
+}
+
+func TestConvergeStateByID_SavedCountsAsStopped(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateSaved})
+	c := newTestClient(api)
+
+	state, err := c.ConvergeStateByID(context.Background(), "uuid-vm", "stopped", StopMethodSaveState, "", "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state != vboxapi.MachineStateSaved {
+		t.Errorf("state = %q, want %q", state, vboxapi.MachineStateSaved)
+	}
+	if n := api.called("LockMachine"); n != 0 {
+		t.Errorf("LockMachine called %d times, want 0", n)
+	}
+}
+
+func TestConvergeStateByID_PowerOffDiscardsSavedState(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateSaved})
+	c := newTestClient(api)
+
+	state, err := c.ConvergeStateByID(context.Background(), "uuid-vm", "stopped", StopMethodPowerOff, "", "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state != vboxapi.MachineStatePoweredOff {
+		t.Errorf("state = %q, want %q", state, vboxapi.MachineStatePoweredOff)
+	}
+	if n := api.called("DiscardSavedState"); n != 1 {
+		t.Errorf("DiscardSavedState called %d times, want 1", n)
+	}
+}
+
+func TestConvergeStateByID_Transitions(t *testing.T) {
+	want := map[string]string{
+		"started": vboxapi.MachineStateRunning,
+		"stopped": vboxapi.MachineStatePoweredOff,
+		"paused":  vboxapi.MachineStatePaused,
+		"saved":   vboxapi.MachineStateSaved,
+	}
+	for from, initial := range want {
+		for to, target := range want {
+			t.Run(from+" to "+to, func(t *testing.T) {
+				api := newFakeAPI()
+				api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: initial})
+				c := newTestClient(api)
+
+				state, err := c.ConvergeStateByID(context.Background(), "uuid-vm", to, "", "", "", 0)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if state != target {
+					t.Errorf("state = %q, want %q", state, target)
+				}
+				if from == to && api.called("LockMachine")+api.called("LaunchVMProcess") != 0 {
+					t.Error("expected a VM already in the desired state to be left alone")
+				}
+			})
+		}
+	}
+
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStatePaused})
+	if _, err := newTestClient(api).ConvergeStateByID(context.Background(), "uuid-vm", "started", "", "", "", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("Resume"); n != 1 {
+		t.Errorf("Resume called %d times, want 1", n)
+	}
+	if n := api.called("LaunchVMProcess"); n != 0 {
+		t.Errorf("LaunchVMProcess called %d times, want 0", n)
+	}
+}
+
+func TestConvergeStateByID_WaitsForStableState(t *testing.T) {
+	tests := []struct {
+		name      string
+		initial   string
+		desired   string
+		transient []string
+		want      string
+	}{
+		{"start", vboxapi.MachineStatePoweredOff, "started", []string{vboxapi.MachineStateStarting, vboxapi.MachineStateStarting}, vboxapi.MachineStateRunning},
+		{"stop", vboxapi.MachineStateRunning, "stopped", []string{vboxapi.MachineStateStopping}, vboxapi.MachineStatePoweredOff},
+		{"save", vboxapi.MachineStateRunning, "saved", []string{vboxapi.MachineStateSaving}, vboxapi.MachineStateSaved},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: tc.initial, TransientStates: tc.transient})
+			c := newTestClient(api)
+
+			state, err := c.ConvergeStateByID(context.Background(), "uuid-vm", tc.desired, "", "", "", time.Minute)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if state != tc.want {
+				t.Errorf("state = %q, want %q", state, tc.want)
+			}
+		})
+	}
+
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStatePoweredOff, TransientStates: []string{vboxapi.MachineStateStarting}})
+	c := newTestClient(api)
+	c.SetWaitForStableState(false)
+	state, err := c.ConvergeStateByID(context.Background(), "uuid-vm", "started", "", "", "", time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state != vboxapi.MachineStateStarting {
+		t.Errorf("state = %q, want %q when not waiting for stable states", state, vboxapi.MachineStateStarting)
+	}
+}
+
+func TestConvergeStateByID_StableStateTimeout(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning, TransientStates: slices.Repeat([]string{vboxapi.MachineStateStopping}, 1000)})
+	c := newTestClient(api)
+
+	_, err := c.ConvergeStateByID(context.Background(), "uuid-vm", "stopped", "", "", "", 20*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "Stopping") {
+		t.Errorf("error = %v, want a timeout naming the Stopping state", err)
+	}
+}
+
+func TestConvergeStateByID_InvalidState(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	if _, err := newTestClient(api).ConvergeStateByID(context.Background(), "uuid-vm", "hibernated", "", "", "", 0); err == nil {
+		t.Error("expected an error for an unknown desired state")
+	}
+}
+
+func TestConvergeStateByID_ACPIShutdown(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       string
+		acpiIgnored bool
+		wantEvents  []string
+	}{
+		{name: "guest shuts down", state: vboxapi.MachineStateRunning, wantEvents: []string{"acpi:machine-vm"}},
+		{name: "guest ignores the power button", state: vboxapi.MachineStateRunning, acpiIgnored: true, wantEvents: []string{"acpi:machine-vm", "stop:machine-vm"}},
+		{name: "paused", state: vboxapi.MachineStatePaused, wantEvents: []string{"stop:machine-vm"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: tt.state})
+			api.acpiIgnored = tt.acpiIgnored
+			c := newTestClient(api)
+
+			state, err := c.ConvergeStateByID(context.Background(), "uuid-vm", "stopped", StopMethodPowerOff, ShutdownModeACPI, "", 20*time.Millisecond)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if state != vboxapi.MachineStatePoweredOff {
+				t.Errorf("state = %q, want %q", state, vboxapi.MachineStatePoweredOff)
+			}
+			if !slices.Equal(api.powerEvents, tt.wantEvents) {
+				t.Errorf("power events = %v, want %v", api.powerEvents, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestDeleteByID_SavedMachine(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateSaved})
+	c := newTestClient(api)
+
+	if err := c.DeleteByID(context.Background(), "uuid-vm", 0, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("DiscardSavedState"); n != 1 {
+		t.Errorf("DiscardSavedState called %d times, want 1", n)
+	}
+	if _, ok := api.machines["machine-vm"]; ok {
+		t.Error("expected machine to be deleted")
+	}
+}
+
+func TestDeleteByID_LinkedCloneDependents(t *testing.T) {
+	newAPI := func() *fakeAPI {
+		api := newFakeAPI()
+		// The template's current state is a differencing image of its base
+		// disk, from which the linked clone branched off.
+		api.addMachine("machine-tpl", &fakeMachine{ID: "uuid-tpl", Name: "tpl", Disks: []string{"medium-tpl-current"}})
+		api.addMachine("machine-clone", &fakeMachine{ID: "uuid-clone", Name: "clone", Disks: []string{"medium-clone"}})
+		api.addMedium("medium-base", &fakeMedium{ID: "uuid-base", MachineIDs: []string{"uuid-tpl"}})
+		api.addMedium("medium-tpl-current", &fakeMedium{ID: "uuid-tpl-current", Parent: "medium-base", MachineIDs: []string{"uuid-tpl"}})
+		api.addMedium("medium-clone", &fakeMedium{ID: "uuid-clone-disk", Parent: "medium-base", MachineIDs: []string{"uuid-clone"}})
+		return api
+	}
+
+	t.Run("refused", func(t *testing.T) {
+		api := newAPI()
+		err := newTestClient(api).DeleteByID(context.Background(), "uuid-tpl", 0, false)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if !strings.Contains(err.Error(), "clone (uuid-clone)") {
+			t.Errorf("error %q does not name the dependent clone", err)
+		}
+		if _, ok := api.machines["machine-tpl"]; !ok {
+			t.Error("expected machine not to be deleted")
+		}
+		if n := api.called("UnregisterMachine"); n != 0 {
+			t.Errorf("UnregisterMachine called %d times, want 0", n)
+		}
+	})
+
+	t.Run("forced", func(t *testing.T) {
+		api := newAPI()
+		if err := newTestClient(api).DeleteByID(context.Background(), "uuid-tpl", 0, true); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := api.machines["machine-tpl"]; ok {
+			t.Error("expected machine to be deleted")
+		}
+	})
+
+	t.Run("clone", func(t *testing.T) {
+		// Deleting the clone only removes its own differencing image.
+		api := newAPI()
+		if err := newTestClient(api).DeleteByID(context.Background(), "uuid-clone", 0, false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := api.machines["machine-clone"]; ok {
+			t.Error("expected machine to be deleted")
+		}
+	})
+}
+
+func TestGetMachineInfoByID_CurrentSnapshot(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", Snapshots: 2, CurrentSnapshot: "ci-baseline"})
+	api.addMachine("machine-plain", &fakeMachine{ID: "uuid-plain", Name: "plain"})
+	c := newTestClient(api)
+
+	for id, want := range map[string]string{"uuid-vm": "ci-baseline", "uuid-plain": ""} {
+		info, err := c.GetMachineInfoByID(context.Background(), id)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", id, err)
+		}
+		if info.CurrentSnapshot != want {
+			t.Errorf("%s: CurrentSnapshot = %q, want %q", id, info.CurrentSnapshot, want)
+		}
+	}
+}
+
+func TestGetMachineInfoByID_Inaccessible(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", AccessError: "Could not find file vm.vbox"})
+	c := newTestClient(api)
+
+	info, err := c.GetMachineInfoByID(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Accessible {
+		t.Error("expected machine to be inaccessible")
+	}
+	if info.ID != "uuid-vm" || info.AccessError != "Could not find file vm.vbox" {
+		t.Errorf("info = %+v, want ID and AccessError set", info)
+	}
+	if n := api.called("GetMachineName"); n != 0 {
+		t.Errorf("GetMachineName called %d times, want 0", n)
+	}
+
+	api.machines["machine-vm"].AccessError = ""
+	info, err = c.GetMachineInfoByID(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !info.Accessible || info.AccessError != "" || info.Name != "vm" {
+		t.Errorf("info = %+v, want an accessible machine named vm", info)
+	}
+}
+
+func TestGetMachineInfoByID_ByName(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "base-vm", OSTypeID: "Ubuntu_64", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	info, err := c.GetMachineInfoByID(context.Background(), "base-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := MachineInfo{ID: "uuid-vm", Name: "base-vm", State: vboxapi.MachineStateRunning, OSTypeID: "Ubuntu_64", Accessible: true}
+	if *info != want {
+		t.Errorf("info = %+v, want %+v", *info, want)
+	}
+
+	if _, err := c.GetMachineInfoByID(context.Background(), "missing"); !IsNotFound(err) {
+		t.Errorf("error = %v, want a not found error", err)
+	}
+}
+
+func TestListMachines(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-b", &fakeMachine{ID: "uuid-b", Name: "web", State: vboxapi.MachineStateRunning, ExtraData: map[string]string{OwnerExtraDataKey: "team-a"}})
+	api.addMachine("machine-a", &fakeMachine{ID: "uuid-a", Name: "db", State: vboxapi.MachineStatePoweredOff})
+	api.addMachine("machine-c", &fakeMachine{ID: "uuid-c", Name: "broken", AccessError: "Could not find file broken.vbox"})
+	c := newTestClient(api)
+
+	machines, err := c.ListMachines(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []MachineInfo{
+		{ID: "uuid-c", AccessError: "Could not find file broken.vbox"},
+		{ID: "uuid-a", Name: "db", State: vboxapi.MachineStatePoweredOff, Accessible: true},
+		{ID: "uuid-b", Name: "web", State: vboxapi.MachineStateRunning, Accessible: true, Owner: "team-a"},
+	}
+	if !slices.Equal(machines, want) {
+		t.Errorf("machines = %+v, want %+v", machines, want)
+	}
+}
+
+func TestConvergeStatesByID(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-a", &fakeMachine{ID: "uuid-a", Name: "a"})
+	api.addMachine("machine-b", &fakeMachine{ID: "uuid-b", Name: "b"})
+	c := newTestClient(api)
+
+	states, err := c.ConvergeStatesByID(context.Background(), []string{"uuid-a", "uuid-b"}, "started", "", "", "", time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{vboxapi.MachineStateRunning, vboxapi.MachineStateRunning}; !slices.Equal(states, want) {
+		t.Errorf("states = %v, want %v", states, want)
+	}
+	if n := api.called("Logon"); n != 1 {
+		t.Errorf("Logon called %d times, want 1", n)
+	}
+}
+
+func TestRollingPowerCycle(t *testing.T) {
+	api := newFakeAPI()
+	for _, name := range []string{"a", "b", "c"} {
+		api.addMachine("machine-"+name, &fakeMachine{ID: "uuid-" + name, Name: name, State: vboxapi.MachineStateRunning})
+	}
+	api.addMachine("machine-off", &fakeMachine{ID: "uuid-off", Name: "off"})
+	c := newTestClient(api)
+
+	err := c.RollingPowerCycle(context.Background(), []string{"uuid-a", "uuid-off", "uuid-b", "uuid-c"}, 2, "", time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"stop:machine-a", "stop:machine-b", "start:machine-a", "start:machine-b",
+		"stop:machine-c", "start:machine-c",
+	}
+	if !slices.Equal(api.powerEvents, want) {
+		t.Errorf("power events = %v, want %v", api.powerEvents, want)
+	}
+	if st := api.machines["machine-off"].State; st != vboxapi.MachineStatePoweredOff {
+		t.Errorf("stopped VM state = %q, want it left %q", st, vboxapi.MachineStatePoweredOff)
+	}
+}
+
+func TestRollingPowerCycle_StopsAtFailedBatch(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-a", &fakeMachine{ID: "uuid-a", Name: "a", State: vboxapi.MachineStateRunning})
+	api.addMachine("machine-b", &fakeMachine{ID: "uuid-b", Name: "b", State: vboxapi.MachineStateRunning})
+	api.launchErr = errors.New("VM process failed to start")
+	c := newTestClient(api)
+
+	err := c.RollingPowerCycle(context.Background(), []string{"uuid-a", "uuid-b"}, 1, "", time.Minute)
+	if err == nil {
+		t.Fatal("expected an error when the first batch does not come back")
+	}
+	if want := []string{"stop:machine-a"}; !slices.Equal(api.powerEvents, want) {
+		t.Errorf("power events = %v, want %v", api.powerEvents, want)
+	}
+	if st := api.machines["machine-b"].State; st != vboxapi.MachineStateRunning {
+		t.Errorf("second batch state = %q, want it left %q", st, vboxapi.MachineStateRunning)
+	}
+}
+
+func TestNormalizeMachineState(t *testing.T) {
+	tests := map[string]string{
+		vboxapi.MachineStateRunning:      "started",
+		vboxapi.MachineStatePaused:       "paused",
+		vboxapi.MachineStateSaved:        "saved",
+		vboxapi.MachineStateAbortedSaved: "saved",
+		vboxapi.MachineStatePoweredOff:   "stopped",
+		vboxapi.MachineStateAborted:      "stopped",
+		vboxapi.MachineStateStarting:     "",
+		vboxapi.MachineStateStopping:     "",
+		vboxapi.MachineStateSaving:       "",
+		vboxapi.MachineStateRestoring:    "",
+		vboxapi.MachineStateStuck:        "",
+	}
+	for st, want := range tests {
+		if got := NormalizeMachineState(st); got != want {
+			t.Errorf("NormalizeMachineState(%q) = %q, want %q", st, got, want)
+		}
+	}
+}
+
+func TestIsTransientState(t *testing.T) {
+	tests := map[string]bool{
+		vboxapi.MachineStateStarting:          true,
+		vboxapi.MachineStateStopping:          true,
+		vboxapi.MachineStateSaving:            true,
+		vboxapi.MachineStateRestoringSnapshot: true,
+		vboxapi.MachineStateRunning:           false,
+		vboxapi.MachineStatePoweredOff:        false,
+		vboxapi.MachineStateAborted:           false,
+		vboxapi.MachineStateStuck:             false,
+	}
+	for st, want := range tests {
+		if got := IsTransientState(st); got != want {
+			t.Errorf("IsTransientState(%q) = %v, want %v", st, got, want)
+		}
+	}
+}
+
+func TestGetMachineInfoByID_TransientState(t *testing.T) {
+	for _, st := range []string{
+		vboxapi.MachineStateStarting,
+		vboxapi.MachineStateStopping,
+		vboxapi.MachineStateSaving,
+		vboxapi.MachineStateRestoring,
+		vboxapi.MachineStateAborted,
+		vboxapi.MachineStateTeleporting,
+	} {
+		t.Run(st, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: st})
+			c := newTestClient(api)
+
+			info, err := c.GetMachineInfoByID(context.Background(), "uuid-vm")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if info.State != st {
+				t.Errorf("State = %q, want %q unchanged", info.State, st)
+			}
+		})
+	}
+}
+
+// findMachineErrAPI is a fakeAPI whose FindMachine fails with err.
+type findMachineErrAPI struct {
+	*fakeAPI
+	err error
+}
+
+func (f findMachineErrAPI) FindMachine(_ context.Context, _, _ string) (string, error) {
+	return "", f.err
+}
+
+func TestFindMachine_NotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "object not found fault",
+			err:  &vboxapi.Error{ResultCode: vboxapi.ResultObjectNotFound, Text: "Could not find a registered machine named 'vm'"},
+			want: true,
+		},
+		{
+			name: "localized object not found fault",
+			err:  &vboxapi.Error{ResultCode: vboxapi.ResultObjectNotFound, Text: "Konnte keine registrierte Maschine namens 'vm' finden"},
+			want: true,
+		},
+		{
+			name: "other fault",
+			err:  &vboxapi.Error{ResultCode: vboxapi.ResultAccessDenied, Text: "Could not find out whether access is allowed"},
+			want: false,
+		},
+		{
+			name: "transport error",
+			err:  errors.New("could not find host vbox.example.com"),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := findMachineErrAPI{fakeAPI: newFakeAPI(), err: tt.err}
+			_, err := findMachine(context.Background(), api, "session", "vm")
+			if got := IsNotFound(err); got != tt.want {
+				t.Errorf("IsNotFound(%v) = %t, want %t", err, got, tt.want)
+			}
+		})
+	}
+}
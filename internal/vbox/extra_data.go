@@ -0,0 +1,126 @@
+package vbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// SetExtraData sets an extra data item of a VM, or a global one when
+// machineID is empty. The machine is locked shared, so that items of
+// running VMs can be set. An item that already has value is left alone.
+func (c *Client) SetExtraData(ctx context.Context, machineID, key, value string) error {
+	if key == "" {
+		return fmt.Errorf("extra data key must not be empty")
+	}
+	if value == "" {
+		return fmt.Errorf("extra data %s: value must not be empty; an empty value deletes the item", key)
+	}
+
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		if machineID == "" {
+			return changeGlobalExtraData(ctx, api, session, key, value)
+		}
+		// The machine may have been created in the same apply and not be
+		// registered yet.
+		machineRef, err := findRegisteredMachine(ctx, api, session, machineID, c.registrationWait)
+		if err != nil {
+			return err
+		}
+		return changeExtraData(ctx, api, session, machineRef, key, value)
+	})
+}
+
+// ReadExtraData returns an extra data item of a VM, or a global one when
+// machineID is empty. It returns "" if there is no such item.
+func (c *Client) ReadExtraData(ctx context.Context, machineID, key string) (string, error) {
+	var value string
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		var err error
+		if machineID == "" {
+			value, err = api.GetGlobalExtraData(ctx, session, key)
+		} else {
+			var machineRef string
+			machineRef, err = findMachine(ctx, api, session, machineID)
+			if err != nil {
+				return err
+			}
+			value, err = api.GetExtraData(ctx, machineRef, key)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get extra data %s: %w", key, err)
+		}
+		return nil
+	})
+	return value, err
+}
+
+// DeleteExtraData deletes an extra data item of a VM, or a global one when
+// machineID is empty. An item or VM that does not exist is ignored.
+func (c *Client) DeleteExtraData(ctx context.Context, machineID, key string) error {
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		if machineID == "" {
+			return changeGlobalExtraData(ctx, api, session, key, "")
+		}
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			if IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		return changeExtraData(ctx, api, session, machineRef, key, "")
+	})
+}
+
+// changeExtraData sets a machine extra data item, or deletes it when value
+// is empty, under a shared lock. It skips an item that already has value,
+// so it can be replayed on a save conflict.
+func changeExtraData(ctx context.Context, api vboxapi.VBoxAPI, session, machineRef, key, value string) error {
+	sessObj, err := api.GetSessionObject(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to get session object: %w", err)
+	}
+	if err := api.LockMachine(ctx, machineRef, sessObj, true); err != nil {
+		return fmt.Errorf("failed to lock machine: %w", err)
+	}
+	defer unlockSession(ctx, api, sessObj)
+
+	return saveWithRetry(ctx, api, machineRef, sessObj, true, func(mutableMachineRef string) error {
+		current, err := api.GetExtraData(ctx, mutableMachineRef, key)
+		if err != nil {
+			return fmt.Errorf("failed to get extra data %s: %w", key, err)
+		}
+		if current == value {
+			return nil
+		}
+		if err := api.SetExtraData(ctx, mutableMachineRef, key, value); err != nil {
+			return extraDataError(key, value, err)
+		}
+		return nil
+	})
+}
+
+// changeGlobalExtraData sets a global extra data item, or deletes it when
+// value is empty. VirtualBox saves global items right away.
+func changeGlobalExtraData(ctx context.Context, api vboxapi.VBoxAPI, session, key, value string) error {
+	current, err := api.GetGlobalExtraData(ctx, session, key)
+	if err != nil {
+		return fmt.Errorf("failed to get extra data %s: %w", key, err)
+	}
+	if current == value {
+		return nil
+	}
+	if err := api.SetGlobalExtraData(ctx, session, key, value); err != nil {
+		return extraDataError(key, value, err)
+	}
+	return nil
+}
+
+func extraDataError(key, value string, err error) error {
+	if value == "" {
+		return fmt.Errorf("failed to delete extra data %s: %w", key, err)
+	}
+	return fmt.Errorf("failed to set extra data %s: %w", key, err)
+}
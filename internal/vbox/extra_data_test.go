@@ -0,0 +1,90 @@
+package vbox
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestExtraData_Machine(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+	ctx := context.Background()
+
+	if err := c.SetExtraData(ctx, "uuid-vm", "GUI/ScaleFactor", "2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !api.lockShared {
+		t.Error("expected the item to be set under a shared lock")
+	}
+	if got := api.machines["machine-vm"].ExtraData["GUI/ScaleFactor"]; got != "2" {
+		t.Errorf("extra data = %q, want %q", got, "2")
+	}
+
+	// Setting the same value again leaves the item alone.
+	if err := c.SetExtraData(ctx, "uuid-vm", "GUI/ScaleFactor", "2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("SetExtraData"); n != 1 {
+		t.Errorf("SetExtraData called %d times, want 1", n)
+	}
+
+	if got, err := c.ReadExtraData(ctx, "uuid-vm", "GUI/ScaleFactor"); err != nil || got != "2" {
+		t.Errorf("ReadExtraData() = %q, %v, want %q", got, err, "2")
+	}
+
+	if err := c.DeleteExtraData(ctx, "uuid-vm", "GUI/ScaleFactor"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := c.ReadExtraData(ctx, "uuid-vm", "GUI/ScaleFactor"); err != nil || got != "" {
+		t.Errorf("ReadExtraData() = %q, %v, want no item", got, err)
+	}
+	if err := c.DeleteExtraData(ctx, "missing", "GUI/ScaleFactor"); err != nil {
+		t.Errorf("unexpected error deleting from a missing VM: %v", err)
+	}
+	if _, err := c.ReadExtraData(ctx, "missing", "GUI/ScaleFactor"); !IsNotFound(err) {
+		t.Errorf("ReadExtraData() error = %v, want not found", err)
+	}
+	if got := api.called("LockMachine"); got != api.called("UnlockSession") {
+		t.Errorf("LockMachine called %d times but UnlockSession %d times", got, api.called("UnlockSession"))
+	}
+}
+
+func TestExtraData_Global(t *testing.T) {
+	api := newFakeAPI()
+	c := newTestClient(api)
+	ctx := context.Background()
+
+	if err := c.SetExtraData(ctx, "", "GUI/UpdateDate", "never"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := api.globalExtraData["GUI/UpdateDate"]; got != "never" {
+		t.Errorf("global extra data = %q, want %q", got, "never")
+	}
+	if api.called("LockMachine") != 0 {
+		t.Error("expected no machine to be locked")
+	}
+
+	if got, err := c.ReadExtraData(ctx, "", "GUI/UpdateDate"); err != nil || got != "never" {
+		t.Errorf("ReadExtraData() = %q, %v, want %q", got, err, "never")
+	}
+
+	if err := c.DeleteExtraData(ctx, "", "GUI/UpdateDate"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := api.globalExtraData["GUI/UpdateDate"]; ok {
+		t.Error("expected the global item to be deleted")
+	}
+}
+
+func TestSetExtraData_EmptyValue(t *testing.T) {
+	c := newTestClient(newFakeAPI())
+
+	err := c.SetExtraData(context.Background(), "", "GUI/UpdateDate", "")
+	if err == nil || !strings.Contains(err.Error(), "value must not be empty") {
+		t.Errorf("error = %v, want the empty value to be rejected", err)
+	}
+}
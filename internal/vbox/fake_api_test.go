@@ -0,0 +1,2615 @@
+package vbox
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// fakeMachine is the in-memory state of a machine known to fakeAPI.
+type fakeMachine struct {
+	ID          string
+	Name        string
+	OSTypeID    string
+	State       string
+	Registered  bool
+	MaxAdapters uint32
+	Snapshots   uint32
+	// CurrentSnapshot is the name of the current snapshot, if any.
+	CurrentSnapshot string
+	// Architecture is the machine's platform architecture; empty means
+	// x86.
+	Architecture vboxapi.PlatformArchitecture
+	// ExecutionEngine defaults to vboxapi.ExecutionEngineDefault.
+	ExecutionEngine vboxapi.ExecutionEngine
+	// VRDEProperties holds the VRDE server properties.
+	VRDEProperties map[string]string
+	// ExtraData holds the machine's extra data items.
+	ExtraData   map[string]string
+	CPUIDLeaves []vboxapi.CPUIDLeaf
+	// GraphicsController defaults to VBoxVGA and VRAMMB to 16.
+	GraphicsController vboxapi.GraphicsController
+	VRAMMB             uint32
+	Accelerate3D       bool
+	// MonitorCount defaults to 1.
+	MonitorCount uint32
+	// MemoryMB and CPUCount are the memory size and CPU count.
+	// DefaultsApplied is set by ApplyDefaults.
+	MemoryMB        uint32
+	CPUCount        uint32
+	DefaultsApplied bool
+	// PageFusion is the page fusion toggle.
+	PageFusion bool
+	// CPUHotPlug enables CPU hot-plug; DetachedCPUs are the CPUs detached
+	// with it.
+	CPUHotPlug   bool
+	DetachedCPUs map[uint32]bool
+	// APICMode defaults to APIC.
+	APICMode vboxapi.APICMode
+	// BootMenuMode defaults to MessageAndMenu.
+	BootMenuMode vboxapi.BootMenuMode
+	// AutostartEnabled and AutostartDelay are the autostart settings.
+	AutostartEnabled bool
+	AutostartDelay   uint32
+	// ClipboardFileTransfers and RecordingEnabled are the clipboard and
+	// recording toggles.
+	ClipboardFileTransfers bool
+	RecordingEnabled       bool
+	// RecordingScreens are the recording settings of each screen. nil
+	// defaults to one screen per monitor, with only the first recorded.
+	RecordingScreens []vboxapi.RecordingScreenSettings
+	// AccessError, when set, makes the machine inaccessible: its
+	// settings can no longer be read.
+	AccessError string
+	// TransientStates are reported by GetMachineState, one per call,
+	// after each power operation and before State.
+	TransientStates []string
+	pendingStates   []string
+	// HWVirtExProperties and CPUProperties hold the x86 platform
+	// properties that are enabled.
+	HWVirtExProperties map[vboxapi.HWVirtExProperty]bool
+	CPUProperties      map[vboxapi.CPUProperty]bool
+	// DisabledAdapters holds the adapter slots that are disabled.
+	DisabledAdapters map[uint32]bool
+	// AttachmentTypes holds the attachment type of each adapter slot;
+	// slots not in it are attached to NAT.
+	AttachmentTypes map[uint32]vboxapi.NetworkAttachmentType
+	// InternalNetworks holds the internal network of each adapter slot
+	// attached to one.
+	InternalNetworks map[uint32]string
+	// BridgedInterfaces, HostOnlyInterfaces and AdapterNATNetworks hold
+	// the host interface or NAT network set on each adapter slot.
+	BridgedInterfaces  map[uint32]string
+	HostOnlyInterfaces map[uint32]string
+	AdapterNATNetworks map[uint32]string
+	// DisconnectedCables holds the adapter slots whose cable is unplugged.
+	DisconnectedCables map[uint32]bool
+	// LineSpeeds holds the line speed of each adapter slot, in kbps.
+	LineSpeeds map[uint32]uint32
+	// AdapterTypes holds the type of each adapter slot that was set;
+	// other slots report I82540EM.
+	AdapterTypes map[uint32]vboxapi.NetworkAdapterType
+	// MACAddresses holds the MAC address of each adapter slot that was
+	// set; other slots report 0800270000 followed by the slot.
+	MACAddresses map[uint32]string
+	// NATRedirects holds the NAT rules of each adapter slot.
+	NATRedirects map[uint32][]vboxapi.NATRedirect
+	// Disks holds the refs of the hard disks attached to the machine.
+	Disks []string
+	// Attachments holds further attachments, such as DVD drives.
+	Attachments []vboxapi.MediumAttachment
+	// SharedFolders holds the machine's shared folders.
+	SharedFolders []vboxapi.SharedFolder
+	// GuestProperties holds the machine's guest properties by name.
+	GuestProperties map[string]vboxapi.GuestProperty
+}
+
+// fakeMedium is the in-memory state of a medium known to fakeAPI.
+type fakeMedium struct {
+	ID       string
+	Location string
+	Type     vboxapi.MediumType
+	Variants []string
+	// Parent is the ref of the parent of a differencing image.
+	Parent string
+	// MachineIDs are the IDs of the machines using the medium.
+	MachineIDs []string
+}
+
+// fakeSnapshot is the in-memory state of a snapshot taken through fakeAPI.
+type fakeSnapshot struct {
+	Machine     string
+	ID          string
+	Name        string
+	Description string
+	TakenAt     time.Time
+	// Paused records the pause argument of TakeSnapshot.
+	Paused bool
+}
+
+// fakeProgress is the in-memory state of a progress known to fakeAPI.
+type fakeProgress struct {
+	Description string
+	Operation   string
+	Percent     uint32
+	Cancelable  bool
+	// PendingPolls is the number of GetProgressCompleted calls that report
+	// the progress as running before it completes.
+	PendingPolls int
+	ResultCode   int32
+	ErrorText    string
+	// Canceled is set by CancelProgress.
+	Canceled bool
+}
+
+// fakeAPI is an in-memory vboxapi.VBoxAPI used by client tests.
+// Methods that a test does not exercise are left to the embedded nil
+// interface and panic if called.
+type fakeAPI struct {
+	vboxapi.VBoxAPI
+
+	mu       sync.Mutex
+	calls    map[string]int
+	machines map[string]*fakeMachine // keyed by machine ref
+	media    map[string]*fakeMedium  // keyed by medium ref
+
+	// controllers holds storage controller types keyed by controller name.
+	controllers map[string]vboxapi.StorageControllerType
+	// controllerBuses and controllerPorts hold the bus and port count of
+	// the controllers added with AddStorageController.
+	controllerBuses map[string]vboxapi.StorageBus
+	controllerPorts map[string]uint32
+	// controllerIOCache holds whether each controller uses the host I/O
+	// cache.
+	controllerIOCache map[string]bool
+	// controllerBootable holds whether each controller is bootable.
+	controllerBootable map[string]bool
+	// saveErrs are returned by the first SaveSettings calls, one each.
+	saveErrs []error
+	// unlockErr is returned by UnlockSession.
+	unlockErr error
+	// addControllerErr is returned by AddStorageController.
+	addControllerErr error
+	// findMachineMisses is the number of FindMachine calls that report the
+	// machine as not found before it is looked up, as if its registration
+	// lagged.
+	findMachineMisses int
+	// attachments holds attached medium refs keyed by "controller:port:device".
+	attachments map[string]string
+
+	// Captured arguments of the last CloneTo call.
+	cloneMode    string
+	cloneOptions []string
+
+	// progressOps holds the operation description reported by each poll
+	// of a progress; the progress completes once they are used up.
+	progressOps []string
+	// progressResultCode and progressErrorText are reported by every
+	// completed progress.
+	progressResultCode int32
+	progressErrorText  string
+	// progresses holds progresses looked up by ref. Refs not in it use
+	// progressOps, progressResultCode and progressErrorText.
+	progresses map[string]*fakeProgress
+
+	// snapshots holds the snapshots taken with TakeSnapshot, by ref.
+	snapshots map[string]*fakeSnapshot
+
+	// additionsRunLevels holds the Guest Additions run level reported by
+	// each GetAdditionsRunLevel call; the last one is repeated.
+	additionsRunLevels []vboxapi.AdditionsRunLevel
+	// additionsISO is the host's default Guest Additions ISO.
+	additionsISO string
+	// memoryBalloonMB is the memory balloon size of the guest.
+	memoryBalloonMB uint32
+	// updateAdditionsErr is returned by UpdateGuestAdditions.
+	updateAdditionsErr error
+	// Captured source of the last UpdateGuestAdditions call.
+	updateAdditionsSource string
+
+	// unsupportedEngines are reported as unsupported by the host.
+	unsupportedEngines []vboxapi.ExecutionEngine
+
+	// apiVersion is reported by GetAPIVersion; empty reports "7_1".
+	apiVersion string
+	// host is reported by GetVersion and the host getters; its
+	// APIVersion is ignored.
+	host HostInfo
+
+	// natNetworks holds the port forwarding rules of each NAT network, by
+	// ref. natNetworksErr is returned by GetNATNetworks.
+	natNetworks    map[string][]vboxapi.NATRedirect
+	natNetworksErr error
+	// natNetworkSettings holds the settings of the NAT networks created
+	// with CreateNATNetwork, by ref.
+	natNetworkSettings map[string]vboxapi.NATNetwork
+
+	// consoleErrors is the number of GetConsole calls that fail before the
+	// console is reachable.
+	consoleErrors int
+	// scancodes records the scan codes sent with PutScancodes, of which
+	// keyboardQueue are queued; zero queues them all.
+	scancodes     []int32
+	keyboardQueue uint32
+
+	// powerEvents records "start:<ref>", "stop:<ref>" and "acpi:<ref>" for
+	// each LaunchVMProcess, PowerDown and PowerButton call, in order.
+	powerEvents []string
+	// launchErr is returned by LaunchVMProcess.
+	launchErr error
+	// acpiIgnored makes guests ignore PowerButton; otherwise they power
+	// off right away.
+	acpiIgnored bool
+
+	// sessionCount numbers the sessions opened by Logon.
+	sessionCount int
+	// expiredSessions are rejected by FindMachine as invalid objects, as if
+	// the webservice had timed them out.
+	expiredSessions map[string]bool
+
+	// globalExtraData holds the global extra data items.
+	globalExtraData map[string]string
+
+	// hostInterfaces are the host's bridged network interfaces.
+	hostInterfaces []vboxapi.HostNetworkInterface
+	// guestOSTypes are reported by GetGuestOSTypes.
+	guestOSTypes []vboxapi.GuestOSType
+
+	// autostartErr is returned by SetAutostartEnabled, as when the host has
+	// no autostart database.
+	autostartErr error
+
+	// Captured access mode of the last OpenMedium call.
+	openAccessMode vboxapi.AccessMode
+	// Captured arguments of the last LockMachine call.
+	lockedMachine string
+	lockShared    bool
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{
+		calls:              make(map[string]int),
+		machines:           make(map[string]*fakeMachine),
+		media:              make(map[string]*fakeMedium),
+		controllers:        make(map[string]vboxapi.StorageControllerType),
+		controllerBuses:    make(map[string]vboxapi.StorageBus),
+		controllerPorts:    make(map[string]uint32),
+		controllerIOCache:  make(map[string]bool),
+		controllerBootable: make(map[string]bool),
+		attachments:        make(map[string]string),
+		progresses:         make(map[string]*fakeProgress),
+		snapshots:          make(map[string]*fakeSnapshot),
+		globalExtraData:    make(map[string]string),
+	}
+}
+
+// newTestClient returns a Client whose sessions all use api and that polls
+// every millisecond.
+func newTestClient(api vboxapi.VBoxAPI) *Client {
+	return &Client{
+		newAPI:       func() vboxapi.VBoxAPI { return api },
+		progressPoll: ProgressPollConfig{Initial: time.Millisecond, Max: time.Millisecond},
+	}
+}
+
+func (f *fakeAPI) record(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[name]++
+}
+
+func (f *fakeAPI) called(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[name]
+}
+
+// addMachine registers a machine under ref.
+func (f *fakeAPI) addMachine(ref string, m *fakeMachine) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m.Registered = true
+	if m.State == "" {
+		m.State = vboxapi.MachineStatePoweredOff
+	}
+	if m.ExecutionEngine == "" {
+		m.ExecutionEngine = vboxapi.ExecutionEngineDefault
+	}
+	if m.GraphicsController == "" {
+		m.GraphicsController = vboxapi.GraphicsControllerVBoxVGA
+	}
+	if m.VRAMMB == 0 {
+		m.VRAMMB = 16
+	}
+	if m.MonitorCount == 0 {
+		m.MonitorCount = 1
+	}
+	if m.RecordingScreens == nil {
+		for id := range m.MonitorCount {
+			m.RecordingScreens = append(m.RecordingScreens, vboxapi.RecordingScreenSettings{
+				ID:         id,
+				Enabled:    id == 0,
+				Filename:   fmt.Sprintf("/vms/%s/%s-screen%d.webm", m.Name, m.Name, id),
+				VideoCodec: vboxapi.RecordingVideoCodecVP8,
+				VideoFPS:   25,
+			})
+		}
+	}
+	if m.APICMode == "" {
+		m.APICMode = vboxapi.APICModeAPIC
+	}
+	if m.BootMenuMode == "" {
+		m.BootMenuMode = vboxapi.BootMenuModeMessageAndMenu
+	}
+	if m.VRDEProperties == nil {
+		m.VRDEProperties = make(map[string]string)
+	}
+	if m.ExtraData == nil {
+		m.ExtraData = make(map[string]string)
+	}
+	if m.MaxAdapters == 0 {
+		m.MaxAdapters = 8
+	}
+	if m.NATRedirects == nil {
+		m.NATRedirects = make(map[uint32][]vboxapi.NATRedirect)
+	}
+	f.machines[ref] = m
+}
+
+func (f *fakeAPI) machine(ref string) (*fakeMachine, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m, ok := f.machines[ref]
+	if !ok {
+		return nil, fmt.Errorf("object not found: %s", ref)
+	}
+	return m, nil
+}
+
+// addMedium registers a medium under ref.
+func (f *fakeAPI) addMedium(ref string, m *fakeMedium) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if m.Type == "" {
+		m.Type = vboxapi.MediumTypeNormal
+	}
+	f.media[ref] = m
+}
+
+func (f *fakeAPI) medium(ref string) (*fakeMedium, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m, ok := f.media[ref]
+	if !ok {
+		return nil, fmt.Errorf("object not found: %s", ref)
+	}
+	return m, nil
+}
+
+func attachmentKey(controller string, port, device int32) string {
+	return fmt.Sprintf("%s:%d:%d", controller, port, device)
+}
+
+func (f *fakeAPI) Logon(_ context.Context, _, _ string) (string, error) {
+	f.record("Logon")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessionCount++
+	return fmt.Sprintf("session-%d", f.sessionCount), nil
+}
+
+func (f *fakeAPI) Logoff(_ context.Context, _ string) error {
+	f.record("Logoff")
+	return nil
+}
+
+func (f *fakeAPI) GetSessionObject(_ context.Context, _ string) (string, error) {
+	f.record("GetSessionObject")
+	return "session-object", nil
+}
+
+func (f *fakeAPI) FindMachine(_ context.Context, session, nameOrID string) (string, error) {
+	f.record("FindMachine")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.expiredSessions[session] {
+		return "", &vboxapi.Error{Operation: "IVirtualBox_findMachine", Text: "invalid managed object reference " + session, BadObjectID: session}
+	}
+	if f.findMachineMisses > 0 {
+		f.findMachineMisses--
+		return "", machineNotFound(nameOrID)
+	}
+	for ref, m := range f.machines {
+		if m.Registered && (m.ID == nameOrID || m.Name == nameOrID) {
+			return ref, nil
+		}
+	}
+	return "", machineNotFound(nameOrID)
+}
+
+// machineNotFound is the fault IVirtualBox::findMachine returns for a
+// machine that is not registered.
+func machineNotFound(nameOrID string) error {
+	return &vboxapi.Error{
+		Operation:  "IVirtualBox_findMachine",
+		ResultCode: vboxapi.ResultObjectNotFound,
+		Text:       fmt.Sprintf("Could not find a registered machine named '%s'", nameOrID),
+	}
+}
+
+func (f *fakeAPI) GetMachines(_ context.Context, _ string) ([]string, error) {
+	f.record("GetMachines")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var refs []string
+	for ref, m := range f.machines {
+		if m.Registered {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+func (f *fakeAPI) CreateMachine(_ context.Context, _, name, osTypeId string, arch vboxapi.PlatformArchitecture) (string, error) {
+	f.record("CreateMachine")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ref := "machine-" + name
+	f.machines[ref] = &fakeMachine{
+		ID:           "uuid-" + name,
+		Name:         name,
+		OSTypeID:     osTypeId,
+		State:        vboxapi.MachineStatePoweredOff,
+		Architecture: arch,
+	}
+	return ref, nil
+}
+
+func (f *fakeAPI) GetAPIVersion(_ context.Context, _ string) (string, error) {
+	f.record("GetAPIVersion")
+	if f.apiVersion == "" {
+		return "7_1", nil
+	}
+	return f.apiVersion, nil
+}
+
+func (f *fakeAPI) GetGuestOSTypes(_ context.Context, _ string) ([]vboxapi.GuestOSType, error) {
+	f.record("GetGuestOSTypes")
+	return slices.Clone(f.guestOSTypes), nil
+}
+
+func (f *fakeAPI) GetVersion(_ context.Context, _ string) (string, error) {
+	f.record("GetVersion")
+	return f.host.Version, nil
+}
+
+func (f *fakeAPI) GetHostOperatingSystem(_ context.Context, _ string) (string, error) {
+	f.record("GetHostOperatingSystem")
+	return f.host.OS, nil
+}
+
+func (f *fakeAPI) GetHostOSVersion(_ context.Context, _ string) (string, error) {
+	f.record("GetHostOSVersion")
+	return f.host.OSVersion, nil
+}
+
+func (f *fakeAPI) GetHostProcessorCount(_ context.Context, _ string) (uint32, error) {
+	f.record("GetHostProcessorCount")
+	return f.host.ProcessorCount, nil
+}
+
+func (f *fakeAPI) GetHostMemorySize(_ context.Context, _ string) (uint32, error) {
+	f.record("GetHostMemorySize")
+	return f.host.MemoryMB, nil
+}
+
+func (f *fakeAPI) GetPlatformArchitecture(_ context.Context, machineRef string) (vboxapi.PlatformArchitecture, error) {
+	f.record("GetPlatformArchitecture")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	if m.Architecture == "" {
+		return vboxapi.PlatformArchitectureX86, nil
+	}
+	return m.Architecture, nil
+}
+
+func (f *fakeAPI) ApplyDefaults(_ context.Context, machineRef string) error {
+	f.record("ApplyDefaults")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return err
+	}
+	m.DefaultsApplied = true
+	m.MemoryMB, m.CPUCount = 2048, 1
+	return nil
+}
+
+func (f *fakeAPI) SetMemorySize(_ context.Context, mutableMachineRef string, memoryMB uint32) error {
+	f.record("SetMemorySize")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.MemoryMB = memoryMB
+	return nil
+}
+
+func (f *fakeAPI) GetMemorySize(_ context.Context, machineRef string) (uint32, error) {
+	f.record("GetMemorySize")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return 0, err
+	}
+	return m.MemoryMB, nil
+}
+
+func (f *fakeAPI) GetCPUCount(_ context.Context, machineRef string) (uint32, error) {
+	f.record("GetCPUCount")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return 0, err
+	}
+	return m.CPUCount, nil
+}
+
+func (f *fakeAPI) GetCPUHotPlugEnabled(_ context.Context, machineRef string) (bool, error) {
+	f.record("GetCPUHotPlugEnabled")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return false, err
+	}
+	return m.CPUHotPlug, nil
+}
+
+func (f *fakeAPI) GetCPUStatus(_ context.Context, machineRef string, cpu uint32) (bool, error) {
+	f.record("GetCPUStatus")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return false, err
+	}
+	return cpu < m.CPUCount && !m.DetachedCPUs[cpu], nil
+}
+
+func (f *fakeAPI) HotPlugCPU(_ context.Context, mutableMachineRef string, cpu uint32) error {
+	f.record("HotPlugCPU")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	if !m.CPUHotPlug || cpu >= m.CPUCount {
+		return fmt.Errorf("CPU %d cannot be attached", cpu)
+	}
+	delete(m.DetachedCPUs, cpu)
+	return nil
+}
+
+func (f *fakeAPI) HotUnplugCPU(_ context.Context, mutableMachineRef string, cpu uint32) error {
+	f.record("HotUnplugCPU")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	if !m.CPUHotPlug || cpu == 0 || cpu >= m.CPUCount {
+		return fmt.Errorf("CPU %d cannot be detached", cpu)
+	}
+	if m.DetachedCPUs == nil {
+		m.DetachedCPUs = make(map[uint32]bool)
+	}
+	m.DetachedCPUs[cpu] = true
+	return nil
+}
+
+func (f *fakeAPI) SetCPUCount(_ context.Context, mutableMachineRef string, count uint32) error {
+	f.record("SetCPUCount")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.CPUCount = count
+	return nil
+}
+
+func (f *fakeAPI) RegisterMachine(_ context.Context, _, machineRef string) error {
+	f.record("RegisterMachine")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return err
+	}
+	m.Registered = true
+	return nil
+}
+
+func (f *fakeAPI) GetMachineId(_ context.Context, machineRef string) (string, error) {
+	f.record("GetMachineId")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	return m.ID, nil
+}
+
+func (f *fakeAPI) GetMachineName(_ context.Context, machineRef string) (string, error) {
+	f.record("GetMachineName")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	if m.AccessError != "" {
+		return "", fmt.Errorf("machine %s is inaccessible", machineRef)
+	}
+	return m.Name, nil
+}
+
+func (f *fakeAPI) GetMachineState(_ context.Context, machineRef string) (string, error) {
+	f.record("GetMachineState")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	if m.AccessError != "" {
+		return "", fmt.Errorf("machine %s is inaccessible", machineRef)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(m.pendingStates) > 0 {
+		st := m.pendingStates[0]
+		m.pendingStates = m.pendingStates[1:]
+		return st, nil
+	}
+	return m.State, nil
+}
+
+func (f *fakeAPI) GetMachineAccessible(_ context.Context, machineRef string) (bool, error) {
+	f.record("GetMachineAccessible")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return false, err
+	}
+	return m.AccessError == "", nil
+}
+
+func (f *fakeAPI) GetMachineAccessError(_ context.Context, machineRef string) (string, error) {
+	f.record("GetMachineAccessError")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	return m.AccessError, nil
+}
+
+func (f *fakeAPI) GetOSTypeId(_ context.Context, machineRef string) (string, error) {
+	f.record("GetOSTypeId")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	return m.OSTypeID, nil
+}
+
+func (f *fakeAPI) GetSnapshotCount(_ context.Context, machineRef string) (uint32, error) {
+	f.record("GetSnapshotCount")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return 0, err
+	}
+	return m.Snapshots, nil
+}
+
+func (f *fakeAPI) GetCurrentSnapshot(_ context.Context, machineRef string) (string, error) {
+	f.record("GetCurrentSnapshot")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	if m.CurrentSnapshot == "" {
+		return "", nil
+	}
+	return "snapshot-" + m.CurrentSnapshot, nil
+}
+
+func (f *fakeAPI) GetSnapshotName(_ context.Context, snapshotRef string) (string, error) {
+	f.record("GetSnapshotName")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if snap, ok := f.snapshots[snapshotRef]; ok {
+		return snap.Name, nil
+	}
+	return strings.TrimPrefix(snapshotRef, "snapshot-"), nil
+}
+
+func (f *fakeAPI) TakeSnapshot(_ context.Context, mutableMachineRef, name, description string, pause bool) (string, string, error) {
+	f.record("TakeSnapshot")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return "", "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := fmt.Sprintf("uuid-snapshot-%d", len(f.snapshots)+1)
+	f.snapshots["snapshot-"+id] = &fakeSnapshot{
+		Machine:     mutableMachineRef,
+		ID:          id,
+		Name:        name,
+		Description: description,
+		TakenAt:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Paused:      pause,
+	}
+	m.Snapshots++
+	m.CurrentSnapshot = name
+	return id, "progress-snapshot", nil
+}
+
+func (f *fakeAPI) DeleteSnapshot(_ context.Context, mutableMachineRef, snapshotID string) (string, error) {
+	f.record("DeleteSnapshot")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.snapshots["snapshot-"+snapshotID]; !ok {
+		return "", fmt.Errorf("Could not find a snapshot with UUID {%s}", snapshotID)
+	}
+	delete(f.snapshots, "snapshot-"+snapshotID)
+	m.Snapshots--
+	return "progress-deletesnapshot", nil
+}
+
+func (f *fakeAPI) RestoreSnapshot(_ context.Context, mutableMachineRef, snapshotRef string) (string, error) {
+	f.record("RestoreSnapshot")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	snap, ok := f.snapshots[snapshotRef]
+	if !ok {
+		return "", fmt.Errorf("snapshot %s not found", snapshotRef)
+	}
+	m.CurrentSnapshot = snap.Name
+	return "progress-restoresnapshot", nil
+}
+
+func (f *fakeAPI) FindSnapshot(_ context.Context, machineRef, nameOrID string) (string, error) {
+	f.record("FindSnapshot")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ref, snap := range f.snapshots {
+		if snap.Machine == machineRef && (snap.ID == nameOrID || snap.Name == nameOrID) {
+			return ref, nil
+		}
+	}
+	return "", &vboxapi.Error{
+		Operation:  "IMachine_findSnapshot",
+		Text:       fmt.Sprintf("Could not find a snapshot with UUID {%s}", nameOrID),
+		ResultCode: vboxapi.ResultObjectNotFound,
+	}
+}
+
+// snapshot returns the snapshot with the given ref from snapshots.
+func (f *fakeAPI) snapshot(ref string) (*fakeSnapshot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	snap, ok := f.snapshots[ref]
+	if !ok {
+		return nil, fmt.Errorf("object not found: %s", ref)
+	}
+	return snap, nil
+}
+
+func (f *fakeAPI) GetSnapshotId(_ context.Context, snapshotRef string) (string, error) {
+	f.record("GetSnapshotId")
+	snap, err := f.snapshot(snapshotRef)
+	if err != nil {
+		return "", err
+	}
+	return snap.ID, nil
+}
+
+func (f *fakeAPI) GetSnapshotDescription(_ context.Context, snapshotRef string) (string, error) {
+	f.record("GetSnapshotDescription")
+	snap, err := f.snapshot(snapshotRef)
+	if err != nil {
+		return "", err
+	}
+	return snap.Description, nil
+}
+
+func (f *fakeAPI) GetSnapshotTimeStamp(_ context.Context, snapshotRef string) (time.Time, error) {
+	f.record("GetSnapshotTimeStamp")
+	snap, err := f.snapshot(snapshotRef)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return snap.TakenAt, nil
+}
+
+func (f *fakeAPI) SetSnapshotName(_ context.Context, snapshotRef, name string) error {
+	f.record("SetSnapshotName")
+	snap, err := f.snapshot(snapshotRef)
+	if err != nil {
+		return err
+	}
+	snap.Name = name
+	return nil
+}
+
+func (f *fakeAPI) SetSnapshotDescription(_ context.Context, snapshotRef, description string) error {
+	f.record("SetSnapshotDescription")
+	snap, err := f.snapshot(snapshotRef)
+	if err != nil {
+		return err
+	}
+	snap.Description = description
+	return nil
+}
+
+func (f *fakeAPI) GetExecutionEngine(_ context.Context, machineRef string) (vboxapi.ExecutionEngine, error) {
+	f.record("GetExecutionEngine")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	if m.ExecutionEngine == "" {
+		return vboxapi.ExecutionEngineDefault, nil
+	}
+	return m.ExecutionEngine, nil
+}
+
+func (f *fakeAPI) SetExecutionEngine(_ context.Context, mutableMachineRef string, engine vboxapi.ExecutionEngine) error {
+	f.record("SetExecutionEngine")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.ExecutionEngine = engine
+	return nil
+}
+
+func (f *fakeAPI) IsExecutionEngineSupported(_ context.Context, _, _ string, engine vboxapi.ExecutionEngine) (bool, error) {
+	f.record("IsExecutionEngineSupported")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !slices.Contains(f.unsupportedEngines, engine), nil
+}
+
+func (f *fakeAPI) GetVRDEProperty(_ context.Context, machineRef, key string) (string, error) {
+	f.record("GetVRDEProperty")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	return m.VRDEProperties[key], nil
+}
+
+func (f *fakeAPI) GetExtraData(_ context.Context, machineRef, key string) (string, error) {
+	f.record("GetExtraData")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return m.ExtraData[key], nil
+}
+
+func (f *fakeAPI) SetExtraData(_ context.Context, mutableMachineRef, key, value string) error {
+	f.record("SetExtraData")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if value == "" {
+		delete(m.ExtraData, key)
+	} else {
+		m.ExtraData[key] = value
+	}
+	return nil
+}
+
+func (f *fakeAPI) GetGlobalExtraData(_ context.Context, _, key string) (string, error) {
+	f.record("GetGlobalExtraData")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.globalExtraData[key], nil
+}
+
+func (f *fakeAPI) SetGlobalExtraData(_ context.Context, _, key, value string) error {
+	f.record("SetGlobalExtraData")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if value == "" {
+		delete(f.globalExtraData, key)
+	} else {
+		f.globalExtraData[key] = value
+	}
+	return nil
+}
+
+func (f *fakeAPI) GetGuestProperty(_ context.Context, machineRef, name string) (string, string, error) {
+	f.record("GetGuestProperty")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p := m.GuestProperties[name]
+	return p.Value, p.Flags, nil
+}
+
+// SetGuestProperty stores flags the way VirtualBox reports them: upper
+// case, separated by ", ".
+func (f *fakeAPI) SetGuestProperty(_ context.Context, mutableMachineRef, name, value, flags string) error {
+	f.record("SetGuestProperty")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if value == "" {
+		delete(m.GuestProperties, name)
+		return nil
+	}
+	if m.GuestProperties == nil {
+		m.GuestProperties = make(map[string]vboxapi.GuestProperty)
+	}
+	var normalized []string
+	for _, flag := range strings.Split(flags, ",") {
+		if flag = strings.ToUpper(strings.TrimSpace(flag)); flag != "" {
+			normalized = append(normalized, flag)
+		}
+	}
+	m.GuestProperties[name] = vboxapi.GuestProperty{Name: name, Value: value, Flags: strings.Join(normalized, ", ")}
+	return nil
+}
+
+func (f *fakeAPI) EnumerateGuestProperties(_ context.Context, machineRef, patterns string) ([]vboxapi.GuestProperty, error) {
+	f.record("EnumerateGuestProperties")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var props []vboxapi.GuestProperty
+	for name, p := range m.GuestProperties {
+		for _, pattern := range strings.Split(patterns, "|") {
+			if ok, _ := path.Match(pattern, name); ok || patterns == "" {
+				props = append(props, p)
+				break
+			}
+		}
+	}
+	return props, nil
+}
+
+func (f *fakeAPI) SetVRDEProperty(_ context.Context, mutableMachineRef, key, value string) error {
+	f.record("SetVRDEProperty")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	if m.VRDEProperties == nil {
+		m.VRDEProperties = make(map[string]string)
+	}
+	if value == "" {
+		delete(m.VRDEProperties, key)
+	} else {
+		m.VRDEProperties[key] = value
+	}
+	return nil
+}
+
+// x86Machine returns the machine with the given ref, failing like
+// IPlatform::getX86 for ARM machines.
+func (f *fakeAPI) x86Machine(ref string) (*fakeMachine, error) {
+	m, err := f.machine(ref)
+	if err != nil {
+		return nil, err
+	}
+	if m.Architecture == vboxapi.PlatformArchitectureARM {
+		return nil, &vboxapi.Error{Operation: "IPlatform_getX86", Text: "The platform is not x86", ResultCode: vboxapi.ResultNotImpl}
+	}
+	return m, nil
+}
+
+func (f *fakeAPI) GetCPUIDLeaves(_ context.Context, machineRef string) ([]vboxapi.CPUIDLeaf, error) {
+	f.record("GetCPUIDLeaves")
+	m, err := f.x86Machine(machineRef)
+	if err != nil {
+		return nil, err
+	}
+	return append([]vboxapi.CPUIDLeaf(nil), m.CPUIDLeaves...), nil
+}
+
+func (f *fakeAPI) SetCPUIDLeaf(_ context.Context, mutableMachineRef string, leaf vboxapi.CPUIDLeaf) error {
+	f.record("SetCPUIDLeaf")
+	m, err := f.x86Machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	for i, l := range m.CPUIDLeaves {
+		if l.Leaf == leaf.Leaf && l.SubLeaf == leaf.SubLeaf {
+			m.CPUIDLeaves[i] = leaf
+			return nil
+		}
+	}
+	m.CPUIDLeaves = append(m.CPUIDLeaves, leaf)
+	return nil
+}
+
+func (f *fakeAPI) RemoveCPUIDLeaf(_ context.Context, mutableMachineRef string, leaf, subLeaf uint32) error {
+	f.record("RemoveCPUIDLeaf")
+	m, err := f.x86Machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.CPUIDLeaves = slices.DeleteFunc(m.CPUIDLeaves, func(l vboxapi.CPUIDLeaf) bool {
+		return l.Leaf == leaf && l.SubLeaf == subLeaf
+	})
+	return nil
+}
+
+func (f *fakeAPI) GetGraphicsController(_ context.Context, machineRef string) (vboxapi.GraphicsController, error) {
+	f.record("GetGraphicsController")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	return m.GraphicsController, nil
+}
+
+func (f *fakeAPI) SetGraphicsController(_ context.Context, mutableMachineRef string, controller vboxapi.GraphicsController) error {
+	f.record("SetGraphicsController")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.GraphicsController = controller
+	return nil
+}
+
+func (f *fakeAPI) GetVRAMSize(_ context.Context, machineRef string) (uint32, error) {
+	f.record("GetVRAMSize")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return 0, err
+	}
+	return m.VRAMMB, nil
+}
+
+func (f *fakeAPI) SetVRAMSize(_ context.Context, mutableMachineRef string, vramMB uint32) error {
+	f.record("SetVRAMSize")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.VRAMMB = vramMB
+	return nil
+}
+
+func (f *fakeAPI) GetGuestVRAMLimits(_ context.Context, _ string) (uint32, uint32, error) {
+	f.record("GetGuestVRAMLimits")
+	return 1, 256, nil
+}
+
+func (f *fakeAPI) GetPageFusionEnabled(_ context.Context, machineRef string) (bool, error) {
+	f.record("GetPageFusionEnabled")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return false, err
+	}
+	return m.PageFusion, nil
+}
+
+func (f *fakeAPI) SetPageFusionEnabled(_ context.Context, mutableMachineRef string, enabled bool) error {
+	f.record("SetPageFusionEnabled")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.PageFusion = enabled
+	return nil
+}
+
+func (f *fakeAPI) GetAccelerate3DEnabled(_ context.Context, machineRef string) (bool, error) {
+	f.record("GetAccelerate3DEnabled")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return false, err
+	}
+	return m.Accelerate3D, nil
+}
+
+func (f *fakeAPI) SetAccelerate3DEnabled(_ context.Context, mutableMachineRef string, enabled bool) error {
+	f.record("SetAccelerate3DEnabled")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.Accelerate3D = enabled
+	return nil
+}
+
+func (f *fakeAPI) GetMonitorCount(_ context.Context, machineRef string) (uint32, error) {
+	f.record("GetMonitorCount")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return 0, err
+	}
+	return m.MonitorCount, nil
+}
+
+func (f *fakeAPI) SetMonitorCount(_ context.Context, mutableMachineRef string, count uint32) error {
+	f.record("SetMonitorCount")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.MonitorCount = count
+	return nil
+}
+
+func (f *fakeAPI) GetAPICMode(_ context.Context, machineRef string) (vboxapi.APICMode, error) {
+	f.record("GetAPICMode")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	return m.APICMode, nil
+}
+
+func (f *fakeAPI) SetAPICMode(_ context.Context, mutableMachineRef string, mode vboxapi.APICMode) error {
+	f.record("SetAPICMode")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.APICMode = mode
+	return nil
+}
+
+func (f *fakeAPI) GetBootMenuMode(_ context.Context, machineRef string) (vboxapi.BootMenuMode, error) {
+	f.record("GetBootMenuMode")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	return m.BootMenuMode, nil
+}
+
+func (f *fakeAPI) SetBootMenuMode(_ context.Context, mutableMachineRef string, mode vboxapi.BootMenuMode) error {
+	f.record("SetBootMenuMode")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.BootMenuMode = mode
+	return nil
+}
+
+func (f *fakeAPI) GetAutostartEnabled(_ context.Context, machineRef string) (bool, error) {
+	f.record("GetAutostartEnabled")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return false, err
+	}
+	return m.AutostartEnabled, nil
+}
+
+func (f *fakeAPI) SetAutostartEnabled(_ context.Context, mutableMachineRef string, enabled bool) error {
+	f.record("SetAutostartEnabled")
+	if f.autostartErr != nil {
+		return f.autostartErr
+	}
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.AutostartEnabled = enabled
+	return nil
+}
+
+func (f *fakeAPI) GetAutostartDelay(_ context.Context, machineRef string) (uint32, error) {
+	f.record("GetAutostartDelay")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return 0, err
+	}
+	return m.AutostartDelay, nil
+}
+
+func (f *fakeAPI) SetAutostartDelay(_ context.Context, mutableMachineRef string, seconds uint32) error {
+	f.record("SetAutostartDelay")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.AutostartDelay = seconds
+	return nil
+}
+
+func (f *fakeAPI) GetClipboardFileTransfersEnabled(_ context.Context, machineRef string) (bool, error) {
+	f.record("GetClipboardFileTransfersEnabled")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return false, err
+	}
+	return m.ClipboardFileTransfers, nil
+}
+
+func (f *fakeAPI) SetClipboardFileTransfersEnabled(_ context.Context, mutableMachineRef string, enabled bool) error {
+	f.record("SetClipboardFileTransfersEnabled")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.ClipboardFileTransfers = enabled
+	return nil
+}
+
+func (f *fakeAPI) GetRecordingEnabled(_ context.Context, machineRef string) (bool, error) {
+	f.record("GetRecordingEnabled")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return false, err
+	}
+	return m.RecordingEnabled, nil
+}
+
+func (f *fakeAPI) SetRecordingEnabled(_ context.Context, mutableMachineRef string, enabled bool) error {
+	f.record("SetRecordingEnabled")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m.RecordingEnabled = enabled
+	return nil
+}
+
+func (f *fakeAPI) GetRecordingScreenSettings(_ context.Context, machineRef string) ([]vboxapi.RecordingScreenSettings, error) {
+	f.record("GetRecordingScreenSettings")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(m.RecordingScreens), nil
+}
+
+// recordingScreen returns the recording settings of a screen of the machine.
+func (f *fakeAPI) recordingScreen(machineRef string, screenID uint32) (*vboxapi.RecordingScreenSettings, error) {
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return nil, err
+	}
+	for i := range m.RecordingScreens {
+		if m.RecordingScreens[i].ID == screenID {
+			return &m.RecordingScreens[i], nil
+		}
+	}
+	return nil, fmt.Errorf("screen %d not found", screenID)
+}
+
+func (f *fakeAPI) SetRecordingScreenEnabled(_ context.Context, mutableMachineRef string, screenID uint32, enabled bool) error {
+	f.record("SetRecordingScreenEnabled")
+	screen, err := f.recordingScreen(mutableMachineRef, screenID)
+	if err != nil {
+		return err
+	}
+	screen.Enabled = enabled
+	return nil
+}
+
+func (f *fakeAPI) SetRecordingScreenFilename(_ context.Context, mutableMachineRef string, screenID uint32, filename string) error {
+	f.record("SetRecordingScreenFilename")
+	screen, err := f.recordingScreen(mutableMachineRef, screenID)
+	if err != nil {
+		return err
+	}
+	screen.Filename = filename
+	return nil
+}
+
+func (f *fakeAPI) SetRecordingScreenVideoCodec(_ context.Context, mutableMachineRef string, screenID uint32, codec vboxapi.RecordingVideoCodec) error {
+	f.record("SetRecordingScreenVideoCodec")
+	screen, err := f.recordingScreen(mutableMachineRef, screenID)
+	if err != nil {
+		return err
+	}
+	screen.VideoCodec = codec
+	return nil
+}
+
+func (f *fakeAPI) SetRecordingScreenVideoFPS(_ context.Context, mutableMachineRef string, screenID uint32, fps uint32) error {
+	f.record("SetRecordingScreenVideoFPS")
+	screen, err := f.recordingScreen(mutableMachineRef, screenID)
+	if err != nil {
+		return err
+	}
+	screen.VideoFPS = fps
+	return nil
+}
+
+func (f *fakeAPI) GetSupportedRecordingVideoCodecs(_ context.Context, _ string) ([]vboxapi.RecordingVideoCodec, error) {
+	f.record("GetSupportedRecordingVideoCodecs")
+	return []vboxapi.RecordingVideoCodec{vboxapi.RecordingVideoCodecVP8, vboxapi.RecordingVideoCodecVP9}, nil
+}
+
+func (f *fakeAPI) GetHWVirtExProperty(_ context.Context, machineRef string, property vboxapi.HWVirtExProperty) (bool, error) {
+	f.record("GetHWVirtExProperty")
+	m, err := f.x86Machine(machineRef)
+	if err != nil {
+		return false, err
+	}
+	return m.HWVirtExProperties[property], nil
+}
+
+func (f *fakeAPI) GetCPUProperty(_ context.Context, machineRef string, property vboxapi.CPUProperty) (bool, error) {
+	f.record("GetCPUProperty")
+	m, err := f.x86Machine(machineRef)
+	if err != nil {
+		return false, err
+	}
+	return m.CPUProperties[property], nil
+}
+
+func (f *fakeAPI) SetCPUProperty(_ context.Context, mutableMachineRef string, property vboxapi.CPUProperty, enabled bool) error {
+	f.record("SetCPUProperty")
+	m, err := f.x86Machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	if m.CPUProperties == nil {
+		m.CPUProperties = make(map[vboxapi.CPUProperty]bool)
+	}
+	m.CPUProperties[property] = enabled
+	return nil
+}
+
+func (f *fakeAPI) GetMaxGuestMonitors(_ context.Context, _ string) (uint32, error) {
+	f.record("GetMaxGuestMonitors")
+	return 64, nil
+}
+
+func (f *fakeAPI) GetGuestRAMLimits(_ context.Context, _ string) (uint32, uint32, error) {
+	f.record("GetGuestRAMLimits")
+	return 4, 2097152, nil
+}
+
+func (f *fakeAPI) GetGuestCPUCountLimits(_ context.Context, _ string) (uint32, uint32, error) {
+	f.record("GetGuestCPUCountLimits")
+	return 1, 64, nil
+}
+
+// CloneTo copies the source's disks into the target's folder. Like
+// VirtualBox, it names them after the target unless KeepDiskNames is set.
+func (f *fakeAPI) CloneTo(_ context.Context, srcRef, targetRef, mode string, options []string) (string, error) {
+	f.record("CloneTo")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cloneMode = mode
+	f.cloneOptions = append([]string(nil), options...)
+
+	src, target := f.machines[srcRef], f.machines[targetRef]
+	if src == nil || target == nil {
+		return "progress-clone", nil
+	}
+	for i, diskRef := range src.Disks {
+		disk := f.media[diskRef]
+		if disk == nil {
+			continue
+		}
+		name := target.Name + path.Ext(disk.Location)
+		if i > 0 {
+			name = fmt.Sprintf("%s-disk%d%s", target.Name, i, path.Ext(disk.Location))
+		}
+		if slices.Contains(options, "KeepDiskNames") {
+			name = path.Base(disk.Location)
+		}
+		ref := fmt.Sprintf("%s-disk%d", targetRef, i)
+		f.media[ref] = &fakeMedium{
+			ID:         "uuid-" + ref,
+			Location:   path.Join("/vms", target.Name, name),
+			Type:       vboxapi.MediumTypeNormal,
+			MachineIDs: []string{target.ID},
+		}
+		target.Disks = append(target.Disks, ref)
+	}
+	return "progress-clone", nil
+}
+
+func (f *fakeAPI) GetProgressCompleted(_ context.Context, progressRef string) (bool, error) {
+	f.record("GetProgressCompleted")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if p, ok := f.progresses[progressRef]; ok {
+		if p.PendingPolls > 0 {
+			p.PendingPolls--
+			return false, nil
+		}
+		return true, nil
+	}
+	return len(f.progressOps) == 0, nil
+}
+
+func (f *fakeAPI) GetProgressOperationDescription(_ context.Context, progressRef string) (string, error) {
+	f.record("GetProgressOperationDescription")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if p, ok := f.progresses[progressRef]; ok {
+		return p.Operation, nil
+	}
+	if len(f.progressOps) == 0 {
+		return "", nil
+	}
+	desc := f.progressOps[0]
+	f.progressOps = f.progressOps[1:]
+	return desc, nil
+}
+
+func (f *fakeAPI) GetProgressResultCode(_ context.Context, progressRef string) (int32, error) {
+	f.record("GetProgressResultCode")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if p, ok := f.progresses[progressRef]; ok {
+		return p.ResultCode, nil
+	}
+	return f.progressResultCode, nil
+}
+
+func (f *fakeAPI) GetProgressErrorText(_ context.Context, progressRef string) (string, error) {
+	f.record("GetProgressErrorText")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if p, ok := f.progresses[progressRef]; ok {
+		return p.ErrorText, nil
+	}
+	return f.progressErrorText, nil
+}
+
+// progress returns the progress with the given ref from progresses.
+func (f *fakeAPI) progress(progressRef string) (*fakeProgress, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, ok := f.progresses[progressRef]
+	if !ok {
+		return nil, &vboxapi.Error{Operation: "IProgress_getDescription", Text: "Invalid managed object reference \"" + progressRef + "\"", ResultCode: vboxapi.ResultInvalidArg}
+	}
+	return p, nil
+}
+
+func (f *fakeAPI) GetProgressDescription(_ context.Context, progressRef string) (string, error) {
+	f.record("GetProgressDescription")
+	p, err := f.progress(progressRef)
+	if err != nil {
+		return "", err
+	}
+	return p.Description, nil
+}
+
+func (f *fakeAPI) GetProgressPercent(_ context.Context, progressRef string) (uint32, error) {
+	f.record("GetProgressPercent")
+	p, err := f.progress(progressRef)
+	if err != nil {
+		return 0, err
+	}
+	if p.PendingPolls == 0 {
+		return 100, nil
+	}
+	return p.Percent, nil
+}
+
+func (f *fakeAPI) GetProgressCancelable(_ context.Context, progressRef string) (bool, error) {
+	f.record("GetProgressCancelable")
+	p, err := f.progress(progressRef)
+	if err != nil {
+		return false, err
+	}
+	return p.Cancelable, nil
+}
+
+func (f *fakeAPI) CancelProgress(_ context.Context, progressRef string) error {
+	f.record("CancelProgress")
+	p, err := f.progress(progressRef)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p.Canceled = true
+	return nil
+}
+
+func (f *fakeAPI) LockMachine(_ context.Context, machineRef, _ string, shared bool) error {
+	f.record("LockMachine")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lockedMachine = machineRef
+	f.lockShared = shared
+	return nil
+}
+
+func (f *fakeAPI) UnlockSession(_ context.Context, _ string) error {
+	f.record("UnlockSession")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.unlockErr
+}
+
+// GetMutableMachine returns the ref of the locked machine, so that the
+// fake's per-machine state is shared between mutable and immutable refs.
+func (f *fakeAPI) GetMutableMachine(_ context.Context, _ string) (string, error) {
+	f.record("GetMutableMachine")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lockedMachine, nil
+}
+
+func (f *fakeAPI) SaveSettings(_ context.Context, _ string) error {
+	f.record("SaveSettings")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.saveErrs) > 0 {
+		err := f.saveErrs[0]
+		f.saveErrs = f.saveErrs[1:]
+		return err
+	}
+	return nil
+}
+
+func (f *fakeAPI) OpenMedium(_ context.Context, _, location string, _ vboxapi.DeviceType, accessMode vboxapi.AccessMode) (string, error) {
+	f.record("OpenMedium")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.openAccessMode = accessMode
+	for ref, m := range f.media {
+		if m.Location == location {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("Could not find file for the medium '%s'", location)
+}
+
+func (f *fakeAPI) GetMediumId(_ context.Context, mediumRef string) (string, error) {
+	f.record("GetMediumId")
+	m, err := f.medium(mediumRef)
+	if err != nil {
+		return "", err
+	}
+	return m.ID, nil
+}
+
+func (f *fakeAPI) GetMediumLocation(_ context.Context, mediumRef string) (string, error) {
+	f.record("GetMediumLocation")
+	m, err := f.medium(mediumRef)
+	if err != nil {
+		return "", err
+	}
+	return m.Location, nil
+}
+
+func (f *fakeAPI) GetMediumType(_ context.Context, mediumRef string) (vboxapi.MediumType, error) {
+	f.record("GetMediumType")
+	m, err := f.medium(mediumRef)
+	if err != nil {
+		return "", err
+	}
+	return m.Type, nil
+}
+
+func (f *fakeAPI) SetMediumType(_ context.Context, mediumRef string, mediumType vboxapi.MediumType) error {
+	f.record("SetMediumType")
+	m, err := f.medium(mediumRef)
+	if err != nil {
+		return err
+	}
+	m.Type = mediumType
+	return nil
+}
+
+func (f *fakeAPI) GetMediumVariant(_ context.Context, mediumRef string) ([]string, error) {
+	f.record("GetMediumVariant")
+	m, err := f.medium(mediumRef)
+	if err != nil {
+		return nil, err
+	}
+	return m.Variants, nil
+}
+
+func (f *fakeAPI) GetMediumFormat(_ context.Context, mediumRef string) (string, error) {
+	f.record("GetMediumFormat")
+	m, err := f.medium(mediumRef)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(strings.TrimPrefix(path.Ext(m.Location), ".")), nil
+}
+
+func (f *fakeAPI) CreateMedium(_ context.Context, _, _, location string, _ vboxapi.DeviceType) (string, error) {
+	f.record("CreateMedium")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ref := fmt.Sprintf("medium-created-%d", f.calls["CreateMedium"])
+	f.media[ref] = &fakeMedium{ID: "uuid-" + ref, Location: location, Type: vboxapi.MediumTypeNormal}
+	return ref, nil
+}
+
+// CloneMediumToBase gives the target the medium's machines, as if it was
+// attached in the medium's place right away.
+func (f *fakeAPI) CloneMediumToBase(_ context.Context, mediumRef, targetRef string, variants []string) (string, error) {
+	f.record("CloneMediumToBase")
+	m, err := f.medium(mediumRef)
+	if err != nil {
+		return "", err
+	}
+	target, err := f.medium(targetRef)
+	if err != nil {
+		return "", err
+	}
+	target.Variants = append([]string(nil), variants...)
+	target.MachineIDs = m.MachineIDs
+	return "progress-clonemedium", nil
+}
+
+func (f *fakeAPI) MoveMedium(_ context.Context, mediumRef, location string) (string, error) {
+	f.record("MoveMedium")
+	m, err := f.medium(mediumRef)
+	if err != nil {
+		return "", err
+	}
+	m.Location = location
+	return "progress-movemedium", nil
+}
+
+func (f *fakeAPI) DeleteMediumStorage(_ context.Context, mediumRef string) (string, error) {
+	f.record("DeleteMediumStorage")
+	if _, err := f.medium(mediumRef); err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.media, mediumRef)
+	return "progress-deletemedium", nil
+}
+
+func (f *fakeAPI) GetMediumBase(_ context.Context, mediumRef string) (string, error) {
+	f.record("GetMediumBase")
+	for {
+		m, err := f.medium(mediumRef)
+		if err != nil {
+			return "", err
+		}
+		if m.Parent == "" {
+			return mediumRef, nil
+		}
+		mediumRef = m.Parent
+	}
+}
+
+func (f *fakeAPI) GetMediumChildren(_ context.Context, mediumRef string) ([]string, error) {
+	f.record("GetMediumChildren")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var children []string
+	for ref, m := range f.media {
+		if m.Parent == mediumRef {
+			children = append(children, ref)
+		}
+	}
+	slices.Sort(children)
+	return children, nil
+}
+
+func (f *fakeAPI) GetMediumMachineIds(_ context.Context, mediumRef string) ([]string, error) {
+	f.record("GetMediumMachineIds")
+	m, err := f.medium(mediumRef)
+	if err != nil {
+		return nil, err
+	}
+	return m.MachineIDs, nil
+}
+
+func (f *fakeAPI) GetMediumAttachments(_ context.Context, machineRef string) ([]vboxapi.MediumAttachment, error) {
+	f.record("GetMediumAttachments")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return nil, err
+	}
+	atts := make([]vboxapi.MediumAttachment, 0, len(m.Disks)+len(m.Attachments))
+	for i, ref := range m.Disks {
+		atts = append(atts, vboxapi.MediumAttachment{Controller: "SATA", Port: int32(i), Type: vboxapi.DeviceTypeHardDisk, MediumRef: ref})
+	}
+	return append(atts, m.Attachments...), nil
+}
+
+func (f *fakeAPI) AttachDevice(_ context.Context, _, controller string, port, device int32, _ vboxapi.DeviceType, mediumRef string) error {
+	f.record("AttachDevice")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attachments[attachmentKey(controller, port, device)] = mediumRef
+	return nil
+}
+
+func (f *fakeAPI) AttachDeviceWithoutMedium(_ context.Context, _, controller string, port, device int32, _ vboxapi.DeviceType) error {
+	f.record("AttachDeviceWithoutMedium")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attachments[attachmentKey(controller, port, device)] = ""
+	return nil
+}
+
+func (f *fakeAPI) DetachDevice(_ context.Context, _, controller string, port, device int32) error {
+	f.record("DetachDevice")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.attachments, attachmentKey(controller, port, device))
+	return nil
+}
+
+func (f *fakeAPI) MountMedium(_ context.Context, _, controller string, port, device int32, mediumRef string, _ bool) error {
+	f.record("MountMedium")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := attachmentKey(controller, port, device)
+	if _, ok := f.attachments[key]; !ok {
+		return fmt.Errorf("No storage device attached to device slot %d on port %d of controller '%s'", device, port, controller)
+	}
+	f.attachments[key] = mediumRef
+	return nil
+}
+
+func (f *fakeAPI) GetStorageControllerByName(_ context.Context, _, name string) (string, error) {
+	f.record("GetStorageControllerByName")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.controllers[name]; !ok {
+		return "", fmt.Errorf("Could not find a storage controller named '%s'", name)
+	}
+	return "controller-" + name, nil
+}
+
+func (f *fakeAPI) GetStorageControllerType(_ context.Context, controllerRef string) (vboxapi.StorageControllerType, error) {
+	f.record("GetStorageControllerType")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.controllers[strings.TrimPrefix(controllerRef, "controller-")]
+	if !ok {
+		return "", fmt.Errorf("object not found: %s", controllerRef)
+	}
+	return t, nil
+}
+
+func (f *fakeAPI) GetStorageControllers(_ context.Context, _ string) ([]vboxapi.StorageController, error) {
+	f.record("GetStorageControllers")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []vboxapi.StorageController
+	for name, t := range f.controllers {
+		out = append(out, vboxapi.StorageController{Name: name, Bus: f.controllerBuses[name], Type: t, PortCount: f.controllerPorts[name], UseHostIOCache: f.controllerIOCache[name], Bootable: f.controllerBootable[name]})
+	}
+	slices.SortFunc(out, func(a, b vboxapi.StorageController) int { return strings.Compare(a.Name, b.Name) })
+	return out, nil
+}
+
+func (f *fakeAPI) AddStorageController(_ context.Context, _, name string, bus vboxapi.StorageBus) (string, error) {
+	f.record("AddStorageController")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.addControllerErr != nil {
+		return "", f.addControllerErr
+	}
+	if _, ok := f.controllers[name]; ok {
+		return "", fmt.Errorf("Storage controller named '%s' already exists", name)
+	}
+	// Like VirtualBox, the first controller of a bus is bootable.
+	bootable := true
+	for other, otherBus := range f.controllerBuses {
+		if otherBus == bus && f.controllerBootable[other] {
+			bootable = false
+		}
+	}
+	f.controllers[name] = ControllerTypesForBus(bus)[0]
+	f.controllerBuses[name] = bus
+	f.controllerPorts[name] = 1
+	f.controllerBootable[name] = bootable
+	return "controller-" + name, nil
+}
+
+func (f *fakeAPI) RemoveStorageController(_ context.Context, _, name string) error {
+	f.record("RemoveStorageController")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.controllers[name]; !ok {
+		return fmt.Errorf("Could not find a storage controller named '%s'", name)
+	}
+	delete(f.controllers, name)
+	delete(f.controllerBuses, name)
+	delete(f.controllerPorts, name)
+	delete(f.controllerIOCache, name)
+	delete(f.controllerBootable, name)
+	return nil
+}
+
+func (f *fakeAPI) SetStorageControllerType(_ context.Context, controllerRef string, controllerType vboxapi.StorageControllerType) error {
+	f.record("SetStorageControllerType")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.controllers[strings.TrimPrefix(controllerRef, "controller-")] = controllerType
+	return nil
+}
+
+func (f *fakeAPI) SetStorageControllerPortCount(_ context.Context, controllerRef string, portCount uint32) error {
+	f.record("SetStorageControllerPortCount")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.controllerPorts[strings.TrimPrefix(controllerRef, "controller-")] = portCount
+	return nil
+}
+
+func (f *fakeAPI) SetStorageControllerUseHostIOCache(_ context.Context, controllerRef string, enabled bool) error {
+	f.record("SetStorageControllerUseHostIOCache")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.controllerIOCache[strings.TrimPrefix(controllerRef, "controller-")] = enabled
+	return nil
+}
+
+func (f *fakeAPI) GetStorageControllerBootable(_ context.Context, controllerRef string) (bool, error) {
+	f.record("GetStorageControllerBootable")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.controllerBootable[strings.TrimPrefix(controllerRef, "controller-")], nil
+}
+
+func (f *fakeAPI) SetStorageControllerBootable(_ context.Context, _, name string, bootable bool) error {
+	f.record("SetStorageControllerBootable")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.controllers[name]
+	if !ok {
+		return fmt.Errorf("Could not find a storage controller named '%s'", name)
+	}
+	// Like VirtualBox, only one controller of a type stays bootable.
+	if bootable {
+		for other, otherType := range f.controllers {
+			if other != name && otherType == t {
+				f.controllerBootable[other] = false
+			}
+		}
+	}
+	f.controllerBootable[name] = bootable
+	return nil
+}
+
+func (f *fakeAPI) GetMaxNetworkAdapters(_ context.Context, machineRef string) (uint32, error) {
+	f.record("GetMaxNetworkAdapters")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return 0, err
+	}
+	return m.MaxAdapters, nil
+}
+
+// Adapter refs are "<machineRef>/nic<slot>" and NAT engine refs append "/nat".
+func (f *fakeAPI) GetNetworkAdapter(_ context.Context, machineRef string, slot uint32) (string, error) {
+	f.record("GetNetworkAdapter")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	if slot >= m.MaxAdapters {
+		return "", &vboxapi.Error{
+			Operation:  "IMachine_getNetworkAdapter",
+			Text:       fmt.Sprintf("Invalid slot number: %d (must be in range [0, %d])", slot, m.MaxAdapters-1),
+			ResultCode: vboxapi.ResultInvalidArg,
+		}
+	}
+	return fmt.Sprintf("%s/nic%d", machineRef, slot), nil
+}
+
+// adapter returns the machine and slot of an adapter ref.
+func (f *fakeAPI) adapter(adapterRef string) (*fakeMachine, uint32, error) {
+	machineRef, rest, ok := strings.Cut(adapterRef, "/nic")
+	if !ok {
+		return nil, 0, fmt.Errorf("object not found: %s", adapterRef)
+	}
+	var slot uint32
+	if _, err := fmt.Sscanf(rest, "%d", &slot); err != nil {
+		return nil, 0, fmt.Errorf("object not found: %s", adapterRef)
+	}
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return nil, 0, err
+	}
+	return m, slot, nil
+}
+
+func (f *fakeAPI) GetAdapterEnabled(_ context.Context, adapterRef string) (bool, error) {
+	f.record("GetAdapterEnabled")
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return false, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !m.DisabledAdapters[slot], nil
+}
+
+func (f *fakeAPI) GetAdapterAttachmentType(_ context.Context, adapterRef string) (vboxapi.NetworkAttachmentType, error) {
+	f.record("GetAdapterAttachmentType")
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t, ok := m.AttachmentTypes[slot]; ok {
+		return t, nil
+	}
+	return vboxapi.NetworkAttachmentTypeNAT, nil
+}
+
+func (f *fakeAPI) SetAdapterEnabled(_ context.Context, adapterRef string, enabled bool) error {
+	f.record("SetAdapterEnabled")
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if m.DisabledAdapters == nil {
+		m.DisabledAdapters = make(map[uint32]bool)
+	}
+	m.DisabledAdapters[slot] = !enabled
+	return nil
+}
+
+func (f *fakeAPI) SetAdapterAttachmentType(_ context.Context, adapterRef string, attachment vboxapi.NetworkAttachmentType) error {
+	f.record("SetAdapterAttachmentType")
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if m.AttachmentTypes == nil {
+		m.AttachmentTypes = make(map[uint32]vboxapi.NetworkAttachmentType)
+	}
+	m.AttachmentTypes[slot] = attachment
+	return nil
+}
+
+func (f *fakeAPI) SetAdapterCableConnected(_ context.Context, adapterRef string, connected bool) error {
+	f.record("SetAdapterCableConnected")
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if m.DisconnectedCables == nil {
+		m.DisconnectedCables = make(map[uint32]bool)
+	}
+	m.DisconnectedCables[slot] = !connected
+	return nil
+}
+
+// adapterString returns the value for slot in the map field picks from m.
+func (f *fakeAPI) adapterString(op, adapterRef string, field func(m *fakeMachine) map[uint32]string) (string, error) {
+	f.record(op)
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return field(m)[slot], nil
+}
+
+// setAdapterString sets the value for slot in the map field picks from m,
+// creating the map if needed.
+func (f *fakeAPI) setAdapterString(op, adapterRef, value string, field func(m *fakeMachine) *map[uint32]string) error {
+	f.record(op)
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values := field(m)
+	if *values == nil {
+		*values = make(map[uint32]string)
+	}
+	(*values)[slot] = value
+	return nil
+}
+
+func (f *fakeAPI) GetAdapterBridgedInterface(_ context.Context, adapterRef string) (string, error) {
+	return f.adapterString("GetAdapterBridgedInterface", adapterRef, func(m *fakeMachine) map[uint32]string { return m.BridgedInterfaces })
+}
+
+func (f *fakeAPI) SetAdapterBridgedInterface(_ context.Context, adapterRef, name string) error {
+	return f.setAdapterString("SetAdapterBridgedInterface", adapterRef, name, func(m *fakeMachine) *map[uint32]string { return &m.BridgedInterfaces })
+}
+
+func (f *fakeAPI) GetAdapterHostOnlyInterface(_ context.Context, adapterRef string) (string, error) {
+	return f.adapterString("GetAdapterHostOnlyInterface", adapterRef, func(m *fakeMachine) map[uint32]string { return m.HostOnlyInterfaces })
+}
+
+func (f *fakeAPI) SetAdapterHostOnlyInterface(_ context.Context, adapterRef, name string) error {
+	return f.setAdapterString("SetAdapterHostOnlyInterface", adapterRef, name, func(m *fakeMachine) *map[uint32]string { return &m.HostOnlyInterfaces })
+}
+
+func (f *fakeAPI) SetAdapterInternalNetwork(_ context.Context, adapterRef, name string) error {
+	return f.setAdapterString("SetAdapterInternalNetwork", adapterRef, name, func(m *fakeMachine) *map[uint32]string { return &m.InternalNetworks })
+}
+
+func (f *fakeAPI) GetAdapterNATNetwork(_ context.Context, adapterRef string) (string, error) {
+	return f.adapterString("GetAdapterNATNetwork", adapterRef, func(m *fakeMachine) map[uint32]string { return m.AdapterNATNetworks })
+}
+
+func (f *fakeAPI) SetAdapterNATNetwork(_ context.Context, adapterRef, name string) error {
+	return f.setAdapterString("SetAdapterNATNetwork", adapterRef, name, func(m *fakeMachine) *map[uint32]string { return &m.AdapterNATNetworks })
+}
+
+// MAC addresses are derived from the slot: 080027000000 + slot.
+func (f *fakeAPI) GetAdapterMACAddress(_ context.Context, adapterRef string) (string, error) {
+	f.record("GetAdapterMACAddress")
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if mac, ok := m.MACAddresses[slot]; ok {
+		return mac, nil
+	}
+	return fmt.Sprintf("0800270000%02X", slot), nil
+}
+
+func (f *fakeAPI) SetAdapterMACAddress(_ context.Context, adapterRef, mac string) error {
+	f.record("SetAdapterMACAddress")
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if m.MACAddresses == nil {
+		m.MACAddresses = make(map[uint32]string)
+	}
+	m.MACAddresses[slot] = mac
+	return nil
+}
+
+func (f *fakeAPI) GetAdapterCableConnected(_ context.Context, adapterRef string) (bool, error) {
+	f.record("GetAdapterCableConnected")
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return false, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !m.DisconnectedCables[slot], nil
+}
+
+func (f *fakeAPI) GetAdapterType(_ context.Context, adapterRef string) (vboxapi.NetworkAdapterType, error) {
+	f.record("GetAdapterType")
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t, ok := m.AdapterTypes[slot]; ok {
+		return t, nil
+	}
+	return vboxapi.NetworkAdapterTypeI82540EM, nil
+}
+
+func (f *fakeAPI) SetAdapterType(_ context.Context, adapterRef string, adapterType vboxapi.NetworkAdapterType) error {
+	f.record("SetAdapterType")
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if m.AdapterTypes == nil {
+		m.AdapterTypes = make(map[uint32]vboxapi.NetworkAdapterType)
+	}
+	m.AdapterTypes[slot] = adapterType
+	return nil
+}
+
+func (f *fakeAPI) GetAdapterLineSpeed(_ context.Context, adapterRef string) (uint32, error) {
+	f.record("GetAdapterLineSpeed")
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return 0, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return m.LineSpeeds[slot], nil
+}
+
+func (f *fakeAPI) SetAdapterLineSpeed(_ context.Context, adapterRef string, kbps uint32) error {
+	f.record("SetAdapterLineSpeed")
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if m.LineSpeeds == nil {
+		m.LineSpeeds = make(map[uint32]uint32)
+	}
+	m.LineSpeeds[slot] = kbps
+	return nil
+}
+
+func (f *fakeAPI) GetAdapterInternalNetwork(_ context.Context, adapterRef string) (string, error) {
+	f.record("GetAdapterInternalNetwork")
+	m, slot, err := f.adapter(adapterRef)
+	if err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return m.InternalNetworks[slot], nil
+}
+
+func (f *fakeAPI) GetNATEngine(_ context.Context, adapterRef string) (string, error) {
+	f.record("GetNATEngine")
+	return adapterRef + "/nat", nil
+}
+
+func (f *fakeAPI) GetNATNetworks(_ context.Context, _ string) ([]string, error) {
+	f.record("GetNATNetworks")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.natNetworksErr != nil {
+		return nil, f.natNetworksErr
+	}
+	var refs []string
+	for ref := range f.natNetworks {
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+func (f *fakeAPI) GetNATNetworkPortForwardRules4(_ context.Context, natNetworkRef string) ([]vboxapi.NATRedirect, error) {
+	f.record("GetNATNetworkPortForwardRules4")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rules, ok := f.natNetworks[natNetworkRef]
+	if !ok {
+		return nil, fmt.Errorf("object not found: %s", natNetworkRef)
+	}
+	return append([]vboxapi.NATRedirect(nil), rules...), nil
+}
+
+// NAT networks created with CreateNATNetwork have the ref
+// "natnet-<name>".
+func (f *fakeAPI) FindNATNetworkByName(_ context.Context, _, name string) (string, error) {
+	f.record("FindNATNetworkByName")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ref, network := range f.natNetworkSettings {
+		if network.Name == name {
+			return ref, nil
+		}
+	}
+	return "", &vboxapi.Error{
+		Operation:  "IVirtualBox_findNATNetworkByName",
+		Text:       "Could not find a NAT network named '" + name + "'",
+		ResultCode: vboxapi.ResultObjectNotFound,
+	}
+}
+
+func (f *fakeAPI) CreateNATNetwork(_ context.Context, _, name string) (string, error) {
+	f.record("CreateNATNetwork")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ref := "natnet-" + name
+	if _, ok := f.natNetworkSettings[ref]; ok {
+		return "", fmt.Errorf("NAT network %s already exists", name)
+	}
+	if f.natNetworkSettings == nil {
+		f.natNetworkSettings = make(map[string]vboxapi.NATNetwork)
+	}
+	if f.natNetworks == nil {
+		f.natNetworks = make(map[string][]vboxapi.NATRedirect)
+	}
+	f.natNetworkSettings[ref] = vboxapi.NATNetwork{Name: name}
+	f.natNetworks[ref] = nil
+	return ref, nil
+}
+
+func (f *fakeAPI) RemoveNATNetwork(_ context.Context, _, natNetworkRef string) error {
+	f.record("RemoveNATNetwork")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.natNetworkSettings[natNetworkRef]; !ok {
+		return fmt.Errorf("object not found: %s", natNetworkRef)
+	}
+	delete(f.natNetworkSettings, natNetworkRef)
+	delete(f.natNetworks, natNetworkRef)
+	return nil
+}
+
+func (f *fakeAPI) GetNATNetwork(_ context.Context, natNetworkRef string) (vboxapi.NATNetwork, error) {
+	f.record("GetNATNetwork")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	network, ok := f.natNetworkSettings[natNetworkRef]
+	if !ok {
+		return network, fmt.Errorf("object not found: %s", natNetworkRef)
+	}
+	return network, nil
+}
+
+func (f *fakeAPI) SetNATNetwork(_ context.Context, natNetworkRef string, network vboxapi.NATNetwork) error {
+	f.record("SetNATNetwork")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	current, ok := f.natNetworkSettings[natNetworkRef]
+	if !ok {
+		return fmt.Errorf("object not found: %s", natNetworkRef)
+	}
+	network.Name = current.Name
+	f.natNetworkSettings[natNetworkRef] = network
+	return nil
+}
+
+func (f *fakeAPI) AddNATNetworkPortForwardRule4(_ context.Context, natNetworkRef string, rule vboxapi.NATRedirect) error {
+	f.record("AddNATNetworkPortForwardRule4")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rules, ok := f.natNetworks[natNetworkRef]
+	if !ok {
+		return fmt.Errorf("object not found: %s", natNetworkRef)
+	}
+	for _, r := range rules {
+		if r.Name == rule.Name {
+			return fmt.Errorf("a NAT rule of this name already exists")
+		}
+	}
+	f.natNetworks[natNetworkRef] = append(rules, rule)
+	return nil
+}
+
+func (f *fakeAPI) RemoveNATNetworkPortForwardRule4(_ context.Context, natNetworkRef, name string) error {
+	f.record("RemoveNATNetworkPortForwardRule4")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rules, ok := f.natNetworks[natNetworkRef]
+	if !ok {
+		return fmt.Errorf("object not found: %s", natNetworkRef)
+	}
+	for i, r := range rules {
+		if r.Name == name {
+			f.natNetworks[natNetworkRef] = append(rules[:i:i], rules[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("a NAT rule of this name does not exist")
+}
+
+// natRules returns the machine and slot a NAT engine ref belongs to.
+func (f *fakeAPI) natRules(natEngineRef string) (*fakeMachine, uint32, error) {
+	machineRef, rest, ok := strings.Cut(natEngineRef, "/nic")
+	if !ok {
+		return nil, 0, fmt.Errorf("object not found: %s", natEngineRef)
+	}
+	var slot uint32
+	if _, err := fmt.Sscanf(rest, "%d/nat", &slot); err != nil {
+		return nil, 0, fmt.Errorf("object not found: %s", natEngineRef)
+	}
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return nil, 0, err
+	}
+	return m, slot, nil
+}
+
+func (f *fakeAPI) GetNATRedirects(_ context.Context, natEngineRef string) ([]vboxapi.NATRedirect, error) {
+	f.record("GetNATRedirects")
+	m, slot, err := f.natRules(natEngineRef)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]vboxapi.NATRedirect(nil), m.NATRedirects[slot]...), nil
+}
+
+func (f *fakeAPI) AddNATRedirect(_ context.Context, natEngineRef, name string, proto vboxapi.NATProtocol, hostIP string, hostPort uint16, guestIP string, guestPort uint16) error {
+	f.record("AddNATRedirect")
+	m, slot, err := f.natRules(natEngineRef)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range m.NATRedirects[slot] {
+		if r.Name == name {
+			return fmt.Errorf("A NAT rule of this name already exists")
+		}
+	}
+	m.NATRedirects[slot] = append(m.NATRedirects[slot], vboxapi.NATRedirect{
+		Name:      name,
+		Protocol:  proto,
+		HostIP:    hostIP,
+		HostPort:  hostPort,
+		GuestIP:   guestIP,
+		GuestPort: guestPort,
+	})
+	return nil
+}
+
+func (f *fakeAPI) RemoveNATRedirect(_ context.Context, natEngineRef, name string) error {
+	f.record("RemoveNATRedirect")
+	m, slot, err := f.natRules(natEngineRef)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rules := m.NATRedirects[slot]
+	for i, r := range rules {
+		if r.Name == name {
+			m.NATRedirects[slot] = append(rules[:i:i], rules[i+1:]...)
+			return nil
+		}
+	}
+	return &vboxapi.Error{Operation: "INATEngine_removeRedirect", ResultCode: vboxapi.ResultInvalidArg}
+}
+
+func (f *fakeAPI) GetSharedFolders(_ context.Context, machineRef string) ([]vboxapi.SharedFolder, error) {
+	f.record("GetSharedFolders")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]vboxapi.SharedFolder(nil), m.SharedFolders...), nil
+}
+
+func (f *fakeAPI) CreateSharedFolder(_ context.Context, mutableMachineRef string, folder vboxapi.SharedFolder) error {
+	f.record("CreateSharedFolder")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, sf := range m.SharedFolders {
+		if sf.Name == folder.Name {
+			return fmt.Errorf("Shared folder named '%s' already exists", folder.Name)
+		}
+	}
+	m.SharedFolders = append(m.SharedFolders, folder)
+	return nil
+}
+
+func (f *fakeAPI) RemoveSharedFolder(_ context.Context, mutableMachineRef, name string) error {
+	f.record("RemoveSharedFolder")
+	m, err := f.machine(mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, sf := range m.SharedFolders {
+		if sf.Name == name {
+			m.SharedFolders = append(m.SharedFolders[:i:i], m.SharedFolders[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("Could not find a shared folder named '%s'", name)
+}
+
+// setLockedState changes the state of the machine locked by the last
+// LockMachine call.
+func (f *fakeAPI) setLockedState(state string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m, ok := f.machines[f.lockedMachine]
+	if !ok {
+		return fmt.Errorf("object not found: %s", f.lockedMachine)
+	}
+	m.State = state
+	m.pendingStates = slices.Clone(m.TransientStates)
+	return nil
+}
+
+func (f *fakeAPI) LaunchVMProcess(_ context.Context, machineRef, _, _ string) (string, error) {
+	f.record("LaunchVMProcess")
+	if f.launchErr != nil {
+		return "", f.launchErr
+	}
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	m.State = vboxapi.MachineStateRunning
+	m.pendingStates = slices.Clone(m.TransientStates)
+	f.powerEvents = append(f.powerEvents, "start:"+machineRef)
+	f.mu.Unlock()
+	return "progress-launch", nil
+}
+
+func (f *fakeAPI) GetConsole(_ context.Context, _ string) (string, error) {
+	f.record("GetConsole")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.consoleErrors > 0 {
+		f.consoleErrors--
+		return "", fmt.Errorf("The session is not locked (session state: Unlocked)")
+	}
+	return "console", nil
+}
+
+func (f *fakeAPI) PutScancodes(_ context.Context, _ string, scancodes []int32) (uint32, error) {
+	f.record("PutScancodes")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scancodes = append(f.scancodes, scancodes...)
+	if f.keyboardQueue != 0 && int(f.keyboardQueue) < len(scancodes) {
+		return f.keyboardQueue, nil
+	}
+	return uint32(len(scancodes)), nil
+}
+
+func (f *fakeAPI) GetGuest(_ context.Context, _ string) (string, error) {
+	f.record("GetGuest")
+	return "guest", nil
+}
+
+func (f *fakeAPI) GetAdditionsRunLevel(_ context.Context, _ string) (vboxapi.AdditionsRunLevel, error) {
+	f.record("GetAdditionsRunLevel")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.additionsRunLevels) == 0 {
+		return vboxapi.AdditionsRunLevelNone, nil
+	}
+	level := f.additionsRunLevels[0]
+	if len(f.additionsRunLevels) > 1 {
+		f.additionsRunLevels = f.additionsRunLevels[1:]
+	}
+	return level, nil
+}
+
+func (f *fakeAPI) GetMemoryBalloonSize(_ context.Context, _ string) (uint32, error) {
+	f.record("GetMemoryBalloonSize")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.memoryBalloonMB, nil
+}
+
+func (f *fakeAPI) SetMemoryBalloonSize(_ context.Context, _ string, sizeMB uint32) error {
+	f.record("SetMemoryBalloonSize")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.memoryBalloonMB = sizeMB
+	return nil
+}
+
+func (f *fakeAPI) UpdateGuestAdditions(_ context.Context, _, source string) (string, error) {
+	f.record("UpdateGuestAdditions")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updateAdditionsSource = source
+	if f.updateAdditionsErr != nil {
+		return "", f.updateAdditionsErr
+	}
+	return "progress-additions", nil
+}
+
+func (f *fakeAPI) GetDefaultAdditionsISO(_ context.Context, _ string) (string, error) {
+	f.record("GetDefaultAdditionsISO")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.additionsISO, nil
+}
+
+func (f *fakeAPI) PowerDown(_ context.Context, _ string) (string, error) {
+	f.record("PowerDown")
+	if err := f.setLockedState(vboxapi.MachineStatePoweredOff); err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	f.powerEvents = append(f.powerEvents, "stop:"+f.lockedMachine)
+	f.mu.Unlock()
+	return "progress-powerdown", nil
+}
+
+func (f *fakeAPI) PowerButton(_ context.Context, _ string) error {
+	f.record("PowerButton")
+	f.mu.Lock()
+	f.powerEvents = append(f.powerEvents, "acpi:"+f.lockedMachine)
+	ignored := f.acpiIgnored
+	f.mu.Unlock()
+	if ignored {
+		return nil
+	}
+	return f.setLockedState(vboxapi.MachineStatePoweredOff)
+}
+
+func (f *fakeAPI) Pause(_ context.Context, _ string) error {
+	f.record("Pause")
+	return f.setLockedState(vboxapi.MachineStatePaused)
+}
+
+func (f *fakeAPI) Resume(_ context.Context, _ string) error {
+	f.record("Resume")
+	return f.setLockedState(vboxapi.MachineStateRunning)
+}
+
+func (f *fakeAPI) SaveState(_ context.Context, _ string) (string, error) {
+	f.record("SaveState")
+	if err := f.setLockedState(vboxapi.MachineStateSaved); err != nil {
+		return "", err
+	}
+	return "progress-savestate", nil
+}
+
+func (f *fakeAPI) DiscardSavedState(_ context.Context, _ string, _ bool) error {
+	f.record("DiscardSavedState")
+	return f.setLockedState(vboxapi.MachineStatePoweredOff)
+}
+
+func (f *fakeAPI) UnregisterMachine(_ context.Context, machineRef string) ([]string, error) {
+	f.record("UnregisterMachine")
+	m, err := f.machine(machineRef)
+	if err != nil {
+		return nil, err
+	}
+	if m.State == vboxapi.MachineStateSaved {
+		return nil, fmt.Errorf("Cannot unregister the machine '%s' because it is in the Saved state", m.Name)
+	}
+	m.Registered = false
+	return nil, nil
+}
+
+func (f *fakeAPI) DeleteConfig(_ context.Context, machineRef string, _ []string) (string, error) {
+	f.record("DeleteConfig")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.machines, machineRef)
+	return "progress-delete", nil
+}
+
+func (f *fakeAPI) GetBridgedInterfaces(_ context.Context, _ string) ([]string, error) {
+	f.record("GetBridgedInterfaces")
+	refs := make([]string, len(f.hostInterfaces))
+	for i := range f.hostInterfaces {
+		refs[i] = fmt.Sprintf("hostif-%d", i)
+	}
+	return refs, nil
+}
+
+func (f *fakeAPI) GetHostNetworkInterface(_ context.Context, interfaceRef string) (vboxapi.HostNetworkInterface, error) {
+	f.record("GetHostNetworkInterface")
+	var i int
+	if _, err := fmt.Sscanf(interfaceRef, "hostif-%d", &i); err != nil || i >= len(f.hostInterfaces) {
+		return vboxapi.HostNetworkInterface{}, fmt.Errorf("invalid host network interface %s", interfaceRef)
+	}
+	return f.hostInterfaces[i], nil
+}
@@ -0,0 +1,200 @@
+package vbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// InstallGuestAdditionsByID updates the Guest Additions of a running VM
+// from the Guest Additions ISO shipped with VirtualBox. The guest must
+// already run a version of the Guest Additions, which performs the update.
+// It waits up to timeout for them to start, and for the update to finish.
+func (c *Client) InstallGuestAdditionsByID(ctx context.Context, id string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = 20 * time.Minute
+	}
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		mRef, err := findMachine(ctx, api, session, id)
+		if err != nil {
+			return err
+		}
+		return c.installGuestAdditions(ctx, api, session, mRef, timeout)
+	})
+}
+
+func (c *Client) installGuestAdditions(ctx context.Context, api vboxapi.VBoxAPI, vboxSession, machineRef string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	st, err := api.GetMachineState(ctx, machineRef)
+	if err != nil {
+		return err
+	}
+	if st != vboxapi.MachineStateRunning {
+		return fmt.Errorf("cannot install Guest Additions: the VM must be running, but it is %s", st)
+	}
+
+	source, err := api.GetDefaultAdditionsISO(ctx, vboxSession)
+	if err != nil {
+		return err
+	}
+	if source == "" {
+		return fmt.Errorf("cannot install Guest Additions: the VirtualBox host has no Guest Additions ISO")
+	}
+
+	sessObj, err := api.GetSessionObject(ctx, vboxSession)
+	if err != nil {
+		return err
+	}
+	if err := api.LockMachine(ctx, machineRef, sessObj, true); err != nil {
+		return err
+	}
+	defer unlockSession(ctx, api, sessObj)
+
+	consoleRef, err := api.GetConsole(ctx, sessObj)
+	if err != nil {
+		return err
+	}
+	guestRef, err := api.GetGuest(ctx, consoleRef)
+	if err != nil {
+		return err
+	}
+
+	if err := c.waitAdditionsRunning(ctx, api, guestRef, deadline); err != nil {
+		return err
+	}
+
+	progressRef, err := api.UpdateGuestAdditions(ctx, guestRef, source)
+	if err != nil {
+		var vErr *vboxapi.Error
+		if errors.As(err, &vErr) && vErr.ResultCode == vboxapi.ResultNotSupported {
+			return fmt.Errorf("the guest does not support automatic Guest Additions updates; only Windows, Linux and Solaris guests with working Guest Additions do: %w", err)
+		}
+		return err
+	}
+	if err := c.waitProgress(ctx, api, progressRef, time.Until(deadline)); err != nil {
+		return fmt.Errorf("failed to install Guest Additions: %w", err)
+	}
+	return nil
+}
+
+// waitAdditionsRunning waits until the Guest Additions in the guest have
+// started their user-land services, which carry out updates. A freshly
+// started VM takes a while to boot that far.
+func (c *Client) waitAdditionsRunning(ctx context.Context, api vboxapi.VBoxAPI, guestRef string, deadline time.Time) error {
+	interval := c.progressPoll.initial()
+	for {
+		level, err := api.GetAdditionsRunLevel(ctx, guestRef)
+		if err != nil {
+			return err
+		}
+		if level == vboxapi.AdditionsRunLevelUserland || level == vboxapi.AdditionsRunLevelDesktop {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for the Guest Additions to start in the VM (run level %s); the guest must already have the Guest Additions installed", level)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		interval = c.progressPoll.next(interval)
+	}
+}
+
+// SetMemoryBalloonByID sets the memory balloon of a running VM: the guest
+// RAM, in MB, the Guest Additions hand back to the host. It waits up to
+// timeout for the Guest Additions to start, as they inflate the balloon.
+func (c *Client) SetMemoryBalloonByID(ctx context.Context, id string, sizeMB uint32, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = 20 * time.Minute
+	}
+	deadline := time.Now().Add(timeout)
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		mRef, err := findMachine(ctx, api, session, id)
+		if err != nil {
+			return err
+		}
+		return withGuest(ctx, api, session, mRef, "set the memory balloon", func(guestRef string) error {
+			current, err := api.GetMemoryBalloonSize(ctx, guestRef)
+			if err != nil {
+				return fmt.Errorf("failed to get memory balloon size: %w", err)
+			}
+			if current == sizeMB {
+				return nil
+			}
+			if err := c.waitAdditionsRunning(ctx, api, guestRef, deadline); err != nil {
+				return fmt.Errorf("cannot set the memory balloon: %w", err)
+			}
+			if err := api.SetMemoryBalloonSize(ctx, guestRef, sizeMB); err != nil {
+				return fmt.Errorf("failed to set memory balloon size to %d MB: %w", sizeMB, err)
+			}
+			return nil
+		})
+	})
+}
+
+// ReadMemoryBalloonByID returns the memory balloon size of a VM, in MB. ok
+// is false when the VM is not running, as only a running VM has a balloon.
+func (c *Client) ReadMemoryBalloonByID(ctx context.Context, id string) (sizeMB uint32, ok bool, err error) {
+	err = c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		mRef, err := findMachine(ctx, api, session, id)
+		if err != nil {
+			return err
+		}
+		st, err := api.GetMachineState(ctx, mRef)
+		if err != nil {
+			return err
+		}
+		if st != vboxapi.MachineStateRunning {
+			return nil
+		}
+		return withGuest(ctx, api, session, mRef, "read the memory balloon", func(guestRef string) error {
+			sizeMB, err = api.GetMemoryBalloonSize(ctx, guestRef)
+			if err != nil {
+				return fmt.Errorf("failed to get memory balloon size: %w", err)
+			}
+			ok = true
+			return nil
+		})
+	})
+	return sizeMB, ok, err
+}
+
+// withGuest runs fn on the guest of a running VM, which it locks shared.
+// action describes what fn does, for the error returned when the VM is
+// not running.
+func withGuest(ctx context.Context, api vboxapi.VBoxAPI, vboxSession, machineRef, action string, fn func(guestRef string) error) error {
+	st, err := api.GetMachineState(ctx, machineRef)
+	if err != nil {
+		return err
+	}
+	if st != vboxapi.MachineStateRunning {
+		return fmt.Errorf("cannot %s: the VM must be running, but it is %s", action, st)
+	}
+
+	sessObj, err := api.GetSessionObject(ctx, vboxSession)
+	if err != nil {
+		return err
+	}
+	if err := api.LockMachine(ctx, machineRef, sessObj, true); err != nil {
+		return err
+	}
+	defer unlockSession(ctx, api, sessObj)
+
+	consoleRef, err := api.GetConsole(ctx, sessObj)
+	if err != nil {
+		return err
+	}
+	guestRef, err := api.GetGuest(ctx, consoleRef)
+	if err != nil {
+		return err
+	}
+	return fn(guestRef)
+}
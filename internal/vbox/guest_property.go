@@ -0,0 +1,128 @@
+package vbox
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// GuestPropertyFlags are the flags a guest property accepts.
+var GuestPropertyFlags = []string{"TRANSIENT", "TRANSRESET", "RDONLYGUEST", "RDONLYHOST", "READONLY"}
+
+// SetGuestProperty sets a guest property of a VM. The machine is locked
+// shared, so that properties of running VMs, which guests usually read,
+// change live. A property that already has value and flags is left alone.
+func (c *Client) SetGuestProperty(ctx context.Context, machineID, name, value, flags string) error {
+	if name == "" {
+		return fmt.Errorf("guest property name must not be empty")
+	}
+	if value == "" {
+		return fmt.Errorf("guest property %s: value must not be empty; an empty value deletes the property", name)
+	}
+
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		// The machine may have been created in the same apply and not be
+		// registered yet.
+		machineRef, err := findRegisteredMachine(ctx, api, session, machineID, c.registrationWait)
+		if err != nil {
+			return err
+		}
+		return changeGuestProperty(ctx, api, session, machineRef, name, value, flags)
+	})
+}
+
+// ReadGuestProperty returns a guest property of a VM, or nil if the VM has
+// no such property.
+func (c *Client) ReadGuestProperty(ctx context.Context, machineID, name string) (*vboxapi.GuestProperty, error) {
+	var result *vboxapi.GuestProperty
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+		// The name narrows the listing down; it may hold wildcards that
+		// match other properties too.
+		props, err := api.EnumerateGuestProperties(ctx, machineRef, name)
+		if err != nil {
+			return fmt.Errorf("failed to list guest properties: %w", err)
+		}
+		for _, p := range props {
+			if p.Name == name {
+				result = &p
+				break
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// DeleteGuestProperty deletes a guest property of a VM. A property or VM
+// that does not exist is ignored.
+func (c *Client) DeleteGuestProperty(ctx context.Context, machineID, name string) error {
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			if IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		return changeGuestProperty(ctx, api, session, machineRef, name, "", "")
+	})
+}
+
+// changeGuestProperty sets a guest property, or deletes it when value is
+// empty, under a shared lock. It skips a property that already has value
+// and flags, so it can be replayed on a save conflict.
+func changeGuestProperty(ctx context.Context, api vboxapi.VBoxAPI, session, machineRef, name, value, flags string) error {
+	sessObj, err := api.GetSessionObject(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to get session object: %w", err)
+	}
+	if err := api.LockMachine(ctx, machineRef, sessObj, true); err != nil {
+		return fmt.Errorf("failed to lock machine: %w", err)
+	}
+	defer unlockSession(ctx, api, sessObj)
+
+	return saveWithRetry(ctx, api, machineRef, sessObj, true, func(mutableMachineRef string) error {
+		current, currentFlags, err := api.GetGuestProperty(ctx, mutableMachineRef, name)
+		if err != nil {
+			return fmt.Errorf("failed to get guest property %s: %w", name, err)
+		}
+		if current == value && (value == "" || GuestPropertyFlagsEqual(currentFlags, flags)) {
+			return nil
+		}
+		if err := api.SetGuestProperty(ctx, mutableMachineRef, name, value, flags); err != nil {
+			if value == "" {
+				return fmt.Errorf("failed to delete guest property %s: %w", name, err)
+			}
+			return fmt.Errorf("failed to set guest property %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// GuestPropertyFlagsEqual reports whether two guest property flag lists
+// hold the same flags. VirtualBox reports flags in its own order and
+// spelling, e.g. "TRANSIENT, RDONLYGUEST" for "rdonlyguest,transient".
+func GuestPropertyFlagsEqual(a, b string) bool {
+	return slices.Equal(guestPropertyFlags(a), guestPropertyFlags(b))
+}
+
+// guestPropertyFlags returns the flags of a flag list, upper case and
+// sorted. NONE is no flag.
+func guestPropertyFlags(flags string) []string {
+	var out []string
+	for _, f := range strings.Split(flags, ",") {
+		f = strings.ToUpper(strings.TrimSpace(f))
+		if f != "" && f != "NONE" && !slices.Contains(out, f) {
+			out = append(out, f)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
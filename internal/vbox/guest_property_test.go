@@ -0,0 +1,104 @@
+package vbox
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestGuestProperty(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+	ctx := context.Background()
+
+	if err := c.SetGuestProperty(ctx, "uuid-vm", "/ci/ip", "10.0.0.5", "rdonlyguest,transient"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !api.lockShared {
+		t.Error("expected the property to be set under a shared lock")
+	}
+
+	got, err := c.ReadGuestProperty(ctx, "uuid-vm", "/ci/ip")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := vboxapi.GuestProperty{Name: "/ci/ip", Value: "10.0.0.5", Flags: "RDONLYGUEST, TRANSIENT"}
+	if got == nil || *got != want {
+		t.Errorf("ReadGuestProperty() = %+v, want %+v", got, want)
+	}
+
+	// Setting the same value and flags again, however they are spelled,
+	// leaves the property alone.
+	if err := c.SetGuestProperty(ctx, "uuid-vm", "/ci/ip", "10.0.0.5", "TRANSIENT, RDONLYGUEST"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("SetGuestProperty"); n != 1 {
+		t.Errorf("SetGuestProperty called %d times, want 1", n)
+	}
+
+	// A value changed out of band is read back.
+	api.machines["machine-vm"].GuestProperties["/ci/ip"] = vboxapi.GuestProperty{Name: "/ci/ip", Value: "10.0.0.9"}
+	if got, err := c.ReadGuestProperty(ctx, "uuid-vm", "/ci/ip"); err != nil || got == nil || got.Value != "10.0.0.9" {
+		t.Errorf("ReadGuestProperty() = %+v, %v, want value 10.0.0.9", got, err)
+	}
+
+	if err := c.DeleteGuestProperty(ctx, "uuid-vm", "/ci/ip"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := c.ReadGuestProperty(ctx, "uuid-vm", "/ci/ip"); err != nil || got != nil {
+		t.Errorf("ReadGuestProperty() = %+v, %v, want no property", got, err)
+	}
+	if err := c.DeleteGuestProperty(ctx, "uuid-vm", "/ci/ip"); err != nil {
+		t.Errorf("unexpected error deleting a missing property: %v", err)
+	}
+	if err := c.DeleteGuestProperty(ctx, "missing", "/ci/ip"); err != nil {
+		t.Errorf("unexpected error deleting from a missing VM: %v", err)
+	}
+	if got := api.called("LockMachine"); got != api.called("UnlockSession") {
+		t.Errorf("LockMachine called %d times but UnlockSession %d times", got, api.called("UnlockSession"))
+	}
+}
+
+func TestReadGuestProperty_Wildcards(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", GuestProperties: map[string]vboxapi.GuestProperty{
+		"/ci/a": {Name: "/ci/a", Value: "a"},
+	}})
+	c := newTestClient(api)
+
+	// A name with wildcards only matches the property of that name.
+	if got, err := c.ReadGuestProperty(context.Background(), "uuid-vm", "/ci/*"); err != nil || got != nil {
+		t.Errorf("ReadGuestProperty() = %+v, %v, want no property", got, err)
+	}
+}
+
+func TestSetGuestProperty_EmptyValue(t *testing.T) {
+	c := newTestClient(newFakeAPI())
+
+	err := c.SetGuestProperty(context.Background(), "uuid-vm", "/ci/ip", "", "")
+	if err == nil || !strings.Contains(err.Error(), "value must not be empty") {
+		t.Errorf("error = %v, want the empty value to be rejected", err)
+	}
+}
+
+func TestGuestPropertyFlagsEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"", "", true},
+		{"", "NONE", true},
+		{"TRANSIENT, RDONLYGUEST", "rdonlyguest,transient", true},
+		{"TRANSIENT", "TRANSIENT,TRANSIENT", true},
+		{"TRANSIENT", "", false},
+		{"TRANSIENT", "TRANSRESET", false},
+	}
+	for _, tt := range tests {
+		if got := GuestPropertyFlagsEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("GuestPropertyFlagsEqual(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
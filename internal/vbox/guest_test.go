@@ -0,0 +1,167 @@
+package vbox
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestInstallGuestAdditionsByID(t *testing.T) {
+	const iso = "/usr/share/virtualbox/VBoxGuestAdditions.iso"
+	booting := []vboxapi.AdditionsRunLevel{
+		vboxapi.AdditionsRunLevelNone,
+		vboxapi.AdditionsRunLevelSystem,
+		vboxapi.AdditionsRunLevelUserland,
+	}
+
+	tests := []struct {
+		name      string
+		state     string
+		runLevels []vboxapi.AdditionsRunLevel
+		iso       string
+		updateErr error
+		rc        int32
+		errText   string
+		wantErr   string
+	}{
+		{
+			name:      "installs once the additions have started",
+			runLevels: booting,
+			iso:       iso,
+		},
+		{
+			name:    "VM not running",
+			state:   vboxapi.MachineStatePoweredOff,
+			iso:     iso,
+			wantErr: "the VM must be running, but it is PoweredOff",
+		},
+		{
+			name:      "no additions ISO on the host",
+			runLevels: booting,
+			wantErr:   "the VirtualBox host has no Guest Additions ISO",
+		},
+		{
+			name:    "additions never start",
+			iso:     iso,
+			wantErr: "timeout waiting for the Guest Additions to start in the VM (run level None)",
+		},
+		{
+			name:      "unsupported guest",
+			runLevels: booting,
+			iso:       iso,
+			updateErr: &vboxapi.Error{Interface: "IGuest", ResultCode: vboxapi.ResultNotSupported, Text: "Guest OS not supported"},
+			wantErr:   "the guest does not support automatic Guest Additions updates",
+		},
+		{
+			name:      "installer fails",
+			runLevels: booting,
+			iso:       iso,
+			rc:        vboxapi.ResultVMError,
+			errText:   "installer exited with code 1",
+			wantErr:   "failed to install Guest Additions: progress failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.state
+			if state == "" {
+				state = vboxapi.MachineStateRunning
+			}
+			api := newFakeAPI()
+			api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: state})
+			api.additionsRunLevels = tt.runLevels
+			api.additionsISO = tt.iso
+			api.updateAdditionsErr = tt.updateErr
+			api.progressResultCode = tt.rc
+			api.progressErrorText = tt.errText
+			c := newTestClient(api)
+
+			err := c.InstallGuestAdditionsByID(context.Background(), "uuid-vm", 50*time.Millisecond)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				if tt.errText != "" && !strings.Contains(err.Error(), tt.errText) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.errText)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := api.called("LockMachine"); got != api.called("UnlockSession") {
+				t.Errorf("LockMachine called %d times but UnlockSession %d times", got, api.called("UnlockSession"))
+			}
+			if tt.wantErr == "" {
+				if api.updateAdditionsSource != iso {
+					t.Errorf("UpdateGuestAdditions source = %q, want %q", api.updateAdditionsSource, iso)
+				}
+				if !api.lockShared {
+					t.Error("expected a shared lock on the running VM")
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryBalloon(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	api.additionsRunLevels = []vboxapi.AdditionsRunLevel{vboxapi.AdditionsRunLevelUserland}
+	c := newTestClient(api)
+	ctx := context.Background()
+
+	if err := c.SetMemoryBalloonByID(ctx, "uuid-vm", 512, time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !api.lockShared {
+		t.Error("expected a shared lock on the running VM")
+	}
+	size, ok, err := c.ReadMemoryBalloonByID(ctx, "uuid-vm")
+	if err != nil || !ok || size != 512 {
+		t.Errorf("ReadMemoryBalloonByID() = %d, %t, %v, want 512", size, ok, err)
+	}
+
+	// Setting the current size again leaves the balloon alone.
+	if err := c.SetMemoryBalloonByID(ctx, "uuid-vm", 512, time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("SetMemoryBalloonSize"); n != 1 {
+		t.Errorf("SetMemoryBalloonSize called %d times, want 1", n)
+	}
+	if got := api.called("LockMachine"); got != api.called("UnlockSession") {
+		t.Errorf("LockMachine called %d times but UnlockSession %d times", got, api.called("UnlockSession"))
+	}
+}
+
+func TestMemoryBalloon_Errors(t *testing.T) {
+	t.Run("no additions", func(t *testing.T) {
+		api := newFakeAPI()
+		api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+		c := newTestClient(api)
+
+		err := c.SetMemoryBalloonByID(context.Background(), "uuid-vm", 512, 50*time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "cannot set the memory balloon: timeout waiting for the Guest Additions") {
+			t.Fatalf("error = %v, want the Guest Additions to be missing", err)
+		}
+		if n := api.called("SetMemoryBalloonSize"); n != 0 {
+			t.Errorf("SetMemoryBalloonSize called %d times, want 0", n)
+		}
+	})
+
+	t.Run("VM not running", func(t *testing.T) {
+		api := newFakeAPI()
+		api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStatePoweredOff})
+		c := newTestClient(api)
+
+		err := c.SetMemoryBalloonByID(context.Background(), "uuid-vm", 512, time.Second)
+		if err == nil || !strings.Contains(err.Error(), "the VM must be running, but it is PoweredOff") {
+			t.Fatalf("error = %v, want the VM to be required running", err)
+		}
+		if _, ok, err := c.ReadMemoryBalloonByID(context.Background(), "uuid-vm"); err != nil || ok {
+			t.Errorf("ReadMemoryBalloonByID() = %t, %v, want no balloon", ok, err)
+		}
+	})
+}
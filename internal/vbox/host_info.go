@@ -0,0 +1,55 @@
+package vbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// HostInfo describes the VirtualBox host and its VirtualBox installation.
+type HostInfo struct {
+	// Version is the full VirtualBox version, e.g. "7.1.4", and APIVersion
+	// the version of its API, e.g. "7_1".
+	Version    string
+	APIVersion string
+	// OS and OSVersion are the host's operating system, e.g. "Linux", and
+	// its version.
+	OS        string
+	OSVersion string
+	// ProcessorCount is the number of host CPUs and MemoryMB the host's
+	// physical memory.
+	ProcessorCount uint32
+	MemoryMB       uint32
+}
+
+// GetHostInfo returns the VirtualBox version and the capacity of the host.
+func (c *Client) GetHostInfo(ctx context.Context) (*HostInfo, error) {
+	var info HostInfo
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		var err error
+		if info.Version, err = api.GetVersion(ctx, session); err != nil {
+			return fmt.Errorf("failed to get VirtualBox version: %w", err)
+		}
+		if info.APIVersion, err = api.GetAPIVersion(ctx, session); err != nil {
+			return fmt.Errorf("failed to get VirtualBox API version: %w", err)
+		}
+		if info.OS, err = api.GetHostOperatingSystem(ctx, session); err != nil {
+			return fmt.Errorf("failed to get host operating system: %w", err)
+		}
+		if info.OSVersion, err = api.GetHostOSVersion(ctx, session); err != nil {
+			return fmt.Errorf("failed to get host OS version: %w", err)
+		}
+		if info.ProcessorCount, err = api.GetHostProcessorCount(ctx, session); err != nil {
+			return fmt.Errorf("failed to get host processor count: %w", err)
+		}
+		if info.MemoryMB, err = api.GetHostMemorySize(ctx, session); err != nil {
+			return fmt.Errorf("failed to get host memory size: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
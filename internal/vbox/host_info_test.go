@@ -0,0 +1,22 @@
+package vbox
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetHostInfo(t *testing.T) {
+	api := newFakeAPI()
+	api.host = HostInfo{Version: "7.1.4", OS: "Linux", OSVersion: "6.8.0-45-generic", ProcessorCount: 16, MemoryMB: 65536}
+	c := newTestClient(api)
+
+	got, err := c.GetHostInfo(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := api.host
+	want.APIVersion = "7_1"
+	if *got != want {
+		t.Errorf("GetHostInfo() = %+v, want %+v", *got, want)
+	}
+}
@@ -0,0 +1,83 @@
+package vbox
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// ReadBridgedInterfaces lists the host network interfaces that network
+// adapters can be bridged to, ordered by name.
+func (c *Client) ReadBridgedInterfaces(ctx context.Context) ([]vboxapi.HostNetworkInterface, error) {
+	var result []vboxapi.HostNetworkInterface
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		var err error
+		result, err = bridgedInterfaces(ctx, api, session)
+		return err
+	})
+	return result, err
+}
+
+// CheckBridgedInterface returns an error unless the host has a bridged
+// interface named name whose link is up. VirtualBox accepts adapters bridged
+// to a missing or down interface, and only fails when the VM starts or,
+// for a down interface, not at all.
+func (c *Client) CheckBridgedInterface(ctx context.Context, name string) error {
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		ifaces, err := bridgedInterfaces(ctx, api, session)
+		if err != nil {
+			return err
+		}
+		return CheckBridgedInterfaceUp(name, ifaces)
+	})
+}
+
+// CheckBridgedInterfaceUp returns an error unless ifaces has an interface
+// named name that is up. The error names the interfaces that are up.
+func CheckBridgedInterfaceUp(name string, ifaces []vboxapi.HostNetworkInterface) error {
+	var up []string
+	found := false
+	for _, iface := range ifaces {
+		if iface.Status == vboxapi.HostNetworkInterfaceStatusUp {
+			if iface.Name == name {
+				return nil
+			}
+			up = append(up, iface.Name)
+		}
+		if iface.Name == name {
+			found = true
+		}
+	}
+
+	valid := "none"
+	if len(up) > 0 {
+		valid = strings.Join(up, ", ")
+	}
+	if !found {
+		return fmt.Errorf("the VirtualBox host has no bridged interface named %q; interfaces that are up: %s", name, valid)
+	}
+	return fmt.Errorf("bridged interface %q of the VirtualBox host is not up; interfaces that are up: %s", name, valid)
+}
+
+// bridgedInterfaces reads the host network interfaces that network adapters
+// can be bridged to, ordered by name.
+func bridgedInterfaces(ctx context.Context, api vboxapi.VBoxAPI, session string) ([]vboxapi.HostNetworkInterface, error) {
+	refs, err := api.GetBridgedInterfaces(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list host network interfaces: %w", err)
+	}
+	ifaces := make([]vboxapi.HostNetworkInterface, 0, len(refs))
+	for _, ref := range refs {
+		iface, err := api.GetHostNetworkInterface(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read host network interface: %w", err)
+		}
+		ifaces = append(ifaces, iface)
+	}
+	slices.SortFunc(ifaces, func(a, b vboxapi.HostNetworkInterface) int { return cmp.Compare(a.Name, b.Name) })
+	return ifaces, nil
+}
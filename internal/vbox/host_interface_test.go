@@ -0,0 +1,64 @@
+package vbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestCheckBridgedInterface(t *testing.T) {
+	ifaces := []vboxapi.HostNetworkInterface{
+		{Name: "eth0", Status: vboxapi.HostNetworkInterfaceStatusUp, IPAddress: "192.168.1.10"},
+		{Name: "eth1", Status: vboxapi.HostNetworkInterfaceStatusUp},
+		{Name: "wlan0", Status: vboxapi.HostNetworkInterfaceStatusDown, Wireless: true},
+		{Name: "veth0", Status: vboxapi.HostNetworkInterfaceStatusUnknown},
+	}
+	tests := []struct {
+		name    string
+		ifaces  []vboxapi.HostNetworkInterface
+		wantErr string
+	}{
+		{"eth0", ifaces, ""},
+		{"wlan0", ifaces, `bridged interface "wlan0" of the VirtualBox host is not up; interfaces that are up: eth0, eth1`},
+		{"veth0", ifaces, `bridged interface "veth0" of the VirtualBox host is not up; interfaces that are up: eth0, eth1`},
+		{"en0", ifaces, `the VirtualBox host has no bridged interface named "en0"; interfaces that are up: eth0, eth1`},
+		{"wlan0", ifaces[2:], `bridged interface "wlan0" of the VirtualBox host is not up; interfaces that are up: none`},
+	}
+	for _, tt := range tests {
+		err := CheckBridgedInterfaceUp(tt.name, tt.ifaces)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("CheckBridgedInterfaceUp(%q) unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("CheckBridgedInterfaceUp(%q) error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestReadBridgedInterfaces(t *testing.T) {
+	api := newFakeAPI()
+	api.hostInterfaces = []vboxapi.HostNetworkInterface{
+		{Name: "wlan0", Status: vboxapi.HostNetworkInterfaceStatusDown, Wireless: true},
+		{Name: "eth0", Status: vboxapi.HostNetworkInterfaceStatusUp, IPAddress: "192.168.1.10", HardwareAddress: "00:11:22:33:44:55"},
+	}
+	c := newTestClient(api)
+
+	got, err := c.ReadBridgedInterfaces(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0] != api.hostInterfaces[1] || got[1] != api.hostInterfaces[0] {
+		t.Errorf("ReadBridgedInterfaces() = %+v, want the interfaces ordered by name", got)
+	}
+
+	if err := c.CheckBridgedInterface(context.Background(), "eth0"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := c.CheckBridgedInterface(context.Background(), "wlan0"); err == nil {
+		t.Error("expected the down interface to be rejected")
+	}
+}
@@ -0,0 +1,84 @@
+package vbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// Scan codes (set 1) of the keys pressed for a magic SysRq sequence. SysRq
+// is the Print Screen key, which sends 0x54 while Alt is held.
+const (
+	scancodeLeftAlt = 0x38
+	scancodeSysRq   = 0x54
+	// scancodeBreak is added to a key's scan code when it is released.
+	scancodeBreak = 0x80
+)
+
+// sysrqKeyScancodes are the scan codes (set 1) of the keys that can follow
+// Alt+SysRq.
+var sysrqKeyScancodes = map[byte]int32{
+	'1': 0x02, '2': 0x03, '3': 0x04, '4': 0x05, '5': 0x06,
+	'6': 0x07, '7': 0x08, '8': 0x09, '9': 0x0a, '0': 0x0b,
+	'q': 0x10, 'w': 0x11, 'e': 0x12, 'r': 0x13, 't': 0x14,
+	'y': 0x15, 'u': 0x16, 'i': 0x17, 'o': 0x18, 'p': 0x19,
+	'a': 0x1e, 's': 0x1f, 'd': 0x20, 'f': 0x21, 'g': 0x22,
+	'h': 0x23, 'j': 0x24, 'k': 0x25, 'l': 0x26,
+	'z': 0x2c, 'x': 0x2d, 'c': 0x2e, 'v': 0x2f, 'b': 0x30,
+	'n': 0x31, 'm': 0x32,
+}
+
+// SendSysRqByID sends the magic SysRq key combination Alt+SysRq+key to a
+// running VM, e.g. "t" to dump the tasks of a hung Linux guest to its
+// console or "b" to reboot it. The guest kernel must have SysRq enabled
+// (kernel.sysrq), or it ignores the keys.
+func (c *Client) SendSysRqByID(ctx context.Context, id, key string) error {
+	scancodes, err := sysrqScancodes(key)
+	if err != nil {
+		return err
+	}
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		mRef, err := findMachine(ctx, api, session, id)
+		if err != nil {
+			return err
+		}
+		st, err := api.GetMachineState(ctx, mRef)
+		if err != nil {
+			return err
+		}
+		if st != vboxapi.MachineStateRunning {
+			return fmt.Errorf("cannot send SysRq: the VM must be running, but it is %s", st)
+		}
+		return withConsole(ctx, api, session, mRef, func(ctx context.Context, consoleRef string) error {
+			queued, err := api.PutScancodes(ctx, consoleRef, scancodes)
+			if err != nil {
+				return fmt.Errorf("failed to send SysRq %s: %w", key, err)
+			}
+			if int(queued) < len(scancodes) {
+				return fmt.Errorf("failed to send SysRq %s: the keyboard buffer of the VM is full", key)
+			}
+			return nil
+		})
+	})
+}
+
+// sysrqScancodes returns the scan codes that press and release Alt+SysRq+key,
+// where key is a lowercase letter or a digit.
+func sysrqScancodes(key string) ([]int32, error) {
+	if len(key) != 1 {
+		return nil, fmt.Errorf("SysRq key must be a single lowercase letter or digit, got %q", key)
+	}
+	code, ok := sysrqKeyScancodes[key[0]]
+	if !ok {
+		return nil, fmt.Errorf("SysRq key must be a single lowercase letter or digit, got %q", key)
+	}
+	return []int32{
+		scancodeLeftAlt,
+		scancodeSysRq,
+		code,
+		code | scancodeBreak,
+		scancodeSysRq | scancodeBreak,
+		scancodeLeftAlt | scancodeBreak,
+	}, nil
+}
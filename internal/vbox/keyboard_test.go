@@ -0,0 +1,82 @@
+package vbox
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestSysRqScancodes(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    []int32
+		wantErr bool
+	}{
+		// Alt down, SysRq down, key down, key up, SysRq up, Alt up.
+		{"b", []int32{0x38, 0x54, 0x30, 0xb0, 0xd4, 0xb8}, false},
+		{"t", []int32{0x38, 0x54, 0x14, 0x94, 0xd4, 0xb8}, false},
+		{"s", []int32{0x38, 0x54, 0x1f, 0x9f, 0xd4, 0xb8}, false},
+		{"0", []int32{0x38, 0x54, 0x0b, 0x8b, 0xd4, 0xb8}, false},
+		{"9", []int32{0x38, 0x54, 0x0a, 0x8a, 0xd4, 0xb8}, false},
+		{"B", nil, true},
+		{"", nil, true},
+		{"bt", nil, true},
+		{"?", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := sysrqScancodes(tt.key)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("sysrqScancodes(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("sysrqScancodes(%q) = %#x, want %#x", tt.key, got, tt.want)
+		}
+	}
+
+	// Every letter and digit has a scan code.
+	for _, key := range "abcdefghijklmnopqrstuvwxyz0123456789" {
+		if _, err := sysrqScancodes(string(key)); err != nil {
+			t.Errorf("sysrqScancodes(%q) unexpected error: %v", key, err)
+		}
+	}
+}
+
+func TestSendSysRqByID(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	if err := c.SendSysRqByID(context.Background(), "uuid-vm", "t"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int32{0x38, 0x54, 0x14, 0x94, 0xd4, 0xb8}; !slices.Equal(api.scancodes, want) {
+		t.Errorf("scancodes = %#x, want %#x", api.scancodes, want)
+	}
+	if !api.lockShared {
+		t.Error("expected a shared lock")
+	}
+
+	api.keyboardQueue = 2
+	err := c.SendSysRqByID(context.Background(), "uuid-vm", "t")
+	if err == nil || !strings.Contains(err.Error(), "keyboard buffer") {
+		t.Errorf("expected keyboard buffer error, got %v", err)
+	}
+}
+
+func TestSendSysRqByID_RequiresRunning(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStatePoweredOff})
+	c := newTestClient(api)
+
+	err := c.SendSysRqByID(context.Background(), "uuid-vm", "b")
+	if err == nil || !strings.Contains(err.Error(), "must be running") {
+		t.Fatalf("expected not running error, got %v", err)
+	}
+	if n := api.called("PutScancodes"); n != 0 {
+		t.Errorf("PutScancodes called %d times, want 0", n)
+	}
+}
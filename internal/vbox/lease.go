@@ -0,0 +1,117 @@
+package vbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// LeaseExtraDataKey is the machine extra data item holding the holder of
+// the VM's lease, e.g. a CI pipeline, while the VM is leased.
+const LeaseExtraDataKey = "vboxweb/lease"
+
+// AcquireLease leases a VM to holder. The lease is checked and set while
+// the machine is locked, so that two holders leasing a powered off VM
+// cannot both succeed. A running VM can only be locked shared; its lease
+// is read back after being set, which catches most, but not all, races.
+// Leasing a VM again to its current holder succeeds.
+func (c *Client) AcquireLease(ctx context.Context, machineID, holder string) error {
+	if holder == "" {
+		return fmt.Errorf("lease holder must not be empty")
+	}
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+		err = withMutableMachine(ctx, api, session, machineRef, func(mutableMachineRef string) error {
+			current, err := api.GetExtraData(ctx, mutableMachineRef, LeaseExtraDataKey)
+			if err != nil {
+				return fmt.Errorf("failed to read lease: %w", err)
+			}
+			if current == holder {
+				return nil
+			}
+			if current != "" {
+				return leaseHeldError(machineID, current)
+			}
+			if err := api.SetExtraData(ctx, mutableMachineRef, LeaseExtraDataKey, holder); err != nil {
+				return fmt.Errorf("failed to set lease: %w", err)
+			}
+			if err := api.SaveSettings(ctx, mutableMachineRef); err != nil {
+				return fmt.Errorf("failed to save machine settings: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		current, err := api.GetExtraData(ctx, machineRef, LeaseExtraDataKey)
+		if err != nil {
+			return fmt.Errorf("failed to read lease back: %w", err)
+		}
+		if current != holder {
+			return leaseHeldError(machineID, current)
+		}
+		return nil
+	})
+}
+
+// ReadLease returns the holder of a VM's lease, or "" if the VM is not
+// leased.
+func (c *Client) ReadLease(ctx context.Context, machineID string) (string, error) {
+	var holder string
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+		holder, err = api.GetExtraData(ctx, machineRef, LeaseExtraDataKey)
+		if err != nil {
+			return fmt.Errorf("failed to read lease: %w", err)
+		}
+		return nil
+	})
+	return holder, err
+}
+
+// ReleaseLease ends the lease of a VM held by holder. A lease that is
+// already released, or held by someone else, is left alone.
+func (c *Client) ReleaseLease(ctx context.Context, machineID, holder string) error {
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+		return withMutableMachine(ctx, api, session, machineRef, func(mutableMachineRef string) error {
+			current, err := api.GetExtraData(ctx, mutableMachineRef, LeaseExtraDataKey)
+			if err != nil {
+				return fmt.Errorf("failed to read lease: %w", err)
+			}
+			if current != holder {
+				if current != "" {
+					tflog.Warn(ctx, "The VM is leased by another holder; leaving its lease alone", map[string]interface{}{
+						"machine_id": machineID,
+						"holder":     current,
+					})
+				}
+				return nil
+			}
+			if err := api.SetExtraData(ctx, mutableMachineRef, LeaseExtraDataKey, ""); err != nil {
+				return fmt.Errorf("failed to clear lease: %w", err)
+			}
+			if err := api.SaveSettings(ctx, mutableMachineRef); err != nil {
+				return fmt.Errorf("failed to save machine settings: %w", err)
+			}
+			return nil
+		})
+	})
+}
+
+func leaseHeldError(machineID, holder string) error {
+	return fmt.Errorf("machine %s is already leased by %q", machineID, holder)
+}
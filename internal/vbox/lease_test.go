@@ -0,0 +1,65 @@
+package vbox
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestLease(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStatePoweredOff})
+	c := newTestClient(api)
+	ctx := context.Background()
+
+	if err := c.AcquireLease(ctx, "uuid-vm", "pipeline-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if api.lockShared {
+		t.Error("expected a powered off VM to be leased under a write lock")
+	}
+	if holder, err := c.ReadLease(ctx, "uuid-vm"); err != nil || holder != "pipeline-1" {
+		t.Errorf("ReadLease() = %q, %v, want pipeline-1", holder, err)
+	}
+
+	// Leasing again to the same holder succeeds.
+	if err := c.AcquireLease(ctx, "uuid-vm", "pipeline-1"); err != nil {
+		t.Errorf("unexpected error re-acquiring the lease: %v", err)
+	}
+
+	err := c.AcquireLease(ctx, "uuid-vm", "pipeline-2")
+	if err == nil || !strings.Contains(err.Error(), `"pipeline-1"`) {
+		t.Fatalf("error = %v, want the lease held by pipeline-1", err)
+	}
+
+	// Releasing someone else's lease leaves it alone.
+	if err := c.ReleaseLease(ctx, "uuid-vm", "pipeline-2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if holder := api.machines["machine-vm"].ExtraData[LeaseExtraDataKey]; holder != "pipeline-1" {
+		t.Errorf("holder = %q, want pipeline-1", holder)
+	}
+
+	if err := c.ReleaseLease(ctx, "uuid-vm", "pipeline-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if holder, err := c.ReadLease(ctx, "uuid-vm"); err != nil || holder != "" {
+		t.Errorf("ReadLease() = %q, %v, want no holder", holder, err)
+	}
+	if err := c.AcquireLease(ctx, "uuid-vm", "pipeline-2"); err != nil {
+		t.Errorf("unexpected error acquiring a released lease: %v", err)
+	}
+}
+
+func TestLease_MissingMachine(t *testing.T) {
+	c := newTestClient(newFakeAPI())
+
+	if err := c.AcquireLease(context.Background(), "missing", "pipeline-1"); !IsNotFound(err) {
+		t.Errorf("error = %v, want a not found error", err)
+	}
+	if _, err := c.ReadLease(context.Background(), "missing"); !IsNotFound(err) {
+		t.Errorf("error = %v, want a not found error", err)
+	}
+}
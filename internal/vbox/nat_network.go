@@ -0,0 +1,262 @@
+package vbox
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// CreateNATNetwork creates a NAT network. It fails if a NAT network of the
+// same name already exists, which must be imported instead.
+func (c *Client) CreateNATNetwork(ctx context.Context, network vboxapi.NATNetwork) error {
+	if err := validateNATNetwork(network); err != nil {
+		return err
+	}
+
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		_, err := findNATNetwork(ctx, api, session, network.Name)
+		if err == nil {
+			return fmt.Errorf("NAT network %s already exists; import it to manage it", network.Name)
+		}
+		if !IsNotFound(err) {
+			return err
+		}
+
+		natNetworkRef, err := api.CreateNATNetwork(ctx, session, network.Name)
+		if err != nil {
+			return fmt.Errorf("failed to create NAT network %s: %w", network.Name, err)
+		}
+		if err := api.SetNATNetwork(ctx, natNetworkRef, network); err != nil {
+			// Don't leave a half configured network behind, which would
+			// make the next apply fail as already existing.
+			if rmErr := api.RemoveNATNetwork(ctx, session, natNetworkRef); rmErr != nil {
+				tflog.Warn(ctx, "Failed to remove NAT network after failing to configure it", map[string]interface{}{
+					"name":  network.Name,
+					"error": rmErr.Error(),
+				})
+			}
+			return fmt.Errorf("failed to configure NAT network %s: %w", network.Name, err)
+		}
+		return nil
+	})
+}
+
+// ReadNATNetwork returns the NAT network named name, or nil if there is
+// none.
+func (c *Client) ReadNATNetwork(ctx context.Context, name string) (*vboxapi.NATNetwork, error) {
+	var result *vboxapi.NATNetwork
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		natNetworkRef, err := findNATNetwork(ctx, api, session, name)
+		if err != nil {
+			if IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		network, err := api.GetNATNetwork(ctx, natNetworkRef)
+		if err != nil {
+			return fmt.Errorf("failed to read NAT network %s: %w", name, err)
+		}
+		result = &network
+		return nil
+	})
+	return result, err
+}
+
+// UpdateNATNetwork changes the network, enabled and DHCP settings of the
+// NAT network named network.Name. Settings that already match are left
+// alone.
+func (c *Client) UpdateNATNetwork(ctx context.Context, network vboxapi.NATNetwork) error {
+	if err := validateNATNetwork(network); err != nil {
+		return err
+	}
+
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		natNetworkRef, err := findNATNetwork(ctx, api, session, network.Name)
+		if err != nil {
+			return err
+		}
+		current, err := api.GetNATNetwork(ctx, natNetworkRef)
+		if err != nil {
+			return fmt.Errorf("failed to read NAT network %s: %w", network.Name, err)
+		}
+		if current == network {
+			return nil
+		}
+		if err := api.SetNATNetwork(ctx, natNetworkRef, network); err != nil {
+			return fmt.Errorf("failed to configure NAT network %s: %w", network.Name, err)
+		}
+		return nil
+	})
+}
+
+// DeleteNATNetwork removes the NAT network named name. A NAT network that
+// does not exist is ignored.
+func (c *Client) DeleteNATNetwork(ctx context.Context, name string) error {
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		natNetworkRef, err := findNATNetwork(ctx, api, session, name)
+		if err != nil {
+			if IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if err := api.RemoveNATNetwork(ctx, session, natNetworkRef); err != nil {
+			return fmt.Errorf("failed to remove NAT network %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// NATNetworkPortForwardRule is an IPv4 port forwarding rule of a NAT
+// network. Unlike the rules of NAT adapters, it forwards to the address of
+// one of the VMs attached to the network.
+type NATNetworkPortForwardRule struct {
+	NetworkName string
+	Name        string
+	Protocol    vboxapi.NATProtocol
+	HostIP      string
+	HostPort    uint16
+	GuestIP     string
+	GuestPort   uint16
+}
+
+func (r NATNetworkPortForwardRule) apiRule() vboxapi.NATRedirect {
+	return vboxapi.NATRedirect{
+		Name:      r.Name,
+		Protocol:  r.Protocol,
+		HostIP:    r.HostIP,
+		HostPort:  r.HostPort,
+		GuestIP:   r.GuestIP,
+		GuestPort: r.GuestPort,
+	}
+}
+
+// CreateNATNetworkPortForward adds a port forwarding rule to a NAT network.
+// A rule that already exists with the same settings is left alone; one with
+// other settings is an error.
+func (c *Client) CreateNATNetworkPortForward(ctx context.Context, rule NATNetworkPortForwardRule) error {
+	if rule.Name == "" || strings.Contains(rule.Name, ":") {
+		return fmt.Errorf("NAT network port forwarding rule name must not be empty or contain ':', got %q", rule.Name)
+	}
+
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		natNetworkRef, err := findNATNetwork(ctx, api, session, rule.NetworkName)
+		if err != nil {
+			return err
+		}
+		existing, err := natNetworkRule(ctx, api, natNetworkRef, rule.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			if *existing == rule.apiRule() {
+				return nil
+			}
+			return fmt.Errorf("NAT network %s already has a port forwarding rule named %s with other settings", rule.NetworkName, rule.Name)
+		}
+		if err := api.AddNATNetworkPortForwardRule4(ctx, natNetworkRef, rule.apiRule()); err != nil {
+			return fmt.Errorf("failed to add port forwarding rule %s to NAT network %s: %w", rule.Name, rule.NetworkName, err)
+		}
+		return nil
+	})
+}
+
+// ReadNATNetworkPortForward returns a port forwarding rule of a NAT
+// network, or nil if the network has no such rule. A missing network is an
+// error that IsNotFound recognizes.
+func (c *Client) ReadNATNetworkPortForward(ctx context.Context, networkName, name string) (*NATNetworkPortForwardRule, error) {
+	var result *NATNetworkPortForwardRule
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		natNetworkRef, err := findNATNetwork(ctx, api, session, networkName)
+		if err != nil {
+			return err
+		}
+		rule, err := natNetworkRule(ctx, api, natNetworkRef, name)
+		if err != nil || rule == nil {
+			return err
+		}
+		result = &NATNetworkPortForwardRule{
+			NetworkName: networkName,
+			Name:        rule.Name,
+			Protocol:    rule.Protocol,
+			HostIP:      rule.HostIP,
+			HostPort:    rule.HostPort,
+			GuestIP:     rule.GuestIP,
+			GuestPort:   rule.GuestPort,
+		}
+		return nil
+	})
+	return result, err
+}
+
+// DeleteNATNetworkPortForward removes a port forwarding rule from a NAT
+// network. A rule or network that does not exist is ignored.
+func (c *Client) DeleteNATNetworkPortForward(ctx context.Context, networkName, name string) error {
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		natNetworkRef, err := findNATNetwork(ctx, api, session, networkName)
+		if err != nil {
+			if IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		rule, err := natNetworkRule(ctx, api, natNetworkRef, name)
+		if err != nil || rule == nil {
+			return err
+		}
+		if err := api.RemoveNATNetworkPortForwardRule4(ctx, natNetworkRef, name); err != nil {
+			return fmt.Errorf("failed to remove port forwarding rule %s from NAT network %s: %w", name, networkName, err)
+		}
+		return nil
+	})
+}
+
+// natNetworkRule returns the IPv4 port forwarding rule of a NAT network
+// named name, or nil if there is none.
+func natNetworkRule(ctx context.Context, api vboxapi.VBoxAPI, natNetworkRef, name string) (*vboxapi.NATRedirect, error) {
+	rules, err := api.GetNATNetworkPortForwardRules4(ctx, natNetworkRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NAT network port forwarding rules: %w", err)
+	}
+	for _, r := range rules {
+		if r.Name == name {
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+// findNATNetwork returns the ref of the NAT network named name, or an error
+// that IsNotFound recognizes if there is none.
+func findNATNetwork(ctx context.Context, api vboxapi.VBoxAPI, session, name string) (string, error) {
+	natNetworkRef, err := api.FindNATNetworkByName(ctx, session, name)
+	if err != nil {
+		if vboxapi.IsObjectNotFound(err) {
+			return "", fmt.Errorf("%w: NAT network %s", errNotFound, name)
+		}
+		return "", err
+	}
+	if strings.TrimSpace(natNetworkRef) == "" {
+		return "", fmt.Errorf("%w: NAT network %s", errNotFound, name)
+	}
+	return natNetworkRef, nil
+}
+
+// validateNATNetwork checks that network has a name and an IPv4 network in
+// CIDR notation.
+func validateNATNetwork(network vboxapi.NATNetwork) error {
+	if strings.TrimSpace(network.Name) == "" {
+		return fmt.Errorf("NAT network name must not be empty")
+	}
+	ip, _, err := net.ParseCIDR(network.Network)
+	if err != nil || ip.To4() == nil {
+		return fmt.Errorf("NAT network %s: network must be an IPv4 network in CIDR notation, e.g. 10.0.2.0/24, got %q", network.Name, network.Network)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package vbox
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestNATNetwork(t *testing.T) {
+	api := newFakeAPI()
+	c := newTestClient(api)
+	ctx := context.Background()
+
+	network := vboxapi.NATNetwork{Name: "lab", Network: "10.0.9.0/24", Enabled: true, DHCPEnabled: true}
+	if err := c.CreateNATNetwork(ctx, network); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := c.ReadNATNetwork(ctx, "lab")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || *got != network {
+		t.Errorf("ReadNATNetwork() = %+v, want %+v", got, network)
+	}
+
+	err = c.CreateNATNetwork(ctx, network)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("error = %v, want the existing network to be reported", err)
+	}
+
+	// Updating to the current settings leaves the network alone.
+	if err := c.UpdateNATNetwork(ctx, network); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("SetNATNetwork"); n != 1 {
+		t.Errorf("SetNATNetwork called %d times, want 1", n)
+	}
+
+	network.DHCPEnabled = false
+	if err := c.UpdateNATNetwork(ctx, network); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := api.natNetworkSettings["natnet-lab"]; got != network {
+		t.Errorf("NAT network = %+v, want %+v", got, network)
+	}
+
+	if err := c.DeleteNATNetwork(ctx, "lab"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := c.ReadNATNetwork(ctx, "lab"); err != nil || got != nil {
+		t.Errorf("ReadNATNetwork() = %+v, %v, want no network", got, err)
+	}
+	if err := c.DeleteNATNetwork(ctx, "lab"); err != nil {
+		t.Errorf("unexpected error deleting a missing network: %v", err)
+	}
+	if err := c.UpdateNATNetwork(ctx, network); !IsNotFound(err) {
+		t.Errorf("UpdateNATNetwork() error = %v, want not found", err)
+	}
+}
+
+func TestValidateNATNetwork(t *testing.T) {
+	tests := []struct {
+		network string
+		wantErr bool
+	}{
+		{"10.0.2.0/24", false},
+		{"192.168.15.0/28", false},
+		{"10.0.2.0", true},
+		{"fd00::/64", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		err := validateNATNetwork(vboxapi.NATNetwork{Name: "lab", Network: tt.network})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateNATNetwork(%q) error = %v, wantErr %v", tt.network, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNATNetworkPortForward(t *testing.T) {
+	api := newFakeAPI()
+	c := newTestClient(api)
+	ctx := context.Background()
+
+	if err := c.CreateNATNetwork(ctx, vboxapi.NATNetwork{Name: "lab", Network: "10.0.9.0/24", Enabled: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rule := NATNetworkPortForwardRule{NetworkName: "lab", Name: "ssh", Protocol: vboxapi.NATProtocolTCP, HostPort: 2222, GuestIP: "10.0.9.5", GuestPort: 22}
+	if err := c.CreateNATNetworkPortForward(ctx, rule); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Creating the same rule again leaves it alone.
+	if err := c.CreateNATNetworkPortForward(ctx, rule); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("AddNATNetworkPortForwardRule4"); n != 1 {
+		t.Errorf("AddNATNetworkPortForwardRule4 called %d times, want 1", n)
+	}
+
+	got, err := c.ReadNATNetworkPortForward(ctx, "lab", "ssh")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || *got != rule {
+		t.Errorf("ReadNATNetworkPortForward() = %+v, want %+v", got, rule)
+	}
+
+	// The rule's host port is seen by the auto host port allocator.
+	used, err := CollectUsedPorts(ctx, api, "session", true, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(used) != 1 || used[0].Port != 2222 {
+		t.Errorf("CollectUsedPorts() = %+v, want port 2222", used)
+	}
+
+	changed := rule
+	changed.GuestPort = 2222
+	err = c.CreateNATNetworkPortForward(ctx, changed)
+	if err == nil || !strings.Contains(err.Error(), "other settings") {
+		t.Errorf("error = %v, want the conflicting rule to be reported", err)
+	}
+
+	if err := c.DeleteNATNetworkPortForward(ctx, "lab", "ssh"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := c.ReadNATNetworkPortForward(ctx, "lab", "ssh"); err != nil || got != nil {
+		t.Errorf("ReadNATNetworkPortForward() = %+v, %v, want no rule", got, err)
+	}
+	if err := c.DeleteNATNetworkPortForward(ctx, "lab", "ssh"); err != nil {
+		t.Errorf("unexpected error deleting a missing rule: %v", err)
+	}
+	if err := c.DeleteNATNetworkPortForward(ctx, "missing", "ssh"); err != nil {
+		t.Errorf("unexpected error deleting from a missing network: %v", err)
+	}
+	if _, err := c.ReadNATNetworkPortForward(ctx, "missing", "ssh"); !IsNotFound(err) {
+		t.Errorf("ReadNATNetworkPortForward() error = %v, want not found", err)
+	}
+}
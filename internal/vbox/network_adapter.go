@@ -0,0 +1,479 @@
+package vbox
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// NetworkAdapterInfo describes a network adapter of a VM.
+type NetworkAdapterInfo struct {
+	Slot           uint32
+	Enabled        bool
+	AttachmentType vboxapi.NetworkAttachmentType
+	// Network is the host interface of Bridged and HostOnly adapters, or
+	// the network Internal and NATNetwork adapters join; it is empty for
+	// other attachment types.
+	Network        string
+	AdapterType    vboxapi.NetworkAdapterType
+	MACAddress     string
+	CableConnected bool
+	// LineSpeedKbps is the speed the adapter reports to the guest, in
+	// kbps.
+	LineSpeedKbps uint32
+	// NATRedirectCount is the number of port forwarding rules of NAT
+	// adapters; it is 0 for other attachment types.
+	NATRedirectCount int
+}
+
+// ReadNetworkAdapters lists the enabled network adapters of a VM, ordered
+// by slot. Disabled slots, and slots the VM's chipset does not provide,
+// are left out.
+func (c *Client) ReadNetworkAdapters(ctx context.Context, machineID string) ([]NetworkAdapterInfo, error) {
+	var out []NetworkAdapterInfo
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+
+		maxAdapters, err := api.GetMaxNetworkAdapters(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get the number of network adapters: %w", err)
+		}
+
+		for slot := uint32(0); slot < maxAdapters; slot++ {
+			adapterRef, err := api.GetNetworkAdapter(ctx, machineRef, slot)
+			if err != nil {
+				continue
+			}
+			enabled, err := api.GetAdapterEnabled(ctx, adapterRef)
+			if err != nil {
+				return fmt.Errorf("network adapter slot %d: %w", slot, err)
+			}
+			if !enabled {
+				continue
+			}
+
+			info, err := readNetworkAdapter(ctx, api, adapterRef)
+			if err != nil {
+				return fmt.Errorf("network adapter slot %d: %w", slot, err)
+			}
+			info.Slot = slot
+			info.Enabled = true
+			out = append(out, *info)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// ReadNetworkAdapter reads the network adapter in slot of a VM, whether it
+// is enabled or not.
+func (c *Client) ReadNetworkAdapter(ctx context.Context, machineID string, slot uint32) (*NetworkAdapterInfo, error) {
+	var result *NetworkAdapterInfo
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+		if err := checkAdapterSlot(ctx, api, machineRef, slot); err != nil {
+			return err
+		}
+		adapterRef, err := api.GetNetworkAdapter(ctx, machineRef, slot)
+		if err != nil {
+			return fmt.Errorf("failed to get network adapter slot %d: %w", slot, err)
+		}
+		enabled, err := api.GetAdapterEnabled(ctx, adapterRef)
+		if err != nil {
+			return fmt.Errorf("network adapter slot %d: %w", slot, err)
+		}
+		info, err := readNetworkAdapter(ctx, api, adapterRef)
+		if err != nil {
+			return fmt.Errorf("network adapter slot %d: %w", slot, err)
+		}
+		info.Slot = slot
+		info.Enabled = enabled
+		result = info
+		return nil
+	})
+	return result, err
+}
+
+// readNetworkAdapter reads the settings of an adapter, leaving Slot and
+// Enabled unset.
+func readNetworkAdapter(ctx context.Context, api vboxapi.VBoxAPI, adapterRef string) (*NetworkAdapterInfo, error) {
+	attachment, err := api.GetAdapterAttachmentType(ctx, adapterRef)
+	if err != nil {
+		return nil, err
+	}
+	network, err := adapterNetwork(ctx, api, adapterRef, attachment)
+	if err != nil {
+		return nil, err
+	}
+	adapterType, err := api.GetAdapterType(ctx, adapterRef)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := api.GetAdapterMACAddress(ctx, adapterRef)
+	if err != nil {
+		return nil, err
+	}
+	connected, err := api.GetAdapterCableConnected(ctx, adapterRef)
+	if err != nil {
+		return nil, err
+	}
+	lineSpeed, err := api.GetAdapterLineSpeed(ctx, adapterRef)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &NetworkAdapterInfo{
+		AttachmentType: attachment,
+		Network:        network,
+		AdapterType:    adapterType,
+		MACAddress:     mac,
+		CableConnected: connected,
+		LineSpeedKbps:  lineSpeed,
+	}
+	if attachment == vboxapi.NetworkAttachmentTypeNAT {
+		natEngineRef, err := api.GetNATEngine(ctx, adapterRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get NAT engine: %w", err)
+		}
+		redirects, err := api.GetNATRedirects(ctx, natEngineRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get NAT redirects: %w", err)
+		}
+		info.NATRedirectCount = len(redirects)
+	}
+	return info, nil
+}
+
+// attachmentNetworks names the setting that holds the interface or network
+// of the attachment types that have one.
+var attachmentNetworks = map[vboxapi.NetworkAttachmentType]string{
+	vboxapi.NetworkAttachmentTypeBridged:    "bridged interface",
+	vboxapi.NetworkAttachmentTypeHostOnly:   "host-only interface",
+	vboxapi.NetworkAttachmentTypeInternal:   "internal network name",
+	vboxapi.NetworkAttachmentTypeNATNetwork: "NAT network name",
+}
+
+// adapterNetwork returns the interface or network an adapter attached to
+// attachment uses, or "" if the attachment type has none. VirtualBox keeps
+// one per attachment type, so it can be read for a type the adapter is not
+// attached to yet.
+func adapterNetwork(ctx context.Context, api vboxapi.VBoxAPI, adapterRef string, attachment vboxapi.NetworkAttachmentType) (string, error) {
+	switch attachment {
+	case vboxapi.NetworkAttachmentTypeBridged:
+		return api.GetAdapterBridgedInterface(ctx, adapterRef)
+	case vboxapi.NetworkAttachmentTypeHostOnly:
+		return api.GetAdapterHostOnlyInterface(ctx, adapterRef)
+	case vboxapi.NetworkAttachmentTypeInternal:
+		return api.GetAdapterInternalNetwork(ctx, adapterRef)
+	case vboxapi.NetworkAttachmentTypeNATNetwork:
+		return api.GetAdapterNATNetwork(ctx, adapterRef)
+	}
+	return "", nil
+}
+
+// setAdapterNetwork sets the interface or network an adapter attached to
+// attachment uses. attachment must have one.
+func setAdapterNetwork(ctx context.Context, api vboxapi.VBoxAPI, adapterRef string, attachment vboxapi.NetworkAttachmentType, network string) error {
+	switch attachment {
+	case vboxapi.NetworkAttachmentTypeBridged:
+		return api.SetAdapterBridgedInterface(ctx, adapterRef, network)
+	case vboxapi.NetworkAttachmentTypeHostOnly:
+		return api.SetAdapterHostOnlyInterface(ctx, adapterRef, network)
+	case vboxapi.NetworkAttachmentTypeInternal:
+		return api.SetAdapterInternalNetwork(ctx, adapterRef, network)
+	case vboxapi.NetworkAttachmentTypeNATNetwork:
+		return api.SetAdapterNATNetwork(ctx, adapterRef, network)
+	}
+	return fmt.Errorf("%s attachments have no interface or network", attachment)
+}
+
+// NetworkAdapterSettings are settings of a network adapter. Nil fields are
+// left unchanged.
+type NetworkAdapterSettings struct {
+	// Enabled, AdapterType and MACAddress can only change while the VM is
+	// powered off.
+	Enabled        *bool
+	AttachmentType *vboxapi.NetworkAttachmentType
+	// Network is the interface or network of the attachment type, see
+	// NetworkAdapterInfo.Network. It must be set along with attachment
+	// types that have one.
+	Network        *string
+	AdapterType    *vboxapi.NetworkAdapterType
+	MACAddress     *string
+	CableConnected *bool
+	LineSpeedKbps  *uint32
+}
+
+// ConfigureNetworkAdapter applies settings to the network adapter in slot
+// under a shared lock, so that the attachment and cable of a running VM's
+// adapter can change. Settings that already match are left alone; changing
+// the others on a running VM is an error.
+func (c *Client) ConfigureNetworkAdapter(ctx context.Context, machineID string, slot uint32, settings NetworkAdapterSettings) error {
+	if err := validateNetworkAdapterSettings(slot, &settings); err != nil {
+		return err
+	}
+
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findRegisteredMachine(ctx, api, session, machineID, c.registrationWait)
+		if err != nil {
+			return err
+		}
+		if err := checkAdapterSlot(ctx, api, machineRef, slot); err != nil {
+			return err
+		}
+		st, err := api.GetMachineState(ctx, machineRef)
+		if err != nil {
+			return err
+		}
+
+		sessObj, err := api.GetSessionObject(ctx, session)
+		if err != nil {
+			return fmt.Errorf("failed to get session object: %w", err)
+		}
+		if err := api.LockMachine(ctx, machineRef, sessObj, true); err != nil {
+			return fmt.Errorf("failed to lock machine: %w", err)
+		}
+		defer unlockSession(ctx, api, sessObj)
+
+		return saveWithRetry(ctx, api, machineRef, sessObj, true, func(mutableMachineRef string) error {
+			adapterRef, err := api.GetNetworkAdapter(ctx, mutableMachineRef, slot)
+			if err != nil {
+				return fmt.Errorf("failed to get network adapter slot %d: %w", slot, err)
+			}
+			return applyNetworkAdapterSettings(ctx, api, adapterRef, slot, st, settings)
+		})
+	})
+}
+
+// validateNetworkAdapterSettings checks settings before they reach
+// VirtualBox, normalizing MACAddress.
+func validateNetworkAdapterSettings(slot uint32, settings *NetworkAdapterSettings) error {
+	if a := settings.AttachmentType; a != nil {
+		what, ok := attachmentNetworks[*a]
+		if ok && (settings.Network == nil || *settings.Network == "") {
+			return fmt.Errorf("network adapter slot %d: a %s attachment needs a %s", slot, *a, what)
+		}
+	}
+	if t := settings.AdapterType; t != nil && !slices.Contains(NetworkAdapterTypes, *t) {
+		return fmt.Errorf("network adapter slot %d: unsupported adapter type %q", slot, *t)
+	}
+	if settings.MACAddress != nil {
+		mac, err := NormalizeMACAddress(*settings.MACAddress)
+		if err != nil {
+			return fmt.Errorf("network adapter slot %d: %w", slot, err)
+		}
+		settings.MACAddress = &mac
+	}
+	if s := settings.LineSpeedKbps; s != nil && *s == 0 {
+		return fmt.Errorf("network adapter slot %d: line speed must be positive", slot)
+	}
+	return nil
+}
+
+// applyNetworkAdapterSettings changes the settings of an adapter of a
+// mutable machine that differ from settings. state is the machine state,
+// which decides whether the settings that need a powered-off VM can
+// change.
+func applyNetworkAdapterSettings(ctx context.Context, api vboxapi.VBoxAPI, adapterRef string, slot uint32, state string, settings NetworkAdapterSettings) error {
+	current, err := readNetworkAdapter(ctx, api, adapterRef)
+	if err != nil {
+		return fmt.Errorf("network adapter slot %d: %w", slot, err)
+	}
+	enabled, err := api.GetAdapterEnabled(ctx, adapterRef)
+	if err != nil {
+		return fmt.Errorf("network adapter slot %d: %w", slot, err)
+	}
+
+	changeEnabled := settings.Enabled != nil && *settings.Enabled != enabled
+	changeType := settings.AdapterType != nil && *settings.AdapterType != current.AdapterType
+	changeMAC := settings.MACAddress != nil && !strings.EqualFold(*settings.MACAddress, current.MACAddress)
+	if isMachineOnline(state) {
+		var offline []string
+		if changeEnabled {
+			offline = append(offline, "enabled state")
+		}
+		if changeType {
+			offline = append(offline, "adapter type")
+		}
+		if changeMAC {
+			offline = append(offline, "MAC address")
+		}
+		if len(offline) > 0 {
+			return fmt.Errorf("machine is %s; power off the VM (state = \"stopped\") to change the %s of network adapter slot %d", state, strings.Join(offline, " and "), slot)
+		}
+	}
+
+	if changeEnabled {
+		if err := api.SetAdapterEnabled(ctx, adapterRef, *settings.Enabled); err != nil {
+			return fmt.Errorf("failed to enable or disable network adapter slot %d: %w", slot, err)
+		}
+	}
+
+	// Set the interface or network first, so that the adapter is never
+	// attached to a stale one.
+	attachment := current.AttachmentType
+	if settings.AttachmentType != nil {
+		attachment = *settings.AttachmentType
+	}
+	if _, ok := attachmentNetworks[attachment]; ok && settings.Network != nil {
+		network, err := adapterNetwork(ctx, api, adapterRef, attachment)
+		if err != nil {
+			return fmt.Errorf("network adapter slot %d: %w", slot, err)
+		}
+		if network != *settings.Network {
+			if err := setAdapterNetwork(ctx, api, adapterRef, attachment, *settings.Network); err != nil {
+				return fmt.Errorf("failed to set the %s of network adapter slot %d: %w", attachmentNetworks[attachment], slot, err)
+			}
+		}
+	}
+	if attachment != current.AttachmentType {
+		if err := api.SetAdapterAttachmentType(ctx, adapterRef, attachment); err != nil {
+			return fmt.Errorf("failed to set attachment type of network adapter slot %d: %w", slot, err)
+		}
+	}
+
+	if changeType {
+		if err := api.SetAdapterType(ctx, adapterRef, *settings.AdapterType); err != nil {
+			return fmt.Errorf("failed to set adapter type of network adapter slot %d: %w", slot, err)
+		}
+	}
+	if changeMAC {
+		if err := api.SetAdapterMACAddress(ctx, adapterRef, *settings.MACAddress); err != nil {
+			return fmt.Errorf("failed to set MAC address of network adapter slot %d: %w", slot, err)
+		}
+	}
+	if c := settings.CableConnected; c != nil && *c != current.CableConnected {
+		if err := api.SetAdapterCableConnected(ctx, adapterRef, *c); err != nil {
+			return fmt.Errorf("failed to set cable of network adapter slot %d: %w", slot, err)
+		}
+	}
+	if s := settings.LineSpeedKbps; s != nil && *s != current.LineSpeedKbps {
+		if err := api.SetAdapterLineSpeed(ctx, adapterRef, *s); err != nil {
+			return fmt.Errorf("failed to set line speed of network adapter slot %d: %w", slot, err)
+		}
+	}
+	return nil
+}
+
+// NetworkAdapterTypes are the hardware a network adapter can emulate:
+// AMD PCnet (Am79C970A, Am79C973), Intel PRO/1000 (I82540EM, I82543GC,
+// I82545EM) and paravirtualized virtio-net (Virtio), which the guest needs
+// a driver for.
+var NetworkAdapterTypes = []vboxapi.NetworkAdapterType{
+	vboxapi.NetworkAdapterTypeAm79C970A,
+	vboxapi.NetworkAdapterTypeAm79C973,
+	vboxapi.NetworkAdapterTypeI82540EM,
+	vboxapi.NetworkAdapterTypeI82543GC,
+	vboxapi.NetworkAdapterTypeI82545EM,
+	vboxapi.NetworkAdapterTypeVirtio,
+}
+
+// NormalizeMACAddress returns mac as the 12 upper case hexadecimal digits
+// VirtualBox reports, e.g. "080027ABCDEF" for "08:00:27:ab:cd:ef". The
+// octets may be separated by ':' or '-'. Multicast addresses, whose first
+// octet is odd, are rejected, as VirtualBox does.
+func NormalizeMACAddress(mac string) (string, error) {
+	digits := strings.ToUpper(strings.NewReplacer(":", "", "-", "").Replace(mac))
+	if len(digits) != 12 || strings.Trim(digits, "0123456789ABCDEF") != "" {
+		return "", fmt.Errorf("MAC address must be 12 hexadecimal digits, optionally separated by ':' or '-', got %q", mac)
+	}
+	if strings.IndexByte("13579BDF", digits[1]) >= 0 {
+		return "", fmt.Errorf("MAC address %s is a multicast address; the first octet must be even", mac)
+	}
+	return digits, nil
+}
+
+// InternalNetworkMember is a network adapter attached to an internal
+// network.
+type InternalNetworkMember struct {
+	MachineID   string
+	MachineName string
+	Slot        uint32
+}
+
+// ReadInternalNetworkMembers lists the enabled network adapters of all VMs
+// that are attached to the internal network name, ordered by VM name and
+// slot. VirtualBox has no object for internal networks: one exists while
+// adapters refer to it. Inaccessible VMs are left out.
+func (c *Client) ReadInternalNetworkMembers(ctx context.Context, name string) ([]InternalNetworkMember, error) {
+	out := []InternalNetworkMember{}
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRefs, err := api.GetMachines(ctx, session)
+		if err != nil {
+			return fmt.Errorf("failed to enumerate machines: %w", err)
+		}
+		for _, mRef := range machineRefs {
+			info, err := readMachineInfo(ctx, api, mRef)
+			if err != nil {
+				return err
+			}
+			if !info.Accessible {
+				continue
+			}
+			slots, err := internalNetworkSlots(ctx, api, mRef, name)
+			if err != nil {
+				return fmt.Errorf("machine %s: %w", info.ID, err)
+			}
+			for _, slot := range slots {
+				out = append(out, InternalNetworkMember{MachineID: info.ID, MachineName: info.Name, Slot: slot})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(out, func(a, b InternalNetworkMember) int {
+		return cmp.Or(cmp.Compare(a.MachineName, b.MachineName), cmp.Compare(a.MachineID, b.MachineID), cmp.Compare(a.Slot, b.Slot))
+	})
+	return out, nil
+}
+
+// internalNetworkSlots returns the slots of the enabled adapters of a
+// machine that are attached to the internal network name.
+func internalNetworkSlots(ctx context.Context, api vboxapi.VBoxAPI, machineRef, name string) ([]uint32, error) {
+	maxAdapters, err := api.GetMaxNetworkAdapters(ctx, machineRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the number of network adapters: %w", err)
+	}
+
+	var slots []uint32
+	for slot := uint32(0); slot < maxAdapters; slot++ {
+		adapterRef, err := api.GetNetworkAdapter(ctx, machineRef, slot)
+		if err != nil {
+			continue
+		}
+		enabled, err := api.GetAdapterEnabled(ctx, adapterRef)
+		if err != nil {
+			return nil, fmt.Errorf("network adapter slot %d: %w", slot, err)
+		}
+		if !enabled {
+			continue
+		}
+		attachment, err := api.GetAdapterAttachmentType(ctx, adapterRef)
+		if err != nil {
+			return nil, fmt.Errorf("network adapter slot %d: %w", slot, err)
+		}
+		if attachment != vboxapi.NetworkAttachmentTypeInternal {
+			continue
+		}
+		network, err := api.GetAdapterInternalNetwork(ctx, adapterRef)
+		if err != nil {
+			return nil, fmt.Errorf("network adapter slot %d: %w", slot, err)
+		}
+		if network == name {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, nil
+}
@@ -0,0 +1,284 @@
+package vbox
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestReadNetworkAdapters(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{
+		ID:               "uuid-vm",
+		Name:             "vm",
+		MaxAdapters:      4,
+		DisabledAdapters: map[uint32]bool{1: true},
+		AttachmentTypes: map[uint32]vboxapi.NetworkAttachmentType{
+			2: vboxapi.NetworkAttachmentTypeBridged,
+			3: vboxapi.NetworkAttachmentTypeNull,
+		},
+		BridgedInterfaces:  map[uint32]string{2: "eth0"},
+		AdapterTypes:       map[uint32]vboxapi.NetworkAdapterType{2: vboxapi.NetworkAdapterTypeVirtio},
+		DisconnectedCables: map[uint32]bool{3: true},
+		LineSpeeds:         map[uint32]uint32{2: 1000000},
+		NATRedirects: map[uint32][]vboxapi.NATRedirect{
+			0: {{Name: "ssh", HostPort: 2222}, {Name: "http", HostPort: 8080}},
+			1: {{Name: "stale", HostPort: 3333}},
+		},
+	})
+	c := newTestClient(api)
+
+	got, err := c.ReadNetworkAdapters(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []NetworkAdapterInfo{
+		{Slot: 0, Enabled: true, AttachmentType: vboxapi.NetworkAttachmentTypeNAT, AdapterType: vboxapi.NetworkAdapterTypeI82540EM, MACAddress: "080027000000", CableConnected: true, NATRedirectCount: 2},
+		{Slot: 2, Enabled: true, AttachmentType: vboxapi.NetworkAttachmentTypeBridged, Network: "eth0", AdapterType: vboxapi.NetworkAdapterTypeVirtio, MACAddress: "080027000002", CableConnected: true, LineSpeedKbps: 1000000},
+		{Slot: 3, Enabled: true, AttachmentType: vboxapi.NetworkAttachmentTypeNull, AdapterType: vboxapi.NetworkAdapterTypeI82540EM, MACAddress: "080027000003"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadNetworkAdapters() = %+v, want %+v", got, want)
+	}
+	if n := api.called("GetNATEngine"); n != 1 {
+		t.Errorf("GetNATEngine called %d times, want 1", n)
+	}
+}
+
+func TestReadNetworkAdapters_NotFound(t *testing.T) {
+	c := newTestClient(newFakeAPI())
+
+	if _, err := c.ReadNetworkAdapters(context.Background(), "missing"); !IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestReadNetworkAdapter(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{
+		ID:                 "uuid-vm",
+		Name:               "vm",
+		MaxAdapters:        4,
+		DisabledAdapters:   map[uint32]bool{1: true},
+		AttachmentTypes:    map[uint32]vboxapi.NetworkAttachmentType{1: vboxapi.NetworkAttachmentTypeInternal},
+		InternalNetworks:   map[uint32]string{1: "lab"},
+		BridgedInterfaces:  map[uint32]string{1: "eth0"},
+		DisconnectedCables: map[uint32]bool{1: true},
+	})
+	c := newTestClient(api)
+	ctx := context.Background()
+
+	got, err := c.ReadNetworkAdapter(ctx, "uuid-vm", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &NetworkAdapterInfo{
+		Slot:           1,
+		AttachmentType: vboxapi.NetworkAttachmentTypeInternal,
+		Network:        "lab",
+		AdapterType:    vboxapi.NetworkAdapterTypeI82540EM,
+		MACAddress:     "080027000001",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadNetworkAdapter() = %+v, want %+v", got, want)
+	}
+
+	if _, err := c.ReadNetworkAdapter(ctx, "uuid-vm", 4); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("error = %v, want slot 4 to be out of range", err)
+	}
+	if _, err := c.ReadNetworkAdapter(ctx, "missing", 0); !IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestConfigureNetworkAdapter(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", MaxAdapters: 4, DisabledAdapters: map[uint32]bool{1: true}})
+	c := newTestClient(api)
+	ctx := context.Background()
+
+	enabled, connected := true, false
+	bridged := vboxapi.NetworkAttachmentTypeBridged
+	virtio := vboxapi.NetworkAdapterTypeVirtio
+	iface, mac := "eth0", "08:00:27:ab:cd:ef"
+	kbps := uint32(100000)
+	settings := NetworkAdapterSettings{
+		Enabled:        &enabled,
+		AttachmentType: &bridged,
+		Network:        &iface,
+		AdapterType:    &virtio,
+		MACAddress:     &mac,
+		CableConnected: &connected,
+		LineSpeedKbps:  &kbps,
+	}
+	if err := c.ConfigureNetworkAdapter(ctx, "uuid-vm", 1, settings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !api.lockShared {
+		t.Error("expected the adapter to be configured under a shared lock")
+	}
+
+	got, err := c.ReadNetworkAdapter(ctx, "uuid-vm", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &NetworkAdapterInfo{
+		Slot:           1,
+		Enabled:        true,
+		AttachmentType: vboxapi.NetworkAttachmentTypeBridged,
+		Network:        "eth0",
+		AdapterType:    vboxapi.NetworkAdapterTypeVirtio,
+		MACAddress:     "080027ABCDEF",
+		LineSpeedKbps:  100000,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("adapter = %+v, want %+v", got, want)
+	}
+
+	// Applying the same settings again changes nothing, even on a
+	// running VM.
+	api.machines["machine-vm"].State = vboxapi.MachineStateRunning
+	if err := c.ConfigureNetworkAdapter(ctx, "uuid-vm", 1, settings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, op := range []string{"SetAdapterEnabled", "SetAdapterBridgedInterface", "SetAdapterAttachmentType", "SetAdapterType", "SetAdapterMACAddress", "SetAdapterCableConnected", "SetAdapterLineSpeed"} {
+		if n := api.called(op); n != 1 {
+			t.Errorf("%s called %d times, want 1", op, n)
+		}
+	}
+
+	// The attachment of a running VM's adapter can change, its MAC
+	// address cannot.
+	intnet := vboxapi.NetworkAttachmentTypeInternal
+	network := "lab"
+	if err := c.ConfigureNetworkAdapter(ctx, "uuid-vm", 1, NetworkAdapterSettings{AttachmentType: &intnet, Network: &network}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := api.machines["machine-vm"].InternalNetworks[1]; got != "lab" {
+		t.Errorf("internal network = %q, want lab", got)
+	}
+	other := "080027000099"
+	err = c.ConfigureNetworkAdapter(ctx, "uuid-vm", 1, NetworkAdapterSettings{MACAddress: &other})
+	if err == nil || !strings.Contains(err.Error(), "power off the VM") {
+		t.Errorf("error = %v, want a running VM to be rejected", err)
+	}
+	if got := api.called("LockMachine"); got != api.called("UnlockSession") {
+		t.Errorf("LockMachine called %d times but UnlockSession %d times", got, api.called("UnlockSession"))
+	}
+}
+
+func TestConfigureNetworkAdapter_Validation(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", MaxAdapters: 4})
+	c := newTestClient(api)
+	ctx := context.Background()
+
+	hostOnly := vboxapi.NetworkAttachmentTypeHostOnly
+	unknown := vboxapi.NetworkAdapterType("virtio")
+	badMAC := "09:00:27:ab:cd:ef"
+	zero := uint32(0)
+	tests := []struct {
+		name     string
+		slot     uint32
+		settings NetworkAdapterSettings
+		want     string
+	}{
+		{"missing interface", 0, NetworkAdapterSettings{AttachmentType: &hostOnly}, "needs a host-only interface"},
+		{"unknown adapter type", 0, NetworkAdapterSettings{AdapterType: &unknown}, "unsupported adapter type"},
+		{"multicast MAC address", 0, NetworkAdapterSettings{MACAddress: &badMAC}, "multicast"},
+		{"zero line speed", 0, NetworkAdapterSettings{LineSpeedKbps: &zero}, "must be positive"},
+		{"slot out of range", 4, NetworkAdapterSettings{}, "out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.ConfigureNetworkAdapter(ctx, "uuid-vm", tt.slot, tt.settings)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+	if n := api.called("LockMachine"); n != 0 {
+		t.Errorf("LockMachine called %d times, want invalid settings to be rejected before locking", n)
+	}
+}
+
+func TestNormalizeMACAddress(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"080027ABCDEF", "080027ABCDEF", false},
+		{"08:00:27:ab:cd:ef", "080027ABCDEF", false},
+		{"08-00-27-AB-CD-EF", "080027ABCDEF", false},
+		{"080027abcde", "", true},
+		{"080027abcdeg", "", true},
+		{"08:00:27:ab:cd:ef:00", "", true},
+		{"09:00:27:ab:cd:ef", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeMACAddress(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeMACAddress(%q) = %q, %v; want %q, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestReadInternalNetworkMembers(t *testing.T) {
+	internal := func(slots ...uint32) map[uint32]vboxapi.NetworkAttachmentType {
+		types := make(map[uint32]vboxapi.NetworkAttachmentType)
+		for _, slot := range slots {
+			types[slot] = vboxapi.NetworkAttachmentTypeInternal
+		}
+		return types
+	}
+	api := newFakeAPI()
+	api.addMachine("machine-web", &fakeMachine{
+		ID:               "uuid-web",
+		Name:             "web",
+		MaxAdapters:      4,
+		AttachmentTypes:  internal(1, 2),
+		InternalNetworks: map[uint32]string{1: "backend", 2: "backend", 3: "backend"},
+	})
+	api.addMachine("machine-db", &fakeMachine{
+		ID:               "uuid-db",
+		Name:             "db",
+		MaxAdapters:      4,
+		AttachmentTypes:  internal(0, 1),
+		InternalNetworks: map[uint32]string{0: "backend", 1: "storage"},
+	})
+	// A disabled adapter does not join the network.
+	api.addMachine("machine-off", &fakeMachine{
+		ID:               "uuid-off",
+		Name:             "off",
+		MaxAdapters:      4,
+		AttachmentTypes:  internal(0),
+		InternalNetworks: map[uint32]string{0: "backend"},
+		DisabledAdapters: map[uint32]bool{0: true},
+	})
+	api.addMachine("machine-broken", &fakeMachine{ID: "uuid-broken", AccessError: "Could not find file broken.vbox"})
+	c := newTestClient(api)
+
+	got, err := c.ReadInternalNetworkMembers(context.Background(), "backend")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []InternalNetworkMember{
+		{MachineID: "uuid-db", MachineName: "db", Slot: 0},
+		{MachineID: "uuid-web", MachineName: "web", Slot: 1},
+		{MachineID: "uuid-web", MachineName: "web", Slot: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadInternalNetworkMembers() = %+v, want %+v", got, want)
+	}
+
+	got, err = c.ReadInternalNetworkMembers(context.Background(), "unused")
+	if err != nil || got == nil || len(got) != 0 {
+		t.Errorf("ReadInternalNetworkMembers() = %#v, %v, want no members", got, err)
+	}
+}
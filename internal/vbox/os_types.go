@@ -0,0 +1,48 @@
+package vbox
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// ReadGuestOSTypes lists the guest OS types VirtualBox knows, ordered by ID.
+// If familyID is not empty, only the types of that family are listed; it is
+// matched case-insensitively.
+func (c *Client) ReadGuestOSTypes(ctx context.Context, familyID string) ([]vboxapi.GuestOSType, error) {
+	var result []vboxapi.GuestOSType
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		osTypes, err := api.GetGuestOSTypes(ctx, session)
+		if err != nil {
+			return fmt.Errorf("failed to list guest OS types: %w", err)
+		}
+		result = make([]vboxapi.GuestOSType, 0, len(osTypes))
+		for _, t := range osTypes {
+			if familyID == "" || strings.EqualFold(t.FamilyID, familyID) {
+				result = append(result, t)
+			}
+		}
+		slices.SortFunc(result, func(a, b vboxapi.GuestOSType) int { return cmp.Compare(a.ID, b.ID) })
+		return nil
+	})
+	return result, err
+}
+
+// checkOSType checks that VirtualBox knows the guest OS type id, so that a
+// misspelled ID is reported before a machine is created with it.
+func checkOSType(ctx context.Context, api vboxapi.VBoxAPI, session, id string) error {
+	osTypes, err := api.GetGuestOSTypes(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to list guest OS types: %w", err)
+	}
+	for _, t := range osTypes {
+		if strings.EqualFold(t.ID, id) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown OS type %q: the VirtualBox host has no such guest OS type; the vboxweb_os_types data source lists the valid IDs", id)
+}
@@ -0,0 +1,48 @@
+package vbox
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestReadGuestOSTypes(t *testing.T) {
+	api := newFakeAPI()
+	api.guestOSTypes = []vboxapi.GuestOSType{
+		{ID: "Windows11_64", Description: "Windows 11 (64-bit)", FamilyID: "Windows", Is64Bit: true},
+		{ID: "Ubuntu_64", Description: "Ubuntu (64-bit)", FamilyID: "Linux", Is64Bit: true},
+		{ID: "Debian", Description: "Debian (32-bit)", FamilyID: "Linux"},
+	}
+	c := newTestClient(api)
+	ctx := context.Background()
+
+	got, err := c.ReadGuestOSTypes(ctx, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []string
+	for _, osType := range got {
+		ids = append(ids, osType.ID)
+	}
+	if want := []string{"Debian", "Ubuntu_64", "Windows11_64"}; !slices.Equal(ids, want) {
+		t.Errorf("ReadGuestOSTypes() IDs = %v, want %v", ids, want)
+	}
+
+	got, err = c.ReadGuestOSTypes(ctx, "linux")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != "Debian" || got[1] != api.guestOSTypes[1] {
+		t.Errorf("ReadGuestOSTypes(linux) = %+v, want the Linux types", got)
+	}
+
+	got, err = c.ReadGuestOSTypes(ctx, "Solaris")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("ReadGuestOSTypes(Solaris) = %#v, want an empty list", got)
+	}
+}
@@ -2,8 +2,10 @@ package vbox
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
+	"sync"
 
 	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
 )
@@ -30,6 +32,9 @@ type PortAllocatorOptions struct {
 	Scope HostIPScope
 	// IncludeNATNetworks includes NAT Network port forward rules in conflict detection.
 	IncludeNATNetworks bool
+	// Concurrency is how many VMs are read at once while collecting used
+	// ports. Zero uses DefaultPortScanConcurrency.
+	Concurrency int
 }
 
 // DefaultPortAllocatorOptions returns default options for port allocation.
@@ -49,10 +54,20 @@ type UsedPort struct {
 	HostIP string
 }
 
+// DefaultPortScanConcurrency is how many VMs CollectUsedPorts reads at once
+// unless PortAllocatorOptions.Concurrency is set.
+const DefaultPortScanConcurrency = 8
+
 // CollectUsedPorts enumerates all NAT port forwarding rules across all VMs (and optionally
-// NAT Networks) and returns the set of used host ports.
-func CollectUsedPorts(ctx context.Context, api vboxapi.VBoxAPI, session string, includeNATNetworks bool) ([]UsedPort, error) {
-	var usedPorts []UsedPort
+// NAT Networks) and returns the set of used host ports. A host without VMs or
+// NAT networks has no used ports; only failing to enumerate the VMs is an
+// error, since ports could then be allocated twice. Up to concurrency VMs are
+// read at once; zero or less uses DefaultPortScanConcurrency. The ports are
+// returned in no particular order.
+func CollectUsedPorts(ctx context.Context, api vboxapi.VBoxAPI, session string, includeNATNetworks bool, concurrency int) ([]UsedPort, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultPortScanConcurrency
+	}
 
 	// Get all machines
 	machineRefs, err := api.GetMachines(ctx, session)
@@ -60,40 +75,46 @@ func CollectUsedPorts(ctx context.Context, api vboxapi.VBoxAPI, session string,
 		return nil, fmt.Errorf("failed to enumerate machines: %w", err)
 	}
 
-	// For each machine, check all network adapter slots (0-7)
-	for _, machineRef := range machineRefs {
-		for slot := uint32(0); slot <= 7; slot++ {
-			adapterRef, err := api.GetNetworkAdapter(ctx, machineRef, slot)
-			if err != nil {
-				// Adapter might not exist or not accessible, skip
-				continue
-			}
-
-			natEngineRef, err := api.GetNATEngine(ctx, adapterRef)
-			if err != nil {
-				// NAT engine might not be available (different attachment type)
-				continue
-			}
-
-			redirects, err := api.GetNATRedirects(ctx, natEngineRef)
-			if err != nil {
-				continue
-			}
-
-			for _, r := range redirects {
-				usedPorts = append(usedPorts, UsedPort{
-					Port:   r.HostPort,
-					HostIP: r.HostIP,
-				})
+	var (
+		mu        sync.Mutex
+		usedPorts []UsedPort
+		wg        sync.WaitGroup
+	)
+	refs := make(chan string)
+	for range min(concurrency, len(machineRefs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for machineRef := range refs {
+				ports := machineUsedPorts(ctx, api, machineRef)
+				mu.Lock()
+				usedPorts = append(usedPorts, ports...)
+				mu.Unlock()
 			}
+		}()
+	}
+	for _, machineRef := range machineRefs {
+		if machineRef != "" {
+			refs <- machineRef
 		}
 	}
+	close(refs)
+	wg.Wait()
+
+	// Rules of machines whose calls were canceled are missing.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Optionally include NAT Network rules
 	if includeNATNetworks {
 		natNetworkRefs, err := api.GetNATNetworks(ctx, session)
-		if err == nil { // Ignore errors - NAT Networks might not be available
+		// Ignore errors: some builds fail rather than report no NAT networks.
+		if err == nil {
 			for _, natNetRef := range natNetworkRefs {
+				if natNetRef == "" {
+					continue
+				}
 				rules, err := api.GetNATNetworkPortForwardRules4(ctx, natNetRef)
 				if err != nil {
 					continue
@@ -112,6 +133,57 @@ func CollectUsedPorts(ctx context.Context, api vboxapi.VBoxAPI, session string,
 	return usedPorts, nil
 }
 
+// machineUsedPorts returns the host ports of the NAT rules of the network
+// adapter slots (0-7) of a machine. Slots that cannot be read are skipped.
+// Probing stops at the first slot VirtualBox rejects as out of range, which
+// only saves calls on chipsets with fewer than 8 slots: the x86 chipsets have
+// 8 (PIIX3) or 36 (ICH9).
+func machineUsedPorts(ctx context.Context, api vboxapi.VBoxAPI, machineRef string) []UsedPort {
+	var usedPorts []UsedPort
+	for slot := uint32(0); slot <= 7; slot++ {
+		adapterRef, err := api.GetNetworkAdapter(ctx, machineRef, slot)
+		if isInvalidSlot(err) {
+			break
+		}
+		if err != nil {
+			// Adapter might not be accessible, skip
+			continue
+		}
+
+		// Disabled adapters forward nothing. Should the check fail, look
+		// at the NAT engine anyway rather than miss a used port.
+		if enabled, err := api.GetAdapterEnabled(ctx, adapterRef); err == nil && !enabled {
+			continue
+		}
+
+		natEngineRef, err := api.GetNATEngine(ctx, adapterRef)
+		if err != nil {
+			// NAT engine might not be available (different attachment type)
+			continue
+		}
+
+		redirects, err := api.GetNATRedirects(ctx, natEngineRef)
+		if err != nil {
+			continue
+		}
+
+		for _, r := range redirects {
+			usedPorts = append(usedPorts, UsedPort{
+				Port:   r.HostPort,
+				HostIP: r.HostIP,
+			})
+		}
+	}
+	return usedPorts
+}
+
+// isInvalidSlot reports whether err is VirtualBox rejecting a network
+// adapter slot beyond the machine's chipset limit.
+func isInvalidSlot(err error) bool {
+	var vErr *vboxapi.Error
+	return errors.As(err, &vErr) && vErr.ResultCode == vboxapi.ResultInvalidArg
+}
+
 // SelectAvailablePort selects an available port from the given range that does not
 // conflict with any used ports.
 func SelectAvailablePort(usedPorts []UsedPort, opts PortAllocatorOptions) (uint16, error) {
@@ -157,13 +229,45 @@ func SelectAvailablePort(usedPorts []UsedPort, opts PortAllocatorOptions) (uint1
 
 // AllocatePort is a convenience function that collects used ports and selects an available one.
 func AllocatePort(ctx context.Context, api vboxapi.VBoxAPI, session string, opts PortAllocatorOptions) (uint16, error) {
-	usedPorts, err := CollectUsedPorts(ctx, api, session, opts.IncludeNATNetworks)
+	usedPorts, err := CollectUsedPorts(ctx, api, session, opts.IncludeNATNetworks, opts.Concurrency)
 	if err != nil {
 		return 0, err
 	}
 	return SelectAvailablePort(usedPorts, opts)
 }
 
+// autoRangeSet records the auto allocation ranges requested through a
+// Client, so that rules of the same apply whose ranges may contend can be
+// reported.
+type autoRangeSet struct {
+	mu     sync.Mutex
+	ranges []PortAllocatorOptions
+}
+
+// add records opts and returns the earlier ranges that overlap it with a
+// different host IP or scope. Overlapping requests that bind alike are
+// expected, e.g. rules sharing the default range, and are not returned.
+func (s *autoRangeSet) add(opts PortAllocatorOptions) []PortAllocatorOptions {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var overlaps []PortAllocatorOptions
+	known := false
+	for _, r := range s.ranges {
+		if r.MinPort > opts.MaxPort || opts.MinPort > r.MaxPort {
+			continue
+		}
+		if r.HostIP == opts.HostIP && r.Scope == opts.Scope {
+			known = known || (r.MinPort == opts.MinPort && r.MaxPort == opts.MaxPort)
+			continue
+		}
+		overlaps = append(overlaps, r)
+	}
+	if !known {
+		s.ranges = append(s.ranges, opts)
+	}
+	return overlaps
+}
+
 // UsedPortsByPort returns a sorted list of unique ports that are in use.
 func UsedPortsByPort(usedPorts []UsedPort) []uint16 {
 	seen := make(map[uint16]bool)
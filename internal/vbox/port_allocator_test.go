@@ -1,7 +1,13 @@
 package vbox
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"testing"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
 )
 
 func TestSelectAvailablePort(t *testing.T) {
@@ -176,6 +182,87 @@ func TestSelectAvailablePort(t *testing.T) {
 	}
 }
 
+func TestCollectUsedPorts_SkipsDisabledAdapters(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-1", &fakeMachine{
+		ID:               "uuid-1",
+		MaxAdapters:      2,
+		DisabledAdapters: map[uint32]bool{1: true},
+		NATRedirects: map[uint32][]vboxapi.NATRedirect{
+			0: {{Name: "ssh", HostPort: 2222}},
+			1: {{Name: "stale", HostPort: 3333}},
+		},
+	})
+
+	got, err := CollectUsedPorts(context.Background(), api, "session", false, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(got) != 1 || got[0].Port != 2222 {
+		t.Errorf("CollectUsedPorts() = %+v, want only port 2222", got)
+	}
+	if n := api.called("GetNATEngine"); n != 1 {
+		t.Errorf("GetNATEngine called %d times, want 1", n)
+	}
+}
+
+func TestCollectUsedPorts_StopsAtInvalidSlot(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-1", &fakeMachine{
+		ID:           "uuid-1",
+		MaxAdapters:  2,
+		NATRedirects: map[uint32][]vboxapi.NATRedirect{1: {{Name: "ssh", HostPort: 2222}}},
+	})
+
+	got, err := CollectUsedPorts(context.Background(), api, "session", false, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Port != 2222 {
+		t.Errorf("CollectUsedPorts() = %+v, want only port 2222", got)
+	}
+	// Slots 0 and 1, then slot 2 is out of range.
+	if n := api.called("GetNetworkAdapter"); n != 3 {
+		t.Errorf("GetNetworkAdapter called %d times, want 3", n)
+	}
+}
+
+func TestCollectUsedPorts_Concurrency(t *testing.T) {
+	api := newFakeAPI()
+	var want []uint16
+	for i := range 20 {
+		port := uint16(30000 + i)
+		api.addMachine(fmt.Sprintf("machine-%d", i), &fakeMachine{
+			ID:           fmt.Sprintf("uuid-%d", i),
+			MaxAdapters:  2,
+			NATRedirects: map[uint32][]vboxapi.NATRedirect{1: {{Name: "ssh", HostPort: port}}},
+		})
+		want = append(want, port)
+	}
+
+	for _, concurrency := range []int{0, 1, 3, 50} {
+		got, err := CollectUsedPorts(context.Background(), api, "session", false, concurrency)
+		if err != nil {
+			t.Fatalf("concurrency %d: unexpected error: %v", concurrency, err)
+		}
+		ports := make([]uint16, 0, len(got))
+		for _, up := range got {
+			ports = append(ports, up.Port)
+		}
+		slices.Sort(ports)
+		if !slices.Equal(ports, want) {
+			t.Errorf("concurrency %d: ports = %v, want %v", concurrency, ports, want)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := CollectUsedPorts(ctx, api, "session", false, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+}
+
 func TestDefaultPortAllocatorOptions(t *testing.T) {
 	opts := DefaultPortAllocatorOptions()
 
@@ -256,3 +343,68 @@ func TestHostIPScopeConstants(t *testing.T) {
 		t.Errorf("HostIPScopeExact = %q, want %q", HostIPScopeExact, "exact")
 	}
 }
+
+func TestAllocatePort_EmptyHost(t *testing.T) {
+	opts := PortAllocatorOptions{MinPort: 20000, MaxPort: 20010, Scope: HostIPScopeAny, IncludeNATNetworks: true}
+
+	tests := []struct {
+		name           string
+		natNetworksErr error
+	}{
+		{name: "no VMs or NAT networks"},
+		{name: "NAT networks not available", natNetworksErr: errors.New("no such attribute")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.natNetworksErr = tt.natNetworksErr
+
+			port, err := AllocatePort(context.Background(), api, "session", opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if port != opts.MinPort {
+				t.Errorf("AllocatePort() = %d, want %d", port, opts.MinPort)
+			}
+		})
+	}
+}
+
+func TestAllocatePort_NATNetworkRules(t *testing.T) {
+	api := newFakeAPI()
+	api.natNetworks = map[string][]vboxapi.NATRedirect{
+		"natnet-1": {{Name: "web", HostPort: 20000}},
+	}
+
+	port, err := AllocatePort(context.Background(), api, "session",
+		PortAllocatorOptions{MinPort: 20000, MaxPort: 20010, Scope: HostIPScopeAny, IncludeNATNetworks: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if port != 20001 {
+		t.Errorf("AllocatePort() = %d, want 20001", port)
+	}
+}
+
+func TestRecordAutoRange(t *testing.T) {
+	c := newTestClient(newFakeAPI())
+	anyIP := PortAllocatorOptions{MinPort: 20000, MaxPort: 20100, Scope: HostIPScopeAny}
+
+	if got := c.RecordAutoRange(anyIP); len(got) != 0 {
+		t.Fatalf("first range reported overlaps: %+v", got)
+	}
+	// Rules sharing a range and binding alike are expected.
+	if got := c.RecordAutoRange(anyIP); len(got) != 0 {
+		t.Errorf("identical range reported overlaps: %+v", got)
+	}
+	disjoint := PortAllocatorOptions{MinPort: 30000, MaxPort: 30100, HostIP: "127.0.0.1", Scope: HostIPScopeExact}
+	if got := c.RecordAutoRange(disjoint); len(got) != 0 {
+		t.Errorf("disjoint range reported overlaps: %+v", got)
+	}
+
+	exact := PortAllocatorOptions{MinPort: 20050, MaxPort: 20150, HostIP: "127.0.0.1", Scope: HostIPScopeExact}
+	got := c.RecordAutoRange(exact)
+	if len(got) != 1 || got[0] != anyIP {
+		t.Errorf("RecordAutoRange() = %+v, want [%+v]", got, anyIP)
+	}
+}
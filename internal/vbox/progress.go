@@ -0,0 +1,144 @@
+package vbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// ProgressPollConfig sets how often a Client polls a long-running
+// operation. The first poll waits Initial, and each following one waits
+// Multiplier times longer, up to Max: short operations finish responsively,
+// while long ones, such as full clones of large disks, do not flood the web
+// service with requests.
+type ProgressPollConfig struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// DefaultProgressPollConfig is the polling used unless
+// SetProgressPollConfig is called.
+var DefaultProgressPollConfig = ProgressPollConfig{
+	Initial:    500 * time.Millisecond,
+	Max:        10 * time.Second,
+	Multiplier: 2,
+}
+
+// initial returns the wait before the second poll.
+func (p ProgressPollConfig) initial() time.Duration {
+	if p.Initial <= 0 {
+		return min(DefaultProgressPollConfig.Initial, p.max())
+	}
+	return min(p.Initial, p.max())
+}
+
+func (p ProgressPollConfig) max() time.Duration {
+	if p.Max <= 0 {
+		return max(DefaultProgressPollConfig.Max, p.Initial)
+	}
+	return p.Max
+}
+
+// next returns the wait that follows a wait of d.
+func (p ProgressPollConfig) next(d time.Duration) time.Duration {
+	m := p.Multiplier
+	if m <= 0 {
+		m = DefaultProgressPollConfig.Multiplier
+	}
+	if float64(d)*m >= float64(p.max()) {
+		return p.max()
+	}
+	return max(time.Duration(float64(d)*m), d)
+}
+
+// ProgressInfo describes a VirtualBox progress object, i.e. a long-running
+// operation such as a clone or a snapshot.
+type ProgressInfo struct {
+	Ref string
+	// Description describes the whole task, Operation the step in progress.
+	Description string
+	Operation   string
+	Percent     uint32
+	Cancelable  bool
+	Completed   bool
+	// ResultCode and ErrorText are only set once the progress completed.
+	ResultCode int32
+	ErrorText  string
+}
+
+// ReadProgress reports the state of a progress. VirtualBox cannot list the
+// progresses of a machine, so the ref must come from whoever started the
+// operation, and it stays valid only as long as that web service session.
+// With a positive wait, ReadProgress first waits up to wait for the progress
+// to complete; a progress still running afterwards is not an error.
+func (c *Client) ReadProgress(ctx context.Context, progressRef string, wait time.Duration) (*ProgressInfo, error) {
+	var info *ProgressInfo
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, _ string) error {
+		if wait > 0 {
+			if err := c.waitProgressCompleted(ctx, api, progressRef, wait); err != nil {
+				return err
+			}
+		}
+		var err error
+		info, err = readProgress(ctx, api, progressRef)
+		return err
+	})
+	return info, err
+}
+
+// waitProgressCompleted polls a progress until it completes or wait
+// elapses. Unlike waitProgress, it does not check the result.
+func (c *Client) waitProgressCompleted(ctx context.Context, api vboxapi.VBoxAPI, progressRef string, wait time.Duration) error {
+	deadline := time.Now().Add(wait)
+	interval := c.progressPoll.initial()
+	for {
+		completed, err := api.GetProgressCompleted(ctx, progressRef)
+		if err != nil {
+			return fmt.Errorf("failed to get progress %s completion status: %w", progressRef, err)
+		}
+		if completed || !time.Now().Before(deadline) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(min(interval, time.Until(deadline))):
+		}
+		interval = c.progressPoll.next(interval)
+	}
+}
+
+func readProgress(ctx context.Context, api vboxapi.VBoxAPI, progressRef string) (*ProgressInfo, error) {
+	info := &ProgressInfo{Ref: progressRef}
+	var err error
+	if info.Description, err = api.GetProgressDescription(ctx, progressRef); err != nil {
+		return nil, fmt.Errorf("failed to get progress %s description: %w", progressRef, err)
+	}
+	if info.Operation, err = api.GetProgressOperationDescription(ctx, progressRef); err != nil {
+		return nil, fmt.Errorf("failed to get progress %s operation: %w", progressRef, err)
+	}
+	if info.Percent, err = api.GetProgressPercent(ctx, progressRef); err != nil {
+		return nil, fmt.Errorf("failed to get progress %s percent: %w", progressRef, err)
+	}
+	if info.Cancelable, err = api.GetProgressCancelable(ctx, progressRef); err != nil {
+		return nil, fmt.Errorf("failed to get progress %s cancelable: %w", progressRef, err)
+	}
+	if info.Completed, err = api.GetProgressCompleted(ctx, progressRef); err != nil {
+		return nil, fmt.Errorf("failed to get progress %s completion status: %w", progressRef, err)
+	}
+	if !info.Completed {
+		return info, nil
+	}
+	if info.ResultCode, err = api.GetProgressResultCode(ctx, progressRef); err != nil {
+		return nil, fmt.Errorf("failed to get progress %s result code: %w", progressRef, err)
+	}
+	if info.ResultCode != 0 {
+		if info.ErrorText, err = api.GetProgressErrorText(ctx, progressRef); err != nil {
+			return nil, fmt.Errorf("failed to get progress %s error: %w", progressRef, err)
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,163 @@
+package vbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestReadProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress fakeProgress
+		wait     time.Duration
+		want     ProgressInfo
+	}{
+		{
+			name:     "running",
+			progress: fakeProgress{Description: "Cloning machine", Operation: "Copying hard disk", Percent: 40, Cancelable: true, PendingPolls: 10},
+			want:     ProgressInfo{Ref: "progress-1", Description: "Cloning machine", Operation: "Copying hard disk", Percent: 40, Cancelable: true},
+		},
+		{
+			name:     "completed after waiting",
+			progress: fakeProgress{Description: "Cloning machine", Percent: 40, PendingPolls: 2},
+			wait:     time.Second,
+			want:     ProgressInfo{Ref: "progress-1", Description: "Cloning machine", Percent: 100, Completed: true},
+		},
+		{
+			name:     "failed",
+			progress: fakeProgress{Description: "Taking snapshot", ResultCode: vboxapi.ResultFileError, ErrorText: "disk full"},
+			want:     ProgressInfo{Ref: "progress-1", Description: "Taking snapshot", Percent: 100, Completed: true, ResultCode: vboxapi.ResultFileError, ErrorText: "disk full"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			p := tt.progress
+			api.progresses["progress-1"] = &p
+			c := newTestClient(api)
+
+			got, err := c.ReadProgress(context.Background(), "progress-1", tt.wait)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("ReadProgress() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadProgress_InvalidRef(t *testing.T) {
+	c := newTestClient(newFakeAPI())
+
+	if _, err := c.ReadProgress(context.Background(), "progress-gone", 0); err == nil {
+		t.Fatal("expected an error for an unknown progress")
+	}
+}
+
+func TestWaitProgress_Canceled(t *testing.T) {
+	api := newFakeAPI()
+	p := &fakeProgress{Description: "Cloning machine", Cancelable: true, PendingPolls: 1000}
+	api.progresses["progress-1"] = p
+	c := newTestClient(api)
+	// A poll interval longer than the test timeout: waitProgress must not
+	// sleep through the cancellation.
+	c.SetProgressPollConfig(ProgressPollConfig{Initial: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.waitProgress(ctx, api, "progress-1", 0) }()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waitProgress did not return after cancellation")
+	}
+	if !p.Canceled {
+		t.Error("expected the progress to be canceled")
+	}
+}
+
+func TestProgressPollConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  ProgressPollConfig
+		want []time.Duration
+	}{
+		{
+			name: "default",
+			cfg:  DefaultProgressPollConfig,
+			want: []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second},
+		},
+		{
+			name: "zero uses defaults",
+			want: []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second},
+		},
+		{
+			name: "custom",
+			cfg:  ProgressPollConfig{Initial: time.Second, Max: 5 * time.Second, Multiplier: 1.5},
+			want: []time.Duration{time.Second, 1500 * time.Millisecond, 2250 * time.Millisecond, 3375 * time.Millisecond, 5 * time.Second},
+		},
+		{
+			name: "fixed",
+			cfg:  ProgressPollConfig{Initial: 2 * time.Second, Multiplier: 1},
+			want: []time.Duration{2 * time.Second, 2 * time.Second, 2 * time.Second},
+		},
+		{
+			name: "initial above max",
+			cfg:  ProgressPollConfig{Initial: time.Minute, Max: 10 * time.Second},
+			want: []time.Duration{10 * time.Second, 10 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.cfg.initial()
+			for i, want := range tt.want {
+				if d != want {
+					t.Fatalf("wait %d = %v, want %v", i, d, want)
+				}
+				d = tt.cfg.next(d)
+			}
+		})
+	}
+}
+
+func TestWaitProgress_LogsProgress(t *testing.T) {
+	api := newFakeAPI()
+	api.progresses["progress-1"] = &fakeProgress{Description: "Cloning machine", Operation: "Copying hard disk", Percent: 42, PendingPolls: 2}
+	c := newTestClient(api)
+
+	var out bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &out)
+	if err := c.waitProgress(ctx, api, "progress-1", time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&out)
+	if err != nil {
+		t.Fatalf("failed to decode log: %v", err)
+	}
+	var messages []string
+	for _, e := range entries {
+		if e["@level"] == "info" && e["progress"] == "progress-1" {
+			messages = append(messages, e["@message"].(string))
+		}
+	}
+	want := []string{"Copying hard disk: 42%", "Copying hard disk: 100%"}
+	if !slices.Equal(messages, want) {
+		t.Errorf("progress log = %q, want %q", messages, want)
+	}
+}
@@ -0,0 +1,156 @@
+package vbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// maxIdleSessions is how many logged-on sessions a Client keeps for reuse.
+// It matches Terraform's default parallelism, so that a typical apply logs
+// on about once per concurrent operation.
+const maxIdleSessions = 10
+
+// logoffTimeout bounds the Logoff calls made when a session is dropped.
+const logoffTimeout = 10 * time.Second
+
+// webSession is a logged-on webservice session and the adapter it was
+// opened with.
+type webSession struct {
+	api vboxapi.VBoxAPI
+	ref string
+}
+
+// sessionPool holds the idle sessions of a Client. A session is used by one
+// operation at a time: each websession has a single ISession object, which
+// concurrent operations locking different machines would fight over.
+type sessionPool struct {
+	mu     sync.Mutex
+	idle   []webSession
+	closed bool
+}
+
+// get returns an idle session, if any.
+func (p *sessionPool) get() (webSession, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.idle) == 0 {
+		return webSession{}, false
+	}
+	s := p.idle[len(p.idle)-1]
+	p.idle = p.idle[:len(p.idle)-1]
+	return s, true
+}
+
+// put keeps s for reuse. It reports false when the pool is full or closed,
+// in which case the caller logs s off.
+func (p *sessionPool) put(s webSession) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || len(p.idle) >= maxIdleSessions {
+		return false
+	}
+	p.idle = append(p.idle, s)
+	return true
+}
+
+// close empties the pool and stops it from keeping sessions. It returns the
+// sessions that were idle.
+func (p *sessionPool) close() []webSession {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	return idle
+}
+
+// withSession runs fn with a logged-on session. Sessions are reused across
+// calls; one that the webservice no longer knows, e.g. because it timed
+// out, is replaced by a new one and fn is run again. Sessions whose
+// operation failed are logged off rather than reused, since a failure may
+// have left their ISession locked.
+func (c *Client) withSession(ctx context.Context, fn func(ctx context.Context, api vboxapi.VBoxAPI, session string) error) error {
+	s, reused := c.sessions.get()
+	if !reused {
+		var err error
+		if s, err = c.logon(ctx); err != nil {
+			return err
+		}
+	}
+
+	err := fn(ctx, s.api, s.ref)
+	if reused && vboxapi.IsInvalidObject(err, s.ref) {
+		// The session is gone: there is nothing to log off.
+		if s, err = c.logon(ctx); err != nil {
+			return err
+		}
+		err = fn(ctx, s.api, s.ref)
+	}
+
+	if err != nil || !c.sessions.put(s) {
+		logoff(s)
+	}
+	return err
+}
+
+// logon opens a new session.
+func (c *Client) logon(ctx context.Context) (webSession, error) {
+	api := c.newAPI()
+	ref, err := api.Logon(ctx, c.username, c.password)
+	if err != nil {
+		return webSession{}, err
+	}
+	return webSession{api: api, ref: ref}, nil
+}
+
+// logoff closes s, best-effort. It does not use the operation's context,
+// which may be canceled.
+func logoff(s webSession) error {
+	ctx, cancel := context.WithTimeout(context.Background(), logoffTimeout)
+	defer cancel()
+	return s.api.Logoff(ctx, s.ref)
+}
+
+// Close logs off the sessions kept for reuse. The Client remains usable,
+// but later operations log on and off each time.
+func (c *Client) Close() error {
+	var errs []error
+	for _, s := range c.sessions.close() {
+		errs = append(errs, logoff(s))
+	}
+	return errors.Join(errs...)
+}
+
+// unlockSession releases the lock that sessObj holds on a machine. It keeps
+// the operation's logger but not its cancellation, so that a canceled
+// operation still unlocks. A session that is not locked, or no longer
+// exists, is expected after some failures; any other failure is logged as a
+// warning, since the machine may stay locked for later operations.
+func unlockSession(ctx context.Context, api vboxapi.VBoxAPI, sessObj string) {
+	err := api.UnlockSession(context.WithoutCancel(ctx), sessObj)
+	if err == nil {
+		return
+	}
+	fields := map[string]interface{}{"session": sessObj, "error": err.Error()}
+	if benignUnlockError(err, sessObj) {
+		tflog.Debug(ctx, "Session was not locked", fields)
+		return
+	}
+	tflog.Warn(ctx, "Failed to unlock session; the machine may stay locked for later operations", fields)
+}
+
+// benignUnlockError reports whether err only says that sessObj held no
+// lock to release.
+func benignUnlockError(err error, sessObj string) bool {
+	var vErr *vboxapi.Error
+	if errors.As(err, &vErr) && vErr.ResultCode == vboxapi.ResultInvalidSessionState {
+		return true
+	}
+	return vboxapi.IsInvalidObject(err, sessObj)
+}
@@ -0,0 +1,178 @@
+package vbox
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestWithSession_ReusesSession(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetStateByID(context.Background(), "uuid-vm"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := api.called("Logon"); n != 1 {
+		t.Errorf("Logon called %d times, want 1", n)
+	}
+	if n := api.called("Logoff"); n != 0 {
+		t.Errorf("Logoff called %d times before Close, want 0", n)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("Logoff"); n != 1 {
+		t.Errorf("Logoff called %d times after Close, want 1", n)
+	}
+
+	// A closed client still works, without keeping sessions.
+	if _, err := c.GetStateByID(context.Background(), "uuid-vm"); err != nil {
+		t.Fatalf("unexpected error after Close: %v", err)
+	}
+	if n := api.called("Logoff"); n != 2 {
+		t.Errorf("Logoff called %d times, want 2", n)
+	}
+}
+
+func TestWithSession_ExpiredSession(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	if _, err := c.GetStateByID(context.Background(), "uuid-vm"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	api.expiredSessions = map[string]bool{"session-1": true}
+
+	st, err := c.GetStateByID(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("expected the expired session to be replaced, got %v", err)
+	}
+	if st != vboxapi.MachineStatePoweredOff {
+		t.Errorf("state = %q, want %q", st, vboxapi.MachineStatePoweredOff)
+	}
+	if n := api.called("Logon"); n != 2 {
+		t.Errorf("Logon called %d times, want 2", n)
+	}
+	if n := api.called("Logoff"); n != 0 {
+		t.Errorf("Logoff called %d times, want 0: the expired session is already gone", n)
+	}
+}
+
+func TestWithSession_DropsSessionAfterFailure(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	if _, err := c.GetStateByID(context.Background(), "missing"); !IsNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	if n := api.called("Logoff"); n != 1 {
+		t.Errorf("Logoff called %d times, want 1", n)
+	}
+
+	if _, err := c.GetStateByID(context.Background(), "uuid-vm"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("Logon"); n != 2 {
+		t.Errorf("Logon called %d times, want 2", n)
+	}
+}
+
+func TestWithSession_ConcurrentCallsUseDistinctSessions(t *testing.T) {
+	api := newFakeAPI()
+	c := newTestClient(api)
+
+	var mu sync.Mutex
+	inUse := make(map[string]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c.withSession(context.Background(), func(_ context.Context, _ vboxapi.VBoxAPI, session string) error {
+				mu.Lock()
+				if inUse[session] {
+					t.Errorf("session %s used by two operations at once", session)
+				}
+				inUse[session] = true
+				mu.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				delete(inUse, session)
+				mu.Unlock()
+				return nil
+			})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if idle := len(c.sessions.idle); idle > maxIdleSessions {
+		t.Errorf("%d idle sessions kept, want at most %d", idle, maxIdleSessions)
+	}
+}
+
+func TestUnlockSession_LogsFailure(t *testing.T) {
+	tests := []struct {
+		name      string
+		unlockErr error
+		wantLevel string
+	}{
+		{
+			name:      "failure",
+			unlockErr: &vboxapi.Error{Operation: "ISession_unlockMachine", ResultCode: vboxapi.ResultUnexpected, Text: "machine lock is held by another process"},
+			wantLevel: "warn",
+		},
+		{
+			name:      "not locked",
+			unlockErr: &vboxapi.Error{Operation: "ISession_unlockMachine", ResultCode: vboxapi.ResultInvalidSessionState, Text: "Machine is not locked by session"},
+			wantLevel: "debug",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+			api.unlockErr = tt.unlockErr
+			c := newTestClient(api)
+
+			var out bytes.Buffer
+			ctx := tflogtest.RootLogger(context.Background(), &out)
+			err := c.ApplyMachineSettings(ctx, "uuid-vm", MachineSettings{BootMenuMode: vboxapi.BootMenuModeDisabled})
+			if err != nil {
+				t.Fatalf("unlock failures must not fail the operation: %v", err)
+			}
+
+			entries, err := tflogtest.MultilineJSONDecode(&out)
+			if err != nil {
+				t.Fatalf("failed to decode log: %v", err)
+			}
+			var levels []string
+			for _, e := range entries {
+				if e["session"] == "session-object" {
+					levels = append(levels, e["@level"].(string))
+				}
+			}
+			if !slices.Equal(levels, []string{tt.wantLevel}) {
+				t.Errorf("unlock log levels = %v, want [%s]; log: %v", levels, tt.wantLevel, entries)
+			}
+		})
+	}
+}
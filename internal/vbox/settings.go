@@ -0,0 +1,976 @@
+package vbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// MachineSettings holds VM settings managed by vboxweb_machine. Empty fields
+// are left unchanged when applied.
+type MachineSettings struct {
+	// ExecutionEngine selects how guest code is run, e.g. NativeApi when
+	// VirtualBox must coexist with Hyper-V.
+	ExecutionEngine vboxapi.ExecutionEngine
+	// VRDEKeyboardLayout is the keyboard layout hint for RDP sessions.
+	// nil leaves it unchanged and an empty string removes it.
+	VRDEKeyboardLayout *string
+	// CPUIDLeaves are set as CPUID overrides. RemoveCPUIDLeaves are
+	// removed; only their Leaf and SubLeaf are used. Only x86 VMs have
+	// CPUID overrides: they are not read for ARM VMs, and setting them is
+	// an error.
+	CPUIDLeaves       []vboxapi.CPUIDLeaf
+	RemoveCPUIDLeaves []vboxapi.CPUIDLeaf
+	// GraphicsController is the emulated graphics controller, e.g. VMSVGA
+	// for modern Linux guests.
+	GraphicsController vboxapi.GraphicsController
+	// VRAMMB is the video memory size in MB.
+	VRAMMB uint32
+	// MemoryMB is the guest memory size in MB.
+	MemoryMB uint32
+	// PageFusion shares identical memory pages between VMs, saving host
+	// memory when many similar VMs run; nil leaves it unchanged. Only some
+	// hosts support it: on others, the VM may fail to start.
+	PageFusion *bool
+	// CPUCount is the number of guest CPUs. With CPU hot-plug enabled, it
+	// is the number of attached CPUs, which can change while the VM runs,
+	// up to its CPU slots.
+	CPUCount uint32
+	// Accelerate3D enables 3D acceleration; nil leaves it unchanged.
+	Accelerate3D *bool
+	// MonitorCount is the number of virtual monitors.
+	MonitorCount uint32
+	// APIC and X2APIC enable the APIC and its x2APIC mode; nil leaves them
+	// unchanged. x2APIC requires the APIC.
+	APIC   *bool
+	X2APIC *bool
+	// PAE and LongMode enable the PAE and long mode (64-bit) CPU features;
+	// nil leaves them unchanged. Long mode requires PAE. Only x86 VMs have
+	// them: they are left nil for ARM VMs, and cannot be applied to one.
+	PAE      *bool
+	LongMode *bool
+	// BootMenuMode controls whether the firmware shows its boot menu,
+	// which delays unattended boots.
+	BootMenuMode vboxapi.BootMenuMode
+	// Owner identifies who the VM belongs to on shared hosts. It is stored
+	// in the OwnerExtraDataKey extra data item; nil leaves it unchanged and
+	// an empty string removes it.
+	Owner *string
+	// AutostartEnabled starts the VM when the host boots, AutostartDelay
+	// seconds after the autostart service runs; nil leaves them unchanged.
+	// Autostart also needs the host's autostart service to be configured.
+	AutostartEnabled *bool
+	AutostartDelay   *uint32
+	// ClipboardFileTransfers allows copying files through the shared
+	// clipboard; nil leaves it unchanged.
+	ClipboardFileTransfers *bool
+	// TimeSyncDisabled stops the Guest Additions from synchronizing the
+	// guest clock with the host, for guests that run their own NTP client.
+	// It is stored in the TimeSyncDisabledExtraDataKey extra data item and
+	// takes effect when the VM next starts; nil leaves it unchanged.
+	TimeSyncDisabled *bool
+	// RecordingEnabled records the VM's screens to a file on the host; nil
+	// leaves it unchanged. At least one screen must be set up for
+	// recording.
+	RecordingEnabled *bool
+	// RecordingScreens are the IDs of the screens to record, starting at 0;
+	// other screens are not recorded. nil leaves them unchanged.
+	RecordingScreens []uint32
+	// RecordingFile is the path of the WebM file the recorded screens are
+	// written to, on the VirtualBox host; nil leaves it unchanged and an
+	// empty string lets VirtualBox pick a file in the VM's folder.
+	RecordingFile *string
+	// RecordingVideoCodec and RecordingFPS set how the recorded screens are
+	// encoded; empty values leave them unchanged.
+	RecordingVideoCodec vboxapi.RecordingVideoCodec
+	RecordingFPS        uint32
+	// RecordingScreenSettings reports the recording settings of each
+	// screen. It is read but never applied.
+	RecordingScreenSettings []vboxapi.RecordingScreenSettings
+	// HostIOCache enables the host I/O cache of the storage controller
+	// named HostIOCacheController, or of every controller when that is
+	// empty; nil leaves it unchanged. The cache speeds up disk access but
+	// loses writes the host has not flushed when it crashes.
+	HostIOCache           *bool
+	HostIOCacheController string
+	// ControllersHostIOCache reports whether each storage controller, by
+	// name, uses the host I/O cache. It is read but never applied.
+	ControllersHostIOCache map[string]bool
+
+	// HWVirt, NestedPaging and NestedHWVirt report whether the VM uses
+	// hardware virtualization and nested paging, and exposes hardware
+	// virtualization to the guest. They are read but never applied, and are
+	// left nil for ARM VMs.
+	HWVirt       *bool
+	NestedPaging *bool
+	NestedHWVirt *bool
+}
+
+// VRDEKeyboardLayoutProperty is the VRDE server property holding the
+// keyboard layout used by RDP sessions.
+const VRDEKeyboardLayoutProperty = "TCP/KeyboardLayout"
+
+// OwnerExtraDataKey is the machine extra data item holding the VM's owner,
+// for tooling that enforces quotas or cleans up VMs on shared hosts.
+const OwnerExtraDataKey = "vboxweb/owner"
+
+// TimeSyncDisabledExtraDataKey is the machine extra data item that, set to
+// "1", stops the VMM device from giving the host time to the Guest
+// Additions, which disables their time synchronization.
+const TimeSyncDisabledExtraDataKey = "VBoxInternal/Devices/VMMDev/0/Config/GetHostTimeDisabled"
+
+// Empty reports whether s changes nothing.
+func (s MachineSettings) Empty() bool {
+	return s.ExecutionEngine == "" && s.VRDEKeyboardLayout == nil &&
+		len(s.CPUIDLeaves) == 0 && len(s.RemoveCPUIDLeaves) == 0 &&
+		s.GraphicsController == "" && s.VRAMMB == 0 &&
+		s.MemoryMB == 0 && s.PageFusion == nil && s.CPUCount == 0 &&
+		s.Accelerate3D == nil && s.MonitorCount == 0 &&
+		s.APIC == nil && s.X2APIC == nil && s.BootMenuMode == "" &&
+		s.PAE == nil && s.LongMode == nil && s.Owner == nil &&
+		s.AutostartEnabled == nil && s.AutostartDelay == nil &&
+		s.HostIOCache == nil && s.ClipboardFileTransfers == nil && s.TimeSyncDisabled == nil &&
+		s.RecordingEnabled == nil && s.RecordingScreens == nil &&
+		s.RecordingFile == nil && s.RecordingVideoCodec == "" && s.RecordingFPS == 0
+}
+
+// changesRecordingOptions reports whether s changes how screens are
+// recorded, which VirtualBox refuses while a running VM is being recorded.
+func (s MachineSettings) changesRecordingOptions() bool {
+	return s.RecordingScreens != nil || s.RecordingFile != nil ||
+		s.RecordingVideoCodec != "" || s.RecordingFPS != 0
+}
+
+// requiresPowerOff reports whether s changes settings that VirtualBox only
+// accepts while the VM is not running.
+func (s MachineSettings) requiresPowerOff() bool {
+	return s.ExecutionEngine != "" || len(s.CPUIDLeaves) > 0 || len(s.RemoveCPUIDLeaves) > 0 ||
+		s.GraphicsController != "" || s.VRAMMB != 0 || s.MemoryMB != 0 || s.PageFusion != nil ||
+		s.Accelerate3D != nil || s.MonitorCount != 0 ||
+		s.APIC != nil || s.X2APIC != nil || s.BootMenuMode != "" ||
+		s.PAE != nil || s.LongMode != nil || s.HostIOCache != nil
+}
+
+// ApplyMachineSettings changes the settings of a VM. Settings other than
+// VRDEKeyboardLayout, Owner, the autostart settings, ClipboardFileTransfers,
+// TimeSyncDisabled, the recording settings and CPUCount require the VM not
+// to be running. The recording
+// options of a running VM can only be changed while it is not being recorded,
+// and its CPU count only with CPU hot-plug enabled.
+func (c *Client) ApplyMachineSettings(ctx context.Context, id string, s MachineSettings) error {
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, id)
+		if err != nil {
+			return err
+		}
+		return applyMachineSettings(ctx, api, session, machineRef, s)
+	})
+}
+
+// ReadMachineSettings returns the current settings of a VM. The settings
+// only x86 VMs have are left nil for ARM VMs.
+func (c *Client) ReadMachineSettings(ctx context.Context, id string) (*MachineSettings, error) {
+	var out MachineSettings
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, id)
+		if err != nil {
+			return err
+		}
+		arm, err := isARMMachine(ctx, api, machineRef)
+		if err != nil {
+			return err
+		}
+		out.ExecutionEngine, err = api.GetExecutionEngine(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get execution engine: %w", err)
+		}
+		layout, err := api.GetVRDEProperty(ctx, machineRef, VRDEKeyboardLayoutProperty)
+		if err != nil {
+			return fmt.Errorf("failed to get VRDE keyboard layout: %w", err)
+		}
+		out.VRDEKeyboardLayout = &layout
+		owner, err := api.GetExtraData(ctx, machineRef, OwnerExtraDataKey)
+		if err != nil {
+			return fmt.Errorf("failed to get owner: %w", err)
+		}
+		out.Owner = &owner
+		autostart, err := api.GetAutostartEnabled(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get autostart: %w", err)
+		}
+		delay, err := api.GetAutostartDelay(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get autostart delay: %w", err)
+		}
+		out.AutostartEnabled, out.AutostartDelay = &autostart, &delay
+		clipboardFileTransfers, err := api.GetClipboardFileTransfersEnabled(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get clipboard file transfers: %w", err)
+		}
+		out.ClipboardFileTransfers = &clipboardFileTransfers
+		timeSync, err := api.GetExtraData(ctx, machineRef, TimeSyncDisabledExtraDataKey)
+		if err != nil {
+			return fmt.Errorf("failed to get time synchronization: %w", err)
+		}
+		timeSyncDisabled := timeSync == "1"
+		out.TimeSyncDisabled = &timeSyncDisabled
+		recording, err := api.GetRecordingEnabled(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get recording: %w", err)
+		}
+		out.RecordingEnabled = &recording
+		out.RecordingScreenSettings, err = api.GetRecordingScreenSettings(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get recording screen settings: %w", err)
+		}
+		if !arm {
+			out.CPUIDLeaves, err = api.GetCPUIDLeaves(ctx, machineRef)
+			if err != nil {
+				return fmt.Errorf("failed to get CPUID leaves: %w", err)
+			}
+		}
+		out.GraphicsController, err = api.GetGraphicsController(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get graphics controller: %w", err)
+		}
+		out.VRAMMB, err = api.GetVRAMSize(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get VRAM size: %w", err)
+		}
+		out.MemoryMB, err = api.GetMemorySize(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get memory size: %w", err)
+		}
+		pageFusion, err := api.GetPageFusionEnabled(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get page fusion: %w", err)
+		}
+		out.PageFusion = &pageFusion
+		out.CPUCount, err = readCPUCount(ctx, api, machineRef)
+		if err != nil {
+			return err
+		}
+		accelerate3D, err := api.GetAccelerate3DEnabled(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get 3D acceleration: %w", err)
+		}
+		out.Accelerate3D = &accelerate3D
+		out.MonitorCount, err = api.GetMonitorCount(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get monitor count: %w", err)
+		}
+		mode, err := api.GetAPICMode(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get APIC mode: %w", err)
+		}
+		apic, x2apic := mode != vboxapi.APICModeDisabled, mode == vboxapi.APICModeX2APIC
+		out.APIC, out.X2APIC = &apic, &x2apic
+		if !arm {
+			pae, err := api.GetCPUProperty(ctx, machineRef, vboxapi.CPUPropertyPAE)
+			if err != nil {
+				return fmt.Errorf("failed to get PAE: %w", err)
+			}
+			longMode, err := api.GetCPUProperty(ctx, machineRef, vboxapi.CPUPropertyLongMode)
+			if err != nil {
+				return fmt.Errorf("failed to get long mode: %w", err)
+			}
+			out.PAE, out.LongMode = &pae, &longMode
+		}
+		out.BootMenuMode, err = api.GetBootMenuMode(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get boot menu mode: %w", err)
+		}
+		controllers, err := api.GetStorageControllers(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to list storage controllers: %w", err)
+		}
+		out.ControllersHostIOCache = make(map[string]bool, len(controllers))
+		for _, ctl := range controllers {
+			out.ControllersHostIOCache[ctl.Name] = ctl.UseHostIOCache
+		}
+		if !arm {
+			hwVirt, err := api.GetHWVirtExProperty(ctx, machineRef, vboxapi.HWVirtExPropertyEnabled)
+			if err != nil {
+				return fmt.Errorf("failed to get hardware virtualization: %w", err)
+			}
+			nestedPaging, err := api.GetHWVirtExProperty(ctx, machineRef, vboxapi.HWVirtExPropertyNestedPaging)
+			if err != nil {
+				return fmt.Errorf("failed to get nested paging: %w", err)
+			}
+			nestedHWVirt, err := api.GetCPUProperty(ctx, machineRef, vboxapi.CPUPropertyHWVirt)
+			if err != nil {
+				return fmt.Errorf("failed to get nested virtualization: %w", err)
+			}
+			out.HWVirt, out.NestedPaging, out.NestedHWVirt = &hwVirt, &nestedPaging, &nestedHWVirt
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func applyMachineSettings(ctx context.Context, api vboxapi.VBoxAPI, session, machineRef string, s MachineSettings) error {
+	if s.Empty() {
+		return nil
+	}
+
+	st, err := api.GetMachineState(ctx, machineRef)
+	if err != nil {
+		return err
+	}
+	online := isMachineOnline(st)
+	r, err := checkMachineSettings(ctx, api, session, machineRef, st, s)
+	if err != nil {
+		return err
+	}
+
+	sessObj, err := api.GetSessionObject(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to get session object: %w", err)
+	}
+	// A running VM only accepts a shared lock.
+	if err := api.LockMachine(ctx, machineRef, sessObj, online); err != nil {
+		return fmt.Errorf("failed to lock machine: %w", err)
+	}
+	defer unlockSession(ctx, api, sessObj)
+
+	mutableMachineRef, err := api.GetMutableMachine(ctx, sessObj)
+	if err != nil {
+		return fmt.Errorf("failed to get mutable machine: %w", err)
+	}
+
+	if s.ExecutionEngine != "" {
+		if err := api.SetExecutionEngine(ctx, mutableMachineRef, s.ExecutionEngine); err != nil {
+			return fmt.Errorf("failed to set execution engine to %s: %w", s.ExecutionEngine, err)
+		}
+	}
+	if s.VRDEKeyboardLayout != nil {
+		if err := api.SetVRDEProperty(ctx, mutableMachineRef, VRDEKeyboardLayoutProperty, *s.VRDEKeyboardLayout); err != nil {
+			return fmt.Errorf("failed to set VRDE keyboard layout: %w", err)
+		}
+	}
+	if s.Owner != nil {
+		if err := api.SetExtraData(ctx, mutableMachineRef, OwnerExtraDataKey, *s.Owner); err != nil {
+			return fmt.Errorf("failed to set owner: %w", err)
+		}
+	}
+	if s.AutostartDelay != nil {
+		if err := api.SetAutostartDelay(ctx, mutableMachineRef, *s.AutostartDelay); err != nil {
+			return fmt.Errorf("failed to set autostart delay: %w", err)
+		}
+	}
+	if s.AutostartEnabled != nil {
+		if err := api.SetAutostartEnabled(ctx, mutableMachineRef, *s.AutostartEnabled); err != nil {
+			return autostartError(err)
+		}
+	}
+	if s.ClipboardFileTransfers != nil {
+		if err := api.SetClipboardFileTransfersEnabled(ctx, mutableMachineRef, *s.ClipboardFileTransfers); err != nil {
+			return fmt.Errorf("failed to set clipboard file transfers: %w", err)
+		}
+	}
+	if s.TimeSyncDisabled != nil {
+		// Removing the item restores the default, enabled synchronization.
+		value := ""
+		if *s.TimeSyncDisabled {
+			value = "1"
+		}
+		if err := api.SetExtraData(ctx, mutableMachineRef, TimeSyncDisabledExtraDataKey, value); err != nil {
+			return fmt.Errorf("failed to set time synchronization: %w", err)
+		}
+	}
+	// Recording is stopped before its options change and started once
+	// they are set.
+	if s.RecordingEnabled != nil && !*s.RecordingEnabled {
+		if err := api.SetRecordingEnabled(ctx, mutableMachineRef, false); err != nil {
+			return fmt.Errorf("failed to set recording: %w", err)
+		}
+	}
+	if err := applyRecordingOptions(ctx, api, mutableMachineRef, s, r.recordingScreens); err != nil {
+		return err
+	}
+	if s.RecordingEnabled != nil && *s.RecordingEnabled {
+		if err := api.SetRecordingEnabled(ctx, mutableMachineRef, true); err != nil {
+			return fmt.Errorf("failed to set recording: %w", err)
+		}
+	}
+	if s.GraphicsController != "" {
+		if err := api.SetGraphicsController(ctx, mutableMachineRef, s.GraphicsController); err != nil {
+			return fmt.Errorf("failed to set graphics controller to %s: %w", s.GraphicsController, err)
+		}
+	}
+	if s.VRAMMB != 0 {
+		if err := api.SetVRAMSize(ctx, mutableMachineRef, s.VRAMMB); err != nil {
+			return fmt.Errorf("failed to set VRAM size to %d MB: %w", s.VRAMMB, err)
+		}
+	}
+	if s.Accelerate3D != nil {
+		if err := api.SetAccelerate3DEnabled(ctx, mutableMachineRef, *s.Accelerate3D); err != nil {
+			return fmt.Errorf("failed to set 3D acceleration: %w", err)
+		}
+	}
+	if s.MonitorCount != 0 {
+		if err := api.SetMonitorCount(ctx, mutableMachineRef, s.MonitorCount); err != nil {
+			return fmt.Errorf("failed to set monitor count to %d: %w", s.MonitorCount, err)
+		}
+	}
+	if s.MemoryMB != 0 {
+		if err := api.SetMemorySize(ctx, mutableMachineRef, s.MemoryMB); err != nil {
+			return fmt.Errorf("failed to set memory size to %d MB: %w", s.MemoryMB, err)
+		}
+	}
+	if s.PageFusion != nil {
+		if err := api.SetPageFusionEnabled(ctx, mutableMachineRef, *s.PageFusion); err != nil {
+			return fmt.Errorf("failed to set page fusion: %w", err)
+		}
+	}
+	if s.CPUCount != 0 {
+		if err := setCPUCount(ctx, api, mutableMachineRef, s.CPUCount, r.cpuHotPlug); err != nil {
+			return err
+		}
+	}
+	if r.apicMode != "" {
+		if err := api.SetAPICMode(ctx, mutableMachineRef, r.apicMode); err != nil {
+			return fmt.Errorf("failed to set APIC mode to %s: %w", r.apicMode, err)
+		}
+	}
+	// Long mode is turned off before PAE and on after it.
+	if r.longMode != nil && !*r.longMode {
+		if err := api.SetCPUProperty(ctx, mutableMachineRef, vboxapi.CPUPropertyLongMode, false); err != nil {
+			return fmt.Errorf("failed to disable long mode: %w", err)
+		}
+	}
+	if r.pae != nil {
+		if err := api.SetCPUProperty(ctx, mutableMachineRef, vboxapi.CPUPropertyPAE, *r.pae); err != nil {
+			return fmt.Errorf("failed to set PAE: %w", err)
+		}
+	}
+	if r.longMode != nil && *r.longMode {
+		if err := api.SetCPUProperty(ctx, mutableMachineRef, vboxapi.CPUPropertyLongMode, true); err != nil {
+			return fmt.Errorf("failed to enable long mode: %w", err)
+		}
+	}
+	if s.BootMenuMode != "" {
+		if err := api.SetBootMenuMode(ctx, mutableMachineRef, s.BootMenuMode); err != nil {
+			return fmt.Errorf("failed to set boot menu mode to %s: %w", s.BootMenuMode, err)
+		}
+	}
+	if s.HostIOCache != nil {
+		if err := setHostIOCache(ctx, api, mutableMachineRef, s.HostIOCacheController, *s.HostIOCache); err != nil {
+			return err
+		}
+	}
+	for _, leaf := range s.RemoveCPUIDLeaves {
+		if err := api.RemoveCPUIDLeaf(ctx, mutableMachineRef, leaf.Leaf, leaf.SubLeaf); err != nil {
+			return fmt.Errorf("failed to remove CPUID leaf %#x sub-leaf %#x: %w", leaf.Leaf, leaf.SubLeaf, err)
+		}
+	}
+	for _, leaf := range s.CPUIDLeaves {
+		if err := api.SetCPUIDLeaf(ctx, mutableMachineRef, leaf); err != nil {
+			return fmt.Errorf("failed to set CPUID leaf %#x sub-leaf %#x: %w", leaf.Leaf, leaf.SubLeaf, err)
+		}
+	}
+
+	if err := api.SaveSettings(ctx, mutableMachineRef); err != nil {
+		return fmt.Errorf("failed to save machine settings: %w", err)
+	}
+	return nil
+}
+
+// resolvedSettings holds the changes checkMachineSettings resolves for
+// applyMachineSettings.
+type resolvedSettings struct {
+	cpuHotPlug       bool
+	recordingScreens []uint32
+	apicMode         vboxapi.APICMode
+	pae, longMode    *bool
+}
+
+// checkMachineSettings checks s against the VirtualBox host and the machine,
+// which is in state st, and resolves the changes to make. It changes
+// nothing, so the settings of a new VM can be checked against its source, or
+// before it is registered, and a failed check leaves no VM behind.
+func checkMachineSettings(ctx context.Context, api vboxapi.VBoxAPI, session, machineRef, st string, s MachineSettings) (*resolvedSettings, error) {
+	online := isMachineOnline(st)
+	if online && s.requiresPowerOff() {
+		return nil, fmt.Errorf("machine is %s; power off the VM (state = \"stopped\") to change its settings", st)
+	}
+	// VirtualBox refuses these settings while a saved state would restore
+	// the old ones, and discarding it loses the guest's memory.
+	if (st == vboxapi.MachineStateSaved || st == vboxapi.MachineStateAbortedSaved) && s.requiresPowerOff() {
+		return nil, fmt.Errorf("machine is %s; discard its saved state (state = \"stopped\" with stop_method = %q) to change its settings", st, StopMethodPowerOff)
+	}
+
+	var r resolvedSettings
+	var err error
+	if len(s.CPUIDLeaves) > 0 || len(s.RemoveCPUIDLeaves) > 0 {
+		arm, err := isARMMachine(ctx, api, machineRef)
+		if err != nil {
+			return nil, err
+		}
+		if arm {
+			return nil, fmt.Errorf("CPUID overrides are only supported on x86 VMs; remove cpuid_overrides from this ARM VM")
+		}
+	}
+	if s.ExecutionEngine != "" {
+		if err := checkExecutionEngine(ctx, api, session, machineRef, s.ExecutionEngine); err != nil {
+			return nil, err
+		}
+	}
+	if s.VRAMMB != 0 {
+		if err := checkVRAMSize(ctx, api, session, s.VRAMMB); err != nil {
+			return nil, err
+		}
+	}
+	if s.MonitorCount != 0 {
+		if err := checkMonitorCount(ctx, api, session, s.MonitorCount); err != nil {
+			return nil, err
+		}
+	}
+	if s.MemoryMB != 0 {
+		if err := checkMemorySize(ctx, api, session, s.MemoryMB); err != nil {
+			return nil, err
+		}
+	}
+	if s.CPUCount != 0 {
+		if r.cpuHotPlug, err = checkCPUCount(ctx, api, session, machineRef, st, s.CPUCount); err != nil {
+			return nil, err
+		}
+	}
+	if (s.RecordingEnabled != nil && *s.RecordingEnabled) || s.changesRecordingOptions() {
+		r.recordingScreens, err = checkRecordingSettings(ctx, api, session, machineRef, online, s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if s.APIC != nil || s.X2APIC != nil {
+		current, err := api.GetAPICMode(ctx, machineRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get APIC mode: %w", err)
+		}
+		r.apicMode, err = resolveAPICMode(current, s.APIC, s.X2APIC)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r.pae, r.longMode, err = resolveCPUModes(s.PAE, s.LongMode)
+	if err != nil {
+		return nil, err
+	}
+	if r.pae != nil || r.longMode != nil {
+		arm, err := isARMMachine(ctx, api, machineRef)
+		if err != nil {
+			return nil, err
+		}
+		if arm {
+			return nil, fmt.Errorf("PAE and long mode are only supported on x86 VMs; remove pae_enabled and long_mode from this ARM VM")
+		}
+	}
+	return &r, nil
+}
+
+// isARMMachine reports whether a machine is an ARM VM, which lacks the x86
+// platform settings: VirtualBox fails to read or change them.
+func isARMMachine(ctx context.Context, api vboxapi.VBoxAPI, machineRef string) (bool, error) {
+	arch, err := api.GetPlatformArchitecture(ctx, machineRef)
+	if err != nil {
+		return false, fmt.Errorf("failed to get platform architecture: %w", err)
+	}
+	return arch == vboxapi.PlatformArchitectureARM, nil
+}
+
+// autostartError explains the failure to enable or disable autostart.
+// VirtualBox records autostart VMs in a database on the host and reports
+// E_FAIL when no database path is set, or VBOX_E_NOT_SUPPORTED when the
+// host has no autostart service at all. The codes are matched rather than
+// the message, which depends on the locale of vboxwebsrv.
+func autostartError(err error) error {
+	var vErr *vboxapi.Error
+	if errors.As(err, &vErr) {
+		switch vErr.ResultCode {
+		case vboxapi.ResultFail:
+			return fmt.Errorf("failed to set autostart: autostart is not configured on the VirtualBox host: "+
+				"set the autostart database path with \"VBoxManage setproperty autostartdbpath <dir>\" "+
+				"and set up the vboxautostart service (see the VirtualBox manual, \"Starting Virtual Machines During System Boot\"): %w", err)
+		case vboxapi.ResultNotSupported:
+			return fmt.Errorf("failed to set autostart: the VirtualBox host does not support autostart: %w", err)
+		}
+	}
+	return fmt.Errorf("failed to set autostart: %w", err)
+}
+
+// setHostIOCache enables or disables the host I/O cache of the storage
+// controller named controller, or of every controller when it is empty.
+func setHostIOCache(ctx context.Context, api vboxapi.VBoxAPI, mutableMachineRef, controller string, enabled bool) error {
+	names := []string{controller}
+	if controller == "" {
+		controllers, err := api.GetStorageControllers(ctx, mutableMachineRef)
+		if err != nil {
+			return fmt.Errorf("failed to list storage controllers: %w", err)
+		}
+		names = names[:0]
+		for _, ctl := range controllers {
+			names = append(names, ctl.Name)
+		}
+	}
+	for _, name := range names {
+		controllerRef, err := api.GetStorageControllerByName(ctx, mutableMachineRef, name)
+		if err != nil {
+			return fmt.Errorf("failed to get storage controller %s: %w", name, err)
+		}
+		if err := api.SetStorageControllerUseHostIOCache(ctx, controllerRef, enabled); err != nil {
+			return fmt.Errorf("failed to set host I/O cache of storage controller %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// checkExecutionEngine fails early when the host cannot provide engine.
+// VirtualBox accepts any engine in the settings and would otherwise only
+// fail when the VM starts.
+func checkExecutionEngine(ctx context.Context, api vboxapi.VBoxAPI, session, machineRef string, engine vboxapi.ExecutionEngine) error {
+	if engine == vboxapi.ExecutionEngineDefault {
+		return nil
+	}
+	supported, err := api.IsExecutionEngineSupported(ctx, session, machineRef, engine)
+	if err != nil {
+		return fmt.Errorf("failed to check execution engine support: %w", err)
+	}
+	if !supported {
+		return fmt.Errorf("execution engine %s is not supported by the VirtualBox host", engine)
+	}
+	return nil
+}
+
+// GuestLimits are the ranges of VM settings that the VirtualBox host
+// accepts. Settings outside of them would only fail when applied or when
+// the VM starts.
+type GuestLimits struct {
+	MinRAMMB, MaxRAMMB       uint32
+	MinCPUCount, MaxCPUCount uint32
+	MinVRAMMB, MaxVRAMMB     uint32
+	MaxMonitors              uint32
+}
+
+// ReadGuestLimits reads the ranges of VM settings that the host accepts.
+func (c *Client) ReadGuestLimits(ctx context.Context) (*GuestLimits, error) {
+	var l GuestLimits
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		var err error
+		if l.MinRAMMB, l.MaxRAMMB, err = api.GetGuestRAMLimits(ctx, session); err != nil {
+			return fmt.Errorf("failed to get memory limits: %w", err)
+		}
+		if l.MinCPUCount, l.MaxCPUCount, err = api.GetGuestCPUCountLimits(ctx, session); err != nil {
+			return fmt.Errorf("failed to get CPU count limits: %w", err)
+		}
+		if l.MinVRAMMB, l.MaxVRAMMB, err = api.GetGuestVRAMLimits(ctx, session); err != nil {
+			return fmt.Errorf("failed to get VRAM limits: %w", err)
+		}
+		if l.MaxMonitors, err = api.GetMaxGuestMonitors(ctx, session); err != nil {
+			return fmt.Errorf("failed to get maximum monitor count: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// checkVRAMSize fails early with the accepted range when VirtualBox would
+// reject vramMB.
+func checkVRAMSize(ctx context.Context, api vboxapi.VBoxAPI, session string, vramMB uint32) error {
+	minMB, maxMB, err := api.GetGuestVRAMLimits(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to get VRAM limits: %w", err)
+	}
+	if vramMB < minMB || vramMB > maxMB {
+		return fmt.Errorf("VRAM size %d MB is out of range: VirtualBox accepts %d to %d MB", vramMB, minMB, maxMB)
+	}
+	return nil
+}
+
+// checkMemorySize fails early with the accepted range when VirtualBox would
+// reject memoryMB.
+func checkMemorySize(ctx context.Context, api vboxapi.VBoxAPI, session string, memoryMB uint32) error {
+	minMB, maxMB, err := api.GetGuestRAMLimits(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to get memory limits: %w", err)
+	}
+	if memoryMB < minMB || memoryMB > maxMB {
+		return fmt.Errorf("memory size %d MB is out of range: VirtualBox accepts %d to %d MB", memoryMB, minMB, maxMB)
+	}
+	return nil
+}
+
+// checkCPUCount fails early when VirtualBox would reject count, or the VM
+// in state st cannot change its CPU count, and reports whether the VM uses
+// CPU hot-plug. Without it, the CPU count of a running VM cannot change;
+// with it, CPUs can be attached to a running VM up to its CPU slots.
+func checkCPUCount(ctx context.Context, api vboxapi.VBoxAPI, session, machineRef, st string, count uint32) (bool, error) {
+	minCount, maxCount, err := api.GetGuestCPUCountLimits(ctx, session)
+	if err != nil {
+		return false, fmt.Errorf("failed to get CPU count limits: %w", err)
+	}
+	if count < minCount || count > maxCount {
+		return false, fmt.Errorf("CPU count %d is out of range: VirtualBox accepts %d to %d CPUs", count, minCount, maxCount)
+	}
+	hotPlug, err := api.GetCPUHotPlugEnabled(ctx, machineRef)
+	if err != nil {
+		return false, fmt.Errorf("failed to get CPU hot-plug: %w", err)
+	}
+	if !isMachineOnline(st) {
+		return hotPlug, nil
+	}
+	if !hotPlug {
+		return false, fmt.Errorf("machine is %s and CPU hot-plug is disabled; power off the VM (state = \"stopped\") to change its CPU count", st)
+	}
+	slots, err := api.GetCPUCount(ctx, machineRef)
+	if err != nil {
+		return false, fmt.Errorf("failed to get CPU count: %w", err)
+	}
+	if count > slots {
+		return false, fmt.Errorf("machine is %s and has %d CPU slots; power off the VM (state = \"stopped\") to raise its CPU count to %d", st, slots, count)
+	}
+	return true, nil
+}
+
+// setCPUCount sets the number of CPUs of a locked machine. With CPU
+// hot-plug, CPUs 0 to count-1 are attached and the others detached, after
+// adding CPU slots if there are fewer than count.
+func setCPUCount(ctx context.Context, api vboxapi.VBoxAPI, mutableMachineRef string, count uint32, hotPlug bool) error {
+	if !hotPlug {
+		if err := api.SetCPUCount(ctx, mutableMachineRef, count); err != nil {
+			return fmt.Errorf("failed to set CPU count to %d: %w", count, err)
+		}
+		return nil
+	}
+
+	slots, err := api.GetCPUCount(ctx, mutableMachineRef)
+	if err != nil {
+		return fmt.Errorf("failed to get CPU count: %w", err)
+	}
+	if count > slots {
+		if err := api.SetCPUCount(ctx, mutableMachineRef, count); err != nil {
+			return fmt.Errorf("failed to set CPU count to %d: %w", count, err)
+		}
+		slots = count
+	}
+	for cpu := uint32(0); cpu < slots; cpu++ {
+		attached, err := api.GetCPUStatus(ctx, mutableMachineRef, cpu)
+		if err != nil {
+			return fmt.Errorf("failed to get status of CPU %d: %w", cpu, err)
+		}
+		switch want := cpu < count; {
+		case want && !attached:
+			if err := api.HotPlugCPU(ctx, mutableMachineRef, cpu); err != nil {
+				return fmt.Errorf("failed to attach CPU %d: %w", cpu, err)
+			}
+		case !want && attached:
+			if err := api.HotUnplugCPU(ctx, mutableMachineRef, cpu); err != nil {
+				return fmt.Errorf("failed to detach CPU %d: %w", cpu, err)
+			}
+		}
+	}
+	return nil
+}
+
+// readCPUCount returns the number of CPUs of a machine: with CPU hot-plug,
+// the number of attached CPUs.
+func readCPUCount(ctx context.Context, api vboxapi.VBoxAPI, machineRef string) (uint32, error) {
+	count, err := api.GetCPUCount(ctx, machineRef)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get CPU count: %w", err)
+	}
+	hotPlug, err := api.GetCPUHotPlugEnabled(ctx, machineRef)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get CPU hot-plug: %w", err)
+	}
+	if !hotPlug {
+		return count, nil
+	}
+	var attached uint32
+	for cpu := uint32(0); cpu < count; cpu++ {
+		ok, err := api.GetCPUStatus(ctx, machineRef, cpu)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get status of CPU %d: %w", cpu, err)
+		}
+		if ok {
+			attached++
+		}
+	}
+	return attached, nil
+}
+
+// checkMonitorCount fails early with the supported range when VirtualBox
+// would reject count.
+func checkMonitorCount(ctx context.Context, api vboxapi.VBoxAPI, session string, count uint32) error {
+	maxCount, err := api.GetMaxGuestMonitors(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to get maximum monitor count: %w", err)
+	}
+	if count > maxCount {
+		return fmt.Errorf("monitor count %d is out of range: VirtualBox supports 1 to %d monitors", count, maxCount)
+	}
+	return nil
+}
+
+// checkRecordingSettings fails early when VirtualBox would reject the
+// recording settings in s, and returns the IDs of the screens that will be
+// recorded.
+func checkRecordingSettings(ctx context.Context, api vboxapi.VBoxAPI, session, machineRef string, online bool, s MachineSettings) ([]uint32, error) {
+	if online && s.changesRecordingOptions() {
+		recording, err := api.GetRecordingEnabled(ctx, machineRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get recording: %w", err)
+		}
+		if recording && (s.RecordingEnabled == nil || *s.RecordingEnabled) {
+			return nil, fmt.Errorf("the recording options of a running VM can only be changed while recording is disabled")
+		}
+	}
+
+	current, err := api.GetRecordingScreenSettings(ctx, machineRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recording screen settings: %w", err)
+	}
+	screenCount := uint32(len(current))
+	if s.MonitorCount != 0 {
+		screenCount = s.MonitorCount
+	}
+
+	screens := s.RecordingScreens
+	if screens == nil {
+		for _, screen := range current {
+			if screen.Enabled {
+				screens = append(screens, screen.ID)
+			}
+		}
+	}
+	for _, id := range screens {
+		if id >= screenCount {
+			return nil, fmt.Errorf("recording screen %d is out of range: the VM has %d screens, numbered from 0", id, screenCount)
+		}
+	}
+	if s.RecordingEnabled != nil && *s.RecordingEnabled && len(screens) == 0 {
+		return nil, fmt.Errorf("recording is enabled but no screen of the VM is set up for recording")
+	}
+	if s.RecordingFile != nil && *s.RecordingFile != "" && len(screens) > 1 {
+		return nil, fmt.Errorf("recording file %q can only be set when a single screen is recorded, got %d screens", *s.RecordingFile, len(screens))
+	}
+
+	if s.RecordingVideoCodec != "" {
+		supported, err := api.GetSupportedRecordingVideoCodecs(ctx, session)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get supported recording video codecs: %w", err)
+		}
+		if !slices.Contains(supported, s.RecordingVideoCodec) {
+			return nil, fmt.Errorf("recording video codec %s is not supported by this host; supported codecs: %v", s.RecordingVideoCodec, supported)
+		}
+	}
+	return screens, nil
+}
+
+// applyRecordingOptions sets the recording options in s on the screens to
+// record, and stops recording the others when s.RecordingScreens is set.
+func applyRecordingOptions(ctx context.Context, api vboxapi.VBoxAPI, mutableMachineRef string, s MachineSettings, screens []uint32) error {
+	if !s.changesRecordingOptions() {
+		return nil
+	}
+	if s.RecordingScreens != nil {
+		current, err := api.GetRecordingScreenSettings(ctx, mutableMachineRef)
+		if err != nil {
+			return fmt.Errorf("failed to get recording screen settings: %w", err)
+		}
+		for _, screen := range current {
+			if enabled := slices.Contains(screens, screen.ID); enabled != screen.Enabled {
+				if err := api.SetRecordingScreenEnabled(ctx, mutableMachineRef, screen.ID, enabled); err != nil {
+					return fmt.Errorf("failed to set recording of screen %d: %w", screen.ID, err)
+				}
+			}
+		}
+	}
+	for _, id := range screens {
+		if s.RecordingFile != nil {
+			if err := api.SetRecordingScreenFilename(ctx, mutableMachineRef, id, *s.RecordingFile); err != nil {
+				return fmt.Errorf("failed to set recording file of screen %d: %w", id, err)
+			}
+		}
+		if s.RecordingVideoCodec != "" {
+			if err := api.SetRecordingScreenVideoCodec(ctx, mutableMachineRef, id, s.RecordingVideoCodec); err != nil {
+				return fmt.Errorf("failed to set recording video codec of screen %d: %w", id, err)
+			}
+		}
+		if s.RecordingFPS != 0 {
+			if err := api.SetRecordingScreenVideoFPS(ctx, mutableMachineRef, id, s.RecordingFPS); err != nil {
+				return fmt.Errorf("failed to set recording frame rate of screen %d: %w", id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveAPICMode returns the APIC mode that results from changing current.
+// Enabling x2APIC enables the APIC, and disabling the APIC disables x2APIC.
+func resolveAPICMode(current vboxapi.APICMode, apic, x2apic *bool) (vboxapi.APICMode, error) {
+	if apic != nil && x2apic != nil && *x2apic && !*apic {
+		return "", fmt.Errorf("x2APIC requires the APIC; enable the APIC or disable x2APIC")
+	}
+	enabled, x2 := current != vboxapi.APICModeDisabled, current == vboxapi.APICModeX2APIC
+	if apic != nil {
+		enabled = *apic
+		x2 = x2 && enabled
+	}
+	if x2apic != nil {
+		x2 = *x2apic
+		enabled = enabled || x2
+	}
+	switch {
+	case x2:
+		return vboxapi.APICModeX2APIC, nil
+	case enabled:
+		return vboxapi.APICModeAPIC, nil
+	default:
+		return vboxapi.APICModeDisabled, nil
+	}
+}
+
+// resolveCPUModes returns the PAE and long mode changes to apply. Enabling
+// long mode enables PAE, and disabling PAE disables long mode.
+func resolveCPUModes(pae, longMode *bool) (*bool, *bool, error) {
+	if pae != nil && longMode != nil && *longMode && !*pae {
+		return nil, nil, fmt.Errorf("long mode requires PAE; enable PAE or disable long mode")
+	}
+	if longMode != nil && *longMode && pae == nil {
+		enabled := true
+		pae = &enabled
+	}
+	if pae != nil && !*pae && longMode == nil {
+		disabled := false
+		longMode = &disabled
+	}
+	return pae, longMode, nil
+}
+
+// startFailureHint adds the configured execution engine and page fusion to
+// a VM start failure, since an engine or page fusion the host cannot
+// provide is a common cause.
+func startFailureHint(ctx context.Context, api vboxapi.VBoxAPI, machineRef string, err error) error {
+	var hints []string
+	if engine, eErr := api.GetExecutionEngine(ctx, machineRef); eErr == nil && engine != vboxapi.ExecutionEngineDefault {
+		hints = append(hints, fmt.Sprintf("execution engine %s", engine))
+	}
+	if pageFusion, pErr := api.GetPageFusionEnabled(ctx, machineRef); pErr == nil && pageFusion {
+		hints = append(hints, "page fusion")
+	}
+	if len(hints) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w (the VM uses %s; check that the host supports it)", err, strings.Join(hints, " and "))
+}
@@ -0,0 +1,1141 @@
+package vbox
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestApplyMachineSettings_ExecutionEngine(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{ExecutionEngine: vboxapi.ExecutionEngineNativeAPI})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if api.lockShared {
+		t.Error("expected a write lock")
+	}
+	if n := api.called("SaveSettings"); n != 1 {
+		t.Errorf("SaveSettings called %d times, want 1", n)
+	}
+
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.ExecutionEngine != vboxapi.ExecutionEngineNativeAPI {
+		t.Errorf("execution engine = %q, want %q", s.ExecutionEngine, vboxapi.ExecutionEngineNativeAPI)
+	}
+}
+
+func TestApplyMachineSettings_Empty(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	if err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("LockMachine"); n != 0 {
+		t.Errorf("LockMachine called %d times, want 0", n)
+	}
+}
+
+func TestApplyMachineSettings_RequiresPowerOff(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{ExecutionEngine: vboxapi.ExecutionEngineHwVirt})
+	if err == nil || !strings.Contains(err.Error(), "power off") {
+		t.Fatalf("expected power off error, got %v", err)
+	}
+	if n := api.called("SetExecutionEngine"); n != 0 {
+		t.Errorf("SetExecutionEngine called %d times, want 0", n)
+	}
+}
+
+func TestApplyMachineSettings_VRDEKeyboardLayoutWhileRunning(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	layout := "de-de"
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{VRDEKeyboardLayout: &layout})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !api.lockShared {
+		t.Error("expected a shared lock on a running VM")
+	}
+
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.VRDEKeyboardLayout == nil || *s.VRDEKeyboardLayout != "de-de" {
+		t.Errorf("VRDE keyboard layout = %v, want de-de", s.VRDEKeyboardLayout)
+	}
+
+	cleared := ""
+	if err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{VRDEKeyboardLayout: &cleared}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := api.machines["machine-vm"].VRDEProperties[VRDEKeyboardLayoutProperty]; ok {
+		t.Error("expected the VRDE keyboard layout property to be removed")
+	}
+}
+
+func TestApplyMachineSettings_Owner(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	owner := "team-a"
+	if err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{Owner: &owner}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := api.machines["machine-vm"].ExtraData[OwnerExtraDataKey]; got != "team-a" {
+		t.Errorf("extra data %s = %q, want %q", OwnerExtraDataKey, got, "team-a")
+	}
+
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Owner == nil || *s.Owner != "team-a" {
+		t.Errorf("owner = %v, want team-a", s.Owner)
+	}
+
+	cleared := ""
+	if err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{Owner: &cleared}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := api.machines["machine-vm"].ExtraData[OwnerExtraDataKey]; ok {
+		t.Error("expected the owner extra data item to be removed")
+	}
+}
+
+func TestApplyMachineSettings_Autostart(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	enabled, delay := true, uint32(30)
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{AutostartEnabled: &enabled, AutostartDelay: &delay})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m := api.machines["machine-vm"]; !m.AutostartEnabled || m.AutostartDelay != 30 {
+		t.Errorf("autostart = %t, delay %d; want true, 30", m.AutostartEnabled, m.AutostartDelay)
+	}
+
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.AutostartEnabled == nil || !*s.AutostartEnabled {
+		t.Errorf("autostart enabled = %v, want true", s.AutostartEnabled)
+	}
+	if s.AutostartDelay == nil || *s.AutostartDelay != 30 {
+		t.Errorf("autostart delay = %v, want 30", s.AutostartDelay)
+	}
+}
+
+func TestApplyMachineSettings_AutostartNotConfigured(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	api.autostartErr = &vboxapi.Error{
+		Operation:  "IMachine_setAutostartEnabled",
+		Text:       "The path to the autostart database is not set",
+		ResultCode: vboxapi.ResultFail,
+	}
+	c := newTestClient(api)
+
+	enabled := true
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{AutostartEnabled: &enabled})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "autostartdbpath") {
+		t.Errorf("error = %v, want it to explain how to configure autostart on the host", err)
+	}
+	var vErr *vboxapi.Error
+	if !errors.As(err, &vErr) {
+		t.Error("expected the VirtualBox error to be wrapped")
+	}
+
+	// Other failures to update the database are not mistaken for a
+	// missing one.
+	api.autostartErr = &vboxapi.Error{
+		Operation:  "IMachine_setAutostartEnabled",
+		Text:       "Adding machine 'vm' to the autostart database failed with VERR_ACCESS_DENIED",
+		ResultCode: vboxapi.ResultUnexpected,
+	}
+	err = c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{AutostartEnabled: &enabled})
+	if err == nil || strings.Contains(err.Error(), "autostartdbpath") {
+		t.Errorf("error = %v, want the VirtualBox error without the configuration hint", err)
+	}
+}
+
+func TestApplyMachineSettings_TimeSyncDisabled(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	disabled := true
+	if err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{TimeSyncDisabled: &disabled}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := api.machines["machine-vm"].ExtraData[TimeSyncDisabledExtraDataKey]; got != "1" {
+		t.Errorf("%s = %q, want \"1\"", TimeSyncDisabledExtraDataKey, got)
+	}
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.TimeSyncDisabled == nil || !*s.TimeSyncDisabled {
+		t.Errorf("time sync disabled = %v, want true", s.TimeSyncDisabled)
+	}
+
+	disabled = false
+	if err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{TimeSyncDisabled: &disabled}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := api.machines["machine-vm"].ExtraData[TimeSyncDisabledExtraDataKey]; ok {
+		t.Errorf("expected %s to be removed", TimeSyncDisabledExtraDataKey)
+	}
+	s, err = c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.TimeSyncDisabled == nil || *s.TimeSyncDisabled {
+		t.Errorf("time sync disabled = %v, want false", s.TimeSyncDisabled)
+	}
+}
+
+func TestApplyMachineSettings_ClipboardAndRecording(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	enabled := true
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{ClipboardFileTransfers: &enabled, RecordingEnabled: &enabled})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m := api.machines["machine-vm"]; !m.ClipboardFileTransfers || !m.RecordingEnabled {
+		t.Errorf("clipboard file transfers = %t, recording = %t; want true, true", m.ClipboardFileTransfers, m.RecordingEnabled)
+	}
+
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.ClipboardFileTransfers == nil || !*s.ClipboardFileTransfers {
+		t.Errorf("clipboard file transfers = %v, want true", s.ClipboardFileTransfers)
+	}
+	if s.RecordingEnabled == nil || !*s.RecordingEnabled {
+		t.Errorf("recording = %v, want true", s.RecordingEnabled)
+	}
+}
+
+func TestApplyMachineSettings_RecordingWithoutScreens(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", RecordingScreens: []vboxapi.RecordingScreenSettings{{ID: 0}}})
+	c := newTestClient(api)
+
+	enabled := true
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{RecordingEnabled: &enabled})
+	if err == nil || !strings.Contains(err.Error(), "no screen") {
+		t.Fatalf("error = %v, want it to report that no screen is recorded", err)
+	}
+	if n := api.called("SetRecordingEnabled"); n != 0 {
+		t.Errorf("SetRecordingEnabled called %d times, want 0", n)
+	}
+
+	// Disabling recording needs no screen.
+	disabled := false
+	if err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{RecordingEnabled: &disabled}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestApplyMachineSettings_RecordingOptions(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", MonitorCount: 2})
+	c := newTestClient(api)
+
+	enabled := true
+	file := "/srv/recordings/vm.webm"
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{
+		RecordingEnabled:    &enabled,
+		RecordingScreens:    []uint32{1},
+		RecordingFile:       &file,
+		RecordingVideoCodec: vboxapi.RecordingVideoCodecVP9,
+		RecordingFPS:        30,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []vboxapi.RecordingScreenSettings{
+		{ID: 0, Enabled: false, Filename: "/vms/vm/vm-screen0.webm", VideoCodec: vboxapi.RecordingVideoCodecVP8, VideoFPS: 25},
+		{ID: 1, Enabled: true, Filename: file, VideoCodec: vboxapi.RecordingVideoCodecVP9, VideoFPS: 30},
+	}
+	if !slices.Equal(s.RecordingScreenSettings, want) {
+		t.Errorf("recording screens = %+v, want %+v", s.RecordingScreenSettings, want)
+	}
+	if s.RecordingEnabled == nil || !*s.RecordingEnabled {
+		t.Errorf("recording = %v, want true", s.RecordingEnabled)
+	}
+}
+
+func TestApplyMachineSettings_RecordingOptionsValidation(t *testing.T) {
+	file := "/srv/recordings/vm.webm"
+	tests := []struct {
+		name    string
+		machine fakeMachine
+		s       MachineSettings
+		wantErr string
+	}{
+		{
+			name:    "screen out of range",
+			s:       MachineSettings{RecordingScreens: []uint32{1}},
+			wantErr: "out of range",
+		},
+		{
+			name:    "unsupported codec",
+			s:       MachineSettings{RecordingVideoCodec: vboxapi.RecordingVideoCodecAV1},
+			wantErr: "not supported",
+		},
+		{
+			name:    "file with several screens",
+			machine: fakeMachine{MonitorCount: 2},
+			s:       MachineSettings{RecordingScreens: []uint32{0, 1}, RecordingFile: &file},
+			wantErr: "single screen",
+		},
+		{
+			name:    "recording running VM",
+			machine: fakeMachine{State: vboxapi.MachineStateRunning, RecordingEnabled: true},
+			s:       MachineSettings{RecordingFPS: 30},
+			wantErr: "while recording is disabled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			m := tt.machine
+			m.ID, m.Name = "uuid-vm", "vm"
+			api.addMachine("machine-vm", &m)
+			c := newTestClient(api)
+
+			err := c.ApplyMachineSettings(context.Background(), "uuid-vm", tt.s)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if n := api.called("LockMachine"); n != 0 {
+				t.Errorf("LockMachine called %d times, want 0", n)
+			}
+		})
+	}
+}
+
+func TestApplyMachineSettings_CPUIDLeaves(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{
+		ID:          "uuid-vm",
+		Name:        "vm",
+		CPUIDLeaves: []vboxapi.CPUIDLeaf{{Leaf: 0x80000001, EDX: 0x20100800}},
+	})
+	c := newTestClient(api)
+
+	leaf := vboxapi.CPUIDLeaf{Leaf: 0x1, EAX: 0x000306a9, EBX: 0x00020800, ECX: 0x80000201, EDX: 0x178bfbff}
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{
+		CPUIDLeaves:       []vboxapi.CPUIDLeaf{leaf},
+		RemoveCPUIDLeaves: []vboxapi.CPUIDLeaf{{Leaf: 0x80000001}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if api.lockShared {
+		t.Error("expected a write lock")
+	}
+
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(s.CPUIDLeaves) != 1 || s.CPUIDLeaves[0] != leaf {
+		t.Errorf("CPUID leaves = %+v, want [%+v]", s.CPUIDLeaves, leaf)
+	}
+}
+
+func TestApplyMachineSettings_CPUIDLeavesRequirePowerOff(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{
+		CPUIDLeaves: []vboxapi.CPUIDLeaf{{Leaf: 0x1}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "power off") {
+		t.Fatalf("expected power off error, got %v", err)
+	}
+}
+
+func TestApplyMachineSettings_CPUIDLeavesOnARM(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", Architecture: vboxapi.PlatformArchitectureARM})
+	c := newTestClient(api)
+
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{
+		CPUIDLeaves: []vboxapi.CPUIDLeaf{{Leaf: 0x1}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "only supported on x86 VMs") {
+		t.Fatalf("expected an x86-only error, got %v", err)
+	}
+	if n := api.called("LockMachine"); n != 0 {
+		t.Errorf("LockMachine called %d times, want the overrides to be rejected before locking", n)
+	}
+}
+
+func TestReadMachineSettings_ARM(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", Architecture: vboxapi.PlatformArchitectureARM, MemoryMB: 2048})
+	c := newTestClient(api)
+
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.CPUIDLeaves != nil {
+		t.Errorf("CPUID leaves = %+v, want nil for an ARM VM", s.CPUIDLeaves)
+	}
+	if s.HWVirt != nil || s.NestedPaging != nil || s.NestedHWVirt != nil {
+		t.Errorf("HWVirt = %v, NestedPaging = %v, NestedHWVirt = %v, want nil for an ARM VM", s.HWVirt, s.NestedPaging, s.NestedHWVirt)
+	}
+	if s.PAE != nil || s.LongMode != nil {
+		t.Errorf("PAE = %v, LongMode = %v, want nil for an ARM VM", s.PAE, s.LongMode)
+	}
+	if s.MemoryMB != 2048 {
+		t.Errorf("memory = %d MB, want 2048", s.MemoryMB)
+	}
+	for _, op := range []string{"GetCPUIDLeaves", "GetHWVirtExProperty", "GetCPUProperty"} {
+		if n := api.called(op); n != 0 {
+			t.Errorf("%s called %d times, want 0", op, n)
+		}
+	}
+}
+
+func TestApplyMachineSettings_CPUModesOnARM(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", Architecture: vboxapi.PlatformArchitectureARM})
+	c := newTestClient(api)
+
+	enabled := true
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{LongMode: &enabled, MemoryMB: 2048})
+	if err == nil || !strings.Contains(err.Error(), "only supported on x86 VMs") {
+		t.Fatalf("expected an x86-only error, got %v", err)
+	}
+	if n := api.called("LockMachine"); n != 0 {
+		t.Errorf("LockMachine called %d times, want 0", n)
+	}
+}
+
+func TestApplyMachineSettings_UnsupportedExecutionEngine(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	api.unsupportedEngines = []vboxapi.ExecutionEngine{vboxapi.ExecutionEngineNativeAPI}
+	c := newTestClient(api)
+
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{ExecutionEngine: vboxapi.ExecutionEngineNativeAPI})
+	if err == nil || !strings.Contains(err.Error(), "not supported by the VirtualBox host") {
+		t.Fatalf("expected unsupported engine error, got %v", err)
+	}
+	if n := api.called("SetExecutionEngine"); n != 0 {
+		t.Errorf("SetExecutionEngine called %d times, want 0", n)
+	}
+}
+
+func TestApplyMachineSettings_Graphics(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{
+		GraphicsController: vboxapi.GraphicsControllerVMSVGA,
+		VRAMMB:             128,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if api.lockShared {
+		t.Error("expected a write lock")
+	}
+
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.GraphicsController != vboxapi.GraphicsControllerVMSVGA {
+		t.Errorf("graphics controller = %q, want %q", s.GraphicsController, vboxapi.GraphicsControllerVMSVGA)
+	}
+	if s.VRAMMB != 128 {
+		t.Errorf("VRAM = %d MB, want 128", s.VRAMMB)
+	}
+}
+
+func TestApplyMachineSettings_GraphicsRequirePowerOff(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{GraphicsController: vboxapi.GraphicsControllerVMSVGA})
+	if err == nil || !strings.Contains(err.Error(), "power off") {
+		t.Fatalf("expected power off error, got %v", err)
+	}
+	if n := api.called("SetGraphicsController"); n != 0 {
+		t.Errorf("SetGraphicsController called %d times, want 0", n)
+	}
+}
+
+func TestApplyMachineSettings_VRAMOutOfRange(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{VRAMMB: 512})
+	if err == nil || !strings.Contains(err.Error(), "VirtualBox accepts 1 to 256 MB") {
+		t.Fatalf("expected VRAM range error, got %v", err)
+	}
+	if n := api.called("SetVRAMSize"); n != 0 {
+		t.Errorf("SetVRAMSize called %d times, want 0", n)
+	}
+}
+
+func TestApplyMachineSettings_Display(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", Accelerate3D: true})
+	c := newTestClient(api)
+
+	disabled := false
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{
+		Accelerate3D: &disabled,
+		MonitorCount: 2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Accelerate3D == nil || *s.Accelerate3D {
+		t.Errorf("accelerate 3D = %v, want false", s.Accelerate3D)
+	}
+	if s.MonitorCount != 2 {
+		t.Errorf("monitor count = %d, want 2", s.MonitorCount)
+	}
+}
+
+func TestApplyMachineSettings_DisplayRequiresPowerOff(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	enabled := true
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{Accelerate3D: &enabled})
+	if err == nil || !strings.Contains(err.Error(), "power off") {
+		t.Fatalf("expected power off error, got %v", err)
+	}
+	if n := api.called("SetAccelerate3DEnabled"); n != 0 {
+		t.Errorf("SetAccelerate3DEnabled called %d times, want 0", n)
+	}
+}
+
+func TestApplyMachineSettings_PageFusion(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	enabled := true
+	if err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{PageFusion: &enabled}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !api.machines["machine-vm"].PageFusion {
+		t.Error("expected page fusion to be enabled")
+	}
+	if api.lockShared {
+		t.Error("expected a write lock")
+	}
+
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.PageFusion == nil || !*s.PageFusion {
+		t.Errorf("page fusion = %v, want true", s.PageFusion)
+	}
+}
+
+func TestApplyMachineSettings_PageFusionRequiresPowerOff(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	enabled := true
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{PageFusion: &enabled})
+	if err == nil || !strings.Contains(err.Error(), "power off") {
+		t.Fatalf("expected power off error, got %v", err)
+	}
+	if n := api.called("SetPageFusionEnabled"); n != 0 {
+		t.Errorf("SetPageFusionEnabled called %d times, want 0", n)
+	}
+}
+
+func TestApplyMachineSettings_MonitorCountOutOfRange(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{MonitorCount: 65})
+	if err == nil || !strings.Contains(err.Error(), "VirtualBox supports 1 to 64 monitors") {
+		t.Fatalf("expected monitor count range error, got %v", err)
+	}
+	if n := api.called("SetMonitorCount"); n != 0 {
+		t.Errorf("SetMonitorCount called %d times, want 0", n)
+	}
+}
+
+func TestApplyMachineSettings_MemoryAndCPUs(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", MemoryMB: 2048, CPUCount: 1})
+	c := newTestClient(api)
+
+	if err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{MemoryMB: 8192, CPUCount: 4}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.MemoryMB != 8192 || s.CPUCount != 4 {
+		t.Errorf("memory = %d MB, CPUs = %d, want 8192 MB and 4", s.MemoryMB, s.CPUCount)
+	}
+}
+
+func TestReadMachineSettings_MemoryAndCPUs(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-plain", &fakeMachine{ID: "uuid-plain", Name: "plain", MemoryMB: 1024, CPUCount: 2})
+	api.addMachine("machine-hotplug", &fakeMachine{ID: "uuid-hotplug", Name: "hotplug", State: vboxapi.MachineStateRunning,
+		MemoryMB: 4096, CPUCount: 4, CPUHotPlug: true, DetachedCPUs: map[uint32]bool{3: true}})
+	c := newTestClient(api)
+
+	tests := []struct {
+		id          string
+		memoryMB    uint32
+		cpuCount    uint32
+		description string
+	}{
+		{"uuid-plain", 1024, 2, "configured CPUs"},
+		{"uuid-hotplug", 4096, 3, "attached CPUs"},
+	}
+	for _, tt := range tests {
+		s, err := c.ReadMachineSettings(context.Background(), tt.id)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.id, err)
+		}
+		if s.MemoryMB != tt.memoryMB || s.CPUCount != tt.cpuCount {
+			t.Errorf("%s: memory = %d MB, CPUs = %d, want %d MB and the %d %s", tt.id, s.MemoryMB, s.CPUCount, tt.memoryMB, tt.cpuCount, tt.description)
+		}
+	}
+	if n := api.called("SetMemorySize") + api.called("SetCPUCount"); n != 0 {
+		t.Errorf("reading the settings changed the VMs %d times", n)
+	}
+}
+
+func TestApplyMachineSettings_WhileSaved(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateSaved, MemoryMB: 2048})
+	c := newTestClient(api)
+
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{MemoryMB: 4096})
+	if err == nil || !strings.Contains(err.Error(), "discard its saved state") {
+		t.Errorf("error = %v, want a saved VM to be rejected", err)
+	}
+	if n := api.called("LockMachine"); n != 0 {
+		t.Errorf("LockMachine called %d times, want the settings to be rejected before locking", n)
+	}
+
+	// Settings a saved VM accepts still change.
+	owner := "team-a"
+	if err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{Owner: &owner}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := api.machines["machine-vm"].ExtraData[OwnerExtraDataKey]; got != owner {
+		t.Errorf("owner = %q, want %q", got, owner)
+	}
+}
+
+func TestApplyMachineSettings_MemoryAndCPUsWhileRunning(t *testing.T) {
+	tests := []struct {
+		name       string
+		settings   MachineSettings
+		hotPlug    bool
+		wantErr    string
+		wantCPUs   uint32
+		wantCalled string
+	}{
+		{name: "memory", settings: MachineSettings{MemoryMB: 4096}, hotPlug: true, wantErr: "power off"},
+		{name: "CPUs without hot-plug", settings: MachineSettings{CPUCount: 2}, wantErr: "CPU hot-plug is disabled"},
+		{name: "CPUs beyond the slots", settings: MachineSettings{CPUCount: 8}, hotPlug: true, wantErr: "has 4 CPU slots"},
+		{name: "detach CPUs", settings: MachineSettings{CPUCount: 2}, hotPlug: true, wantCPUs: 2, wantCalled: "HotUnplugCPU"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning, MemoryMB: 2048, CPUCount: 4, CPUHotPlug: tt.hotPlug})
+			c := newTestClient(api)
+
+			err := c.ApplyMachineSettings(context.Background(), "uuid-vm", tt.settings)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				for _, method := range []string{"SetMemorySize", "SetCPUCount", "HotPlugCPU", "HotUnplugCPU"} {
+					if n := api.called(method); n != 0 {
+						t.Errorf("%s called %d times, want 0", method, n)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if n := api.called(tt.wantCalled); n == 0 {
+				t.Errorf("%s not called", tt.wantCalled)
+			}
+			s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if s.CPUCount != tt.wantCPUs {
+				t.Errorf("CPUs = %d, want %d", s.CPUCount, tt.wantCPUs)
+			}
+		})
+	}
+}
+
+func TestApplyMachineSettings_CPUHotPlugStopped(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", CPUCount: 4, CPUHotPlug: true, DetachedCPUs: map[uint32]bool{2: true, 3: true}})
+	c := newTestClient(api)
+
+	if err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{CPUCount: 6}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := api.machines["machine-vm"]
+	if m.CPUCount != 6 || len(m.DetachedCPUs) != 0 {
+		t.Errorf("CPU slots = %d, detached = %v, want 6 slots and none detached", m.CPUCount, m.DetachedCPUs)
+	}
+}
+
+func TestApplyMachineSettings_MemoryOutOfRange(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{MemoryMB: 2})
+	if err == nil || !strings.Contains(err.Error(), "VirtualBox accepts 4 to 2097152 MB") {
+		t.Fatalf("expected memory range error, got %v", err)
+	}
+	err = c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{CPUCount: 65})
+	if err == nil || !strings.Contains(err.Error(), "VirtualBox accepts 1 to 64 CPUs") {
+		t.Fatalf("expected CPU count range error, got %v", err)
+	}
+	if n := api.called("SetMemorySize") + api.called("SetCPUCount"); n != 0 {
+		t.Errorf("setters called %d times, want 0", n)
+	}
+}
+
+func TestReadGuestLimits(t *testing.T) {
+	c := newTestClient(newFakeAPI())
+
+	got, err := c.ReadGuestLimits(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := GuestLimits{
+		MinRAMMB: 4, MaxRAMMB: 2097152,
+		MinCPUCount: 1, MaxCPUCount: 64,
+		MinVRAMMB: 1, MaxVRAMMB: 256,
+		MaxMonitors: 64,
+	}
+	if *got != want {
+		t.Errorf("ReadGuestLimits() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestApplyMachineSettings_APIC(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		name    string
+		current vboxapi.APICMode
+		apic    *bool
+		x2apic  *bool
+		want    vboxapi.APICMode
+		wantErr string
+	}{
+		{name: "enable x2APIC", current: vboxapi.APICModeAPIC, x2apic: &on, want: vboxapi.APICModeX2APIC},
+		{name: "x2APIC enables the APIC", current: vboxapi.APICModeDisabled, x2apic: &on, want: vboxapi.APICModeX2APIC},
+		{name: "disable x2APIC", current: vboxapi.APICModeX2APIC, x2apic: &off, want: vboxapi.APICModeAPIC},
+		{name: "disabling the APIC disables x2APIC", current: vboxapi.APICModeX2APIC, apic: &off, want: vboxapi.APICModeDisabled},
+		{name: "enabling the APIC keeps x2APIC off", current: vboxapi.APICModeDisabled, apic: &on, want: vboxapi.APICModeAPIC},
+		{name: "x2APIC without the APIC", current: vboxapi.APICModeAPIC, apic: &off, x2apic: &on, wantErr: "x2APIC requires the APIC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", APICMode: tt.current})
+			c := newTestClient(api)
+
+			err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{APIC: tt.apic, X2APIC: tt.x2apic})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				if n := api.called("SetAPICMode"); n != 0 {
+					t.Errorf("SetAPICMode called %d times, want 0", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := api.machines["machine-vm"].APICMode; got != tt.want {
+				t.Errorf("APIC mode = %q, want %q", got, tt.want)
+			}
+
+			s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			wantAPIC, wantX2APIC := tt.want != vboxapi.APICModeDisabled, tt.want == vboxapi.APICModeX2APIC
+			if s.APIC == nil || *s.APIC != wantAPIC || s.X2APIC == nil || *s.X2APIC != wantX2APIC {
+				t.Errorf("APIC = %v, x2APIC = %v, want %v, %v", s.APIC, s.X2APIC, wantAPIC, wantX2APIC)
+			}
+		})
+	}
+}
+
+func TestApplyMachineSettings_APICRequiresPowerOff(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	enabled := true
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{X2APIC: &enabled})
+	if err == nil || !strings.Contains(err.Error(), "power off") {
+		t.Fatalf("expected power off error, got %v", err)
+	}
+	if n := api.called("SetAPICMode"); n != 0 {
+		t.Errorf("SetAPICMode called %d times, want 0", n)
+	}
+}
+
+func TestApplyMachineSettings_CPUModes(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		name         string
+		pae          bool
+		longMode     bool
+		setPAE       *bool
+		setLongMode  *bool
+		wantPAE      bool
+		wantLongMode bool
+		wantErr      string
+	}{
+		{name: "enable PAE", setPAE: &on, wantPAE: true},
+		{name: "long mode enables PAE", setLongMode: &on, wantPAE: true, wantLongMode: true},
+		{name: "disabling PAE disables long mode", pae: true, longMode: true, setPAE: &off},
+		{name: "disable long mode", pae: true, longMode: true, setLongMode: &off, wantPAE: true},
+		{name: "long mode without PAE", setPAE: &off, setLongMode: &on, wantErr: "long mode requires PAE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMachine("machine-vm", &fakeMachine{
+				ID:            "uuid-vm",
+				Name:          "vm",
+				CPUProperties: map[vboxapi.CPUProperty]bool{vboxapi.CPUPropertyPAE: tt.pae, vboxapi.CPUPropertyLongMode: tt.longMode},
+			})
+			c := newTestClient(api)
+
+			err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{PAE: tt.setPAE, LongMode: tt.setLongMode})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				if n := api.called("SetCPUProperty"); n != 0 {
+					t.Errorf("SetCPUProperty called %d times, want 0", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if s.PAE == nil || *s.PAE != tt.wantPAE || s.LongMode == nil || *s.LongMode != tt.wantLongMode {
+				t.Errorf("PAE = %v, long mode = %v, want %v, %v", s.PAE, s.LongMode, tt.wantPAE, tt.wantLongMode)
+			}
+		})
+	}
+}
+
+func TestApplyMachineSettings_CPUModesRequirePowerOff(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	enabled := true
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{PAE: &enabled})
+	if err == nil || !strings.Contains(err.Error(), "power off") {
+		t.Fatalf("expected power off error, got %v", err)
+	}
+	if n := api.called("SetCPUProperty"); n != 0 {
+		t.Errorf("SetCPUProperty called %d times, want 0", n)
+	}
+}
+
+func TestApplyMachineSettings_BootMenuMode(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{BootMenuMode: vboxapi.BootMenuModeDisabled})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("SetBootMenuMode"); n != 1 {
+		t.Errorf("SetBootMenuMode called %d times, want 1", n)
+	}
+
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.BootMenuMode != vboxapi.BootMenuModeDisabled {
+		t.Errorf("boot menu mode = %q, want %q", s.BootMenuMode, vboxapi.BootMenuModeDisabled)
+	}
+}
+
+func TestApplyMachineSettings_BootMenuModeRequiresPowerOff(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{BootMenuMode: vboxapi.BootMenuModeDisabled})
+	if err == nil || !strings.Contains(err.Error(), "power off") {
+		t.Fatalf("expected power off error, got %v", err)
+	}
+	if n := api.called("SetBootMenuMode"); n != 0 {
+		t.Errorf("SetBootMenuMode called %d times, want 0", n)
+	}
+}
+
+func TestApplyMachineSettings_HostIOCache(t *testing.T) {
+	tests := []struct {
+		name       string
+		controller string
+		want       map[string]bool
+		wantErr    string
+	}{
+		{name: "all controllers", want: map[string]bool{"IDE": true, "SATA": true}},
+		{name: "named controller", controller: "SATA", want: map[string]bool{"IDE": false, "SATA": true}},
+		{name: "unknown controller", controller: "NVMe", wantErr: "storage controller NVMe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+			api.controllers["IDE"] = vboxapi.StorageControllerPIIX4
+			api.controllers["SATA"] = vboxapi.StorageControllerIntelAhci
+			c := newTestClient(api)
+
+			enabled := true
+			err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{HostIOCache: &enabled, HostIOCacheController: tt.controller})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !maps.Equal(s.ControllersHostIOCache, tt.want) {
+				t.Errorf("host I/O cache = %v, want %v", s.ControllersHostIOCache, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyMachineSettings_HostIOCacheRequiresPowerOff(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	api.controllers["SATA"] = vboxapi.StorageControllerIntelAhci
+	c := newTestClient(api)
+
+	enabled := false
+	err := c.ApplyMachineSettings(context.Background(), "uuid-vm", MachineSettings{HostIOCache: &enabled})
+	if err == nil || !strings.Contains(err.Error(), "power off") {
+		t.Fatalf("expected power off error, got %v", err)
+	}
+	if n := api.called("SetStorageControllerUseHostIOCache"); n != 0 {
+		t.Errorf("SetStorageControllerUseHostIOCache called %d times, want 0", n)
+	}
+}
+
+func TestReadMachineSettings_Virtualization(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{
+		ID:                 "uuid-vm",
+		Name:               "vm",
+		HWVirtExProperties: map[vboxapi.HWVirtExProperty]bool{vboxapi.HWVirtExPropertyEnabled: true},
+		CPUProperties:      map[vboxapi.CPUProperty]bool{vboxapi.CPUPropertyHWVirt: true},
+	})
+	c := newTestClient(api)
+
+	s, err := c.ReadMachineSettings(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.HWVirt == nil || s.NestedPaging == nil || s.NestedHWVirt == nil {
+		t.Fatalf("HWVirt = %v, NestedPaging = %v, NestedHWVirt = %v, want them read for an x86 VM", s.HWVirt, s.NestedPaging, s.NestedHWVirt)
+	}
+	if !*s.HWVirt || *s.NestedPaging || !*s.NestedHWVirt {
+		t.Errorf("HWVirt = %v, NestedPaging = %v, NestedHWVirt = %v, want true, false, true", *s.HWVirt, *s.NestedPaging, *s.NestedHWVirt)
+	}
+}
+
+func TestCloneAndConverge_AppliesSettings(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-template", &fakeMachine{ID: "uuid-template", Name: "template", OSTypeID: "Ubuntu_64"})
+	c := newTestClient(api)
+
+	_, err := c.CloneAndConverge(context.Background(), CloneRequest{
+		Name:     "vm",
+		Source:   "template",
+		Settings: MachineSettings{ExecutionEngine: vboxapi.ExecutionEngineHwVirt},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := api.machines["machine-vm"].ExecutionEngine; got != vboxapi.ExecutionEngineHwVirt {
+		t.Errorf("execution engine = %q, want %q", got, vboxapi.ExecutionEngineHwVirt)
+	}
+}
+
+func TestCloneAndConverge_ChecksSettingsFirst(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-template", &fakeMachine{ID: "uuid-template", Name: "template", OSTypeID: "Ubuntu_64"})
+	c := newTestClient(api)
+
+	result, err := c.CloneAndConverge(context.Background(), CloneRequest{
+		Name:     "vm",
+		Source:   "template",
+		Settings: MachineSettings{MemoryMB: 2},
+	})
+	if err == nil || !strings.Contains(err.Error(), "VirtualBox accepts 4 to 2097152 MB") {
+		t.Fatalf("expected memory range error, got %v", err)
+	}
+	if result != nil {
+		t.Errorf("result = %+v, want nil when no VM was created", result)
+	}
+	if n := api.called("CreateMachine"); n != 0 {
+		t.Errorf("CreateMachine called %d times, want 0", n)
+	}
+}
+
+func TestCloneAndConverge_FailureAfterRegistration(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-template", &fakeMachine{ID: "uuid-template", Name: "template", OSTypeID: "Ubuntu_64"})
+	api.autostartErr = &vboxapi.Error{Operation: "IMachine_setAutostartEnabled", ResultCode: vboxapi.ResultFail}
+	c := newTestClient(api)
+
+	enabled := true
+	result, err := c.CloneAndConverge(context.Background(), CloneRequest{
+		Name:     "vm",
+		Source:   "template",
+		Settings: MachineSettings{AutostartEnabled: &enabled},
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if result == nil || result.ID != "uuid-vm" {
+		t.Errorf("result = %+v, want the ID of the registered VM", result)
+	}
+}
+
+func TestCreateAndConverge_ChecksSettingsFirst(t *testing.T) {
+	api := newFakeAPI()
+	c := newTestClient(api)
+
+	_, err := c.CreateAndConverge(context.Background(), CreateRequest{
+		Name:     "vm",
+		OSTypeID: "Ubuntu_64",
+		Settings: MachineSettings{CPUCount: 65},
+	})
+	if err == nil || !strings.Contains(err.Error(), "VirtualBox accepts 1 to 64 CPUs") {
+		t.Fatalf("expected CPU count range error, got %v", err)
+	}
+	if n := api.called("RegisterMachine"); n != 0 {
+		t.Errorf("RegisterMachine called %d times, want 0", n)
+	}
+}
+
+func TestStartFailureHint(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-default", &fakeMachine{ID: "uuid-default", Name: "default"})
+	api.addMachine("machine-nem", &fakeMachine{ID: "uuid-nem", Name: "nem", ExecutionEngine: vboxapi.ExecutionEngineNativeAPI})
+	startErr := errors.New("VT-x is not available")
+
+	if err := startFailureHint(context.Background(), api, "machine-default", startErr); err != startErr {
+		t.Errorf("expected the error unchanged for the default engine, got %v", err)
+	}
+
+	err := startFailureHint(context.Background(), api, "machine-nem", startErr)
+	if !errors.Is(err, startErr) || !strings.Contains(err.Error(), "NativeApi") {
+		t.Errorf("expected wrapped error mentioning NativeApi, got %v", err)
+	}
+
+	api.addMachine("machine-fusion", &fakeMachine{ID: "uuid-fusion", Name: "fusion", PageFusion: true})
+	err = startFailureHint(context.Background(), api, "machine-fusion", startErr)
+	if !errors.Is(err, startErr) || !strings.Contains(err.Error(), "page fusion") {
+		t.Errorf("expected wrapped error mentioning page fusion, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package vbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// SharedFolder is a host directory shared with the guest of a VM.
+type SharedFolder struct {
+	MachineID string
+	Name      string
+	// HostPath is the directory on the VirtualBox host.
+	HostPath string
+	Writable bool
+	// AutoMount makes the Guest Additions mount the folder at boot, at
+	// AutoMountPoint or, when it is empty, at a path the guest chooses.
+	AutoMount      bool
+	AutoMountPoint string
+}
+
+// CreateSharedFolder adds a shared folder to a VM. A running VM is locked
+// shared; the guest sees the folder once the Guest Additions pick it up. A
+// folder of the same name that already exists with the same settings is
+// left as is; one with other settings is an error.
+func (c *Client) CreateSharedFolder(ctx context.Context, folder SharedFolder) error {
+	if folder.Name == "" {
+		return fmt.Errorf("shared folder name must not be empty")
+	}
+	if folder.HostPath == "" {
+		return fmt.Errorf("shared folder %q: host path must not be empty", folder.Name)
+	}
+
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		// The machine may have been created in the same apply and not be
+		// registered yet.
+		machineRef, err := findRegisteredMachine(ctx, api, session, folder.MachineID, c.registrationWait)
+		if err != nil {
+			return err
+		}
+
+		sessObj, err := api.GetSessionObject(ctx, session)
+		if err != nil {
+			return fmt.Errorf("failed to get session object: %w", err)
+		}
+		if err := api.LockMachine(ctx, machineRef, sessObj, true); err != nil {
+			return fmt.Errorf("failed to lock machine: %w", err)
+		}
+		defer unlockSession(ctx, api, sessObj)
+
+		// Creating the folder skips it when it exists, so it can be
+		// replayed on a save conflict.
+		return saveWithRetry(ctx, api, machineRef, sessObj, true, func(mutableMachineRef string) error {
+			existing, err := sharedFolder(ctx, api, mutableMachineRef, folder.Name)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				if *existing != folder.apiFolder() {
+					return fmt.Errorf("machine %s already has a shared folder named %q", folder.MachineID, folder.Name)
+				}
+				return nil
+			}
+			if err := api.CreateSharedFolder(ctx, mutableMachineRef, folder.apiFolder()); err != nil {
+				return fmt.Errorf("failed to create shared folder %q: %w", folder.Name, err)
+			}
+			return nil
+		})
+	})
+}
+
+// ReadSharedFolder returns the shared folder of a VM called name, or nil if
+// the VM has no such folder.
+func (c *Client) ReadSharedFolder(ctx context.Context, machineID, name string) (*SharedFolder, error) {
+	var result *SharedFolder
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+		folder, err := sharedFolder(ctx, api, machineRef, name)
+		if err != nil || folder == nil {
+			return err
+		}
+		result = &SharedFolder{
+			MachineID:      machineID,
+			Name:           folder.Name,
+			HostPath:       folder.HostPath,
+			Writable:       folder.Writable,
+			AutoMount:      folder.AutoMount,
+			AutoMountPoint: folder.AutoMountPoint,
+		}
+		return nil
+	})
+	return result, err
+}
+
+// DeleteSharedFolder removes a shared folder from a VM. A folder or VM that
+// does not exist is ignored.
+func (c *Client) DeleteSharedFolder(ctx context.Context, machineID, name string) error {
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			if IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+
+		sessObj, err := api.GetSessionObject(ctx, session)
+		if err != nil {
+			return fmt.Errorf("failed to get session object: %w", err)
+		}
+		if err := api.LockMachine(ctx, machineRef, sessObj, true); err != nil {
+			return fmt.Errorf("failed to lock machine: %w", err)
+		}
+		defer unlockSession(ctx, api, sessObj)
+
+		return saveWithRetry(ctx, api, machineRef, sessObj, true, func(mutableMachineRef string) error {
+			existing, err := sharedFolder(ctx, api, mutableMachineRef, name)
+			if err != nil || existing == nil {
+				return err
+			}
+			if err := api.RemoveSharedFolder(ctx, mutableMachineRef, name); err != nil {
+				return fmt.Errorf("failed to remove shared folder %q: %w", name, err)
+			}
+			return nil
+		})
+	})
+}
+
+// apiFolder returns the folder VirtualBox reports for f.
+func (f SharedFolder) apiFolder() vboxapi.SharedFolder {
+	return vboxapi.SharedFolder{
+		Name:           f.Name,
+		HostPath:       f.HostPath,
+		Writable:       f.Writable,
+		AutoMount:      f.AutoMount,
+		AutoMountPoint: f.AutoMountPoint,
+	}
+}
+
+// sharedFolder returns the shared folder of a machine called name, or nil
+// if there is none.
+func sharedFolder(ctx context.Context, api vboxapi.VBoxAPI, machineRef, name string) (*vboxapi.SharedFolder, error) {
+	folders, err := api.GetSharedFolders(ctx, machineRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shared folders: %w", err)
+	}
+	for _, f := range folders {
+		if f.Name == name {
+			return &f, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,101 @@
+package vbox
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestSharedFolder(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+	ctx := context.Background()
+
+	folder := SharedFolder{
+		MachineID:      "uuid-vm",
+		Name:           "src",
+		HostPath:       "/home/user/src",
+		Writable:       true,
+		AutoMount:      true,
+		AutoMountPoint: "/mnt/src",
+	}
+	if err := c.CreateSharedFolder(ctx, folder); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !api.lockShared {
+		t.Error("expected the folder to be created under a shared lock")
+	}
+
+	got, err := c.ReadSharedFolder(ctx, "uuid-vm", "src")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || *got != folder {
+		t.Errorf("ReadSharedFolder() = %+v, want %+v", got, folder)
+	}
+
+	// Creating the same folder again is a no-op; another folder of the
+	// same name is an error.
+	if err := c.CreateSharedFolder(ctx, folder); err != nil {
+		t.Errorf("unexpected error re-creating the folder: %v", err)
+	}
+	other := folder
+	other.HostPath = "/tmp"
+	err = c.CreateSharedFolder(ctx, other)
+	if err == nil || !strings.Contains(err.Error(), `already has a shared folder named "src"`) {
+		t.Errorf("error = %v, want the folder to already exist", err)
+	}
+	if n := api.called("CreateSharedFolder"); n != 1 {
+		t.Errorf("CreateSharedFolder called %d times, want 1", n)
+	}
+
+	if err := c.DeleteSharedFolder(ctx, "uuid-vm", "src"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := c.ReadSharedFolder(ctx, "uuid-vm", "src"); err != nil || got != nil {
+		t.Errorf("ReadSharedFolder() = %+v, %v, want no folder", got, err)
+	}
+
+	// Deleting a folder that is gone, or from a VM that is gone, succeeds.
+	if err := c.DeleteSharedFolder(ctx, "uuid-vm", "src"); err != nil {
+		t.Errorf("unexpected error deleting a missing folder: %v", err)
+	}
+	if err := c.DeleteSharedFolder(ctx, "missing", "src"); err != nil {
+		t.Errorf("unexpected error deleting from a missing VM: %v", err)
+	}
+	if n := api.called("RemoveSharedFolder"); n != 1 {
+		t.Errorf("RemoveSharedFolder called %d times, want 1", n)
+	}
+}
+
+func TestCreateSharedFolder_RetriesSaveConflict(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	api.saveErrs = []error{&vboxapi.Error{
+		Operation:  "IMachine_saveSettings",
+		ResultCode: vboxapi.ResultFileError,
+		Text:       "Die Einstellungsdatei der VM wurde von einem anderen Prozess geändert",
+	}}
+	c := newTestClient(api)
+
+	if err := c.CreateSharedFolder(context.Background(), SharedFolder{MachineID: "uuid-vm", Name: "src", HostPath: "/src"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("SaveSettings"); n != 2 {
+		t.Errorf("SaveSettings called %d times, want 2", n)
+	}
+	if folders := api.machines["machine-vm"].SharedFolders; len(folders) != 1 {
+		t.Errorf("unexpected shared folders: %+v", folders)
+	}
+}
+
+func TestSharedFolder_MissingMachine(t *testing.T) {
+	c := newTestClient(newFakeAPI())
+
+	if _, err := c.ReadSharedFolder(context.Background(), "missing", "src"); !IsNotFound(err) {
+		t.Errorf("error = %v, want a not found error", err)
+	}
+}
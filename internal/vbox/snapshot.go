@@ -0,0 +1,238 @@
+package vbox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// Snapshot describes a snapshot of a VM.
+type Snapshot struct {
+	MachineID   string
+	ID          string
+	Name        string
+	Description string
+	TakenAt     time.Time
+}
+
+// TakeSnapshot takes a snapshot of a VM and waits up to timeout for it to
+// complete. A running VM keeps running: its memory is saved along with its
+// disks.
+func (c *Client) TakeSnapshot(ctx context.Context, machineID, name, description string, timeout time.Duration) (*Snapshot, error) {
+	if timeout <= 0 {
+		timeout = 20 * time.Minute
+	}
+
+	var snapshotID string
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+		return withMutableMachine(ctx, api, session, machineRef, func(mutableMachineRef string) error {
+			var progressRef string
+			snapshotID, progressRef, err = api.TakeSnapshot(ctx, mutableMachineRef, name, description, false)
+			if err != nil {
+				return fmt.Errorf("failed to take snapshot %q: %w", name, err)
+			}
+			return c.waitProgress(ctx, api, progressRef, timeout)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := c.ReadSnapshot(ctx, machineID, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	if snap == nil {
+		return nil, fmt.Errorf("snapshot %s of machine %s was taken but could not be read back", snapshotID, machineID)
+	}
+	return snap, nil
+}
+
+// ReadSnapshot reads a snapshot of a VM by its UUID. Returns nil, nil if the
+// VM has no such snapshot.
+func (c *Client) ReadSnapshot(ctx context.Context, machineID, snapshotID string) (*Snapshot, error) {
+	var result *Snapshot
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+		snapshotRef, err := findSnapshot(ctx, api, machineRef, snapshotID)
+		if err != nil {
+			if IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+
+		snap := Snapshot{MachineID: machineID}
+		if snap.ID, err = api.GetSnapshotId(ctx, snapshotRef); err != nil {
+			return fmt.Errorf("failed to get snapshot ID: %w", err)
+		}
+		if snap.Name, err = api.GetSnapshotName(ctx, snapshotRef); err != nil {
+			return fmt.Errorf("failed to get snapshot name: %w", err)
+		}
+		if snap.Description, err = api.GetSnapshotDescription(ctx, snapshotRef); err != nil {
+			return fmt.Errorf("failed to get snapshot description: %w", err)
+		}
+		if snap.TakenAt, err = api.GetSnapshotTimeStamp(ctx, snapshotRef); err != nil {
+			return fmt.Errorf("failed to get snapshot time stamp: %w", err)
+		}
+		result = &snap
+		return nil
+	})
+	return result, err
+}
+
+// UpdateSnapshot renames a snapshot and changes its description. nil
+// fields are left unchanged.
+func (c *Client) UpdateSnapshot(ctx context.Context, machineID, snapshotID string, name, description *string) error {
+	if name == nil && description == nil {
+		return nil
+	}
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+		return withMutableMachine(ctx, api, session, machineRef, func(mutableMachineRef string) error {
+			snapshotRef, err := findSnapshot(ctx, api, mutableMachineRef, snapshotID)
+			if err != nil {
+				return err
+			}
+			if name != nil {
+				if err := api.SetSnapshotName(ctx, snapshotRef, *name); err != nil {
+					return fmt.Errorf("failed to rename snapshot to %q: %w", *name, err)
+				}
+			}
+			if description != nil {
+				if err := api.SetSnapshotDescription(ctx, snapshotRef, *description); err != nil {
+					return fmt.Errorf("failed to set snapshot description: %w", err)
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// DeleteSnapshot deletes a snapshot of a VM and waits up to timeout for its
+// differencing images to be merged.
+func (c *Client) DeleteSnapshot(ctx context.Context, machineID, snapshotID string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = 20 * time.Minute
+	}
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+		if _, err := findSnapshot(ctx, api, machineRef, snapshotID); err != nil {
+			return err
+		}
+		return withMutableMachine(ctx, api, session, machineRef, func(mutableMachineRef string) error {
+			progressRef, err := api.DeleteSnapshot(ctx, mutableMachineRef, snapshotID)
+			if err != nil {
+				return fmt.Errorf("failed to delete snapshot %s: %w", snapshotID, err)
+			}
+			return c.waitProgress(ctx, api, progressRef, timeout)
+		})
+	})
+}
+
+// RestoreSnapshot restores a VM to a snapshot, by name or UUID, and waits up
+// to timeout for it to complete. The VM must be powered off or saved; a VM
+// restored to a snapshot taken while it was running is left saved.
+func (c *Client) RestoreSnapshot(ctx context.Context, machineID, nameOrID string, timeout time.Duration) (*Snapshot, error) {
+	if timeout <= 0 {
+		timeout = 20 * time.Minute
+	}
+
+	var snapshotID string
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+		st, err := api.GetMachineState(ctx, machineRef)
+		if err != nil {
+			return err
+		}
+		if isMachineOnline(st) {
+			return fmt.Errorf("machine is %s; power off the VM (state = \"stopped\") to restore snapshot %q", st, nameOrID)
+		}
+		snapshotRef, err := findSnapshot(ctx, api, machineRef, nameOrID)
+		if err != nil {
+			return err
+		}
+		if snapshotID, err = api.GetSnapshotId(ctx, snapshotRef); err != nil {
+			return fmt.Errorf("failed to get snapshot ID: %w", err)
+		}
+		return withMutableMachine(ctx, api, session, machineRef, func(mutableMachineRef string) error {
+			progressRef, err := api.RestoreSnapshot(ctx, mutableMachineRef, snapshotRef)
+			if err != nil {
+				return fmt.Errorf("failed to restore snapshot %q: %w", nameOrID, err)
+			}
+			return c.waitProgress(ctx, api, progressRef, timeout)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := c.ReadSnapshot(ctx, machineID, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	if snap == nil {
+		return nil, fmt.Errorf("snapshot %s of machine %s was restored but could not be read back", snapshotID, machineID)
+	}
+	return snap, nil
+}
+
+// findSnapshot returns the ref of a snapshot of machineRef by name or UUID,
+// or an error that IsNotFound recognizes if there is none.
+func findSnapshot(ctx context.Context, api vboxapi.VBoxAPI, machineRef, nameOrID string) (string, error) {
+	snapshotRef, err := api.FindSnapshot(ctx, machineRef, nameOrID)
+	if err != nil {
+		if vboxapi.IsObjectNotFound(err) {
+			return "", fmt.Errorf("%w: snapshot %s", errNotFound, nameOrID)
+		}
+		return "", err
+	}
+	if strings.TrimSpace(snapshotRef) == "" {
+		return "", fmt.Errorf("%w: snapshot %s", errNotFound, nameOrID)
+	}
+	return snapshotRef, nil
+}
+
+// withMutableMachine runs fn on the mutable machine of machineRef. A
+// running VM only accepts a shared lock, which is enough for snapshot
+// operations; others get a write lock.
+func withMutableMachine(ctx context.Context, api vboxapi.VBoxAPI, session, machineRef string, fn func(mutableMachineRef string) error) error {
+	st, err := api.GetMachineState(ctx, machineRef)
+	if err != nil {
+		return err
+	}
+
+	sessObj, err := api.GetSessionObject(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to get session object: %w", err)
+	}
+	if err := api.LockMachine(ctx, machineRef, sessObj, isMachineOnline(st)); err != nil {
+		return fmt.Errorf("failed to lock machine: %w", err)
+	}
+	defer unlockSession(ctx, api, sessObj)
+
+	mutableMachineRef, err := api.GetMutableMachine(ctx, sessObj)
+	if err != nil {
+		return fmt.Errorf("failed to get mutable machine: %w", err)
+	}
+	return fn(mutableMachineRef)
+}
@@ -0,0 +1,149 @@
+package vbox
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestTakeSnapshot(t *testing.T) {
+	for _, state := range []string{vboxapi.MachineStatePoweredOff, vboxapi.MachineStateRunning} {
+		t.Run(state, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: state})
+			c := newTestClient(api)
+
+			snap, err := c.TakeSnapshot(context.Background(), "uuid-vm", "baseline", "before tests", 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := Snapshot{
+				MachineID:   "uuid-vm",
+				ID:          "uuid-snapshot-1",
+				Name:        "baseline",
+				Description: "before tests",
+				TakenAt:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			}
+			if *snap != want {
+				t.Errorf("snapshot = %+v, want %+v", *snap, want)
+			}
+			if online := state == vboxapi.MachineStateRunning; api.lockShared != online {
+				t.Errorf("shared lock = %t, want %t", api.lockShared, online)
+			}
+			if api.snapshots["snapshot-uuid-snapshot-1"].Paused {
+				t.Error("expected the VM not to be paused while the snapshot is taken")
+			}
+		})
+	}
+}
+
+func TestUpdateSnapshot(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	snap, err := c.TakeSnapshot(context.Background(), "uuid-vm", "baseline", "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	description := "after provisioning"
+	if err := c.UpdateSnapshot(context.Background(), "uuid-vm", snap.ID, nil, &description); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := c.ReadSnapshot(context.Background(), "uuid-vm", snap.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Name != "baseline" || got.Description != description {
+		t.Errorf("snapshot = %+v, want name baseline and description %q", *got, description)
+	}
+	if n := api.called("SetSnapshotName"); n != 0 {
+		t.Errorf("SetSnapshotName called %d times, want 0", n)
+	}
+}
+
+func TestDeleteSnapshot(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	snap, err := c.TakeSnapshot(context.Background(), "uuid-vm", "baseline", "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.DeleteSnapshot(context.Background(), "uuid-vm", snap.ID, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m := api.machines["machine-vm"]; m.Snapshots != 0 {
+		t.Errorf("machine has %d snapshots, want 0", m.Snapshots)
+	}
+
+	// A snapshot deleted out of band reads as missing.
+	got, err := c.ReadSnapshot(context.Background(), "uuid-vm", snap.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != nil {
+		t.Errorf("snapshot = %+v, want nil", *got)
+	}
+	if err := c.DeleteSnapshot(context.Background(), "uuid-vm", snap.ID, 0); !IsNotFound(err) {
+		t.Errorf("error = %v, want a not found error", err)
+	}
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	baseline, err := c.TakeSnapshot(context.Background(), "uuid-vm", "baseline", "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.TakeSnapshot(context.Background(), "uuid-vm", "provisioned", "", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Snapshots can be restored by name or UUID.
+	for _, nameOrID := range []string{"baseline", baseline.ID} {
+		api.machines["machine-vm"].CurrentSnapshot = "provisioned"
+		snap, err := c.RestoreSnapshot(context.Background(), "uuid-vm", nameOrID, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *snap != *baseline {
+			t.Errorf("snapshot = %+v, want %+v", *snap, *baseline)
+		}
+		if got := api.machines["machine-vm"].CurrentSnapshot; got != "baseline" {
+			t.Errorf("current snapshot = %q, want baseline", got)
+		}
+	}
+	if api.lockShared {
+		t.Error("expected a write lock")
+	}
+
+	if _, err := c.RestoreSnapshot(context.Background(), "uuid-vm", "missing", 0); !IsNotFound(err) {
+		t.Errorf("error = %v, want a not found error", err)
+	}
+}
+
+func TestRestoreSnapshot_Running(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	c := newTestClient(api)
+
+	if _, err := c.TakeSnapshot(context.Background(), "uuid-vm", "baseline", "", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err := c.RestoreSnapshot(context.Background(), "uuid-vm", "baseline", 0)
+	if err == nil || !strings.Contains(err.Error(), "power off") {
+		t.Fatalf("error = %v, want it to ask to power off the VM", err)
+	}
+	if n := api.called("RestoreSnapshot"); n != 0 {
+		t.Errorf("RestoreSnapshot called %d times, want 0", n)
+	}
+}
@@ -0,0 +1,594 @@
+package vbox
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// Medium access modes for storage attachments.
+const (
+	MediumAccessReadWrite = "readwrite"
+	MediumAccessReadOnly  = "readonly"
+	MediumAccessShareable = "shareable"
+)
+
+// StorageAttachment describes a medium attached to a VM storage controller.
+type StorageAttachment struct {
+	MachineID  string
+	Controller string
+	Port       int32
+	Device     int32
+	DeviceType vboxapi.DeviceType
+	// Medium is the path of the medium image on the VirtualBox host.
+	Medium   string
+	MediumID string
+	// AccessMode is one of readwrite, readonly or shareable. Shareable
+	// media are opened read-write and switched to the Shareable type so
+	// that several VMs can attach them at once.
+	AccessMode string
+}
+
+// AttachStorage opens the medium and attaches it to the given controller slot.
+// The VM must be powered off unless the controller supports hot-plug.
+func (c *Client) AttachStorage(ctx context.Context, att StorageAttachment) error {
+	accessMode, err := mediumAccessMode(att.AccessMode)
+	if err != nil {
+		return err
+	}
+	if att.AccessMode == MediumAccessShareable && att.DeviceType != vboxapi.DeviceTypeHardDisk {
+		return fmt.Errorf("access mode %s is only supported for hard disks", MediumAccessShareable)
+	}
+
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, att.MachineID)
+		if err != nil {
+			return err
+		}
+
+		// Removable drives may be attached empty.
+		var mediumRef string
+		if att.Medium != "" {
+			mediumRef, err = api.OpenMedium(ctx, session, att.Medium, att.DeviceType, accessMode)
+			if err != nil {
+				return fmt.Errorf("failed to open medium %s: %w", att.Medium, err)
+			}
+		} else if err := checkEmptyDrive(ctx, api, machineRef, att.Controller, att.DeviceType); err != nil {
+			return err
+		}
+
+		if att.AccessMode == MediumAccessShareable {
+			if err := ensureShareable(ctx, api, mediumRef, att.Medium); err != nil {
+				return err
+			}
+		}
+
+		sessObj, err := lockForStorageChange(ctx, api, session, machineRef, att.Controller)
+		if err != nil {
+			return err
+		}
+		defer unlockSession(ctx, api, sessObj)
+
+		mutableMachineRef, err := api.GetMutableMachine(ctx, sessObj)
+		if err != nil {
+			return fmt.Errorf("failed to get mutable machine: %w", err)
+		}
+
+		if mediumRef == "" {
+			if err := api.AttachDeviceWithoutMedium(ctx, mutableMachineRef, att.Controller, att.Port, att.Device, att.DeviceType); err != nil {
+				return fmt.Errorf("failed to attach empty %s drive to %s port %d device %d: %w", att.DeviceType, att.Controller, att.Port, att.Device, err)
+			}
+		} else if err := api.AttachDevice(ctx, mutableMachineRef, att.Controller, att.Port, att.Device, att.DeviceType, mediumRef); err != nil {
+			return fmt.Errorf("failed to attach medium to %s port %d device %d: %w", att.Controller, att.Port, att.Device, err)
+		}
+
+		if err := api.SaveSettings(ctx, mutableMachineRef); err != nil {
+			return fmt.Errorf("failed to save machine settings: %w", err)
+		}
+		return nil
+	})
+}
+
+// MountStorageMedium replaces the medium in an attached DVD or floppy drive
+// without detaching the drive. An empty Medium ejects the current medium.
+// This works on running VMs as well.
+func (c *Client) MountStorageMedium(ctx context.Context, att StorageAttachment) error {
+	if att.DeviceType != vboxapi.DeviceTypeDVD && att.DeviceType != vboxapi.DeviceTypeFloppy {
+		return fmt.Errorf("only DVD and floppy media can be changed in place, got %s", att.DeviceType)
+	}
+	accessMode, err := mediumAccessMode(att.AccessMode)
+	if err != nil {
+		return err
+	}
+
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, att.MachineID)
+		if err != nil {
+			return err
+		}
+
+		var mediumRef string
+		if att.Medium != "" {
+			mediumRef, err = api.OpenMedium(ctx, session, att.Medium, att.DeviceType, accessMode)
+			if err != nil {
+				return fmt.Errorf("failed to open medium %s: %w", att.Medium, err)
+			}
+		}
+
+		st, err := api.GetMachineState(ctx, machineRef)
+		if err != nil {
+			return err
+		}
+
+		sessObj, err := api.GetSessionObject(ctx, session)
+		if err != nil {
+			return fmt.Errorf("failed to get session object: %w", err)
+		}
+
+		// A running VM only accepts a shared lock; a powered-off one needs a write lock.
+		shared := isMachineOnline(st)
+		if err := api.LockMachine(ctx, machineRef, sessObj, shared); err != nil {
+			return fmt.Errorf("failed to lock machine: %w", err)
+		}
+		defer unlockSession(ctx, api, sessObj)
+
+		mutableMachineRef, err := api.GetMutableMachine(ctx, sessObj)
+		if err != nil {
+			return fmt.Errorf("failed to get mutable machine: %w", err)
+		}
+
+		if err := api.MountMedium(ctx, mutableMachineRef, att.Controller, att.Port, att.Device, mediumRef, false); err != nil {
+			if mediumRef == "" {
+				return fmt.Errorf("failed to eject medium from %s port %d device %d: %w", att.Controller, att.Port, att.Device, err)
+			}
+			return fmt.Errorf("failed to mount medium on %s port %d device %d: %w", att.Controller, att.Port, att.Device, err)
+		}
+
+		if err := api.SaveSettings(ctx, mutableMachineRef); err != nil {
+			return fmt.Errorf("failed to save machine settings: %w", err)
+		}
+		return nil
+	})
+}
+
+// lockForStorageChange locks a machine for attaching or detaching a device
+// on controller and returns the locked session object. The caller must
+// unlock it.
+func lockForStorageChange(ctx context.Context, api vboxapi.VBoxAPI, session, machineRef, controller string) (string, error) {
+	st, err := api.GetMachineState(ctx, machineRef)
+	if err != nil {
+		return "", err
+	}
+
+	// The controller type only matters for hot-plug.
+	var controllerType vboxapi.StorageControllerType
+	if isMachineOnline(st) {
+		controllerRef, err := api.GetStorageControllerByName(ctx, machineRef, controller)
+		if err != nil {
+			return "", fmt.Errorf("failed to get storage controller %s: %w", controller, err)
+		}
+		controllerType, err = api.GetStorageControllerType(ctx, controllerRef)
+		if err != nil {
+			return "", fmt.Errorf("failed to get storage controller type: %w", err)
+		}
+	}
+
+	shared, err := storageLockShared(st, controller, controllerType)
+	if err != nil {
+		return "", err
+	}
+
+	sessObj, err := api.GetSessionObject(ctx, session)
+	if err != nil {
+		return "", fmt.Errorf("failed to get session object: %w", err)
+	}
+	if err := api.LockMachine(ctx, machineRef, sessObj, shared); err != nil {
+		return "", fmt.Errorf("failed to lock machine: %w", err)
+	}
+	return sessObj, nil
+}
+
+// checkEmptyDrive verifies that an empty drive of deviceType can be
+// attached to controller. Only DVD and floppy drives can be empty.
+func checkEmptyDrive(ctx context.Context, api vboxapi.VBoxAPI, machineRef, controller string, deviceType vboxapi.DeviceType) error {
+	if deviceType != vboxapi.DeviceTypeDVD && deviceType != vboxapi.DeviceTypeFloppy {
+		return fmt.Errorf("only DVD and floppy drives can be attached without a medium, got %s", deviceType)
+	}
+
+	controllerRef, err := api.GetStorageControllerByName(ctx, machineRef, controller)
+	if err != nil {
+		return fmt.Errorf("failed to get storage controller %s: %w", controller, err)
+	}
+	controllerType, err := api.GetStorageControllerType(ctx, controllerRef)
+	if err != nil {
+		return fmt.Errorf("failed to get storage controller type: %w", err)
+	}
+	if !controllerSupportsDevice(controllerType, deviceType) {
+		return fmt.Errorf("storage controller %q (%s) does not support %s drives", controller, controllerType, deviceType)
+	}
+	return nil
+}
+
+// controllerSupportsDevice reports whether devices of type t can be attached
+// to a controller of type controllerType. Floppy drives need the floppy
+// controller, which takes nothing else, and NVMe only takes hard disks.
+func controllerSupportsDevice(controllerType vboxapi.StorageControllerType, t vboxapi.DeviceType) bool {
+	switch controllerType {
+	case vboxapi.StorageControllerI82078:
+		return t == vboxapi.DeviceTypeFloppy
+	case vboxapi.StorageControllerNVMe:
+		return t == vboxapi.DeviceTypeHardDisk
+	default:
+		return t == vboxapi.DeviceTypeHardDisk || t == vboxapi.DeviceTypeDVD
+	}
+}
+
+// storageLockShared decides how to lock a machine for a storage change.
+// Powered-off VMs take a write lock. Running VMs only accept a shared lock,
+// and VirtualBox then requires a hot-plug capable controller.
+func storageLockShared(state, controller string, controllerType vboxapi.StorageControllerType) (bool, error) {
+	if !isMachineOnline(state) {
+		return false, nil
+	}
+	if !storageHotPlugCapable(controllerType) {
+		return false, fmt.Errorf("machine is %s and storage controller %q (%s) does not support hot-plug; power off the VM to change its storage", state, controller, controllerType)
+	}
+	return true, nil
+}
+
+// storageHotPlugCapable reports whether devices can be attached to and
+// detached from a controller of this type while the VM runs.
+func storageHotPlugCapable(t vboxapi.StorageControllerType) bool {
+	switch t {
+	case vboxapi.StorageControllerIntelAhci, vboxapi.StorageControllerUSB:
+		return true
+	default:
+		return false
+	}
+}
+
+// isMachineOnline reports whether the VM process is up, in which case its
+// settings can only be changed through a shared lock.
+func isMachineOnline(state string) bool {
+	return state == vboxapi.MachineStateRunning || state == vboxapi.MachineStatePaused
+}
+
+// mediumAccessMode maps a storage attachment access mode to the mode used
+// to open the medium.
+func mediumAccessMode(mode string) (vboxapi.AccessMode, error) {
+	switch mode {
+	case "", MediumAccessReadWrite, MediumAccessShareable:
+		return vboxapi.AccessModeReadWrite, nil
+	case MediumAccessReadOnly:
+		return vboxapi.AccessModeReadOnly, nil
+	default:
+		return "", fmt.Errorf("invalid access mode: %s", mode)
+	}
+}
+
+// ensureShareable switches a medium to the Shareable type. VirtualBox only
+// allows this for fixed-size images.
+func ensureShareable(ctx context.Context, api vboxapi.VBoxAPI, mediumRef, location string) error {
+	mediumType, err := api.GetMediumType(ctx, mediumRef)
+	if err != nil {
+		return fmt.Errorf("failed to get medium type: %w", err)
+	}
+	if mediumType == vboxapi.MediumTypeShareable {
+		return nil
+	}
+
+	variants, err := api.GetMediumVariant(ctx, mediumRef)
+	if err != nil {
+		return fmt.Errorf("failed to get medium variant: %w", err)
+	}
+	if !slices.Contains(variants, vboxapi.MediumVariantFixed) {
+		return fmt.Errorf("medium %s cannot be shareable: VirtualBox requires shareable disks to be fixed-size, but it is dynamically allocated", location)
+	}
+
+	if err := api.SetMediumType(ctx, mediumRef, vboxapi.MediumTypeShareable); err != nil {
+		return fmt.Errorf("failed to set medium type to %s: %w", vboxapi.MediumTypeShareable, err)
+	}
+	return nil
+}
+
+// Disk image formats that cloned disks can be converted to.
+const (
+	DiskFormatVDI  = "VDI"
+	DiskFormatVMDK = "VMDK"
+	DiskFormatVHD  = "VHD"
+)
+
+// diskConversion describes how the hard disks of a clone are converted.
+type diskConversion struct {
+	// Format is the image format, e.g. DiskFormatVMDK; empty keeps the
+	// format of each disk.
+	Format string
+	// Fixed makes the disks fixed-size.
+	Fixed bool
+}
+
+// convertDisks replaces the hard disks of a powered off machine with copies
+// in the format and allocation of conv. VirtualBox cannot convert an image
+// in place, so each disk that needs it is copied to a new image, which is
+// attached in its place before the original is deleted. A copy in another
+// format is named after the original with the format's extension; a copy in
+// the same format is moved to the original's location. Differencing images,
+// such as the disks of linked clones, cannot be converted.
+func (c *Client) convertDisks(ctx context.Context, api vboxapi.VBoxAPI, session, machineRef string, conv diskConversion, timeout time.Duration) error {
+	attachments, err := api.GetMediumAttachments(ctx, machineRef)
+	if err != nil {
+		return fmt.Errorf("failed to list storage attachments: %w", err)
+	}
+	for _, ma := range attachments {
+		if ma.Type != vboxapi.DeviceTypeHardDisk || ma.MediumRef == "" {
+			continue
+		}
+		if err := c.convertDisk(ctx, api, session, machineRef, ma, conv, timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) convertDisk(ctx context.Context, api vboxapi.VBoxAPI, session, machineRef string, ma vboxapi.MediumAttachment, conv diskConversion, timeout time.Duration) error {
+	location, err := api.GetMediumLocation(ctx, ma.MediumRef)
+	if err != nil {
+		return fmt.Errorf("failed to get medium location: %w", err)
+	}
+	format, err := api.GetMediumFormat(ctx, ma.MediumRef)
+	if err != nil {
+		return fmt.Errorf("failed to get medium format: %w", err)
+	}
+	variants, err := api.GetMediumVariant(ctx, ma.MediumRef)
+	if err != nil {
+		return fmt.Errorf("failed to get medium variant: %w", err)
+	}
+	fixed := slices.Contains(variants, vboxapi.MediumVariantFixed)
+	sameFormat := conv.Format == "" || strings.EqualFold(conv.Format, format)
+	if sameFormat && (fixed || !conv.Fixed) {
+		return nil
+	}
+	base, err := api.GetMediumBase(ctx, ma.MediumRef)
+	if err != nil {
+		return fmt.Errorf("failed to get base medium: %w", err)
+	}
+	if base != ma.MediumRef {
+		return fmt.Errorf("disk %s cannot be converted: it is a differencing image, as the disks of linked clones are", location)
+	}
+
+	// A fixed-size disk stays fixed-size in another format.
+	variant := "Standard"
+	if fixed || conv.Fixed {
+		variant = vboxapi.MediumVariantFixed
+	}
+	ext := path.Ext(location)
+	targetFormat, targetLocation := format, strings.TrimSuffix(location, ext)+"-converted"+ext
+	if !sameFormat {
+		targetFormat = conv.Format
+		targetLocation = strings.TrimSuffix(location, ext) + "." + strings.ToLower(conv.Format)
+	}
+	targetRef, err := api.CreateMedium(ctx, session, targetFormat, targetLocation, vboxapi.DeviceTypeHardDisk)
+	if err != nil {
+		return fmt.Errorf("failed to create disk %s: %w", targetLocation, err)
+	}
+	progressRef, err := api.CloneMediumToBase(ctx, ma.MediumRef, targetRef, []string{variant})
+	if err == nil {
+		err = c.waitProgress(ctx, api, progressRef, timeout)
+	}
+	if err != nil {
+		// Best effort: the copy may have been partly written.
+		if progressRef, delErr := api.DeleteMediumStorage(ctx, targetRef); delErr == nil {
+			_ = c.waitProgress(ctx, api, progressRef, timeout)
+		}
+		return fmt.Errorf("failed to copy disk %s to %s: %w", location, targetLocation, err)
+	}
+
+	err = withMutableMachine(ctx, api, session, machineRef, func(mutableMachineRef string) error {
+		if err := api.DetachDevice(ctx, mutableMachineRef, ma.Controller, ma.Port, ma.Device); err != nil {
+			return fmt.Errorf("failed to detach disk %s: %w", location, err)
+		}
+		if err := api.AttachDevice(ctx, mutableMachineRef, ma.Controller, ma.Port, ma.Device, vboxapi.DeviceTypeHardDisk, targetRef); err != nil {
+			return fmt.Errorf("failed to attach disk %s: %w", targetLocation, err)
+		}
+		if err := api.SaveSettings(ctx, mutableMachineRef); err != nil {
+			return fmt.Errorf("failed to save machine settings: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	progressRef, err = api.DeleteMediumStorage(ctx, ma.MediumRef)
+	if err == nil {
+		err = c.waitProgress(ctx, api, progressRef, timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete disk %s after converting it: %w", location, err)
+	}
+	if !sameFormat {
+		return nil
+	}
+	progressRef, err = api.MoveMedium(ctx, targetRef, location)
+	if err == nil {
+		err = c.waitProgress(ctx, api, progressRef, timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to move disk %s to %s: %w", targetLocation, location, err)
+	}
+	return nil
+}
+
+// ReadStorageAttachment reads the medium attached to a controller slot.
+// Returns nil, nil if nothing is attached there.
+func (c *Client) ReadStorageAttachment(ctx context.Context, machineID, controller string, port, device int32) (*StorageAttachment, error) {
+	var result *StorageAttachment
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+
+		ma, err := api.GetMediumAttachment(ctx, machineRef, controller, port, device)
+		if err != nil {
+			if vboxapi.IsObjectNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to get medium attachment: %w", err)
+		}
+		if ma == nil {
+			return nil
+		}
+
+		result, err = storageAttachment(ctx, api, machineID, *ma)
+		return err
+	})
+	return result, err
+}
+
+// storageAttachment converts ma, resolving the ID and location of its
+// medium, if any.
+func storageAttachment(ctx context.Context, api vboxapi.VBoxAPI, machineID string, ma vboxapi.MediumAttachment) (*StorageAttachment, error) {
+	att := &StorageAttachment{
+		MachineID:  machineID,
+		Controller: ma.Controller,
+		Port:       ma.Port,
+		Device:     ma.Device,
+		DeviceType: ma.Type,
+	}
+	if ma.MediumRef == "" {
+		return att, nil
+	}
+
+	var err error
+	att.MediumID, err = api.GetMediumId(ctx, ma.MediumRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medium id: %w", err)
+	}
+	att.Medium, err = api.GetMediumLocation(ctx, ma.MediumRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medium location: %w", err)
+	}
+	return att, nil
+}
+
+// DetachStorage detaches the medium from a controller slot. The medium
+// itself is left registered, as other VMs may still use it. The VM must be
+// powered off unless the controller supports hot-plug.
+// Returns nil if the machine no longer exists.
+func (c *Client) DetachStorage(ctx context.Context, machineID, controller string, port, device int32) error {
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			if IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+
+		sessObj, err := lockForStorageChange(ctx, api, session, machineRef, controller)
+		if err != nil {
+			return err
+		}
+		defer unlockSession(ctx, api, sessObj)
+
+		mutableMachineRef, err := api.GetMutableMachine(ctx, sessObj)
+		if err != nil {
+			return fmt.Errorf("failed to get mutable machine: %w", err)
+		}
+
+		if err := api.DetachDevice(ctx, mutableMachineRef, controller, port, device); err != nil {
+			return fmt.Errorf("failed to detach %s port %d device %d: %w", controller, port, device, err)
+		}
+
+		if err := api.SaveSettings(ctx, mutableMachineRef); err != nil {
+			return fmt.Errorf("failed to save machine settings: %w", err)
+		}
+		return nil
+	})
+}
+
+// dependentMachines returns the names of the registered machines, other than
+// the machine with the given ID, that use a medium of that machine or a
+// differencing image derived from one. Linked clones are the common case.
+func dependentMachines(ctx context.Context, api vboxapi.VBoxAPI, session, machineRef, machineID string) ([]string, error) {
+	atts, err := api.GetMediumAttachments(ctx, machineRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medium attachments: %w", err)
+	}
+
+	dependents := make(map[string]bool)
+	seen := make(map[string]bool)
+	// walk visits the medium tree below mediumRef. owned is true when an
+	// ancestor of mediumRef belongs to the machine.
+	var walk func(mediumRef string, owned bool) error
+	walk = func(mediumRef string, owned bool) error {
+		if seen[mediumRef] {
+			return nil
+		}
+		seen[mediumRef] = true
+		ids, err := api.GetMediumMachineIds(ctx, mediumRef)
+		if err != nil {
+			return fmt.Errorf("failed to get machines using medium: %w", err)
+		}
+		owned = owned || slices.Contains(ids, machineID)
+		if owned {
+			for _, id := range ids {
+				if id != machineID {
+					dependents[id] = true
+				}
+			}
+		}
+		children, err := api.GetMediumChildren(ctx, mediumRef)
+		if err != nil {
+			return fmt.Errorf("failed to get medium children: %w", err)
+		}
+		for _, child := range children {
+			if err := walk(child, owned); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, att := range atts {
+		if att.MediumRef == "" || att.Type != vboxapi.DeviceTypeHardDisk {
+			continue
+		}
+		// Start from the base so that clones of earlier snapshots, which
+		// branch off above the current state, are found too.
+		baseRef, err := api.GetMediumBase(ctx, att.MediumRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get base medium: %w", err)
+		}
+		if err := walk(baseRef, false); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(dependents))
+	for id := range dependents {
+		names = append(names, dependentName(ctx, api, session, id))
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// dependentName returns the name of the machine with the given ID, falling
+// back to the ID when the machine cannot be looked up.
+func dependentName(ctx context.Context, api vboxapi.VBoxAPI, session, id string) string {
+	ref, err := api.FindMachine(ctx, session, id)
+	if err != nil || ref == "" {
+		return id
+	}
+	name, err := api.GetMachineName(ctx, ref)
+	if err != nil || name == "" {
+		return id
+	}
+	return fmt.Sprintf("%s (%s)", name, id)
+}
@@ -0,0 +1,369 @@
+package vbox
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+// StorageController describes a storage controller of a VM.
+type StorageController struct {
+	MachineID string
+	Name      string
+	Bus       vboxapi.StorageBus
+	// Type is the emulated chipset. When empty, VirtualBox uses the
+	// default type of the bus.
+	Type vboxapi.StorageControllerType
+	// PortCount is the number of ports. When 0, the bus default is kept.
+	PortCount uint32
+	// Bootable is whether the firmware may boot from the controller's
+	// devices. Only one controller of each type is bootable. nil leaves
+	// it unchanged; it is always set when read.
+	Bootable *bool
+}
+
+// storageBusControllerTypes lists the controller types each bus accepts.
+// The first one is the type VirtualBox gives new controllers.
+var storageBusControllerTypes = map[vboxapi.StorageBus][]vboxapi.StorageControllerType{
+	vboxapi.StorageBusIDE:        {vboxapi.StorageControllerPIIX4, vboxapi.StorageControllerPIIX3, vboxapi.StorageControllerICH6},
+	vboxapi.StorageBusSATA:       {vboxapi.StorageControllerIntelAhci},
+	vboxapi.StorageBusSCSI:       {vboxapi.StorageControllerLsiLogic, vboxapi.StorageControllerBusLogic},
+	vboxapi.StorageBusFloppy:     {vboxapi.StorageControllerI82078},
+	vboxapi.StorageBusSAS:        {vboxapi.StorageControllerLsiLogicSas},
+	vboxapi.StorageBusUSB:        {vboxapi.StorageControllerUSB},
+	vboxapi.StorageBusPCIe:       {vboxapi.StorageControllerNVMe},
+	vboxapi.StorageBusVirtioSCSI: {vboxapi.StorageControllerVirtioSCSI},
+}
+
+// ControllerTypesForBus returns the controller types a bus accepts, with
+// VirtualBox's default first.
+func ControllerTypesForBus(bus vboxapi.StorageBus) []vboxapi.StorageControllerType {
+	return storageBusControllerTypes[bus]
+}
+
+// CreateStorageController adds a storage controller to a powered-off VM.
+func (c *Client) CreateStorageController(ctx context.Context, ctl StorageController) error {
+	if err := checkControllerType(ctl.Bus, ctl.Type); err != nil {
+		return err
+	}
+
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		if feature, ok := storageBusFeature(ctl.Bus); ok {
+			if err := c.requireFeature(ctx, api, session, feature); err != nil {
+				return err
+			}
+		}
+
+		machineRef, err := findMachine(ctx, api, session, ctl.MachineID)
+		if err != nil {
+			return err
+		}
+
+		existing, err := api.GetStorageControllers(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to list storage controllers: %w", err)
+		}
+		if slices.ContainsFunc(existing, func(e vboxapi.StorageController) bool { return e.Name == ctl.Name }) {
+			return fmt.Errorf("machine %s already has a storage controller named %q", ctl.MachineID, ctl.Name)
+		}
+
+		return changeStorageControllers(ctx, api, session, machineRef, func(mutableMachineRef string) error {
+			controllerRef, err := api.AddStorageController(ctx, mutableMachineRef, ctl.Name, ctl.Bus)
+			if err != nil {
+				return extPackHint(ctl.Bus, fmt.Errorf("failed to add %s storage controller %q: %w", ctl.Bus, ctl.Name, err))
+			}
+			return configureStorageController(ctx, api, mutableMachineRef, controllerRef, ctl)
+		})
+	})
+}
+
+// ReadStorageController reads a storage controller by name. Returns nil, nil
+// if the VM has no controller with that name.
+func (c *Client) ReadStorageController(ctx context.Context, machineID, name string) (*StorageController, error) {
+	var result *StorageController
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+
+		controllers, err := api.GetStorageControllers(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to list storage controllers: %w", err)
+		}
+		for _, ctl := range controllers {
+			if ctl.Name == name {
+				result = &StorageController{
+					MachineID: machineID,
+					Name:      ctl.Name,
+					Bus:       ctl.Bus,
+					Type:      ctl.Type,
+					PortCount: ctl.PortCount,
+					Bootable:  &ctl.Bootable,
+				}
+				return nil
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// ControllerStorage is a storage controller and the devices attached to it.
+type ControllerStorage struct {
+	StorageController
+	// Attachments are ordered by port and device.
+	Attachments []StorageAttachment
+}
+
+// ReadMachineStorage reads the storage layout of a VM: its controllers, in
+// the order VirtualBox reports them, and what is attached to each.
+func (c *Client) ReadMachineStorage(ctx context.Context, machineID string) ([]ControllerStorage, error) {
+	var result []ControllerStorage
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+
+		controllers, err := api.GetStorageControllers(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to list storage controllers: %w", err)
+		}
+		attachments, err := api.GetMediumAttachments(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to list medium attachments: %w", err)
+		}
+
+		result = make([]ControllerStorage, 0, len(controllers))
+		for _, ctl := range controllers {
+			cs := ControllerStorage{
+				StorageController: StorageController{
+					MachineID: machineID,
+					Name:      ctl.Name,
+					Bus:       ctl.Bus,
+					Type:      ctl.Type,
+					PortCount: ctl.PortCount,
+					Bootable:  &ctl.Bootable,
+				},
+				Attachments: []StorageAttachment{},
+			}
+			for _, ma := range attachments {
+				if ma.Controller != ctl.Name {
+					continue
+				}
+				att, err := storageAttachment(ctx, api, machineID, ma)
+				if err != nil {
+					return err
+				}
+				cs.Attachments = append(cs.Attachments, *att)
+			}
+			slices.SortFunc(cs.Attachments, func(a, b StorageAttachment) int {
+				if a.Port != b.Port {
+					return int(a.Port - b.Port)
+				}
+				return int(a.Device - b.Device)
+			})
+			result = append(result, cs)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// ReadDiskNames returns the file names of the hard disks attached to a VM,
+// ordered by controller, port and device. After a clone they show whether
+// the disks were named after the new VM or, with KeepDiskNames, kept the
+// source's names.
+func (c *Client) ReadDiskNames(ctx context.Context, machineID string) ([]string, error) {
+	var names []string
+	err := c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+		attachments, err := api.GetMediumAttachments(ctx, machineRef)
+		if err != nil {
+			return fmt.Errorf("failed to list medium attachments: %w", err)
+		}
+		slices.SortFunc(attachments, func(a, b vboxapi.MediumAttachment) int {
+			if a.Controller != b.Controller {
+				return strings.Compare(a.Controller, b.Controller)
+			}
+			if a.Port != b.Port {
+				return int(a.Port - b.Port)
+			}
+			return int(a.Device - b.Device)
+		})
+
+		names = []string{}
+		for _, ma := range attachments {
+			if ma.Type != vboxapi.DeviceTypeHardDisk || ma.MediumRef == "" {
+				continue
+			}
+			location, err := api.GetMediumLocation(ctx, ma.MediumRef)
+			if err != nil {
+				return fmt.Errorf("failed to get medium location: %w", err)
+			}
+			names = append(names, mediumFileName(location))
+		}
+		return nil
+	})
+	return names, err
+}
+
+// mediumFileName returns the last element of a medium location. The
+// location is a path on the VirtualBox host, which may use either
+// separator.
+func mediumFileName(location string) string {
+	if i := strings.LastIndexAny(location, `/\`); i >= 0 {
+		return location[i+1:]
+	}
+	return location
+}
+
+// UpdateStorageController changes the type, port count and bootable flag of
+// a storage controller of a powered-off VM. Zero values are left unchanged.
+func (c *Client) UpdateStorageController(ctx context.Context, ctl StorageController) error {
+	if err := checkControllerType(ctl.Bus, ctl.Type); err != nil {
+		return err
+	}
+
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, ctl.MachineID)
+		if err != nil {
+			return err
+		}
+
+		return changeStorageControllers(ctx, api, session, machineRef, func(mutableMachineRef string) error {
+			controllerRef, err := api.GetStorageControllerByName(ctx, mutableMachineRef, ctl.Name)
+			if err != nil {
+				return fmt.Errorf("failed to get storage controller %s: %w", ctl.Name, err)
+			}
+			return configureStorageController(ctx, api, mutableMachineRef, controllerRef, ctl)
+		})
+	})
+}
+
+// DeleteStorageController removes a storage controller, and the devices
+// attached to it, from a powered-off VM.
+func (c *Client) DeleteStorageController(ctx context.Context, machineID, name string) error {
+	return c.withSession(ctx, func(ctx context.Context, api vboxapi.VBoxAPI, session string) error {
+		machineRef, err := findMachine(ctx, api, session, machineID)
+		if err != nil {
+			return err
+		}
+
+		return changeStorageControllers(ctx, api, session, machineRef, func(mutableMachineRef string) error {
+			if err := api.RemoveStorageController(ctx, mutableMachineRef, name); err != nil {
+				return fmt.Errorf("failed to remove storage controller %q: %w", name, err)
+			}
+			return nil
+		})
+	})
+}
+
+// changeStorageControllers runs fn on the mutable machine under a write
+// lock and saves the settings. VirtualBox only changes storage controllers
+// of powered-off VMs.
+func changeStorageControllers(ctx context.Context, api vboxapi.VBoxAPI, session, machineRef string, fn func(mutableMachineRef string) error) error {
+	st, err := api.GetMachineState(ctx, machineRef)
+	if err != nil {
+		return err
+	}
+	if isMachineOnline(st) {
+		return fmt.Errorf("machine is %s; power off the VM (state = \"stopped\") to change its storage controllers", st)
+	}
+
+	sessObj, err := api.GetSessionObject(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to get session object: %w", err)
+	}
+	if err := api.LockMachine(ctx, machineRef, sessObj, false); err != nil {
+		return fmt.Errorf("failed to lock machine: %w", err)
+	}
+	defer unlockSession(ctx, api, sessObj)
+
+	mutableMachineRef, err := api.GetMutableMachine(ctx, sessObj)
+	if err != nil {
+		return fmt.Errorf("failed to get mutable machine: %w", err)
+	}
+
+	if err := fn(mutableMachineRef); err != nil {
+		return err
+	}
+
+	if err := api.SaveSettings(ctx, mutableMachineRef); err != nil {
+		return fmt.Errorf("failed to save machine settings: %w", err)
+	}
+	return nil
+}
+
+func configureStorageController(ctx context.Context, api vboxapi.VBoxAPI, mutableMachineRef, controllerRef string, ctl StorageController) error {
+	if ctl.Type != "" {
+		if err := api.SetStorageControllerType(ctx, controllerRef, ctl.Type); err != nil {
+			return fmt.Errorf("failed to set storage controller type to %s: %w", ctl.Type, err)
+		}
+	}
+	if ctl.PortCount != 0 {
+		if err := api.SetStorageControllerPortCount(ctx, controllerRef, ctl.PortCount); err != nil {
+			return fmt.Errorf("failed to set port count of storage controller %q to %d: %w", ctl.Name, ctl.PortCount, err)
+		}
+	}
+	if ctl.Bootable != nil {
+		if *ctl.Bootable {
+			if err := checkBootableController(ctx, api, mutableMachineRef, ctl.Name); err != nil {
+				return err
+			}
+		}
+		if err := api.SetStorageControllerBootable(ctx, mutableMachineRef, ctl.Name, *ctl.Bootable); err != nil {
+			return fmt.Errorf("failed to set bootable flag of storage controller %q: %w", ctl.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkBootableController fails when another controller of the same type
+// as the controller named name is bootable. VirtualBox keeps one bootable
+// controller per type and would silently clear the other's flag.
+func checkBootableController(ctx context.Context, api vboxapi.VBoxAPI, mutableMachineRef, name string) error {
+	controllers, err := api.GetStorageControllers(ctx, mutableMachineRef)
+	if err != nil {
+		return fmt.Errorf("failed to list storage controllers: %w", err)
+	}
+	i := slices.IndexFunc(controllers, func(c vboxapi.StorageController) bool { return c.Name == name })
+	if i < 0 {
+		return nil
+	}
+	for _, other := range controllers {
+		if other.Name != name && other.Type == controllers[i].Type && other.Bootable {
+			return fmt.Errorf("storage controller %q is already the bootable %s controller; VirtualBox allows one per controller type, so make it not bootable first", other.Name, other.Type)
+		}
+	}
+	return nil
+}
+
+// checkControllerType verifies that bus accepts controllers of type t. An
+// empty t is always accepted.
+func checkControllerType(bus vboxapi.StorageBus, t vboxapi.StorageControllerType) error {
+	allowed, ok := storageBusControllerTypes[bus]
+	if !ok {
+		return fmt.Errorf("unknown storage bus %q", bus)
+	}
+	if t != "" && !slices.Contains(allowed, t) {
+		return fmt.Errorf("controller type %s is not supported on the %s bus; use one of %v", t, bus, allowed)
+	}
+	return nil
+}
+
+// extPackHint notes that NVMe and USB storage controllers are provided by
+// the VirtualBox Extension Pack when adding one fails.
+func extPackHint(bus vboxapi.StorageBus, err error) error {
+	if bus != vboxapi.StorageBusPCIe && bus != vboxapi.StorageBusUSB {
+		return err
+	}
+	return fmt.Errorf("%w (NVMe and USB storage controllers require the VirtualBox Extension Pack; check that it is installed on the VirtualBox host)", err)
+}
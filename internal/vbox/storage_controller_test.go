@@ -0,0 +1,336 @@
+package vbox
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestCreateStorageController(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	err := c.CreateStorageController(context.Background(), StorageController{
+		MachineID: "uuid-vm",
+		Name:      "SCSI",
+		Bus:       vboxapi.StorageBusSCSI,
+		Type:      vboxapi.StorageControllerBusLogic,
+		PortCount: 8,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if api.lockShared {
+		t.Error("expected a write lock")
+	}
+	if n := api.called("SaveSettings"); n != 1 {
+		t.Errorf("SaveSettings called %d times, want 1", n)
+	}
+
+	got, err := c.ReadStorageController(context.Background(), "uuid-vm", "SCSI")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bootable := true
+	want := StorageController{MachineID: "uuid-vm", Name: "SCSI", Bus: vboxapi.StorageBusSCSI, Type: vboxapi.StorageControllerBusLogic, PortCount: 8, Bootable: &bootable}
+	if got == nil || !reflect.DeepEqual(*got, want) {
+		t.Errorf("ReadStorageController() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCreateStorageController_DefaultType(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	err := c.CreateStorageController(context.Background(), StorageController{MachineID: "uuid-vm", Name: "NVMe", Bus: vboxapi.StorageBusPCIe})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"SetStorageControllerType", "SetStorageControllerPortCount"} {
+		if n := api.called(name); n != 0 {
+			t.Errorf("%s called %d times, want 0", name, n)
+		}
+	}
+	if got := api.controllers["NVMe"]; got != vboxapi.StorageControllerNVMe {
+		t.Errorf("controller type = %q, want %q", got, vboxapi.StorageControllerNVMe)
+	}
+}
+
+func TestCreateStorageController_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    string
+		existing bool
+		ctl      StorageController
+		addErr   error
+		wantErr  string
+	}{
+		{
+			name:    "type does not match bus",
+			ctl:     StorageController{Name: "SATA", Bus: vboxapi.StorageBusSATA, Type: vboxapi.StorageControllerPIIX4},
+			wantErr: "controller type PIIX4 is not supported on the SATA bus",
+		},
+		{
+			name:     "name taken",
+			existing: true,
+			ctl:      StorageController{Name: "SATA", Bus: vboxapi.StorageBusSATA},
+			wantErr:  `already has a storage controller named "SATA"`,
+		},
+		{
+			name:    "VM running",
+			state:   vboxapi.MachineStateRunning,
+			ctl:     StorageController{Name: "SATA", Bus: vboxapi.StorageBusSATA},
+			wantErr: "power off the VM",
+		},
+		{
+			name:    "NVMe without extension pack",
+			ctl:     StorageController{Name: "NVMe", Bus: vboxapi.StorageBusPCIe},
+			addErr:  errors.New("NVMe is not available"),
+			wantErr: "require the VirtualBox Extension Pack",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: tt.state})
+			if tt.existing {
+				api.controllers[tt.ctl.Name] = vboxapi.StorageControllerIntelAhci
+			}
+			api.addControllerErr = tt.addErr
+			c := newTestClient(api)
+
+			tt.ctl.MachineID = "uuid-vm"
+			err := c.CreateStorageController(context.Background(), tt.ctl)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if n := api.called("SaveSettings"); n != 0 {
+				t.Errorf("SaveSettings called %d times, want 0", n)
+			}
+		})
+	}
+}
+
+func TestUpdateStorageController(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	api.controllers["IDE"] = vboxapi.StorageControllerPIIX4
+	c := newTestClient(api)
+
+	err := c.UpdateStorageController(context.Background(), StorageController{
+		MachineID: "uuid-vm",
+		Name:      "IDE",
+		Bus:       vboxapi.StorageBusIDE,
+		Type:      vboxapi.StorageControllerICH6,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := api.controllers["IDE"]; got != vboxapi.StorageControllerICH6 {
+		t.Errorf("controller type = %q, want %q", got, vboxapi.StorageControllerICH6)
+	}
+	if n := api.called("SetStorageControllerPortCount"); n != 0 {
+		t.Errorf("SetStorageControllerPortCount called %d times, want 0", n)
+	}
+}
+
+func TestDeleteStorageController(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	ctx := context.Background()
+	if err := c.CreateStorageController(ctx, StorageController{MachineID: "uuid-vm", Name: "SATA", Bus: vboxapi.StorageBusSATA}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.DeleteStorageController(ctx, "uuid-vm", "SATA"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := c.ReadStorageController(ctx, "uuid-vm", "SATA")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != nil {
+		t.Errorf("expected the controller to be gone, got %+v", got)
+	}
+}
+
+func TestReadMachineStorage(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{
+		ID:    "uuid-vm",
+		Name:  "vm",
+		Disks: []string{"medium-os"},
+		Attachments: []vboxapi.MediumAttachment{
+			{Controller: "IDE", Port: 1, Device: 0, Type: vboxapi.DeviceTypeDVD},
+			{Controller: "IDE", Port: 0, Device: 1, Type: vboxapi.DeviceTypeDVD, MediumRef: "medium-iso"},
+		},
+	})
+	api.addMedium("medium-os", &fakeMedium{ID: "uuid-os", Location: "/vms/vm/os.vdi"})
+	api.addMedium("medium-iso", &fakeMedium{ID: "uuid-iso", Location: "/isos/tools.iso"})
+	api.controllers["IDE"] = vboxapi.StorageControllerPIIX4
+	api.controllerBuses["IDE"] = vboxapi.StorageBusIDE
+	api.controllerPorts["IDE"] = 2
+	api.controllers["SATA"] = vboxapi.StorageControllerIntelAhci
+	api.controllerBuses["SATA"] = vboxapi.StorageBusSATA
+	api.controllerPorts["SATA"] = 4
+	api.controllers["NVMe"] = vboxapi.StorageControllerNVMe
+	api.controllerBuses["NVMe"] = vboxapi.StorageBusPCIe
+	api.controllerPorts["NVMe"] = 1
+	c := newTestClient(api)
+
+	got, err := c.ReadMachineStorage(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d controllers, want 3", len(got))
+	}
+
+	ide, nvme, sata := got[0], got[1], got[2]
+	if ide.Name != "IDE" || ide.Bus != vboxapi.StorageBusIDE || ide.Type != vboxapi.StorageControllerPIIX4 || ide.PortCount != 2 {
+		t.Errorf("IDE controller = %+v", ide.StorageController)
+	}
+	wantIDE := []StorageAttachment{
+		{MachineID: "uuid-vm", Controller: "IDE", Port: 0, Device: 1, DeviceType: vboxapi.DeviceTypeDVD, Medium: "/isos/tools.iso", MediumID: "uuid-iso"},
+		{MachineID: "uuid-vm", Controller: "IDE", Port: 1, Device: 0, DeviceType: vboxapi.DeviceTypeDVD},
+	}
+	if !slices.Equal(ide.Attachments, wantIDE) {
+		t.Errorf("IDE attachments = %+v, want %+v", ide.Attachments, wantIDE)
+	}
+
+	if nvme.Attachments == nil || len(nvme.Attachments) != 0 {
+		t.Errorf("NVMe attachments = %#v, want an empty list", nvme.Attachments)
+	}
+
+	wantSATA := []StorageAttachment{
+		{MachineID: "uuid-vm", Controller: "SATA", Port: 0, DeviceType: vboxapi.DeviceTypeHardDisk, Medium: "/vms/vm/os.vdi", MediumID: "uuid-os"},
+	}
+	if !slices.Equal(sata.Attachments, wantSATA) {
+		t.Errorf("SATA attachments = %+v, want %+v", sata.Attachments, wantSATA)
+	}
+}
+
+func TestReadMachineStorage_NoControllers(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+
+	got, err := c.ReadMachineStorage(context.Background(), "uuid-vm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("ReadMachineStorage() = %#v, want an empty list", got)
+	}
+}
+
+func TestReadDiskNames_AfterClone(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []string
+		want    []string
+	}{
+		{name: "named after the clone", want: []string{"web.vdi", "web-disk1.vdi"}},
+		{name: "KeepDiskNames", options: []string{"KeepDiskNames"}, want: []string{"template.vdi", "data.vdi"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMedium("medium-os", &fakeMedium{ID: "uuid-os", Location: "/vms/template/template.vdi"})
+			api.addMedium("medium-data", &fakeMedium{ID: "uuid-data", Location: "/vms/template/data.vdi"})
+			api.addMachine("machine-template", &fakeMachine{ID: "uuid-template", Name: "template", Disks: []string{"medium-os", "medium-data"}})
+			c := newTestClient(api)
+
+			result, err := c.CloneAndConverge(context.Background(), CloneRequest{Name: "web", Source: "template", CloneOptions: tt.options})
+			if err != nil {
+				t.Fatalf("unexpected clone error: %v", err)
+			}
+			got, err := c.ReadDiskNames(context.Background(), result.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ReadDiskNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMediumFileName(t *testing.T) {
+	tests := map[string]string{
+		"/vms/web/web.vdi":              "web.vdi",
+		`C:\VirtualBox VMs\web\web.vdi`: "web.vdi",
+		"web.vdi":                       "web.vdi",
+	}
+	for location, want := range tests {
+		if got := mediumFileName(location); got != want {
+			t.Errorf("mediumFileName(%q) = %q, want %q", location, got, want)
+		}
+	}
+}
+
+func TestUpdateStorageController_Bootable(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	c := newTestClient(api)
+	ctx := context.Background()
+
+	for _, name := range []string{"NVMe1", "NVMe2"} {
+		if err := c.CreateStorageController(ctx, StorageController{MachineID: "uuid-vm", Name: name, Bus: vboxapi.StorageBusPCIe}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !api.controllerBootable["NVMe1"] || api.controllerBootable["NVMe2"] {
+		t.Fatalf("bootable = %v, want only the first controller bootable", api.controllerBootable)
+	}
+
+	bootable := true
+	err := c.UpdateStorageController(ctx, StorageController{MachineID: "uuid-vm", Name: "NVMe2", Bus: vboxapi.StorageBusPCIe, Bootable: &bootable})
+	if err == nil || !strings.Contains(err.Error(), `storage controller "NVMe1" is already the bootable NVMe controller`) {
+		t.Fatalf("error = %v, want the other bootable controller reported", err)
+	}
+	if n := api.called("SetStorageControllerBootable"); n != 0 {
+		t.Errorf("SetStorageControllerBootable called %d times, want 0", n)
+	}
+
+	notBootable := false
+	if err := c.UpdateStorageController(ctx, StorageController{MachineID: "uuid-vm", Name: "NVMe1", Bus: vboxapi.StorageBusPCIe, Bootable: &notBootable}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.UpdateStorageController(ctx, StorageController{MachineID: "uuid-vm", Name: "NVMe2", Bus: vboxapi.StorageBusPCIe, Bootable: &bootable}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := c.ReadStorageController(ctx, "uuid-vm", "NVMe2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || got.Bootable == nil || !*got.Bootable {
+		t.Errorf("ReadStorageController() = %+v, want bootable", got)
+	}
+	if api.controllerBootable["NVMe1"] {
+		t.Error("expected NVMe1 not to be bootable")
+	}
+}
+
+func TestUpdateStorageController_BootableRunning(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	api.controllers["NVMe"] = vboxapi.StorageControllerNVMe
+	c := newTestClient(api)
+
+	bootable := true
+	err := c.UpdateStorageController(context.Background(), StorageController{MachineID: "uuid-vm", Name: "NVMe", Bus: vboxapi.StorageBusPCIe, Bootable: &bootable})
+	if err == nil || !strings.Contains(err.Error(), "power off the VM") {
+		t.Errorf("error = %v, want a power off error", err)
+	}
+}
@@ -0,0 +1,537 @@
+package vbox
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func TestAttachStorage_Shareable(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	api.addMedium("medium-data", &fakeMedium{ID: "uuid-data", Location: "/vms/data.vdi", Variants: []string{"Standard", vboxapi.MediumVariantFixed}})
+	c := newTestClient(api)
+
+	err := c.AttachStorage(context.Background(), StorageAttachment{
+		MachineID:  "uuid-vm",
+		Controller: "SATA",
+		Port:       1,
+		DeviceType: vboxapi.DeviceTypeHardDisk,
+		Medium:     "/vms/data.vdi",
+		AccessMode: MediumAccessShareable,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if api.openAccessMode != vboxapi.AccessModeReadWrite {
+		t.Errorf("OpenMedium access mode = %q, want %q", api.openAccessMode, vboxapi.AccessModeReadWrite)
+	}
+	if got := api.media["medium-data"].Type; got != vboxapi.MediumTypeShareable {
+		t.Errorf("medium type = %q, want %q", got, vboxapi.MediumTypeShareable)
+	}
+	if got := api.attachments["SATA:1:0"]; got != "medium-data" {
+		t.Errorf("attachment SATA:1:0 = %q, want %q", got, "medium-data")
+	}
+	if n := api.called("SaveSettings"); n != 1 {
+		t.Errorf("SaveSettings called %d times, want 1", n)
+	}
+}
+
+func TestAttachStorage_ShareableAlreadyShareable(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	api.addMedium("medium-data", &fakeMedium{ID: "uuid-data", Location: "/vms/data.vdi", Type: vboxapi.MediumTypeShareable})
+	c := newTestClient(api)
+
+	err := c.AttachStorage(context.Background(), StorageAttachment{
+		MachineID:  "uuid-vm",
+		Controller: "SATA",
+		Port:       1,
+		DeviceType: vboxapi.DeviceTypeHardDisk,
+		Medium:     "/vms/data.vdi",
+		AccessMode: MediumAccessShareable,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := api.called("SetMediumType"); n != 0 {
+		t.Errorf("SetMediumType called %d times, want 0", n)
+	}
+}
+
+func TestAttachStorage_ShareableRequiresFixedSize(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	api.addMedium("medium-data", &fakeMedium{ID: "uuid-data", Location: "/vms/data.vdi", Variants: []string{"Standard"}})
+	c := newTestClient(api)
+
+	err := c.AttachStorage(context.Background(), StorageAttachment{
+		MachineID:  "uuid-vm",
+		Controller: "SATA",
+		Port:       1,
+		DeviceType: vboxapi.DeviceTypeHardDisk,
+		Medium:     "/vms/data.vdi",
+		AccessMode: MediumAccessShareable,
+	})
+	if err == nil || !strings.Contains(err.Error(), "fixed-size") {
+		t.Fatalf("expected fixed-size error, got %v", err)
+	}
+	if n := api.called("SetMediumType"); n != 0 {
+		t.Errorf("SetMediumType called %d times, want 0", n)
+	}
+	if n := api.called("AttachDevice"); n != 0 {
+		t.Errorf("AttachDevice called %d times, want 0", n)
+	}
+}
+
+func TestAttachStorage_ReadOnly(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	api.addMedium("medium-data", &fakeMedium{ID: "uuid-data", Location: "/vms/data.vdi"})
+	c := newTestClient(api)
+
+	err := c.AttachStorage(context.Background(), StorageAttachment{
+		MachineID:  "uuid-vm",
+		Controller: "SATA",
+		Port:       1,
+		DeviceType: vboxapi.DeviceTypeHardDisk,
+		Medium:     "/vms/data.vdi",
+		AccessMode: MediumAccessReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if api.openAccessMode != vboxapi.AccessModeReadOnly {
+		t.Errorf("OpenMedium access mode = %q, want %q", api.openAccessMode, vboxapi.AccessModeReadOnly)
+	}
+	if n := api.called("SetMediumType"); n != 0 {
+		t.Errorf("SetMediumType called %d times, want 0", n)
+	}
+}
+
+func TestAttachStorage_ShareableOnlyForHardDisks(t *testing.T) {
+	c := newTestClient(newFakeAPI())
+
+	err := c.AttachStorage(context.Background(), StorageAttachment{
+		MachineID:  "uuid-vm",
+		Controller: "IDE",
+		DeviceType: vboxapi.DeviceTypeDVD,
+		Medium:     "/isos/tools.iso",
+		AccessMode: MediumAccessShareable,
+	})
+	if err == nil {
+		t.Fatal("expected error for shareable DVD")
+	}
+}
+
+func TestAttachStorage_EmptyDVDDrive(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	api.controllers["IDE"] = vboxapi.StorageControllerPIIX4
+	c := newTestClient(api)
+
+	err := c.AttachStorage(context.Background(), StorageAttachment{
+		MachineID:  "uuid-vm",
+		Controller: "IDE",
+		Port:       1,
+		DeviceType: vboxapi.DeviceTypeDVD,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, ok := api.attachments["IDE:1:0"]; !ok || got != "" {
+		t.Errorf("attachment IDE:1:0 = %q (present %v), want an empty drive", got, ok)
+	}
+	if n := api.called("AttachDeviceWithoutMedium"); n != 1 {
+		t.Errorf("AttachDeviceWithoutMedium called %d times, want 1", n)
+	}
+	for _, name := range []string{"OpenMedium", "AttachDevice"} {
+		if n := api.called(name); n != 0 {
+			t.Errorf("%s called %d times, want 0", name, n)
+		}
+	}
+	if n := api.called("SaveSettings"); n != 1 {
+		t.Errorf("SaveSettings called %d times, want 1", n)
+	}
+}
+
+func TestAttachStorage_EmptyDriveUnsupported(t *testing.T) {
+	tests := []struct {
+		name           string
+		controllerType vboxapi.StorageControllerType
+		deviceType     vboxapi.DeviceType
+		wantErr        string
+	}{
+		{
+			name:           "floppy on SATA",
+			controllerType: vboxapi.StorageControllerIntelAhci,
+			deviceType:     vboxapi.DeviceTypeFloppy,
+			wantErr:        `storage controller "ctl" (IntelAhci) does not support Floppy drives`,
+		},
+		{
+			name:           "DVD on NVMe",
+			controllerType: vboxapi.StorageControllerNVMe,
+			deviceType:     vboxapi.DeviceTypeDVD,
+			wantErr:        `storage controller "ctl" (NVMe) does not support DVD drives`,
+		},
+		{
+			name:           "DVD on floppy controller",
+			controllerType: vboxapi.StorageControllerI82078,
+			deviceType:     vboxapi.DeviceTypeDVD,
+			wantErr:        `storage controller "ctl" (I82078) does not support DVD drives`,
+		},
+		{
+			name:           "empty hard disk",
+			controllerType: vboxapi.StorageControllerIntelAhci,
+			deviceType:     vboxapi.DeviceTypeHardDisk,
+			wantErr:        "only DVD and floppy drives can be attached without a medium",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+			api.controllers["ctl"] = tt.controllerType
+			c := newTestClient(api)
+
+			err := c.AttachStorage(context.Background(), StorageAttachment{
+				MachineID:  "uuid-vm",
+				Controller: "ctl",
+				DeviceType: tt.deviceType,
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if n := api.called("LockMachine"); n != 0 {
+				t.Errorf("LockMachine called %d times, want 0", n)
+			}
+		})
+	}
+}
+
+func TestAttachStorage_EmptyFloppyDrive(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	api.controllers["Floppy"] = vboxapi.StorageControllerI82078
+	c := newTestClient(api)
+
+	err := c.AttachStorage(context.Background(), StorageAttachment{
+		MachineID:  "uuid-vm",
+		Controller: "Floppy",
+		DeviceType: vboxapi.DeviceTypeFloppy,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := api.attachments["Floppy:0:0"]; !ok {
+		t.Error("expected an empty floppy drive on Floppy:0:0")
+	}
+}
+
+func TestMountStorageMedium_Eject(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	api.addMedium("medium-iso", &fakeMedium{ID: "uuid-iso", Location: "/isos/install.iso"})
+	api.attachments["IDE:1:0"] = "medium-iso"
+	c := newTestClient(api)
+
+	err := c.MountStorageMedium(context.Background(), StorageAttachment{
+		MachineID:  "uuid-vm",
+		Controller: "IDE",
+		Port:       1,
+		DeviceType: vboxapi.DeviceTypeDVD,
+		Medium:     "",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, ok := api.attachments["IDE:1:0"]; !ok || got != "" {
+		t.Errorf("attachment IDE:1:0 = %q (present %v), want empty drive", got, ok)
+	}
+	if n := api.called("OpenMedium"); n != 0 {
+		t.Errorf("OpenMedium called %d times, want 0", n)
+	}
+	if n := api.called("DetachDevice"); n != 0 {
+		t.Errorf("DetachDevice called %d times, want 0", n)
+	}
+	if api.lockShared {
+		t.Error("expected a write lock for a powered-off VM")
+	}
+}
+
+func TestMountStorageMedium_Swap(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	api.addMedium("medium-old", &fakeMedium{ID: "uuid-old", Location: "/isos/old.iso"})
+	api.addMedium("medium-new", &fakeMedium{ID: "uuid-new", Location: "/isos/new.iso"})
+	api.attachments["IDE:1:0"] = "medium-old"
+	c := newTestClient(api)
+
+	err := c.MountStorageMedium(context.Background(), StorageAttachment{
+		MachineID:  "uuid-vm",
+		Controller: "IDE",
+		Port:       1,
+		DeviceType: vboxapi.DeviceTypeDVD,
+		Medium:     "/isos/new.iso",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := api.attachments["IDE:1:0"]; got != "medium-new" {
+		t.Errorf("attachment IDE:1:0 = %q, want %q", got, "medium-new")
+	}
+	if !api.lockShared {
+		t.Error("expected a shared lock for a running VM")
+	}
+	if n := api.called("SaveSettings"); n != 1 {
+		t.Errorf("SaveSettings called %d times, want 1", n)
+	}
+}
+
+func TestMountStorageMedium_HardDiskRejected(t *testing.T) {
+	c := newTestClient(newFakeAPI())
+
+	err := c.MountStorageMedium(context.Background(), StorageAttachment{
+		MachineID:  "uuid-vm",
+		Controller: "SATA",
+		DeviceType: vboxapi.DeviceTypeHardDisk,
+		Medium:     "/vms/other.vdi",
+	})
+	if err == nil {
+		t.Fatal("expected error when swapping a hard disk in place")
+	}
+}
+
+func TestStorageLockShared(t *testing.T) {
+	tests := []struct {
+		name           string
+		state          string
+		controllerType vboxapi.StorageControllerType
+		wantShared     bool
+		wantErr        bool
+	}{
+		{"powered off", vboxapi.MachineStatePoweredOff, vboxapi.StorageControllerPIIX4, false, false},
+		{"saved", vboxapi.MachineStateSaved, vboxapi.StorageControllerIntelAhci, false, false},
+		{"running sata", vboxapi.MachineStateRunning, vboxapi.StorageControllerIntelAhci, true, false},
+		{"paused usb", vboxapi.MachineStatePaused, vboxapi.StorageControllerUSB, true, false},
+		{"running ide", vboxapi.MachineStateRunning, vboxapi.StorageControllerPIIX4, false, true},
+		{"running nvme", vboxapi.MachineStateRunning, vboxapi.StorageControllerNVMe, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shared, err := storageLockShared(tt.state, "ctl", tt.controllerType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("storageLockShared() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if shared != tt.wantShared {
+				t.Errorf("storageLockShared() shared = %v, want %v", shared, tt.wantShared)
+			}
+		})
+	}
+}
+
+func TestAttachStorage_HotPlug(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	api.addMedium("medium-data", &fakeMedium{ID: "uuid-data", Location: "/vms/data.vdi"})
+	api.controllers["SATA"] = vboxapi.StorageControllerIntelAhci
+	c := newTestClient(api)
+
+	err := c.AttachStorage(context.Background(), StorageAttachment{
+		MachineID:  "uuid-vm",
+		Controller: "SATA",
+		Port:       2,
+		DeviceType: vboxapi.DeviceTypeHardDisk,
+		Medium:     "/vms/data.vdi",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !api.lockShared {
+		t.Error("expected a shared lock for hot-plug")
+	}
+	if got := api.attachments["SATA:2:0"]; got != "medium-data" {
+		t.Errorf("attachment SATA:2:0 = %q, want %q", got, "medium-data")
+	}
+}
+
+func TestAttachStorage_ColdAttachSkipsControllerLookup(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm"})
+	api.addMedium("medium-data", &fakeMedium{ID: "uuid-data", Location: "/vms/data.vdi"})
+	c := newTestClient(api)
+
+	err := c.AttachStorage(context.Background(), StorageAttachment{
+		MachineID:  "uuid-vm",
+		Controller: "IDE",
+		DeviceType: vboxapi.DeviceTypeHardDisk,
+		Medium:     "/vms/data.vdi",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if api.lockShared {
+		t.Error("expected a write lock for a powered-off VM")
+	}
+	if n := api.called("GetStorageControllerType"); n != 0 {
+		t.Errorf("GetStorageControllerType called %d times, want 0", n)
+	}
+}
+
+func TestAttachStorage_RunningWithoutHotPlug(t *testing.T) {
+	api := newFakeAPI()
+	api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStateRunning})
+	api.addMedium("medium-data", &fakeMedium{ID: "uuid-data", Location: "/vms/data.vdi"})
+	api.controllers["IDE"] = vboxapi.StorageControllerPIIX4
+	c := newTestClient(api)
+
+	err := c.AttachStorage(context.Background(), StorageAttachment{
+		MachineID:  "uuid-vm",
+		Controller: "IDE",
+		DeviceType: vboxapi.DeviceTypeHardDisk,
+		Medium:     "/vms/data.vdi",
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not support hot-plug") {
+		t.Fatalf("expected hot-plug error, got %v", err)
+	}
+	if n := api.called("LockMachine"); n != 0 {
+		t.Errorf("LockMachine called %d times, want 0", n)
+	}
+}
+
+func TestCloneAndConverge_FixedDisks(t *testing.T) {
+	api := newFakeAPI()
+	api.addMedium("medium-os", &fakeMedium{ID: "uuid-os", Location: "/vms/template/template.vdi"})
+	api.addMedium("medium-data", &fakeMedium{ID: "uuid-data", Location: "/vms/template/data.vdi"})
+	api.addMachine("machine-template", &fakeMachine{ID: "uuid-template", Name: "template", Disks: []string{"medium-os", "medium-data"}})
+	c := newTestClient(api)
+
+	_, err := c.CloneAndConverge(context.Background(), CloneRequest{Name: "web", Source: "template", FixedDisks: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for slot, location := range map[string]string{"SATA:0:0": "/vms/web/web.vdi", "SATA:1:0": "/vms/web/web-disk1.vdi"} {
+		ref := api.attachments[slot]
+		m := api.media[ref]
+		if m == nil {
+			t.Fatalf("%s: no medium attached", slot)
+		}
+		if m.Location != location {
+			t.Errorf("%s: location = %q, want %q", slot, m.Location, location)
+		}
+		if !slices.Contains(m.Variants, vboxapi.MediumVariantFixed) {
+			t.Errorf("%s: variants = %v, want %s", slot, m.Variants, vboxapi.MediumVariantFixed)
+		}
+	}
+	for _, ref := range []string{"machine-web-disk0", "machine-web-disk1"} {
+		if _, ok := api.media[ref]; ok {
+			t.Errorf("dynamically allocated disk %s was not deleted", ref)
+		}
+	}
+}
+
+func TestCloneAndConverge_DiskFormat(t *testing.T) {
+	api := newFakeAPI()
+	api.addMedium("medium-os", &fakeMedium{ID: "uuid-os", Location: "/vms/template/template.vdi", Variants: []string{"Standard", vboxapi.MediumVariantFixed}})
+	api.addMachine("machine-template", &fakeMachine{ID: "uuid-template", Name: "template", Disks: []string{"medium-os"}})
+	c := newTestClient(api)
+
+	_, err := c.CloneAndConverge(context.Background(), CloneRequest{Name: "web", Source: "template", DiskFormat: DiskFormatVMDK})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := api.media[api.attachments["SATA:0:0"]]
+	if m == nil || m.Location != "/vms/web/web.vmdk" {
+		t.Fatalf("attached disk = %+v, want /vms/web/web.vmdk", m)
+	}
+	if n := api.called("MoveMedium"); n != 0 {
+		t.Errorf("MoveMedium called %d times, want 0", n)
+	}
+
+	_, err = c.CloneAndConverge(context.Background(), CloneRequest{Name: "db", Source: "template", DiskFormat: "QCOW"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported disk format") {
+		t.Errorf("expected unsupported disk format error, got %v", err)
+	}
+}
+
+func TestConvertDisks(t *testing.T) {
+	tests := []struct {
+		name        string
+		disk        *fakeMedium
+		conv        diskConversion
+		wantErr     string
+		wantCopies  int
+		wantVariant string
+	}{
+		{
+			name:        "to fixed-size",
+			disk:        &fakeMedium{Location: "/vms/vm/vm.vdi", Variants: []string{"Standard"}},
+			conv:        diskConversion{Fixed: true},
+			wantCopies:  1,
+			wantVariant: vboxapi.MediumVariantFixed,
+		},
+		{
+			name: "already fixed-size",
+			disk: &fakeMedium{Location: "/vms/vm/vm.vdi", Variants: []string{"Standard", vboxapi.MediumVariantFixed}},
+			conv: diskConversion{Fixed: true},
+		},
+		{
+			name: "already in the format",
+			disk: &fakeMedium{Location: "/vms/vm/vm.vmdk", Variants: []string{"Standard"}},
+			conv: diskConversion{Format: DiskFormatVMDK},
+		},
+		{
+			name:        "to another format",
+			disk:        &fakeMedium{Location: "/vms/vm/vm.vdi", Variants: []string{"Standard"}},
+			conv:        diskConversion{Format: DiskFormatVHD},
+			wantCopies:  1,
+			wantVariant: "Standard",
+		},
+		{
+			name:        "fixed-size to another format",
+			disk:        &fakeMedium{Location: "/vms/vm/vm.vdi", Variants: []string{"Standard", vboxapi.MediumVariantFixed}},
+			conv:        diskConversion{Format: DiskFormatVMDK},
+			wantCopies:  1,
+			wantVariant: vboxapi.MediumVariantFixed,
+		},
+		{
+			name:    "differencing image",
+			disk:    &fakeMedium{Location: "/vms/vm/Snapshots/{diff}.vdi", Parent: "medium-base"},
+			conv:    diskConversion{Fixed: true},
+			wantErr: "differencing image",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addMedium("medium-base", &fakeMedium{Location: "/vms/template/template.vdi"})
+			api.addMedium("medium-vm", tt.disk)
+			api.addMachine("machine-vm", &fakeMachine{ID: "uuid-vm", Name: "vm", State: vboxapi.MachineStatePoweredOff, Disks: []string{"medium-vm"}})
+			c := newTestClient(api)
+
+			err := c.convertDisks(context.Background(), api, "session", "machine-vm", tt.conv, time.Minute)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := api.called("CloneMediumToBase"); got != tt.wantCopies {
+				t.Errorf("CloneMediumToBase called %d times, want %d", got, tt.wantCopies)
+			}
+			if tt.wantVariant != "" {
+				m := api.media[api.attachments["SATA:0:0"]]
+				if m == nil || !slices.Equal(m.Variants, []string{tt.wantVariant}) {
+					t.Errorf("attached disk = %+v, want variant %s", m, tt.wantVariant)
+				}
+			}
+		})
+	}
+}
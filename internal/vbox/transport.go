@@ -0,0 +1,282 @@
+package vbox
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Timeouts of the HTTP client sending the SOAP requests. They match the
+// defaults of the SOAP library, which only applies them to its own client.
+const (
+	soapDialTimeout         = 30 * time.Second
+	soapTLSHandshakeTimeout = 15 * time.Second
+	soapRequestTimeout      = 90 * time.Second
+)
+
+// TLSOptions configures how the client verifies, and authenticates to, an
+// https vboxwebsrv endpoint. The zero value verifies the server against
+// the host's root CAs.
+type TLSOptions struct {
+	// CACertPEM holds PEM encoded CA certificates trusted in addition to
+	// the host's root CAs, e.g. a private CA that signed the server's
+	// certificate.
+	CACertPEM string
+	// InsecureSkipVerify disables the verification of the server's
+	// certificate. Only meant for testing.
+	InsecureSkipVerify bool
+	// ClientCertPEM and ClientKeyPEM are the PEM encoded certificate and
+	// private key the client authenticates with. Both or neither must be
+	// set.
+	ClientCertPEM string
+	ClientKeyPEM  string
+}
+
+// tlsConfig returns the TLS configuration described by o.
+func (o TLSOptions) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CACertPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(o.CACertPEM)) {
+			return nil, fmt.Errorf("the CA certificate holds no PEM encoded certificate")
+		}
+		cfg.RootCAs = pool
+	}
+
+	if (o.ClientCertPEM == "") != (o.ClientKeyPEM == "") {
+		return nil, fmt.Errorf("the client certificate and key must be set together")
+	}
+	if o.ClientCertPEM != "" {
+		cert, err := tls.X509KeyPair([]byte(o.ClientCertPEM), []byte(o.ClientKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate or key: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// SetTLSOptions sets how the client connects to an https endpoint. It has
+// no effect on http endpoints.
+func (c *Client) SetTLSOptions(o TLSOptions) error {
+	cfg, err := o.tlsConfig()
+	if err != nil {
+		return err
+	}
+	c.transport.tlsConfig = cfg
+	c.httpClient = c.transport.httpClient()
+	return nil
+}
+
+// DefaultRequestTimeout is how long a single SOAP request may take unless
+// SetRequestOptions is called.
+const DefaultRequestTimeout = soapRequestTimeout
+
+// SetRequestOptions sets how long a single SOAP request may take, and how
+// many times a request that fails with a transient error is retried. A
+// timeout <= 0 uses DefaultRequestTimeout.
+//
+// Only errors raised before vboxwebsrv answered are retried: connection
+// failures, resets and timeouts, and 502, 503 and 504 responses from a
+// proxy in front of it. SOAP faults, such as a VM that does not exist, are
+// never retried. A request whose connection was reset may have been
+// processed by vboxwebsrv before the reset, so retries can repeat it.
+func (c *Client) SetRequestOptions(timeout time.Duration, maxRetries int) {
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	c.transport.requestTimeout = timeout
+	c.transport.maxRetries = max(maxRetries, 0)
+	c.httpClient = c.transport.httpClient()
+}
+
+// SetProxyURL sends the SOAP requests through the proxy at rawURL, an
+// http, https or socks5 URL. An empty rawURL uses the proxy set by the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables, as the
+// client does by default.
+func (c *Client) SetProxyURL(rawURL string) error {
+	if rawURL == "" {
+		c.transport.proxy = nil
+		c.httpClient = c.transport.httpClient()
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("invalid proxy URL %q: the scheme must be http, https, socks5 or socks5h", rawURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid proxy URL %q: missing host", rawURL)
+	}
+	c.transport.proxy = http.ProxyURL(u)
+	c.httpClient = c.transport.httpClient()
+	return nil
+}
+
+// transportOptions are the options the HTTP client sending the SOAP
+// requests is built from.
+type transportOptions struct {
+	// proxy selects the proxy of each request; nil uses the environment.
+	proxy          func(*http.Request) (*url.URL, error)
+	tlsConfig      *tls.Config
+	requestTimeout time.Duration
+	maxRetries     int
+	// backoff is the first wait between retries; 0 uses retryBackoff.
+	backoff time.Duration
+}
+
+// httpClient builds the HTTP client described by o.
+func (o transportOptions) httpClient() *http.Client {
+	dialer := &net.Dialer{Timeout: soapDialTimeout}
+	timeout := o.requestTimeout
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	backoff := o.backoff
+	if backoff <= 0 {
+		backoff = retryBackoff
+	}
+	proxy := o.proxy
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+	return &http.Client{
+		Transport: &retryTransport{
+			base: &http.Transport{
+				Proxy:               proxy,
+				TLSClientConfig:     o.tlsConfig,
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: soapTLSHandshakeTimeout,
+			},
+			timeout:    timeout,
+			maxRetries: o.maxRetries,
+			backoff:    backoff,
+		},
+	}
+}
+
+// Backoff between retried requests: it doubles after each attempt, up to
+// retryMaxBackoff.
+const (
+	retryBackoff    = 500 * time.Millisecond
+	retryMaxBackoff = 8 * time.Second
+)
+
+// retryTransport sends requests through base, giving each attempt timeout
+// to complete, and retries those that fail with a transient error up to
+// maxRetries times.
+type retryTransport struct {
+	base       http.RoundTripper
+	timeout    time.Duration
+	maxRetries int
+	backoff    time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	backoff := t.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.roundTrip(req)
+		if attempt >= t.maxRetries || req.GetBody == nil || ctx.Err() != nil || !isTransientHTTPFailure(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		tflog.Debug(ctx, "Retrying SOAP request after a transient failure", map[string]interface{}{
+			"attempt": attempt + 1,
+			"error":   transientFailureString(resp, err),
+			"backoff": backoff.String(),
+		})
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, retryMaxBackoff)
+
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(ctx)
+		req.Body = body
+	}
+}
+
+// roundTrip sends a single attempt of req. The attempt's deadline also
+// covers reading the response body.
+func (t *retryTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases the context of a request once its response body
+// is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// isTransientHTTPFailure reports whether a request failed before
+// vboxwebsrv answered it, so that sending it again may succeed. vboxwebsrv
+// reports SOAP faults with status 500, which is not transient.
+func isTransientHTTPFailure(resp *http.Response, err error) bool {
+	if err == nil {
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func transientFailureString(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
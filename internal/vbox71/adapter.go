@@ -4,8 +4,10 @@ package vbox71
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox71/generated"
 	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
@@ -17,9 +19,15 @@ type Adapter struct {
 	svc generated.VboxPortType
 }
 
-// NewAdapter creates a new adapter for VirtualBox 7.1.
-func NewAdapter(endpoint string) *Adapter {
-	soapClient := soap.NewClient(endpoint)
+// NewAdapter creates a new adapter for VirtualBox 7.1. httpClient, when not
+// nil, sends the SOAP requests, e.g. to trust a private CA; nil uses the
+// SOAP library's default client.
+func NewAdapter(endpoint string, httpClient *http.Client) *Adapter {
+	var opts []soap.Option
+	if httpClient != nil {
+		opts = append(opts, soap.WithHTTPClient(httpClient))
+	}
+	soapClient := soap.NewClient(endpoint, opts...)
 	return &Adapter{svc: generated.NewVboxPortType(soapClient)}
 }
 
@@ -29,7 +37,7 @@ func (a *Adapter) Logon(ctx context.Context, username, password string) (string,
 		Password: password,
 	})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IWebsessionManager_logon", err)
 	}
 	return resp.Returnval, nil
 }
@@ -38,7 +46,7 @@ func (a *Adapter) Logoff(ctx context.Context, session string) error {
 	_, err := a.svc.IWebsessionManager_logoffContext(ctx, &generated.IWebsessionManager_logoff{
 		RefIVirtualBox: session,
 	})
-	return err
+	return a.wrap(ctx, "IWebsessionManager_logoff", err)
 }
 
 func (a *Adapter) GetSessionObject(ctx context.Context, session string) (string, error) {
@@ -46,7 +54,7 @@ func (a *Adapter) GetSessionObject(ctx context.Context, session string) (string,
 		RefIVirtualBox: session,
 	})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IWebsessionManager_getSessionObject", err)
 	}
 	return resp.Returnval, nil
 }
@@ -57,41 +65,55 @@ func (a *Adapter) FindMachine(ctx context.Context, session, nameOrID string) (st
 		NameOrId: nameOrID,
 	})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IVirtualBox_findMachine", err)
 	}
 	return resp.Returnval, nil
 }
 
-func (a *Adapter) CreateMachine(ctx context.Context, session, name, osTypeId, sourceMachineRef string) (string, error) {
+func (a *Adapter) CreateMachine(ctx context.Context, session, name, osTypeId string, arch vboxapi.PlatformArchitecture) (string, error) {
 	// VBox 7.1 requires platform architecture
-	platformArch, err := a.getPlatformArchitecture(ctx, sourceMachineRef)
-	if err != nil {
-		// Default to x86 if we can't determine
-		arch := generated.PlatformArchitectureX86
-		platformArch = &arch
+	if arch == "" {
+		arch = vboxapi.PlatformArchitectureX86
 	}
+	platformArch := generated.PlatformArchitecture(arch)
 
 	resp, err := a.svc.IVirtualBox_createMachineContext(ctx, &generated.IVirtualBox_createMachine{
 		This:     session,
 		Name:     name,
-		Platform: platformArch,
+		Platform: &platformArch,
 		OsTypeId: osTypeId,
 	})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IVirtualBox_createMachine", err)
 	}
 	return resp.Returnval, nil
 }
 
+func (a *Adapter) ApplyDefaults(ctx context.Context, machineRef string) error {
+	_, err := a.svc.IMachine_applyDefaultsContext(ctx, &generated.IMachine_applyDefaults{This: machineRef})
+	return a.wrap(ctx, "IMachine_applyDefaults", err)
+}
+
+func (a *Adapter) GetPlatformArchitecture(ctx context.Context, machineRef string) (vboxapi.PlatformArchitecture, error) {
+	arch, err := a.getPlatformArchitecture(ctx, machineRef)
+	if err != nil {
+		return "", err
+	}
+	if arch == nil {
+		return vboxapi.PlatformArchitectureX86, nil
+	}
+	return vboxapi.PlatformArchitecture(*arch), nil
+}
+
 func (a *Adapter) getPlatformArchitecture(ctx context.Context, machineRef string) (*generated.PlatformArchitecture, error) {
 	platformResp, err := a.svc.IMachine_getPlatformContext(ctx, &generated.IMachine_getPlatform{This: machineRef})
 	if err != nil {
-		return nil, err
+		return nil, a.wrap(ctx, "IMachine_getPlatform", err)
 	}
 
 	archResp, err := a.svc.IPlatform_getArchitectureContext(ctx, &generated.IPlatform_getArchitecture{This: platformResp.Returnval})
 	if err != nil {
-		return nil, err
+		return nil, a.wrap(ctx, "IPlatform_getArchitecture", err)
 	}
 
 	return archResp.Returnval, nil
@@ -102,7 +124,7 @@ func (a *Adapter) RegisterMachine(ctx context.Context, session, machineRef strin
 		This:    session,
 		Machine: machineRef,
 	})
-	return err
+	return a.wrap(ctx, "IVirtualBox_registerMachine", err)
 }
 
 func (a *Adapter) UnregisterMachine(ctx context.Context, machineRef string) ([]string, error) {
@@ -112,7 +134,7 @@ func (a *Adapter) UnregisterMachine(ctx context.Context, machineRef string) ([]s
 		CleanupMode: &cm,
 	})
 	if err != nil {
-		return nil, err
+		return nil, a.wrap(ctx, "IMachine_unregister", err)
 	}
 	return resp.Returnval, nil
 }
@@ -123,7 +145,7 @@ func (a *Adapter) DeleteConfig(ctx context.Context, machineRef string, mediaRefs
 		Media: mediaRefs,
 	})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IMachine_deleteConfig", err)
 	}
 	return resp.Returnval, nil
 }
@@ -131,7 +153,7 @@ func (a *Adapter) DeleteConfig(ctx context.Context, machineRef string, mediaRefs
 func (a *Adapter) GetMachineId(ctx context.Context, machineRef string) (string, error) {
 	resp, err := a.svc.IMachine_getIdContext(ctx, &generated.IMachine_getId{This: machineRef})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IMachine_getId", err)
 	}
 	return resp.Returnval, nil
 }
@@ -139,7 +161,7 @@ func (a *Adapter) GetMachineId(ctx context.Context, machineRef string) (string,
 func (a *Adapter) GetMachineName(ctx context.Context, machineRef string) (string, error) {
 	resp, err := a.svc.IMachine_getNameContext(ctx, &generated.IMachine_getName{This: machineRef})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IMachine_getName", err)
 	}
 	return resp.Returnval, nil
 }
@@ -147,7 +169,7 @@ func (a *Adapter) GetMachineName(ctx context.Context, machineRef string) (string
 func (a *Adapter) GetMachineState(ctx context.Context, machineRef string) (string, error) {
 	resp, err := a.svc.IMachine_getStateContext(ctx, &generated.IMachine_getState{This: machineRef})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IMachine_getState", err)
 	}
 	if resp.Returnval == nil {
 		return vboxapi.MachineStateNull, nil
@@ -155,14 +177,144 @@ func (a *Adapter) GetMachineState(ctx context.Context, machineRef string) (strin
 	return string(*resp.Returnval), nil
 }
 
+func (a *Adapter) GetMachineAccessible(ctx context.Context, machineRef string) (bool, error) {
+	resp, err := a.svc.IMachine_getAccessibleContext(ctx, &generated.IMachine_getAccessible{This: machineRef})
+	if err != nil {
+		return false, a.wrap(ctx, "IMachine_getAccessible", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetMachineAccessError(ctx context.Context, machineRef string) (string, error) {
+	ei, err := a.svc.IMachine_getAccessErrorContext(ctx, &generated.IMachine_getAccessError{This: machineRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_getAccessError", err)
+	}
+	if strings.TrimSpace(ei.Returnval) == "" {
+		return "", nil
+	}
+
+	txt, err := a.svc.IVirtualBoxErrorInfo_getTextContext(ctx, &generated.IVirtualBoxErrorInfo_getText{This: ei.Returnval})
+	if err != nil {
+		return "", a.wrap(ctx, "IVirtualBoxErrorInfo_getText", err)
+	}
+	return txt.Returnval, nil
+}
+
 func (a *Adapter) GetOSTypeId(ctx context.Context, machineRef string) (string, error) {
 	resp, err := a.svc.IMachine_getOSTypeIdContext(ctx, &generated.IMachine_getOSTypeId{This: machineRef})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IMachine_getOSTypeId", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetSnapshotCount(ctx context.Context, machineRef string) (uint32, error) {
+	resp, err := a.svc.IMachine_getSnapshotCountContext(ctx, &generated.IMachine_getSnapshotCount{This: machineRef})
+	if err != nil {
+		return 0, a.wrap(ctx, "IMachine_getSnapshotCount", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetCurrentSnapshot(ctx context.Context, machineRef string) (string, error) {
+	resp, err := a.svc.IMachine_getCurrentSnapshotContext(ctx, &generated.IMachine_getCurrentSnapshot{This: machineRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_getCurrentSnapshot", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetSnapshotName(ctx context.Context, snapshotRef string) (string, error) {
+	resp, err := a.svc.ISnapshot_getNameContext(ctx, &generated.ISnapshot_getName{This: snapshotRef})
+	if err != nil {
+		return "", a.wrap(ctx, "ISnapshot_getName", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) TakeSnapshot(ctx context.Context, mutableMachineRef, name, description string, pause bool) (string, string, error) {
+	resp, err := a.svc.IMachine_takeSnapshotContext(ctx, &generated.IMachine_takeSnapshot{
+		This:        mutableMachineRef,
+		Name:        name,
+		Description: description,
+		Pause:       pause,
+	})
+	if err != nil {
+		return "", "", a.wrap(ctx, "IMachine_takeSnapshot", err)
+	}
+	return resp.Id, resp.Returnval, nil
+}
+
+func (a *Adapter) DeleteSnapshot(ctx context.Context, mutableMachineRef, snapshotID string) (string, error) {
+	resp, err := a.svc.IMachine_deleteSnapshotContext(ctx, &generated.IMachine_deleteSnapshot{
+		This: mutableMachineRef,
+		Id:   snapshotID,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_deleteSnapshot", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) RestoreSnapshot(ctx context.Context, mutableMachineRef, snapshotRef string) (string, error) {
+	resp, err := a.svc.IMachine_restoreSnapshotContext(ctx, &generated.IMachine_restoreSnapshot{
+		This:     mutableMachineRef,
+		Snapshot: snapshotRef,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_restoreSnapshot", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) FindSnapshot(ctx context.Context, machineRef, nameOrID string) (string, error) {
+	resp, err := a.svc.IMachine_findSnapshotContext(ctx, &generated.IMachine_findSnapshot{
+		This:     machineRef,
+		NameOrId: nameOrID,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_findSnapshot", err)
 	}
 	return resp.Returnval, nil
 }
 
+func (a *Adapter) GetSnapshotId(ctx context.Context, snapshotRef string) (string, error) {
+	resp, err := a.svc.ISnapshot_getIdContext(ctx, &generated.ISnapshot_getId{This: snapshotRef})
+	if err != nil {
+		return "", a.wrap(ctx, "ISnapshot_getId", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetSnapshotDescription(ctx context.Context, snapshotRef string) (string, error) {
+	resp, err := a.svc.ISnapshot_getDescriptionContext(ctx, &generated.ISnapshot_getDescription{This: snapshotRef})
+	if err != nil {
+		return "", a.wrap(ctx, "ISnapshot_getDescription", err)
+	}
+	return resp.Returnval, nil
+}
+
+// GetSnapshotTimeStamp returns when the snapshot was taken. VirtualBox
+// reports it in milliseconds since the Unix epoch.
+func (a *Adapter) GetSnapshotTimeStamp(ctx context.Context, snapshotRef string) (time.Time, error) {
+	resp, err := a.svc.ISnapshot_getTimeStampContext(ctx, &generated.ISnapshot_getTimeStamp{This: snapshotRef})
+	if err != nil {
+		return time.Time{}, a.wrap(ctx, "ISnapshot_getTimeStamp", err)
+	}
+	return time.UnixMilli(resp.Returnval).UTC(), nil
+}
+
+func (a *Adapter) SetSnapshotName(ctx context.Context, snapshotRef, name string) error {
+	_, err := a.svc.ISnapshot_setNameContext(ctx, &generated.ISnapshot_setName{This: snapshotRef, Name: name})
+	return a.wrap(ctx, "ISnapshot_setName", err)
+}
+
+func (a *Adapter) SetSnapshotDescription(ctx context.Context, snapshotRef, description string) error {
+	_, err := a.svc.ISnapshot_setDescriptionContext(ctx, &generated.ISnapshot_setDescription{This: snapshotRef, Description: description})
+	return a.wrap(ctx, "ISnapshot_setDescription", err)
+}
+
 func (a *Adapter) CloneTo(ctx context.Context, srcMachineRef, targetMachineRef, mode string, options []string) (string, error) {
 	m := generated.CloneMode(mode)
 
@@ -179,7 +331,7 @@ func (a *Adapter) CloneTo(ctx context.Context, srcMachineRef, targetMachineRef,
 		Options: optPtrs,
 	})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IMachine_cloneTo", err)
 	}
 	return resp.Returnval, nil
 }
@@ -191,7 +343,7 @@ func (a *Adapter) LaunchVMProcess(ctx context.Context, machineRef, sessionObj, s
 		Name:    sessionType,
 	})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IMachine_launchVMProcess", err)
 	}
 	return resp.Returnval, nil
 }
@@ -206,34 +358,147 @@ func (a *Adapter) LockMachine(ctx context.Context, machineRef, sessionObj string
 		Session:  sessionObj,
 		LockType: &lockType,
 	})
-	return err
+	return a.wrap(ctx, "IMachine_lockMachine", err)
 }
 
 func (a *Adapter) UnlockSession(ctx context.Context, sessionObj string) error {
 	_, err := a.svc.ISession_unlockMachineContext(ctx, &generated.ISession_unlockMachine{This: sessionObj})
-	return err
+	return a.wrap(ctx, "ISession_unlockMachine", err)
 }
 
 func (a *Adapter) GetConsole(ctx context.Context, sessionObj string) (string, error) {
 	resp, err := a.svc.ISession_getConsoleContext(ctx, &generated.ISession_getConsole{This: sessionObj})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "ISession_getConsole", err)
 	}
 	return resp.Returnval, nil
 }
 
 func (a *Adapter) PowerDown(ctx context.Context, consoleRef string) (string, error) {
 	resp, err := a.svc.IConsole_powerDownContext(ctx, &generated.IConsole_powerDown{This: consoleRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IConsole_powerDown", err)
+	}
+	return resp.Returnval, nil
+}
+
+// PowerButton sends an ACPI power button press to the guest, which
+// usually shuts down in response. It returns without waiting.
+func (a *Adapter) PowerButton(ctx context.Context, consoleRef string) error {
+	_, err := a.svc.IConsole_powerButtonContext(ctx, &generated.IConsole_powerButton{This: consoleRef})
+	return a.wrap(ctx, "IConsole_powerButton", err)
+}
+
+// Pause suspends the execution of a running VM; it stays in memory.
+func (a *Adapter) Pause(ctx context.Context, consoleRef string) error {
+	_, err := a.svc.IConsole_pauseContext(ctx, &generated.IConsole_pause{This: consoleRef})
+	return a.wrap(ctx, "IConsole_pause", err)
+}
+
+// Resume continues the execution of a paused VM.
+func (a *Adapter) Resume(ctx context.Context, consoleRef string) error {
+	_, err := a.svc.IConsole_resumeContext(ctx, &generated.IConsole_resume{This: consoleRef})
+	return a.wrap(ctx, "IConsole_resume", err)
+}
+
+func (a *Adapter) SaveState(ctx context.Context, mutableMachineRef string) (string, error) {
+	resp, err := a.svc.IMachine_saveStateContext(ctx, &generated.IMachine_saveState{This: mutableMachineRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_saveState", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) DiscardSavedState(ctx context.Context, mutableMachineRef string, removeFile bool) error {
+	_, err := a.svc.IMachine_discardSavedStateContext(ctx, &generated.IMachine_discardSavedState{
+		This:        mutableMachineRef,
+		FRemoveFile: removeFile,
+	})
+	return a.wrap(ctx, "IMachine_discardSavedState", err)
+}
+
+func (a *Adapter) PutScancodes(ctx context.Context, consoleRef string, scancodes []int32) (uint32, error) {
+	keyboardResp, err := a.svc.IConsole_getKeyboardContext(ctx, &generated.IConsole_getKeyboard{This: consoleRef})
+	if err != nil {
+		return 0, a.wrap(ctx, "IConsole_getKeyboard", err)
+	}
+	resp, err := a.svc.IKeyboard_putScancodesContext(ctx, &generated.IKeyboard_putScancodes{
+		This:      keyboardResp.Returnval,
+		Scancodes: scancodes,
+	})
+	if err != nil {
+		return 0, a.wrap(ctx, "IKeyboard_putScancodes", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetGuest(ctx context.Context, consoleRef string) (string, error) {
+	resp, err := a.svc.IConsole_getGuestContext(ctx, &generated.IConsole_getGuest{This: consoleRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IConsole_getGuest", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetAdditionsRunLevel(ctx context.Context, guestRef string) (vboxapi.AdditionsRunLevel, error) {
+	resp, err := a.svc.IGuest_getAdditionsRunLevelContext(ctx, &generated.IGuest_getAdditionsRunLevel{This: guestRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IGuest_getAdditionsRunLevel", err)
+	}
+	if resp.Returnval == nil {
+		return vboxapi.AdditionsRunLevelNone, nil
+	}
+	return vboxapi.AdditionsRunLevel(*resp.Returnval), nil
+}
+
+func (a *Adapter) GetMemoryBalloonSize(ctx context.Context, guestRef string) (uint32, error) {
+	resp, err := a.svc.IGuest_getMemoryBalloonSizeContext(ctx, &generated.IGuest_getMemoryBalloonSize{This: guestRef})
+	if err != nil {
+		return 0, a.wrap(ctx, "IGuest_getMemoryBalloonSize", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetMemoryBalloonSize(ctx context.Context, guestRef string, sizeMB uint32) error {
+	_, err := a.svc.IGuest_setMemoryBalloonSizeContext(ctx, &generated.IGuest_setMemoryBalloonSize{
+		This:              guestRef,
+		MemoryBalloonSize: sizeMB,
+	})
+	return a.wrap(ctx, "IGuest_setMemoryBalloonSize", err)
+}
+
+// UpdateGuestAdditions installs the Guest Additions from the ISO at source
+// into the guest. The update reboots the guest only if the installer
+// requires it.
+func (a *Adapter) UpdateGuestAdditions(ctx context.Context, guestRef, source string) (string, error) {
+	flag := generated.AdditionsUpdateFlagNone
+	resp, err := a.svc.IGuest_updateGuestAdditionsContext(ctx, &generated.IGuest_updateGuestAdditions{
+		This:   guestRef,
+		Source: source,
+		Flags:  []*generated.AdditionsUpdateFlag{&flag},
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IGuest_updateGuestAdditions", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetDefaultAdditionsISO(ctx context.Context, session string) (string, error) {
+	spRef, err := a.getSystemProperties(ctx, session)
 	if err != nil {
 		return "", err
 	}
+	resp, err := a.svc.ISystemProperties_getDefaultAdditionsISOContext(ctx, &generated.ISystemProperties_getDefaultAdditionsISO{This: spRef})
+	if err != nil {
+		return "", a.wrap(ctx, "ISystemProperties_getDefaultAdditionsISO", err)
+	}
 	return resp.Returnval, nil
 }
 
 func (a *Adapter) GetProgressCompleted(ctx context.Context, progressRef string) (bool, error) {
 	resp, err := a.svc.IProgress_getCompletedContext(ctx, &generated.IProgress_getCompleted{This: progressRef})
 	if err != nil {
-		return false, err
+		return false, a.wrap(ctx, "IProgress_getCompleted", err)
 	}
 	return resp.Returnval, nil
 }
@@ -241,7 +506,7 @@ func (a *Adapter) GetProgressCompleted(ctx context.Context, progressRef string)
 func (a *Adapter) GetProgressResultCode(ctx context.Context, progressRef string) (int32, error) {
 	resp, err := a.svc.IProgress_getResultCodeContext(ctx, &generated.IProgress_getResultCode{This: progressRef})
 	if err != nil {
-		return -1, err
+		return -1, a.wrap(ctx, "IProgress_getResultCode", err)
 	}
 	return resp.Returnval, nil
 }
@@ -249,7 +514,7 @@ func (a *Adapter) GetProgressResultCode(ctx context.Context, progressRef string)
 func (a *Adapter) GetProgressErrorText(ctx context.Context, progressRef string) (string, error) {
 	ei, err := a.svc.IProgress_getErrorInfoContext(ctx, &generated.IProgress_getErrorInfo{This: progressRef})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IProgress_getErrorInfo", err)
 	}
 	if strings.TrimSpace(ei.Returnval) == "" {
 		return "", nil
@@ -257,34 +522,318 @@ func (a *Adapter) GetProgressErrorText(ctx context.Context, progressRef string)
 
 	txt, err := a.svc.IVirtualBoxErrorInfo_getTextContext(ctx, &generated.IVirtualBoxErrorInfo_getText{This: ei.Returnval})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IVirtualBoxErrorInfo_getText", err)
 	}
 	return txt.Returnval, nil
 }
 
+func (a *Adapter) GetProgressOperationDescription(ctx context.Context, progressRef string) (string, error) {
+	resp, err := a.svc.IProgress_getOperationDescriptionContext(ctx, &generated.IProgress_getOperationDescription{This: progressRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IProgress_getOperationDescription", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetProgressDescription(ctx context.Context, progressRef string) (string, error) {
+	resp, err := a.svc.IProgress_getDescriptionContext(ctx, &generated.IProgress_getDescription{This: progressRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IProgress_getDescription", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetProgressPercent(ctx context.Context, progressRef string) (uint32, error) {
+	resp, err := a.svc.IProgress_getPercentContext(ctx, &generated.IProgress_getPercent{This: progressRef})
+	if err != nil {
+		return 0, a.wrap(ctx, "IProgress_getPercent", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetProgressCancelable(ctx context.Context, progressRef string) (bool, error) {
+	resp, err := a.svc.IProgress_getCancelableContext(ctx, &generated.IProgress_getCancelable{This: progressRef})
+	if err != nil {
+		return false, a.wrap(ctx, "IProgress_getCancelable", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) CancelProgress(ctx context.Context, progressRef string) error {
+	_, err := a.svc.IProgress_cancelContext(ctx, &generated.IProgress_cancel{This: progressRef})
+	if err != nil {
+		return a.wrap(ctx, "IProgress_cancel", err)
+	}
+	return nil
+}
+
 func (a *Adapter) GetAPIVersion(ctx context.Context, session string) (string, error) {
 	resp, err := a.svc.IVirtualBox_getAPIVersionContext(ctx, &generated.IVirtualBox_getAPIVersion{This: session})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IVirtualBox_getAPIVersion", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetVersion(ctx context.Context, session string) (string, error) {
+	resp, err := a.svc.IVirtualBox_getVersionContext(ctx, &generated.IVirtualBox_getVersion{This: session})
+	if err != nil {
+		return "", a.wrap(ctx, "IVirtualBox_getVersion", err)
 	}
 	return resp.Returnval, nil
 }
 
+func (a *Adapter) GetGuestOSTypes(ctx context.Context, session string) ([]vboxapi.GuestOSType, error) {
+	resp, err := a.svc.IVirtualBox_getGuestOSTypesContext(ctx, &generated.IVirtualBox_getGuestOSTypes{This: session})
+	if err != nil {
+		return nil, a.wrap(ctx, "IVirtualBox_getGuestOSTypes", err)
+	}
+	types := make([]vboxapi.GuestOSType, 0, len(resp.Returnval))
+	for _, t := range resp.Returnval {
+		if t == nil {
+			continue
+		}
+		types = append(types, vboxapi.GuestOSType{
+			ID:          t.Id,
+			Description: t.Description,
+			FamilyID:    t.FamilyId,
+			Is64Bit:     t.Is64Bit,
+		})
+	}
+	return types, nil
+}
+
 func (a *Adapter) GetMachines(ctx context.Context, session string) ([]string, error) {
 	resp, err := a.svc.IVirtualBox_getMachinesContext(ctx, &generated.IVirtualBox_getMachines{This: session})
 	if err != nil {
-		return nil, err
+		return nil, a.wrap(ctx, "IVirtualBox_getMachines", err)
 	}
 	return resp.Returnval, nil
 }
 
+// GetMaxNetworkAdapters returns the number of network adapter slots
+// available to the machine, which depends on its chipset.
+func (a *Adapter) GetMaxNetworkAdapters(ctx context.Context, machineRef string) (uint32, error) {
+	platformResp, err := a.svc.IMachine_getPlatformContext(ctx, &generated.IMachine_getPlatform{This: machineRef})
+	if err != nil {
+		return 0, a.wrap(ctx, "IMachine_getPlatform", err)
+	}
+
+	chipsetResp, err := a.svc.IPlatform_getChipsetTypeContext(ctx, &generated.IPlatform_getChipsetType{This: platformResp.Returnval})
+	if err != nil {
+		return 0, a.wrap(ctx, "IPlatform_getChipsetType", err)
+	}
+
+	propsResp, err := a.svc.IPlatform_getPropertiesContext(ctx, &generated.IPlatform_getProperties{This: platformResp.Returnval})
+	if err != nil {
+		return 0, a.wrap(ctx, "IPlatform_getProperties", err)
+	}
+
+	maxResp, err := a.svc.IPlatformProperties_getMaxNetworkAdaptersContext(ctx, &generated.IPlatformProperties_getMaxNetworkAdapters{
+		This:    propsResp.Returnval,
+		Chipset: chipsetResp.Returnval,
+	})
+	if err != nil {
+		return 0, a.wrap(ctx, "IPlatformProperties_getMaxNetworkAdapters", err)
+	}
+	return maxResp.Returnval, nil
+}
+
 func (a *Adapter) GetNetworkAdapter(ctx context.Context, machineRef string, slot uint32) (string, error) {
 	resp, err := a.svc.IMachine_getNetworkAdapterContext(ctx, &generated.IMachine_getNetworkAdapter{
 		This: machineRef,
 		Slot: slot,
 	})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "IMachine_getNetworkAdapter", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetAdapterEnabled(ctx context.Context, adapterRef string) (bool, error) {
+	resp, err := a.svc.INetworkAdapter_getEnabledContext(ctx, &generated.INetworkAdapter_getEnabled{
+		This: adapterRef,
+	})
+	if err != nil {
+		return false, a.wrap(ctx, "INetworkAdapter_getEnabled", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetAdapterAttachmentType(ctx context.Context, adapterRef string) (vboxapi.NetworkAttachmentType, error) {
+	resp, err := a.svc.INetworkAdapter_getAttachmentTypeContext(ctx, &generated.INetworkAdapter_getAttachmentType{
+		This: adapterRef,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "INetworkAdapter_getAttachmentType", err)
+	}
+	if resp.Returnval == nil {
+		return vboxapi.NetworkAttachmentTypeNull, nil
+	}
+	return vboxapi.NetworkAttachmentType(*resp.Returnval), nil
+}
+
+func (a *Adapter) SetAdapterEnabled(ctx context.Context, adapterRef string, enabled bool) error {
+	_, err := a.svc.INetworkAdapter_setEnabledContext(ctx, &generated.INetworkAdapter_setEnabled{
+		This:    adapterRef,
+		Enabled: enabled,
+	})
+	return a.wrap(ctx, "INetworkAdapter_setEnabled", err)
+}
+
+func (a *Adapter) SetAdapterAttachmentType(ctx context.Context, adapterRef string, attachment vboxapi.NetworkAttachmentType) error {
+	t := generated.NetworkAttachmentType(attachment)
+	_, err := a.svc.INetworkAdapter_setAttachmentTypeContext(ctx, &generated.INetworkAdapter_setAttachmentType{
+		This:           adapterRef,
+		AttachmentType: &t,
+	})
+	return a.wrap(ctx, "INetworkAdapter_setAttachmentType", err)
+}
+
+func (a *Adapter) SetAdapterCableConnected(ctx context.Context, adapterRef string, connected bool) error {
+	_, err := a.svc.INetworkAdapter_setCableConnectedContext(ctx, &generated.INetworkAdapter_setCableConnected{
+		This:           adapterRef,
+		CableConnected: connected,
+	})
+	return a.wrap(ctx, "INetworkAdapter_setCableConnected", err)
+}
+
+func (a *Adapter) GetAdapterBridgedInterface(ctx context.Context, adapterRef string) (string, error) {
+	resp, err := a.svc.INetworkAdapter_getBridgedInterfaceContext(ctx, &generated.INetworkAdapter_getBridgedInterface{
+		This: adapterRef,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "INetworkAdapter_getBridgedInterface", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetAdapterBridgedInterface(ctx context.Context, adapterRef, name string) error {
+	_, err := a.svc.INetworkAdapter_setBridgedInterfaceContext(ctx, &generated.INetworkAdapter_setBridgedInterface{
+		This:             adapterRef,
+		BridgedInterface: name,
+	})
+	return a.wrap(ctx, "INetworkAdapter_setBridgedInterface", err)
+}
+
+func (a *Adapter) GetAdapterHostOnlyInterface(ctx context.Context, adapterRef string) (string, error) {
+	resp, err := a.svc.INetworkAdapter_getHostOnlyInterfaceContext(ctx, &generated.INetworkAdapter_getHostOnlyInterface{
+		This: adapterRef,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "INetworkAdapter_getHostOnlyInterface", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetAdapterHostOnlyInterface(ctx context.Context, adapterRef, name string) error {
+	_, err := a.svc.INetworkAdapter_setHostOnlyInterfaceContext(ctx, &generated.INetworkAdapter_setHostOnlyInterface{
+		This:              adapterRef,
+		HostOnlyInterface: name,
+	})
+	return a.wrap(ctx, "INetworkAdapter_setHostOnlyInterface", err)
+}
+
+func (a *Adapter) SetAdapterInternalNetwork(ctx context.Context, adapterRef, name string) error {
+	_, err := a.svc.INetworkAdapter_setInternalNetworkContext(ctx, &generated.INetworkAdapter_setInternalNetwork{
+		This:            adapterRef,
+		InternalNetwork: name,
+	})
+	return a.wrap(ctx, "INetworkAdapter_setInternalNetwork", err)
+}
+
+func (a *Adapter) GetAdapterNATNetwork(ctx context.Context, adapterRef string) (string, error) {
+	resp, err := a.svc.INetworkAdapter_getNATNetworkContext(ctx, &generated.INetworkAdapter_getNATNetwork{
+		This: adapterRef,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "INetworkAdapter_getNATNetwork", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetAdapterNATNetwork(ctx context.Context, adapterRef, name string) error {
+	_, err := a.svc.INetworkAdapter_setNATNetworkContext(ctx, &generated.INetworkAdapter_setNATNetwork{
+		This:       adapterRef,
+		NATNetwork: name,
+	})
+	return a.wrap(ctx, "INetworkAdapter_setNATNetwork", err)
+}
+
+func (a *Adapter) GetAdapterMACAddress(ctx context.Context, adapterRef string) (string, error) {
+	resp, err := a.svc.INetworkAdapter_getMACAddressContext(ctx, &generated.INetworkAdapter_getMACAddress{
+		This: adapterRef,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "INetworkAdapter_getMACAddress", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetAdapterMACAddress(ctx context.Context, adapterRef, mac string) error {
+	_, err := a.svc.INetworkAdapter_setMACAddressContext(ctx, &generated.INetworkAdapter_setMACAddress{
+		This:       adapterRef,
+		MACAddress: mac,
+	})
+	return a.wrap(ctx, "INetworkAdapter_setMACAddress", err)
+}
+
+func (a *Adapter) GetAdapterCableConnected(ctx context.Context, adapterRef string) (bool, error) {
+	resp, err := a.svc.INetworkAdapter_getCableConnectedContext(ctx, &generated.INetworkAdapter_getCableConnected{
+		This: adapterRef,
+	})
+	if err != nil {
+		return false, a.wrap(ctx, "INetworkAdapter_getCableConnected", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetAdapterLineSpeed(ctx context.Context, adapterRef string) (uint32, error) {
+	resp, err := a.svc.INetworkAdapter_getLineSpeedContext(ctx, &generated.INetworkAdapter_getLineSpeed{
+		This: adapterRef,
+	})
+	if err != nil {
+		return 0, a.wrap(ctx, "INetworkAdapter_getLineSpeed", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetAdapterType(ctx context.Context, adapterRef string) (vboxapi.NetworkAdapterType, error) {
+	resp, err := a.svc.INetworkAdapter_getAdapterTypeContext(ctx, &generated.INetworkAdapter_getAdapterType{
+		This: adapterRef,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "INetworkAdapter_getAdapterType", err)
+	}
+	if resp.Returnval == nil {
+		return "", nil
+	}
+	return vboxapi.NetworkAdapterType(*resp.Returnval), nil
+}
+
+func (a *Adapter) SetAdapterType(ctx context.Context, adapterRef string, adapterType vboxapi.NetworkAdapterType) error {
+	t := generated.NetworkAdapterType(adapterType)
+	_, err := a.svc.INetworkAdapter_setAdapterTypeContext(ctx, &generated.INetworkAdapter_setAdapterType{
+		This:        adapterRef,
+		AdapterType: &t,
+	})
+	return a.wrap(ctx, "INetworkAdapter_setAdapterType", err)
+}
+
+func (a *Adapter) SetAdapterLineSpeed(ctx context.Context, adapterRef string, kbps uint32) error {
+	_, err := a.svc.INetworkAdapter_setLineSpeedContext(ctx, &generated.INetworkAdapter_setLineSpeed{
+		This:      adapterRef,
+		LineSpeed: kbps,
+	})
+	return a.wrap(ctx, "INetworkAdapter_setLineSpeed", err)
+}
+
+func (a *Adapter) GetAdapterInternalNetwork(ctx context.Context, adapterRef string) (string, error) {
+	resp, err := a.svc.INetworkAdapter_getInternalNetworkContext(ctx, &generated.INetworkAdapter_getInternalNetwork{
+		This: adapterRef,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "INetworkAdapter_getInternalNetwork", err)
 	}
 	return resp.Returnval, nil
 }
@@ -294,7 +843,7 @@ func (a *Adapter) GetNATEngine(ctx context.Context, adapterRef string) (string,
 		This: adapterRef,
 	})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "INetworkAdapter_getNATEngine", err)
 	}
 	return resp.Returnval, nil
 }
@@ -304,7 +853,7 @@ func (a *Adapter) GetNATRedirects(ctx context.Context, natEngineRef string) ([]v
 		This: natEngineRef,
 	})
 	if err != nil {
-		return nil, err
+		return nil, a.wrap(ctx, "INATEngine_getRedirects", err)
 	}
 
 	// VBox 7.1 format: "name,proto,hostIP,hostPort,guestIP,guestPort"
@@ -383,7 +932,7 @@ func (a *Adapter) AddNATRedirect(ctx context.Context, natEngineRef, name string,
 		GuestIP:   guestIP,
 		GuestPort: guestPort,
 	})
-	return err
+	return a.wrap(ctx, "INATEngine_addRedirect", err)
 }
 
 func (a *Adapter) RemoveNATRedirect(ctx context.Context, natEngineRef, name string) error {
@@ -391,13 +940,13 @@ func (a *Adapter) RemoveNATRedirect(ctx context.Context, natEngineRef, name stri
 		This: natEngineRef,
 		Name: name,
 	})
-	return err
+	return a.wrap(ctx, "INATEngine_removeRedirect", err)
 }
 
 func (a *Adapter) GetNATNetworks(ctx context.Context, session string) ([]string, error) {
 	resp, err := a.svc.IVirtualBox_getNATNetworksContext(ctx, &generated.IVirtualBox_getNATNetworks{This: session})
 	if err != nil {
-		return nil, err
+		return nil, a.wrap(ctx, "IVirtualBox_getNATNetworks", err)
 	}
 	return resp.Returnval, nil
 }
@@ -405,7 +954,7 @@ func (a *Adapter) GetNATNetworks(ctx context.Context, session string) ([]string,
 func (a *Adapter) GetNATNetworkPortForwardRules4(ctx context.Context, natNetworkRef string) ([]vboxapi.NATRedirect, error) {
 	resp, err := a.svc.INATNetwork_getPortForwardRules4Context(ctx, &generated.INATNetwork_getPortForwardRules4{This: natNetworkRef})
 	if err != nil {
-		return nil, err
+		return nil, a.wrap(ctx, "INATNetwork_getPortForwardRules4", err)
 	}
 
 	// VBox 7.1 NAT Network format: "name:proto:hostIP:hostPort:guestIP:guestPort"
@@ -421,6 +970,31 @@ func (a *Adapter) GetNATNetworkPortForwardRules4(ctx context.Context, natNetwork
 	return redirects, nil
 }
 
+func (a *Adapter) AddNATNetworkPortForwardRule4(ctx context.Context, natNetworkRef string, rule vboxapi.NATRedirect) error {
+	p := generated.NATProtocolUDP
+	if rule.Protocol == vboxapi.NATProtocolTCP {
+		p = generated.NATProtocolTCP
+	}
+	_, err := a.svc.INATNetwork_addPortForwardRuleContext(ctx, &generated.INATNetwork_addPortForwardRule{
+		This:      natNetworkRef,
+		RuleName:  rule.Name,
+		Proto:     &p,
+		HostIP:    rule.HostIP,
+		HostPort:  rule.HostPort,
+		GuestIP:   rule.GuestIP,
+		GuestPort: rule.GuestPort,
+	})
+	return a.wrap(ctx, "INATNetwork_addPortForwardRule", err)
+}
+
+func (a *Adapter) RemoveNATNetworkPortForwardRule4(ctx context.Context, natNetworkRef, name string) error {
+	_, err := a.svc.INATNetwork_removePortForwardRuleContext(ctx, &generated.INATNetwork_removePortForwardRule{
+		This:     natNetworkRef,
+		RuleName: name,
+	})
+	return a.wrap(ctx, "INATNetwork_removePortForwardRule", err)
+}
+
 // parseNATNetworkRule71 parses VBox 7.1 NAT Network port forward format.
 // Format: "name:proto:hostIP:hostPort:guestIP:guestPort"
 // proto: "tcp" or "udp"
@@ -463,14 +1037,14 @@ func parseNATNetworkRule71(raw string) (vboxapi.NATRedirect, error) {
 func (a *Adapter) GetMutableMachine(ctx context.Context, sessionObj string) (string, error) {
 	resp, err := a.svc.ISession_getMachineContext(ctx, &generated.ISession_getMachine{This: sessionObj})
 	if err != nil {
-		return "", err
+		return "", a.wrap(ctx, "ISession_getMachine", err)
 	}
 	return resp.Returnval, nil
 }
 
 func (a *Adapter) SaveSettings(ctx context.Context, machineRef string) error {
 	_, err := a.svc.IMachine_saveSettingsContext(ctx, &generated.IMachine_saveSettings{This: machineRef})
-	return err
+	return a.wrap(ctx, "IMachine_saveSettings", err)
 }
 
 // Compile-time check that Adapter implements vboxapi.VBoxAPI
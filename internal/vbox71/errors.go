@@ -0,0 +1,129 @@
+package vbox71
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox71/generated"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+	"github.com/hooklift/gowsdl/soap"
+)
+
+// faultEnvelope is the subset of a vboxwebsrv SOAP fault response that we
+// care about. vboxwebsrv answers faults with HTTP 500, so gowsdl surfaces
+// them as *soap.HTTPError carrying the raw envelope.
+type faultEnvelope struct {
+	Body struct {
+		Fault *struct {
+			String string `xml:"faultstring"`
+			Detail struct {
+				RuntimeFault *struct {
+					ResultCode int32  `xml:"resultCode"`
+					Returnval  string `xml:"returnval"`
+				} `xml:"RuntimeFault"`
+				InvalidObjectFault *struct {
+					BadObjectID string `xml:"badObjectID"`
+				} `xml:"InvalidObjectFault"`
+			} `xml:"detail"`
+		} `xml:"Fault"`
+	} `xml:"Body"`
+}
+
+// parseFault converts a SOAP fault into a *vboxapi.Error. It also returns
+// the managed object reference of the IVirtualBoxErrorInfo attached to the
+// fault, if any. ok is false when err is not a SOAP fault.
+func parseFault(op string, err error) (vErr *vboxapi.Error, errorInfoRef string, ok bool) {
+	vErr = &vboxapi.Error{Operation: op}
+	if i := strings.Index(op, "_"); i > 0 {
+		vErr.Interface = op[:i]
+	}
+
+	var httpErr *soap.HTTPError
+	var soapFault *soap.SOAPFault
+	switch {
+	case errors.As(err, &httpErr):
+		var env faultEnvelope
+		if xml.Unmarshal(httpErr.ResponseBody, &env) != nil || env.Body.Fault == nil {
+			return nil, "", false
+		}
+		f := env.Body.Fault
+		vErr.Text = faultText(f.String)
+		if rf := f.Detail.RuntimeFault; rf != nil {
+			vErr.ResultCode = rf.ResultCode
+			errorInfoRef = rf.Returnval
+		} else if code, ok := faultResultCode(f.String); ok {
+			vErr.ResultCode = code
+		}
+		if iof := f.Detail.InvalidObjectFault; iof != nil {
+			vErr.BadObjectID = iof.BadObjectID
+			if vErr.Text == "" {
+				vErr.Text = "invalid managed object reference " + iof.BadObjectID
+			}
+		}
+	case errors.As(err, &soapFault):
+		// gowsdl does not decode the fault detail, so the result code is
+		// only available from the fault string.
+		vErr.Text = faultText(soapFault.String)
+		vErr.ResultCode, _ = faultResultCode(soapFault.String)
+	default:
+		return nil, "", false
+	}
+	return vErr, errorInfoRef, true
+}
+
+// faultResultCode returns the result code vboxwebsrv appends to fault
+// strings, e.g. 0x80bb0001 in "VirtualBox error: ... (0x80bb0001)". Unlike
+// the message, which depends on the locale of vboxwebsrv, the code can be
+// relied on.
+func faultResultCode(s string) (int32, bool) {
+	s = strings.TrimSpace(s)
+	i := strings.LastIndex(s, "(0x")
+	if i < 0 || !strings.HasSuffix(s, ")") {
+		return 0, false
+	}
+	code, err := strconv.ParseUint(s[i+3:len(s)-1], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(uint32(code)), true
+}
+
+// faultText strips the decoration vboxwebsrv adds around error messages,
+// e.g. "VirtualBox error: Could not find ... (0x80bb0001)".
+func faultText(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "VirtualBox error: ")
+	if i := strings.LastIndex(s, " (0x"); i > 0 && strings.HasSuffix(s, ")") {
+		s = s[:i]
+	}
+	return s
+}
+
+// wrap converts SOAP faults returned by op into *vboxapi.Error, enriching
+// them with the reporting component when VirtualBox attached error info.
+// Faults caused by an unavailable VBoxSVC are returned as
+// *vboxapi.ServiceUnavailableError. Other errors (transport failures, nil)
+// are returned unchanged.
+func (a *Adapter) wrap(ctx context.Context, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	vErr, errorInfoRef, ok := parseFault(op, err)
+	if !ok {
+		return err
+	}
+	if vErr.ServiceUnavailable() {
+		// The error info object lives in VBoxSVC, so don't query it.
+		return &vboxapi.ServiceUnavailableError{Err: vErr}
+	}
+	if errorInfoRef != "" {
+		// Best-effort: the fault is still useful without the component.
+		if resp, cErr := a.svc.IVirtualBoxErrorInfo_getComponentContext(ctx, &generated.IVirtualBoxErrorInfo_getComponent{This: errorInfoRef}); cErr == nil {
+			vErr.Component = resp.Returnval
+		}
+	}
+	return vErr
+}
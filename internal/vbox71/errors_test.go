@@ -0,0 +1,202 @@
+package vbox71
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox71/generated"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+	"github.com/hooklift/gowsdl/soap"
+)
+
+const runtimeFaultBody = `<?xml version="1.0" encoding="UTF-8"?>
+<SOAP-ENV:Envelope xmlns:SOAP-ENV="http://schemas.xmlsoap.org/soap/envelope/" xmlns:vbox="http://www.virtualbox.org/">
+<SOAP-ENV:Body><SOAP-ENV:Fault>
+<faultcode>SOAP-ENV:Client</faultcode>
+<faultstring>VirtualBox error: Could not find a registered machine named 'missing' (0x80bb0001)</faultstring>
+<detail><vbox:RuntimeFault><resultCode>-2135228415</resultCode><returnval>errinfo-1</returnval></vbox:RuntimeFault></detail>
+</SOAP-ENV:Fault></SOAP-ENV:Body></SOAP-ENV:Envelope>`
+
+func TestParseFault_RuntimeFault(t *testing.T) {
+	err := &soap.HTTPError{StatusCode: 500, ResponseBody: []byte(runtimeFaultBody)}
+
+	vErr, errorInfoRef, ok := parseFault("IVirtualBox_findMachine", err)
+	if !ok {
+		t.Fatal("expected fault to be parsed")
+	}
+	if vErr.Interface != "IVirtualBox" {
+		t.Errorf("Interface = %q, want %q", vErr.Interface, "IVirtualBox")
+	}
+	if vErr.Operation != "IVirtualBox_findMachine" {
+		t.Errorf("Operation = %q, want %q", vErr.Operation, "IVirtualBox_findMachine")
+	}
+	if vErr.ResultCode != vboxapi.ResultObjectNotFound {
+		t.Errorf("ResultCode = %s, want %s", vboxapi.ResultCodeString(vErr.ResultCode), vboxapi.ResultCodeString(vboxapi.ResultObjectNotFound))
+	}
+	if vErr.Text != "Could not find a registered machine named 'missing'" {
+		t.Errorf("Text = %q", vErr.Text)
+	}
+	if errorInfoRef != "errinfo-1" {
+		t.Errorf("errorInfoRef = %q, want %q", errorInfoRef, "errinfo-1")
+	}
+}
+
+func TestParseFault_InvalidObjectFault(t *testing.T) {
+	body := `<Envelope><Body><Fault><faultstring></faultstring><detail><InvalidObjectFault><badObjectID>abc</badObjectID></InvalidObjectFault></detail></Fault></Body></Envelope>`
+	err := &soap.HTTPError{StatusCode: 500, ResponseBody: []byte(body)}
+
+	vErr, _, ok := parseFault("IMachine_getName", err)
+	if !ok {
+		t.Fatal("expected fault to be parsed")
+	}
+	if vErr.Text != "invalid managed object reference abc" {
+		t.Errorf("Text = %q", vErr.Text)
+	}
+	if !vboxapi.IsInvalidObject(vErr, "abc") {
+		t.Error("expected the fault to reject abc")
+	}
+}
+
+func TestParseFault_NotAFault(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"transport error", errors.New("connection refused")},
+		{"http error without envelope", &soap.HTTPError{StatusCode: 503, ResponseBody: []byte("Service Unavailable")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, ok := parseFault("IMachine_getName", tt.err); ok {
+				t.Error("expected ok = false")
+			}
+		})
+	}
+}
+
+func TestParseFault_ResultCodeFromFaultString(t *testing.T) {
+	// A German vboxwebsrv, and a fault without RuntimeFault detail.
+	body := `<Envelope><Body><Fault><faultstring>VirtualBox error: Konnte keine registrierte Maschine namens 'missing' finden (0x80bb0001)</faultstring></Fault></Body></Envelope>`
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"http error", &soap.HTTPError{StatusCode: 500, ResponseBody: []byte(body)}},
+		{"soap fault", &soap.SOAPFault{String: "VirtualBox error: Konnte keine registrierte Maschine namens 'missing' finden (0x80bb0001)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vErr, _, ok := parseFault("IVirtualBox_findMachine", tt.err)
+			if !ok {
+				t.Fatal("expected fault to be parsed")
+			}
+			if !vboxapi.IsObjectNotFound(vErr) {
+				t.Errorf("ResultCode = %s, want %s", vboxapi.ResultCodeString(vErr.ResultCode), vboxapi.ResultCodeString(vboxapi.ResultObjectNotFound))
+			}
+		})
+	}
+}
+
+func TestFaultResultCode(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   int32
+		wantOK bool
+	}{
+		{"VirtualBox error: Machine is locked (0x80bb0007)", vboxapi.ResultInvalidObjectState, true},
+		{"VirtualBox error: Could not find a registered machine (0x80BB0001)", vboxapi.ResultObjectNotFound, true},
+		{"VirtualBox error: no code", 0, false},
+		{"VirtualBox error: bad code (0xzz)", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := faultResultCode(tt.input)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("faultResultCode(%q) = %s, %t; want %s, %t", tt.input, vboxapi.ResultCodeString(got), ok, vboxapi.ResultCodeString(tt.want), tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFaultText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"VirtualBox error: Machine is locked (0x80bb0007)", "Machine is locked"},
+		{"plain message", "plain message"},
+		{"  VirtualBox error: no code  ", "no code"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := faultText(tt.input); got != tt.want {
+				t.Errorf("faultText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// deadSvc simulates a vboxwebsrv whose VBoxSVC backend is gone: any call
+// not overridden by a test panics via the nil embedded interface.
+type deadSvc struct {
+	generated.VboxPortType
+	componentCalls int
+}
+
+func (s *deadSvc) IVirtualBoxErrorInfo_getComponentContext(_ context.Context, _ *generated.IVirtualBoxErrorInfo_getComponent) (*generated.IVirtualBoxErrorInfo_getComponentResponse, error) {
+	s.componentCalls++
+	return nil, errors.New("unexpected call")
+}
+
+func TestWrap_ServiceUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{
+			name: "RPC server unavailable result code",
+			body: `<Envelope><Body><Fault><faultstring>VirtualBox error: The RPC server is unavailable. (0x800706ba)</faultstring><detail><RuntimeFault><resultCode>-2147023174</resultCode><returnval>errinfo-1</returnval></RuntimeFault></detail></Fault></Body></Envelope>`,
+		},
+		{
+			name: "VBoxSVC not available message",
+			body: `<Envelope><Body><Fault><faultstring>VirtualBox error: The VirtualBox service (VBoxSVC) is not available</faultstring><detail><RuntimeFault><resultCode>-2147467259</resultCode></RuntimeFault></detail></Fault></Body></Envelope>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &deadSvc{}
+			a := &Adapter{svc: svc}
+
+			err := a.wrap(context.Background(), "IMachine_getState", &soap.HTTPError{StatusCode: 500, ResponseBody: []byte(tt.body)})
+			if !vboxapi.IsServiceUnavailable(err) {
+				t.Fatalf("expected ServiceUnavailableError, got %T: %v", err, err)
+			}
+			if !strings.Contains(err.Error(), "VBoxSVC") {
+				t.Errorf("error should mention VBoxSVC: %v", err)
+			}
+			var vErr *vboxapi.Error
+			if !errors.As(err, &vErr) || vErr.Operation != "IMachine_getState" {
+				t.Errorf("expected wrapped *vboxapi.Error for IMachine_getState, got %v", vErr)
+			}
+			if svc.componentCalls != 0 {
+				t.Errorf("error info component queried %d times, want 0", svc.componentCalls)
+			}
+		})
+	}
+}
+
+func TestWrap_OtherFaultIsNotServiceUnavailable(t *testing.T) {
+	a := &Adapter{svc: &deadSvc{}}
+
+	err := a.wrap(context.Background(), "IVirtualBox_findMachine", &soap.HTTPError{StatusCode: 500, ResponseBody: []byte(runtimeFaultBody)})
+	if vboxapi.IsServiceUnavailable(err) {
+		t.Fatalf("did not expect ServiceUnavailableError: %v", err)
+	}
+	var vErr *vboxapi.Error
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *vboxapi.Error, got %T", err)
+	}
+}
@@ -0,0 +1,123 @@
+package vbox71
+
+import (
+	"context"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox71/generated"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func (a *Adapter) getHost(ctx context.Context, session string) (string, error) {
+	resp, err := a.svc.IVirtualBox_getHostContext(ctx, &generated.IVirtualBox_getHost{This: session})
+	if err != nil {
+		return "", a.wrap(ctx, "IVirtualBox_getHost", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetHostOperatingSystem(ctx context.Context, session string) (string, error) {
+	hostRef, err := a.getHost(ctx, session)
+	if err != nil {
+		return "", err
+	}
+	resp, err := a.svc.IHost_getOperatingSystemContext(ctx, &generated.IHost_getOperatingSystem{This: hostRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IHost_getOperatingSystem", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetHostOSVersion(ctx context.Context, session string) (string, error) {
+	hostRef, err := a.getHost(ctx, session)
+	if err != nil {
+		return "", err
+	}
+	resp, err := a.svc.IHost_getOSVersionContext(ctx, &generated.IHost_getOSVersion{This: hostRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IHost_getOSVersion", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetHostProcessorCount(ctx context.Context, session string) (uint32, error) {
+	hostRef, err := a.getHost(ctx, session)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := a.svc.IHost_getProcessorCountContext(ctx, &generated.IHost_getProcessorCount{This: hostRef})
+	if err != nil {
+		return 0, a.wrap(ctx, "IHost_getProcessorCount", err)
+	}
+	return resp.Returnval, nil
+}
+
+// GetHostMemorySize returns the physical memory of the host, in MB.
+func (a *Adapter) GetHostMemorySize(ctx context.Context, session string) (uint32, error) {
+	hostRef, err := a.getHost(ctx, session)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := a.svc.IHost_getMemorySizeContext(ctx, &generated.IHost_getMemorySize{This: hostRef})
+	if err != nil {
+		return 0, a.wrap(ctx, "IHost_getMemorySize", err)
+	}
+	return resp.Returnval, nil
+}
+
+// GetBridgedInterfaces returns the host network interfaces that network
+// adapters can be bridged to.
+func (a *Adapter) GetBridgedInterfaces(ctx context.Context, session string) ([]string, error) {
+	hostRef, err := a.getHost(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	t := generated.HostNetworkInterfaceTypeBridged
+	resp, err := a.svc.IHost_findHostNetworkInterfacesOfTypeContext(ctx, &generated.IHost_findHostNetworkInterfacesOfType{
+		This:  hostRef,
+		Type_: &t,
+	})
+	if err != nil {
+		return nil, a.wrap(ctx, "IHost_findHostNetworkInterfacesOfType", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetHostNetworkInterface(ctx context.Context, interfaceRef string) (vboxapi.HostNetworkInterface, error) {
+	var iface vboxapi.HostNetworkInterface
+
+	name, err := a.svc.IHostNetworkInterface_getNameContext(ctx, &generated.IHostNetworkInterface_getName{This: interfaceRef})
+	if err != nil {
+		return iface, a.wrap(ctx, "IHostNetworkInterface_getName", err)
+	}
+	iface.Name = name.Returnval
+
+	status, err := a.svc.IHostNetworkInterface_getStatusContext(ctx, &generated.IHostNetworkInterface_getStatus{This: interfaceRef})
+	if err != nil {
+		return iface, a.wrap(ctx, "IHostNetworkInterface_getStatus", err)
+	}
+	iface.Status = vboxapi.HostNetworkInterfaceStatusUnknown
+	if status.Returnval != nil {
+		iface.Status = vboxapi.HostNetworkInterfaceStatus(*status.Returnval)
+	}
+
+	ip, err := a.svc.IHostNetworkInterface_getIPAddressContext(ctx, &generated.IHostNetworkInterface_getIPAddress{This: interfaceRef})
+	if err != nil {
+		return iface, a.wrap(ctx, "IHostNetworkInterface_getIPAddress", err)
+	}
+	iface.IPAddress = ip.Returnval
+
+	mac, err := a.svc.IHostNetworkInterface_getHardwareAddressContext(ctx, &generated.IHostNetworkInterface_getHardwareAddress{This: interfaceRef})
+	if err != nil {
+		return iface, a.wrap(ctx, "IHostNetworkInterface_getHardwareAddress", err)
+	}
+	iface.HardwareAddress = mac.Returnval
+
+	wireless, err := a.svc.IHostNetworkInterface_getWirelessContext(ctx, &generated.IHostNetworkInterface_getWireless{This: interfaceRef})
+	if err != nil {
+		return iface, a.wrap(ctx, "IHostNetworkInterface_getWireless", err)
+	}
+	iface.Wireless = wireless.Returnval
+
+	return iface, nil
+}
@@ -0,0 +1,87 @@
+package vbox71
+
+import (
+	"context"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox71/generated"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func (a *Adapter) FindNATNetworkByName(ctx context.Context, session, name string) (string, error) {
+	resp, err := a.svc.IVirtualBox_findNATNetworkByNameContext(ctx, &generated.IVirtualBox_findNATNetworkByName{
+		This:        session,
+		NetworkName: name,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IVirtualBox_findNATNetworkByName", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) CreateNATNetwork(ctx context.Context, session, name string) (string, error) {
+	resp, err := a.svc.IVirtualBox_createNATNetworkContext(ctx, &generated.IVirtualBox_createNATNetwork{
+		This:        session,
+		NetworkName: name,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IVirtualBox_createNATNetwork", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) RemoveNATNetwork(ctx context.Context, session, natNetworkRef string) error {
+	_, err := a.svc.IVirtualBox_removeNATNetworkContext(ctx, &generated.IVirtualBox_removeNATNetwork{
+		This:    session,
+		Network: natNetworkRef,
+	})
+	return a.wrap(ctx, "IVirtualBox_removeNATNetwork", err)
+}
+
+func (a *Adapter) GetNATNetwork(ctx context.Context, natNetworkRef string) (vboxapi.NATNetwork, error) {
+	var network vboxapi.NATNetwork
+
+	name, err := a.svc.INATNetwork_getNetworkNameContext(ctx, &generated.INATNetwork_getNetworkName{This: natNetworkRef})
+	if err != nil {
+		return network, a.wrap(ctx, "INATNetwork_getNetworkName", err)
+	}
+	network.Name = name.Returnval
+
+	cidr, err := a.svc.INATNetwork_getNetworkContext(ctx, &generated.INATNetwork_getNetwork{This: natNetworkRef})
+	if err != nil {
+		return network, a.wrap(ctx, "INATNetwork_getNetwork", err)
+	}
+	network.Network = cidr.Returnval
+
+	enabled, err := a.svc.INATNetwork_getEnabledContext(ctx, &generated.INATNetwork_getEnabled{This: natNetworkRef})
+	if err != nil {
+		return network, a.wrap(ctx, "INATNetwork_getEnabled", err)
+	}
+	network.Enabled = enabled.Returnval
+
+	dhcp, err := a.svc.INATNetwork_getNeedDhcpServerContext(ctx, &generated.INATNetwork_getNeedDhcpServer{This: natNetworkRef})
+	if err != nil {
+		return network, a.wrap(ctx, "INATNetwork_getNeedDhcpServer", err)
+	}
+	network.DHCPEnabled = dhcp.Returnval
+	return network, nil
+}
+
+func (a *Adapter) SetNATNetwork(ctx context.Context, natNetworkRef string, network vboxapi.NATNetwork) error {
+	if _, err := a.svc.INATNetwork_setNetworkContext(ctx, &generated.INATNetwork_setNetwork{
+		This:    natNetworkRef,
+		Network: network.Network,
+	}); err != nil {
+		return a.wrap(ctx, "INATNetwork_setNetwork", err)
+	}
+	if _, err := a.svc.INATNetwork_setEnabledContext(ctx, &generated.INATNetwork_setEnabled{
+		This:    natNetworkRef,
+		Enabled: network.Enabled,
+	}); err != nil {
+		return a.wrap(ctx, "INATNetwork_setEnabled", err)
+	}
+	_, err := a.svc.INATNetwork_setNeedDhcpServerContext(ctx, &generated.INATNetwork_setNeedDhcpServer{
+		This:           natNetworkRef,
+		NeedDhcpServer: network.DHCPEnabled,
+	})
+	return a.wrap(ctx, "INATNetwork_setNeedDhcpServer", err)
+}
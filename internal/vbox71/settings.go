@@ -0,0 +1,848 @@
+package vbox71
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox71/generated"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func (a *Adapter) GetExecutionEngine(ctx context.Context, machineRef string) (vboxapi.ExecutionEngine, error) {
+	resp, err := a.svc.IMachine_getVMExecutionEngineContext(ctx, &generated.IMachine_getVMExecutionEngine{This: machineRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_getVMExecutionEngine", err)
+	}
+	if resp.Returnval == nil || *resp.Returnval == generated.VMExecutionEngineNotSet {
+		return vboxapi.ExecutionEngineDefault, nil
+	}
+	return vboxapi.ExecutionEngine(*resp.Returnval), nil
+}
+
+func (a *Adapter) SetExecutionEngine(ctx context.Context, mutableMachineRef string, engine vboxapi.ExecutionEngine) error {
+	e := generated.VMExecutionEngine(engine)
+	_, err := a.svc.IMachine_setVMExecutionEngineContext(ctx, &generated.IMachine_setVMExecutionEngine{
+		This:              mutableMachineRef,
+		VMExecutionEngine: &e,
+	})
+	return a.wrap(ctx, "IMachine_setVMExecutionEngine", err)
+}
+
+// IsExecutionEngineSupported asks the host whether it can run machineRef's
+// CPU architecture with engine.
+func (a *Adapter) IsExecutionEngineSupported(ctx context.Context, session, machineRef string, engine vboxapi.ExecutionEngine) (bool, error) {
+	arch, err := a.getPlatformArchitecture(ctx, machineRef)
+	if err != nil {
+		return false, err
+	}
+	cpuArch := generated.CPUArchitectureAMD64
+	if arch != nil && *arch == generated.PlatformArchitectureARM {
+		cpuArch = generated.CPUArchitectureARMv8_64
+	}
+
+	hostResp, err := a.svc.IVirtualBox_getHostContext(ctx, &generated.IVirtualBox_getHost{This: session})
+	if err != nil {
+		return false, a.wrap(ctx, "IVirtualBox_getHost", err)
+	}
+
+	e := generated.VMExecutionEngine(engine)
+	resp, err := a.svc.IHost_isExecutionEngineSupportedContext(ctx, &generated.IHost_isExecutionEngineSupported{
+		This:            hostResp.Returnval,
+		CpuArchitecture: &cpuArch,
+		ExecutionEngine: &e,
+	})
+	if err != nil {
+		return false, a.wrap(ctx, "IHost_isExecutionEngineSupported", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) getVRDEServer(ctx context.Context, machineRef string) (string, error) {
+	resp, err := a.svc.IMachine_getVRDEServerContext(ctx, &generated.IMachine_getVRDEServer{This: machineRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_getVRDEServer", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetVRDEProperty(ctx context.Context, machineRef, key string) (string, error) {
+	vrdeRef, err := a.getVRDEServer(ctx, machineRef)
+	if err != nil {
+		return "", err
+	}
+	resp, err := a.svc.IVRDEServer_getVRDEPropertyContext(ctx, &generated.IVRDEServer_getVRDEProperty{
+		This: vrdeRef,
+		Key:  key,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IVRDEServer_getVRDEProperty", err)
+	}
+	return resp.Returnval, nil
+}
+
+// SetVRDEProperty sets a VRDE server property. An empty value removes it.
+func (a *Adapter) SetVRDEProperty(ctx context.Context, mutableMachineRef, key, value string) error {
+	vrdeRef, err := a.getVRDEServer(ctx, mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	_, err = a.svc.IVRDEServer_setVRDEPropertyContext(ctx, &generated.IVRDEServer_setVRDEProperty{
+		This:  vrdeRef,
+		Key:   key,
+		Value: value,
+	})
+	return a.wrap(ctx, "IVRDEServer_setVRDEProperty", err)
+}
+
+func (a *Adapter) GetExtraData(ctx context.Context, machineRef, key string) (string, error) {
+	resp, err := a.svc.IMachine_getExtraDataContext(ctx, &generated.IMachine_getExtraData{
+		This: machineRef,
+		Key:  key,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_getExtraData", err)
+	}
+	return resp.Returnval, nil
+}
+
+// SetExtraData sets a machine extra data item. An empty value removes it.
+func (a *Adapter) SetExtraData(ctx context.Context, mutableMachineRef, key, value string) error {
+	_, err := a.svc.IMachine_setExtraDataContext(ctx, &generated.IMachine_setExtraData{
+		This:  mutableMachineRef,
+		Key:   key,
+		Value: value,
+	})
+	return a.wrap(ctx, "IMachine_setExtraData", err)
+}
+
+func (a *Adapter) GetGlobalExtraData(ctx context.Context, session, key string) (string, error) {
+	resp, err := a.svc.IVirtualBox_getExtraDataContext(ctx, &generated.IVirtualBox_getExtraData{
+		This: session,
+		Key:  key,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IVirtualBox_getExtraData", err)
+	}
+	return resp.Returnval, nil
+}
+
+// SetGlobalExtraData sets a global extra data item. An empty value removes
+// it.
+func (a *Adapter) SetGlobalExtraData(ctx context.Context, session, key, value string) error {
+	_, err := a.svc.IVirtualBox_setExtraDataContext(ctx, &generated.IVirtualBox_setExtraData{
+		This:  session,
+		Key:   key,
+		Value: value,
+	})
+	return a.wrap(ctx, "IVirtualBox_setExtraData", err)
+}
+
+func (a *Adapter) GetGuestProperty(ctx context.Context, machineRef, name string) (string, string, error) {
+	resp, err := a.svc.IMachine_getGuestPropertyContext(ctx, &generated.IMachine_getGuestProperty{
+		This: machineRef,
+		Name: name,
+	})
+	if err != nil {
+		return "", "", a.wrap(ctx, "IMachine_getGuestProperty", err)
+	}
+	return resp.Value, resp.Flags, nil
+}
+
+func (a *Adapter) SetGuestProperty(ctx context.Context, mutableMachineRef, name, value, flags string) error {
+	_, err := a.svc.IMachine_setGuestPropertyContext(ctx, &generated.IMachine_setGuestProperty{
+		This:     mutableMachineRef,
+		Property: name,
+		Value:    value,
+		Flags:    flags,
+	})
+	return a.wrap(ctx, "IMachine_setGuestProperty", err)
+}
+
+func (a *Adapter) EnumerateGuestProperties(ctx context.Context, machineRef, patterns string) ([]vboxapi.GuestProperty, error) {
+	resp, err := a.svc.IMachine_enumerateGuestPropertiesContext(ctx, &generated.IMachine_enumerateGuestProperties{
+		This:     machineRef,
+		Patterns: patterns,
+	})
+	if err != nil {
+		return nil, a.wrap(ctx, "IMachine_enumerateGuestProperties", err)
+	}
+	if len(resp.Values) != len(resp.Names) || len(resp.Flags) != len(resp.Names) {
+		return nil, fmt.Errorf("IMachine_enumerateGuestProperties returned %d names, %d values and %d flags", len(resp.Names), len(resp.Values), len(resp.Flags))
+	}
+
+	props := make([]vboxapi.GuestProperty, len(resp.Names))
+	for i, name := range resp.Names {
+		props[i] = vboxapi.GuestProperty{Name: name, Value: resp.Values[i], Flags: resp.Flags[i]}
+	}
+	return props, nil
+}
+
+// getPlatformX86 returns the x86-specific platform settings of a machine.
+func (a *Adapter) getPlatformX86(ctx context.Context, machineRef string) (string, error) {
+	platformResp, err := a.svc.IMachine_getPlatformContext(ctx, &generated.IMachine_getPlatform{This: machineRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_getPlatform", err)
+	}
+	x86Resp, err := a.svc.IPlatform_getX86Context(ctx, &generated.IPlatform_getX86{This: platformResp.Returnval})
+	if err != nil {
+		return "", a.wrap(ctx, "IPlatform_getX86", err)
+	}
+	return x86Resp.Returnval, nil
+}
+
+// GetCPUIDLeaves returns all CPUID leaf overrides of a machine. VirtualBox
+// has no count for them, so they are enumerated by ordinal until it reports
+// an invalid argument.
+func (a *Adapter) GetCPUIDLeaves(ctx context.Context, machineRef string) ([]vboxapi.CPUIDLeaf, error) {
+	x86Ref, err := a.getPlatformX86(ctx, machineRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var leaves []vboxapi.CPUIDLeaf
+	for ordinal := uint32(0); ; ordinal++ {
+		resp, err := a.svc.IPlatformX86_getCPUIDLeafByOrdinalContext(ctx, &generated.IPlatformX86_getCPUIDLeafByOrdinal{
+			This:    x86Ref,
+			Ordinal: ordinal,
+		})
+		if err != nil {
+			err = a.wrap(ctx, "IPlatformX86_getCPUIDLeafByOrdinal", err)
+			var vErr *vboxapi.Error
+			if errors.As(err, &vErr) && vErr.ResultCode == vboxapi.ResultInvalidArg {
+				return leaves, nil
+			}
+			return nil, err
+		}
+		leaves = append(leaves, vboxapi.CPUIDLeaf{
+			Leaf:    resp.Idx,
+			SubLeaf: resp.IdxSub,
+			EAX:     resp.ValEax,
+			EBX:     resp.ValEbx,
+			ECX:     resp.ValEcx,
+			EDX:     resp.ValEdx,
+		})
+	}
+}
+
+func (a *Adapter) GetHWVirtExProperty(ctx context.Context, machineRef string, property vboxapi.HWVirtExProperty) (bool, error) {
+	x86Ref, err := a.getPlatformX86(ctx, machineRef)
+	if err != nil {
+		return false, err
+	}
+	p := generated.HWVirtExPropertyType(property)
+	resp, err := a.svc.IPlatformX86_getHWVirtExPropertyContext(ctx, &generated.IPlatformX86_getHWVirtExProperty{
+		This:     x86Ref,
+		Property: &p,
+	})
+	if err != nil {
+		return false, a.wrap(ctx, "IPlatformX86_getHWVirtExProperty", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetCPUProperty(ctx context.Context, machineRef string, property vboxapi.CPUProperty) (bool, error) {
+	x86Ref, err := a.getPlatformX86(ctx, machineRef)
+	if err != nil {
+		return false, err
+	}
+	p := generated.CPUPropertyTypeX86(property)
+	resp, err := a.svc.IPlatformX86_getCPUPropertyContext(ctx, &generated.IPlatformX86_getCPUProperty{
+		This:     x86Ref,
+		Property: &p,
+	})
+	if err != nil {
+		return false, a.wrap(ctx, "IPlatformX86_getCPUProperty", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetCPUProperty(ctx context.Context, mutableMachineRef string, property vboxapi.CPUProperty, enabled bool) error {
+	x86Ref, err := a.getPlatformX86(ctx, mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	p := generated.CPUPropertyTypeX86(property)
+	_, err = a.svc.IPlatformX86_setCPUPropertyContext(ctx, &generated.IPlatformX86_setCPUProperty{
+		This:     x86Ref,
+		Property: &p,
+		Value:    enabled,
+	})
+	return a.wrap(ctx, "IPlatformX86_setCPUProperty", err)
+}
+
+func (a *Adapter) SetCPUIDLeaf(ctx context.Context, mutableMachineRef string, leaf vboxapi.CPUIDLeaf) error {
+	x86Ref, err := a.getPlatformX86(ctx, mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	_, err = a.svc.IPlatformX86_setCPUIDLeafContext(ctx, &generated.IPlatformX86_setCPUIDLeaf{
+		This:   x86Ref,
+		Idx:    leaf.Leaf,
+		IdxSub: leaf.SubLeaf,
+		ValEax: leaf.EAX,
+		ValEbx: leaf.EBX,
+		ValEcx: leaf.ECX,
+		ValEdx: leaf.EDX,
+	})
+	return a.wrap(ctx, "IPlatformX86_setCPUIDLeaf", err)
+}
+
+func (a *Adapter) RemoveCPUIDLeaf(ctx context.Context, mutableMachineRef string, leaf, subLeaf uint32) error {
+	x86Ref, err := a.getPlatformX86(ctx, mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	_, err = a.svc.IPlatformX86_removeCPUIDLeafContext(ctx, &generated.IPlatformX86_removeCPUIDLeaf{
+		This:   x86Ref,
+		Idx:    leaf,
+		IdxSub: subLeaf,
+	})
+	return a.wrap(ctx, "IPlatformX86_removeCPUIDLeaf", err)
+}
+
+func (a *Adapter) getGraphicsAdapter(ctx context.Context, machineRef string) (string, error) {
+	resp, err := a.svc.IMachine_getGraphicsAdapterContext(ctx, &generated.IMachine_getGraphicsAdapter{This: machineRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_getGraphicsAdapter", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetGraphicsController(ctx context.Context, machineRef string) (vboxapi.GraphicsController, error) {
+	gaRef, err := a.getGraphicsAdapter(ctx, machineRef)
+	if err != nil {
+		return "", err
+	}
+	resp, err := a.svc.IGraphicsAdapter_getGraphicsControllerTypeContext(ctx, &generated.IGraphicsAdapter_getGraphicsControllerType{This: gaRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IGraphicsAdapter_getGraphicsControllerType", err)
+	}
+	if resp.Returnval == nil {
+		return vboxapi.GraphicsControllerNull, nil
+	}
+	return vboxapi.GraphicsController(*resp.Returnval), nil
+}
+
+func (a *Adapter) SetGraphicsController(ctx context.Context, mutableMachineRef string, controller vboxapi.GraphicsController) error {
+	gaRef, err := a.getGraphicsAdapter(ctx, mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	t := generated.GraphicsControllerType(controller)
+	_, err = a.svc.IGraphicsAdapter_setGraphicsControllerTypeContext(ctx, &generated.IGraphicsAdapter_setGraphicsControllerType{
+		This:                   gaRef,
+		GraphicsControllerType: &t,
+	})
+	return a.wrap(ctx, "IGraphicsAdapter_setGraphicsControllerType", err)
+}
+
+func (a *Adapter) GetVRAMSize(ctx context.Context, machineRef string) (uint32, error) {
+	gaRef, err := a.getGraphicsAdapter(ctx, machineRef)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := a.svc.IGraphicsAdapter_getVRAMSizeContext(ctx, &generated.IGraphicsAdapter_getVRAMSize{This: gaRef})
+	if err != nil {
+		return 0, a.wrap(ctx, "IGraphicsAdapter_getVRAMSize", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetMemorySize(ctx context.Context, machineRef string) (uint32, error) {
+	resp, err := a.svc.IMachine_getMemorySizeContext(ctx, &generated.IMachine_getMemorySize{This: machineRef})
+	if err != nil {
+		return 0, a.wrap(ctx, "IMachine_getMemorySize", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetMemorySize(ctx context.Context, mutableMachineRef string, memoryMB uint32) error {
+	_, err := a.svc.IMachine_setMemorySizeContext(ctx, &generated.IMachine_setMemorySize{
+		This:       mutableMachineRef,
+		MemorySize: memoryMB,
+	})
+	return a.wrap(ctx, "IMachine_setMemorySize", err)
+}
+
+func (a *Adapter) GetPageFusionEnabled(ctx context.Context, machineRef string) (bool, error) {
+	resp, err := a.svc.IMachine_getPageFusionEnabledContext(ctx, &generated.IMachine_getPageFusionEnabled{This: machineRef})
+	if err != nil {
+		return false, a.wrap(ctx, "IMachine_getPageFusionEnabled", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetPageFusionEnabled(ctx context.Context, mutableMachineRef string, enabled bool) error {
+	_, err := a.svc.IMachine_setPageFusionEnabledContext(ctx, &generated.IMachine_setPageFusionEnabled{
+		This:              mutableMachineRef,
+		PageFusionEnabled: enabled,
+	})
+	return a.wrap(ctx, "IMachine_setPageFusionEnabled", err)
+}
+
+func (a *Adapter) GetCPUCount(ctx context.Context, machineRef string) (uint32, error) {
+	resp, err := a.svc.IMachine_getCPUCountContext(ctx, &generated.IMachine_getCPUCount{This: machineRef})
+	if err != nil {
+		return 0, a.wrap(ctx, "IMachine_getCPUCount", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetCPUCount(ctx context.Context, mutableMachineRef string, count uint32) error {
+	_, err := a.svc.IMachine_setCPUCountContext(ctx, &generated.IMachine_setCPUCount{
+		This:     mutableMachineRef,
+		CPUCount: count,
+	})
+	return a.wrap(ctx, "IMachine_setCPUCount", err)
+}
+
+func (a *Adapter) GetCPUHotPlugEnabled(ctx context.Context, machineRef string) (bool, error) {
+	resp, err := a.svc.IMachine_getCPUHotPlugEnabledContext(ctx, &generated.IMachine_getCPUHotPlugEnabled{This: machineRef})
+	if err != nil {
+		return false, a.wrap(ctx, "IMachine_getCPUHotPlugEnabled", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetCPUStatus(ctx context.Context, machineRef string, cpu uint32) (bool, error) {
+	resp, err := a.svc.IMachine_getCPUStatusContext(ctx, &generated.IMachine_getCPUStatus{
+		This: machineRef,
+		Cpu:  cpu,
+	})
+	if err != nil {
+		return false, a.wrap(ctx, "IMachine_getCPUStatus", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) HotPlugCPU(ctx context.Context, mutableMachineRef string, cpu uint32) error {
+	_, err := a.svc.IMachine_hotPlugCPUContext(ctx, &generated.IMachine_hotPlugCPU{
+		This: mutableMachineRef,
+		Cpu:  cpu,
+	})
+	return a.wrap(ctx, "IMachine_hotPlugCPU", err)
+}
+
+func (a *Adapter) HotUnplugCPU(ctx context.Context, mutableMachineRef string, cpu uint32) error {
+	_, err := a.svc.IMachine_hotUnplugCPUContext(ctx, &generated.IMachine_hotUnplugCPU{
+		This: mutableMachineRef,
+		Cpu:  cpu,
+	})
+	return a.wrap(ctx, "IMachine_hotUnplugCPU", err)
+}
+
+func (a *Adapter) SetVRAMSize(ctx context.Context, mutableMachineRef string, vramMB uint32) error {
+	gaRef, err := a.getGraphicsAdapter(ctx, mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	_, err = a.svc.IGraphicsAdapter_setVRAMSizeContext(ctx, &generated.IGraphicsAdapter_setVRAMSize{
+		This:     gaRef,
+		VRAMSize: vramMB,
+	})
+	return a.wrap(ctx, "IGraphicsAdapter_setVRAMSize", err)
+}
+
+func (a *Adapter) getSystemProperties(ctx context.Context, session string) (string, error) {
+	resp, err := a.svc.IVirtualBox_getSystemPropertiesContext(ctx, &generated.IVirtualBox_getSystemProperties{This: session})
+	if err != nil {
+		return "", a.wrap(ctx, "IVirtualBox_getSystemProperties", err)
+	}
+	return resp.Returnval, nil
+}
+
+// GetGuestVRAMLimits returns the VRAM sizes, in MB, that VirtualBox accepts.
+func (a *Adapter) GetGuestVRAMLimits(ctx context.Context, session string) (uint32, uint32, error) {
+	spRef, err := a.getSystemProperties(ctx, session)
+	if err != nil {
+		return 0, 0, err
+	}
+	minResp, err := a.svc.ISystemProperties_getMinGuestVRAMContext(ctx, &generated.ISystemProperties_getMinGuestVRAM{This: spRef})
+	if err != nil {
+		return 0, 0, a.wrap(ctx, "ISystemProperties_getMinGuestVRAM", err)
+	}
+	maxResp, err := a.svc.ISystemProperties_getMaxGuestVRAMContext(ctx, &generated.ISystemProperties_getMaxGuestVRAM{This: spRef})
+	if err != nil {
+		return 0, 0, a.wrap(ctx, "ISystemProperties_getMaxGuestVRAM", err)
+	}
+	return minResp.Returnval, maxResp.Returnval, nil
+}
+
+func (a *Adapter) GetAccelerate3DEnabled(ctx context.Context, machineRef string) (bool, error) {
+	gaRef, err := a.getGraphicsAdapter(ctx, machineRef)
+	if err != nil {
+		return false, err
+	}
+	feature := generated.GraphicsFeatureAcceleration3D
+	resp, err := a.svc.IGraphicsAdapter_isFeatureEnabledContext(ctx, &generated.IGraphicsAdapter_isFeatureEnabled{
+		This:    gaRef,
+		Feature: &feature,
+	})
+	if err != nil {
+		return false, a.wrap(ctx, "IGraphicsAdapter_isFeatureEnabled", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetAccelerate3DEnabled(ctx context.Context, mutableMachineRef string, enabled bool) error {
+	gaRef, err := a.getGraphicsAdapter(ctx, mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	feature := generated.GraphicsFeatureAcceleration3D
+	_, err = a.svc.IGraphicsAdapter_setFeatureContext(ctx, &generated.IGraphicsAdapter_setFeature{
+		This:    gaRef,
+		Feature: &feature,
+		Enabled: enabled,
+	})
+	return a.wrap(ctx, "IGraphicsAdapter_setFeature", err)
+}
+
+func (a *Adapter) GetMonitorCount(ctx context.Context, machineRef string) (uint32, error) {
+	gaRef, err := a.getGraphicsAdapter(ctx, machineRef)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := a.svc.IGraphicsAdapter_getMonitorCountContext(ctx, &generated.IGraphicsAdapter_getMonitorCount{This: gaRef})
+	if err != nil {
+		return 0, a.wrap(ctx, "IGraphicsAdapter_getMonitorCount", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetMonitorCount(ctx context.Context, mutableMachineRef string, count uint32) error {
+	gaRef, err := a.getGraphicsAdapter(ctx, mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	_, err = a.svc.IGraphicsAdapter_setMonitorCountContext(ctx, &generated.IGraphicsAdapter_setMonitorCount{
+		This:         gaRef,
+		MonitorCount: count,
+	})
+	return a.wrap(ctx, "IGraphicsAdapter_setMonitorCount", err)
+}
+
+func (a *Adapter) GetMaxGuestMonitors(ctx context.Context, session string) (uint32, error) {
+	spRef, err := a.getSystemProperties(ctx, session)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := a.svc.ISystemProperties_getMaxGuestMonitorsContext(ctx, &generated.ISystemProperties_getMaxGuestMonitors{This: spRef})
+	if err != nil {
+		return 0, a.wrap(ctx, "ISystemProperties_getMaxGuestMonitors", err)
+	}
+	return resp.Returnval, nil
+}
+
+// GetGuestRAMLimits returns the guest memory sizes, in MB, that VirtualBox
+// accepts.
+func (a *Adapter) GetGuestRAMLimits(ctx context.Context, session string) (uint32, uint32, error) {
+	spRef, err := a.getSystemProperties(ctx, session)
+	if err != nil {
+		return 0, 0, err
+	}
+	minResp, err := a.svc.ISystemProperties_getMinGuestRAMContext(ctx, &generated.ISystemProperties_getMinGuestRAM{This: spRef})
+	if err != nil {
+		return 0, 0, a.wrap(ctx, "ISystemProperties_getMinGuestRAM", err)
+	}
+	maxResp, err := a.svc.ISystemProperties_getMaxGuestRAMContext(ctx, &generated.ISystemProperties_getMaxGuestRAM{This: spRef})
+	if err != nil {
+		return 0, 0, a.wrap(ctx, "ISystemProperties_getMaxGuestRAM", err)
+	}
+	return minResp.Returnval, maxResp.Returnval, nil
+}
+
+// GetGuestCPUCountLimits returns the virtual CPU counts that VirtualBox
+// accepts.
+func (a *Adapter) GetGuestCPUCountLimits(ctx context.Context, session string) (uint32, uint32, error) {
+	spRef, err := a.getSystemProperties(ctx, session)
+	if err != nil {
+		return 0, 0, err
+	}
+	minResp, err := a.svc.ISystemProperties_getMinGuestCPUCountContext(ctx, &generated.ISystemProperties_getMinGuestCPUCount{This: spRef})
+	if err != nil {
+		return 0, 0, a.wrap(ctx, "ISystemProperties_getMinGuestCPUCount", err)
+	}
+	maxResp, err := a.svc.ISystemProperties_getMaxGuestCPUCountContext(ctx, &generated.ISystemProperties_getMaxGuestCPUCount{This: spRef})
+	if err != nil {
+		return 0, 0, a.wrap(ctx, "ISystemProperties_getMaxGuestCPUCount", err)
+	}
+	return minResp.Returnval, maxResp.Returnval, nil
+}
+
+func (a *Adapter) getFirmwareSettings(ctx context.Context, machineRef string) (string, error) {
+	resp, err := a.svc.IMachine_getFirmwareSettingsContext(ctx, &generated.IMachine_getFirmwareSettings{This: machineRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_getFirmwareSettings", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetAPICMode(ctx context.Context, machineRef string) (vboxapi.APICMode, error) {
+	fwRef, err := a.getFirmwareSettings(ctx, machineRef)
+	if err != nil {
+		return "", err
+	}
+	resp, err := a.svc.IFirmwareSettings_getAPICModeContext(ctx, &generated.IFirmwareSettings_getAPICMode{This: fwRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IFirmwareSettings_getAPICMode", err)
+	}
+	if resp.Returnval == nil {
+		return vboxapi.APICModeDisabled, nil
+	}
+	return vboxapi.APICMode(*resp.Returnval), nil
+}
+
+func (a *Adapter) SetAPICMode(ctx context.Context, mutableMachineRef string, mode vboxapi.APICMode) error {
+	fwRef, err := a.getFirmwareSettings(ctx, mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m := generated.APICMode(mode)
+	_, err = a.svc.IFirmwareSettings_setAPICModeContext(ctx, &generated.IFirmwareSettings_setAPICMode{
+		This:     fwRef,
+		APICMode: &m,
+	})
+	return a.wrap(ctx, "IFirmwareSettings_setAPICMode", err)
+}
+
+func (a *Adapter) GetBootMenuMode(ctx context.Context, machineRef string) (vboxapi.BootMenuMode, error) {
+	fwRef, err := a.getFirmwareSettings(ctx, machineRef)
+	if err != nil {
+		return "", err
+	}
+	resp, err := a.svc.IFirmwareSettings_getBootMenuModeContext(ctx, &generated.IFirmwareSettings_getBootMenuMode{This: fwRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IFirmwareSettings_getBootMenuMode", err)
+	}
+	if resp.Returnval == nil {
+		return vboxapi.BootMenuModeDisabled, nil
+	}
+	return vboxapi.BootMenuMode(*resp.Returnval), nil
+}
+
+func (a *Adapter) GetAutostartEnabled(ctx context.Context, machineRef string) (bool, error) {
+	resp, err := a.svc.IMachine_getAutostartEnabledContext(ctx, &generated.IMachine_getAutostartEnabled{This: machineRef})
+	if err != nil {
+		return false, a.wrap(ctx, "IMachine_getAutostartEnabled", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetAutostartEnabled(ctx context.Context, mutableMachineRef string, enabled bool) error {
+	_, err := a.svc.IMachine_setAutostartEnabledContext(ctx, &generated.IMachine_setAutostartEnabled{
+		This:             mutableMachineRef,
+		AutostartEnabled: enabled,
+	})
+	return a.wrap(ctx, "IMachine_setAutostartEnabled", err)
+}
+
+func (a *Adapter) GetAutostartDelay(ctx context.Context, machineRef string) (uint32, error) {
+	resp, err := a.svc.IMachine_getAutostartDelayContext(ctx, &generated.IMachine_getAutostartDelay{This: machineRef})
+	if err != nil {
+		return 0, a.wrap(ctx, "IMachine_getAutostartDelay", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetAutostartDelay(ctx context.Context, mutableMachineRef string, seconds uint32) error {
+	_, err := a.svc.IMachine_setAutostartDelayContext(ctx, &generated.IMachine_setAutostartDelay{
+		This:           mutableMachineRef,
+		AutostartDelay: seconds,
+	})
+	return a.wrap(ctx, "IMachine_setAutostartDelay", err)
+}
+
+func (a *Adapter) GetClipboardFileTransfersEnabled(ctx context.Context, machineRef string) (bool, error) {
+	resp, err := a.svc.IMachine_getClipboardFileTransfersEnabledContext(ctx, &generated.IMachine_getClipboardFileTransfersEnabled{This: machineRef})
+	if err != nil {
+		return false, a.wrap(ctx, "IMachine_getClipboardFileTransfersEnabled", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetClipboardFileTransfersEnabled(ctx context.Context, mutableMachineRef string, enabled bool) error {
+	_, err := a.svc.IMachine_setClipboardFileTransfersEnabledContext(ctx, &generated.IMachine_setClipboardFileTransfersEnabled{
+		This:                          mutableMachineRef,
+		ClipboardFileTransfersEnabled: enabled,
+	})
+	return a.wrap(ctx, "IMachine_setClipboardFileTransfersEnabled", err)
+}
+
+func (a *Adapter) getRecordingSettings(ctx context.Context, machineRef string) (string, error) {
+	resp, err := a.svc.IMachine_getRecordingSettingsContext(ctx, &generated.IMachine_getRecordingSettings{This: machineRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_getRecordingSettings", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetRecordingEnabled(ctx context.Context, machineRef string) (bool, error) {
+	rsRef, err := a.getRecordingSettings(ctx, machineRef)
+	if err != nil {
+		return false, err
+	}
+	resp, err := a.svc.IRecordingSettings_getEnabledContext(ctx, &generated.IRecordingSettings_getEnabled{This: rsRef})
+	if err != nil {
+		return false, a.wrap(ctx, "IRecordingSettings_getEnabled", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetRecordingEnabled(ctx context.Context, mutableMachineRef string, enabled bool) error {
+	rsRef, err := a.getRecordingSettings(ctx, mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	_, err = a.svc.IRecordingSettings_setEnabledContext(ctx, &generated.IRecordingSettings_setEnabled{
+		This:    rsRef,
+		Enabled: enabled,
+	})
+	return a.wrap(ctx, "IRecordingSettings_setEnabled", err)
+}
+
+func (a *Adapter) GetRecordingScreenSettings(ctx context.Context, machineRef string) ([]vboxapi.RecordingScreenSettings, error) {
+	rsRef, err := a.getRecordingSettings(ctx, machineRef)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.svc.IRecordingSettings_getScreensContext(ctx, &generated.IRecordingSettings_getScreens{This: rsRef})
+	if err != nil {
+		return nil, a.wrap(ctx, "IRecordingSettings_getScreens", err)
+	}
+
+	screens := make([]vboxapi.RecordingScreenSettings, 0, len(resp.Returnval))
+	for _, screenRef := range resp.Returnval {
+		var screen vboxapi.RecordingScreenSettings
+		id, err := a.svc.IRecordingScreenSettings_getIdContext(ctx, &generated.IRecordingScreenSettings_getId{This: screenRef})
+		if err != nil {
+			return nil, a.wrap(ctx, "IRecordingScreenSettings_getId", err)
+		}
+		screen.ID = id.Returnval
+		enabled, err := a.svc.IRecordingScreenSettings_getEnabledContext(ctx, &generated.IRecordingScreenSettings_getEnabled{This: screenRef})
+		if err != nil {
+			return nil, a.wrap(ctx, "IRecordingScreenSettings_getEnabled", err)
+		}
+		screen.Enabled = enabled.Returnval
+		filename, err := a.svc.IRecordingScreenSettings_getFilenameContext(ctx, &generated.IRecordingScreenSettings_getFilename{This: screenRef})
+		if err != nil {
+			return nil, a.wrap(ctx, "IRecordingScreenSettings_getFilename", err)
+		}
+		screen.Filename = filename.Returnval
+		codec, err := a.svc.IRecordingScreenSettings_getVideoCodecContext(ctx, &generated.IRecordingScreenSettings_getVideoCodec{This: screenRef})
+		if err != nil {
+			return nil, a.wrap(ctx, "IRecordingScreenSettings_getVideoCodec", err)
+		}
+		if codec.Returnval != nil {
+			screen.VideoCodec = vboxapi.RecordingVideoCodec(*codec.Returnval)
+		}
+		fps, err := a.svc.IRecordingScreenSettings_getVideoFPSContext(ctx, &generated.IRecordingScreenSettings_getVideoFPS{This: screenRef})
+		if err != nil {
+			return nil, a.wrap(ctx, "IRecordingScreenSettings_getVideoFPS", err)
+		}
+		screen.VideoFPS = fps.Returnval
+		screens = append(screens, screen)
+	}
+	slices.SortFunc(screens, func(a, b vboxapi.RecordingScreenSettings) int { return cmp.Compare(a.ID, b.ID) })
+	return screens, nil
+}
+
+func (a *Adapter) getRecordingScreenSettings(ctx context.Context, machineRef string, screenID uint32) (string, error) {
+	rsRef, err := a.getRecordingSettings(ctx, machineRef)
+	if err != nil {
+		return "", err
+	}
+	resp, err := a.svc.IRecordingSettings_getScreenSettingsContext(ctx, &generated.IRecordingSettings_getScreenSettings{
+		This:     rsRef,
+		ScreenId: screenID,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IRecordingSettings_getScreenSettings", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetRecordingScreenEnabled(ctx context.Context, mutableMachineRef string, screenID uint32, enabled bool) error {
+	screenRef, err := a.getRecordingScreenSettings(ctx, mutableMachineRef, screenID)
+	if err != nil {
+		return err
+	}
+	_, err = a.svc.IRecordingScreenSettings_setEnabledContext(ctx, &generated.IRecordingScreenSettings_setEnabled{
+		This:    screenRef,
+		Enabled: enabled,
+	})
+	return a.wrap(ctx, "IRecordingScreenSettings_setEnabled", err)
+}
+
+func (a *Adapter) SetRecordingScreenFilename(ctx context.Context, mutableMachineRef string, screenID uint32, filename string) error {
+	screenRef, err := a.getRecordingScreenSettings(ctx, mutableMachineRef, screenID)
+	if err != nil {
+		return err
+	}
+	_, err = a.svc.IRecordingScreenSettings_setFilenameContext(ctx, &generated.IRecordingScreenSettings_setFilename{
+		This:     screenRef,
+		Filename: filename,
+	})
+	return a.wrap(ctx, "IRecordingScreenSettings_setFilename", err)
+}
+
+func (a *Adapter) SetRecordingScreenVideoCodec(ctx context.Context, mutableMachineRef string, screenID uint32, codec vboxapi.RecordingVideoCodec) error {
+	screenRef, err := a.getRecordingScreenSettings(ctx, mutableMachineRef, screenID)
+	if err != nil {
+		return err
+	}
+	c := generated.RecordingVideoCodec(codec)
+	_, err = a.svc.IRecordingScreenSettings_setVideoCodecContext(ctx, &generated.IRecordingScreenSettings_setVideoCodec{
+		This:       screenRef,
+		VideoCodec: &c,
+	})
+	return a.wrap(ctx, "IRecordingScreenSettings_setVideoCodec", err)
+}
+
+func (a *Adapter) SetRecordingScreenVideoFPS(ctx context.Context, mutableMachineRef string, screenID uint32, fps uint32) error {
+	screenRef, err := a.getRecordingScreenSettings(ctx, mutableMachineRef, screenID)
+	if err != nil {
+		return err
+	}
+	_, err = a.svc.IRecordingScreenSettings_setVideoFPSContext(ctx, &generated.IRecordingScreenSettings_setVideoFPS{
+		This:     screenRef,
+		VideoFPS: fps,
+	})
+	return a.wrap(ctx, "IRecordingScreenSettings_setVideoFPS", err)
+}
+
+// GetSupportedRecordingVideoCodecs returns the video codecs the host can
+// record with.
+func (a *Adapter) GetSupportedRecordingVideoCodecs(ctx context.Context, session string) ([]vboxapi.RecordingVideoCodec, error) {
+	spRef, err := a.getSystemProperties(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.svc.ISystemProperties_getSupportedRecordingVideoCodecsContext(ctx, &generated.ISystemProperties_getSupportedRecordingVideoCodecs{This: spRef})
+	if err != nil {
+		return nil, a.wrap(ctx, "ISystemProperties_getSupportedRecordingVideoCodecs", err)
+	}
+	codecs := make([]vboxapi.RecordingVideoCodec, 0, len(resp.Returnval))
+	for _, c := range resp.Returnval {
+		if c != nil {
+			codecs = append(codecs, vboxapi.RecordingVideoCodec(*c))
+		}
+	}
+	return codecs, nil
+}
+
+func (a *Adapter) SetBootMenuMode(ctx context.Context, mutableMachineRef string, mode vboxapi.BootMenuMode) error {
+	fwRef, err := a.getFirmwareSettings(ctx, mutableMachineRef)
+	if err != nil {
+		return err
+	}
+	m := generated.FirmwareBootMenuMode(mode)
+	_, err = a.svc.IFirmwareSettings_setBootMenuModeContext(ctx, &generated.IFirmwareSettings_setBootMenuMode{
+		This:         fwRef,
+		BootMenuMode: &m,
+	})
+	return a.wrap(ctx, "IFirmwareSettings_setBootMenuMode", err)
+}
@@ -0,0 +1,80 @@
+package vbox71
+
+import (
+	"context"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox71/generated"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func (a *Adapter) GetSharedFolders(ctx context.Context, machineRef string) ([]vboxapi.SharedFolder, error) {
+	resp, err := a.svc.IMachine_getSharedFoldersContext(ctx, &generated.IMachine_getSharedFolders{This: machineRef})
+	if err != nil {
+		return nil, a.wrap(ctx, "IMachine_getSharedFolders", err)
+	}
+
+	folders := make([]vboxapi.SharedFolder, 0, len(resp.Returnval))
+	for _, ref := range resp.Returnval {
+		folder, err := a.sharedFolder(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		folders = append(folders, folder)
+	}
+	return folders, nil
+}
+
+func (a *Adapter) sharedFolder(ctx context.Context, folderRef string) (vboxapi.SharedFolder, error) {
+	var folder vboxapi.SharedFolder
+
+	name, err := a.svc.ISharedFolder_getNameContext(ctx, &generated.ISharedFolder_getName{This: folderRef})
+	if err != nil {
+		return folder, a.wrap(ctx, "ISharedFolder_getName", err)
+	}
+	folder.Name = name.Returnval
+
+	hostPath, err := a.svc.ISharedFolder_getHostPathContext(ctx, &generated.ISharedFolder_getHostPath{This: folderRef})
+	if err != nil {
+		return folder, a.wrap(ctx, "ISharedFolder_getHostPath", err)
+	}
+	folder.HostPath = hostPath.Returnval
+
+	writable, err := a.svc.ISharedFolder_getWritableContext(ctx, &generated.ISharedFolder_getWritable{This: folderRef})
+	if err != nil {
+		return folder, a.wrap(ctx, "ISharedFolder_getWritable", err)
+	}
+	folder.Writable = writable.Returnval
+
+	autoMount, err := a.svc.ISharedFolder_getAutoMountContext(ctx, &generated.ISharedFolder_getAutoMount{This: folderRef})
+	if err != nil {
+		return folder, a.wrap(ctx, "ISharedFolder_getAutoMount", err)
+	}
+	folder.AutoMount = autoMount.Returnval
+
+	autoMountPoint, err := a.svc.ISharedFolder_getAutoMountPointContext(ctx, &generated.ISharedFolder_getAutoMountPoint{This: folderRef})
+	if err != nil {
+		return folder, a.wrap(ctx, "ISharedFolder_getAutoMountPoint", err)
+	}
+	folder.AutoMountPoint = autoMountPoint.Returnval
+	return folder, nil
+}
+
+func (a *Adapter) CreateSharedFolder(ctx context.Context, mutableMachineRef string, folder vboxapi.SharedFolder) error {
+	_, err := a.svc.IMachine_createSharedFolderContext(ctx, &generated.IMachine_createSharedFolder{
+		This:           mutableMachineRef,
+		Name:           folder.Name,
+		HostPath:       folder.HostPath,
+		Writable:       folder.Writable,
+		Automount:      folder.AutoMount,
+		AutoMountPoint: folder.AutoMountPoint,
+	})
+	return a.wrap(ctx, "IMachine_createSharedFolder", err)
+}
+
+func (a *Adapter) RemoveSharedFolder(ctx context.Context, mutableMachineRef, name string) error {
+	_, err := a.svc.IMachine_removeSharedFolderContext(ctx, &generated.IMachine_removeSharedFolder{
+		This: mutableMachineRef,
+		Name: name,
+	})
+	return a.wrap(ctx, "IMachine_removeSharedFolder", err)
+}
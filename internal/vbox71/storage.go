@@ -0,0 +1,396 @@
+package vbox71
+
+import (
+	"context"
+
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vbox71/generated"
+	"github.com/aslafy-z/terraform-provider-vboxweb/internal/vboxapi"
+)
+
+func (a *Adapter) GetStorageControllerByName(ctx context.Context, machineRef, name string) (string, error) {
+	resp, err := a.svc.IMachine_getStorageControllerByNameContext(ctx, &generated.IMachine_getStorageControllerByName{
+		This: machineRef,
+		Name: name,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_getStorageControllerByName", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetStorageControllers(ctx context.Context, machineRef string) ([]vboxapi.StorageController, error) {
+	resp, err := a.svc.IMachine_getStorageControllersContext(ctx, &generated.IMachine_getStorageControllers{This: machineRef})
+	if err != nil {
+		return nil, a.wrap(ctx, "IMachine_getStorageControllers", err)
+	}
+
+	controllers := make([]vboxapi.StorageController, 0, len(resp.Returnval))
+	for _, ref := range resp.Returnval {
+		ctl, err := a.storageController(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		controllers = append(controllers, ctl)
+	}
+	return controllers, nil
+}
+
+func (a *Adapter) storageController(ctx context.Context, controllerRef string) (vboxapi.StorageController, error) {
+	var ctl vboxapi.StorageController
+
+	name, err := a.svc.IStorageController_getNameContext(ctx, &generated.IStorageController_getName{This: controllerRef})
+	if err != nil {
+		return ctl, a.wrap(ctx, "IStorageController_getName", err)
+	}
+	ctl.Name = name.Returnval
+
+	bus, err := a.svc.IStorageController_getBusContext(ctx, &generated.IStorageController_getBus{This: controllerRef})
+	if err != nil {
+		return ctl, a.wrap(ctx, "IStorageController_getBus", err)
+	}
+	if bus.Returnval != nil {
+		ctl.Bus = vboxapi.StorageBus(*bus.Returnval)
+	}
+
+	ctl.Type, err = a.GetStorageControllerType(ctx, controllerRef)
+	if err != nil {
+		return ctl, err
+	}
+
+	ports, err := a.svc.IStorageController_getPortCountContext(ctx, &generated.IStorageController_getPortCount{This: controllerRef})
+	if err != nil {
+		return ctl, a.wrap(ctx, "IStorageController_getPortCount", err)
+	}
+	ctl.PortCount = ports.Returnval
+
+	cache, err := a.svc.IStorageController_getUseHostIOCacheContext(ctx, &generated.IStorageController_getUseHostIOCache{This: controllerRef})
+	if err != nil {
+		return ctl, a.wrap(ctx, "IStorageController_getUseHostIOCache", err)
+	}
+	ctl.UseHostIOCache = cache.Returnval
+
+	ctl.Bootable, err = a.GetStorageControllerBootable(ctx, controllerRef)
+	if err != nil {
+		return ctl, err
+	}
+	return ctl, nil
+}
+
+func (a *Adapter) AddStorageController(ctx context.Context, mutableMachineRef, name string, bus vboxapi.StorageBus) (string, error) {
+	b := generated.StorageBus(bus)
+	resp, err := a.svc.IMachine_addStorageControllerContext(ctx, &generated.IMachine_addStorageController{
+		This:           mutableMachineRef,
+		Name:           name,
+		ConnectionType: &b,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IMachine_addStorageController", err)
+	}
+	return resp.Returnval, nil
+}
+
+// RemoveStorageController removes a controller together with the devices
+// attached to it.
+func (a *Adapter) RemoveStorageController(ctx context.Context, mutableMachineRef, name string) error {
+	_, err := a.svc.IMachine_removeStorageControllerContext(ctx, &generated.IMachine_removeStorageController{
+		This: mutableMachineRef,
+		Name: name,
+	})
+	return a.wrap(ctx, "IMachine_removeStorageController", err)
+}
+
+func (a *Adapter) SetStorageControllerType(ctx context.Context, controllerRef string, controllerType vboxapi.StorageControllerType) error {
+	t := generated.StorageControllerType(controllerType)
+	_, err := a.svc.IStorageController_setControllerTypeContext(ctx, &generated.IStorageController_setControllerType{
+		This:           controllerRef,
+		ControllerType: &t,
+	})
+	return a.wrap(ctx, "IStorageController_setControllerType", err)
+}
+
+func (a *Adapter) SetStorageControllerPortCount(ctx context.Context, controllerRef string, portCount uint32) error {
+	_, err := a.svc.IStorageController_setPortCountContext(ctx, &generated.IStorageController_setPortCount{
+		This:      controllerRef,
+		PortCount: portCount,
+	})
+	return a.wrap(ctx, "IStorageController_setPortCount", err)
+}
+
+func (a *Adapter) SetStorageControllerUseHostIOCache(ctx context.Context, controllerRef string, enabled bool) error {
+	_, err := a.svc.IStorageController_setUseHostIOCacheContext(ctx, &generated.IStorageController_setUseHostIOCache{
+		This:           controllerRef,
+		UseHostIOCache: enabled,
+	})
+	return a.wrap(ctx, "IStorageController_setUseHostIOCache", err)
+}
+
+func (a *Adapter) GetStorageControllerBootable(ctx context.Context, controllerRef string) (bool, error) {
+	resp, err := a.svc.IStorageController_getBootableContext(ctx, &generated.IStorageController_getBootable{This: controllerRef})
+	if err != nil {
+		return false, a.wrap(ctx, "IStorageController_getBootable", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) SetStorageControllerBootable(ctx context.Context, mutableMachineRef, name string, bootable bool) error {
+	_, err := a.svc.IMachine_setStorageControllerBootableContext(ctx, &generated.IMachine_setStorageControllerBootable{
+		This:     mutableMachineRef,
+		Name:     name,
+		Bootable: bootable,
+	})
+	return a.wrap(ctx, "IMachine_setStorageControllerBootable", err)
+}
+
+func (a *Adapter) GetStorageControllerType(ctx context.Context, controllerRef string) (vboxapi.StorageControllerType, error) {
+	resp, err := a.svc.IStorageController_getControllerTypeContext(ctx, &generated.IStorageController_getControllerType{This: controllerRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IStorageController_getControllerType", err)
+	}
+	if resp.Returnval == nil {
+		return "", nil
+	}
+	return vboxapi.StorageControllerType(*resp.Returnval), nil
+}
+
+func (a *Adapter) OpenMedium(ctx context.Context, session, location string, deviceType vboxapi.DeviceType, accessMode vboxapi.AccessMode) (string, error) {
+	dt := generated.DeviceType(deviceType)
+	am := generated.AccessMode(accessMode)
+	resp, err := a.svc.IVirtualBox_openMediumContext(ctx, &generated.IVirtualBox_openMedium{
+		This:       session,
+		Location:   location,
+		DeviceType: &dt,
+		AccessMode: &am,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IVirtualBox_openMedium", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetMediumId(ctx context.Context, mediumRef string) (string, error) {
+	resp, err := a.svc.IMedium_getIdContext(ctx, &generated.IMedium_getId{This: mediumRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMedium_getId", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetMediumLocation(ctx context.Context, mediumRef string) (string, error) {
+	resp, err := a.svc.IMedium_getLocationContext(ctx, &generated.IMedium_getLocation{This: mediumRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMedium_getLocation", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetMediumType(ctx context.Context, mediumRef string) (vboxapi.MediumType, error) {
+	resp, err := a.svc.IMedium_getTypeContext(ctx, &generated.IMedium_getType{This: mediumRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMedium_getType", err)
+	}
+	if resp.Returnval == nil {
+		return vboxapi.MediumTypeNormal, nil
+	}
+	return vboxapi.MediumType(*resp.Returnval), nil
+}
+
+func (a *Adapter) SetMediumType(ctx context.Context, mediumRef string, mediumType vboxapi.MediumType) error {
+	mt := generated.MediumType(mediumType)
+	_, err := a.svc.IMedium_setTypeContext(ctx, &generated.IMedium_setType{
+		This:  mediumRef,
+		Type_: &mt,
+	})
+	return a.wrap(ctx, "IMedium_setType", err)
+}
+
+func (a *Adapter) GetMediumVariant(ctx context.Context, mediumRef string) ([]string, error) {
+	resp, err := a.svc.IMedium_getVariantContext(ctx, &generated.IMedium_getVariant{This: mediumRef})
+	if err != nil {
+		return nil, a.wrap(ctx, "IMedium_getVariant", err)
+	}
+	var variants []string
+	for _, v := range resp.Returnval {
+		if v != nil {
+			variants = append(variants, string(*v))
+		}
+	}
+	return variants, nil
+}
+
+func (a *Adapter) GetMediumFormat(ctx context.Context, mediumRef string) (string, error) {
+	resp, err := a.svc.IMedium_getFormatContext(ctx, &generated.IMedium_getFormat{This: mediumRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMedium_getFormat", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) CreateMedium(ctx context.Context, session, format, location string, deviceType vboxapi.DeviceType) (string, error) {
+	dt := generated.DeviceType(deviceType)
+	am := generated.AccessModeReadWrite
+	resp, err := a.svc.IVirtualBox_createMediumContext(ctx, &generated.IVirtualBox_createMedium{
+		This:            session,
+		Format:          format,
+		Location:        location,
+		AccessMode:      &am,
+		ADeviceTypeType: &dt,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IVirtualBox_createMedium", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) CloneMediumToBase(ctx context.Context, mediumRef, targetRef string, variants []string) (string, error) {
+	mv := make([]*generated.MediumVariant, 0, len(variants))
+	for _, v := range variants {
+		v := generated.MediumVariant(v)
+		mv = append(mv, &v)
+	}
+	resp, err := a.svc.IMedium_cloneToBaseContext(ctx, &generated.IMedium_cloneToBase{
+		This:    mediumRef,
+		Target:  targetRef,
+		Variant: mv,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IMedium_cloneToBase", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) MoveMedium(ctx context.Context, mediumRef, location string) (string, error) {
+	resp, err := a.svc.IMedium_moveToContext(ctx, &generated.IMedium_moveTo{
+		This:     mediumRef,
+		Location: location,
+	})
+	if err != nil {
+		return "", a.wrap(ctx, "IMedium_moveTo", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) DeleteMediumStorage(ctx context.Context, mediumRef string) (string, error) {
+	resp, err := a.svc.IMedium_deleteStorageContext(ctx, &generated.IMedium_deleteStorage{This: mediumRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMedium_deleteStorage", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetMediumAttachment(ctx context.Context, machineRef, controller string, port, device int32) (*vboxapi.MediumAttachment, error) {
+	resp, err := a.svc.IMachine_getMediumAttachmentContext(ctx, &generated.IMachine_getMediumAttachment{
+		This:           machineRef,
+		Name:           controller,
+		ControllerPort: port,
+		Device:         device,
+	})
+	if err != nil {
+		return nil, a.wrap(ctx, "IMachine_getMediumAttachment", err)
+	}
+	if resp.Returnval == nil {
+		return nil, nil
+	}
+	return mediumAttachment(resp.Returnval), nil
+}
+
+func (a *Adapter) GetMediumAttachments(ctx context.Context, machineRef string) ([]vboxapi.MediumAttachment, error) {
+	resp, err := a.svc.IMachine_getMediumAttachmentsContext(ctx, &generated.IMachine_getMediumAttachments{This: machineRef})
+	if err != nil {
+		return nil, a.wrap(ctx, "IMachine_getMediumAttachments", err)
+	}
+	var atts []vboxapi.MediumAttachment
+	for _, ma := range resp.Returnval {
+		if ma != nil {
+			atts = append(atts, *mediumAttachment(ma))
+		}
+	}
+	return atts, nil
+}
+
+func mediumAttachment(ma *generated.IMediumAttachment) *vboxapi.MediumAttachment {
+	att := &vboxapi.MediumAttachment{
+		Controller: ma.Controller,
+		Port:       ma.Port,
+		Device:     ma.Device,
+		MediumRef:  ma.Medium,
+	}
+	if ma.Type_ != nil {
+		att.Type = vboxapi.DeviceType(*ma.Type_)
+	}
+	return att
+}
+
+func (a *Adapter) GetMediumBase(ctx context.Context, mediumRef string) (string, error) {
+	resp, err := a.svc.IMedium_getBaseContext(ctx, &generated.IMedium_getBase{This: mediumRef})
+	if err != nil {
+		return "", a.wrap(ctx, "IMedium_getBase", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetMediumChildren(ctx context.Context, mediumRef string) ([]string, error) {
+	resp, err := a.svc.IMedium_getChildrenContext(ctx, &generated.IMedium_getChildren{This: mediumRef})
+	if err != nil {
+		return nil, a.wrap(ctx, "IMedium_getChildren", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) GetMediumMachineIds(ctx context.Context, mediumRef string) ([]string, error) {
+	resp, err := a.svc.IMedium_getMachineIdsContext(ctx, &generated.IMedium_getMachineIds{This: mediumRef})
+	if err != nil {
+		return nil, a.wrap(ctx, "IMedium_getMachineIds", err)
+	}
+	return resp.Returnval, nil
+}
+
+func (a *Adapter) AttachDevice(ctx context.Context, machineRef, controller string, port, device int32, deviceType vboxapi.DeviceType, mediumRef string) error {
+	dt := generated.DeviceType(deviceType)
+	_, err := a.svc.IMachine_attachDeviceContext(ctx, &generated.IMachine_attachDevice{
+		This:           machineRef,
+		Name:           controller,
+		ControllerPort: port,
+		Device:         device,
+		Type_:          &dt,
+		Medium:         mediumRef,
+	})
+	return a.wrap(ctx, "IMachine_attachDevice", err)
+}
+
+// AttachDeviceWithoutMedium attaches an empty DVD or floppy drive, into
+// which a medium can later be inserted with MountMedium.
+func (a *Adapter) AttachDeviceWithoutMedium(ctx context.Context, machineRef, controller string, port, device int32, deviceType vboxapi.DeviceType) error {
+	dt := generated.DeviceType(deviceType)
+	_, err := a.svc.IMachine_attachDeviceWithoutMediumContext(ctx, &generated.IMachine_attachDeviceWithoutMedium{
+		This:           machineRef,
+		Name:           controller,
+		ControllerPort: port,
+		Device:         device,
+		Type_:          &dt,
+	})
+	return a.wrap(ctx, "IMachine_attachDeviceWithoutMedium", err)
+}
+
+func (a *Adapter) DetachDevice(ctx context.Context, machineRef, controller string, port, device int32) error {
+	_, err := a.svc.IMachine_detachDeviceContext(ctx, &generated.IMachine_detachDevice{
+		This:           machineRef,
+		Name:           controller,
+		ControllerPort: port,
+		Device:         device,
+	})
+	return a.wrap(ctx, "IMachine_detachDevice", err)
+}
+
+// MountMedium inserts mediumRef into a DVD or floppy drive. An empty
+// mediumRef ejects the current medium while keeping the drive attached.
+func (a *Adapter) MountMedium(ctx context.Context, machineRef, controller string, port, device int32, mediumRef string, force bool) error {
+	_, err := a.svc.IMachine_mountMediumContext(ctx, &generated.IMachine_mountMedium{
+		This:           machineRef,
+		Name:           controller,
+		ControllerPort: port,
+		Device:         device,
+		Medium:         mediumRef,
+		Force:          force,
+	})
+	return a.wrap(ctx, "IMachine_mountMedium", err)
+}
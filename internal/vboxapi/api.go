@@ -2,7 +2,10 @@
 // This package has no dependencies on other internal packages to avoid import cycles.
 package vboxapi
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // VBoxAPI defines the interface for VirtualBox SOAP operations.
 // This abstraction allows supporting multiple VirtualBox versions with
@@ -18,7 +21,14 @@ type VBoxAPI interface {
 	GetMachines(ctx context.Context, session string) (machineRefs []string, err error)
 
 	// Machine creation and registration
-	CreateMachine(ctx context.Context, session, name, osTypeId, sourceMachineRef string) (machineRef string, err error)
+	// CreateMachine creates an unregistered machine for the given CPU
+	// architecture; an empty arch means PlatformArchitectureX86.
+	CreateMachine(ctx context.Context, session, name, osTypeId string, arch PlatformArchitecture) (machineRef string, err error)
+	GetPlatformArchitecture(ctx context.Context, machineRef string) (PlatformArchitecture, error)
+	// ApplyDefaults gives a new machine the recommended settings of its OS
+	// type, such as its memory size, storage controllers and network
+	// adapter.
+	ApplyDefaults(ctx context.Context, machineRef string) error
 	RegisterMachine(ctx context.Context, session, machineRef string) error
 	UnregisterMachine(ctx context.Context, machineRef string) (mediaRefs []string, err error)
 	DeleteConfig(ctx context.Context, machineRef string, mediaRefs []string) (progressRef string, err error)
@@ -27,7 +37,108 @@ type VBoxAPI interface {
 	GetMachineId(ctx context.Context, machineRef string) (uuid string, err error)
 	GetMachineName(ctx context.Context, machineRef string) (name string, err error)
 	GetMachineState(ctx context.Context, machineRef string) (state string, err error)
+	// GetMachineAccessible reports whether the machine's settings could be
+	// loaded. Of an inaccessible machine, only the ID and the access error
+	// can be read.
+	GetMachineAccessible(ctx context.Context, machineRef string) (bool, error)
+	// GetMachineAccessError returns why the machine is inaccessible, or ""
+	// when it is accessible.
+	GetMachineAccessError(ctx context.Context, machineRef string) (string, error)
 	GetOSTypeId(ctx context.Context, machineRef string) (osTypeId string, err error)
+	GetSnapshotCount(ctx context.Context, machineRef string) (count uint32, err error)
+	// GetCurrentSnapshot returns "" when the machine has no snapshots.
+	GetCurrentSnapshot(ctx context.Context, machineRef string) (snapshotRef string, err error)
+	GetSnapshotName(ctx context.Context, snapshotRef string) (name string, err error)
+
+	// Snapshots. TakeSnapshot and DeleteSnapshot require a locked mutable
+	// machine: a shared lock while the VM runs, a write lock otherwise.
+	TakeSnapshot(ctx context.Context, mutableMachineRef, name, description string, pause bool) (snapshotID, progressRef string, err error)
+	DeleteSnapshot(ctx context.Context, mutableMachineRef, snapshotID string) (progressRef string, err error)
+	// RestoreSnapshot requires a write lock: the machine must not be
+	// running.
+	RestoreSnapshot(ctx context.Context, mutableMachineRef, snapshotRef string) (progressRef string, err error)
+	FindSnapshot(ctx context.Context, machineRef, nameOrID string) (snapshotRef string, err error)
+	GetSnapshotId(ctx context.Context, snapshotRef string) (uuid string, err error)
+	GetSnapshotDescription(ctx context.Context, snapshotRef string) (description string, err error)
+	GetSnapshotTimeStamp(ctx context.Context, snapshotRef string) (time.Time, error)
+	SetSnapshotName(ctx context.Context, snapshotRef, name string) error
+	SetSnapshotDescription(ctx context.Context, snapshotRef, description string) error
+
+	// Machine settings (setters require a locked mutable machine)
+	GetExecutionEngine(ctx context.Context, machineRef string) (ExecutionEngine, error)
+	SetExecutionEngine(ctx context.Context, mutableMachineRef string, engine ExecutionEngine) error
+	IsExecutionEngineSupported(ctx context.Context, session, machineRef string, engine ExecutionEngine) (supported bool, err error)
+	GetVRDEProperty(ctx context.Context, machineRef, key string) (value string, err error)
+	SetVRDEProperty(ctx context.Context, mutableMachineRef, key, value string) error
+	GetExtraData(ctx context.Context, machineRef, key string) (value string, err error)
+	SetExtraData(ctx context.Context, mutableMachineRef, key, value string) error
+	// GetGlobalExtraData and SetGlobalExtraData access the extra data of
+	// the VirtualBox installation rather than of a machine. An empty value
+	// is a missing item; setting one removes the item.
+	GetGlobalExtraData(ctx context.Context, session, key string) (value string, err error)
+	SetGlobalExtraData(ctx context.Context, session, key, value string) error
+	// GetGuestProperty returns an empty value for a property that does not
+	// exist. SetGuestProperty with an empty value deletes the property.
+	GetGuestProperty(ctx context.Context, machineRef, name string) (value, flags string, err error)
+	SetGuestProperty(ctx context.Context, mutableMachineRef, name, value, flags string) error
+	// EnumerateGuestProperties returns the properties whose names match
+	// patterns, a "|" separated list of wildcard patterns; empty matches
+	// all properties.
+	EnumerateGuestProperties(ctx context.Context, machineRef, patterns string) ([]GuestProperty, error)
+	GetCPUIDLeaves(ctx context.Context, machineRef string) ([]CPUIDLeaf, error)
+	SetCPUIDLeaf(ctx context.Context, mutableMachineRef string, leaf CPUIDLeaf) error
+	RemoveCPUIDLeaf(ctx context.Context, mutableMachineRef string, leaf, subLeaf uint32) error
+	GetGraphicsController(ctx context.Context, machineRef string) (GraphicsController, error)
+	SetGraphicsController(ctx context.Context, mutableMachineRef string, controller GraphicsController) error
+	GetVRAMSize(ctx context.Context, machineRef string) (vramMB uint32, err error)
+	SetVRAMSize(ctx context.Context, mutableMachineRef string, vramMB uint32) error
+	GetGuestVRAMLimits(ctx context.Context, session string) (minMB, maxMB uint32, err error)
+	GetAccelerate3DEnabled(ctx context.Context, machineRef string) (enabled bool, err error)
+	SetAccelerate3DEnabled(ctx context.Context, mutableMachineRef string, enabled bool) error
+	GetMonitorCount(ctx context.Context, machineRef string) (count uint32, err error)
+	SetMonitorCount(ctx context.Context, mutableMachineRef string, count uint32) error
+	GetMaxGuestMonitors(ctx context.Context, session string) (count uint32, err error)
+	GetMemorySize(ctx context.Context, machineRef string) (memoryMB uint32, err error)
+	SetMemorySize(ctx context.Context, mutableMachineRef string, memoryMB uint32) error
+	GetPageFusionEnabled(ctx context.Context, machineRef string) (enabled bool, err error)
+	SetPageFusionEnabled(ctx context.Context, mutableMachineRef string, enabled bool) error
+	// GetCPUCount returns the number of CPUs of the machine. With CPU
+	// hot-plug enabled, it is the number of CPU slots, of which
+	// GetCPUStatus reports the attached ones.
+	GetCPUCount(ctx context.Context, machineRef string) (count uint32, err error)
+	SetCPUCount(ctx context.Context, mutableMachineRef string, count uint32) error
+	GetCPUHotPlugEnabled(ctx context.Context, machineRef string) (enabled bool, err error)
+	GetCPUStatus(ctx context.Context, machineRef string, cpu uint32) (attached bool, err error)
+	// HotPlugCPU and HotUnplugCPU attach and detach the CPU with the given
+	// index, also while the VM runs. They require CPU hot-plug; CPU 0
+	// cannot be detached.
+	HotPlugCPU(ctx context.Context, mutableMachineRef string, cpu uint32) error
+	HotUnplugCPU(ctx context.Context, mutableMachineRef string, cpu uint32) error
+	GetGuestRAMLimits(ctx context.Context, session string) (minMB, maxMB uint32, err error)
+	GetGuestCPUCountLimits(ctx context.Context, session string) (minCount, maxCount uint32, err error)
+	GetAPICMode(ctx context.Context, machineRef string) (APICMode, error)
+	GetHWVirtExProperty(ctx context.Context, machineRef string, property HWVirtExProperty) (enabled bool, err error)
+	GetCPUProperty(ctx context.Context, machineRef string, property CPUProperty) (enabled bool, err error)
+	SetCPUProperty(ctx context.Context, mutableMachineRef string, property CPUProperty, enabled bool) error
+	SetAPICMode(ctx context.Context, mutableMachineRef string, mode APICMode) error
+	GetBootMenuMode(ctx context.Context, machineRef string) (BootMenuMode, error)
+	SetBootMenuMode(ctx context.Context, mutableMachineRef string, mode BootMenuMode) error
+	GetAutostartEnabled(ctx context.Context, machineRef string) (enabled bool, err error)
+	SetAutostartEnabled(ctx context.Context, mutableMachineRef string, enabled bool) error
+	GetAutostartDelay(ctx context.Context, machineRef string) (seconds uint32, err error)
+	SetAutostartDelay(ctx context.Context, mutableMachineRef string, seconds uint32) error
+	GetClipboardFileTransfersEnabled(ctx context.Context, machineRef string) (enabled bool, err error)
+	SetClipboardFileTransfersEnabled(ctx context.Context, mutableMachineRef string, enabled bool) error
+	GetRecordingEnabled(ctx context.Context, machineRef string) (enabled bool, err error)
+	SetRecordingEnabled(ctx context.Context, mutableMachineRef string, enabled bool) error
+	// GetRecordingScreenSettings returns the recording settings of each
+	// screen of the machine, ordered by screen ID.
+	GetRecordingScreenSettings(ctx context.Context, machineRef string) ([]RecordingScreenSettings, error)
+	SetRecordingScreenEnabled(ctx context.Context, mutableMachineRef string, screenID uint32, enabled bool) error
+	SetRecordingScreenFilename(ctx context.Context, mutableMachineRef string, screenID uint32, filename string) error
+	SetRecordingScreenVideoCodec(ctx context.Context, mutableMachineRef string, screenID uint32, codec RecordingVideoCodec) error
+	SetRecordingScreenVideoFPS(ctx context.Context, mutableMachineRef string, screenID uint32, fps uint32) error
+	GetSupportedRecordingVideoCodecs(ctx context.Context, session string) ([]RecordingVideoCodec, error)
 
 	// Clone
 	CloneTo(ctx context.Context, srcMachineRef, targetMachineRef, mode string, options []string) (progressRef string, err error)
@@ -38,14 +149,78 @@ type VBoxAPI interface {
 	UnlockSession(ctx context.Context, sessionObj string) error
 	GetConsole(ctx context.Context, sessionObj string) (consoleRef string, err error)
 	PowerDown(ctx context.Context, consoleRef string) (progressRef string, err error)
+	PowerButton(ctx context.Context, consoleRef string) error
+	Pause(ctx context.Context, consoleRef string) error
+	Resume(ctx context.Context, consoleRef string) error
+	SaveState(ctx context.Context, mutableMachineRef string) (progressRef string, err error)
+	DiscardSavedState(ctx context.Context, mutableMachineRef string, removeFile bool) error
+	// PutScancodes sends PC keyboard scan codes (set 1) to a running VM. It
+	// returns how many were queued, which is less than sent when the
+	// keyboard buffer is full.
+	PutScancodes(ctx context.Context, consoleRef string, scancodes []int32) (queued uint32, err error)
+
+	// Guest Additions (require a running VM)
+	GetGuest(ctx context.Context, consoleRef string) (guestRef string, err error)
+	GetAdditionsRunLevel(ctx context.Context, guestRef string) (AdditionsRunLevel, error)
+	UpdateGuestAdditions(ctx context.Context, guestRef, source string) (progressRef string, err error)
+	// GetMemoryBalloonSize and SetMemoryBalloonSize get and set the guest
+	// RAM, in MB, the Guest Additions hand back to the host. The size is
+	// also saved in the machine settings.
+	GetMemoryBalloonSize(ctx context.Context, guestRef string) (sizeMB uint32, err error)
+	SetMemoryBalloonSize(ctx context.Context, guestRef string, sizeMB uint32) error
+	// GetDefaultAdditionsISO returns "" when the host has no Guest Additions ISO.
+	GetDefaultAdditionsISO(ctx context.Context, session string) (path string, err error)
 
 	// Progress monitoring
 	GetProgressCompleted(ctx context.Context, progressRef string) (completed bool, err error)
 	GetProgressResultCode(ctx context.Context, progressRef string) (resultCode int32, err error)
 	GetProgressErrorText(ctx context.Context, progressRef string) (errorText string, err error)
+	GetProgressOperationDescription(ctx context.Context, progressRef string) (description string, err error)
+	GetProgressDescription(ctx context.Context, progressRef string) (description string, err error)
+	GetProgressPercent(ctx context.Context, progressRef string) (percent uint32, err error)
+	GetProgressCancelable(ctx context.Context, progressRef string) (cancelable bool, err error)
+	CancelProgress(ctx context.Context, progressRef string) error
 
 	// Network adapters and NAT engine
+	GetMaxNetworkAdapters(ctx context.Context, machineRef string) (count uint32, err error)
 	GetNetworkAdapter(ctx context.Context, machineRef string, slot uint32) (adapterRef string, err error)
+	GetAdapterEnabled(ctx context.Context, adapterRef string) (enabled bool, err error)
+	GetAdapterAttachmentType(ctx context.Context, adapterRef string) (NetworkAttachmentType, error)
+	// The setters of an adapter's attachment, and of whether it is enabled
+	// or its cable is connected, must be given the adapter of a mutable
+	// machine. VirtualBox only enables or disables the adapters of
+	// powered-off VMs.
+	SetAdapterEnabled(ctx context.Context, adapterRef string, enabled bool) error
+	SetAdapterAttachmentType(ctx context.Context, adapterRef string, attachment NetworkAttachmentType) error
+	SetAdapterCableConnected(ctx context.Context, adapterRef string, connected bool) error
+	// The interface or network an adapter joins is kept per attachment
+	// type, and only used while the adapter is attached to that type.
+	GetAdapterBridgedInterface(ctx context.Context, adapterRef string) (name string, err error)
+	SetAdapterBridgedInterface(ctx context.Context, adapterRef, name string) error
+	GetAdapterHostOnlyInterface(ctx context.Context, adapterRef string) (name string, err error)
+	SetAdapterHostOnlyInterface(ctx context.Context, adapterRef, name string) error
+	SetAdapterInternalNetwork(ctx context.Context, adapterRef, name string) error
+	GetAdapterNATNetwork(ctx context.Context, adapterRef string) (name string, err error)
+	SetAdapterNATNetwork(ctx context.Context, adapterRef, name string) error
+	GetAdapterMACAddress(ctx context.Context, adapterRef string) (mac string, err error)
+	// SetAdapterMACAddress sets the MAC address of the adapter, as 12
+	// hexadecimal digits; empty generates a new one. The adapter of a
+	// mutable machine locked for writing must be used.
+	SetAdapterMACAddress(ctx context.Context, adapterRef, mac string) error
+	GetAdapterCableConnected(ctx context.Context, adapterRef string) (connected bool, err error)
+	// GetAdapterType and SetAdapterType access the hardware the adapter
+	// emulates. The adapter of a mutable machine locked for writing must
+	// be used to set it.
+	GetAdapterType(ctx context.Context, adapterRef string) (NetworkAdapterType, error)
+	SetAdapterType(ctx context.Context, adapterRef string, adapterType NetworkAdapterType) error
+	// GetAdapterLineSpeed and SetAdapterLineSpeed access the speed the
+	// adapter reports to the guest, in kbps. The adapter of a mutable
+	// machine must be used to set it.
+	GetAdapterLineSpeed(ctx context.Context, adapterRef string) (kbps uint32, err error)
+	SetAdapterLineSpeed(ctx context.Context, adapterRef string, kbps uint32) error
+	// GetAdapterInternalNetwork returns the internal network the adapter
+	// joins when it is attached to one.
+	GetAdapterInternalNetwork(ctx context.Context, adapterRef string) (name string, err error)
 	GetNATEngine(ctx context.Context, adapterRef string) (natEngineRef string, err error)
 	GetNATRedirects(ctx context.Context, natEngineRef string) ([]NATRedirect, error)
 	AddNATRedirect(ctx context.Context, natEngineRef, name string, proto NATProtocol, hostIP string, hostPort uint16, guestIP string, guestPort uint16) error
@@ -54,6 +229,77 @@ type VBoxAPI interface {
 	// NAT Networks (for port conflict detection across NAT networks)
 	GetNATNetworks(ctx context.Context, session string) (natNetworkRefs []string, err error)
 	GetNATNetworkPortForwardRules4(ctx context.Context, natNetworkRef string) ([]NATRedirect, error)
+	AddNATNetworkPortForwardRule4(ctx context.Context, natNetworkRef string, rule NATRedirect) error
+	RemoveNATNetworkPortForwardRule4(ctx context.Context, natNetworkRef, name string) error
+	FindNATNetworkByName(ctx context.Context, session, name string) (natNetworkRef string, err error)
+	CreateNATNetwork(ctx context.Context, session, name string) (natNetworkRef string, err error)
+	RemoveNATNetwork(ctx context.Context, session, natNetworkRef string) error
+	GetNATNetwork(ctx context.Context, natNetworkRef string) (NATNetwork, error)
+	// SetNATNetwork sets the network, enabled and DHCP settings of a NAT
+	// network; its name is left alone.
+	SetNATNetwork(ctx context.Context, natNetworkRef string, network NATNetwork) error
+
+	// Host
+	GetHostOperatingSystem(ctx context.Context, session string) (os string, err error)
+	GetHostOSVersion(ctx context.Context, session string) (version string, err error)
+	GetHostProcessorCount(ctx context.Context, session string) (count uint32, err error)
+	GetHostMemorySize(ctx context.Context, session string) (memoryMB uint32, err error)
+
+	// Host network interfaces
+	GetBridgedInterfaces(ctx context.Context, session string) (interfaceRefs []string, err error)
+	GetHostNetworkInterface(ctx context.Context, interfaceRef string) (HostNetworkInterface, error)
+
+	// Guest OS types
+	GetGuestOSTypes(ctx context.Context, session string) ([]GuestOSType, error)
+
+	// Storage controllers
+	GetStorageControllerByName(ctx context.Context, machineRef, name string) (controllerRef string, err error)
+	GetStorageControllerType(ctx context.Context, controllerRef string) (StorageControllerType, error)
+	GetStorageControllers(ctx context.Context, machineRef string) ([]StorageController, error)
+	AddStorageController(ctx context.Context, mutableMachineRef, name string, bus StorageBus) (controllerRef string, err error)
+	RemoveStorageController(ctx context.Context, mutableMachineRef, name string) error
+	SetStorageControllerType(ctx context.Context, controllerRef string, controllerType StorageControllerType) error
+	SetStorageControllerPortCount(ctx context.Context, controllerRef string, portCount uint32) error
+	SetStorageControllerUseHostIOCache(ctx context.Context, controllerRef string, enabled bool) error
+	GetStorageControllerBootable(ctx context.Context, controllerRef string) (bool, error)
+	// SetStorageControllerBootable marks a controller as the one the
+	// firmware boots from among the controllers of its type. VirtualBox
+	// clears the flag of the other controller of that type.
+	SetStorageControllerBootable(ctx context.Context, mutableMachineRef, name string, bootable bool) error
+
+	// Media and storage attachments
+	OpenMedium(ctx context.Context, session, location string, deviceType DeviceType, accessMode AccessMode) (mediumRef string, err error)
+	GetMediumId(ctx context.Context, mediumRef string) (uuid string, err error)
+	GetMediumLocation(ctx context.Context, mediumRef string) (location string, err error)
+	GetMediumType(ctx context.Context, mediumRef string) (mediumType MediumType, err error)
+	SetMediumType(ctx context.Context, mediumRef string, mediumType MediumType) error
+	GetMediumVariant(ctx context.Context, mediumRef string) (variants []string, err error)
+	GetMediumFormat(ctx context.Context, mediumRef string) (format string, err error)
+	// CreateMedium creates a medium for a new image at location. Its image
+	// is only written when the medium is the target of CloneMediumToBase.
+	CreateMedium(ctx context.Context, session, format, location string, deviceType DeviceType) (mediumRef string, err error)
+	// CloneMediumToBase copies a medium to the new base image targetRef,
+	// which is created with the given variants, e.g. MediumVariantFixed.
+	CloneMediumToBase(ctx context.Context, mediumRef, targetRef string, variants []string) (progressRef string, err error)
+	// MoveMedium moves the image of a medium to location.
+	MoveMedium(ctx context.Context, mediumRef, location string) (progressRef string, err error)
+	// DeleteMediumStorage deletes the image of a medium that is not
+	// attached to any machine, and closes the medium.
+	DeleteMediumStorage(ctx context.Context, mediumRef string) (progressRef string, err error)
+	GetMediumBase(ctx context.Context, mediumRef string) (baseMediumRef string, err error)
+	GetMediumChildren(ctx context.Context, mediumRef string) (childMediumRefs []string, err error)
+	GetMediumMachineIds(ctx context.Context, mediumRef string) (machineIDs []string, err error)
+	GetMediumAttachments(ctx context.Context, machineRef string) ([]MediumAttachment, error)
+	GetMediumAttachment(ctx context.Context, machineRef, controller string, port, device int32) (*MediumAttachment, error)
+	AttachDevice(ctx context.Context, machineRef, controller string, port, device int32, deviceType DeviceType, mediumRef string) error
+	AttachDeviceWithoutMedium(ctx context.Context, machineRef, controller string, port, device int32, deviceType DeviceType) error
+	DetachDevice(ctx context.Context, machineRef, controller string, port, device int32) error
+	MountMedium(ctx context.Context, machineRef, controller string, port, device int32, mediumRef string, force bool) error
+
+	// Shared folders
+	GetSharedFolders(ctx context.Context, machineRef string) ([]SharedFolder, error)
+	CreateSharedFolder(ctx context.Context, mutableMachineRef string, folder SharedFolder) error
+	RemoveSharedFolder(ctx context.Context, mutableMachineRef, name string) error
 
 	// Mutable machine operations (require lock)
 	GetMutableMachine(ctx context.Context, sessionObj string) (mutableMachineRef string, err error)
@@ -61,6 +307,8 @@ type VBoxAPI interface {
 
 	// Version info
 	GetAPIVersion(ctx context.Context, session string) (version string, err error)
+	// GetVersion returns the full VirtualBox version, e.g. "7.1.4".
+	GetVersion(ctx context.Context, session string) (version string, err error)
 }
 
 // NATProtocol represents the protocol for NAT port forwarding.
@@ -82,11 +330,318 @@ type NATRedirect struct {
 	GuestPort uint16
 }
 
-// MachineState constants normalized across versions.
+// NATNetwork describes a NAT network, which VMs attached to it share.
+type NATNetwork struct {
+	Name string
+	// Network is the IPv4 network in CIDR notation, e.g. "10.0.2.0/24".
+	Network     string
+	Enabled     bool
+	DHCPEnabled bool
+}
+
+// HostNetworkInterfaceStatus is the link status of a host network
+// interface.
+type HostNetworkInterfaceStatus string
+
+const (
+	HostNetworkInterfaceStatusUnknown HostNetworkInterfaceStatus = "Unknown"
+	HostNetworkInterfaceStatusUp      HostNetworkInterfaceStatus = "Up"
+	HostNetworkInterfaceStatusDown    HostNetworkInterfaceStatus = "Down"
+)
+
+// HostNetworkInterface describes a network interface of the VirtualBox
+// host.
+type HostNetworkInterface struct {
+	Name   string
+	Status HostNetworkInterfaceStatus
+	// IPAddress is the interface's IPv4 address, empty if it has none.
+	IPAddress       string
+	HardwareAddress string
+	Wireless        bool
+}
+
+// GuestOSType describes a guest OS that VirtualBox knows the recommended
+// settings of. Its ID is a valid OS type ID of a machine.
+type GuestOSType struct {
+	ID          string
+	Description string
+	FamilyID    string
+	Is64Bit     bool
+}
+
+// NetworkAttachmentType is what a network adapter is attached to.
+type NetworkAttachmentType string
+
+const (
+	NetworkAttachmentTypeNull            NetworkAttachmentType = "Null"
+	NetworkAttachmentTypeNAT             NetworkAttachmentType = "NAT"
+	NetworkAttachmentTypeBridged         NetworkAttachmentType = "Bridged"
+	NetworkAttachmentTypeInternal        NetworkAttachmentType = "Internal"
+	NetworkAttachmentTypeHostOnly        NetworkAttachmentType = "HostOnly"
+	NetworkAttachmentTypeGeneric         NetworkAttachmentType = "Generic"
+	NetworkAttachmentTypeNATNetwork      NetworkAttachmentType = "NATNetwork"
+	NetworkAttachmentTypeCloud           NetworkAttachmentType = "Cloud"
+	NetworkAttachmentTypeHostOnlyNetwork NetworkAttachmentType = "HostOnlyNetwork"
+)
+
+// NetworkAdapterType is the hardware a network adapter emulates.
+type NetworkAdapterType string
+
+const (
+	NetworkAdapterTypeAm79C970A NetworkAdapterType = "Am79C970A"
+	NetworkAdapterTypeAm79C973  NetworkAdapterType = "Am79C973"
+	NetworkAdapterTypeI82540EM  NetworkAdapterType = "I82540EM"
+	NetworkAdapterTypeI82543GC  NetworkAdapterType = "I82543GC"
+	NetworkAdapterTypeI82545EM  NetworkAdapterType = "I82545EM"
+	NetworkAdapterTypeVirtio    NetworkAdapterType = "Virtio"
+)
+
+// PlatformArchitecture is the CPU architecture of a VM.
+type PlatformArchitecture string
+
+const (
+	PlatformArchitectureX86 PlatformArchitecture = "x86"
+	PlatformArchitectureARM PlatformArchitecture = "ARM"
+)
+
+// ExecutionEngine selects how VirtualBox executes guest code.
+type ExecutionEngine string
+
+const (
+	ExecutionEngineDefault     ExecutionEngine = "Default"
+	ExecutionEngineHwVirt      ExecutionEngine = "HwVirt"
+	ExecutionEngineNativeAPI   ExecutionEngine = "NativeApi"
+	ExecutionEngineInterpreter ExecutionEngine = "Interpreter"
+	ExecutionEngineRecompiler  ExecutionEngine = "Recompiler"
+)
+
+// GraphicsController is the graphics controller emulated for a VM.
+type GraphicsController string
+
+const (
+	GraphicsControllerNull      GraphicsController = "Null"
+	GraphicsControllerVBoxVGA   GraphicsController = "VBoxVGA"
+	GraphicsControllerVMSVGA    GraphicsController = "VMSVGA"
+	GraphicsControllerVBoxSVGA  GraphicsController = "VBoxSVGA"
+	GraphicsControllerQemuRamFB GraphicsController = "QemuRamFB"
+)
+
+// APICMode is the interrupt controller the firmware exposes to the guest.
+type APICMode string
+
+const (
+	APICModeDisabled APICMode = "Disabled"
+	APICModeAPIC     APICMode = "APIC"
+	APICModeX2APIC   APICMode = "X2APIC"
+)
+
+// BootMenuMode controls whether the VM firmware shows its boot menu.
+type BootMenuMode string
+
+const (
+	BootMenuModeDisabled       BootMenuMode = "Disabled"
+	BootMenuModeMenuOnly       BootMenuMode = "MenuOnly"
+	BootMenuModeMessageAndMenu BootMenuMode = "MessageAndMenu"
+)
+
+// HWVirtExProperty is a hardware virtualization setting of an x86 VM.
+type HWVirtExProperty string
+
+const (
+	HWVirtExPropertyEnabled      HWVirtExProperty = "Enabled"
+	HWVirtExPropertyNestedPaging HWVirtExProperty = "NestedPaging"
+)
+
+// CPUProperty is a virtual CPU feature of an x86 VM.
+type CPUProperty string
+
+const (
+	CPUPropertyPAE      CPUProperty = "PAE"
+	CPUPropertyLongMode CPUProperty = "LongMode"
+	// CPUPropertyHWVirt exposes hardware virtualization to the guest
+	// (nested virtualization).
+	CPUPropertyHWVirt CPUProperty = "HWVirt"
+)
+
+// RecordingVideoCodec is the codec screen recordings are encoded with.
+// Recordings are written to WebM files.
+type RecordingVideoCodec string
+
+const (
+	RecordingVideoCodecVP8 RecordingVideoCodec = "VP8"
+	RecordingVideoCodecVP9 RecordingVideoCodec = "VP9"
+	RecordingVideoCodecAV1 RecordingVideoCodec = "AV1"
+)
+
+// RecordingScreenSettings describes how a screen of a machine is recorded.
+type RecordingScreenSettings struct {
+	ID      uint32
+	Enabled bool
+	// Filename is the path of the recording on the VirtualBox host.
+	Filename   string
+	VideoCodec RecordingVideoCodec
+	VideoFPS   uint32
+}
+
+// AdditionsRunLevel reports how far the Guest Additions in a running VM
+// have started.
+type AdditionsRunLevel string
+
+const (
+	AdditionsRunLevelNone     AdditionsRunLevel = "None"
+	AdditionsRunLevelSystem   AdditionsRunLevel = "System"
+	AdditionsRunLevelUserland AdditionsRunLevel = "Userland"
+	AdditionsRunLevelDesktop  AdditionsRunLevel = "Desktop"
+)
+
+// CPUIDLeaf is a CPUID leaf override reported to the guest instead of the
+// host's value. Only x86 machines support it.
+type CPUIDLeaf struct {
+	Leaf    uint32
+	SubLeaf uint32
+	EAX     uint32
+	EBX     uint32
+	ECX     uint32
+	EDX     uint32
+}
+
+// DeviceType is the kind of device a medium is attached as.
+type DeviceType string
+
+const (
+	DeviceTypeHardDisk DeviceType = "HardDisk"
+	DeviceTypeDVD      DeviceType = "DVD"
+	DeviceTypeFloppy   DeviceType = "Floppy"
+)
+
+// AccessMode controls how a medium is opened.
+type AccessMode string
+
+const (
+	AccessModeReadOnly  AccessMode = "ReadOnly"
+	AccessModeReadWrite AccessMode = "ReadWrite"
+)
+
+// MediumType controls how a medium behaves when attached to machines.
+type MediumType string
+
+const (
+	MediumTypeNormal    MediumType = "Normal"
+	MediumTypeImmutable MediumType = "Immutable"
+	MediumTypeShareable MediumType = "Shareable"
+	MediumTypeReadonly  MediumType = "Readonly"
+)
+
+// MediumVariantFixed is reported by GetMediumVariant for fixed-size images.
+const MediumVariantFixed = "Fixed"
+
+// StorageControllerType is the emulated chipset of a storage controller.
+type StorageControllerType string
+
+const (
+	StorageControllerLsiLogic    StorageControllerType = "LsiLogic"
+	StorageControllerBusLogic    StorageControllerType = "BusLogic"
+	StorageControllerIntelAhci   StorageControllerType = "IntelAhci"
+	StorageControllerPIIX3       StorageControllerType = "PIIX3"
+	StorageControllerPIIX4       StorageControllerType = "PIIX4"
+	StorageControllerICH6        StorageControllerType = "ICH6"
+	StorageControllerI82078      StorageControllerType = "I82078"
+	StorageControllerLsiLogicSas StorageControllerType = "LsiLogicSas"
+	StorageControllerUSB         StorageControllerType = "USB"
+	StorageControllerNVMe        StorageControllerType = "NVMe"
+	StorageControllerVirtioSCSI  StorageControllerType = "VirtioSCSI"
+)
+
+// StorageBus is the bus a storage controller connects its devices with.
+type StorageBus string
+
+const (
+	StorageBusIDE        StorageBus = "IDE"
+	StorageBusSATA       StorageBus = "SATA"
+	StorageBusSCSI       StorageBus = "SCSI"
+	StorageBusFloppy     StorageBus = "Floppy"
+	StorageBusSAS        StorageBus = "SAS"
+	StorageBusUSB        StorageBus = "USB"
+	StorageBusPCIe       StorageBus = "PCIe"
+	StorageBusVirtioSCSI StorageBus = "VirtioSCSI"
+)
+
+// StorageController describes a storage controller of a machine.
+type StorageController struct {
+	Name      string
+	Bus       StorageBus
+	Type      StorageControllerType
+	PortCount uint32
+	// UseHostIOCache reports whether the host's I/O cache is used for the
+	// controller's disks.
+	UseHostIOCache bool
+	// Bootable reports whether the firmware may boot from the controller's
+	// devices.
+	Bootable bool
+}
+
+// MediumAttachment describes a medium attached to a storage controller port.
+type MediumAttachment struct {
+	Controller string
+	Port       int32
+	Device     int32
+	Type       DeviceType
+	MediumRef  string // empty when the slot holds no medium
+}
+
+// GuestProperty is a property shared between the host and the guest
+// through the Guest Additions.
+type GuestProperty struct {
+	Name  string
+	Value string
+	// Flags is a comma separated list of flags, such as TRANSIENT or
+	// RDONLYGUEST; empty means none.
+	Flags string
+}
+
+// SharedFolder describes a host directory shared with a machine's guest.
+type SharedFolder struct {
+	Name     string
+	HostPath string
+	Writable bool
+	// AutoMount reports whether the Guest Additions mount the folder at
+	// boot, at AutoMountPoint or, when it is empty, a guest-chosen path.
+	AutoMount      bool
+	AutoMountPoint string
+}
+
+// MachineState constants normalized across versions. GetMachineState
+// returns them verbatim, including the transient states a VM passes through
+// while it changes state.
 const (
 	MachineStateNull       = "Null"
 	MachineStatePoweredOff = "PoweredOff"
-	MachineStateRunning    = "Running"
 	MachineStateSaved      = "Saved"
-	MachineStatePaused     = "Paused"
+	MachineStateTeleported = "Teleported"
+	// MachineStateAborted is the state of a VM whose process terminated
+	// unexpectedly; MachineStateAbortedSaved also has a saved state.
+	MachineStateAborted      = "Aborted"
+	MachineStateAbortedSaved = "AbortedSaved"
+	MachineStateRunning      = "Running"
+	MachineStatePaused       = "Paused"
+	// MachineStateStuck is the state of a VM whose guest hit a fatal error,
+	// a "guru meditation".
+	MachineStateStuck = "Stuck"
+
+	// Transient states.
+	MachineStateTeleporting            = "Teleporting"
+	MachineStateLiveSnapshotting       = "LiveSnapshotting"
+	MachineStateStarting               = "Starting"
+	MachineStateStopping               = "Stopping"
+	MachineStateSaving                 = "Saving"
+	MachineStateRestoring              = "Restoring"
+	MachineStateTeleportingPausedVM    = "TeleportingPausedVM"
+	MachineStateTeleportingIn          = "TeleportingIn"
+	MachineStateDeletingSnapshotOnline = "DeletingSnapshotOnline"
+	MachineStateDeletingSnapshotPaused = "DeletingSnapshotPaused"
+	MachineStateOnlineSnapshotting     = "OnlineSnapshotting"
+	MachineStateRestoringSnapshot      = "RestoringSnapshot"
+	MachineStateDeletingSnapshot       = "DeletingSnapshot"
+	MachineStateSettingUp              = "SettingUp"
+	MachineStateSnapshotting           = "Snapshotting"
 )
@@ -0,0 +1,155 @@
+package vboxapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error is a fault returned by VirtualBox for a SOAP operation.
+// Adapters convert version-specific faults into this type so that callers
+// can inspect the failing component and result code.
+type Error struct {
+	// Operation is the SOAP operation that failed, e.g. "IMachine_cloneTo".
+	Operation string
+	// Interface is the VirtualBox interface the operation belongs to, e.g. "IMachine".
+	Interface string
+	// Component is the VirtualBox component that raised the error, e.g. "MachineWrap".
+	// Empty when the fault carried no error info.
+	Component string
+	// ResultCode is the COM result code of the fault.
+	ResultCode int32
+	// Text is the message reported by VirtualBox.
+	Text string
+	// BadObjectID is the managed object reference the webservice rejected
+	// as invalid, e.g. that of a session that timed out. Empty for other
+	// faults.
+	BadObjectID string
+}
+
+func (e *Error) Error() string {
+	msg := e.Text
+	if msg == "" {
+		msg = "unknown error"
+	}
+	if e.Operation != "" {
+		msg = e.Operation + ": " + msg
+	}
+	if e.ResultCode != 0 {
+		msg += " (" + ResultCodeString(e.ResultCode) + ")"
+	}
+	return msg
+}
+
+// VirtualBox and COM result codes.
+const (
+	ResultObjectNotFound      = int32(-2135228415) // 0x80BB0001
+	ResultInvalidVMState      = int32(-2135228414) // 0x80BB0002
+	ResultVMError             = int32(-2135228413) // 0x80BB0003
+	ResultFileError           = int32(-2135228412) // 0x80BB0004
+	ResultIPRTError           = int32(-2135228411) // 0x80BB0005
+	ResultPDMError            = int32(-2135228410) // 0x80BB0006
+	ResultInvalidObjectState  = int32(-2135228409) // 0x80BB0007
+	ResultHostError           = int32(-2135228408) // 0x80BB0008
+	ResultNotSupported        = int32(-2135228407) // 0x80BB0009
+	ResultXMLError            = int32(-2135228406) // 0x80BB000A
+	ResultInvalidSessionState = int32(-2135228405) // 0x80BB000B
+	ResultObjectInUse         = int32(-2135228404) // 0x80BB000C
+	ResultPasswordIncorrect   = int32(-2135228403) // 0x80BB000D
+	ResultNotImpl             = int32(-2147467263) // 0x80004001
+	ResultFail                = int32(-2147467259) // 0x80004005
+	ResultUnexpected          = int32(-2147418113) // 0x8000FFFF
+	ResultAccessDenied        = int32(-2147024891) // 0x80070005
+	ResultInvalidArg          = int32(-2147024809) // 0x80070057
+
+	// RPC failures reported when the COM/XPCOM connection to VBoxSVC is lost.
+	ResultRPCServerUnavailable = int32(-2147023174) // 0x800706BA
+	ResultRPCCallFailed        = int32(-2147023170) // 0x800706BE
+	ResultRPCDisconnected      = int32(-2147417848) // 0x80010108
+)
+
+var resultCodeNames = map[int32]string{
+	ResultObjectNotFound:      "VBOX_E_OBJECT_NOT_FOUND",
+	ResultInvalidVMState:      "VBOX_E_INVALID_VM_STATE",
+	ResultVMError:             "VBOX_E_VM_ERROR",
+	ResultFileError:           "VBOX_E_FILE_ERROR",
+	ResultIPRTError:           "VBOX_E_IPRT_ERROR",
+	ResultPDMError:            "VBOX_E_PDM_ERROR",
+	ResultInvalidObjectState:  "VBOX_E_INVALID_OBJECT_STATE",
+	ResultHostError:           "VBOX_E_HOST_ERROR",
+	ResultNotSupported:        "VBOX_E_NOT_SUPPORTED",
+	ResultXMLError:            "VBOX_E_XML_ERROR",
+	ResultInvalidSessionState: "VBOX_E_INVALID_SESSION_STATE",
+	ResultObjectInUse:         "VBOX_E_OBJECT_IN_USE",
+	ResultPasswordIncorrect:   "VBOX_E_PASSWORD_INCORRECT",
+	ResultNotImpl:             "E_NOTIMPL",
+	ResultFail:                "E_FAIL",
+	ResultUnexpected:          "E_UNEXPECTED",
+	ResultAccessDenied:        "E_ACCESSDENIED",
+	ResultInvalidArg:          "E_INVALIDARG",
+
+	ResultRPCServerUnavailable: "RPC_S_SERVER_UNAVAILABLE",
+	ResultRPCCallFailed:        "RPC_S_CALL_FAILED",
+	ResultRPCDisconnected:      "RPC_E_DISCONNECTED",
+}
+
+// ResultCodeString formats a result code as hex, followed by its symbolic
+// name when known, e.g. "0x80BB0001 VBOX_E_OBJECT_NOT_FOUND".
+func ResultCodeString(code int32) string {
+	s := fmt.Sprintf("0x%08X", uint32(code))
+	if name, ok := resultCodeNames[code]; ok {
+		s += " " + name
+	}
+	return s
+}
+
+// IsObjectNotFound reports whether err is a fault with result code
+// VBOX_E_OBJECT_NOT_FOUND, which VirtualBox returns when a lookup, such as
+// IVirtualBox::findMachine, finds nothing. Unlike its message, the code
+// does not depend on the locale of vboxwebsrv.
+func IsObjectNotFound(err error) bool {
+	var vErr *Error
+	return errors.As(err, &vErr) && vErr.ResultCode == ResultObjectNotFound
+}
+
+// IsInvalidObject reports whether err is a fault rejecting ref as an invalid
+// managed object reference.
+func IsInvalidObject(err error, ref string) bool {
+	var vErr *Error
+	return ref != "" && errors.As(err, &vErr) && vErr.BadObjectID == ref
+}
+
+// ServiceUnavailableError reports that vboxwebsrv answered a request but
+// could not reach its VBoxSVC backend, typically because VBoxSVC crashed.
+// Unlike a connection error, the HTTP endpoint is still up.
+type ServiceUnavailableError struct {
+	Err *Error
+}
+
+func (e *ServiceUnavailableError) Error() string {
+	return "VirtualBox service (VBoxSVC) is not available: " + e.Err.Error() +
+		". vboxwebsrv is reachable but its VBoxSVC backend is not responding, which usually means VBoxSVC crashed." +
+		" Check that VBoxSVC is running on the VirtualBox host (restarting vboxwebsrv starts a new one) and retry."
+}
+
+func (e *ServiceUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// IsServiceUnavailable reports whether err indicates that VBoxSVC is unavailable.
+func IsServiceUnavailable(err error) bool {
+	var sErr *ServiceUnavailableError
+	return errors.As(err, &sErr)
+}
+
+// ServiceUnavailable reports whether e was caused by a lost connection
+// between vboxwebsrv and VBoxSVC.
+func (e *Error) ServiceUnavailable() bool {
+	switch e.ResultCode {
+	case ResultRPCServerUnavailable, ResultRPCCallFailed, ResultRPCDisconnected:
+		return true
+	}
+	text := strings.ToLower(e.Text)
+	return strings.Contains(text, "vboxsvc") &&
+		(strings.Contains(text, "not available") || strings.Contains(text, "unavailable") || strings.Contains(text, "terminated"))
+}
@@ -2,17 +2,27 @@ package main
 
 import (
 	"context"
+	"io"
 	"log"
 
+	tfprovider "github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 
 	"github.com/aslafy-z/terraform-provider-vboxweb/internal/provider"
 )
 
 func main() {
-	err := providerserver.Serve(context.Background(), provider.New, providerserver.ServeOpts{
+	// A single provider instance serves every request, so that the
+	// webservice sessions it keeps can be logged off on exit.
+	p := provider.New()
+	err := providerserver.Serve(context.Background(), func() tfprovider.Provider { return p }, providerserver.ServeOpts{
 		Address: "example.com/local/vboxweb",
 	})
+	if c, ok := p.(io.Closer); ok {
+		if cErr := c.Close(); cErr != nil {
+			log.Printf("failed to log off VirtualBox sessions: %v", cErr)
+		}
+	}
 	if err != nil {
 		log.Fatal(err)
 	}